@@ -1,17 +1,22 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
 
 	"github.com/go-co-op/gocron/v2"
+	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/rhellwege/task-social/config"
 	_ "github.com/rhellwege/task-social/docs"
@@ -32,7 +37,8 @@ import (
 // @name						Authorization
 // @schemes					http
 func main() {
-	app := fiber.New()
+	rebuildPoints := flag.Bool("rebuild-points", false, "recompute every member's points from the points ledger and exit")
+	flag.Parse()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -52,12 +58,87 @@ func main() {
 	}
 	log.Printf("Connecting to database at %s..", dbURL)
 
+	config.Environment = os.Getenv("APP_ENV")
+	if config.Environment == "" {
+		log.Println("WARNING: APP_ENV environment variable not set, assuming development")
+	}
+
+	switch alg := os.Getenv("JWT_ALGORITHM"); alg {
+	case "":
+		log.Println("WARNING: JWT_ALGORITHM environment variable not set, using HS256")
+	case jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg():
+		config.JWTSigningMethod = jwt.GetSigningMethod(alg)
+	default:
+		log.Fatalf("JWT_ALGORITHM %q is not supported, use HS256, RS256 or EdDSA", alg)
+	}
+
 	config.JWTSecret = []byte(os.Getenv("JWT_SECRET_KEY"))
-	if len(config.JWTSecret) == 0 {
+	usesSecret := config.JWTSigningMethod.Alg() == jwt.SigningMethodHS256.Alg()
+	if usesSecret && config.Environment == config.EnvironmentProduction &&
+		(len(config.JWTSecret) == 0 || bytes.Equal(config.JWTSecret, config.DefaultJWTSecret)) {
+		log.Fatal("Refusing to start in production with the default JWT secret, set JWT_SECRET_KEY or an asymmetric JWT_ALGORITHM")
+	}
+	if usesSecret && len(config.JWTSecret) == 0 {
 		log.Println("WARNING: JWT_SECRET_KEY environment variable not set, using default")
 		config.JWTSecret = config.DefaultJWTSecret
 	}
 
+	config.AppURL = os.Getenv("APP_URL")
+	if config.AppURL == "" {
+		log.Println("WARNING: APP_URL environment variable not set, using default")
+		config.AppURL = config.DefaultAppURL
+	}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := config.OIDCProvider{
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			log.Printf("WARNING: %sISSUER or %sCLIENT_ID environment variable not set, skipping identity provider %s", prefix, prefix, name)
+			continue
+		}
+		if provider.RedirectURL == "" {
+			provider.RedirectURL = config.AppURL + "/oidc/" + name + "/callback"
+		}
+		config.OIDCProviders[name] = provider
+	}
+
+	config.TrustedProxies = splitList(os.Getenv("TRUSTED_PROXIES"))
+	if header := os.Getenv("PROXY_HEADER"); header != "" {
+		config.ProxyHeader = header
+	}
+	app := fiber.New(routes.AppConfig())
+
+	config.ContentBlockedWords = splitList(os.Getenv("CONTENT_BLOCKED_WORDS"))
+	config.ContentReviewWords = splitList(os.Getenv("CONTENT_REVIEW_WORDS"))
+
+	var mailer services.Mailer
+	if smtpHost := os.Getenv("SMTP_HOST"); smtpHost != "" {
+		smtpPort := os.Getenv("SMTP_PORT")
+		if smtpPort == "" {
+			smtpPort = config.DefaultSMTPPort
+		}
+		mailFrom := os.Getenv("MAIL_FROM")
+		if mailFrom == "" {
+			mailFrom = config.DefaultMailFrom
+		}
+		mailer = services.NewSMTPMailer(smtpHost, smtpPort, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), mailFrom)
+	} else if mailDir := os.Getenv("MAIL_DIR"); mailDir != "" {
+		log.Printf("WARNING: SMTP_HOST environment variable not set, writing emails to %s", mailDir)
+		mailer = services.NewFileMailer(mailDir, config.DefaultMailFrom)
+	} else {
+		log.Println("WARNING: SMTP_HOST environment variable not set, emails will only be logged")
+		mailer = services.NewLogMailer()
+	}
+
 	conn, conn_closer, err := db.New(ctx, dbURL)
 	if err != nil {
 		panic(err)
@@ -66,13 +147,28 @@ func main() {
 
 	queries := repository.New(conn)
 
+	if *rebuildPoints {
+		fixed, err := services.RebuildPointBalances(ctx, queries)
+		if err != nil {
+			log.Fatalf("Failed to rebuild point balances: %v", err)
+		}
+		log.Printf("Rebuilt point balances from the points ledger, %d were wrong", fixed)
+		return
+	}
+
+	// env: ADMIN_EMAILS promotes the accounts that verified one of the addresses, checked on every start
+	if err := services.GrantAdminRoles(ctx, queries, splitList(os.Getenv("ADMIN_EMAILS"))); err != nil {
+		log.Fatalf("Failed to grant admin roles: %v", err)
+	}
+
 	app.Get("/swagger/*", swagger.HandlerDefault) // default
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = config.DefaultPort
 	}
-	routes.SetupServicesAndRoutes(app, queries)
+	wsService := services.NewWebSocketService(services.DisconnectSlowClients)
+	routes.SetupServicesAndRoutes(app, conn, queries, mailer, wsService)
 
 	scheduler, err := gocron.NewScheduler()
 	if err != nil {
@@ -86,6 +182,45 @@ func main() {
 		}
 	}))
 
+	if config.JWTSigningMethod.Alg() != jwt.SigningMethodHS256.Alg() {
+		scheduler.NewJob(gocron.DurationJob(config.SigningKeyCheckPeriod), gocron.NewTask(func() {
+			if err := services.RotateSigningKeys(ctx, queries, config.JWTSigningMethod, time.Now()); err != nil {
+				log.Printf("Failed to rotate JWT signing keys: %v", err)
+			}
+		}))
+	}
+
+	imageService := services.NewImageService("./assets")
+	scheduler.NewJob(gocron.DurationJob(config.AccountDeletionCheckPeriod), gocron.NewTask(func() {
+		if err := services.DeleteScheduledAccounts(ctx, queries, services.NewTransactor(conn), imageService, time.Now()); err != nil {
+			log.Printf("Failed to delete scheduled accounts: %v", err)
+		}
+	}))
+
+	scheduler.NewJob(gocron.DurationJob(config.AuctionCheckPeriod), gocron.NewTask(func() {
+		if err := services.SettleAuctions(ctx, queries, services.NewTransactor(conn), wsService, time.Now()); err != nil {
+			log.Printf("Failed to settle auctions: %v", err)
+		}
+	}))
+
+	scheduler.NewJob(gocron.DurationJob(config.SessionCleanupPeriod), gocron.NewTask(func() {
+		if err := services.CleanUpExpiredSessions(ctx, queries); err != nil {
+			log.Printf("Failed to clean up expired sessions: %v", err)
+		}
+		if err := services.CleanUpExpiredUserTokens(ctx, queries); err != nil {
+			log.Printf("Failed to clean up expired user tokens: %v", err)
+		}
+		if err := services.CleanUpExpiredOIDCLoginStates(ctx, queries); err != nil {
+			log.Printf("Failed to clean up expired oidc login states: %v", err)
+		}
+		if err := services.CleanUpOldLoginAttempts(ctx, queries); err != nil {
+			log.Printf("Failed to clean up old login attempts: %v", err)
+		}
+		if err := services.CleanUpExpiredPersonalAccessTokens(ctx, queries); err != nil {
+			log.Printf("Failed to clean up expired personal access tokens: %v", err)
+		}
+	}))
+
 	scheduler.Start()
 
 	serverAddr := fmt.Sprintf(":%s", port)
@@ -100,3 +235,14 @@ func main() {
 	<-ctx.Done()
 	log.Println("Server shut down.")
 }
+
+// splitList splits a comma separated environment variable, leaving out empty entries
+func splitList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
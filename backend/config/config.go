@@ -11,8 +11,13 @@ const (
 	DefaultBcryptHashCost   = bcrypt.DefaultCost
 	DefaultPort             = "5050"
 	DefaultDBURL            = "./test.sqlite"
+	DefaultProxyHeader      = "X-Real-IP"
 	HandlerTimeout          = 10 * time.Second
-	TokenExpirationDuration = 24 * time.Hour
+	AccessTokenDuration     = 15 * time.Minute
+	RefreshTokenDuration    = 30 * 24 * time.Hour
+	RefreshTokenBytes       = 32
+	SessionLastSeenInterval = 1 * time.Minute // how stale last_seen_at may get before it is rewritten
+	SessionCleanupPeriod    = 1 * time.Hour
 	MetricCheckPeriod       = 1 * time.Minute
 	MinPasswordLength       = 8
 	MinPasswordLowercase    = 1
@@ -24,14 +29,190 @@ const (
 	BannerImageWidth        = 1024
 	BannerImageHeight       = 256
 	BannerImageSubDir       = "assets/banner"
+
+	// Email
+	EmailTokenBytes            = 32
+	VerifyEmailTokenDuration   = 48 * time.Hour
+	ResetPasswordTokenDuration = 1 * time.Hour
+	DeleteAccountTokenDuration = 1 * time.Hour
+	DefaultAppURL              = "http://localhost:8081"
+	DefaultMailFrom            = "Task Social <no-reply@tasksocial.local>"
+	DefaultSMTPPort            = "25"
+	MailSendTimeout            = 30 * time.Second // connecting to the relay and delivering one mail
+
+	// Two factor auth
+	TOTPIssuer        = "Task Social"
+	TOTPSecretBytes   = 20
+	TOTPPeriod        = 30 * time.Second
+	TOTPDigits        = 6
+	TOTPSkewSteps     = 1 // codes from one step before or after are accepted to tolerate clock drift
+	RecoveryCodeCount = 10
+	MFATokenDuration  = 5 * time.Minute
+
+	// OpenID Connect
+	OIDCLoginStateDuration = 10 * time.Minute // how long the user has to finish logging in at the provider
+	OIDCHTTPTimeout        = 10 * time.Second
+	OIDCKeyRefreshInterval = 1 * time.Minute // how often a token with an unknown key id may refetch the provider's keys
+	OIDCMaxUsernameLength  = 32
+
+	// Login rate limiting, failures are counted per account and per IP inside the window.
+	// after the free failures every further one doubles the wait, reaching the lockout threshold locks for LoginLockoutDuration
+	LoginAttemptWindow       = 15 * time.Minute
+	LoginBackoffBase         = 1 * time.Second
+	LoginBackoffMax          = 5 * time.Minute
+	LoginLockoutDuration     = 15 * time.Minute
+	AccountFreeLoginFailures = 3
+	AccountLockoutFailures   = 10
+	IPFreeLoginFailures      = 20
+	IPLockoutFailures        = 100
+	RegistrationWindow       = 1 * time.Hour
+	MaxRegistrationsPerIP    = 10
+	LoginAttemptRetention    = 90 * 24 * time.Hour
+	LoginFailuresShown       = 50 // recent failures a user can see on their own account
+
+	// Personal access tokens
+	PersonalTokenPrefix          = "tsp_" // makes leaked tokens easy to recognize
+	PersonalTokenBytes           = 32
+	DefaultPersonalTokenDuration = 30 * 24 * time.Hour
+	MaxPersonalTokenDuration     = 365 * 24 * time.Hour
+
+	// Profiles
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 500
+	MaxLocationLength    = 100
+	MaxPronounsLength    = 30
+	MaxProfileLinks      = 5
+	MaxLinkLabelLength   = 50
+	MaxLinkURLLength     = 2048
+
+	// WebSocket, every connection has its own queue of messages waiting to be written
+	WebSocketSendQueueSize = 64
+	WebSocketWriteTimeout  = 10 * time.Second
+	// events for a club's posts, members and auctions or a metric are only sent to connections subscribed to them
+	MaxWebSocketSubscriptions = 100
+
+	// Account deletion, the account stays until the cooling-off period is over and can be restored by cancelling
+	AccountDeletionCoolingOff  = 14 * 24 * time.Hour
+	AccountDeletionCheckPeriod = 1 * time.Hour
+
+	// Admin, impersonation sessions are read-only and end with their access token
+	ImpersonationDuration = 15 * time.Minute
+	DefaultAdminPageSize  = 50
+	MaxAdminPageSize      = 200
+	MaxAdminReasonLength  = 500
+
+	// Reports
+	MaxReportCommentLength = 1000
+	MaxReportNoteLength    = 500
+
+	// Direct messages, users can write to their friends, the members of their clubs and whoever wrote to them
+	MaxDirectMessageLength = 2000
+
+	// Content filter, posting too often is rejected, too many or repeated links are held for the club's moderators
+	PostingRateWindow     = 10 * time.Minute
+	MaxPostsPerRateWindow = 20
+	MaxItemsPerRateWindow = 10
+	MaxLinksPerContent    = 3
+	RepeatedLinkWindow    = 24 * time.Hour // the same link posted again inside the window is held
+	MaxBlockedTermLength  = 100
+	MaxClubBlockedTerms   = 200
+
+	// Marketplace
+	DefaultCurrency         = "USD" // for price estimates given without a currency
+	MaxItemImages           = 8
+	ItemImageWidth          = 1024 // item photos keep their aspect ratio
+	ItemThumbnailWidth      = 256
+	MaxPickupLocationLength = 100
+
+	// Marketplace search, words in a query match as prefixes of the words in an item's name or description
+	DefaultSearchPageSize    = 50
+	MaxSearchPageSize        = 200
+	MaxSearchQueryLength     = 200
+	MaxSavedSearches         = 20 // per user
+	MaxSavedSearchNameLength = 50
+
+	// Reservations and wishlists, a reserved item is hidden from everyone but the user it is held for
+	DefaultReservationDuration = 48 * time.Hour
+	MaxReservationDuration     = 7 * 24 * time.Hour
+	MaxWishlistItems           = 200 // per user
+
+	// Auctions, bids are in the auction's currency and paid outside the app.
+	// a bid less than AuctionSnipingWindow before the end moves the end to AuctionExtension after the bid
+	MinAuctionDuration   = 1 * time.Hour
+	MaxAuctionDuration   = 30 * 24 * time.Hour
+	DefaultBidIncrement  = 1.0
+	AuctionSnipingWindow = 5 * time.Minute
+	AuctionExtension     = 5 * time.Minute
+	AuctionCheckPeriod   = 1 * time.Minute
+
+	// Trades
+	MaxTradeItems = 10 // per side of a trade
+	// proposals where one side is estimated at more than this many times the other get a warning
+	TradeValueWarningRatio = 2.0
+
+	// Reviews, each party of an accepted trade can rate the other once
+	MinReviewRating        = 1
+	MaxReviewRating        = 5
+	MaxReviewCommentLength = 400
+	DefaultReviewPageSize  = 50
+	MaxReviewPageSize      = 200
+
+	// Point shop, members spend their club points on rewards the club's moderators then hand out
+	MaxRewardNameLength        = 100
+	MaxRewardDescriptionLength = 1000
+	MaxRedemptionNoteLength    = 500
+
+	// Points, every change is recorded in the club's points ledger
+	MetricEntryPoints           = 10.0 // for turning in a metric
+	StreakBonusPoints           = 5.0  // on top, when the previous instance of the metric was turned in as well
+	MaxPointNoteLength          = 500
+	DefaultPointHistoryPageSize = 50
+	MaxPointHistoryPageSize     = 200
+
+	// JWT signing keys, only used with an asymmetric JWT_ALGORITHM. a key signs for the rotation period,
+	// is published in the JWKS before it starts signing and stays there until the tokens it signed have expired
+	SigningKeyRotationPeriod = 30 * 24 * time.Hour
+	SigningKeyPrePublish     = 24 * time.Hour
+	SigningKeyGracePeriod    = 24 * time.Hour
+	SigningKeyCheckPeriod    = 1 * time.Hour
+	SigningKeyCacheDuration  = 1 * time.Minute // how long an instance may take to notice another one rotated
+	RSAKeyBits               = 2048
+	EnvironmentProduction    = "production"
 )
 
 var (
 	DefaultJWTSecret = []byte("secret")
-	JWTSecret        []byte // env: JWT_SECRET_KEY or config.DefaultJWTSecret
-	JWTSigningMethod = jwt.SigningMethodHS256
-	Version          string // set at compile time. do not touch
-	CommitHash       string // set at compile time. do not touch
-	BuildDate        string // set at compile time. do not touch
-	DBURL            string // env: DATABASE_URL or config.DefaultDBURL
+	JWTSecret        []byte                                     // env: JWT_SECRET_KEY or config.DefaultJWTSecret
+	JWTSigningMethod jwt.SigningMethod = jwt.SigningMethodHS256 // env: JWT_ALGORITHM, HS256, RS256 or EdDSA
+	Environment      string                                     // env: APP_ENV, production refuses to start with the default secret
+	Version          string                                     // set at compile time. do not touch
+	CommitHash       string                                     // set at compile time. do not touch
+	BuildDate        string                                     // set at compile time. do not touch
+	DBURL            string                                     // env: DATABASE_URL or config.DefaultDBURL
+	AppURL           string                                     // env: APP_URL or config.DefaultAppURL, used to build links in emails
+
+	// unverified accounts can browse but not create clubs, posts or items. tests turn this off
+	RequireVerifiedEmail = true
+	// logins and registrations are throttled, failed attempts are recorded either way. tests turn this off
+	LoginRateLimiting = true
+
+	// behind a reverse proxy every request comes from the proxy, which would put all users in one login throttling bucket.
+	// the header is only believed for requests from a trusted proxy, which has to set it rather than append to it
+	TrustedProxies []string             // env: TRUSTED_PROXIES, comma separated IP addresses and CIDR ranges
+	ProxyHeader    = DefaultProxyHeader // env: PROXY_HEADER
+
+	ContentBlockedWords []string // env: CONTENT_BLOCKED_WORDS, comma separated words and phrases that reject content
+	ContentReviewWords  []string // env: CONTENT_REVIEW_WORDS, comma separated words and phrases that hold club content for review
 )
+
+// OIDCProvider is an OpenID Connect identity provider users can log in with
+type OIDCProvider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // optional for public clients, PKCE is always used
+	RedirectURL  string // page of the app that receives the authorization code
+}
+
+// env: OIDC_PROVIDERS is a comma separated list of names, each configured with
+// OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET and OIDC_<NAME>_REDIRECT_URL
+var OIDCProviders = map[string]OIDCProvider{}
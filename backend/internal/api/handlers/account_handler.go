@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+type AccountDeletionRequest struct {
+	Password string `json:"password"`
+}
+
+type ConfirmAccountDeletionRequest struct {
+	Token string `json:"token"`
+}
+
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+// RequestAccountDeletion godoc
+//
+//	@ID				RequestAccountDeletion
+//	@Summary		Delete the account
+//	@Description	Schedule the authenticated user's account for deletion after a 14 day cooling-off period. Until then the account works normally and the deletion can be cancelled. Posts stay in their clubs without an author, owned clubs are handed to a moderator or the longest standing member, everything else is deleted. Accounts without a password, which log in through an identity provider, get 400 and confirm by email instead.
+//	@Tags			Account
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		AccountDeletionRequest	true	"Current password"
+//	@Success		202		{object}	AccountDeletionResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Router			/api/user/deletion [post]
+func RequestAccountDeletion(accountService services.AccountServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		var params AccountDeletionRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		deleteAt, err := accountService.RequestDeletion(ctx, userID, params.Password)
+		if errors.Is(err, services.ErrIncorrectPassword) {
+			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrNoPassword) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrDeletionScheduled) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(AccountDeletionResponse{
+			DeletionScheduledAt: deleteAt,
+		})
+	}
+}
+
+// SendAccountDeletionEmail godoc
+//
+//	@ID				SendAccountDeletionEmail
+//	@Summary		Confirm the account deletion by email
+//	@Description	For accounts without a password. Send a link to the authenticated user's email address, its token confirms the deletion at /api/user/deletion/confirm. Older links stop working.
+//	@Tags			Account
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	SuccessResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Router			/api/user/deletion/email [post]
+func SendAccountDeletionEmail(accountService services.AccountServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		err := accountService.SendDeletionConfirmation(ctx, userID)
+		if errors.Is(err, services.ErrHasPassword) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrDeletionScheduled) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Confirmation email sent",
+		})
+	}
+}
+
+// ConfirmAccountDeletion godoc
+//
+//	@ID				ConfirmAccountDeletion
+//	@Summary		Delete the account with an emailed token
+//	@Description	Schedule the deletion like /api/user/deletion with the token from the confirmation email. The token must have been sent to the authenticated user.
+//	@Tags			Account
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		ConfirmAccountDeletionRequest	true	"Confirmation token"
+//	@Success		202		{object}	AccountDeletionResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Router			/api/user/deletion/confirm [post]
+func ConfirmAccountDeletion(accountService services.AccountServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		var params ConfirmAccountDeletionRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		deleteAt, err := accountService.ConfirmDeletion(ctx, userID, params.Token)
+		if errors.Is(err, services.ErrInvalidEmailToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrDeletionScheduled) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(AccountDeletionResponse{
+			DeletionScheduledAt: deleteAt,
+		})
+	}
+}
+
+// GetAccountDeletion godoc
+//
+//	@ID				GetAccountDeletion
+//	@Summary		Get the scheduled account deletion
+//	@Description	Get when the authenticated user's account will be deleted.
+//	@Tags			Account
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	AccountDeletionResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Router			/api/user/deletion [get]
+func GetAccountDeletion(accountService services.AccountServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		deleteAt, err := accountService.GetDeletionScheduledAt(ctx, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if deleteAt == nil {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: services.ErrNoDeletionScheduled.Error(),
+			})
+		}
+
+		return c.JSON(AccountDeletionResponse{
+			DeletionScheduledAt: *deleteAt,
+		})
+	}
+}
+
+// CancelAccountDeletion godoc
+//
+//	@ID			CancelAccountDeletion
+//	@Summary	Cancel the scheduled account deletion
+//	@Tags		Account
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Success	200	{object}	SuccessResponse
+//	@Failure	401	{object}	ErrorResponse
+//	@Failure	404	{object}	ErrorResponse
+//	@Router		/api/user/deletion [delete]
+func CancelAccountDeletion(accountService services.AccountServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		err := accountService.CancelDeletion(ctx, userID)
+		if errors.Is(err, services.ErrNoDeletionScheduled) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Account deletion cancelled",
+		})
+	}
+}
+
+// ExportAccountData godoc
+//
+//	@ID				ExportAccountData
+//	@Summary		Download your data
+//	@Description	Download a ZIP archive of the authenticated user's profile, clubs, posts, metric entries, items and messages as JSON and CSV, together with the images they uploaded.
+//	@Tags			Account
+//	@Produce		application/zip
+//	@Security		ApiKeyAuth
+//	@Success		200	{file}		file
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/api/user/export [get]
+func ExportAccountData(accountService services.AccountServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		archive, err := accountService.ExportData(ctx, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		c.Attachment("task-social-export.zip")
+		return c.Send(archive)
+	}
+}
@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+type AdminActionRequest struct {
+	// why the action was taken, kept in the audit log
+	Reason string `json:"reason"`
+}
+
+type SetUserRoleRequest struct {
+	// user or admin
+	Role   string `json:"role"`
+	Reason string `json:"reason"`
+}
+
+func adminRequest(c *fiber.Ctx, reason string) services.AdminRequest {
+	return services.AdminRequest{
+		AdminID: c.Locals("userID").(string),
+		Reason:  reason,
+		Client:  clientInfo(c),
+	}
+}
+
+func adminError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrAdminReasonRequired), errors.Is(err, services.ErrInvalidRole):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrAdminTargetSelf), errors.Is(err, services.ErrAdminTargetAdmin):
+		status = fiber.StatusForbidden
+	case errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrClubNotFound), errors.Is(err, services.ErrPostNotFound),
+		errors.Is(err, services.ErrItemNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, services.ErrUserAlreadyDisabled), errors.Is(err, services.ErrUserNotDisabled):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(ErrorResponse{
+		Error: err.Error(),
+	})
+}
+
+// AdminSearchUsers godoc
+//
+//	@ID				AdminSearchUsers
+//	@Summary		Search users
+//	@Description	List users whose username or email contains the query, or whose id matches it, newest first. Includes disabled accounts and accounts scheduled for deletion.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			q		query		string	false	"Part of a username or email, or a user id"
+//	@Param			limit	query		int		false	"Page size, 50 by default and at most 200"
+//	@Param			offset	query		int		false	"Number of users to skip"
+//	@Success		200		{array}		repository.SearchUsersRow
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/api/admin/users [get]
+func AdminSearchUsers(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+
+		users, err := adminService.SearchUsers(ctx, c.Query("q"), int64(c.QueryInt("limit")), int64(c.QueryInt("offset")))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(users)
+	}
+}
+
+// AdminSearchClubs godoc
+//
+//	@ID				AdminSearchClubs
+//	@Summary		Search clubs
+//	@Description	List clubs whose name or owner's username contains the query, or whose id matches it, newest first. Private clubs are included.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			q		query		string	false	"Part of a club name or owner username, or a club id"
+//	@Param			limit	query		int		false	"Page size, 50 by default and at most 200"
+//	@Param			offset	query		int		false	"Number of clubs to skip"
+//	@Success		200		{array}		repository.SearchClubsRow
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/api/admin/clubs [get]
+func AdminSearchClubs(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+
+		clubs, err := adminService.SearchClubs(ctx, c.Query("q"), int64(c.QueryInt("limit")), int64(c.QueryInt("offset")))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(clubs)
+	}
+}
+
+// AdminDisableUser godoc
+//
+//	@ID				AdminDisableUser
+//	@Summary		Disable an account
+//	@Description	Log the user out of every session, revoke their access tokens and refuse new logins until the account is enabled again. Admins have to be demoted first.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			user_id	path		string				true	"User ID"
+//	@Param			body	body		AdminActionRequest	true	"Reason for the audit log"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Router			/api/admin/users/{user_id}/disable [post]
+func AdminDisableUser(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params AdminActionRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := adminService.DisableUser(ctx, adminRequest(c, params.Reason), c.Params("user_id")); err != nil {
+			return adminError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "User disabled",
+		})
+	}
+}
+
+// AdminEnableUser godoc
+//
+//	@ID			AdminEnableUser
+//	@Summary	Enable a disabled account
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		user_id	path		string				true	"User ID"
+//	@Param		body	body		AdminActionRequest	true	"Reason for the audit log"
+//	@Success	200		{object}	SuccessResponse
+//	@Failure	400		{object}	ErrorResponse
+//	@Failure	401		{object}	ErrorResponse
+//	@Failure	403		{object}	ErrorResponse
+//	@Failure	404		{object}	ErrorResponse
+//	@Failure	409		{object}	ErrorResponse
+//	@Router		/api/admin/users/{user_id}/enable [post]
+func AdminEnableUser(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params AdminActionRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := adminService.EnableUser(ctx, adminRequest(c, params.Reason), c.Params("user_id")); err != nil {
+			return adminError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "User enabled",
+		})
+	}
+}
+
+// AdminSetUserRole godoc
+//
+//	@ID				AdminSetUserRole
+//	@Summary		Change a user's role
+//	@Description	Grant or remove the admin role. Admins cannot change their own role.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			user_id	path		string				true	"User ID"
+//	@Param			body	body		SetUserRoleRequest	true	"New role and reason for the audit log"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/admin/users/{user_id}/role [put]
+func AdminSetUserRole(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params SetUserRoleRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := adminService.SetUserRole(ctx, adminRequest(c, params.Reason), c.Params("user_id"), params.Role); err != nil {
+			return adminError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "User role updated",
+		})
+	}
+}
+
+// AdminImpersonateUser godoc
+//
+//	@ID				AdminImpersonateUser
+//	@Summary		Impersonate a user
+//	@Description	Get a short lived, read-only access token for the user's account to reproduce what they see. The session cannot be refreshed and is listed in the user's sessions with the admin's id. Admins cannot be impersonated.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			user_id	path		string				true	"User ID"
+//	@Param			body	body		AdminActionRequest	true	"Reason for the audit log"
+//	@Success		200		{object}	services.Impersonation
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/admin/users/{user_id}/impersonate [post]
+func AdminImpersonateUser(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params AdminActionRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		impersonation, err := adminService.ImpersonateUser(ctx, adminRequest(c, params.Reason), c.Params("user_id"))
+		if err != nil {
+			return adminError(c, err)
+		}
+
+		return c.JSON(impersonation)
+	}
+}
+
+// AdminDeleteClub godoc
+//
+//	@ID				AdminDeleteClub
+//	@Summary		Force delete a club
+//	@Description	Delete any club together with its posts, metrics and banner, regardless of who owns it.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string				true	"Club ID"
+//	@Param			body	body		AdminActionRequest	true	"Reason for the audit log"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/admin/clubs/{club_id} [delete]
+func AdminDeleteClub(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params AdminActionRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := adminService.DeleteClub(ctx, adminRequest(c, params.Reason), c.Params("club_id")); err != nil {
+			return adminError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Club deleted",
+		})
+	}
+}
+
+// AdminDeleteClubPost godoc
+//
+//	@ID			AdminDeleteClubPost
+//	@Summary	Force delete a club post
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		post_id	path		string				true	"Post ID"
+//	@Param		body	body		AdminActionRequest	true	"Reason for the audit log"
+//	@Success	200		{object}	SuccessResponse
+//	@Failure	400		{object}	ErrorResponse
+//	@Failure	401		{object}	ErrorResponse
+//	@Failure	403		{object}	ErrorResponse
+//	@Failure	404		{object}	ErrorResponse
+//	@Router		/api/admin/posts/{post_id} [delete]
+func AdminDeleteClubPost(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params AdminActionRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := adminService.DeleteClubPost(ctx, adminRequest(c, params.Reason), c.Params("post_id")); err != nil {
+			return adminError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Post deleted",
+		})
+	}
+}
+
+// AdminDeleteItem godoc
+//
+//	@ID			AdminDeleteItem
+//	@Summary	Force delete a marketplace item
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		item_id	path		string				true	"Item ID"
+//	@Param		body	body		AdminActionRequest	true	"Reason for the audit log"
+//	@Success	200		{object}	SuccessResponse
+//	@Failure	400		{object}	ErrorResponse
+//	@Failure	401		{object}	ErrorResponse
+//	@Failure	403		{object}	ErrorResponse
+//	@Failure	404		{object}	ErrorResponse
+//	@Router		/api/admin/items/{item_id} [delete]
+func AdminDeleteItem(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params AdminActionRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := adminService.DeleteItem(ctx, adminRequest(c, params.Reason), c.Params("item_id")); err != nil {
+			return adminError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Item deleted",
+		})
+	}
+}
+
+// AdminGetAuditLog godoc
+//
+//	@ID				AdminGetAuditLog
+//	@Summary		Get the admin audit log
+//	@Description	List admin actions, newest first. Entries can never be changed or deleted.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			admin_id	query		string	false	"Only actions by this admin"
+//	@Param			target_id	query		string	false	"Only actions on this user, club or post"
+//	@Param			limit		query		int		false	"Page size, 50 by default and at most 200"
+//	@Param			offset		query		int		false	"Number of entries to skip"
+//	@Success		200			{array}		repository.GetAdminAuditLogRow
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Router			/api/admin/audit-log [get]
+func AdminGetAuditLog(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+
+		entries, err := adminService.GetAuditLog(ctx, services.AuditLogFilter{
+			AdminID:  c.Query("admin_id"),
+			TargetID: c.Query("target_id"),
+			Limit:    int64(c.QueryInt("limit")),
+			Offset:   int64(c.QueryInt("offset")),
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(entries)
+	}
+}
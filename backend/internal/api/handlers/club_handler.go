@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"github.com/rhellwege/task-social/config"
 	"github.com/rhellwege/task-social/internal/api/services"
 	"github.com/rhellwege/task-social/internal/db/repository"
 )
@@ -187,6 +191,8 @@ type UpdateClubRequest struct {
 	Description *string `json:"description,omitempty"`
 	BannerImage *string `json:"banner_image,omitempty"`
 	IsPublic    *bool   `json:"is_public,omitempty"`
+	// members rated below this cannot list items in the club, 0 removes the minimum
+	MinListingReputation *float64 `json:"min_listing_reputation,omitempty"`
 }
 
 // UpdateClub godoc
@@ -223,13 +229,20 @@ func UpdateClub(clubService services.ClubServicer) fiber.Handler {
 				Error: "Club name cannot be empty",
 			})
 		}
+		if params.MinListingReputation != nil &&
+			(*params.MinListingReputation < 0 || *params.MinListingReputation > config.MaxReviewRating) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: fmt.Sprintf("Minimum listing reputation must be from 0 to %d", config.MaxReviewRating),
+			})
+		}
 
 		dbParams := repository.UpdateClubParams{
-			Name:        params.Name,
-			Description: params.Description,
-			BannerImage: params.BannerImage,
-			IsPublic:    params.IsPublic,
-			ID:          clubID,
+			Name:                 params.Name,
+			Description:          params.Description,
+			BannerImage:          params.BannerImage,
+			IsPublic:             params.IsPublic,
+			MinListingReputation: params.MinListingReputation,
+			ID:                   clubID,
 		}
 
 		err := clubService.UpdateClub(ctx, userID, dbParams)
@@ -434,13 +447,14 @@ type ClubPostRequest struct {
 //
 //	@ID				CreateClubPost
 //	@Summary		Create a new club post
-//	@Description	Create a new post in a club
+//	@Description	Create a new post in a club. Posts the content filter holds are only shown to the author and the club's moderators until a moderator approves them, the response is then 202 Accepted.
 //	@Tags			Club
 //	@Produce		json
 //	@Param			club_id	path	string			true	"Club ID"
 //	@Param			body	body	ClubPostRequest	true	"Club post"
 //	@Security		ApiKeyAuth
 //	@Success		200	{object}	CreatedResponse
+//	@Success		202	{object}	CreatedResponse
 //	@Failure		400	{object}	ErrorResponse
 //	@Failure		401	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
@@ -458,14 +472,25 @@ func CreateClubPost(clubService services.ClubServicer) fiber.Handler {
 			})
 		}
 
-		postID, err := clubService.CreateClubPost(ctx, userID, clubID, params.TextContent)
+		postID, verdict, err := clubService.CreateClubPost(ctx, userID, clubID, params.TextContent)
 		log.Error(err)
+		if errors.Is(err, services.ErrContentRejected) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 				Error: err.Error(),
 			})
 		}
 
+		if verdict.Action == services.FilterHold {
+			return c.Status(fiber.StatusAccepted).JSON(CreatedResponse{
+				Message: "Post is held for moderator review: " + verdict.Reason,
+				ID:      postID,
+			})
+		}
 		return c.JSON(CreatedResponse{
 			ID: postID,
 		})
@@ -521,6 +546,7 @@ func DeleteClubPost(clubService services.ClubServicer) fiber.Handler {
 //	@Success		200	{object}	repository.ClubPost
 //	@Failure		400	{object}	ErrorResponse
 //	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
 //	@Router			/api/club/{club_id}/post/{post_id} [get]
 func GetClubPost(clubService services.ClubServicer) fiber.Handler {
@@ -531,6 +557,11 @@ func GetClubPost(clubService services.ClubServicer) fiber.Handler {
 		postID := c.Params("post_id")
 
 		post, err := clubService.GetClubPost(ctx, userID, clubID, postID)
+		if errors.Is(err, services.ErrPostNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(&ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(&ErrorResponse{
 				Error: err.Error(),
@@ -13,7 +13,21 @@ type CreatedResponse struct {
 	ID      string `json:"id"`
 }
 
-type SuccessfulLoginResponse struct {
+// returned for new trades, the warning is set when the price estimates of both sides are far apart
+type TradeCreatedResponse struct {
 	Message string `json:"message"`
-	Token   string `json:"token"`
+	ID      string `json:"id"`
+	Warning string `json:"warning,omitempty"`
+}
+
+type SuccessfulLoginResponse struct {
+	Message      string `json:"message"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// returned by login instead of tokens when the user has two factor auth enabled
+type MFARequiredResponse struct {
+	Message  string `json:"message"`
+	MFAToken string `json:"mfa_token"`
 }
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+// GetJWKS godoc
+//
+//	@ID				GetJWKS
+//	@Summary		Get the public keys access tokens are signed with
+//	@Description	JSON Web Key Set for verifying access tokens without calling the API. Keys are listed before they start signing and after they stop, match them by the token's kid header. Empty when tokens are signed with a shared secret.
+//	@Tags			Session
+//	@Produce		json
+//	@Success		200	{object}	services.JWKS
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/.well-known/jwks.json [get]
+func GetJWKS(keyService services.SigningKeyServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		jwks, err := keyService.JWKS(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		// verifiers refetch on an unknown kid, so a short cache is enough
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(jwks)
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+// tooManyAttempts responds with 429 and tells the client when it may try again
+func tooManyAttempts(c *fiber.Ctx, err *services.TooManyAttemptsError) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+	return c.Status(fiber.StatusTooManyRequests).JSON(ErrorResponse{
+		Error: err.Error(),
+	})
+}
+
+// GetUserLoginFailures godoc
+//
+//	@ID				GetUserLoginFailures
+//	@Summary		List failed logins
+//	@Description	List recent failed login and two factor attempts on the authenticated user's account, newest first.
+//	@Tags			User
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		repository.GetUserLoginFailuresRow
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/api/user/login-failures [get]
+func GetUserLoginFailures(loginAttemptService services.LoginAttemptServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		failures, err := loginAttemptService.GetUserLoginFailures(ctx, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(failures)
+	}
+}
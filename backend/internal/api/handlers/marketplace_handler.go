@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"strconv"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/rhellwege/task-social/internal/api/services"
 	"github.com/rhellwege/task-social/internal/db/repository"
@@ -10,6 +14,62 @@ import (
    Item Handlers
    ============================ */
 
+func itemError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case isInvalidItem(err), errors.Is(err, services.ErrTooManyItemImages), errors.Is(err, services.ErrInvalidImage),
+		errors.Is(err, services.ErrReserveOwnItem), errors.Is(err, services.ErrReserveNotMember),
+		errors.Is(err, services.ErrReservationDuration), errors.Is(err, services.ErrWishlistOwnItem),
+		errors.Is(err, services.ErrWishlistFull):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrNotItemOwner):
+		status = fiber.StatusForbidden
+	case errors.Is(err, services.ErrItemNotFound), errors.Is(err, services.ErrItemImageNotFound),
+		errors.Is(err, services.ErrWishlistItemNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, services.ErrItemUnavailable), errors.Is(err, services.ErrItemNotReserved):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(ErrorResponse{Error: err.Error()})
+}
+
+// isInvalidItem reports whether err is about the item's fields rather than who sent it
+func isInvalidItem(err error) bool {
+	return errors.Is(err, services.ErrContentRejected) || errors.Is(err, services.ErrInvalidPriceEstimate) ||
+		errors.Is(err, services.ErrInvalidItemCondition) || errors.Is(err, services.ErrInvalidItemCategory) ||
+		errors.Is(err, services.ErrPickupLocationLength)
+}
+
+// itemFilter reads the listing filters from the query, prices that are not numbers are an error
+func itemFilter(c *fiber.Ctx) (services.ItemFilter, error) {
+	minPrice, err := queryPrice(c, "min_price")
+	if err != nil {
+		return services.ItemFilter{}, err
+	}
+	maxPrice, err := queryPrice(c, "max_price")
+	if err != nil {
+		return services.ItemFilter{}, err
+	}
+	return services.ItemFilter{
+		Sort:     c.Query("sort"),
+		Currency: c.Query("currency"),
+		MinPrice: minPrice,
+		MaxPrice: maxPrice,
+	}, nil
+}
+
+func queryPrice(c *fiber.Ctx, key string) (*float64, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, services.ErrInvalidItemFilter
+	}
+	return &price, nil
+}
+
 // CreateItem godoc
 //
 //	@ID			CreateItem
@@ -35,7 +95,7 @@ func CreateItem(marketplace services.MarketplaceServicer) fiber.Handler {
 
 		itemID, err := marketplace.CreateItem(ctx, userID, req)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+			return itemError(c, err)
 		}
 
 		return c.Status(fiber.StatusCreated).JSON(CreatedResponse{
@@ -47,18 +107,21 @@ func CreateItem(marketplace services.MarketplaceServicer) fiber.Handler {
 
 // UpdateItem godoc
 //
-//	@ID			UpdateItem
-//	@Summary	Update marketplace item
-//	@Tags		Marketplace
-//	@Accept		json
-//	@Produce	json
-//	@Security	ApiKeyAuth
-//	@Param		item_id	path		string						true	"Item ID"
-//	@Param		item	body		repository.UpdateItemParams	true	"Item update"
-//	@Success	200		{object}	SuccessResponse
-//	@Failure	400		{object}	ErrorResponse
-//	@Failure	500		{object}	ErrorResponse
-//	@Router		/api/marketplace/item/{item_id} [put]
+//	@ID				UpdateItem
+//	@Summary		Update marketplace item
+//	@Description	Edits of club items the content filter holds hide the item until one of the club's moderators approves it again. An empty condition, category or pickup_location clears it. The users who wishlisted the item are told over the WebSocket when it becomes available again or its price changes.
+//	@Tags			Marketplace
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			item_id	path		string						true	"Item ID"
+//	@Param			item	body		repository.UpdateItemParams	true	"Item update"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/api/marketplace/item/{item_id} [put]
 func UpdateItem(marketplace services.MarketplaceServicer) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		ctx := c.Context()
@@ -73,7 +136,7 @@ func UpdateItem(marketplace services.MarketplaceServicer) fiber.Handler {
 		params.ID = itemID
 
 		if err := marketplace.UpdateItem(ctx, userID, params); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+			return itemError(c, err)
 		}
 
 		return c.JSON(SuccessResponse{
@@ -91,6 +154,8 @@ func UpdateItem(marketplace services.MarketplaceServicer) fiber.Handler {
 //	@Security	ApiKeyAuth
 //	@Param		item_id	path		string	true	"Item ID"
 //	@Success	200		{object}	SuccessResponse
+//	@Failure	403		{object}	ErrorResponse
+//	@Failure	404		{object}	ErrorResponse
 //	@Failure	500		{object}	ErrorResponse
 //	@Router		/api/marketplace/item/{item_id} [delete]
 func DeleteItem(marketplace services.MarketplaceServicer) fiber.Handler {
@@ -100,7 +165,7 @@ func DeleteItem(marketplace services.MarketplaceServicer) fiber.Handler {
 		itemID := c.Params("item_id")
 
 		if err := marketplace.DeleteItem(ctx, userID, itemID); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+			return itemError(c, err)
 		}
 
 		return c.JSON(SuccessResponse{
@@ -109,24 +174,98 @@ func DeleteItem(marketplace services.MarketplaceServicer) fiber.Handler {
 	}
 }
 
-// GetClubItems
+// AddItemImage godoc
+//
+//	@ID				AddItemImage
+//	@Summary		Upload a photo of a marketplace item
+//	@Description	Saves the photo and a thumbnail of it, both keep the aspect ratio of the upload.
+//	@Tags			Marketplace
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			item_id	path		string	true	"Item ID"
+//	@Param			image	formData	file	true	"Item photo"
+//	@Success		201		{object}	repository.ItemImage
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/api/marketplace/item/{item_id}/images [post]
+func AddItemImage(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		itemID := c.Params("item_id")
+		fileBytes := c.Locals("uploadedImageBytes").([]byte)
+
+		image, err := marketplace.AddItemImage(ctx, userID, itemID, fileBytes)
+		if err != nil {
+			return itemError(c, err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(image)
+	}
+}
+
+// DeleteItemImage godoc
 //
-//	@ID			GetClubItems
-//	@Summary	Get available marketplace items for a club
+//	@ID			DeleteItemImage
+//	@Summary	Delete a photo of a marketplace item
 //	@Tags		Marketplace
 //	@Produce	json
 //	@Security	ApiKeyAuth
-//	@Param		club_id	path		string	true	"Club ID"
-//	@Success	200		{array}		services.ClubMarketplaceItem
-//	@Failure	403		{object}	ErrorResponse
-//	@Router		/api/club/{club_id}/items [get]
+//	@Param		item_id		path		string	true	"Item ID"
+//	@Param		image_id	path		string	true	"Photo ID"
+//	@Success	200			{object}	SuccessResponse
+//	@Failure	403			{object}	ErrorResponse
+//	@Failure	404			{object}	ErrorResponse
+//	@Failure	500			{object}	ErrorResponse
+//	@Router		/api/marketplace/item/{item_id}/images/{image_id} [delete]
+func DeleteItemImage(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := marketplace.DeleteItemImage(ctx, userID, c.Params("item_id"), c.Params("image_id")); err != nil {
+			return itemError(c, err)
+		}
+		return c.JSON(SuccessResponse{
+			Message: "Item photo deleted successfully",
+		})
+	}
+}
+
+// GetClubItems
+//
+//	@ID				GetClubItems
+//	@Summary		Get available marketplace items for a club
+//	@Description	Prices in different currencies are compared as plain numbers, filter by currency to compare like with like. Items without a price estimate are left out by the price filters and listed last when sorting by price.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id		path		string	true	"Club ID"
+//	@Param			sort		query		string	false	"newest, price_asc or price_desc"
+//	@Param			currency	query		string	false	"Only items priced in this currency"
+//	@Param			min_price	query		number	false	"Lowest price estimate"
+//	@Param			max_price	query		number	false	"Highest price estimate"
+//	@Success		200			{array}		services.ClubMarketplaceItem
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/items [get]
 func GetClubItems(marketplace services.MarketplaceServicer) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		ctx := c.Context()
 		userID := c.Locals("userID").(string)
 		clubID := c.Params("club_id")
 
-		items, err := marketplace.GetClubItems(ctx, userID, clubID)
+		filter, err := itemFilter(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		items, err := marketplace.GetClubItems(ctx, userID, clubID, filter)
+		if errors.Is(err, services.ErrInvalidItemFilter) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: err.Error()})
 		}
@@ -136,17 +275,20 @@ func GetClubItems(marketplace services.MarketplaceServicer) fiber.Handler {
 
 // CreateClubItem
 //
-//	@ID			CreateClubItem
-//	@Summary	Post a marketplace item for sale in a club
-//	@Tags		Marketplace
-//	@Accept		json
-//	@Produce	json
-//	@Security	ApiKeyAuth
-//	@Param		club_id	path		string						true	"Club ID"
-//	@Param		item	body		services.CreateItemRequest	true	"Item data"
-//	@Success	201		{object}	CreatedResponse
-//	@Failure	403		{object}	ErrorResponse
-//	@Router		/api/club/{club_id}/items [post]
+//	@ID				CreateClubItem
+//	@Summary		Post a marketplace item for sale in a club
+//	@Description	Items the content filter holds are not listed until one of the club's moderators approves them, the response is then 202 Accepted. Members whose reputation is below the club's minimum listing reputation cannot list items, members who were never reviewed can.
+//	@Tags			Marketplace
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string						true	"Club ID"
+//	@Param			item	body		services.CreateItemRequest	true	"Item data"
+//	@Success		201		{object}	CreatedResponse
+//	@Success		202		{object}	CreatedResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/items [post]
 func CreateClubItem(marketplace services.MarketplaceServicer) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		ctx := c.Context()
@@ -158,14 +300,872 @@ func CreateClubItem(marketplace services.MarketplaceServicer) fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "invalid request body"})
 		}
 
-		id, err := marketplace.CreateClubItem(ctx, userID, clubID, req)
+		id, verdict, err := marketplace.CreateClubItem(ctx, userID, clubID, req)
+		if isInvalidItem(err) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: err.Error()})
 		}
 
+		if verdict.Action == services.FilterHold {
+			return c.Status(fiber.StatusAccepted).JSON(CreatedResponse{
+				Message: "Item is held for moderator review: " + verdict.Reason,
+				ID:      id,
+			})
+		}
+
 		return c.Status(fiber.StatusCreated).JSON(CreatedResponse{
 			Message: "Item created successfully",
 			ID:      id,
 		})
 	}
 }
+
+type ReserveItemRequest struct {
+	// the member of the item's club it is held for
+	UserID string `json:"user_id"`
+	// 48 hours by default and at most a week
+	ExpiresInHours int `json:"expires_in_hours,omitempty"`
+}
+
+type ItemReservationResponse struct {
+	ReservedFor   string    `json:"reserved_for"`
+	ReservedUntil time.Time `json:"reserved_until"`
+}
+
+// ReserveItem godoc
+//
+//	@ID				ReserveItem
+//	@Summary		Reserve an item for a user
+//	@Description	Hold your item for a member of its club. Until the reservation ends the item is only listed and found for them and only they can get it in a trade. Reserving it again replaces the reservation. The user is told over the WebSocket.
+//	@Tags			Marketplace
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			item_id		path		string				true	"Item ID"
+//	@Param			reservation	body		ReserveItemRequest	true	"Who to reserve the item for and how long"
+//	@Success		200			{object}	ItemReservationResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/marketplace/item/{item_id}/reservation [put]
+func ReserveItem(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req ReserveItemRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "invalid request body"})
+		}
+
+		duration := time.Duration(req.ExpiresInHours) * time.Hour
+		until, err := marketplace.ReserveItem(ctx, userID, c.Params("item_id"), req.UserID, duration)
+		if err != nil {
+			return itemError(c, err)
+		}
+
+		return c.JSON(ItemReservationResponse{
+			ReservedFor:   req.UserID,
+			ReservedUntil: until,
+		})
+	}
+}
+
+// ReleaseItemReservation godoc
+//
+//	@ID				ReleaseItemReservation
+//	@Summary		End an item's reservation
+//	@Description	Make your reserved item available to everyone again before the reservation ends. The users who wishlisted it are told over the WebSocket.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			item_id	path		string	true	"Item ID"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Router			/api/marketplace/item/{item_id}/reservation [delete]
+func ReleaseItemReservation(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := marketplace.ReleaseItemReservation(ctx, userID, c.Params("item_id")); err != nil {
+			return itemError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Reservation ended",
+		})
+	}
+}
+
+/* ============================
+   Wishlist Handlers
+   ============================ */
+
+// GetWishlist godoc
+//
+//	@ID				GetWishlist
+//	@Summary		Get your wishlist
+//	@Description	List the items you wishlisted in the clubs you are a member of, the latest first. Items that are unavailable or reserved for someone else are listed too.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		services.WishlistItem
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/api/marketplace/wishlist [get]
+func GetWishlist(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		items, err := marketplace.GetWishlist(ctx, userID)
+		if err != nil {
+			return itemError(c, err)
+		}
+		return c.JSON(items)
+	}
+}
+
+// AddToWishlist godoc
+//
+//	@ID				AddToWishlist
+//	@Summary		Add an item to your wishlist
+//	@Description	Wishlist an item of another member of your clubs. You are told over the WebSocket when it becomes available again or its price changes.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			item_id	path		string	true	"Item ID"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/marketplace/wishlist/{item_id} [put]
+func AddToWishlist(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := marketplace.AddToWishlist(ctx, userID, c.Params("item_id")); err != nil {
+			return itemError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Item added to wishlist",
+		})
+	}
+}
+
+// RemoveFromWishlist godoc
+//
+//	@ID			RemoveFromWishlist
+//	@Summary	Remove an item from your wishlist
+//	@Tags		Marketplace
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		item_id	path		string	true	"Item ID"
+//	@Success	200		{object}	SuccessResponse
+//	@Failure	404		{object}	ErrorResponse
+//	@Router		/api/marketplace/wishlist/{item_id} [delete]
+func RemoveFromWishlist(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := marketplace.RemoveFromWishlist(ctx, userID, c.Params("item_id")); err != nil {
+			return itemError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Item removed from wishlist",
+		})
+	}
+}
+
+// GetItemWishlisters godoc
+//
+//	@ID				GetItemWishlisters
+//	@Summary		See who wishlisted your item
+//	@Description	List the users who wishlisted your item, the earliest first.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			item_id	path		string	true	"Item ID"
+//	@Success		200		{array}		repository.GetItemWishlistersRow
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/marketplace/item/{item_id}/wishlisters [get]
+func GetItemWishlisters(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		users, err := marketplace.GetItemWishlisters(ctx, userID, c.Params("item_id"))
+		if err != nil {
+			return itemError(c, err)
+		}
+		return c.JSON(users)
+	}
+}
+
+/* ============================
+   Search Handlers
+   ============================ */
+
+func searchError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidItemFilter), errors.Is(err, services.ErrInvalidItemCategory),
+		errors.Is(err, services.ErrInvalidItemCondition), errors.Is(err, services.ErrSearchQueryLength),
+		errors.Is(err, services.ErrSavedSearchName), errors.Is(err, services.ErrTooManySavedSearches):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrSavedSearchNotFound):
+		status = fiber.StatusNotFound
+	}
+	return c.Status(status).JSON(ErrorResponse{Error: err.Error()})
+}
+
+// SearchItems godoc
+//
+//	@ID				SearchItems
+//	@Summary		Search the marketplace
+//	@Description	Searches the available items of every club the user is a member of. Every word of the query has to start a word in the item's name or description. Items without a price estimate are left out by the price filters and listed last when sorting by price.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			q			query		string	false	"Words to look for"
+//	@Param			category	query		string	false	"Only items in this category"
+//	@Param			condition	query		string	false	"new, like_new, good, fair or poor"
+//	@Param			owner_id	query		string	false	"Only items of this user"
+//	@Param			currency	query		string	false	"Only items priced in this currency"
+//	@Param			min_price	query		number	false	"Lowest price estimate"
+//	@Param			max_price	query		number	false	"Highest price estimate"
+//	@Param			sort		query		string	false	"newest, price_asc or price_desc"
+//	@Param			limit		query		int		false	"Page size, 50 by default and at most 200"
+//	@Param			offset		query		int		false	"Number of items to skip"
+//	@Success		200			{array}		services.MarketplaceSearchItem
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/api/marketplace/search [get]
+func SearchItems(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		filter, err := itemFilter(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		items, err := marketplace.SearchItems(ctx, userID, services.MarketplaceSearch{
+			ItemFilter: filter,
+			Query:      c.Query("q"),
+			Category:   c.Query("category"),
+			Condition:  c.Query("condition"),
+			OwnerID:    c.Query("owner_id"),
+			Limit:      int64(c.QueryInt("limit")),
+			Offset:     int64(c.QueryInt("offset")),
+		})
+		if err != nil {
+			return searchError(c, err)
+		}
+		return c.JSON(items)
+	}
+}
+
+// CreateSavedSearch godoc
+//
+//	@ID				CreateSavedSearch
+//	@Summary		Save a marketplace search
+//	@Description	Whenever an item listed in one of the user's clubs matches the search, the user gets a saved_search_match event over the WebSocket.
+//	@Tags			Marketplace
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			search	body		services.SavedSearchRequest	true	"Search to save"
+//	@Success		201		{object}	CreatedResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/api/marketplace/saved-searches [post]
+func CreateSavedSearch(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req services.SavedSearchRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "invalid request body"})
+		}
+
+		id, err := marketplace.CreateSavedSearch(ctx, userID, req)
+		if err != nil {
+			return searchError(c, err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(CreatedResponse{
+			Message: "Search saved",
+			ID:      id,
+		})
+	}
+}
+
+// GetSavedSearches godoc
+//
+//	@ID			GetSavedSearches
+//	@Summary	List the user's saved marketplace searches
+//	@Tags		Marketplace
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Success	200	{array}		repository.SavedSearch
+//	@Failure	500	{object}	ErrorResponse
+//	@Router		/api/marketplace/saved-searches [get]
+func GetSavedSearches(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		searches, err := marketplace.GetSavedSearches(ctx, userID)
+		if err != nil {
+			return searchError(c, err)
+		}
+		return c.JSON(searches)
+	}
+}
+
+// DeleteSavedSearch godoc
+//
+//	@ID			DeleteSavedSearch
+//	@Summary	Delete a saved marketplace search
+//	@Tags		Marketplace
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		search_id	path		string	true	"Saved search ID"
+//	@Success	200			{object}	SuccessResponse
+//	@Failure	404			{object}	ErrorResponse
+//	@Failure	500			{object}	ErrorResponse
+//	@Router		/api/marketplace/saved-searches/{search_id} [delete]
+func DeleteSavedSearch(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := marketplace.DeleteSavedSearch(ctx, userID, c.Params("search_id")); err != nil {
+			return searchError(c, err)
+		}
+		return c.JSON(SuccessResponse{
+			Message: "Saved search deleted",
+		})
+	}
+}
+
+/* ============================
+   Auction Handlers
+   ============================ */
+
+func auctionError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidAuction), errors.Is(err, services.ErrAuctionEndTime),
+		errors.Is(err, services.ErrBidOwnAuction), errors.Is(err, services.ErrBidTooLow):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrNotItemOwner), errors.Is(err, services.ErrNotAuctionSeller):
+		status = fiber.StatusForbidden
+	case errors.Is(err, services.ErrItemNotFound), errors.Is(err, services.ErrAuctionNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, services.ErrItemUnavailable), errors.Is(err, services.ErrAuctionClosed),
+		errors.Is(err, services.ErrAuctionHasBids), errors.Is(err, services.ErrAlreadyHighestBidder):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(ErrorResponse{Error: err.Error()})
+}
+
+type PlaceBidRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+// CreateAuction godoc
+//
+//	@ID				CreateAuction
+//	@Summary		Put an item up for auction
+//	@Description	Auction your available club item to the club's members. Until the auction ends the item cannot be traded or reserved. When it ends the item goes to the highest bidder if the bid reached the reserve price, the payment happens outside the app.
+//	@Tags			Marketplace
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			item_id	path		string					true	"Item ID"
+//	@Param			auction	body		services.AuctionRequest	true	"Prices and end of the auction"
+//	@Success		201		{object}	CreatedResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Router			/api/marketplace/item/{item_id}/auction [post]
+func CreateAuction(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req services.AuctionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "invalid request body"})
+		}
+
+		auctionID, err := marketplace.CreateAuction(ctx, userID, c.Params("item_id"), req)
+		if err != nil {
+			return auctionError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(CreatedResponse{
+			Message: "Auction created",
+			ID:      auctionID,
+		})
+	}
+}
+
+// GetClubAuctions godoc
+//
+//	@ID				GetClubAuctions
+//	@Summary		Get the open auctions of a club
+//	@Description	List the club's open auctions, the ones ending first first. The bids are left out.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Success		200		{array}		services.AuctionDetails
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/auctions [get]
+func GetClubAuctions(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		auctions, err := marketplace.GetClubAuctions(ctx, userID, c.Params("club_id"))
+		if err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(auctions)
+	}
+}
+
+// GetAuction godoc
+//
+//	@ID				GetAuction
+//	@Summary		Get an auction with its bids
+//	@Description	The reserve price is only shown to the seller.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			auction_id	path		string	true	"Auction ID"
+//	@Success		200			{object}	services.AuctionDetails
+//	@Failure		404			{object}	ErrorResponse
+//	@Router			/api/marketplace/auctions/{auction_id} [get]
+func GetAuction(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		auction, err := marketplace.GetAuction(ctx, userID, c.Params("auction_id"))
+		if err != nil {
+			return auctionError(c, err)
+		}
+		return c.JSON(auction)
+	}
+}
+
+// PlaceBid godoc
+//
+//	@ID				PlaceBid
+//	@Summary		Bid on an auction
+//	@Description	The first bid has to be at least the start price, later ones the highest bid plus the increment. A bid in the last minutes extends the auction. The club's members are told about the bid over the WebSocket.
+//	@Tags			Marketplace
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			auction_id	path		string			true	"Auction ID"
+//	@Param			bid			body		PlaceBidRequest	true	"How much to bid"
+//	@Success		200			{object}	services.AuctionDetails
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/marketplace/auctions/{auction_id}/bids [post]
+func PlaceBid(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req PlaceBidRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "invalid request body"})
+		}
+
+		auction, err := marketplace.PlaceBid(ctx, userID, c.Params("auction_id"), req.Amount)
+		if err != nil {
+			return auctionError(c, err)
+		}
+		return c.JSON(auction)
+	}
+}
+
+// CancelAuction godoc
+//
+//	@ID				CancelAuction
+//	@Summary		Cancel an auction nobody bid on
+//	@Description	The item can be traded again. The club's members are told over the WebSocket.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			auction_id	path		string	true	"Auction ID"
+//	@Success		200			{object}	SuccessResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/marketplace/auctions/{auction_id}/cancel [post]
+func CancelAuction(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := marketplace.CancelAuction(ctx, userID, c.Params("auction_id")); err != nil {
+			return auctionError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Auction cancelled",
+		})
+	}
+}
+
+/* ============================
+   Trade Handlers
+   ============================ */
+
+func tradeError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrTradeOwnItem), errors.Is(err, services.ErrTradeItemOwners),
+		errors.Is(err, services.ErrTradeNoResponder), errors.Is(err, services.ErrTradeEmptySide),
+		errors.Is(err, services.ErrTooManyTradeItems), errors.Is(err, services.ErrDuplicateTradeItem),
+		errors.Is(err, services.ErrInvalidTradePoints), errors.Is(err, services.ErrTradeClubRequired),
+		errors.Is(err, services.ErrTradeNotClubMember), errors.Is(err, services.ErrCounterOtherUser),
+		errors.Is(err, services.ErrInvalidTradeStatus), errors.Is(err, services.ErrInvalidRating),
+		errors.Is(err, services.ErrReviewCommentLength), errors.Is(err, services.ErrContentRejected):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrTradeNotResponder), errors.Is(err, services.ErrTradeNotProposer):
+		status = fiber.StatusForbidden
+	case errors.Is(err, services.ErrTradeNotFound), errors.Is(err, services.ErrTradeItemNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, services.ErrTradeItemUnavailable), errors.Is(err, services.ErrTradeAlreadyProposed),
+		errors.Is(err, services.ErrTradeNotPending), errors.Is(err, services.ErrNotEnoughPoints),
+		errors.Is(err, services.ErrTradeNotCompleted), errors.Is(err, services.ErrAlreadyReviewed):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(ErrorResponse{
+		Error: err.Error(),
+	})
+}
+
+// ProposeTrade godoc
+//
+//	@ID				ProposeTrade
+//	@Summary		Propose a trade
+//	@Description	Offer your items, club points or both for another user's items or points. Every item must be available, club items can only be requested by members of the club. Only one side can pay points, both users must be members of the club whose points are traded. The response warns when the price estimates of both sides are far apart.
+//	@Tags			Marketplace
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			trade	body		services.TradeRequest	true	"Offered and requested items and points"
+//	@Success		201		{object}	TradeCreatedResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Router			/api/marketplace/trades [post]
+func ProposeTrade(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req services.TradeRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		tradeID, warning, err := marketplace.ProposeTrade(ctx, userID, req)
+		if err != nil {
+			return tradeError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(TradeCreatedResponse{
+			Message: "Trade proposed",
+			ID:      tradeID,
+			Warning: warning,
+		})
+	}
+}
+
+// CounterTrade godoc
+//
+//	@ID				CounterTrade
+//	@Summary		Counter a trade
+//	@Description	Answer a trade offered to you with a different one, which goes back to the proposer. The original trade is closed as countered.
+//	@Tags			Marketplace
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			trade_id	path		string					true	"Trade ID"
+//	@Param			trade		body		services.TradeRequest	true	"Offered and requested items and points"
+//	@Success		201			{object}	TradeCreatedResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/marketplace/trades/{trade_id}/counter [post]
+func CounterTrade(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req services.TradeRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		tradeID, warning, err := marketplace.CounterTrade(ctx, userID, c.Params("trade_id"), req)
+		if err != nil {
+			return tradeError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(TradeCreatedResponse{
+			Message: "Counter-offer proposed",
+			ID:      tradeID,
+			Warning: warning,
+		})
+	}
+}
+
+// AcceptTrade godoc
+//
+//	@ID				AcceptTrade
+//	@Summary		Accept a trade
+//	@Description	Hand over every item and the points in one step. Fails if an item changed owner or is no longer available, or the payer has too few points. Other pending trades sharing an item are cancelled.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			trade_id	path		string	true	"Trade ID"
+//	@Success		200			{object}	SuccessResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/marketplace/trades/{trade_id}/accept [post]
+func AcceptTrade(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := marketplace.AcceptTrade(ctx, userID, c.Params("trade_id")); err != nil {
+			return tradeError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Trade accepted",
+		})
+	}
+}
+
+// RejectTrade godoc
+//
+//	@ID			RejectTrade
+//	@Summary	Reject a trade
+//	@Tags		Marketplace
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		trade_id	path		string	true	"Trade ID"
+//	@Success	200			{object}	SuccessResponse
+//	@Failure	403			{object}	ErrorResponse
+//	@Failure	404			{object}	ErrorResponse
+//	@Failure	409			{object}	ErrorResponse
+//	@Router		/api/marketplace/trades/{trade_id}/reject [post]
+func RejectTrade(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := marketplace.RejectTrade(ctx, userID, c.Params("trade_id")); err != nil {
+			return tradeError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Trade rejected",
+		})
+	}
+}
+
+// CancelTrade godoc
+//
+//	@ID			CancelTrade
+//	@Summary	Cancel a trade you proposed
+//	@Tags		Marketplace
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		trade_id	path		string	true	"Trade ID"
+//	@Success	200			{object}	SuccessResponse
+//	@Failure	403			{object}	ErrorResponse
+//	@Failure	404			{object}	ErrorResponse
+//	@Failure	409			{object}	ErrorResponse
+//	@Router		/api/marketplace/trades/{trade_id}/cancel [post]
+func CancelTrade(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := marketplace.CancelTrade(ctx, userID, c.Params("trade_id")); err != nil {
+			return tradeError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Trade cancelled",
+		})
+	}
+}
+
+// GetTrade godoc
+//
+//	@ID			GetTrade
+//	@Summary	Get a trade you are part of
+//	@Tags		Marketplace
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		trade_id	path		string	true	"Trade ID"
+//	@Success	200			{object}	services.TradeDetails
+//	@Failure	404			{object}	ErrorResponse
+//	@Router		/api/marketplace/trades/{trade_id} [get]
+func GetTrade(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		trade, err := marketplace.GetTrade(ctx, userID, c.Params("trade_id"))
+		if err != nil {
+			return tradeError(c, err)
+		}
+		return c.JSON(trade)
+	}
+}
+
+// GetIncomingTrades godoc
+//
+//	@ID				GetIncomingTrades
+//	@Summary		Get trades offered to you
+//	@Description	List the trades other users offered you, the newest first.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			status	query		string	false	"pending, accepted, rejected, cancelled or countered"
+//	@Success		200		{array}		services.TradeDetails
+//	@Failure		400		{object}	ErrorResponse
+//	@Router			/api/marketplace/trades/incoming [get]
+func GetIncomingTrades(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		trades, err := marketplace.GetIncomingTrades(ctx, userID, c.Query("status"))
+		if err != nil {
+			return tradeError(c, err)
+		}
+		return c.JSON(trades)
+	}
+}
+
+// GetOutgoingTrades godoc
+//
+//	@ID				GetOutgoingTrades
+//	@Summary		Get trades you proposed
+//	@Description	List the trades you offered other users, the newest first.
+//	@Tags			Marketplace
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			status	query		string	false	"pending, accepted, rejected, cancelled or countered"
+//	@Success		200		{array}		services.TradeDetails
+//	@Failure		400		{object}	ErrorResponse
+//	@Router			/api/marketplace/trades/outgoing [get]
+func GetOutgoingTrades(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		trades, err := marketplace.GetOutgoingTrades(ctx, userID, c.Query("status"))
+		if err != nil {
+			return tradeError(c, err)
+		}
+		return c.JSON(trades)
+	}
+}
+
+/* ============================
+   Review Handlers
+   ============================ */
+
+// ReviewTrade godoc
+//
+//	@ID				ReviewTrade
+//	@Summary		Review the other party of a trade
+//	@Description	Rate the other party of an accepted trade from 1 to 5 with an optional comment. Each party can review a trade once, the ratings make up the other party's reputation. Both parties are told over the WebSocket.
+//	@Tags			Marketplace
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			trade_id	path		string						true	"Trade ID"
+//	@Param			review		body		services.TradeReviewRequest	true	"Rating and comment"
+//	@Success		201			{object}	SuccessResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/marketplace/trades/{trade_id}/review [post]
+func ReviewTrade(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req services.TradeReviewRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "invalid request body"})
+		}
+
+		if err := marketplace.ReviewTrade(ctx, userID, c.Params("trade_id"), req); err != nil {
+			return tradeError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(SuccessResponse{
+			Message: "Review saved",
+		})
+	}
+}
+
+// GetUserReviews godoc
+//
+//	@ID				GetUserReviews
+//	@Summary		Get the reviews a user received
+//	@Description	List the reviews other users left the user after trading with them, the newest first.
+//	@Tags			User
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string	true	"User ID"
+//	@Param			limit	query		int		false	"Page size, 50 by default and at most 200"
+//	@Param			offset	query		int		false	"Number of reviews to skip"
+//	@Success		200		{array}		repository.GetUserReviewsRow
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/api/user/{id}/reviews [get]
+func GetUserReviews(marketplace services.MarketplaceServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+
+		reviews, err := marketplace.GetUserReviews(ctx, c.Params("id"), int64(c.QueryInt("limit")), int64(c.QueryInt("offset")))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(reviews)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+type DirectMessageRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// SendDirectMessage godoc
+//
+//	@ID				SendDirectMessage
+//	@Summary		Send a direct message
+//	@Description	Send a message to a user. Users can write to their friends, the members of their clubs and users they already exchanged messages with. Both users' connections subscribed to the conversation, dm:<the other user's id>, get a new_direct_message event.
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string					true	"ID of the recipient"
+//	@Param			body	body		DirectMessageRequest	true	"Message"
+//	@Success		200		{object}	CreatedResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/api/user/{id}/messages [post]
+func SendDirectMessage(messageService services.MessageServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		recipientID := c.Params("id")
+
+		var params DirectMessageRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		messageID, err := messageService.SendDirectMessage(ctx, userID, recipientID, params.Content)
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+		case errors.Is(err, services.ErrMessagingNotAllowed):
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: err.Error()})
+		case errors.Is(err, services.ErrMessageToSelf), errors.Is(err, services.ErrMessageLength),
+			errors.Is(err, services.ErrContentRejected):
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		return c.JSON(CreatedResponse{
+			Message: "Message sent",
+			ID:      messageID,
+		})
+	}
+}
+
+// GetDirectMessages godoc
+//
+//	@ID				GetDirectMessages
+//	@Summary		Get a conversation
+//	@Description	Get the messages the authenticated user exchanged with a user, oldest first.
+//	@Tags			User
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"ID of the other user"
+//	@Success		200	{array}		repository.GetDirectMessagesRow
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/api/user/{id}/messages [get]
+func GetDirectMessages(messageService services.MessageServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		otherID := c.Params("id")
+
+		messages, err := messageService.GetDirectMessages(ctx, userID, otherID)
+		if errors.Is(err, services.ErrConversationNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		return c.JSON(messages)
+	}
+}
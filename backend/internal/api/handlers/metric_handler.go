@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/rhellwege/task-social/internal/api/services"
 	"github.com/rhellwege/task-social/internal/db/repository"
@@ -152,7 +154,7 @@ func DeleteMetric(metricService services.MetricServicer) fiber.Handler {
 //
 //	@ID				CreateMetricEntry
 //	@Summary		Create a new metric entry
-//	@Description	Create a new metric entry for the current instance of a metric.
+//	@Description	Create a new metric entry for the current instance of a metric. Only for members of the metric's club, who earn points for it and a bonus when they turned in the previous instance as well.
 //	@Tags			Metric
 //	@Accept			json
 //	@Produce		json
@@ -162,6 +164,9 @@ func DeleteMetric(metricService services.MetricServicer) fiber.Handler {
 //	@Success		201			{object}	CreatedResponse
 //	@Failure		400			{object}	ErrorResponse
 //	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
 //	@Failure		500			{object}	ErrorResponse
 //	@Router			/api/metric/{metric_id}/entry [post]
 func CreateMetricEntry(metricService services.MetricServicer) fiber.Handler {
@@ -179,7 +184,16 @@ func CreateMetricEntry(metricService services.MetricServicer) fiber.Handler {
 
 		entryID, err := metricService.CreateMetricEntry(ctx, userID, metricID, params)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			status := fiber.StatusInternalServerError
+			switch {
+			case errors.Is(err, services.ErrNotClubMember):
+				status = fiber.StatusForbidden
+			case errors.Is(err, services.ErrMetricNotFound):
+				status = fiber.StatusNotFound
+			case errors.Is(err, services.ErrMetricEntryExists):
+				status = fiber.StatusConflict
+			}
+			return c.Status(status).JSON(ErrorResponse{
 				Error: err.Error(),
 			})
 		}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token"`
+	// TOTP code or recovery code
+	Code string `json:"code"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// LoginMFA godoc
+//
+//	@ID				LoginMFA
+//	@Summary		Finish a two factor login
+//	@Description	Exchange the MFA token from /api/login and a TOTP or recovery code for an access and refresh token pair.
+//	@Tags			MFA
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		MFALoginRequest	true	"MFA token and code"
+//	@Success		200		{object}	SuccessfulLoginResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse
+//	@Router			/api/login/mfa [post]
+func LoginMFA(userService services.UserServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params MFALoginRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		tokens, err := userService.CompleteMFALogin(ctx, params.MFAToken, params.Code, clientInfo(c))
+		var tooMany *services.TooManyAttemptsError
+		if errors.As(err, &tooMany) {
+			return tooManyAttempts(c, tooMany)
+		}
+		if errors.Is(err, services.ErrAccountDisabled) {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessfulLoginResponse{
+			Message:      "User logged in successfully",
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+		})
+	}
+}
+
+// EnrollTOTP godoc
+//
+//	@ID				EnrollTOTP
+//	@Summary		Start two factor enrollment
+//	@Description	Generate a new TOTP secret and provisioning URI for an authenticator app. Two factor auth is enabled once a code is confirmed.
+//	@Tags			MFA
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	services.TOTPEnrollment
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Router			/api/user/mfa/totp [post]
+func EnrollTOTP(userService services.UserServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		enrollment, err := userService.EnrollTOTP(ctx, userID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(enrollment)
+	}
+}
+
+// EnableTOTP godoc
+//
+//	@ID				EnableTOTP
+//	@Summary		Confirm two factor enrollment
+//	@Description	Enable two factor auth with a code from the enrolled secret. Returns one time recovery codes, they are not shown again.
+//	@Tags			MFA
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		MFACodeRequest	true	"TOTP code"
+//	@Success		200		{object}	RecoveryCodesResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Router			/api/user/mfa/totp/enable [post]
+func EnableTOTP(userService services.UserServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		var params MFACodeRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		codes, err := userService.EnableTOTP(ctx, userID, params.Code)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(RecoveryCodesResponse{
+			Message:       "Two factor auth enabled successfully",
+			RecoveryCodes: codes,
+		})
+	}
+}
+
+// DisableTOTP godoc
+//
+//	@ID				DisableTOTP
+//	@Summary		Disable two factor auth
+//	@Description	Disable two factor auth with a TOTP or recovery code. All recovery codes are deleted.
+//	@Tags			MFA
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		MFACodeRequest	true	"TOTP or recovery code"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Router			/api/user/mfa/totp [delete]
+func DisableTOTP(userService services.UserServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		var params MFACodeRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := userService.DisableTOTP(ctx, userID, params.Code); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Two factor auth disabled successfully",
+		})
+	}
+}
+
+// RegenerateRecoveryCodes godoc
+//
+//	@ID				RegenerateRecoveryCodes
+//	@Summary		Regenerate recovery codes
+//	@Description	Replace all recovery codes with new ones. Requires a TOTP code, recovery codes are not accepted.
+//	@Tags			MFA
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		MFACodeRequest	true	"TOTP code"
+//	@Success		200		{object}	RecoveryCodesResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Router			/api/user/mfa/recovery-codes [post]
+func RegenerateRecoveryCodes(userService services.UserServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		var params MFACodeRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		codes, err := userService.RegenerateRecoveryCodes(ctx, userID, params.Code)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(RecoveryCodesResponse{
+			Message:       "Recovery codes regenerated successfully",
+			RecoveryCodes: codes,
+		})
+	}
+}
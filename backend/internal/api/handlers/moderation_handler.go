@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+func moderationError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidBlockedTerm), errors.Is(err, services.ErrInvalidBlockedTermAction),
+		errors.Is(err, services.ErrTooManyBlockedTerms):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrNotClubModerator):
+		status = fiber.StatusForbidden
+	case errors.Is(err, services.ErrBlockedTermNotFound), errors.Is(err, services.ErrHeldContentNotFound):
+		status = fiber.StatusNotFound
+	}
+	return c.Status(status).JSON(ErrorResponse{
+		Error: err.Error(),
+	})
+}
+
+// GetClubBlocklist godoc
+//
+//	@ID				GetClubBlocklist
+//	@Summary		Get the club's blocklist
+//	@Description	List the words and phrases the club rejects or holds for review in posts and items, on top of the site-wide lists. Only for moderators and the owner.
+//	@Tags			Moderation
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Success		200		{array}		repository.ClubBlockedTerm
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/blocklist [get]
+func GetClubBlocklist(moderationService services.ModerationServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		terms, err := moderationService.GetBlockedTerms(ctx, userID, c.Params("club_id"))
+		if err != nil {
+			return moderationError(c, err)
+		}
+
+		return c.JSON(terms)
+	}
+}
+
+// SetClubBlockedTerm godoc
+//
+//	@ID				SetClubBlockedTerm
+//	@Summary		Block a term in the club
+//	@Description	Add a word or phrase to the club's blocklist or change what happens to content with it. Terms match whole words regardless of case and punctuation.
+//	@Tags			Moderation
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string						true	"Club ID"
+//	@Param			body	body		services.BlockedTermRequest	true	"Term and action"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/blocklist [put]
+func SetClubBlockedTerm(moderationService services.ModerationServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		var params services.BlockedTermRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := moderationService.SetBlockedTerm(ctx, userID, c.Params("club_id"), params); err != nil {
+			return moderationError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Term blocked",
+		})
+	}
+}
+
+// RemoveClubBlockedTerm godoc
+//
+//	@ID				RemoveClubBlockedTerm
+//	@Summary		Unblock a term in the club
+//	@Description	Remove a word or phrase from the club's blocklist.
+//	@Tags			Moderation
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Param			term	query		string	true	"Blocked term"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/blocklist [delete]
+func RemoveClubBlockedTerm(moderationService services.ModerationServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := moderationService.RemoveBlockedTerm(ctx, userID, c.Params("club_id"), c.Query("term")); err != nil {
+			return moderationError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Term unblocked",
+		})
+	}
+}
+
+// GetHeldContent godoc
+//
+//	@ID				GetHeldContent
+//	@Summary		Get held posts and items
+//	@Description	List the club's posts and items the content filter held for review, the oldest first. Only for moderators and the owner.
+//	@Tags			Moderation
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Success		200		{object}	services.HeldContent
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/held [get]
+func GetHeldContent(moderationService services.ModerationServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		held, err := moderationService.GetHeldContent(ctx, userID, c.Params("club_id"))
+		if err != nil {
+			return moderationError(c, err)
+		}
+
+		return c.JSON(held)
+	}
+}
+
+// ApproveHeldPost godoc
+//
+//	@ID				ApproveHeldPost
+//	@Summary		Approve a held post
+//	@Description	Show a held post to the club and send it to the members.
+//	@Tags			Moderation
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Param			post_id	path		string	true	"Post ID"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/held/posts/{post_id}/approve [post]
+func ApproveHeldPost(moderationService services.ModerationServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := moderationService.ApprovePost(ctx, userID, c.Params("club_id"), c.Params("post_id")); err != nil {
+			return moderationError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Post approved",
+		})
+	}
+}
+
+// RejectHeldPost godoc
+//
+//	@ID				RejectHeldPost
+//	@Summary		Reject a held post
+//	@Description	Delete a held post.
+//	@Tags			Moderation
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Param			post_id	path		string	true	"Post ID"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/held/posts/{post_id} [delete]
+func RejectHeldPost(moderationService services.ModerationServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := moderationService.RejectPost(ctx, userID, c.Params("club_id"), c.Params("post_id")); err != nil {
+			return moderationError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Post rejected",
+		})
+	}
+}
+
+// ApproveHeldItem godoc
+//
+//	@ID				ApproveHeldItem
+//	@Summary		Approve a held item
+//	@Description	List a held item in the club's marketplace.
+//	@Tags			Moderation
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Param			item_id	path		string	true	"Item ID"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/held/items/{item_id}/approve [post]
+func ApproveHeldItem(moderationService services.ModerationServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := moderationService.ApproveItem(ctx, userID, c.Params("club_id"), c.Params("item_id")); err != nil {
+			return moderationError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Item approved",
+		})
+	}
+}
+
+// RejectHeldItem godoc
+//
+//	@ID				RejectHeldItem
+//	@Summary		Reject a held item
+//	@Description	Delete a held item.
+//	@Tags			Moderation
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Param			item_id	path		string	true	"Item ID"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/held/items/{item_id} [delete]
+func RejectHeldItem(moderationService services.ModerationServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := moderationService.RejectItem(ctx, userID, c.Params("club_id"), c.Params("item_id")); err != nil {
+			return moderationError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Item rejected",
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	// keep it on the device that started the login and send it with the callback
+	CodeVerifier string `json:"code_verifier"`
+}
+
+type OIDCCallbackRequest struct {
+	Code         string `json:"code"`
+	State        string `json:"state"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// GetOIDCProviders godoc
+//
+//	@ID				GetOIDCProviders
+//	@Summary		List identity providers
+//	@Description	List the names of the OpenID Connect providers users can log in with.
+//	@Tags			OIDC
+//	@Produce		json
+//	@Success		200	{object}	OIDCProvidersResponse
+//	@Router			/api/oidc/providers [get]
+func GetOIDCProviders(oidcService services.OIDCServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		return c.JSON(OIDCProvidersResponse{
+			Providers: oidcService.GetProviders(ctx),
+		})
+	}
+}
+
+// OIDCAuthorize godoc
+//
+//	@ID				OIDCAuthorize
+//	@Summary		Start an identity provider login
+//	@Description	Start an authorization code flow with PKCE. Send the user to the returned URL and keep the code verifier, the provider redirects back to the app with a code and state for /api/oidc/{provider}/callback.
+//	@Tags			OIDC
+//	@Produce		json
+//	@Param			provider	path		string	true	"Provider name"
+//	@Success		200			{object}	OIDCAuthorizationResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		502			{object}	ErrorResponse
+//	@Router			/api/oidc/{provider}/authorize [get]
+func OIDCAuthorize(oidcService services.OIDCServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		provider := c.Params("provider")
+
+		authorization, err := oidcService.AuthorizationURL(ctx, provider)
+		if errors.Is(err, services.ErrUnknownOIDCProvider) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusBadGateway).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(OIDCAuthorizationResponse{
+			AuthorizationURL: authorization.URL,
+			CodeVerifier:     authorization.CodeVerifier,
+		})
+	}
+}
+
+// OIDCCallback godoc
+//
+//	@ID				OIDCCallback
+//	@Summary		Finish an identity provider login
+//	@Description	Exchange the code and state from the provider's redirect and the code verifier from /api/oidc/{provider}/authorize for a login. The account is created on first login. If the user has two factor auth enabled, a 202 with an MFA token is returned as for /api/login.
+//	@Tags			OIDC
+//	@Accept			json
+//	@Produce		json
+//	@Param			provider	path		string				true	"Provider name"
+//	@Param			body		body		OIDCCallbackRequest	true	"Code and state from the redirect and the code verifier"
+//	@Success		200			{object}	SuccessfulLoginResponse
+//	@Success		202			{object}	MFARequiredResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/oidc/{provider}/callback [post]
+func OIDCCallback(oidcService services.OIDCServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		provider := c.Params("provider")
+		var params OIDCCallbackRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		result, err := oidcService.Login(ctx, provider, params.Code, params.State, params.CodeVerifier, clientInfo(c))
+		if err != nil {
+			status := fiber.StatusUnauthorized
+			switch {
+			case errors.Is(err, services.ErrUnknownOIDCProvider):
+				status = fiber.StatusNotFound
+			case errors.Is(err, services.ErrInvalidOIDCState):
+				status = fiber.StatusBadRequest
+			case errors.Is(err, services.ErrOIDCEmailTaken):
+				status = fiber.StatusConflict
+			case errors.Is(err, services.ErrAccountDisabled):
+				status = fiber.StatusForbidden
+			}
+			return c.Status(status).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		if result.MFAToken != "" {
+			return c.Status(fiber.StatusAccepted).JSON(MFARequiredResponse{
+				Message:  "mfa_required",
+				MFAToken: result.MFAToken,
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessfulLoginResponse{
+			Message:      "User logged in successfully",
+			Token:        result.AccessToken,
+			RefreshToken: result.RefreshToken,
+		})
+	}
+}
+
+// GetUserIdentities godoc
+//
+//	@ID				GetUserIdentities
+//	@Summary		List linked identities
+//	@Description	List the identity provider accounts linked to the authenticated user.
+//	@Tags			OIDC
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		repository.GetUserIdentitiesRow
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/api/user/identities [get]
+func GetUserIdentities(oidcService services.OIDCServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		identities, err := oidcService.GetIdentities(ctx, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(identities)
+	}
+}
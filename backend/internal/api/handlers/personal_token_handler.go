@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+type CreatePersonalAccessTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// defaults to 30 days
+	ExpiresInDays int `json:"expires_in_days,omitempty"`
+}
+
+type CreatePersonalAccessTokenResponse struct {
+	services.PersonalAccessToken
+	// only returned once, store it somewhere safe
+	Token string `json:"token"`
+}
+
+// CreatePersonalAccessToken godoc
+//
+//	@ID				CreatePersonalAccessToken
+//	@Summary		Create a personal access token
+//	@Description	Create a named, expiring token for scripts. Send it as a bearer token like a JWT. It can only call routes covered by its scopes: profile:read, clubs:read, clubs:write, metrics:read, metrics:write, marketplace:read, marketplace:write. A write scope includes read. Club management by owners and moderators is not available to tokens.
+//	@Tags			Token
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		CreatePersonalAccessTokenRequest	true	"Token details"
+//	@Success		201		{object}	CreatePersonalAccessTokenResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Router			/api/user/tokens [post]
+func CreatePersonalAccessToken(tokenService services.PersonalTokenServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		var params CreatePersonalAccessTokenRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		duration := time.Duration(params.ExpiresInDays) * 24 * time.Hour
+		pat, token, err := tokenService.CreateToken(ctx, userID, params.Name, params.Scopes, duration)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(CreatePersonalAccessTokenResponse{
+			PersonalAccessToken: pat,
+			Token:               token,
+		})
+	}
+}
+
+// GetPersonalAccessTokens godoc
+//
+//	@ID				GetPersonalAccessTokens
+//	@Summary		List personal access tokens
+//	@Description	List the authenticated user's active personal access tokens. The tokens themselves are not returned.
+//	@Tags			Token
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		services.PersonalAccessToken
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/api/user/tokens [get]
+func GetPersonalAccessTokens(tokenService services.PersonalTokenServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		tokens, err := tokenService.GetTokens(ctx, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(tokens)
+	}
+}
+
+// RevokePersonalAccessToken godoc
+//
+//	@ID			RevokePersonalAccessToken
+//	@Summary	Revoke a personal access token
+//	@Tags		Token
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		token_id	path		string	true	"Token ID"
+//	@Success	200			{object}	SuccessResponse
+//	@Failure	401			{object}	ErrorResponse
+//	@Failure	404			{object}	ErrorResponse
+//	@Router		/api/user/tokens/{token_id} [delete]
+func RevokePersonalAccessToken(tokenService services.PersonalTokenServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		tokenID := c.Params("token_id")
+
+		if err := tokenService.RevokeToken(ctx, userID, tokenID); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Access token revoked successfully",
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+func pointsError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidPointAdjustment), errors.Is(err, services.ErrPointNoteLength):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrNotClubModerator), errors.Is(err, services.ErrSelfPointAdjustment):
+		status = fiber.StatusForbidden
+	case errors.Is(err, services.ErrNotClubMember):
+		status = fiber.StatusNotFound
+	case errors.Is(err, services.ErrNotEnoughPoints):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(ErrorResponse{Error: err.Error()})
+}
+
+// GetPointHistory godoc
+//
+//	@ID				GetPointHistory
+//	@Summary		Get a member's point history
+//	@Description	The member's points and streak in the club with every change to the points, the newest first. Members can see their own history, moderators everyone's.
+//	@Tags			Points
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Param			user_id	path		string	true	"Member's user ID"
+//	@Param			limit	query		int		false	"Page size, 50 by default and at most 200"
+//	@Param			offset	query		int		false	"Number of entries to skip"
+//	@Success		200		{object}	services.PointHistory
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/members/{user_id}/points [get]
+func GetPointHistory(pointsService services.PointsServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		history, err := pointsService.GetPointHistory(ctx, userID, c.Params("club_id"), c.Params("user_id"),
+			int64(c.QueryInt("limit")), int64(c.QueryInt("offset")))
+		if err != nil {
+			return pointsError(c, err)
+		}
+		return c.JSON(history)
+	}
+}
+
+// AdjustPoints godoc
+//
+//	@ID				AdjustPoints
+//	@Summary		Give or take away a member's points
+//	@Description	A negative amount takes points away, but not more than the member has. The note is shown in the member's history. Only for moderators and the owner, who cannot adjust their own points.
+//	@Tags			Points
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id		path		string							true	"Club ID"
+//	@Param			user_id		path		string							true	"Member's user ID"
+//	@Param			adjustment	body		services.AdjustPointsRequest	true	"Adjustment"
+//	@Success		200			{object}	SuccessResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/members/{user_id}/points [post]
+func AdjustPoints(pointsService services.PointsServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req services.AdjustPointsRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "invalid request body"})
+		}
+
+		if err := pointsService.AdjustPoints(ctx, userID, c.Params("club_id"), c.Params("user_id"), req); err != nil {
+			return pointsError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Points adjusted",
+		})
+	}
+}
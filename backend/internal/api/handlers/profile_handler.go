@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+// GetUserProfile godoc
+//
+//	@ID				GetUserProfile
+//	@Summary		Get a user's profile
+//	@Description	Get a user's profile with their public clubs, points, longest streak and available marketplace items. Sections the user hid are left out. When the profile is private, or only for friends and the viewer is not one, only the username, picture and trade reputation are returned with visible set to false. The owner also gets their privacy settings.
+//	@Tags			User
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"User ID"
+//	@Success		200	{object}	services.UserProfile
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/api/user/{id}/profile [get]
+func GetUserProfile(profileService services.ProfileServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		viewerID := c.Locals("userID").(string)
+		userID := c.Params("id")
+
+		profile, err := profileService.GetProfile(ctx, viewerID, userID)
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(profile)
+	}
+}
+
+// UpdateUserProfile godoc
+//
+//	@ID				UpdateUserProfile
+//	@Summary		Update the profile
+//	@Description	Update the authenticated user's profile and privacy settings, all parameters are optional. Links replace the existing ones, an empty list removes them.
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			profile	body		services.ProfileUpdate	true	"Profile details"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Router			/api/user/profile [put]
+func UpdateUserProfile(profileService services.ProfileServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var params services.ProfileUpdate
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := profileService.UpdateProfile(ctx, userID, params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Profile updated successfully",
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+func reportError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidReportTarget), errors.Is(err, services.ErrInvalidReportCategory),
+		errors.Is(err, services.ErrReportSelf), errors.Is(err, services.ErrInvalidReportResolution),
+		errors.Is(err, services.ErrAdminReasonRequired):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrReportForbidden), errors.Is(err, services.ErrReportActionNotAllowed),
+		errors.Is(err, services.ErrAdminTargetSelf), errors.Is(err, services.ErrAdminTargetAdmin):
+		status = fiber.StatusForbidden
+	case errors.Is(err, services.ErrReportNotFound), errors.Is(err, services.ErrReportTargetNotFound),
+		errors.Is(err, services.ErrPostNotFound), errors.Is(err, services.ErrItemNotFound),
+		errors.Is(err, services.ErrClubNotFound), errors.Is(err, services.ErrUserNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, services.ErrAlreadyReported), errors.Is(err, services.ErrReportResolved),
+		errors.Is(err, services.ErrReportNotOpen), errors.Is(err, services.ErrUserAlreadyDisabled):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(ErrorResponse{
+		Error: err.Error(),
+	})
+}
+
+func reportFilter(c *fiber.Ctx) services.ReportFilter {
+	return services.ReportFilter{
+		Status:     c.Query("status"),
+		TargetType: c.Query("target_type"),
+		AllClubs:   c.QueryBool("all"),
+		Limit:      int64(c.QueryInt("limit")),
+		Offset:     int64(c.QueryInt("offset")),
+	}
+}
+
+// CreateReport godoc
+//
+//	@ID				CreateReport
+//	@Summary		Report content
+//	@Description	Report a club post, marketplace item, user or club. Reports about posts and items of a club go to its moderators, everything else to the site admins. Reports by different users about the same target are merged while it is unresolved, the returned id is the merged report.
+//	@Tags			Reports
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		services.CreateReportRequest	true	"What is reported and why"
+//	@Success		201		{object}	CreatedResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Router			/api/reports [post]
+func CreateReport(reportService services.ReportServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		var params services.CreateReportRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		reportID, err := reportService.CreateReport(ctx, userID, params)
+		if err != nil {
+			return reportError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(CreatedResponse{
+			Message: "Report submitted",
+			ID:      reportID,
+		})
+	}
+}
+
+// GetUserReports godoc
+//
+//	@ID				GetUserReports
+//	@Summary		List your reports
+//	@Description	List the reports the authenticated user submitted and their current status.
+//	@Tags			Reports
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		repository.GetReportsByReporterRow
+//	@Failure		401	{object}	ErrorResponse
+//	@Router			/api/reports [get]
+func GetUserReports(reportService services.ReportServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		reports, err := reportService.GetUserReports(ctx, userID)
+		if err != nil {
+			return reportError(c, err)
+		}
+
+		return c.JSON(reports)
+	}
+}
+
+// GetReport godoc
+//
+//	@ID				GetReport
+//	@Summary		Get a report
+//	@Description	Get a report with every submission. Only for the club's moderators or site admins.
+//	@Tags			Reports
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			report_id	path		string	true	"Report ID"
+//	@Success		200			{object}	services.ReportDetails
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Router			/api/reports/{report_id} [get]
+func GetReport(reportService services.ReportServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		report, err := reportService.GetReport(ctx, userID, c.Params("report_id"))
+		if err != nil {
+			return reportError(c, err)
+		}
+
+		return c.JSON(report)
+	}
+}
+
+// TriageReport godoc
+//
+//	@ID				TriageReport
+//	@Summary		Triage a report
+//	@Description	Move an open report to triaged and assign it to the authenticated moderator or admin.
+//	@Tags			Reports
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			report_id	path		string	true	"Report ID"
+//	@Success		200			{object}	SuccessResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/reports/{report_id}/triage [post]
+func TriageReport(reportService services.ReportServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := reportService.TriageReport(ctx, userID, c.Params("report_id")); err != nil {
+			return reportError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Report triaged",
+		})
+	}
+}
+
+// ResolveReport godoc
+//
+//	@ID				ResolveReport
+//	@Summary		Resolve a report
+//	@Description	Dismiss a report or action it with a moderation action that is carried out right away: delete_post or remove_member for posts, delete_item or remove_member for items, disable_user for users and delete_club for clubs. Removing members is up to club moderators, actions by site admins are written to the admin audit log with the report id.
+//	@Tags			Reports
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			report_id	path		string						true	"Report ID"
+//	@Param			body		body		services.ReportResolution	true	"Outcome"
+//	@Success		200			{object}	SuccessResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/reports/{report_id}/resolve [post]
+func ResolveReport(reportService services.ReportServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		var params services.ReportResolution
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := reportService.ResolveReport(ctx, userID, c.Params("report_id"), params, clientInfo(c)); err != nil {
+			return reportError(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Report " + params.Status,
+		})
+	}
+}
+
+// GetClubReports godoc
+//
+//	@ID				GetClubReports
+//	@Summary		Get the club's report queue
+//	@Description	List reports about the club's posts and items, the most reported first. Only for moderators and the owner.
+//	@Tags			Reports
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id		path		string	true	"Club ID"
+//	@Param			status		query		string	false	"open, triaged, actioned or dismissed"
+//	@Param			target_type	query		string	false	"post or item"
+//	@Param			limit		query		int		false	"Page size, 50 by default and at most 200"
+//	@Param			offset		query		int		false	"Number of reports to skip"
+//	@Success		200			{array}		repository.GetReportQueueRow
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/reports [get]
+func GetClubReports(reportService services.ReportServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		reports, err := reportService.GetClubReports(ctx, userID, c.Params("club_id"), reportFilter(c))
+		if err != nil {
+			return reportError(c, err)
+		}
+
+		return c.JSON(reports)
+	}
+}
+
+// AdminGetReports godoc
+//
+//	@ID				AdminGetReports
+//	@Summary		Get the site report queue
+//	@Description	List reports routed to site admins, the most reported first. With all=true reports handled by club moderators are included.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			status		query		string	false	"open, triaged, actioned or dismissed"
+//	@Param			target_type	query		string	false	"post, item, user or club"
+//	@Param			all			query		bool	false	"Include reports routed to club moderators"
+//	@Param			limit		query		int		false	"Page size, 50 by default and at most 200"
+//	@Param			offset		query		int		false	"Number of reports to skip"
+//	@Success		200			{array}		repository.GetReportQueueRow
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Router			/api/admin/reports [get]
+func AdminGetReports(reportService services.ReportServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+
+		reports, err := reportService.GetAdminReports(ctx, reportFilter(c))
+		if err != nil {
+			return reportError(c, err)
+		}
+
+		return c.JSON(reports)
+	}
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+func rewardError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidReward), errors.Is(err, services.ErrRedemptionNoteLength),
+		errors.Is(err, services.ErrInvalidRedemptionStatus), errors.Is(err, services.ErrRewardItemStock):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrNotClubMember), errors.Is(err, services.ErrNotClubModerator):
+		status = fiber.StatusForbidden
+	case errors.Is(err, services.ErrRewardNotFound), errors.Is(err, services.ErrRedemptionNotFound),
+		errors.Is(err, services.ErrItemNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, services.ErrRewardUnavailable), errors.Is(err, services.ErrNotEnoughPoints),
+		errors.Is(err, services.ErrRedemptionHandled), errors.Is(err, services.ErrRewardItemUnavailable):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(ErrorResponse{Error: err.Error()})
+}
+
+type RedeemRewardRequest struct {
+	// for the moderators, e.g. which week to skip
+	Note *string `json:"note,omitempty"`
+}
+
+// GetClubRewards godoc
+//
+//	@ID				GetClubRewards
+//	@Summary		Get the club's rewards
+//	@Description	List what the club's members can spend their points on, the cheapest first. Inactive rewards are only listed for moderators.
+//	@Tags			Rewards
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Success		200		{array}		repository.ClubReward
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/rewards [get]
+func GetClubRewards(rewardService services.RewardServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		rewards, err := rewardService.GetClubRewards(ctx, userID, c.Params("club_id"))
+		if err != nil {
+			return rewardError(c, err)
+		}
+		return c.JSON(rewards)
+	}
+}
+
+// CreateClubReward godoc
+//
+//	@ID				CreateClubReward
+//	@Summary		Add a reward to the club's shop
+//	@Description	A reward can hand out one of your tradable items in the club, it is set aside from trading until a redemption is fulfilled. Only for moderators and the owner.
+//	@Tags			Rewards
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string							true	"Club ID"
+//	@Param			reward	body		services.CreateRewardRequest	true	"Reward"
+//	@Success		201		{object}	CreatedResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/rewards [post]
+func CreateClubReward(rewardService services.RewardServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req services.CreateRewardRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "invalid request body"})
+		}
+
+		rewardID, err := rewardService.CreateClubReward(ctx, userID, c.Params("club_id"), req)
+		if err != nil {
+			return rewardError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(CreatedResponse{
+			Message: "Reward created",
+			ID:      rewardID,
+		})
+	}
+}
+
+// UpdateClubReward godoc
+//
+//	@ID				UpdateClubReward
+//	@Summary		Change a reward
+//	@Description	Change the reward's fields that are set. Deactivate a reward to stop members from redeeming it, pending redemptions are not affected. The item of a deactivated reward can be traded again. Only for moderators and the owner.
+//	@Tags			Rewards
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id		path		string							true	"Club ID"
+//	@Param			reward_id	path		string							true	"Reward ID"
+//	@Param			reward		body		services.UpdateRewardRequest	true	"Fields to change"
+//	@Success		200			{object}	SuccessResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/rewards/{reward_id} [put]
+func UpdateClubReward(rewardService services.RewardServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req services.UpdateRewardRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "invalid request body"})
+		}
+
+		err := rewardService.UpdateClubReward(ctx, userID, c.Params("club_id"), c.Params("reward_id"), req)
+		if err != nil {
+			return rewardError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Reward updated",
+		})
+	}
+}
+
+// RedeemReward godoc
+//
+//	@ID				RedeemReward
+//	@Summary		Spend points on a reward
+//	@Description	The reward's cost is taken from your points in the club right away. A moderator then fulfills the redemption or rejects it, which refunds the points. You are told over the WebSocket.
+//	@Tags			Rewards
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id		path		string				true	"Club ID"
+//	@Param			reward_id	path		string				true	"Reward ID"
+//	@Param			redemption	body		RedeemRewardRequest	false	"Note for the moderators"
+//	@Success		201			{object}	CreatedResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/rewards/{reward_id}/redeem [post]
+func RedeemReward(rewardService services.RewardServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var req RedeemRewardRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "invalid request body"})
+			}
+		}
+
+		redemptionID, err := rewardService.RedeemReward(ctx, userID, c.Params("club_id"), c.Params("reward_id"), req.Note)
+		if err != nil {
+			return rewardError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(CreatedResponse{
+			Message: "Reward redeemed",
+			ID:      redemptionID,
+		})
+	}
+}
+
+// GetClubRedemptions godoc
+//
+//	@ID				GetClubRedemptions
+//	@Summary		Get the club's redemptions
+//	@Description	Moderators see every member's redemptions, members only their own, the newest first.
+//	@Tags			Rewards
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id	path		string	true	"Club ID"
+//	@Param			status	query		string	false	"pending, fulfilled or rejected"
+//	@Success		200		{array}		repository.GetClubRedemptionsRow
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/redemptions [get]
+func GetClubRedemptions(rewardService services.RewardServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		var status *string
+		if s := c.Query("status"); s != "" {
+			status = &s
+		}
+
+		redemptions, err := rewardService.GetClubRedemptions(ctx, userID, c.Params("club_id"), status)
+		if err != nil {
+			return rewardError(c, err)
+		}
+		return c.JSON(redemptions)
+	}
+}
+
+// FulfillRedemption godoc
+//
+//	@ID				FulfillRedemption
+//	@Summary		Mark a redemption as handed out
+//	@Description	The reward's item goes to the member. Only for moderators and the owner. The member is told over the WebSocket.
+//	@Tags			Rewards
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id			path		string	true	"Club ID"
+//	@Param			redemption_id	path		string	true	"Redemption ID"
+//	@Success		200				{object}	SuccessResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		404				{object}	ErrorResponse
+//	@Failure		409				{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/redemptions/{redemption_id}/fulfill [post]
+func FulfillRedemption(rewardService services.RewardServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := rewardService.FulfillRedemption(ctx, userID, c.Params("club_id"), c.Params("redemption_id")); err != nil {
+			return rewardError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Redemption fulfilled",
+		})
+	}
+}
+
+// RejectRedemption godoc
+//
+//	@ID				RejectRedemption
+//	@Summary		Reject a redemption
+//	@Description	Refunds the points and puts the reward back in stock. Only for moderators and the owner. The member is told over the WebSocket.
+//	@Tags			Rewards
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			club_id			path		string	true	"Club ID"
+//	@Param			redemption_id	path		string	true	"Redemption ID"
+//	@Success		200				{object}	SuccessResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		404				{object}	ErrorResponse
+//	@Failure		409				{object}	ErrorResponse
+//	@Router			/api/club/{club_id}/redemptions/{redemption_id}/reject [post]
+func RejectRedemption(rewardService services.RewardServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := rewardService.RejectRedemption(ctx, userID, c.Params("club_id"), c.Params("redemption_id")); err != nil {
+			return rewardError(c, err)
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Redemption rejected",
+		})
+	}
+}
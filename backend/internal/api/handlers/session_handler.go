@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/services"
+)
+
+func clientInfo(c *fiber.Ctx) services.ClientInfo {
+	return services.ClientInfo{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IP:        c.IP(),
+	}
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken godoc
+//
+//	@ID				RefreshToken
+//	@Summary		Refresh an access token
+//	@Description	Exchange a refresh token for a new access and refresh token pair. The old refresh token is invalidated, replaying it revokes the session.
+//	@Tags			Session
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		RefreshTokenRequest	true	"Refresh token"
+//	@Success		200		{object}	SuccessfulLoginResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Router			/api/refresh [post]
+func RefreshToken(sessionService services.SessionServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params RefreshTokenRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		tokens, err := sessionService.RefreshSession(ctx, params.RefreshToken, clientInfo(c))
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessfulLoginResponse{
+			Message:      "Token refreshed successfully",
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+		})
+	}
+}
+
+// Logout godoc
+//
+//	@ID				Logout
+//	@Summary		Log out of the current session
+//	@Description	Revoke the session the access token belongs to.
+//	@Tags			Session
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	SuccessResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/api/logout [post]
+func Logout(sessionService services.SessionServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		sessionID := c.Locals("sessionID").(string)
+
+		if err := sessionService.RevokeSession(ctx, userID, sessionID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Logged out successfully",
+		})
+	}
+}
+
+// LogoutAll godoc
+//
+//	@ID				LogoutAll
+//	@Summary		Log out of all devices
+//	@Description	Revoke every session of the authenticated user, including the current one.
+//	@Tags			Session
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	SuccessResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/api/logout-all [post]
+func LogoutAll(sessionService services.SessionServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := sessionService.RevokeAllSessions(ctx, userID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Logged out of all devices successfully",
+		})
+	}
+}
+
+// GetUserSessions godoc
+//
+//	@ID				GetUserSessions
+//	@Summary		List active sessions
+//	@Description	List the authenticated user's active sessions with device and last seen time.
+//	@Tags			Session
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		services.UserSession
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/api/user/sessions [get]
+func GetUserSessions(sessionService services.SessionServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		sessionID := c.Locals("sessionID").(string)
+
+		sessions, err := sessionService.GetSessions(ctx, userID, sessionID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(sessions)
+	}
+}
+
+// RevokeUserSession godoc
+//
+//	@ID				RevokeUserSession
+//	@Summary		Revoke a session
+//	@Description	Log out a single device of the authenticated user.
+//	@Tags			Session
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			session_id	path		string	true	"Session ID"
+//	@Success		200			{object}	SuccessResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/api/user/sessions/{session_id} [delete]
+func RevokeUserSession(sessionService services.SessionServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+		sessionID := c.Params("session_id")
+
+		err := sessionService.RevokeSession(ctx, userID, sessionID)
+		if errors.Is(err, services.ErrSessionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+			Message: "Session revoked successfully",
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 
 	"github.com/gofiber/fiber/v2"
@@ -81,6 +82,8 @@ type RegisterUserRequest struct {
 //	@Param			user	body		RegisterUserRequest	true	"User registration details"
 //	@Success		201		{object}	SuccessfulLoginResponse
 //	@Failure		400		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/api/register [post]
 func RegisterUser(userService services.UserServicer) fiber.Handler {
@@ -93,15 +96,30 @@ func RegisterUser(userService services.UserServicer) fiber.Handler {
 			})
 		}
 
-		tokenString, err := userService.RegisterUser(ctx, params.Username, params.Password, params.Email)
+		tokens, err := userService.RegisterUser(ctx, params.Username, params.Password, params.Email, clientInfo(c))
+		var tooMany *services.TooManyAttemptsError
+		if errors.As(err, &tooMany) {
+			return tooManyAttempts(c, tooMany)
+		}
+		if errors.Is(err, services.ErrContentRejected) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrRegistrationUnavailable) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 				Error: err.Error(),
 			})
 		}
 		return c.Status(fiber.StatusCreated).JSON(SuccessfulLoginResponse{
-			Message: "User registered and logged in successfully",
-			Token:   tokenString,
+			Message:      "User registered and logged in successfully",
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
 		})
 	}
 }
@@ -116,13 +134,17 @@ type LoginUserRequest struct {
 //
 //	@ID				LoginUser
 //	@Summary		Login a user
-//	@Description	Login a user with (email or username) and password
+//	@Description	Login a user with (email or username) and password. If the user has two factor auth enabled, a 202 with an MFA token is returned instead and the login is finished at /api/login/mfa.
 //	@Tags			User
 //	@Accept			json
 //	@Produce		json
 //	@Param			user	body		LoginUserRequest	true	"User login credentials"
 //	@Success		200		{object}	SuccessfulLoginResponse
+//	@Success		202		{object}	MFARequiredResponse
 //	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/api/login [post]
 func LoginUser(userService services.UserServicer) fiber.Handler {
@@ -133,16 +155,33 @@ func LoginUser(userService services.UserServicer) fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
 		}
 
-		tokenString, err := userService.LoginUser(ctx, params.Username, params.Email, params.Password)
+		result, err := userService.LoginUser(ctx, params.Username, params.Email, params.Password, clientInfo(c))
+		var tooMany *services.TooManyAttemptsError
+		if errors.As(err, &tooMany) {
+			return tooManyAttempts(c, tooMany)
+		}
+		if errors.Is(err, services.ErrAccountDisabled) {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
 				Error: err.Error(),
 			})
 		}
 
+		if result.MFAToken != "" {
+			return c.Status(fiber.StatusAccepted).JSON(MFARequiredResponse{
+				Message:  "mfa_required",
+				MFAToken: result.MFAToken,
+			})
+		}
+
 		return c.Status(fiber.StatusOK).JSON(SuccessfulLoginResponse{
-			Message: "User logged in successfully",
-			Token:   tokenString,
+			Message:      "User logged in successfully",
+			Token:        result.AccessToken,
+			RefreshToken: result.RefreshToken,
 		})
 	}
 }
@@ -190,6 +229,11 @@ func UpdateUser(userService services.UserServicer) fiber.Handler {
 		}
 
 		err := userService.UpdateUser(ctx, dbParams)
+		if errors.Is(err, services.ErrContentRejected) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 				Error: err.Error(),
@@ -373,3 +417,137 @@ func GetItemsByOwner(userService services.UserServicer) fiber.Handler {
 		return c.JSON(items)
 	}
 }
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyEmail godoc
+//
+//	@ID				VerifyEmail
+//	@Summary		Verify an email address
+//	@Description	Verify the email address of an account with the token that was mailed to it.
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		VerifyEmailRequest	true	"Verification token"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Router			/api/verify-email [post]
+func VerifyEmail(userService services.UserServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params VerifyEmailRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := userService.VerifyEmail(ctx, params.Token); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Email verified successfully",
+		})
+	}
+}
+
+// ResendVerificationEmail godoc
+//
+//	@ID				ResendVerificationEmail
+//	@Summary		Resend the verification email
+//	@Description	Send a new verification link to the authenticated user's email address. Older links stop working.
+//	@Tags			User
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	SuccessResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Router			/api/user/verify-email [post]
+func ResendVerificationEmail(userService services.UserServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		userID := c.Locals("userID").(string)
+
+		if err := userService.SendVerificationEmail(ctx, userID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Verification email sent",
+		})
+	}
+}
+
+type RequestPasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+// RequestPasswordReset godoc
+//
+//	@ID				RequestPasswordReset
+//	@Summary		Request a password reset
+//	@Description	Mail a password reset link to the given address. The response is the same whether or not an account exists.
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		RequestPasswordResetRequest	true	"Account email"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Router			/api/password-reset [post]
+func RequestPasswordReset(userService services.UserServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params RequestPasswordResetRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		userService.RequestPasswordReset(ctx, params.Email)
+
+		return c.JSON(SuccessResponse{
+			Message: "If an account with that email exists, a reset link has been sent",
+		})
+	}
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ResetPassword godoc
+//
+//	@ID				ResetPassword
+//	@Summary		Reset a password
+//	@Description	Set a new password with a mailed reset token. Every session of the account is logged out.
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		ResetPasswordRequest	true	"Reset token and new password"
+//	@Success		200		{object}	SuccessResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Router			/api/password-reset/confirm [post]
+func ResetPassword(userService services.UserServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.Context()
+		var params ResetPasswordRequest
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		if err := userService.ResetPassword(ctx, params.Token, params.Password); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		return c.JSON(SuccessResponse{
+			Message: "Password reset successfully",
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"log"
 
 	"github.com/gofiber/contrib/websocket"
@@ -9,10 +10,20 @@ import (
 	"github.com/rhellwege/task-social/internal/api/services"
 )
 
-func WebSocketHandler(wsService *services.WebSocketService) fiber.Handler {
+// WebSocketHandler keeps the connection open for events. Besides the events sent to the user, a connection
+// only gets the events of the topics it subscribed to by sending
+//
+//	{"event": "subscribe", "payload": {"topic": "club:<id>:posts"}}
+//
+// which is answered with a subscribed or an error event, and unsubscribe works the same.
+// Topics are club:<id>:posts, club:<id>:members, club:<id>:auctions and metric:<id> for members of the club,
+// and dm:<user id> for the conversation with a user the subscriber may message
+func WebSocketHandler(wsService *services.WebSocketService, subscriptionService services.SubscriptionServicer) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := c.Context()
 		return websocket.New(func(conn *websocket.Conn) {
+			// the request's context is not used once the connection is upgraded
+			ctx := context.Background()
+
 			userID, ok := conn.Locals("userID").(string)
 			if !ok {
 				log.Println("Unauthorized WebSocket connection")
@@ -27,25 +38,21 @@ func WebSocketHandler(wsService *services.WebSocketService) fiber.Handler {
 				return
 			}
 
-			wsService.AddConnection(ctx, userID, conn, jwtToken)
-			defer wsService.RemoveConnection(ctx, userID)
+			// every device gets its own connection, closing one leaves the others connected
+			connectionID := wsService.AddConnection(ctx, userID, conn, jwtToken)
+			defer wsService.RemoveConnection(ctx, userID, connectionID)
 
-			log.Printf("WebSocket connection established for user %s", userID)
+			log.Printf("WebSocket connection %s established for user %s", connectionID, userID)
 
-			// if the client sends through the connection, echo back.
-			// chat messages are sent through the api then broadcasted on ws
+			// chat messages are sent through the api then broadcasted on ws,
+			// the client only sends subscriptions
 			for {
-				mt, msg, err := conn.ReadMessage()
+				_, msg, err := conn.ReadMessage()
 				if err != nil {
 					log.Println("read:", err)
 					break
 				}
-				log.Printf("recv: %s", msg)
-				err = conn.WriteMessage(mt, msg)
-				if err != nil {
-					log.Println("write:", err)
-					break
-				}
+				subscriptionService.HandleMessage(ctx, userID, connectionID, msg)
 			}
 		})(c)
 	}
@@ -1,16 +1,26 @@
 package middleware
 
 import (
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/config"
 	"github.com/rhellwege/task-social/internal/api/handlers"
 	"github.com/rhellwege/task-social/internal/api/services"
 )
 
+// account data an impersonating admin has no reason to see: the full export, the user's sessions, tokens, linked
+// identities and login history. live updates over /ws would keep flowing past the read-only checks
+var impersonationForbiddenRoutes = regexp.MustCompile(`^/(ws|api/user/(export|sessions|tokens|identities|login-failures))(/|$)`)
+
 // Takes JWT token from Authorization header or query parameter and inserts the userID into the context
-func ProtectedRoute(authService services.AuthServicer) fiber.Handler {
+// Tokens whose session has been revoked are rejected
+// Personal access tokens are accepted too, but only for routes covered by their scopes
+// Sessions an admin started to impersonate a user are read-only and cannot reach the account routes above,
+// the admin's id is inserted as impersonatorID
+func ProtectedRoute(authService services.AuthServicer, sessionService services.SessionServicer, tokenService services.PersonalTokenServicer) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		ctx := c.Context()
 		// Get token from query parameter first
@@ -28,6 +38,10 @@ func ProtectedRoute(authService services.AuthServicer) fiber.Handler {
 			})
 		}
 
+		if services.IsPersonalToken(tokenString) {
+			return personalTokenRoute(c, tokenService, tokenString)
+		}
+
 		token, err := authService.VerifyToken(ctx, tokenString)
 		if err != nil || !token.Valid {
 			return c.Status(fiber.StatusUnauthorized).JSON(handlers.ErrorResponse{
@@ -49,9 +63,112 @@ func ProtectedRoute(authService services.AuthServicer) fiber.Handler {
 			})
 		}
 
+		sessionID, err := services.SessionIDFromToken(token)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(handlers.ErrorResponse{
+				Error: "Unauthorized: invalid token",
+			})
+		}
+
+		session, err := sessionService.ValidateSession(ctx, subject, sessionID)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(handlers.ErrorResponse{
+				Error: "Unauthorized: " + err.Error(),
+			})
+		}
+
+		if session.ImpersonatorID != nil {
+			if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+				return c.Status(fiber.StatusForbidden).JSON(handlers.ErrorResponse{
+					Error: "Forbidden: impersonation sessions are read-only",
+				})
+			}
+			if impersonationForbiddenRoutes.MatchString(c.Path()) {
+				return c.Status(fiber.StatusForbidden).JSON(handlers.ErrorResponse{
+					Error: "Forbidden: this route cannot be used while impersonating a user",
+				})
+			}
+			c.Locals("impersonatorID", *session.ImpersonatorID)
+		}
+
 		c.Locals("userID", subject)
+		c.Locals("sessionID", sessionID)
 		c.Locals("jwt", token)
 
 		return c.Next()
 	}
 }
+
+func personalTokenRoute(c *fiber.Ctx, tokenService services.PersonalTokenServicer, tokenString string) error {
+	userID, scopes, err := tokenService.ValidateToken(c.Context(), tokenString)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(handlers.ErrorResponse{
+			Error: "Unauthorized: " + err.Error(),
+		})
+	}
+
+	scope, allowed := requiredScope(c)
+	if !allowed {
+		return c.Status(fiber.StatusForbidden).JSON(handlers.ErrorResponse{
+			Error: "Forbidden: this route cannot be used with a personal access token",
+		})
+	}
+	if !services.HasScope(scopes, scope) {
+		return c.Status(fiber.StatusForbidden).JSON(handlers.ErrorResponse{
+			Error: "Forbidden: access token is missing the " + scope + " scope",
+		})
+	}
+
+	c.Locals("userID", userID)
+	c.Locals("scopes", scopes)
+
+	return c.Next()
+}
+
+// Must run after ProtectedRoute. Rejects users that have not verified their email address yet
+func VerifiedEmailRoute(userService services.UserServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !config.RequireVerifiedEmail {
+			return c.Next()
+		}
+
+		verified, err := userService.IsEmailVerified(c.Context(), c.Locals("userID").(string))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(handlers.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if !verified {
+			return c.Status(fiber.StatusForbidden).JSON(handlers.ErrorResponse{
+				Error: "Forbidden: email address is not verified",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// Must run after ProtectedRoute. Rejects everyone but site admins, also when an admin is impersonating someone
+func AdminRoute(adminService services.AdminServicer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals("impersonatorID") != nil {
+			return c.Status(fiber.StatusForbidden).JSON(handlers.ErrorResponse{
+				Error: "Forbidden: admin routes cannot be used while impersonating a user",
+			})
+		}
+
+		isAdmin, err := adminService.IsAdmin(c.Context(), c.Locals("userID").(string))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(handlers.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if !isAdmin {
+			return c.Status(fiber.StatusForbidden).JSON(handlers.ErrorResponse{
+				Error: "Forbidden: admin role required",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// routes personal access tokens may call and the resource each belongs to.
+// GET needs "<resource>:read", every other method "<resource>:write".
+// account management (sessions, tokens, two factor, password, deletion, export) and club management
+// are never reachable with a personal access token
+var tokenScopeRules = []struct {
+	path     *regexp.Regexp
+	resource string
+}{
+	{regexp.MustCompile(`^/api/club/[^/]+/items$`), "marketplace"},
+	{regexp.MustCompile(`^/api/marketplace/`), "marketplace"},
+	{regexp.MustCompile(`^/api/user/items$`), "marketplace"},
+	{regexp.MustCompile(`^/api/clubs?(/|$)`), "clubs"},
+	{regexp.MustCompile(`^/api/user/clubs$`), "clubs"},
+	{regexp.MustCompile(`^/api/metric(/|$)`), "metrics"},
+	{regexp.MustCompile(`^/api/user/(metrics|metric-entries)$`), "metrics"},
+	{regexp.MustCompile(`^/api/user/?$`), "profile"},
+	{regexp.MustCompile(`^/api/user/profile$`), "profile"},
+	{regexp.MustCompile(`^/api/user/[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}(/profile)?$`), "profile"},
+}
+
+// club routes for owners and moderators, which clubs:write does not cover. an empty method matches every method
+var tokenForbiddenClubRoutes = []struct {
+	method string
+	path   *regexp.Regexp
+}{
+	{fiber.MethodPut, regexp.MustCompile(`^/api/club/[^/]+$`)},
+	{fiber.MethodDelete, regexp.MustCompile(`^/api/club/[^/]+$`)},
+	{"", regexp.MustCompile(`^/api/club/[^/]+/banner$`)},
+	{"", regexp.MustCompile(`^/api/club/[^/]+/blocklist$`)},
+	{"", regexp.MustCompile(`^/api/club/[^/]+/held(/|$)`)},
+	{"", regexp.MustCompile(`^/api/club/[^/]+/reports$`)},
+	{fiber.MethodPost, regexp.MustCompile(`^/api/club/[^/]+/rewards$`)},
+	{fiber.MethodPut, regexp.MustCompile(`^/api/club/[^/]+/rewards/[^/]+$`)},
+	{fiber.MethodPost, regexp.MustCompile(`^/api/club/[^/]+/redemptions/[^/]+/(fulfill|reject)$`)},
+	{fiber.MethodPost, regexp.MustCompile(`^/api/club/[^/]+/members/[^/]+/points$`)},
+}
+
+// requiredScope returns the scope a personal access token needs for the request, false if tokens are not allowed at all
+func requiredScope(c *fiber.Ctx) (string, bool) {
+	access := "write"
+	if c.Method() == fiber.MethodGet {
+		access = "read"
+	}
+	for _, route := range tokenForbiddenClubRoutes {
+		if (route.method == "" || route.method == c.Method()) && route.path.MatchString(c.Path()) {
+			return "", false
+		}
+	}
+	for _, rule := range tokenScopeRules {
+		if rule.path.MatchString(c.Path()) {
+			return rule.resource + ":" + access, true
+		}
+	}
+	return "", false
+}
@@ -1,23 +1,50 @@
 package routes
 
 import (
+	"database/sql"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/rhellwege/task-social/config"
 	"github.com/rhellwege/task-social/internal/api/handlers"
 	"github.com/rhellwege/task-social/internal/api/middleware"
 	"github.com/rhellwege/task-social/internal/api/services"
 	"github.com/rhellwege/task-social/internal/db/repository"
 )
 
-func SetupServicesAndRoutes(app *fiber.App, querier repository.Querier) {
-	authService := services.NewAuthService()
+// AppConfig reads the client IP from the proxy header only for requests from config.TrustedProxies
+func AppConfig() fiber.Config {
+	return fiber.Config{
+		ProxyHeader:             config.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          config.TrustedProxies,
+	}
+}
+
+// wsService is passed in because scheduled jobs also send to the connected users
+func SetupServicesAndRoutes(app *fiber.App, conn *sql.DB, querier repository.Querier, mailer services.Mailer, wsService *services.WebSocketService) {
+	keyService := services.NewSigningKeyService(querier)
+	authService := services.NewAuthService(keyService)
+	sessionService := services.NewSessionService(querier, authService)
+	tokenService := services.NewPersonalTokenService(querier)
 	imageService := services.NewImageService("./assets")
-	userService := services.NewUserService(querier, authService, imageService)
-	wsService := services.NewWebSocketService()
-	clubService := services.NewClubService(querier, imageService, wsService)
-	metricService := services.NewMetricService(querier, clubService)
-	marketplaceService := services.NewMarketplaceService(querier)
+	loginAttemptService := services.NewLoginAttemptService(querier, services.NewTransactor(conn))
+	contentFilter := services.NewContentFilter(querier, config.ContentBlockedWords, config.ContentReviewWords)
+	userService := services.NewUserService(querier, authService, imageService, sessionService, mailer, loginAttemptService, contentFilter, services.NewTransactor(conn))
+	oidcService := services.NewOIDCService(querier, userService, contentFilter)
+	accountService := services.NewAccountService(querier, authService, imageService, mailer)
+	profileService := services.NewProfileService(querier)
+	adminService := services.NewAdminService(querier, sessionService, imageService, services.NewTransactor(conn))
+	reportService := services.NewReportService(querier, adminService, imageService, services.NewTransactor(conn))
+	clubService := services.NewClubService(querier, imageService, wsService, contentFilter, services.NewTransactor(conn))
+	moderationService := services.NewModerationService(querier, imageService, wsService)
+	metricService := services.NewMetricService(querier, clubService, services.NewTransactor(conn), wsService)
+	marketplaceService := services.NewMarketplaceService(querier, imageService, contentFilter, services.NewTransactor(conn), wsService)
+	rewardService := services.NewRewardService(querier, services.NewTransactor(conn), wsService)
+	subscriptionService := services.NewSubscriptionService(querier, wsService)
+	messageService := services.NewMessageService(querier, wsService, contentFilter)
+	pointsService := services.NewPointsService(querier, services.NewTransactor(conn))
 
 	// CORS Origins should not be * but temporarily this is allowed
 	app.Use(cors.New(cors.Config{
@@ -37,20 +64,60 @@ func SetupServicesAndRoutes(app *fiber.App, querier repository.Querier) {
 
 	// Public routes
 	app.Get("/api/version", handlers.Version())
+	app.Get("/.well-known/jwks.json", handlers.GetJWKS(keyService))
 	app.Post("/api/register", handlers.RegisterUser(userService))
 	app.Post("/api/login", handlers.LoginUser(userService))
+	app.Post("/api/login/mfa", handlers.LoginMFA(userService))
+	app.Post("/api/refresh", handlers.RefreshToken(sessionService))
+	app.Post("/api/verify-email", handlers.VerifyEmail(userService))
+	app.Post("/api/password-reset", handlers.RequestPasswordReset(userService))
+	app.Post("/api/password-reset/confirm", handlers.ResetPassword(userService))
+	app.Get("/api/oidc/providers", handlers.GetOIDCProviders(oidcService))
+	app.Get("/api/oidc/:provider/authorize", handlers.OIDCAuthorize(oidcService))
+	app.Post("/api/oidc/:provider/callback", handlers.OIDCCallback(oidcService))
 
 	// Protected routes
-	api := app.Group("/api", middleware.ProtectedRoute(authService))
+	api := app.Group("/api", middleware.ProtectedRoute(authService, sessionService, tokenService))
+	// creating content requires a verified email address
+	verified := middleware.VerifiedEmailRoute(userService)
+
+	// Session routes
+	api.Post("/logout", handlers.Logout(sessionService))
+	api.Post("/logout-all", handlers.LogoutAll(sessionService))
+	api.Get("/user/sessions", handlers.GetUserSessions(sessionService))
+	api.Delete("/user/sessions/:session_id", handlers.RevokeUserSession(sessionService))
+
+	// Personal access token routes
+	api.Post("/user/tokens", handlers.CreatePersonalAccessToken(tokenService))
+	api.Get("/user/tokens", handlers.GetPersonalAccessTokens(tokenService))
+	api.Delete("/user/tokens/:token_id", handlers.RevokePersonalAccessToken(tokenService))
+
+	// Two factor auth routes
+	api.Post("/user/mfa/totp", handlers.EnrollTOTP(userService))
+	api.Post("/user/mfa/totp/enable", handlers.EnableTOTP(userService))
+	api.Delete("/user/mfa/totp", handlers.DisableTOTP(userService))
+	api.Post("/user/mfa/recovery-codes", handlers.RegenerateRecoveryCodes(userService))
+
+	// Account routes
+	api.Post("/user/deletion", handlers.RequestAccountDeletion(accountService))
+	api.Get("/user/deletion", handlers.GetAccountDeletion(accountService))
+	api.Delete("/user/deletion", handlers.CancelAccountDeletion(accountService))
+	api.Post("/user/deletion/email", handlers.SendAccountDeletionEmail(accountService))
+	api.Post("/user/deletion/confirm", handlers.ConfirmAccountDeletion(accountService))
+	api.Get("/user/export", handlers.ExportAccountData(accountService))
 
 	// Club Marketplace routes (SwapStop inside TaskSocial clubs)
 	api.Get("/club/:club_id/items", handlers.GetClubItems(marketplaceService))
-	api.Post("/club/:club_id/items", handlers.CreateClubItem(marketplaceService))
+	api.Post("/club/:club_id/items", verified, handlers.CreateClubItem(marketplaceService))
 
 	// User routes
 	api.Get("/user", handlers.GetUser(userService))
 	api.Get("/user/clubs", handlers.GetUserClubs(userService))
 	api.Put("/user", handlers.UpdateUser(userService))
+	api.Put("/user/profile", handlers.UpdateUserProfile(profileService))
+	api.Post("/user/verify-email", handlers.ResendVerificationEmail(userService))
+	api.Get("/user/identities", handlers.GetUserIdentities(oidcService))
+	api.Get("/user/login-failures", handlers.GetUserLoginFailures(loginAttemptService))
 	api.Post("/user/profile-picture",
 		middleware.ImageUploadMiddleware("./assets"),
 		handlers.UploadProfilePicture(userService),
@@ -61,11 +128,15 @@ func SetupServicesAndRoutes(app *fiber.App, querier repository.Querier) {
 	// returns all of the user's metric entries
 	api.Get("user/metric-entries", handlers.GetUserMetricEntries(userService))
 	api.Get("/user/:id", handlers.GetUserByID(userService))
+	api.Get("/user/:id/profile", handlers.GetUserProfile(profileService))
+	api.Get("/user/:id/reviews", handlers.GetUserReviews(marketplaceService))
+	api.Post("/user/:id/messages", verified, handlers.SendDirectMessage(messageService))
+	api.Get("/user/:id/messages", handlers.GetDirectMessages(messageService))
 
 	// Club routes
-	api.Post("/club", handlers.CreateClub(clubService))
+	api.Post("/club", verified, handlers.CreateClub(clubService))
 	api.Get("/clubs", handlers.GetPublicClubs(clubService))
-	api.Post("/club/:club_id/join", handlers.JoinClub(clubService))
+	api.Post("/club/:club_id/join", verified, handlers.JoinClub(clubService))
 	api.Post("/club/:club_id/leave", handlers.LeaveClub(clubService))
 	api.Get("/club/:club_id", handlers.GetClub(clubService))
 	api.Delete("/club/:club_id", handlers.DeleteClub(clubService))
@@ -78,10 +149,33 @@ func SetupServicesAndRoutes(app *fiber.App, querier repository.Querier) {
 	api.Get("/club/:club_id/metrics", handlers.GetClubMetrics(clubService))
 
 	api.Get("/club/:club_id/posts", handlers.GetClubPosts(clubService))
-	api.Post("/club/:club_id/post", handlers.CreateClubPost(clubService))
+	api.Post("/club/:club_id/post", verified, handlers.CreateClubPost(clubService))
 	api.Get("/club/:club_id/post/:post_id", handlers.GetClubPost(clubService))
 	api.Delete("/club/:club_id/post/:post_id", handlers.DeleteClubPost(clubService))
 
+	// Moderation routes, for the club's moderators and owner
+	api.Get("/club/:club_id/blocklist", handlers.GetClubBlocklist(moderationService))
+	api.Put("/club/:club_id/blocklist", handlers.SetClubBlockedTerm(moderationService))
+	api.Delete("/club/:club_id/blocklist", handlers.RemoveClubBlockedTerm(moderationService))
+	api.Get("/club/:club_id/held", handlers.GetHeldContent(moderationService))
+	api.Post("/club/:club_id/held/posts/:post_id/approve", handlers.ApproveHeldPost(moderationService))
+	api.Delete("/club/:club_id/held/posts/:post_id", handlers.RejectHeldPost(moderationService))
+	api.Post("/club/:club_id/held/items/:item_id/approve", handlers.ApproveHeldItem(moderationService))
+	api.Delete("/club/:club_id/held/items/:item_id", handlers.RejectHeldItem(moderationService))
+
+	// Point shop routes, members redeem their club points for rewards the moderators manage and hand out
+	api.Get("/club/:club_id/rewards", handlers.GetClubRewards(rewardService))
+	api.Post("/club/:club_id/rewards", handlers.CreateClubReward(rewardService))
+	api.Put("/club/:club_id/rewards/:reward_id", handlers.UpdateClubReward(rewardService))
+	api.Post("/club/:club_id/rewards/:reward_id/redeem", handlers.RedeemReward(rewardService))
+	api.Get("/club/:club_id/redemptions", handlers.GetClubRedemptions(rewardService))
+	api.Post("/club/:club_id/redemptions/:redemption_id/fulfill", handlers.FulfillRedemption(rewardService))
+	api.Post("/club/:club_id/redemptions/:redemption_id/reject", handlers.RejectRedemption(rewardService))
+
+	// Points routes, every change to a member's points is in the club's points ledger
+	api.Get("/club/:club_id/members/:user_id/points", handlers.GetPointHistory(pointsService))
+	api.Post("/club/:club_id/members/:user_id/points", handlers.AdjustPoints(pointsService))
+
 	// Metrics routes
 	api.Post("/metric", handlers.CreateMetric(metricService))
 	api.Get("/metric/:metric_id", handlers.GetMetric(metricService))
@@ -97,11 +191,70 @@ func SetupServicesAndRoutes(app *fiber.App, querier repository.Querier) {
 	api.Put("/marketplace/item/:item_id", handlers.UpdateItem(marketplaceService))
 
 	api.Delete("/marketplace/item/:item_id", handlers.DeleteItem(marketplaceService))
+	api.Post("/marketplace/item/:item_id/images",
+		middleware.ImageUploadMiddleware("./assets"),
+		handlers.AddItemImage(marketplaceService),
+	)
+	api.Delete("/marketplace/item/:item_id/images/:image_id", handlers.DeleteItemImage(marketplaceService))
+	api.Put("/marketplace/item/:item_id/reservation", handlers.ReserveItem(marketplaceService))
+	api.Delete("/marketplace/item/:item_id/reservation", handlers.ReleaseItemReservation(marketplaceService))
+	api.Get("/marketplace/item/:item_id/wishlisters", handlers.GetItemWishlisters(marketplaceService))
+
+	// Wishlist routes, only items in the user's clubs can be wishlisted
+	api.Get("/marketplace/wishlist", handlers.GetWishlist(marketplaceService))
+	api.Put("/marketplace/wishlist/:item_id", handlers.AddToWishlist(marketplaceService))
+	api.Delete("/marketplace/wishlist/:item_id", handlers.RemoveFromWishlist(marketplaceService))
+
+	// Auction routes, only the members of the item's club can see and bid on an auction
+	api.Post("/marketplace/item/:item_id/auction", verified, handlers.CreateAuction(marketplaceService))
+	api.Get("/club/:club_id/auctions", handlers.GetClubAuctions(marketplaceService))
+	api.Get("/marketplace/auctions/:auction_id", handlers.GetAuction(marketplaceService))
+	api.Post("/marketplace/auctions/:auction_id/bids", verified, handlers.PlaceBid(marketplaceService))
+	api.Post("/marketplace/auctions/:auction_id/cancel", handlers.CancelAuction(marketplaceService))
+
+	// Search routes, only items in the user's clubs are found
+	api.Get("/marketplace/search", handlers.SearchItems(marketplaceService))
+	api.Get("/marketplace/saved-searches", handlers.GetSavedSearches(marketplaceService))
+	api.Post("/marketplace/saved-searches", handlers.CreateSavedSearch(marketplaceService))
+	api.Delete("/marketplace/saved-searches/:search_id", handlers.DeleteSavedSearch(marketplaceService))
+
+	// Trade routes, only the two parties of a trade can see it
+	api.Post("/marketplace/trades", verified, handlers.ProposeTrade(marketplaceService))
+	api.Get("/marketplace/trades/incoming", handlers.GetIncomingTrades(marketplaceService))
+	api.Get("/marketplace/trades/outgoing", handlers.GetOutgoingTrades(marketplaceService))
+	api.Get("/marketplace/trades/:trade_id", handlers.GetTrade(marketplaceService))
+	api.Post("/marketplace/trades/:trade_id/counter", verified, handlers.CounterTrade(marketplaceService))
+	api.Post("/marketplace/trades/:trade_id/accept", handlers.AcceptTrade(marketplaceService))
+	api.Post("/marketplace/trades/:trade_id/reject", handlers.RejectTrade(marketplaceService))
+	api.Post("/marketplace/trades/:trade_id/cancel", handlers.CancelTrade(marketplaceService))
+	api.Post("/marketplace/trades/:trade_id/review", handlers.ReviewTrade(marketplaceService))
+
+	// Report routes, handled by club moderators or site admins depending on what was reported
+	api.Post("/reports", handlers.CreateReport(reportService))
+	api.Get("/reports", handlers.GetUserReports(reportService))
+	api.Get("/reports/:report_id", handlers.GetReport(reportService))
+	api.Post("/reports/:report_id/triage", handlers.TriageReport(reportService))
+	api.Post("/reports/:report_id/resolve", handlers.ResolveReport(reportService))
+	api.Get("/club/:club_id/reports", handlers.GetClubReports(reportService))
+
+	// Admin routes, every change is written to the audit log
+	admin := api.Group("/admin", middleware.AdminRoute(adminService))
+	admin.Get("/users", handlers.AdminSearchUsers(adminService))
+	admin.Post("/users/:user_id/disable", handlers.AdminDisableUser(adminService))
+	admin.Post("/users/:user_id/enable", handlers.AdminEnableUser(adminService))
+	admin.Put("/users/:user_id/role", handlers.AdminSetUserRole(adminService))
+	admin.Post("/users/:user_id/impersonate", handlers.AdminImpersonateUser(adminService))
+	admin.Get("/clubs", handlers.AdminSearchClubs(adminService))
+	admin.Delete("/clubs/:club_id", handlers.AdminDeleteClub(adminService))
+	admin.Delete("/posts/:post_id", handlers.AdminDeleteClubPost(adminService))
+	admin.Delete("/items/:item_id", handlers.AdminDeleteItem(adminService))
+	admin.Get("/reports", handlers.AdminGetReports(reportService))
+	admin.Get("/audit-log", handlers.AdminGetAuditLog(adminService))
 
 	// Serve uploaded assets
 	app.Static("/assets", "./assets")
 
 	// WebSocket route
-	ws := app.Group("/ws", middleware.WebSocketUpgrade, middleware.ProtectedRoute(authService))
-	ws.Get("/", handlers.WebSocketHandler(wsService))
+	ws := app.Group("/ws", middleware.WebSocketUpgrade, middleware.ProtectedRoute(authService, sessionService, tokenService))
+	ws.Get("/", handlers.WebSocketHandler(wsService, subscriptionService))
 }
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+)
+
+type AccountServicer interface {
+	// schedules the account for deletion after config.AccountDeletionCoolingOff, returns when it will happen
+	RequestDeletion(ctx context.Context, userID string, password string) (time.Time, error)
+	// for accounts without a password, which confirm the deletion with a token sent to their email address
+	SendDeletionConfirmation(ctx context.Context, userID string) error
+	// schedules the deletion like RequestDeletion, the token must belong to the user
+	ConfirmDeletion(ctx context.Context, userID string, token string) (time.Time, error)
+	CancelDeletion(ctx context.Context, userID string) error
+	// nil when no deletion is scheduled
+	GetDeletionScheduledAt(ctx context.Context, userID string) (*time.Time, error)
+	// ZIP archive with the user's data as JSON and CSV and the images they uploaded
+	ExportData(ctx context.Context, userID string) ([]byte, error)
+}
+
+type AccountService struct {
+	q repository.Querier
+	a AuthServicer
+	i ImageServicer
+	m Mailer
+}
+
+var _ AccountServicer = (*AccountService)(nil)
+
+func NewAccountService(q repository.Querier, a AuthServicer, i ImageServicer, m Mailer) *AccountService {
+	return &AccountService{q: q, a: a, i: i, m: m}
+}
+
+var (
+	ErrIncorrectPassword   = errors.New("incorrect password")
+	ErrNoDeletionScheduled = errors.New("no account deletion is scheduled")
+	ErrDeletionScheduled   = errors.New("account deletion is already scheduled")
+	ErrNoPassword          = errors.New("this account has no password, confirm the deletion by email")
+	ErrHasPassword         = errors.New("this account has a password, confirm the deletion with it")
+)
+
+func (s *AccountService) RequestDeletion(ctx context.Context, userID string, password string) (time.Time, error) {
+	hash, err := s.q.GetUserPasswordHash(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// accounts created through an identity provider have no password
+	if hash == "" {
+		return time.Time{}, ErrNoPassword
+	}
+	if err := s.a.VerifyPassword(ctx, password, hash); err != nil {
+		return time.Time{}, ErrIncorrectPassword
+	}
+	return s.scheduleDeletion(ctx, userID)
+}
+
+func (s *AccountService) SendDeletionConfirmation(ctx context.Context, userID string) error {
+	hash, err := s.q.GetUserPasswordHash(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if hash != "" {
+		return ErrHasPassword
+	}
+	scheduledAt, err := s.GetDeletionScheduledAt(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if scheduledAt != nil {
+		return ErrDeletionScheduled
+	}
+
+	token, err := issueUserToken(ctx, s.q, userID, TokenPurposeDeleteAccount, config.DeleteAccountTokenDuration)
+	if err != nil {
+		return err
+	}
+	user, err := s.q.GetUserEmail(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.m.Send(ctx, Email{
+		To:      user.Email,
+		Subject: "Confirm deleting your Task Social account",
+		Body: fmt.Sprintf("Someone asked to delete your account. Confirm it by opening this link while you are logged in:\n%s\n\nThe link expires in %s. If this was not you, ignore this email and log out your other sessions.\n",
+			emailLink("confirm-deletion", token), config.DeleteAccountTokenDuration),
+	})
+}
+
+func (s *AccountService) ConfirmDeletion(ctx context.Context, userID string, token string) (time.Time, error) {
+	tokenUserID, err := consumeUserToken(ctx, s.q, token, TokenPurposeDeleteAccount)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// the link alone is not enough, it has to be opened by the account it was sent to
+	if tokenUserID != userID {
+		return time.Time{}, ErrInvalidEmailToken
+	}
+	return s.scheduleDeletion(ctx, userID)
+}
+
+func (s *AccountService) scheduleDeletion(ctx context.Context, userID string) (time.Time, error) {
+	scheduledAt, err := s.GetDeletionScheduledAt(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if scheduledAt != nil {
+		return time.Time{}, ErrDeletionScheduled
+	}
+
+	deleteAt := time.Now().Add(config.AccountDeletionCoolingOff)
+	err = s.q.ScheduleUserDeletion(ctx, repository.ScheduleUserDeletionParams{
+		ID:                  userID,
+		DeletionScheduledAt: &deleteAt,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// the owner should hear about it even if someone else used their session
+	user, err := s.q.GetUserEmail(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	err = s.m.Send(ctx, Email{
+		To:      user.Email,
+		Subject: "Your Task Social account will be deleted",
+		Body: fmt.Sprintf("Your account is scheduled for deletion on %s.\n\nUntil then you can log in and cancel the deletion from your account settings. If this was not you, cancel it and change your password.\n",
+			deleteAt.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		log.Printf("Failed to send account deletion notice: %v", err)
+	}
+	return deleteAt, nil
+}
+
+func (s *AccountService) CancelDeletion(ctx context.Context, userID string) error {
+	scheduledAt, err := s.GetDeletionScheduledAt(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if scheduledAt == nil {
+		return ErrNoDeletionScheduled
+	}
+	return s.q.CancelUserDeletion(ctx, userID)
+}
+
+func (s *AccountService) GetDeletionScheduledAt(ctx context.Context, userID string) (*time.Time, error) {
+	return s.q.GetUserDeletionScheduledAt(ctx, userID)
+}
+
+func (s *AccountService) ExportData(ctx context.Context, userID string) ([]byte, error) {
+	profile, err := s.q.GetUserExportProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	clubs, err := s.q.GetUserClubs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.q.GetUserExportPosts(ctx, &userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.q.GetUserExportMetricEntries(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.q.GetItemsByOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.q.GetUserExportMessages(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.q.GetUserLinks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ownedClubs, err := s.q.GetOwnedClubs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	tables := []struct {
+		name string
+		rows any
+	}{
+		{"profile", []repository.GetUserExportProfileRow{profile}},
+		{"links", links},
+		{"clubs", clubs},
+		{"posts", posts},
+		{"metric_entries", entries},
+		{"items", items},
+		{"messages", messages},
+	}
+	for _, table := range tables {
+		if err := writeExportTable(archive, table.name, table.rows); err != nil {
+			return nil, err
+		}
+	}
+
+	images := []*string{profile.ProfilePicture}
+	for _, club := range ownedClubs {
+		images = append(images, club.BannerImage)
+	}
+	for _, url := range images {
+		if url == nil || *url == "" {
+			continue
+		}
+		data, err := s.i.ReadImage(*url)
+		if err != nil {
+			// a missing file should not keep the user from the rest of their data
+			log.Printf("Failed to add %s to data export: %v", *url, err)
+			continue
+		}
+		w, err := archive.Create(path.Join("images", strings.TrimPrefix(*url, "/assets/")))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeExportTable adds <name>.json and <name>.csv for a slice of query rows
+func writeExportTable(archive *zip.Writer, name string, rows any) error {
+	w, err := archive.Create(name + ".json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(rows); err != nil {
+		return err
+	}
+
+	w, err = archive.Create(name + ".csv")
+	if err != nil {
+		return err
+	}
+	return writeCSV(w, rows)
+}
+
+// writeCSV writes a slice of structs with a header row taken from the json tags
+func writeCSV(w io.Writer, rows any) error {
+	records := csv.NewWriter(w)
+	v := reflect.ValueOf(rows)
+	t := v.Type().Elem()
+
+	header := make([]string, t.NumField())
+	for i := range header {
+		header[i], _, _ = strings.Cut(t.Field(i).Tag.Get("json"), ",")
+	}
+	if err := records.Write(header); err != nil {
+		return err
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		record := make([]string, row.NumField())
+		for j := range record {
+			record[j] = csvValue(row.Field(j))
+		}
+		if err := records.Write(record); err != nil {
+			return err
+		}
+	}
+	records.Flush()
+	return records.Error()
+}
+
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// DeleteScheduledAccounts deletes the accounts whose cooling-off period is over.
+// posts stay in their clubs without an author, owned clubs go to a moderator or the longest standing member
+// and are deleted when nobody else is left. an account that fails is logged and tried again on the next run
+func DeleteScheduledAccounts(ctx context.Context, q repository.Querier, tx Transactor, i ImageServicer, now time.Time) error {
+	users, err := q.GetUsersDueForDeletion(ctx, &now)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if err := deleteAccount(ctx, tx, i, user); err != nil {
+			log.Printf("Failed to delete account %s: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// deleteAccount removes the uploaded files only after the account is gone, a failed deletion keeps them
+func deleteAccount(ctx context.Context, tx Transactor, i ImageServicer, user repository.GetUsersDueForDeletionRow) error {
+	var bannerImages []*string
+	var itemImages []repository.ItemImage
+	err := tx.WithTx(ctx, func(q repository.Querier) error {
+		clubs, err := q.GetOwnedClubs(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		for _, club := range clubs {
+			successor, err := q.GetClubSuccessor(ctx, repository.GetClubSuccessorParams{
+				ClubID: club.ID,
+				UserID: user.ID,
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				images, err := deleteClubRecord(ctx, q, club.ID)
+				if err != nil {
+					return err
+				}
+				bannerImages = append(bannerImages, club.BannerImage)
+				itemImages = append(itemImages, images...)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			err = q.TransferClubOwnership(ctx, repository.TransferClubOwnershipParams{
+				ID:          club.ID,
+				OwnerUserID: successor,
+			})
+			if err != nil {
+				return err
+			}
+			isModerator := true
+			err = q.UpdateClubMembership(ctx, repository.UpdateClubMembershipParams{
+				UserID:      successor,
+				ClubID:      club.ID,
+				IsModerator: &isModerator,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// the user's items in deleted clubs went with them and are not listed again
+		images, err := q.GetItemImagesByOwner(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		itemImages = append(itemImages, images...)
+
+		// everything else the user owns goes with the account through ON DELETE CASCADE
+		return q.DeleteUser(ctx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, banner := range bannerImages {
+		deleteUploadedImage(i, banner)
+	}
+	deleteUploadedImage(i, user.ProfilePicture)
+	deleteItemImageFiles(i, itemImages)
+	return nil
+}
+
+func deleteUploadedImage(i ImageServicer, url *string) {
+	if url == nil || *url == "" {
+		return
+	}
+	dir, filename := path.Split(strings.TrimPrefix(*url, "/assets/"))
+	if err := i.DeleteImage(strings.TrimSuffix(dir, "/"), filename); err != nil {
+		log.Printf("Failed to delete image %s: %v", *url, err)
+	}
+}
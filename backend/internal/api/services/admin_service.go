@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+type AdminServicer interface {
+	IsAdmin(ctx context.Context, userID string) (bool, error)
+	// matches part of the username or email, an empty query lists everyone
+	SearchUsers(ctx context.Context, query string, limit int64, offset int64) ([]repository.SearchUsersRow, error)
+	// matches part of the club or owner name, private clubs included
+	SearchClubs(ctx context.Context, query string, limit int64, offset int64) ([]repository.SearchClubsRow, error)
+	// logs the user out everywhere and blocks new logins until the account is enabled again
+	DisableUser(ctx context.Context, req AdminRequest, userID string) error
+	EnableUser(ctx context.Context, req AdminRequest, userID string) error
+	SetUserRole(ctx context.Context, req AdminRequest, userID string, role string) error
+	DeleteClub(ctx context.Context, req AdminRequest, clubID string) error
+	DeleteClubPost(ctx context.Context, req AdminRequest, postID string) error
+	DeleteItem(ctx context.Context, req AdminRequest, itemID string) error
+	// starts a short, read-only session as the user, it shows up in the user's session list
+	ImpersonateUser(ctx context.Context, req AdminRequest, userID string) (Impersonation, error)
+	GetAuditLog(ctx context.Context, filter AuditLogFilter) ([]repository.GetAdminAuditLogRow, error)
+}
+
+type AdminService struct {
+	q  repository.Querier
+	s  SessionServicer
+	i  ImageServicer
+	tx Transactor
+}
+
+var _ AdminServicer = (*AdminService)(nil)
+
+func NewAdminService(q repository.Querier, s SessionServicer, i ImageServicer, tx Transactor) *AdminService {
+	return &AdminService{q: q, s: s, i: i, tx: tx}
+}
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// actions written to the audit log
+const (
+	AdminActionDisableUser     = "disable_user"
+	AdminActionEnableUser      = "enable_user"
+	AdminActionSetRole         = "set_role"
+	AdminActionDeleteClub      = "delete_club"
+	AdminActionDeletePost      = "delete_post"
+	AdminActionDeleteItem      = "delete_item"
+	AdminActionImpersonateUser = "impersonate_user"
+)
+
+const (
+	AdminTargetUser = "user"
+	AdminTargetClub = "club"
+	AdminTargetPost = "post"
+	AdminTargetItem = "item"
+)
+
+var (
+	ErrAdminReasonRequired = fmt.Errorf("a reason of at most %d characters is required", config.MaxAdminReasonLength)
+	ErrAdminTargetSelf     = errors.New("admins cannot use this action on their own account")
+	ErrAdminTargetAdmin    = errors.New("this action cannot be used on another admin, remove their admin role first")
+	ErrInvalidRole         = fmt.Errorf("role must be %s or %s", RoleUser, RoleAdmin)
+	ErrUserAlreadyDisabled = errors.New("user is already disabled")
+	ErrUserNotDisabled     = errors.New("user is not disabled")
+	ErrClubNotFound        = errors.New("club not found")
+	ErrPostNotFound        = errors.New("post not found")
+	ErrItemNotFound        = errors.New("item not found")
+)
+
+// AdminRequest identifies who is acting and why, every admin action is recorded with it
+type AdminRequest struct {
+	AdminID string
+	Reason  string
+	Client  ClientInfo
+	// set when the action resolves a report
+	ReportID *string
+}
+
+type Impersonation struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type AuditLogFilter struct {
+	AdminID  string
+	TargetID string
+	Limit    int64
+	Offset   int64
+}
+
+func (s *AdminService) IsAdmin(ctx context.Context, userID string) (bool, error) {
+	role, err := s.q.GetUserRole(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return role == RoleAdmin, err
+}
+
+func (s *AdminService) SearchUsers(ctx context.Context, query string, limit int64, offset int64) ([]repository.SearchUsersRow, error) {
+	return s.q.SearchUsers(ctx, repository.SearchUsersParams{
+		Query:  strings.TrimSpace(query),
+		Limit:  adminPageSize(limit),
+		Offset: max(offset, 0),
+	})
+}
+
+func (s *AdminService) SearchClubs(ctx context.Context, query string, limit int64, offset int64) ([]repository.SearchClubsRow, error) {
+	return s.q.SearchClubs(ctx, repository.SearchClubsParams{
+		Query:  strings.TrimSpace(query),
+		Limit:  adminPageSize(limit),
+		Offset: max(offset, 0),
+	})
+}
+
+// every action commits together with its audit log entry, uploaded files are removed once it did
+func (s *AdminService) DisableUser(ctx context.Context, req AdminRequest, userID string) error {
+	return s.tx.WithTx(ctx, func(q repository.Querier) error {
+		return disableUser(ctx, q, req, userID)
+	})
+}
+
+func (s *AdminService) EnableUser(ctx context.Context, req AdminRequest, userID string) error {
+	return s.tx.WithTx(ctx, func(q repository.Querier) error {
+		return enableUser(ctx, q, req, userID)
+	})
+}
+
+func (s *AdminService) SetUserRole(ctx context.Context, req AdminRequest, userID string, role string) error {
+	return s.tx.WithTx(ctx, func(q repository.Querier) error {
+		return setUserRole(ctx, q, req, userID, role)
+	})
+}
+
+func (s *AdminService) DeleteClub(ctx context.Context, req AdminRequest, clubID string) error {
+	var club repository.Club
+	var images []repository.ItemImage
+	err := s.tx.WithTx(ctx, func(q repository.Querier) error {
+		var err error
+		club, images, err = adminDeleteClub(ctx, q, req, clubID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	deleteUploadedImage(s.i, club.BannerImage)
+	deleteItemImageFiles(s.i, images)
+	return nil
+}
+
+func (s *AdminService) DeleteClubPost(ctx context.Context, req AdminRequest, postID string) error {
+	return s.tx.WithTx(ctx, func(q repository.Querier) error {
+		return adminDeleteClubPost(ctx, q, req, postID)
+	})
+}
+
+func (s *AdminService) DeleteItem(ctx context.Context, req AdminRequest, itemID string) error {
+	var images []repository.ItemImage
+	err := s.tx.WithTx(ctx, func(q repository.Querier) error {
+		var err error
+		images, err = adminDeleteItem(ctx, q, req, itemID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	deleteItemImageFiles(s.i, images)
+	return nil
+}
+
+func disableUser(ctx context.Context, q repository.Querier, req AdminRequest, userID string) error {
+	if err := checkUserTarget(ctx, q, req, userID); err != nil {
+		return err
+	}
+
+	rows, err := q.DisableUser(ctx, repository.DisableUserParams{
+		ID:             userID,
+		DisabledReason: &req.Reason,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserAlreadyDisabled
+	}
+	if err := q.RevokeAllUserSessions(ctx, userID); err != nil {
+		return err
+	}
+	if err := q.RevokeAllPersonalAccessTokens(ctx, userID); err != nil {
+		return err
+	}
+	return audit(ctx, q, req, AdminActionDisableUser, AdminTargetUser, userID)
+}
+
+func enableUser(ctx context.Context, q repository.Querier, req AdminRequest, userID string) error {
+	if err := checkUserTarget(ctx, q, req, userID); err != nil {
+		return err
+	}
+
+	rows, err := q.EnableUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotDisabled
+	}
+	return audit(ctx, q, req, AdminActionEnableUser, AdminTargetUser, userID)
+}
+
+func setUserRole(ctx context.Context, q repository.Querier, req AdminRequest, userID string, role string) error {
+	if err := validateAdminReason(req.Reason); err != nil {
+		return err
+	}
+	if role != RoleUser && role != RoleAdmin {
+		return ErrInvalidRole
+	}
+	// otherwise the last admin could lock everyone out
+	if userID == req.AdminID {
+		return ErrAdminTargetSelf
+	}
+
+	rows, err := q.SetUserRole(ctx, repository.SetUserRoleParams{
+		ID:   userID,
+		Role: role,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return audit(ctx, q, req, AdminActionSetRole, AdminTargetUser, userID)
+}
+
+// adminDeleteClub returns the deleted club and the photos of its items, their files and the banner are removed by the caller
+func adminDeleteClub(ctx context.Context, q repository.Querier, req AdminRequest, clubID string) (repository.Club, []repository.ItemImage, error) {
+	if err := validateAdminReason(req.Reason); err != nil {
+		return repository.Club{}, nil, err
+	}
+
+	club, err := q.GetClub(ctx, clubID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.Club{}, nil, ErrClubNotFound
+	}
+	if err != nil {
+		return repository.Club{}, nil, err
+	}
+
+	images, err := deleteClubRecord(ctx, q, clubID)
+	if err != nil {
+		return repository.Club{}, nil, err
+	}
+	return club, images, audit(ctx, q, req, AdminActionDeleteClub, AdminTargetClub, clubID)
+}
+
+func adminDeleteClubPost(ctx context.Context, q repository.Querier, req AdminRequest, postID string) error {
+	if err := validateAdminReason(req.Reason); err != nil {
+		return err
+	}
+
+	_, err := q.GetClubPost(ctx, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPostNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := q.DeleteClubPost(ctx, postID); err != nil {
+		return err
+	}
+	return audit(ctx, q, req, AdminActionDeletePost, AdminTargetPost, postID)
+}
+
+// adminDeleteItem returns the item's photos, their files are removed by the caller
+func adminDeleteItem(ctx context.Context, q repository.Querier, req AdminRequest, itemID string) ([]repository.ItemImage, error) {
+	if err := validateAdminReason(req.Reason); err != nil {
+		return nil, err
+	}
+
+	_, err := q.GetItem(ctx, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := deleteItemRecord(ctx, q, itemID)
+	if err != nil {
+		return nil, err
+	}
+	return images, audit(ctx, q, req, AdminActionDeleteItem, AdminTargetItem, itemID)
+}
+
+func (s *AdminService) ImpersonateUser(ctx context.Context, req AdminRequest, userID string) (Impersonation, error) {
+	if err := checkUserTarget(ctx, s.q, req, userID); err != nil {
+		return Impersonation{}, err
+	}
+
+	// logged first, nobody gets a token without a record of it
+	if err := audit(ctx, s.q, req, AdminActionImpersonateUser, AdminTargetUser, userID); err != nil {
+		return Impersonation{}, err
+	}
+	expiresAt := time.Now().Add(config.ImpersonationDuration)
+	token, err := s.s.CreateImpersonationSession(ctx, userID, req.AdminID, req.Client)
+	if err != nil {
+		return Impersonation{}, err
+	}
+	return Impersonation{AccessToken: token, ExpiresAt: expiresAt}, nil
+}
+
+func (s *AdminService) GetAuditLog(ctx context.Context, filter AuditLogFilter) ([]repository.GetAdminAuditLogRow, error) {
+	return s.q.GetAdminAuditLog(ctx, repository.GetAdminAuditLogParams{
+		AdminID:  filter.AdminID,
+		TargetID: filter.TargetID,
+		Limit:    adminPageSize(filter.Limit),
+		Offset:   max(filter.Offset, 0),
+	})
+}
+
+// checkUserTarget rejects actions against missing users, the acting admin and other admins
+func checkUserTarget(ctx context.Context, q repository.Querier, req AdminRequest, userID string) error {
+	if err := validateAdminReason(req.Reason); err != nil {
+		return err
+	}
+	if userID == req.AdminID {
+		return ErrAdminTargetSelf
+	}
+	role, err := q.GetUserRole(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if role == RoleAdmin {
+		return ErrAdminTargetAdmin
+	}
+	return nil
+}
+
+func audit(ctx context.Context, q repository.Querier, req AdminRequest, action string, targetType string, targetID string) error {
+	return q.CreateAdminAuditLogEntry(ctx, repository.CreateAdminAuditLogEntryParams{
+		ID:         util.GenerateUUID(),
+		AdminID:    req.AdminID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     strings.TrimSpace(req.Reason),
+		IpAddress:  req.Client.IP,
+		ReportID:   req.ReportID,
+		CreatedAt:  time.Now(),
+	})
+}
+
+func validateAdminReason(reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > config.MaxAdminReasonLength {
+		return ErrAdminReasonRequired
+	}
+	return nil
+}
+
+func adminPageSize(limit int64) int64 {
+	if limit <= 0 {
+		return config.DefaultAdminPageSize
+	}
+	return min(limit, config.MaxAdminPageSize)
+}
+
+// GrantAdminRoles promotes the accounts that verified one of the given emails, used to bootstrap the first admins from ADMIN_EMAILS
+func GrantAdminRoles(ctx context.Context, q repository.Querier, emails []string) error {
+	for _, email := range emails {
+		rows, err := q.GrantAdminRoleByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+		if rows > 0 {
+			log.Printf("Granted the admin role to %s", email)
+		}
+	}
+	return nil
+}
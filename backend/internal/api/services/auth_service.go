@@ -2,9 +2,16 @@ package services
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 	"unicode"
 
@@ -21,17 +28,29 @@ type AuthServicer interface {
 	HashPassword(ctx context.Context, password string) (string, error)
 	// returns nil on success
 	VerifyPassword(ctx context.Context, password, hashedPassword string) error
-	GenerateToken(ctx context.Context, userId string) (string, error)
+	// sessionID is embedded as the "sid" claim so the token dies with its session
+	GenerateToken(ctx context.Context, userId string, sessionID string) (string, error)
 	VerifyToken(ctx context.Context, tokenString string) (*jwt.Token, error)
+	// short lived token proving the password step of a two factor login succeeded
+	GenerateMFAToken(ctx context.Context, userID string) (string, error)
+	// returns the user id of a valid mfa token
+	VerifyMFAToken(ctx context.Context, tokenString string) (string, error)
+	GenerateTOTPSecret(ctx context.Context) (string, error)
+	// otpauth:// uri that authenticator apps read from a QR code
+	TOTPProvisioningURI(ctx context.Context, secret string, accountName string) string
+	// returns the time step the code matched
+	VerifyTOTP(ctx context.Context, secret string, code string) (int64, error)
 }
 
-type AuthService struct{}
+type AuthService struct {
+	k SigningKeyServicer
+}
 
 // compile time check
 var _ AuthServicer = (*AuthService)(nil)
 
-func NewAuthService() *AuthService {
-	return &AuthService{}
+func NewAuthService(k SigningKeyServicer) *AuthService {
+	return &AuthService{k: k}
 }
 
 // returns nil on success
@@ -95,31 +114,40 @@ func (s *AuthService) HashPassword(ctx context.Context, password string) (string
 	return textHash, nil
 }
 
-func (s *AuthService) GenerateToken(ctx context.Context, userId string) (string, error) {
-	token := jwt.NewWithClaims(config.JWTSigningMethod, jwt.MapClaims{
+func (s *AuthService) GenerateToken(ctx context.Context, userId string, sessionID string) (string, error) {
+	return s.signToken(ctx, jwt.MapClaims{
 		"sub": userId,
-		"exp": time.Now().Add(config.TokenExpirationDuration).Unix(),
+		"sid": sessionID,
+		"exp": time.Now().Add(config.AccessTokenDuration).Unix(),
 	})
-	if len(config.JWTSecret) == 0 {
-		panic("JWT secret is empty. Set with JWT_SECRET_KEY env variable.")
-	}
-	return token.SignedString([]byte(config.JWTSecret))
 }
 
 func (s *AuthService) VerifyToken(ctx context.Context, tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		if len(config.JWTSecret) == 0 {
-			panic("JWT secret is empty. Set with JWT_SECRET_KEY env variable.")
+	return jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := s.k.VerificationKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		// the header is attacker controlled, never let it pick how the key is used
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, errors.New("invalid token: incorrect signing method")
 		}
-		return []byte(config.JWTSecret), nil
+		return key.Public, nil
 	})
+}
+
+// signToken signs with the current key and names it in the "kid" header so verifiers can find it after a rotation
+func (s *AuthService) signToken(ctx context.Context, claims jwt.MapClaims) (string, error) {
+	key, err := s.k.SigningKey(ctx)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	if token.Method != config.JWTSigningMethod {
-		return nil, errors.New("invalid token: incorrect signing method")
+	token := jwt.NewWithClaims(key.Method, claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
 	}
-	return token, nil
+	return token.SignedString(key.Private)
 }
 
 // VerifyPassword returns nil on success
@@ -130,3 +158,77 @@ func (s *AuthService) VerifyPassword(ctx context.Context, password string, hashe
 	}
 	return bcrypt.CompareHashAndPassword(hashedBytes, []byte(password))
 }
+
+const mfaTokenType = "mfa"
+
+func (s *AuthService) GenerateMFAToken(ctx context.Context, userID string) (string, error) {
+	return s.signToken(ctx, jwt.MapClaims{
+		"sub": userID,
+		"typ": mfaTokenType,
+		"exp": time.Now().Add(config.MFATokenDuration).Unix(),
+	})
+}
+
+func (s *AuthService) VerifyMFAToken(ctx context.Context, tokenString string) (string, error) {
+	token, err := s.VerifyToken(ctx, tokenString)
+	if err != nil {
+		return "", err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != mfaTokenType {
+		return "", errors.New("invalid token: not an mfa token")
+	}
+	return claims.GetSubject()
+}
+
+func (s *AuthService) GenerateTOTPSecret(ctx context.Context) (string, error) {
+	secret := make([]byte, config.TOTPSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+func (s *AuthService) TOTPProvisioningURI(ctx context.Context, secret string, accountName string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", config.TOTPIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(config.TOTPDigits))
+	params.Set("period", fmt.Sprint(int(config.TOTPPeriod.Seconds())))
+	label := url.PathEscape(config.TOTPIssuer + ":" + accountName)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+func (s *AuthService) VerifyTOTP(ctx context.Context, secret string, code string) (int64, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, err
+	}
+	code = strings.TrimSpace(code)
+	current := time.Now().Unix() / int64(config.TOTPPeriod.Seconds())
+	for step := current - config.TOTPSkewSteps; step <= current+config.TOTPSkewSteps; step++ {
+		if hmac.Equal([]byte(totpCode(key, step, config.TOTPDigits)), []byte(code)) {
+			return step, nil
+		}
+	}
+	return 0, errors.New("invalid two factor code")
+}
+
+// totpCode implements the HOTP truncation from RFC 4226 with the time step as counter (RFC 6238)
+func totpCode(key []byte, step int64, digits int) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
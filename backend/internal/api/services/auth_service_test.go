@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"encoding/base32"
 	"testing"
 	"time"
 
@@ -13,7 +14,7 @@ import (
 func TestAuthService(t *testing.T) {
 	config.JWTSecret = []byte("test-secret")
 	t.Run("HashPassword and VerifyPassword", func(t *testing.T) {
-		authService := NewAuthService()
+		authService := NewAuthService(NewHMACKeyService())
 		ctx := context.Background()
 
 		testCases := []struct {
@@ -58,24 +59,26 @@ func TestAuthService(t *testing.T) {
 	})
 
 	t.Run("GenerateToken and VerifyToken", func(t *testing.T) {
-		authService := NewAuthService()
+		authService := NewAuthService(NewHMACKeyService())
 		ctx := context.Background()
 
 		testCases := []struct {
 			name          string
 			userID        string
+			sessionID     string
 			expectedError bool
 		}{
 			{
 				name:          "Valid user ID",
 				userID:        "test-user-id",
+				sessionID:     "test-session-id",
 				expectedError: false,
 			},
 		}
 
 		for _, tc := range testCases {
 			t.Run(tc.name, func(t *testing.T) {
-				token, err := authService.GenerateToken(ctx, tc.userID)
+				token, err := authService.GenerateToken(ctx, tc.userID, tc.sessionID)
 				if tc.expectedError {
 					assert.Error(t, err)
 					return
@@ -90,16 +93,20 @@ func TestAuthService(t *testing.T) {
 				subject, err := parsedToken.Claims.GetSubject()
 				assert.NoError(t, err)
 				assert.Equal(t, tc.userID, subject)
+
+				sessionID, err := SessionIDFromToken(parsedToken)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.sessionID, sessionID)
 			})
 		}
 	})
 
 	t.Run("Corrupt Token", func(t *testing.T) {
-		authService := NewAuthService()
+		authService := NewAuthService(NewHMACKeyService())
 		ctx := context.Background()
 		userID := "test-user-id"
 
-		tokenString, err := authService.GenerateToken(ctx, userID)
+		tokenString, err := authService.GenerateToken(ctx, userID, "test-session-id")
 		assert.NoError(t, err)
 
 		testCases := []struct {
@@ -132,7 +139,7 @@ func TestAuthService(t *testing.T) {
 	})
 
 	t.Run("Token expiration", func(t *testing.T) {
-		authService := NewAuthService()
+		authService := NewAuthService(NewHMACKeyService())
 		ctx := context.Background()
 
 		testCases := []struct {
@@ -173,8 +180,88 @@ func TestAuthService(t *testing.T) {
 		}
 	})
 
+	t.Run("TOTP codes", func(t *testing.T) {
+		// test vectors from RFC 6238 appendix B, truncated to 6 digits
+		key := []byte("12345678901234567890")
+		testCases := []struct {
+			name     string
+			unixTime int64
+			expected string
+		}{
+			{name: "T=59", unixTime: 59, expected: "287082"},
+			{name: "T=1111111109", unixTime: 1111111109, expected: "081804"},
+			{name: "T=1234567890", unixTime: 1234567890, expected: "005924"},
+			{name: "T=20000000000", unixTime: 20000000000, expected: "353130"},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.expected, totpCode(key, tc.unixTime/30, 6))
+			})
+		}
+	})
+
+	t.Run("VerifyTOTP", func(t *testing.T) {
+		authService := NewAuthService(NewHMACKeyService())
+		ctx := context.Background()
+
+		secret, err := authService.GenerateTOTPSecret(ctx)
+		assert.NoError(t, err)
+		key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+		assert.NoError(t, err)
+
+		step := time.Now().Unix() / int64(config.TOTPPeriod.Seconds())
+		testCases := []struct {
+			name          string
+			code          string
+			expectedError bool
+		}{
+			{name: "Current code", code: totpCode(key, step, config.TOTPDigits), expectedError: false},
+			{name: "Previous code within skew", code: totpCode(key, step-1, config.TOTPDigits), expectedError: false},
+			{name: "Code outside skew", code: totpCode(key, step-3, config.TOTPDigits), expectedError: true},
+			{name: "Garbage", code: "abcdef", expectedError: true},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := authService.VerifyTOTP(ctx, secret, tc.code)
+				if tc.expectedError {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			})
+		}
+
+		uri := authService.TOTPProvisioningURI(ctx, secret, "alice")
+		assert.Contains(t, uri, "otpauth://totp/")
+		assert.Contains(t, uri, "secret="+secret)
+	})
+
+	t.Run("MFA token", func(t *testing.T) {
+		authService := NewAuthService(NewHMACKeyService())
+		ctx := context.Background()
+
+		mfaToken, err := authService.GenerateMFAToken(ctx, "test-user-id")
+		assert.NoError(t, err)
+		userID, err := authService.VerifyMFAToken(ctx, mfaToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "test-user-id", userID)
+
+		// a session token is not an mfa token and vice versa
+		accessToken, err := authService.GenerateToken(ctx, "test-user-id", "test-session-id")
+		assert.NoError(t, err)
+		_, err = authService.VerifyMFAToken(ctx, accessToken)
+		assert.Error(t, err)
+
+		parsed, err := authService.VerifyToken(ctx, mfaToken)
+		assert.NoError(t, err)
+		_, err = SessionIDFromToken(parsed)
+		assert.Error(t, err)
+	})
+
 	t.Run("Password Strength", func(t *testing.T) {
-		authService := NewAuthService()
+		authService := NewAuthService(NewHMACKeyService())
 		ctx := context.Background()
 
 		testCases := []struct {
@@ -23,22 +23,25 @@ type ClubServicer interface {
 	UploadClubBanner(ctx context.Context, userID string, clubID string, fileBytes []byte) (string, error)
 	GetClubMetrics(ctx context.Context, userID string, clubID string) ([]repository.Metric, error)
 	GetClubPosts(ctx context.Context, userID string, clubID string) ([]repository.GetClubPostsRow, error)
-	CreateClubPost(ctx context.Context, userID string, clubID string, text string) (string, error)
+	// held posts are saved but only shown to the author and the moderators until approved
+	CreateClubPost(ctx context.Context, userID string, clubID string, text string) (string, FilterVerdict, error)
 	GetClubPost(ctx context.Context, userID string, clubID string, postID string) (repository.GetClubPostRow, error)
 	DeleteClubPost(ctx context.Context, userID string, clubID string, postID string) error
 }
 
 type ClubService struct {
-	q repository.Querier
-	i ImageServicer
-	w WebSocketServicer
+	q  repository.Querier
+	i  ImageServicer
+	w  WebSocketServicer
+	f  ContentFilter
+	tx Transactor
 }
 
 // compile time assertion that ClubService implements ClubServicer
 var _ ClubServicer = (*ClubService)(nil)
 
-func NewClubService(q repository.Querier, i ImageServicer, w WebSocketServicer) *ClubService {
-	return &ClubService{q: q, i: i, w: w}
+func NewClubService(q repository.Querier, i ImageServicer, w WebSocketServicer, f ContentFilter, tx Transactor) *ClubService {
+	return &ClubService{q: q, i: i, w: w, f: f, tx: tx}
 }
 
 type CreateClubRequest struct {
@@ -124,6 +127,7 @@ func (s *ClubService) JoinClub(ctx context.Context, userID string, clubID string
 
 	wsMessage := WebSocketMessage{
 		Event:   "user_joined_club",
+		Topic:   ClubMembersTopic(clubID),
 		Payload: payload,
 	}
 
@@ -149,14 +153,17 @@ func (s *ClubService) JoinClub(ctx context.Context, userID string, clubID string
 	}
 
 	if len(broadcastList) > 0 {
-		s.w.BroadcastMessage(ctx, broadcastList, string(jsonBytes))
+		s.w.PublishMessage(ctx, broadcastList, ClubMembersTopic(clubID), string(jsonBytes))
 	}
 
 	return nil
 }
 
+// LeaveClub forfeits the member's points in the club
 func (s *ClubService) LeaveClub(ctx context.Context, params repository.DeleteClubMembershipParams) error {
-	return s.q.DeleteClubMembership(ctx, params)
+	return s.tx.WithTx(ctx, func(q repository.Querier) error {
+		return removeClubMember(ctx, q, params.ClubID, params.UserID)
+	})
 }
 
 func (s *ClubService) IsUserMemberOfClub(ctx context.Context, userID string, clubID string) (bool, error) {
@@ -205,7 +212,33 @@ func (s *ClubService) DeleteClub(ctx context.Context, userID string, clubID stri
 	if !isOwner {
 		return errors.New("Permission denied: user is not the owner of the club")
 	}
-	return s.q.DeleteClub(ctx, clubID)
+
+	var club repository.Club
+	var images []repository.ItemImage
+	err = s.tx.WithTx(ctx, func(q repository.Querier) error {
+		club, err = q.GetClub(ctx, clubID)
+		if err != nil {
+			return err
+		}
+		images, err = deleteClubRecord(ctx, q, clubID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	deleteUploadedImage(s.i, club.BannerImage)
+	deleteItemImageFiles(s.i, images)
+	return nil
+}
+
+// deleteClubRecord deletes a club and returns the photos of its items, the items go with the club through
+// ON DELETE CASCADE. the caller removes the files after the transaction commits
+func deleteClubRecord(ctx context.Context, q repository.Querier, clubID string) ([]repository.ItemImage, error) {
+	images, err := q.GetClubItemImages(ctx, &clubID)
+	if err != nil {
+		return nil, err
+	}
+	return images, q.DeleteClub(ctx, clubID)
 }
 
 func (s *ClubService) UpdateClub(ctx context.Context, userID string, params repository.UpdateClubParams) error {
@@ -279,50 +312,78 @@ func (s *ClubService) GetClubPosts(ctx context.Context, userID string, clubID st
 	if !isMember {
 		return nil, errors.New("Permission denied: user is not a member of the club")
 	}
-	return s.q.GetClubPosts(ctx, clubID)
+	return s.q.GetClubPosts(ctx, repository.GetClubPostsParams{
+		ClubID: clubID,
+		UserID: &userID,
+	})
 }
 
-func (s *ClubService) CreateClubPost(ctx context.Context, userID string, clubID string, text string) (string, error) {
+func (s *ClubService) CreateClubPost(ctx context.Context, userID string, clubID string, text string) (string, FilterVerdict, error) {
 	isMember, err := s.IsUserMemberOfClub(ctx, userID, clubID)
 	if err != nil {
-		return "", err
+		return "", FilterVerdict{}, err
 	}
 	if !isMember {
-		return "", errors.New("Permission denied: user is not a member of the club")
+		return "", FilterVerdict{}, errors.New("Permission denied: user is not a member of the club")
+	}
+
+	verdict, err := filterContent(ctx, s.f, Content{
+		Kind:     ContentKindPost,
+		AuthorID: userID,
+		ClubID:   &clubID,
+		Text:     text,
+	})
+	if err != nil {
+		return "", verdict, err
 	}
+	status, reason := moderationStatus(verdict)
 
 	id := util.GenerateUUID()
 	params := repository.CreateClubPostParams{
-		ID:      id,
-		UserID:  userID,
-		ClubID:  clubID,
-		Content: text,
+		ID:               id,
+		UserID:           &userID,
+		ClubID:           clubID,
+		Content:          text,
+		ModerationStatus: status,
+		ModerationReason: reason,
 	}
 	err = s.q.CreateClubPost(ctx, params)
 	if err != nil {
-		return "", err
+		return "", verdict, err
 	}
-	post, err := s.q.GetClubPost(ctx, id)
+	// members are told about held posts once a moderator approves them
+	if status == ModerationApproved {
+		if err := broadcastNewPost(ctx, s.q, s.w, id); err != nil {
+			return "", verdict, err
+		}
+	}
+	return id, verdict, nil
+}
+
+// broadcastNewPost sends the post to the members of its club subscribed to its posts
+func broadcastNewPost(ctx context.Context, q repository.Querier, w WebSocketServicer, postID string) error {
+	post, err := q.GetClubPost(ctx, postID)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	wsMessage := WebSocketMessage{
 		Event:   "new_post",
+		Topic:   ClubPostsTopic(post.ClubID),
 		Payload: post,
 	}
 
 	jsonBytes, err := json.Marshal(wsMessage)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	users, err := s.q.GetClubUserIds(ctx, clubID)
+	users, err := q.GetClubUserIds(ctx, post.ClubID)
 	if err != nil {
-		return "", err
+		return err
 	}
-	s.w.BroadcastMessage(ctx, users, string(jsonBytes))
-	return id, nil
+	w.PublishMessage(ctx, users, ClubPostsTopic(post.ClubID), string(jsonBytes))
+	return nil
 }
 
 // Club posts are only visible to members, even if the club is public.
@@ -334,7 +395,22 @@ func (s *ClubService) GetClubPost(ctx context.Context, userID string, clubID str
 	if !isMember {
 		return repository.GetClubPostRow{}, errors.New("Permission denied: user is not a member of the club")
 	}
-	return s.q.GetClubPost(ctx, postID)
+	post, err := s.q.GetClubPost(ctx, postID)
+	if err != nil {
+		return repository.GetClubPostRow{}, err
+	}
+
+	// held posts are only shown to the author and the moderators
+	if post.ModerationStatus == ModerationPending && (post.UserID == nil || *post.UserID != userID) {
+		isModerator, err := s.IsUserModeratorOfClub(ctx, userID, clubID)
+		if err != nil {
+			return repository.GetClubPostRow{}, err
+		}
+		if !isModerator {
+			return repository.GetClubPostRow{}, ErrPostNotFound
+		}
+	}
+	return post, nil
 }
 
 func (s *ClubService) DeleteClubPost(ctx context.Context, userID string, clubID string, postID string) error {
@@ -342,7 +418,7 @@ func (s *ClubService) DeleteClubPost(ctx context.Context, userID string, clubID
 	if err != nil {
 		return err
 	}
-	if post.UserID != userID {
+	if post.UserID == nil || *post.UserID != userID {
 		return errors.New("Permission denied: user is not the author of the post")
 	}
 	return s.q.DeleteClubPost(ctx, postID)
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+)
+
+// ContentFilter checks text written by users before it is saved
+type ContentFilter interface {
+	CheckContent(ctx context.Context, content Content) (FilterVerdict, error)
+}
+
+type Content struct {
+	Kind string // post, item, review, message or username
+	// empty for usernames, the account does not exist yet
+	AuthorID string
+	// content posted to a club is also checked against the club's blocklist
+	ClubID *string
+	// set when existing content is edited, it is left out of the link history and the posting frequency
+	ID   string
+	Text string
+}
+
+type FilterVerdict struct {
+	Action string `json:"action"` // allow, hold or reject
+	Reason string `json:"reason,omitempty"`
+}
+
+const (
+	ContentKindPost     = "post"
+	ContentKindItem     = "item"
+	ContentKindUsername = "username"
+	ContentKindReview   = "review"
+	ContentKindMessage  = "message"
+)
+
+const (
+	FilterAllow  = "allow"
+	FilterHold   = "hold" // saved but only shown to the author and the club's moderators until they approve it
+	FilterReject = "reject"
+)
+
+const (
+	ModerationApproved = "approved"
+	ModerationPending  = "pending"
+)
+
+var ErrContentRejected = errors.New("content was rejected")
+
+// BuiltinContentFilter rejects or holds content using word lists, link and spam heuristics and the clubs' blocklists
+type BuiltinContentFilter struct {
+	q       repository.Querier
+	blocked []string
+	review  []string
+}
+
+var _ ContentFilter = (*BuiltinContentFilter)(nil)
+
+// NewContentFilter rejects content with one of the blocked words and holds content with one of the review words
+func NewContentFilter(q repository.Querier, blocked []string, review []string) *BuiltinContentFilter {
+	return &BuiltinContentFilter{
+		q:       q,
+		blocked: normalizeTerms(blocked),
+		review:  normalizeTerms(review),
+	}
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+
+func (f *BuiltinContentFilter) CheckContent(ctx context.Context, content Content) (FilterVerdict, error) {
+	// the strictest verdict wins, a reject is returned right away
+	verdict := FilterVerdict{Action: FilterAllow}
+	hold := func(reason string) {
+		if verdict.Action == FilterAllow {
+			verdict = FilterVerdict{Action: FilterHold, Reason: reason}
+		}
+	}
+
+	text := normalizeText(content.Text)
+	if findTerm(text, f.blocked) != "" {
+		return FilterVerdict{Action: FilterReject, Reason: "contains a blocked word"}, nil
+	}
+	if findTerm(text, f.review) != "" {
+		hold("contains a word that needs review")
+	}
+
+	if content.ClubID != nil {
+		terms, err := f.q.GetClubBlockedTerms(ctx, *content.ClubID)
+		if err != nil {
+			return FilterVerdict{}, err
+		}
+		for _, t := range terms {
+			if !containsTerm(text, t.Term) {
+				continue
+			}
+			if t.Action == FilterReject {
+				return FilterVerdict{Action: FilterReject, Reason: "contains a word blocked by the club"}, nil
+			}
+			hold("contains a word the club reviews")
+		}
+	}
+
+	// a review can only be written once per trade and a message only reaches one user,
+	// so posting frequency does not apply
+	if content.Kind == ContentKindUsername || content.Kind == ContentKindReview || content.Kind == ContentKindMessage {
+		return verdict, nil
+	}
+
+	spamVerdict, err := f.checkSpam(ctx, content)
+	if err != nil {
+		return FilterVerdict{}, err
+	}
+	if spamVerdict.Action == FilterReject {
+		return spamVerdict, nil
+	}
+	if spamVerdict.Action == FilterHold {
+		hold(spamVerdict.Reason)
+	}
+	return verdict, nil
+}
+
+// checkSpam looks at the links in the content and how often the author posted recently
+func (f *BuiltinContentFilter) checkSpam(ctx context.Context, content Content) (FilterVerdict, error) {
+	now := time.Now()
+	rateSince := sqliteTimestamp(now.Add(-config.PostingRateWindow))
+	linkSince := sqliteTimestamp(now.Add(-config.RepeatedLinkWindow))
+
+	if content.ID == "" {
+		var recent, limit int64
+		var err error
+		switch content.Kind {
+		case ContentKindPost:
+			limit = config.MaxPostsPerRateWindow
+			recent, err = f.q.CountRecentClubPostsByUser(ctx, repository.CountRecentClubPostsByUserParams{
+				UserID: &content.AuthorID,
+				Since:  rateSince,
+			})
+		case ContentKindItem:
+			limit = config.MaxItemsPerRateWindow
+			recent, err = f.q.CountRecentItemsByOwner(ctx, repository.CountRecentItemsByOwnerParams{
+				OwnerID: content.AuthorID,
+				Since:   rateSince,
+			})
+		}
+		if err != nil {
+			return FilterVerdict{}, err
+		}
+		if recent >= limit {
+			return FilterVerdict{Action: FilterReject, Reason: "posting too often, try again later"}, nil
+		}
+	}
+
+	links := extractLinks(content.Text)
+	if len(links) > config.MaxLinksPerContent {
+		return FilterVerdict{Action: FilterHold, Reason: fmt.Sprintf("contains more than %d links", config.MaxLinksPerContent)}, nil
+	}
+	seen := map[string]bool{}
+	for _, link := range links {
+		if seen[link] {
+			return FilterVerdict{Action: FilterHold, Reason: "repeats the same link"}, nil
+		}
+		seen[link] = true
+
+		var count int64
+		var err error
+		switch content.Kind {
+		case ContentKindPost:
+			count, err = f.q.CountRecentClubPostsWithLink(ctx, repository.CountRecentClubPostsWithLinkParams{
+				UserID:    &content.AuthorID,
+				ExcludeID: content.ID,
+				Since:     linkSince,
+				Link:      link,
+			})
+		case ContentKindItem:
+			count, err = f.q.CountRecentItemsWithLink(ctx, repository.CountRecentItemsWithLinkParams{
+				OwnerID:   content.AuthorID,
+				ExcludeID: content.ID,
+				Since:     linkSince,
+				Link:      link,
+			})
+		}
+		if err != nil {
+			return FilterVerdict{}, err
+		}
+		if count > 0 {
+			return FilterVerdict{Action: FilterHold, Reason: "repeats a link posted recently"}, nil
+		}
+	}
+	return FilterVerdict{Action: FilterAllow}, nil
+}
+
+// filterContent runs the filter and turns a reject into an error.
+// content outside of clubs has no moderators to review it, so holding it rejects it instead
+func filterContent(ctx context.Context, f ContentFilter, content Content) (FilterVerdict, error) {
+	verdict, err := f.CheckContent(ctx, content)
+	if err != nil {
+		return FilterVerdict{}, err
+	}
+	if verdict.Action == FilterReject || (verdict.Action == FilterHold && content.ClubID == nil) {
+		return verdict, fmt.Errorf("%w: %s", ErrContentRejected, verdict.Reason)
+	}
+	return verdict, nil
+}
+
+// moderationStatus is what content with the verdict is saved with
+func moderationStatus(verdict FilterVerdict) (string, *string) {
+	if verdict.Action == FilterHold {
+		return ModerationPending, &verdict.Reason
+	}
+	return ModerationApproved, nil
+}
+
+// extractLinks returns the lowercase links in the text without trailing punctuation
+func extractLinks(text string) []string {
+	var links []string
+	for _, link := range linkPattern.FindAllString(text, -1) {
+		link = strings.TrimRight(strings.ToLower(link), ".,;:!?)]}'")
+		links = append(links, link)
+	}
+	return links
+}
+
+// normalizeText lowercases the text and turns everything that is not a letter or digit into single spaces,
+// padded so whole words can be found with containsTerm
+func normalizeText(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return " " + strings.Join(fields, " ") + " "
+}
+
+// NormalizeTerm is how blocked words and phrases are stored, it returns an empty string for terms without letters or digits
+func NormalizeTerm(term string) string {
+	return strings.TrimSpace(normalizeText(term))
+}
+
+func normalizeTerms(terms []string) []string {
+	var out []string
+	for _, term := range terms {
+		if term = NormalizeTerm(term); term != "" {
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+// containsTerm matches whole words and phrases, text must come from normalizeText
+func containsTerm(text string, term string) bool {
+	return term != "" && strings.Contains(text, " "+term+" ")
+}
+
+func findTerm(text string, terms []string) string {
+	for _, term := range terms {
+		if containsTerm(text, term) {
+			return term
+		}
+	}
+	return ""
+}
+
+// sqliteTimestamp formats t like CURRENT_TIMESTAMP so it compares with created_at defaults
+func sqliteTimestamp(t time.Time) string {
+	return t.UTC().Format(time.DateTime)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentFilterText(t *testing.T) {
+	t.Run("containsTerm", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			text     string
+			term     string
+			expected bool
+		}{
+			{name: "Word", text: "Selling a used bike", term: "bike", expected: true},
+			{name: "Case and punctuation", text: "FREE-PIZZA!!", term: "free pizza", expected: true},
+			{name: "Part of a word", text: "motorbikes for sale", term: "bike", expected: false},
+			{name: "Phrase split up", text: "free hot pizza", term: "free pizza", expected: false},
+			{name: "Empty term", text: "anything", term: "", expected: false},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.expected, containsTerm(normalizeText(tc.text), NormalizeTerm(tc.term)))
+			})
+		}
+	})
+
+	t.Run("extractLinks", func(t *testing.T) {
+		links := extractLinks("See https://Example.com/a, www.shop.test and (http://example.com/a).")
+		assert.Equal(t, []string{"https://example.com/a", "www.shop.test", "http://example.com/a"}, links)
+		assert.Empty(t, extractLinks("no links here, just example.com"))
+	})
+}
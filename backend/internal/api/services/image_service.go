@@ -12,6 +12,7 @@ import (
 	_ "image/png"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/chai2010/webp"
@@ -19,11 +20,16 @@ import (
 	"golang.org/x/image/draw"
 )
 
+var ErrInvalidImage = errors.New("invalid or unsupported image format")
+
 type ImageServicer interface {
+	// a height of 0 keeps the aspect ratio of the uploaded image
 	SaveImage(ctx context.Context, fileBytes []byte, width, height int, subDir string) (string, error)
 	SaveProfileImage(ctx context.Context, fileBytes []byte) (string, error)
 	SaveBannerImage(ctx context.Context, fileBytes []byte) (string, error)
 	DeleteImage(subDir, filename string) error
+	// returns the file behind a url returned by SaveImage
+	ReadImage(url string) ([]byte, error)
 }
 
 type ImageService struct {
@@ -47,7 +53,7 @@ func (s *ImageService) SaveImage(ctx context.Context, fileBytes []byte, width, h
 		if webpImg, err2 := webp.Decode(bytes.NewReader(fileBytes)); err2 == nil {
 			img = webpImg
 		} else {
-			return "", errors.New("invalid or unsupported image format")
+			return "", ErrInvalidImage
 		}
 	}
 
@@ -89,6 +95,10 @@ func (s *ImageService) SaveBannerImage(ctx context.Context, fileBytes []byte) (s
 }
 
 func resizeImage(img image.Image, width, height int) image.Image {
+	if height <= 0 {
+		bounds := img.Bounds()
+		height = max(1, width*bounds.Dy()/max(1, bounds.Dx()))
+	}
 	dst := image.NewRGBA(image.Rect(0, 0, width, height))
 	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
 	return dst
@@ -101,3 +111,11 @@ func (s *ImageService) DeleteImage(subDir, filename string) error {
 	}
 	return os.Remove(path)
 }
+
+func (s *ImageService) ReadImage(url string) ([]byte, error) {
+	rel, ok := strings.CutPrefix(url, "/assets/")
+	if !ok || !filepath.IsLocal(rel) {
+		return nil, fmt.Errorf("not an uploaded image: %s", url)
+	}
+	return os.ReadFile(filepath.Join(s.baseAssetsDir, rel))
+}
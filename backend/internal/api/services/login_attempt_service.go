@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+type LoginAttemptServicer interface {
+	// records the login attempt as failed before the credentials are checked and returns its id, or a
+	// *TooManyAttemptsError if the account or IP has to wait before trying again. The check and the record
+	// happen in one transaction, so attempts made in parallel count against each other
+	StartLogin(ctx context.Context, attempt LoginAttempt, client ClientInfo) (string, error)
+	// stores the outcome of an attempt from StartLogin
+	FinishLogin(ctx context.Context, attemptID string, attempt LoginAttempt) error
+	// forgets an attempt from StartLogin that neither failed nor succeeded
+	CancelLogin(ctx context.Context, attemptID string) error
+	// returns a *TooManyAttemptsError if the IP created too many accounts recently
+	CheckRegister(ctx context.Context, client ClientInfo) error
+	RecordAttempt(ctx context.Context, attempt LoginAttempt, client ClientInfo) error
+	// recent failed logins on the user's account, so they can notice someone guessing their password
+	GetUserLoginFailures(ctx context.Context, userID string) ([]repository.GetUserLoginFailuresRow, error)
+}
+
+type LoginAttemptService struct {
+	q  repository.Querier
+	tx Transactor
+}
+
+var _ LoginAttemptServicer = (*LoginAttemptService)(nil)
+
+func NewLoginAttemptService(q repository.Querier, tx Transactor) *LoginAttemptService {
+	return &LoginAttemptService{q: q, tx: tx}
+}
+
+const (
+	LoginActionLogin    = "login"
+	LoginActionMFA      = "mfa"
+	LoginActionRegister = "register"
+)
+
+type LoginAttempt struct {
+	Action        string
+	Account       string // see LoginAccountKey
+	Identifier    string
+	UserID        *string
+	Succeeded     bool
+	FailureReason string
+}
+
+// an attempt from StartLogin that never finished, e.g. because the server stopped, stays a failure
+const loginAttemptUnfinished = "unfinished"
+
+// ErrInvalidCredentials is returned for unknown accounts and wrong passwords alike so accounts cannot be enumerated
+var ErrInvalidCredentials = errors.New("invalid username, email or password")
+
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("too many attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginAccountKey is what failures are counted against: the user id when the account exists,
+// otherwise the identifier, so guessing at accounts that do not exist is throttled the same way
+func LoginAccountKey(userID string, identifier string) string {
+	if userID != "" {
+		return userID
+	}
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
+
+func (s *LoginAttemptService) StartLogin(ctx context.Context, attempt LoginAttempt, client ClientInfo) (string, error) {
+	attempt.Succeeded = false
+	attempt.FailureReason = loginAttemptUnfinished
+	var attemptID string
+	err := s.tx.WithTx(ctx, func(q repository.Querier) error {
+		if err := checkLogin(ctx, q, attempt.Account, client); err != nil {
+			return err
+		}
+		var err error
+		attemptID, err = createLoginAttempt(ctx, q, attempt, client)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return attemptID, nil
+}
+
+func (s *LoginAttemptService) FinishLogin(ctx context.Context, attemptID string, attempt LoginAttempt) error {
+	var reason *string
+	if attempt.FailureReason != "" {
+		reason = &attempt.FailureReason
+	}
+	return s.q.FinishLoginAttempt(ctx, repository.FinishLoginAttemptParams{
+		ID:            attemptID,
+		Succeeded:     attempt.Succeeded,
+		FailureReason: reason,
+	})
+}
+
+func (s *LoginAttemptService) CancelLogin(ctx context.Context, attemptID string) error {
+	return s.q.DeleteLoginAttempt(ctx, attemptID)
+}
+
+// checkLogin returns a *TooManyAttemptsError if the account or IP has to wait before trying again
+func checkLogin(ctx context.Context, q repository.Querier, account string, client ClientInfo) error {
+	if !config.LoginRateLimiting {
+		return nil
+	}
+	now := time.Now()
+
+	since := now.Add(-config.LoginAttemptWindow)
+	// a successful login clears the account's earlier failures
+	lastSuccess, err := q.GetLastAccountLoginSuccess(ctx, account)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err == nil && lastSuccess.After(since) {
+		since = lastSuccess
+	}
+
+	failures, err := q.GetRecentAccountLoginFailures(ctx, repository.GetRecentAccountLoginFailuresParams{
+		Account: account,
+		Since:   since,
+		MaxRows: config.AccountLockoutFailures,
+	})
+	if err != nil {
+		return err
+	}
+	if err := checkFailures(failures, config.AccountFreeLoginFailures, config.AccountLockoutFailures, now); err != nil {
+		return err
+	}
+
+	failures, err = q.GetRecentIPLoginFailures(ctx, repository.GetRecentIPLoginFailuresParams{
+		IpAddress: client.IP,
+		Since:     now.Add(-config.LoginAttemptWindow),
+		MaxRows:   config.IPLockoutFailures,
+	})
+	if err != nil {
+		return err
+	}
+	return checkFailures(failures, config.IPFreeLoginFailures, config.IPLockoutFailures, now)
+}
+
+func (s *LoginAttemptService) CheckRegister(ctx context.Context, client ClientInfo) error {
+	if !config.LoginRateLimiting {
+		return nil
+	}
+	count, err := s.q.CountRecentIPRegistrations(ctx, repository.CountRecentIPRegistrationsParams{
+		IpAddress: client.IP,
+		Since:     time.Now().Add(-config.RegistrationWindow),
+	})
+	if err != nil {
+		return err
+	}
+	if count >= config.MaxRegistrationsPerIP {
+		return &TooManyAttemptsError{RetryAfter: config.RegistrationWindow}
+	}
+	return nil
+}
+
+func (s *LoginAttemptService) RecordAttempt(ctx context.Context, attempt LoginAttempt, client ClientInfo) error {
+	_, err := createLoginAttempt(ctx, s.q, attempt, client)
+	return err
+}
+
+func createLoginAttempt(ctx context.Context, q repository.Querier, attempt LoginAttempt, client ClientInfo) (string, error) {
+	var reason *string
+	if attempt.FailureReason != "" {
+		reason = &attempt.FailureReason
+	}
+	id := util.GenerateUUID()
+	err := q.CreateLoginAttempt(ctx, repository.CreateLoginAttemptParams{
+		ID:            id,
+		Action:        attempt.Action,
+		Account:       attempt.Account,
+		Identifier:    attempt.Identifier,
+		UserID:        attempt.UserID,
+		IpAddress:     client.IP,
+		UserAgent:     client.UserAgent,
+		Succeeded:     attempt.Succeeded,
+		FailureReason: reason,
+		AttemptedAt:   time.Now(),
+	})
+	return id, err
+}
+
+func (s *LoginAttemptService) GetUserLoginFailures(ctx context.Context, userID string) ([]repository.GetUserLoginFailuresRow, error) {
+	return s.q.GetUserLoginFailures(ctx, repository.GetUserLoginFailuresParams{
+		UserID:  &userID,
+		MaxRows: config.LoginFailuresShown,
+	})
+}
+
+// checkFailures takes failure times newest first and returns an error if the next attempt has to wait
+func checkFailures(failures []time.Time, free int, lockoutAt int, now time.Time) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	wait := failureDelay(len(failures), free, lockoutAt)
+	if retryAt := failures[0].Add(wait); retryAt.After(now) {
+		return &TooManyAttemptsError{RetryAfter: retryAt.Sub(now)}
+	}
+	return nil
+}
+
+// failureDelay is how long to wait after the latest of n failures
+func failureDelay(n int, free int, lockoutAt int) time.Duration {
+	if n >= lockoutAt {
+		return config.LoginLockoutDuration
+	}
+	if n < free {
+		return 0
+	}
+	delay := float64(config.LoginBackoffBase) * math.Pow(2, float64(n-free))
+	return time.Duration(min(delay, float64(config.LoginBackoffMax)))
+}
+
+func CleanUpOldLoginAttempts(ctx context.Context, q repository.Querier) error {
+	return q.DeleteOldLoginAttempts(ctx, time.Now().Add(-config.LoginAttemptRetention))
+}
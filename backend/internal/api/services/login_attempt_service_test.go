@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginAttemptBackoff(t *testing.T) {
+	t.Run("failureDelay", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			failures int
+			expected time.Duration
+		}{
+			{name: "No failures", failures: 0, expected: 0},
+			{name: "Free failures", failures: 2, expected: 0},
+			{name: "First delayed failure", failures: 3, expected: config.LoginBackoffBase},
+			{name: "Delay doubles", failures: 5, expected: 4 * config.LoginBackoffBase},
+			{name: "Capped below lockout", failures: 99, expected: config.LoginBackoffMax},
+			{name: "Lockout", failures: 100, expected: config.LoginLockoutDuration},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.expected, failureDelay(tc.failures, 3, 100))
+			})
+		}
+	})
+
+	t.Run("checkFailures", func(t *testing.T) {
+		now := time.Now()
+
+		assert.NoError(t, checkFailures(nil, 3, 10, now))
+		assert.NoError(t, checkFailures([]time.Time{now, now}, 3, 10, now))
+
+		// third failure one second ago with a one second delay, may try again
+		recent := []time.Time{now.Add(-2 * config.LoginBackoffBase), now.Add(-time.Minute), now.Add(-time.Minute)}
+		assert.NoError(t, checkFailures(recent, 3, 10, now))
+
+		recent[0] = now
+		err := checkFailures(recent, 3, 10, now)
+		var tooMany *TooManyAttemptsError
+		assert.ErrorAs(t, err, &tooMany)
+		assert.Equal(t, config.LoginBackoffBase, tooMany.RetryAfter)
+	})
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rhellwege/task-social/config"
+)
+
+type Email struct {
+	To      string
+	Subject string
+	Body    string // plain text
+}
+
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// SMTPMailer delivers mail through an SMTP relay.
+// Auth is skipped when no username is configured, which is what local test servers like mailpit expect.
+type SMTPMailer struct {
+	addr     string
+	from     string
+	username string
+	password string
+	host     string
+}
+
+var _ Mailer = (*SMTPMailer)(nil)
+
+func NewSMTPMailer(host string, port string, username string, password string, from string) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     host + ":" + port,
+		from:     from,
+		username: username,
+		password: password,
+		host:     host,
+	}
+}
+
+// Send talks to the relay like smtp.SendMail, but gives up when ctx ends or after config.MailSendTimeout
+func (m *SMTPMailer) Send(ctx context.Context, email Email) error {
+	ctx, cancel := context.WithTimeout(ctx, config.MailSendTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// unblocks any read or write waiting on the relay
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(m.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(email.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(formatEmail(m.from, email)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// LogMailer prints every mail to the server log, for development without a mail server
+type LogMailer struct{}
+
+var _ Mailer = (*LogMailer)(nil)
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, email Email) error {
+	log.Printf("Mail to %s: %s\n%s", email.To, email.Subject, email.Body)
+	return nil
+}
+
+// FileMailer writes every mail as an .eml file into dir, for development and inspecting templates
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+var _ Mailer = (*FileMailer)(nil)
+
+func NewFileMailer(dir string, from string) *FileMailer {
+	os.MkdirAll(dir, os.ModePerm)
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (m *FileMailer) Send(ctx context.Context, email Email) error {
+	filename := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), strings.ReplaceAll(email.To, "@", "_at_"))
+	return os.WriteFile(filepath.Join(m.dir, filename), formatEmail(m.from, email), 0o644)
+}
+
+func formatEmail(from string, email Email) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", email.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", email.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+	return b.Bytes()
+}
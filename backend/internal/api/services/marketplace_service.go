@@ -2,32 +2,209 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/rhellwege/task-social/config"
 	"github.com/rhellwege/task-social/internal/db/repository"
 	"github.com/rhellwege/task-social/internal/util"
 )
 
 type MarketplaceServicer interface {
 	CreateItem(ctx context.Context, userID string, req CreateItemRequest) (string, error)
+	// the users who wishlisted the item are told over the WebSocket when it becomes available again or its price changes
 	UpdateItem(ctx context.Context, userID string, params repository.UpdateItemParams) error
+	// the item's photos are deleted with it
 	DeleteItem(ctx context.Context, userID string, itemID string) error
+	// saves the photo and a thumbnail of it, an item has at most config.MaxItemImages photos
+	AddItemImage(ctx context.Context, userID string, itemID string, fileBytes []byte) (repository.ItemImage, error)
+	DeleteItemImage(ctx context.Context, userID string, itemID string, imageID string) error
+	// hides the item from everyone but reservedFor until the reservation ends, trades can only give it to them.
+	// reserving it again replaces the reservation
+	ReserveItem(ctx context.Context, userID string, itemID string, reservedFor string, duration time.Duration) (time.Time, error)
+	// the users who wishlisted the item are told it is available again
+	ReleaseItemReservation(ctx context.Context, userID string, itemID string) error
 
-	GetClubItems(ctx context.Context, userID string, clubID string) ([]ClubMarketplaceItem, error)
-	CreateClubItem(ctx context.Context, userID string, clubID string, req CreateItemRequest) (string, error)
+	GetClubItems(ctx context.Context, userID string, clubID string, filter ItemFilter) ([]ClubMarketplaceItem, error)
+	// held items are saved but not listed until one of the club's moderators approves them.
+	// members rated below the club's minimum listing reputation cannot list items, members without reviews can
+	CreateClubItem(ctx context.Context, userID string, clubID string, req CreateItemRequest) (string, FilterVerdict, error)
+
+	// searches the available items of every club the user is a member of
+	SearchItems(ctx context.Context, userID string, search MarketplaceSearch) ([]MarketplaceSearchItem, error)
+	// the user is told over the WebSocket whenever an item listed in one of their clubs matches a saved search
+	CreateSavedSearch(ctx context.Context, userID string, req SavedSearchRequest) (string, error)
+	GetSavedSearches(ctx context.Context, userID string) ([]repository.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, userID string, searchID string) error
+
+	// wishlisting an item twice keeps it once
+	AddToWishlist(ctx context.Context, userID string, itemID string) error
+	RemoveFromWishlist(ctx context.Context, userID string, itemID string) error
+	GetWishlist(ctx context.Context, userID string) ([]WishlistItem, error)
+	// only the item's owner can see who wishlisted it
+	GetItemWishlisters(ctx context.Context, userID string, itemID string) ([]repository.GetItemWishlistersRow, error)
+
+	// puts a club item up for auction, it cannot be traded or reserved until the auction ends. SettleAuctions ends it
+	CreateAuction(ctx context.Context, userID string, itemID string, req AuctionRequest) (string, error)
+	// the club's open auctions, the ones ending first first
+	GetClubAuctions(ctx context.Context, userID string, clubID string) ([]AuctionDetails, error)
+	// includes the bid history
+	GetAuction(ctx context.Context, userID string, auctionID string) (AuctionDetails, error)
+	// the club's members are told about every bid over the WebSocket, a bid just before the end extends the auction
+	PlaceBid(ctx context.Context, userID string, auctionID string, amount float64) (AuctionDetails, error)
+	// only auctions nobody bid on can be cancelled
+	CancelAuction(ctx context.Context, userID string, auctionID string) error
+
+	// both parties are told about every change to their trades over the WebSocket.
+	// the warning is set when the estimated values of the two sides are far apart, the trade is proposed anyway
+	ProposeTrade(ctx context.Context, userID string, req TradeRequest) (tradeID string, warning string, err error)
+	// only the user a trade was offered to can counter, accept or reject it
+	CounterTrade(ctx context.Context, userID string, tradeID string, req TradeRequest) (counterID string, warning string, err error)
+	// hands over every item and the points, other pending trades sharing an item are cancelled
+	AcceptTrade(ctx context.Context, userID string, tradeID string) error
+	RejectTrade(ctx context.Context, userID string, tradeID string) error
+	CancelTrade(ctx context.Context, userID string, tradeID string) error
+	GetTrade(ctx context.Context, userID string, tradeID string) (TradeDetails, error)
+	// an empty status lists trades with any status
+	GetIncomingTrades(ctx context.Context, userID string, status string) ([]TradeDetails, error)
+	GetOutgoingTrades(ctx context.Context, userID string, status string) ([]TradeDetails, error)
+
+	// each party of an accepted trade can review the other once, the ratings make up the other's reputation
+	ReviewTrade(ctx context.Context, userID string, tradeID string, req TradeReviewRequest) error
+	// the reviews the user received, the newest first
+	GetUserReviews(ctx context.Context, userID string, limit int64, offset int64) ([]repository.GetUserReviewsRow, error)
 }
 
 type MarketplaceService struct {
-	q repository.Querier
+	q  repository.Querier
+	i  ImageServicer
+	f  ContentFilter
+	tx Transactor
+	w  WebSocketServicer
 }
 
 // compile-time assertion
 var _ MarketplaceServicer = (*MarketplaceService)(nil)
 
-func NewMarketplaceService(q repository.Querier) *MarketplaceService {
-	return &MarketplaceService{q: q}
+func NewMarketplaceService(q repository.Querier, i ImageServicer, f ContentFilter, tx Transactor, w WebSocketServicer) *MarketplaceService {
+	return &MarketplaceService{q: q, i: i, f: f, tx: tx, w: w}
 }
 
+const (
+	ItemSortNewest    = "newest"
+	ItemSortPriceAsc  = "price_asc"
+	ItemSortPriceDesc = "price_desc"
+)
+
+const (
+	ItemConditionNew     = "new"
+	ItemConditionLikeNew = "like_new"
+	ItemConditionGood    = "good"
+	ItemConditionFair    = "fair"
+	ItemConditionPoor    = "poor"
+)
+
+var ItemConditions = []string{ItemConditionNew, ItemConditionLikeNew, ItemConditionGood, ItemConditionFair, ItemConditionPoor}
+
+var ItemCategories = []string{"books", "clothing", "electronics", "furniture", "games", "home", "music", "sports", "tools", "toys", "other"}
+
+var (
+	ErrNotItemOwner         = errors.New("permission denied: not item owner")
+	ErrInvalidPriceEstimate = errors.New("price_estimate must be zero or more and currency a three letter ISO 4217 code")
+	ErrInvalidItemFilter    = fmt.Errorf("sort must be %s, %s or %s and prices zero or more", ItemSortNewest, ItemSortPriceAsc, ItemSortPriceDesc)
+	ErrInvalidItemCondition = fmt.Errorf("condition must be one of %s", strings.Join(ItemConditions, ", "))
+	ErrInvalidItemCategory  = fmt.Errorf("category must be one of %s", strings.Join(ItemCategories, ", "))
+	ErrPickupLocationLength = fmt.Errorf("pickup_location must be at most %d characters", config.MaxPickupLocationLength)
+	ErrTooManyItemImages    = fmt.Errorf("an item can have at most %d photos", config.MaxItemImages)
+	ErrItemImageNotFound    = errors.New("item photo not found")
+	ErrSearchQueryLength    = fmt.Errorf("query must be at most %d characters", config.MaxSearchQueryLength)
+	ErrSavedSearchName      = fmt.Errorf("name is required and must be at most %d characters", config.MaxSavedSearchNameLength)
+	ErrTooManySavedSearches = fmt.Errorf("you can save at most %d searches", config.MaxSavedSearches)
+	ErrSavedSearchNotFound  = errors.New("saved search not found")
+	ErrReserveOwnItem       = errors.New("you cannot reserve an item for yourself")
+	ErrReserveNotMember     = errors.New("items can only be reserved for members of the item's club")
+	ErrReservationDuration  = fmt.Errorf("a reservation lasts from 1 to %d hours", int(config.MaxReservationDuration.Hours()))
+	ErrItemUnavailable      = errors.New("item is not available")
+	ErrItemNotReserved      = errors.New("item is not reserved")
+	ErrWishlistOwnItem      = errors.New("you cannot wishlist your own item")
+	ErrWishlistFull         = fmt.Errorf("a wishlist can have at most %d items", config.MaxWishlistItems)
+	ErrWishlistItemNotFound = errors.New("item is not on your wishlist")
+)
+
+const (
+	ListingTrade   = "trade"
+	ListingAuction = "auction"
+	// offered in a club's point shop, see RewardServicer
+	ListingReward = "reward"
+)
+
+const (
+	AuctionOpen = "open"
+	AuctionSold = "sold"
+	// nobody bid or no bid reached the reserve price
+	AuctionUnsold    = "unsold"
+	AuctionCancelled = "cancelled"
+)
+
+var (
+	ErrInvalidAuction = errors.New("start_price must be zero or more, reserve_price at least start_price, " +
+		"bid_increment at least 0.01 and currency a three letter ISO 4217 code")
+	ErrAuctionEndTime = fmt.Errorf("ends_at must be from %d hour to %d days from now",
+		int(config.MinAuctionDuration.Hours()), int(config.MaxAuctionDuration.Hours()/24))
+	ErrAuctionNotFound      = errors.New("auction not found")
+	ErrAuctionClosed        = errors.New("auction has ended")
+	ErrNotAuctionSeller     = errors.New("only the seller can cancel the auction")
+	ErrAuctionHasBids       = errors.New("auctions with bids cannot be cancelled")
+	ErrBidOwnAuction        = errors.New("you cannot bid on your own auction")
+	ErrAlreadyHighestBidder = errors.New("you already have the highest bid")
+	ErrBidTooLow            = errors.New("bid is too low")
+)
+
+const (
+	TradePending   = "pending"
+	TradeAccepted  = "accepted"
+	TradeRejected  = "rejected"
+	TradeCancelled = "cancelled"
+	// the user the trade was offered to answered with a different trade
+	TradeCountered = "countered"
+)
+
+var (
+	ErrTradeNotFound        = errors.New("trade not found")
+	ErrTradeItemNotFound    = errors.New("item not found")
+	ErrTradeOwnItem         = errors.New("offer your own items for items someone else owns")
+	ErrTradeItemOwners      = errors.New("all requested items must belong to the user the trade is offered to")
+	ErrTradeNoResponder     = errors.New("responder_id is required when no items are requested")
+	ErrTradeEmptySide       = errors.New("both sides of a trade must give items or points")
+	ErrTooManyTradeItems    = fmt.Errorf("each side of a trade can give at most %d items", config.MaxTradeItems)
+	ErrDuplicateTradeItem   = errors.New("an item can only be in a trade once")
+	ErrInvalidTradePoints   = errors.New("points must be positive and can only be paid by one side")
+	ErrTradeClubRequired    = errors.New("club_id is required when points are traded, and only then")
+	ErrTradeNotClubMember   = errors.New("both users must be members of the club whose points are traded")
+	ErrNotEnoughPoints      = errors.New("not enough points in the club")
+	ErrTradeItemUnavailable = errors.New("item is not available for trading")
+	ErrTradeAlreadyProposed = errors.New("you already have a pending trade with this user")
+	ErrTradeNotPending      = errors.New("trade is no longer pending")
+	ErrTradeNotResponder    = errors.New("only the user the trade was offered to can do this")
+	ErrTradeNotProposer     = errors.New("only the user who proposed the trade can cancel it")
+	ErrCounterOtherUser     = errors.New("a counter-offer goes back to the user who proposed the trade")
+	ErrInvalidTradeStatus   = errors.New("status must be pending, accepted, rejected, cancelled or countered")
+	ErrTradeNotCompleted    = errors.New("only accepted trades can be reviewed")
+	ErrAlreadyReviewed      = errors.New("you already reviewed this trade")
+	ErrInvalidRating        = fmt.Errorf("rating must be from %d to %d", config.MinReviewRating, config.MaxReviewRating)
+	ErrReviewCommentLength  = fmt.Errorf("comment must be at most %d characters", config.MaxReviewCommentLength)
+	ErrReputationTooLow     = errors.New("your reputation is below the club's minimum for listing items")
+)
+
 /* ============================
    DTOs
    ============================ */
@@ -36,15 +213,180 @@ type CreateItemRequest struct {
 	Name          string   `json:"name"`
 	Description   *string  `json:"description,omitempty"`
 	PriceEstimate *float64 `json:"price_estimate,omitempty"`
+	// ISO 4217 code of the price estimate, defaults to USD
+	Currency *string `json:"currency,omitempty"`
+	// new, like_new, good, fair or poor
+	Condition      *string `json:"condition,omitempty"`
+	Category       *string `json:"category,omitempty"`
+	PickupLocation *string `json:"pickup_location,omitempty"`
+	CanShip        bool    `json:"can_ship"`
+}
+
+// ItemFilter narrows and orders a club's marketplace listing, zero values do not filter
+type ItemFilter struct {
+	// newest (default), price_asc or price_desc
+	Sort     string
+	Currency string
+	MinPrice *float64
+	MaxPrice *float64
+}
+
+// MarketplaceSearch looks for items across the user's clubs, zero values do not filter
+type MarketplaceSearch struct {
+	ItemFilter
+	// words to look for in the name and description
+	Query     string
+	Category  string
+	Condition string
+	OwnerID   string
+	Limit     int64
+	Offset    int64
+}
+
+type MarketplaceSearchItem struct {
+	ClubMarketplaceItem
+	ClubID   string `json:"club_id"`
+	ClubName string `json:"club_name"`
+}
+
+// SavedSearchRequest is a search the user wants to hear about new matches for, empty fields do not filter
+type SavedSearchRequest struct {
+	Name      string   `json:"name"`
+	Query     string   `json:"query,omitempty"`
+	Category  string   `json:"category,omitempty"`
+	Condition string   `json:"condition,omitempty"`
+	OwnerID   string   `json:"owner_id,omitempty"`
+	Currency  string   `json:"currency,omitempty"`
+	MinPrice  *float64 `json:"min_price,omitempty"`
+	MaxPrice  *float64 `json:"max_price,omitempty"`
+}
+
+// SavedSearchMatch is sent to the user when a newly listed item matches one or more of their saved searches
+type SavedSearchMatch struct {
+	Searches []repository.GetSavedSearchesMatchingItemRow `json:"searches"`
+	Item     ClubMarketplaceItem                          `json:"item"`
+	ClubID   string                                       `json:"club_id"`
+}
+
+// TradeRequest offers items, points or both for the responder's items or points
+type TradeRequest struct {
+	// the user the trade is offered to, defaults to the owner of the requested items
+	ResponderID string `json:"responder_id,omitempty"`
+	// your items
+	OfferedItemIDs []string `json:"offered_item_ids"`
+	// the items you want for them
+	RequestedItemIDs []string `json:"requested_item_ids"`
+	// points you pay in the club
+	OfferedPoints float64 `json:"offered_points,omitempty"`
+	// points the responder pays you in the club
+	RequestedPoints float64 `json:"requested_points,omitempty"`
+	// the club whose points are traded, both users must be members
+	ClubID *string `json:"club_id,omitempty"`
+}
+
+type TradeDetails struct {
+	repository.Trade
+	ProposerUsername  string `json:"proposer_username"`
+	ResponderUsername string `json:"responder_username"`
+	// what each side gives besides points
+	ProposerItems  []repository.GetTradeItemsRow `json:"proposer_items"`
+	ResponderItems []repository.GetTradeItemsRow `json:"responder_items"`
+	// what the parties said about each other once the trade was accepted
+	Reviews []repository.TradeReview `json:"reviews"`
+}
+
+type TradeReviewRequest struct {
+	// from 1 to 5
+	Rating  int64   `json:"rating"`
+	Comment *string `json:"comment,omitempty"`
+}
+
+// Reputation is the average rating a user received for their trades
+type Reputation struct {
+	// nil until the user is reviewed
+	Score       *float64 `json:"score"`
+	ReviewCount int64    `json:"review_count"`
 }
 
 type ClubMarketplaceItem struct {
-	ID            string  `json:"id"`
-	Name          string  `json:"name"`
-	Description   *string `json:"description,omitempty"`
-	IsAvailable   bool    `json:"is_available"`
-	OwnerID       string  `json:"owner_id"`
-	OwnerUsername string  `json:"owner_username"`
+	ID             string                 `json:"id"`
+	Name           string                 `json:"name"`
+	Description    *string                `json:"description,omitempty"`
+	PriceEstimate  *float64               `json:"price_estimate,omitempty"`
+	Currency       *string                `json:"currency,omitempty"`
+	Condition      *string                `json:"condition,omitempty"`
+	Category       *string                `json:"category,omitempty"`
+	PickupLocation *string                `json:"pickup_location,omitempty"`
+	CanShip        bool                   `json:"can_ship"`
+	Images         []repository.ItemImage `json:"images"`
+	IsAvailable    bool                   `json:"is_available"`
+	// trade or auction, items on auction can only be bid on
+	ListingType   string `json:"listing_type"`
+	OwnerID       string `json:"owner_id"`
+	OwnerUsername string `json:"owner_username"`
+	// nil until the owner is reviewed
+	OwnerReputation  *float64 `json:"owner_reputation"`
+	OwnerReviewCount int64    `json:"owner_review_count"`
+	// set when the owner reserved the item for you
+	ReservedUntil *time.Time `json:"reserved_until,omitempty"`
+}
+
+type WishlistItem struct {
+	ClubMarketplaceItem
+	ClubID string `json:"club_id"`
+	// the owner reserved the item for someone else
+	Reserved     bool      `json:"reserved"`
+	WishlistedAt time.Time `json:"wishlisted_at"`
+}
+
+type AuctionRequest struct {
+	StartPrice float64 `json:"start_price"`
+	// bids below it do not win, only you see it
+	ReservePrice *float64 `json:"reserve_price,omitempty"`
+	// how much a bid has to beat the highest one by, config.DefaultBidIncrement by default
+	BidIncrement float64 `json:"bid_increment,omitempty"`
+	// ISO 4217 code, defaults to the currency of the item's price estimate
+	Currency *string   `json:"currency,omitempty"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type AuctionDetails struct {
+	ID             string              `json:"id"`
+	Item           ClubMarketplaceItem `json:"item"`
+	ClubID         string              `json:"club_id"`
+	SellerID       string              `json:"seller_id"`
+	SellerUsername string              `json:"seller_username"`
+	StartPrice     float64             `json:"start_price"`
+	BidIncrement   float64             `json:"bid_increment"`
+	Currency       string              `json:"currency"`
+	// only shown to the seller
+	ReservePrice *float64 `json:"reserve_price,omitempty"`
+	HasReserve   bool     `json:"has_reserve"`
+	ReserveMet   bool     `json:"reserve_met"`
+	// moved back by bids just before the end
+	EndsAt time.Time `json:"ends_at"`
+	// open, sold, unsold or cancelled
+	Status     string   `json:"status"`
+	WinnerID   *string  `json:"winner_id,omitempty"`
+	WinningBid *float64 `json:"winning_bid,omitempty"`
+	HighestBid *float64 `json:"highest_bid,omitempty"`
+	BidCount   int      `json:"bid_count"`
+	// the lowest amount the next bid can be
+	MinimumBid float64 `json:"minimum_bid"`
+	// the highest first, only set for a single auction
+	Bids []repository.GetAuctionBidsRow `json:"bids,omitempty"`
+}
+
+// WishlistUpdate is sent to the users who wishlisted an item when it changes
+type WishlistUpdate struct {
+	Item   ClubMarketplaceItem `json:"item"`
+	ClubID string              `json:"club_id"`
+	// the item can be traded for again
+	Available bool `json:"available"`
+	// the price estimate or its currency changed, the previous ones are set
+	PriceChanged     bool     `json:"price_changed"`
+	PreviousPrice    *float64 `json:"previous_price,omitempty"`
+	PreviousCurrency *string  `json:"previous_currency,omitempty"`
 }
 
 /* ============================
@@ -60,15 +402,38 @@ func (s *MarketplaceService) CreateItem(
 	if req.Name == "" {
 		return "", errors.New("item name is required")
 	}
+	price, currency, err := priceEstimate(req.PriceEstimate, req.Currency)
+	if err != nil {
+		return "", err
+	}
+	condition, category, pickup, err := itemDetails(req.Condition, req.Category, req.PickupLocation)
+	if err != nil {
+		return "", err
+	}
+
+	// items outside of clubs have no moderators, anything the filter would hold is rejected
+	if _, err := filterContent(ctx, s.f, Content{
+		Kind:     ContentKindItem,
+		AuthorID: userID,
+		Text:     itemText(req.Name, req.Description),
+	}); err != nil {
+		return "", err
+	}
 
 	itemID := util.GenerateUUID()
 
-	err := s.q.CreateItem(ctx, repository.CreateItemParams{
-		ID:          itemID,
-		Name:        req.Name,
-		Description: req.Description,
-		IsAvailable: true,
-		OwnerID:     userID,
+	err = s.q.CreateItem(ctx, repository.CreateItemParams{
+		ID:             itemID,
+		Name:           req.Name,
+		Description:    req.Description,
+		PriceEstimate:  price,
+		Currency:       currency,
+		Condition:      emptyToNil(condition),
+		Category:       emptyToNil(category),
+		PickupLocation: emptyToNil(pickup),
+		CanShip:        req.CanShip,
+		IsAvailable:    true,
+		OwnerID:        userID,
 	})
 	if err != nil {
 		return "", err
@@ -83,16 +448,78 @@ func (s *MarketplaceService) UpdateItem(
 	params repository.UpdateItemParams,
 ) error {
 
-	item, err := s.q.GetItem(ctx, params.ID)
+	item, err := s.getOwnedItem(ctx, userID, params.ID)
 	if err != nil {
 		return err
 	}
 
-	if item.OwnerID != userID {
-		return errors.New("permission denied: not item owner")
+	// empty strings are kept, they clear the field
+	params.Condition, params.Category, params.PickupLocation, err = itemDetails(params.Condition, params.Category, params.PickupLocation)
+	if err != nil {
+		return err
+	}
+
+	if params.PriceEstimate != nil || params.Currency != nil {
+		price, currency := item.PriceEstimate, item.Currency
+		if params.PriceEstimate != nil {
+			price = params.PriceEstimate
+		}
+		if params.Currency != nil {
+			currency = params.Currency
+		}
+		params.PriceEstimate, params.Currency, err = priceEstimate(price, currency)
+		if err != nil {
+			return err
+		}
+	}
+
+	var verdict FilterVerdict
+	if params.Name != nil || params.Description != nil {
+		name, description := item.Name, item.Description
+		if params.Name != nil {
+			name = *params.Name
+		}
+		if params.Description != nil {
+			description = params.Description
+		}
+		verdict, err = filterContent(ctx, s.f, Content{
+			Kind:     ContentKindItem,
+			AuthorID: userID,
+			ClubID:   item.ClubID,
+			ID:       item.ID,
+			Text:     itemText(name, description),
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	return s.q.UpdateItem(ctx, params)
+	if err := s.q.UpdateItem(ctx, params); err != nil {
+		return err
+	}
+	// an approved item goes back to the moderators if the edit needs review
+	if verdict.Action == FilterHold {
+		return s.q.HoldItem(ctx, repository.HoldItemParams{
+			ID:               item.ID,
+			ModerationReason: &verdict.Reason,
+		})
+	}
+
+	// a reserved item only becomes available to the others once the reservation ends
+	update := WishlistUpdate{
+		Available: params.IsAvailable != nil && *params.IsAvailable && !item.IsAvailable &&
+			activeReservation(item.ReservedUntil, time.Now()) == nil,
+	}
+	if params.PriceEstimate != nil || params.Currency != nil {
+		update.PriceChanged = !equalPtr(params.PriceEstimate, item.PriceEstimate) || !equalPtr(params.Currency, item.Currency)
+	}
+	if update.PriceChanged {
+		update.PreviousPrice, update.PreviousCurrency = item.PriceEstimate, item.Currency
+	}
+	if (update.Available || update.PriceChanged) && item.ModerationStatus == ModerationApproved {
+		notifyWishlist(ctx, s.q, s.w, item.ID, update)
+	}
+	return nil
 }
 
 func (s *MarketplaceService) DeleteItem(
@@ -101,24 +528,97 @@ func (s *MarketplaceService) DeleteItem(
 	itemID string,
 ) error {
 
-	item, err := s.q.GetItem(ctx, itemID)
-	if err != nil {
+	if _, err := s.getOwnedItem(ctx, userID, itemID); err != nil {
 		return err
 	}
 
-	if item.OwnerID != userID {
-		return errors.New("permission denied: not item owner")
+	return deleteItem(ctx, s.q, s.i, itemID)
+}
+
+func (s *MarketplaceService) AddItemImage(
+	ctx context.Context,
+	userID string,
+	itemID string,
+	fileBytes []byte,
+) (repository.ItemImage, error) {
+
+	if _, err := s.getOwnedItem(ctx, userID, itemID); err != nil {
+		return repository.ItemImage{}, err
+	}
+	count, err := s.q.CountItemImages(ctx, itemID)
+	if err != nil {
+		return repository.ItemImage{}, err
+	}
+	if count >= config.MaxItemImages {
+		return repository.ItemImage{}, ErrTooManyItemImages
+	}
+
+	url, err := s.i.SaveImage(ctx, fileBytes, config.ItemImageWidth, 0, "item")
+	if err != nil {
+		return repository.ItemImage{}, err
+	}
+	thumbnailURL, err := s.i.SaveImage(ctx, fileBytes, config.ItemThumbnailWidth, 0, "item_thumbnail")
+	if err != nil {
+		deleteUploadedImage(s.i, &url)
+		return repository.ItemImage{}, err
+	}
+
+	image := repository.ItemImage{
+		ID:           util.GenerateUUID(),
+		ItemID:       itemID,
+		Url:          url,
+		ThumbnailUrl: thumbnailURL,
+	}
+	err = s.q.AddItemImage(ctx, repository.AddItemImageParams{
+		ID:           image.ID,
+		ItemID:       image.ItemID,
+		Url:          image.Url,
+		ThumbnailUrl: image.ThumbnailUrl,
+	})
+	if err != nil {
+		deleteItemImageFiles(s.i, []repository.ItemImage{image})
+		return repository.ItemImage{}, err
+	}
+	return s.q.GetItemImage(ctx, repository.GetItemImageParams{ID: image.ID, ItemID: itemID})
+}
+
+func (s *MarketplaceService) DeleteItemImage(
+	ctx context.Context,
+	userID string,
+	itemID string,
+	imageID string,
+) error {
+
+	if _, err := s.getOwnedItem(ctx, userID, itemID); err != nil {
+		return err
+	}
+	image, err := s.q.GetItemImage(ctx, repository.GetItemImageParams{ID: imageID, ItemID: itemID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrItemImageNotFound
+	}
+	if err != nil {
+		return err
 	}
 
-	return s.q.DeleteItem(ctx, itemID)
+	if err := s.q.DeleteItemImage(ctx, imageID); err != nil {
+		return err
+	}
+	deleteItemImageFiles(s.i, []repository.ItemImage{image})
+	return nil
 }
 
 func (s *MarketplaceService) GetClubItems(
 	ctx context.Context,
 	userID string,
 	clubID string,
+	filter ItemFilter,
 ) ([]ClubMarketplaceItem, error) {
 
+	filter, err := validateItemFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	// Membership check: only club members can view
 	isMember, err := s.q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
 		UserID: userID,
@@ -132,13 +632,29 @@ func (s *MarketplaceService) GetClubItems(
 	}
 
 	// Fetch items scoped strictly to this club
-	items, err := s.q.GetItemsByClub(ctx, &clubID)
+	now := time.Now()
+	items, err := s.q.GetItemsByClub(ctx, repository.GetItemsByClubParams{
+		Sort:     filter.Sort,
+		ClubID:   &clubID,
+		Now:      &now,
+		UserID:   userID,
+		Currency: filter.Currency,
+		MinPrice: filter.MinPrice,
+		MaxPrice: filter.MaxPrice,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := s.q.GetClubItemImages(ctx, &clubID)
 	if err != nil {
 		return nil, err
 	}
+	imagesByItem := groupItemImages(images)
 
 	out := make([]ClubMarketplaceItem, 0, len(items))
 	usernameByID := map[string]string{}
+	reputationByID := map[string]Reputation{}
 
 	for _, it := range items {
 		// Resolve username (best-effort, cached)
@@ -149,14 +665,30 @@ func (s *MarketplaceService) GetClubItems(
 				usernameByID[it.OwnerID] = "unknown"
 			}
 		}
+		reputation, err := cachedReputation(ctx, s.q, reputationByID, it.OwnerID)
+		if err != nil {
+			return nil, err
+		}
 
 		out = append(out, ClubMarketplaceItem{
-			ID:            it.ID,
-			Name:          it.Name,
-			Description:   it.Description,
-			IsAvailable:   it.IsAvailable,
-			OwnerID:       it.OwnerID,
-			OwnerUsername: usernameByID[it.OwnerID],
+			ID:             it.ID,
+			Name:           it.Name,
+			Description:    it.Description,
+			PriceEstimate:  it.PriceEstimate,
+			Currency:       it.Currency,
+			Condition:      it.Condition,
+			Category:       it.Category,
+			PickupLocation: it.PickupLocation,
+			CanShip:        it.CanShip,
+			Images:         imagesOf(imagesByItem, it.ID),
+			IsAvailable:    it.IsAvailable,
+			ListingType:    it.ListingType,
+			OwnerID:        it.OwnerID,
+			OwnerUsername:  usernameByID[it.OwnerID],
+
+			OwnerReputation:  reputation.Score,
+			OwnerReviewCount: reputation.ReviewCount,
+			ReservedUntil:    activeReservation(it.ReservedUntil, now),
 		})
 	}
 
@@ -168,10 +700,18 @@ func (s *MarketplaceService) CreateClubItem(
 	userID string,
 	clubID string,
 	req CreateItemRequest,
-) (string, error) {
+) (string, FilterVerdict, error) {
 
 	if req.Name == "" {
-		return "", errors.New("item name is required")
+		return "", FilterVerdict{}, errors.New("item name is required")
+	}
+	price, currency, err := priceEstimate(req.PriceEstimate, req.Currency)
+	if err != nil {
+		return "", FilterVerdict{}, err
+	}
+	condition, category, pickup, err := itemDetails(req.Condition, req.Category, req.PickupLocation)
+	if err != nil {
+		return "", FilterVerdict{}, err
 	}
 
 	// Membership check
@@ -180,26 +720,1782 @@ func (s *MarketplaceService) CreateClubItem(
 		ClubID: clubID,
 	})
 	if err != nil {
-		return "", err
+		return "", FilterVerdict{}, err
 	}
 	if isMember == 0 {
-		return "", errors.New("permission denied: not a club member")
+		return "", FilterVerdict{}, errors.New("permission denied: not a club member")
 	}
+	if err := s.checkListingReputation(ctx, userID, clubID); err != nil {
+		return "", FilterVerdict{}, err
+	}
+
+	verdict, err := filterContent(ctx, s.f, Content{
+		Kind:     ContentKindItem,
+		AuthorID: userID,
+		ClubID:   &clubID,
+		Text:     itemText(req.Name, req.Description),
+	})
+	if err != nil {
+		return "", verdict, err
+	}
+	status, reason := moderationStatus(verdict)
 
 	itemID := util.GenerateUUID()
 
 	// Create item explicitly tied to this club
 	err = s.q.CreateItemForClub(ctx, repository.CreateItemForClubParams{
-		ID:          itemID,
-		Name:        req.Name,
-		Description: req.Description,
-		IsAvailable: true,
-		OwnerID:     userID,
-		ClubID:      &clubID,
+		ID:               itemID,
+		Name:             req.Name,
+		Description:      req.Description,
+		PriceEstimate:    price,
+		Currency:         currency,
+		Condition:        emptyToNil(condition),
+		Category:         emptyToNil(category),
+		PickupLocation:   emptyToNil(pickup),
+		CanShip:          req.CanShip,
+		IsAvailable:      true,
+		OwnerID:          userID,
+		ClubID:           &clubID,
+		ModerationStatus: status,
+		ModerationReason: reason,
+	})
+	if err != nil {
+		return "", verdict, err
+	}
+
+	// held items are announced once a moderator approves them
+	if verdict.Action != FilterHold {
+		notifySavedSearches(ctx, s.q, s.w, itemID)
+	}
+	return itemID, verdict, nil
+}
+
+// checkListingReputation rejects members rated below the club's minimum, members without reviews have no score to compare
+func (s *MarketplaceService) checkListingReputation(ctx context.Context, userID string, clubID string) error {
+	club, err := s.q.GetClub(ctx, clubID)
+	if err != nil {
+		return err
+	}
+	if club.MinListingReputation <= 0 {
+		return nil
+	}
+	reputation, err := userReputation(ctx, s.q, userID)
+	if err != nil {
+		return err
+	}
+	if reputation.Score != nil && *reputation.Score < club.MinListingReputation {
+		return ErrReputationTooLow
+	}
+	return nil
+}
+
+// validateItemFilter checks the sort and price range, an empty sort lists the newest items first
+func validateItemFilter(filter ItemFilter) (ItemFilter, error) {
+	if filter.Sort == "" {
+		filter.Sort = ItemSortNewest
+	}
+	if !slices.Contains([]string{ItemSortNewest, ItemSortPriceAsc, ItemSortPriceDesc}, filter.Sort) ||
+		(filter.MinPrice != nil && *filter.MinPrice < 0) || (filter.MaxPrice != nil && *filter.MaxPrice < 0) {
+		return filter, ErrInvalidItemFilter
+	}
+	filter.Currency = strings.ToUpper(strings.TrimSpace(filter.Currency))
+	return filter, nil
+}
+
+// groupItemImages maps item ids to their photos
+func groupItemImages(images []repository.ItemImage) map[string][]repository.ItemImage {
+	byItem := map[string][]repository.ItemImage{}
+	for _, image := range images {
+		byItem[image.ItemID] = append(byItem[image.ItemID], image)
+	}
+	return byItem
+}
+
+// imagesOf returns an empty list for items without photos so they are never sent as null
+func imagesOf(byItem map[string][]repository.ItemImage, itemID string) []repository.ItemImage {
+	if images, ok := byItem[itemID]; ok {
+		return images
+	}
+	return []repository.ItemImage{}
+}
+
+// priceEstimate validates an estimate and its currency, an estimate without a currency is in the default one
+func priceEstimate(price *float64, currency *string) (*float64, *string, error) {
+	if price == nil {
+		if currency != nil {
+			return nil, nil, ErrInvalidPriceEstimate
+		}
+		return nil, nil, nil
+	}
+	if *price < 0 || math.IsNaN(*price) || math.IsInf(*price, 0) {
+		return nil, nil, ErrInvalidPriceEstimate
+	}
+
+	code := config.DefaultCurrency
+	if currency != nil {
+		code = strings.ToUpper(strings.TrimSpace(*currency))
+	}
+	if len(code) != 3 || strings.IndexFunc(code, func(r rune) bool { return r < 'A' || r > 'Z' }) != -1 {
+		return nil, nil, ErrInvalidPriceEstimate
+	}
+	return price, &code, nil
+}
+
+// itemDetails normalizes and validates an item's condition, category and pickup location, nil and empty values are left as they are
+func itemDetails(condition, category, pickupLocation *string) (*string, *string, *string, error) {
+	normalize := func(value *string) *string {
+		if value == nil {
+			return nil
+		}
+		v := strings.ToLower(strings.TrimSpace(*value))
+		return &v
+	}
+	condition, category = normalize(condition), normalize(category)
+	if condition != nil && *condition != "" && !slices.Contains(ItemConditions, *condition) {
+		return nil, nil, nil, ErrInvalidItemCondition
+	}
+	if category != nil && *category != "" && !slices.Contains(ItemCategories, *category) {
+		return nil, nil, nil, ErrInvalidItemCategory
+	}
+	if pickupLocation != nil {
+		pickup := strings.TrimSpace(*pickupLocation)
+		if utf8.RuneCountInString(pickup) > config.MaxPickupLocationLength {
+			return nil, nil, nil, ErrPickupLocationLength
+		}
+		pickupLocation = &pickup
+	}
+	return condition, category, pickupLocation, nil
+}
+
+func emptyToNil(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
+
+func (s *MarketplaceService) getOwnedItem(ctx context.Context, userID string, itemID string) (repository.Item, error) {
+	item, err := s.q.GetItem(ctx, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.Item{}, ErrItemNotFound
+	}
+	if err != nil {
+		return repository.Item{}, err
+	}
+	if item.OwnerID != userID {
+		return repository.Item{}, ErrNotItemOwner
+	}
+	return item, nil
+}
+
+// deleteItem deletes an item and then the files of its photos
+func deleteItem(ctx context.Context, q repository.Querier, i ImageServicer, itemID string) error {
+	images, err := deleteItemRecord(ctx, q, itemID)
+	if err != nil {
+		return err
+	}
+	deleteItemImageFiles(i, images)
+	return nil
+}
+
+// deleteItemRecord deletes an item and returns its photos, the rows go with the item through ON DELETE CASCADE.
+// inside a transaction the caller removes the files after it commits
+func deleteItemRecord(ctx context.Context, q repository.Querier, itemID string) ([]repository.ItemImage, error) {
+	images, err := q.GetItemImages(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	return images, q.DeleteItem(ctx, itemID)
+}
+
+func deleteItemImageFiles(i ImageServicer, images []repository.ItemImage) {
+	for _, image := range images {
+		deleteUploadedImage(i, &image.Url)
+		deleteUploadedImage(i, &image.ThumbnailUrl)
+	}
+}
+
+// itemText is what the content filter checks for an item
+func itemText(name string, description *string) string {
+	if description == nil {
+		return name
+	}
+	return name + "\n" + *description
+}
+
+/* ============================
+   Search Logic
+   ============================ */
+
+func (s *MarketplaceService) SearchItems(
+	ctx context.Context,
+	userID string,
+	search MarketplaceSearch,
+) ([]MarketplaceSearchItem, error) {
+
+	filter, err := validateItemFilter(search.ItemFilter)
+	if err != nil {
+		return nil, err
+	}
+	match, category, condition, err := searchTerms(search.Query, search.Category, search.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rows, err := s.q.SearchItems(ctx, repository.SearchItemsParams{
+		Sort:      filter.Sort,
+		Now:       &now,
+		Match:     match,
+		Category:  category,
+		Condition: condition,
+		OwnerID:   search.OwnerID,
+		Currency:  filter.Currency,
+		MinPrice:  filter.MinPrice,
+		MaxPrice:  filter.MaxPrice,
+		UserID:    userID,
+		Limit:     searchPageSize(search.Limit),
+		Offset:    max(search.Offset, 0),
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	itemIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		itemIDs = append(itemIDs, row.ID)
+	}
+	images, err := s.q.GetImagesOfItems(ctx, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	imagesByItem := groupItemImages(images)
+
+	out := make([]MarketplaceSearchItem, 0, len(rows))
+	reputationByID := map[string]Reputation{}
+	for _, row := range rows {
+		reputation, err := cachedReputation(ctx, s.q, reputationByID, row.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, MarketplaceSearchItem{
+			ClubMarketplaceItem: ClubMarketplaceItem{
+				ID:             row.ID,
+				Name:           row.Name,
+				Description:    row.Description,
+				PriceEstimate:  row.PriceEstimate,
+				Currency:       row.Currency,
+				Condition:      row.Condition,
+				Category:       row.Category,
+				PickupLocation: row.PickupLocation,
+				CanShip:        row.CanShip,
+				Images:         imagesOf(imagesByItem, row.ID),
+				IsAvailable:    row.IsAvailable,
+				ListingType:    row.ListingType,
+				OwnerID:        row.OwnerID,
+				OwnerUsername:  row.OwnerUsername,
+
+				OwnerReputation:  reputation.Score,
+				OwnerReviewCount: reputation.ReviewCount,
+				ReservedUntil:    activeReservation(row.ReservedUntil, now),
+			},
+			ClubID:   *row.ClubID,
+			ClubName: row.ClubName,
+		})
+	}
+	return out, nil
+}
+
+func (s *MarketplaceService) CreateSavedSearch(
+	ctx context.Context,
+	userID string,
+	req SavedSearchRequest,
+) (string, error) {
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > config.MaxSavedSearchNameLength {
+		return "", ErrSavedSearchName
+	}
+	filter, err := validateItemFilter(ItemFilter{Currency: req.Currency, MinPrice: req.MinPrice, MaxPrice: req.MaxPrice})
+	if err != nil {
+		return "", err
+	}
+	match, category, condition, err := searchTerms(req.Query, req.Category, req.Condition)
 	if err != nil {
 		return "", err
 	}
 
-	return itemID, nil
+	count, err := s.q.CountSavedSearches(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if count >= config.MaxSavedSearches {
+		return "", ErrTooManySavedSearches
+	}
+
+	searchID := util.GenerateUUID()
+	err = s.q.CreateSavedSearch(ctx, repository.CreateSavedSearchParams{
+		ID:         searchID,
+		UserID:     userID,
+		Name:       name,
+		Query:      strings.TrimSpace(req.Query),
+		MatchQuery: match,
+		Category:   emptyToNil(&category),
+		Condition:  emptyToNil(&condition),
+		OwnerID:    emptyToNil(&req.OwnerID),
+		Currency:   emptyToNil(&filter.Currency),
+		MinPrice:   filter.MinPrice,
+		MaxPrice:   filter.MaxPrice,
+	})
+	if err != nil {
+		return "", err
+	}
+	return searchID, nil
+}
+
+func (s *MarketplaceService) GetSavedSearches(ctx context.Context, userID string) ([]repository.SavedSearch, error) {
+	return s.q.GetSavedSearches(ctx, userID)
+}
+
+func (s *MarketplaceService) DeleteSavedSearch(ctx context.Context, userID string, searchID string) error {
+	rows, err := s.q.DeleteSavedSearch(ctx, repository.DeleteSavedSearchParams{
+		ID:     searchID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// searchTerms turns a query into an FTS5 expression and normalizes the category and condition filters
+func searchTerms(query, category, condition string) (string, string, string, error) {
+	if utf8.RuneCountInString(query) > config.MaxSearchQueryLength {
+		return "", "", "", ErrSearchQueryLength
+	}
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category != "" && !slices.Contains(ItemCategories, category) {
+		return "", "", "", ErrInvalidItemCategory
+	}
+	condition = strings.ToLower(strings.TrimSpace(condition))
+	if condition != "" && !slices.Contains(ItemConditions, condition) {
+		return "", "", "", ErrInvalidItemCondition
+	}
+	return matchQuery(query), category, condition, nil
+}
+
+// matchQuery quotes every word of a query as a prefix so nothing the user types is read as FTS5 syntax,
+// an item has to match all of the words
+func matchQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for i, word := range words {
+		words[i] = `"` + word + `"*`
+	}
+	return strings.Join(words, " ")
+}
+
+func searchPageSize(limit int64) int64 {
+	if limit <= 0 {
+		return config.DefaultSearchPageSize
+	}
+	return min(limit, config.MaxSearchPageSize)
+}
+
+// notifySavedSearches tells the members of an item's club whose saved searches it matches that it was listed
+func notifySavedSearches(ctx context.Context, q repository.Querier, w WebSocketServicer, itemID string) {
+	matches, err := q.GetSavedSearchesMatchingItem(ctx, itemID)
+	if err != nil {
+		log.Printf("Failed to match item %s against saved searches: %v", itemID, err)
+		return
+	}
+	if len(matches) == 0 {
+		return
+	}
+	item, clubID, err := marketplaceItem(ctx, q, itemID)
+	if err != nil {
+		log.Printf("Failed to load item %s for saved searches: %v", itemID, err)
+		return
+	}
+	match := SavedSearchMatch{
+		Item:   item,
+		ClubID: clubID,
+	}
+
+	// one message per user listing every search of theirs the item matches, the rows are ordered by user
+	for start := 0; start < len(matches); {
+		end := start
+		for end < len(matches) && matches[end].UserID == matches[start].UserID {
+			end++
+		}
+		match.Searches = matches[start:end]
+		jsonBytes, err := json.Marshal(WebSocketMessage{
+			Event:   "saved_search_match",
+			Payload: match,
+		})
+		if err != nil {
+			log.Printf("Failed to encode saved_search_match: %v", err)
+			return
+		}
+		w.BroadcastMessage(ctx, []string{matches[start].UserID}, string(jsonBytes))
+		start = end
+	}
+}
+
+// marketplaceItem loads a club item the way it is listed, for notifications about it
+func marketplaceItem(ctx context.Context, q repository.Querier, itemID string) (ClubMarketplaceItem, string, error) {
+	item, err := q.GetItem(ctx, itemID)
+	if err != nil {
+		return ClubMarketplaceItem{}, "", err
+	}
+	if item.ClubID == nil {
+		return ClubMarketplaceItem{}, "", ErrItemNotFound
+	}
+	images, err := q.GetItemImages(ctx, itemID)
+	if err != nil {
+		return ClubMarketplaceItem{}, "", err
+	}
+	owner := "unknown"
+	if disp, err := q.GetUserDisplay(ctx, item.OwnerID); err == nil {
+		owner = disp.Username
+	}
+	reputation, err := userReputation(ctx, q, item.OwnerID)
+	if err != nil {
+		return ClubMarketplaceItem{}, "", err
+	}
+	return ClubMarketplaceItem{
+		ID:             item.ID,
+		Name:           item.Name,
+		Description:    item.Description,
+		PriceEstimate:  item.PriceEstimate,
+		Currency:       item.Currency,
+		Condition:      item.Condition,
+		Category:       item.Category,
+		PickupLocation: item.PickupLocation,
+		CanShip:        item.CanShip,
+		Images:         imagesOf(groupItemImages(images), item.ID),
+		IsAvailable:    item.IsAvailable,
+		ListingType:    item.ListingType,
+		OwnerID:        item.OwnerID,
+		OwnerUsername:  owner,
+
+		OwnerReputation:  reputation.Score,
+		OwnerReviewCount: reputation.ReviewCount,
+	}, *item.ClubID, nil
+}
+
+/* ============================
+   Reservation Logic
+   ============================ */
+
+func (s *MarketplaceService) ReserveItem(
+	ctx context.Context,
+	userID string,
+	itemID string,
+	reservedFor string,
+	duration time.Duration,
+) (time.Time, error) {
+
+	if duration == 0 {
+		duration = config.DefaultReservationDuration
+	}
+	if duration < time.Hour || duration > config.MaxReservationDuration {
+		return time.Time{}, ErrReservationDuration
+	}
+	item, err := s.getOwnedItem(ctx, userID, itemID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if reservedFor == userID {
+		return time.Time{}, ErrReserveOwnItem
+	}
+	if !isTradable(item) || item.ClubID == nil {
+		return time.Time{}, ErrItemUnavailable
+	}
+	isMember, err := s.q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
+		UserID: reservedFor,
+		ClubID: *item.ClubID,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if isMember == 0 {
+		return time.Time{}, ErrReserveNotMember
+	}
+
+	until := time.Now().Add(duration)
+	err = s.q.ReserveItem(ctx, repository.ReserveItemParams{
+		ReservedFor:   &reservedFor,
+		ReservedUntil: &until,
+		ID:            itemID,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if reserved, _, err := marketplaceItem(ctx, s.q, itemID); err != nil {
+		log.Printf("Failed to load item %s for item_reserved: %v", itemID, err)
+	} else {
+		reserved.ReservedUntil = &until
+		jsonBytes, err := json.Marshal(WebSocketMessage{
+			Event:   "item_reserved",
+			Payload: reserved,
+		})
+		if err != nil {
+			log.Printf("Failed to encode item_reserved: %v", err)
+		} else {
+			s.w.BroadcastMessage(ctx, []string{reservedFor}, string(jsonBytes))
+		}
+	}
+	return until, nil
+}
+
+func (s *MarketplaceService) ReleaseItemReservation(ctx context.Context, userID string, itemID string) error {
+	item, err := s.getOwnedItem(ctx, userID, itemID)
+	if err != nil {
+		return err
+	}
+	if activeReservation(item.ReservedUntil, time.Now()) == nil {
+		return ErrItemNotReserved
+	}
+	if err := s.q.ReleaseItemReservation(ctx, itemID); err != nil {
+		return err
+	}
+	if isTradable(item) {
+		notifyWishlist(ctx, s.q, s.w, itemID, WishlistUpdate{Available: true})
+	}
+	return nil
+}
+
+// activeReservation returns when the reservation ends, nil if there is none or it already ended
+func activeReservation(reservedUntil *time.Time, now time.Time) *time.Time {
+	if reservedUntil == nil || !reservedUntil.After(now) {
+		return nil
+	}
+	return reservedUntil
+}
+
+// reservedForOther reports whether the item is held for someone other than the user
+func reservedForOther(item repository.Item, userID string) bool {
+	return activeReservation(item.ReservedUntil, time.Now()) != nil && item.ReservedFor != nil && *item.ReservedFor != userID
+}
+
+/* ============================
+   Wishlist Logic
+   ============================ */
+
+func (s *MarketplaceService) AddToWishlist(ctx context.Context, userID string, itemID string) error {
+	item, err := getTradeItem(ctx, s.q, userID, itemID)
+	if errors.Is(err, ErrTradeItemNotFound) || (err == nil && (item.ClubID == nil || item.ModerationStatus != ModerationApproved)) {
+		return ErrItemNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if item.OwnerID == userID {
+		return ErrWishlistOwnItem
+	}
+
+	count, err := s.q.CountWishlist(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if count >= config.MaxWishlistItems {
+		return ErrWishlistFull
+	}
+	return s.q.AddToWishlist(ctx, repository.AddToWishlistParams{
+		UserID: userID,
+		ItemID: itemID,
+	})
+}
+
+func (s *MarketplaceService) RemoveFromWishlist(ctx context.Context, userID string, itemID string) error {
+	rows, err := s.q.RemoveFromWishlist(ctx, repository.RemoveFromWishlistParams{
+		UserID: userID,
+		ItemID: itemID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrWishlistItemNotFound
+	}
+	return nil
+}
+
+func (s *MarketplaceService) GetWishlist(ctx context.Context, userID string) ([]WishlistItem, error) {
+	rows, err := s.q.GetWishlist(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		itemIDs = append(itemIDs, row.ID)
+	}
+	images, err := s.q.GetImagesOfItems(ctx, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	imagesByItem := groupItemImages(images)
+
+	now := time.Now()
+	out := make([]WishlistItem, 0, len(rows))
+	reputationByID := map[string]Reputation{}
+	for _, row := range rows {
+		reputation, err := cachedReputation(ctx, s.q, reputationByID, row.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		reservedUntil := activeReservation(row.ReservedUntil, now)
+		forUser := reservedUntil != nil && row.ReservedFor != nil && *row.ReservedFor == userID
+		item := WishlistItem{
+			ClubMarketplaceItem: ClubMarketplaceItem{
+				ID:             row.ID,
+				Name:           row.Name,
+				Description:    row.Description,
+				PriceEstimate:  row.PriceEstimate,
+				Currency:       row.Currency,
+				Condition:      row.Condition,
+				Category:       row.Category,
+				PickupLocation: row.PickupLocation,
+				CanShip:        row.CanShip,
+				Images:         imagesOf(imagesByItem, row.ID),
+				IsAvailable:    row.IsAvailable,
+				ListingType:    row.ListingType,
+				OwnerID:        row.OwnerID,
+				OwnerUsername:  row.OwnerUsername,
+
+				OwnerReputation:  reputation.Score,
+				OwnerReviewCount: reputation.ReviewCount,
+			},
+			ClubID:       *row.ClubID,
+			Reserved:     reservedUntil != nil && !forUser,
+			WishlistedAt: row.WishlistedAt,
+		}
+		if forUser {
+			item.ReservedUntil = reservedUntil
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func (s *MarketplaceService) GetItemWishlisters(ctx context.Context, userID string, itemID string) ([]repository.GetItemWishlistersRow, error) {
+	if _, err := s.getOwnedItem(ctx, userID, itemID); err != nil {
+		return nil, err
+	}
+	users, err := s.q.GetItemWishlisters(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []repository.GetItemWishlistersRow{}
+	}
+	return users, nil
+}
+
+// notifyWishlist tells the members of an item's club who wishlisted it what changed, the change is already saved so failures are only logged
+func notifyWishlist(ctx context.Context, q repository.Querier, w WebSocketServicer, itemID string, update WishlistUpdate) {
+	userIDs, err := q.GetItemWishlisterIDs(ctx, itemID)
+	if err != nil {
+		log.Printf("Failed to load the wishlists of item %s: %v", itemID, err)
+		return
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+	update.Item, update.ClubID, err = marketplaceItem(ctx, q, itemID)
+	if err != nil {
+		log.Printf("Failed to load item %s for wishlists: %v", itemID, err)
+		return
+	}
+	jsonBytes, err := json.Marshal(WebSocketMessage{
+		Event:   "wishlist_item_updated",
+		Payload: update,
+	})
+	if err != nil {
+		log.Printf("Failed to encode wishlist_item_updated: %v", err)
+		return
+	}
+	w.BroadcastMessage(ctx, userIDs, string(jsonBytes))
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+/* ============================
+   Auction Logic
+   ============================ */
+
+func (s *MarketplaceService) CreateAuction(
+	ctx context.Context,
+	userID string,
+	itemID string,
+	req AuctionRequest,
+) (string, error) {
+
+	item, err := s.getOwnedItem(ctx, userID, itemID)
+	if err != nil {
+		return "", err
+	}
+	if item.ClubID == nil || !isTradable(item) || activeReservation(item.ReservedUntil, time.Now()) != nil {
+		return "", ErrItemUnavailable
+	}
+
+	currency := req.Currency
+	if currency == nil {
+		currency = item.Currency
+	}
+	_, currency, err = priceEstimate(&req.StartPrice, currency)
+	if err != nil {
+		return "", ErrInvalidAuction
+	}
+	if req.BidIncrement == 0 {
+		req.BidIncrement = config.DefaultBidIncrement
+	}
+	if !(req.BidIncrement >= 0.01) || math.IsInf(req.BidIncrement, 0) ||
+		(req.ReservePrice != nil && (!(*req.ReservePrice >= req.StartPrice) || math.IsInf(*req.ReservePrice, 0))) {
+		return "", ErrInvalidAuction
+	}
+	now := time.Now()
+	if req.EndsAt.Before(now.Add(config.MinAuctionDuration)) || req.EndsAt.After(now.Add(config.MaxAuctionDuration)) {
+		return "", ErrAuctionEndTime
+	}
+
+	auctionID := util.GenerateUUID()
+	err = s.tx.WithTx(ctx, func(q repository.Querier) error {
+		err := q.CreateAuction(ctx, repository.CreateAuctionParams{
+			ID:           auctionID,
+			ItemID:       itemID,
+			ClubID:       *item.ClubID,
+			SellerID:     userID,
+			StartPrice:   req.StartPrice,
+			ReservePrice: req.ReservePrice,
+			BidIncrement: req.BidIncrement,
+			Currency:     *currency,
+			EndsAt:       req.EndsAt,
+		})
+		if err != nil {
+			return err
+		}
+		return q.SetItemListingType(ctx, repository.SetItemListingTypeParams{
+			ListingType: ListingAuction,
+			ID:          itemID,
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return auctionID, nil
+}
+
+func (s *MarketplaceService) GetClubAuctions(
+	ctx context.Context,
+	userID string,
+	clubID string,
+) ([]AuctionDetails, error) {
+
+	isMember, err := s.q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if isMember == 0 {
+		return nil, errors.New("permission denied: not a club member")
+	}
+
+	auctions, err := s.q.GetClubAuctions(ctx, clubID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]AuctionDetails, 0, len(auctions))
+	for _, auction := range auctions {
+		details, err := auctionDetails(ctx, s.q, auction, userID)
+		if err != nil {
+			return nil, err
+		}
+		details.Bids = nil
+		out = append(out, details)
+	}
+	return out, nil
+}
+
+func (s *MarketplaceService) GetAuction(
+	ctx context.Context,
+	userID string,
+	auctionID string,
+) (AuctionDetails, error) {
+
+	auction, err := getAuction(ctx, s.q, userID, auctionID)
+	if err != nil {
+		return AuctionDetails{}, err
+	}
+	return auctionDetails(ctx, s.q, auction, userID)
+}
+
+func (s *MarketplaceService) PlaceBid(
+	ctx context.Context,
+	userID string,
+	auctionID string,
+	amount float64,
+) (AuctionDetails, error) {
+
+	err := s.tx.WithTx(ctx, func(q repository.Querier) error {
+		auction, err := getAuction(ctx, q, userID, auctionID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if auction.Status != AuctionOpen || !now.Before(auction.EndsAt) {
+			return ErrAuctionClosed
+		}
+		if auction.SellerID == userID {
+			return ErrBidOwnAuction
+		}
+
+		bids, err := q.GetAuctionBids(ctx, auctionID)
+		if err != nil {
+			return err
+		}
+		if len(bids) > 0 && bids[0].BidderID == userID {
+			return ErrAlreadyHighestBidder
+		}
+		if minimum := minimumBid(auction, bids); !(amount >= minimum) || math.IsInf(amount, 0) {
+			return fmt.Errorf("%w, bid at least %.2f %s", ErrBidTooLow, minimum, auction.Currency)
+		}
+
+		err = q.CreateAuctionBid(ctx, repository.CreateAuctionBidParams{
+			ID:        util.GenerateUUID(),
+			AuctionID: auctionID,
+			BidderID:  userID,
+			Amount:    amount,
+		})
+		if err != nil {
+			return err
+		}
+		// bidding at the last moment leaves the others time to answer
+		if auction.EndsAt.Sub(now) < config.AuctionSnipingWindow {
+			return q.ExtendAuction(ctx, repository.ExtendAuctionParams{
+				EndsAt: now.Add(config.AuctionExtension),
+				ID:     auctionID,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return AuctionDetails{}, err
+	}
+
+	notifyAuction(ctx, s.q, s.w, "auction_bid", auctionID)
+	auction, err := s.q.GetAuction(ctx, auctionID)
+	if err != nil {
+		return AuctionDetails{}, err
+	}
+	return auctionDetails(ctx, s.q, auction, userID)
+}
+
+func (s *MarketplaceService) CancelAuction(ctx context.Context, userID string, auctionID string) error {
+	err := s.tx.WithTx(ctx, func(q repository.Querier) error {
+		auction, err := getAuction(ctx, q, userID, auctionID)
+		if err != nil {
+			return err
+		}
+		if auction.SellerID != userID {
+			return ErrNotAuctionSeller
+		}
+		if auction.Status != AuctionOpen {
+			return ErrAuctionClosed
+		}
+		bids, err := q.GetAuctionBids(ctx, auctionID)
+		if err != nil {
+			return err
+		}
+		if len(bids) > 0 {
+			return ErrAuctionHasBids
+		}
+		return closeAuction(ctx, q, auction, repository.CloseAuctionParams{Status: AuctionCancelled})
+	})
+	if err != nil {
+		return err
+	}
+	notifyAuction(ctx, s.q, s.w, "auction_ended", auctionID)
+	return nil
+}
+
+// SettleAuctions closes the open auctions that ended before now. the item goes to the highest bidder
+// if the bid reached the reserve price, the members of the auction's club are told how it ended.
+// an auction that fails to settle is logged and left open for the next run
+func SettleAuctions(ctx context.Context, q repository.Querier, tx Transactor, w WebSocketServicer, now time.Time) error {
+	auctionIDs, err := q.GetEndedAuctions(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, auctionID := range auctionIDs {
+		if err := settleAuction(ctx, tx, auctionID); err != nil {
+			log.Printf("Failed to settle auction %s: %v", auctionID, err)
+			continue
+		}
+		notifyAuction(ctx, q, w, "auction_ended", auctionID)
+	}
+	return nil
+}
+
+func settleAuction(ctx context.Context, tx Transactor, auctionID string) error {
+	return tx.WithTx(ctx, func(q repository.Querier) error {
+		auction, err := q.GetAuction(ctx, auctionID)
+		if err != nil {
+			return err
+		}
+		bids, err := q.GetAuctionBids(ctx, auctionID)
+		if err != nil {
+			return err
+		}
+		params := repository.CloseAuctionParams{Status: AuctionUnsold}
+		if len(bids) > 0 && (auction.ReservePrice == nil || bids[0].Amount >= *auction.ReservePrice) {
+			params.Status, params.WinnerID, params.WinningBid = AuctionSold, &bids[0].BidderID, &bids[0].Amount
+		}
+		return closeAuction(ctx, q, auction, params)
+	})
+}
+
+// closeAuction ends the auction, hands the item to the winner if there is one and lists it for trading again
+func closeAuction(ctx context.Context, q repository.Querier, auction repository.Auction, params repository.CloseAuctionParams) error {
+	params.ID = auction.ID
+	rows, err := q.CloseAuction(ctx, params)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAuctionClosed
+	}
+	if params.WinnerID != nil {
+		err := q.TransferItemOwnership(ctx, repository.TransferItemOwnershipParams{
+			OwnerID: *params.WinnerID,
+			ID:      auction.ItemID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return q.SetItemListingType(ctx, repository.SetItemListingTypeParams{
+		ListingType: ListingTrade,
+		ID:          auction.ItemID,
+	})
+}
+
+// Auctions are only visible to the members of their club.
+func getAuction(ctx context.Context, q repository.Querier, userID string, auctionID string) (repository.Auction, error) {
+	auction, err := q.GetAuction(ctx, auctionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.Auction{}, ErrAuctionNotFound
+	}
+	if err != nil {
+		return repository.Auction{}, err
+	}
+	isMember, err := q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
+		UserID: userID,
+		ClubID: auction.ClubID,
+	})
+	if err != nil {
+		return repository.Auction{}, err
+	}
+	if isMember == 0 {
+		return repository.Auction{}, ErrAuctionNotFound
+	}
+	return auction, nil
+}
+
+// auctionDetails adds the item, the bids and what the next bid has to be. the reserve price is only shown to the seller
+func auctionDetails(ctx context.Context, q repository.Querier, auction repository.Auction, userID string) (AuctionDetails, error) {
+	item, _, err := marketplaceItem(ctx, q, auction.ItemID)
+	if err != nil {
+		return AuctionDetails{}, err
+	}
+	seller := "unknown"
+	if disp, err := q.GetUserDisplay(ctx, auction.SellerID); err == nil {
+		seller = disp.Username
+	}
+	bids, err := q.GetAuctionBids(ctx, auction.ID)
+	if err != nil {
+		return AuctionDetails{}, err
+	}
+
+	details := AuctionDetails{
+		ID:             auction.ID,
+		Item:           item,
+		ClubID:         auction.ClubID,
+		SellerID:       auction.SellerID,
+		SellerUsername: seller,
+		StartPrice:     auction.StartPrice,
+		BidIncrement:   auction.BidIncrement,
+		Currency:       auction.Currency,
+		HasReserve:     auction.ReservePrice != nil,
+		EndsAt:         auction.EndsAt,
+		Status:         auction.Status,
+		WinnerID:       auction.WinnerID,
+		WinningBid:     auction.WinningBid,
+		BidCount:       len(bids),
+		MinimumBid:     minimumBid(auction, bids),
+		Bids:           bids,
+	}
+	if details.Bids == nil {
+		details.Bids = []repository.GetAuctionBidsRow{}
+	}
+	if len(bids) > 0 {
+		details.HighestBid = &bids[0].Amount
+		details.ReserveMet = auction.ReservePrice == nil || bids[0].Amount >= *auction.ReservePrice
+	}
+	if auction.SellerID == userID {
+		details.ReservePrice = auction.ReservePrice
+	}
+	return details, nil
+}
+
+// minimumBid is the start price until someone bids, then the highest bid plus the increment rounded to cents
+func minimumBid(auction repository.Auction, bids []repository.GetAuctionBidsRow) float64 {
+	if len(bids) == 0 {
+		return auction.StartPrice
+	}
+	return math.Round((bids[0].Amount+auction.BidIncrement)*100) / 100
+}
+
+// notifyAuction tells the members of the auction's club subscribed to its auctions about it, the change is already saved so failures are only logged
+func notifyAuction(ctx context.Context, q repository.Querier, w WebSocketServicer, event string, auctionID string) {
+	auction, err := q.GetAuction(ctx, auctionID)
+	if err != nil {
+		log.Printf("Failed to load auction %s for %s: %v", auctionID, event, err)
+		return
+	}
+	details, err := auctionDetails(ctx, q, auction, "")
+	if err != nil {
+		log.Printf("Failed to load auction %s for %s: %v", auctionID, event, err)
+		return
+	}
+	members, err := q.GetClubUserIds(ctx, auction.ClubID)
+	if err != nil {
+		log.Printf("Failed to load the members of club %s for %s: %v", auction.ClubID, event, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(WebSocketMessage{
+		Event:   event,
+		Topic:   ClubAuctionsTopic(auction.ClubID),
+		Payload: details,
+	})
+	if err != nil {
+		log.Printf("Failed to encode %s: %v", event, err)
+		return
+	}
+	w.PublishMessage(ctx, members, ClubAuctionsTopic(auction.ClubID), string(jsonBytes))
+}
+
+/* ============================
+   Trade Logic
+   ============================ */
+
+func (s *MarketplaceService) ProposeTrade(
+	ctx context.Context,
+	userID string,
+	req TradeRequest,
+) (string, string, error) {
+
+	tradeID := util.GenerateUUID()
+	var warning string
+	err := s.tx.WithTx(ctx, func(q repository.Querier) (err error) {
+		warning, err = createTrade(ctx, q, tradeID, userID, req, nil)
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	s.notifyTrade(ctx, "trade_proposed", tradeID)
+	return tradeID, warning, nil
+}
+
+func (s *MarketplaceService) CounterTrade(
+	ctx context.Context,
+	userID string,
+	tradeID string,
+	req TradeRequest,
+) (string, string, error) {
+
+	trade, err := s.getTrade(ctx, userID, tradeID)
+	if err != nil {
+		return "", "", err
+	}
+	if trade.ResponderID != userID {
+		return "", "", ErrTradeNotResponder
+	}
+	if req.ResponderID != "" && req.ResponderID != trade.ProposerID {
+		return "", "", ErrCounterOtherUser
+	}
+	req.ResponderID = trade.ProposerID
+
+	counterID := util.GenerateUUID()
+	var warning string
+	err = s.tx.WithTx(ctx, func(q repository.Querier) (err error) {
+		if err := setTradeStatus(ctx, q, tradeID, TradeCountered); err != nil {
+			return err
+		}
+		warning, err = createTrade(ctx, q, counterID, userID, req, &tradeID)
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	s.notifyTrade(ctx, "trade_countered", counterID)
+	return counterID, warning, nil
+}
+
+func (s *MarketplaceService) AcceptTrade(
+	ctx context.Context,
+	userID string,
+	tradeID string,
+) error {
+
+	trade, err := s.getTrade(ctx, userID, tradeID)
+	if err != nil {
+		return err
+	}
+	if trade.ResponderID != userID {
+		return ErrTradeNotResponder
+	}
+
+	var cancelled []string
+	err = s.tx.WithTx(ctx, func(q repository.Querier) error {
+		if err := setTradeStatus(ctx, q, tradeID, TradeAccepted); err != nil {
+			return err
+		}
+
+		items, err := q.GetTradeItems(ctx, tradeID)
+		if err != nil {
+			return err
+		}
+		for _, ti := range items {
+			// the items may have been traded or taken off the market since the trade was proposed
+			item, err := q.GetItem(ctx, ti.ItemID)
+			if err != nil {
+				return err
+			}
+			newOwner := trade.ResponderID
+			if ti.OwnerID == trade.ResponderID {
+				newOwner = trade.ProposerID
+			}
+			if item.OwnerID != ti.OwnerID || !isTradable(item) || reservedForOther(item, newOwner) {
+				return ErrTradeItemUnavailable
+			}
+			if err := q.TransferItemOwnership(ctx, repository.TransferItemOwnershipParams{
+				OwnerID: newOwner,
+				ID:      ti.ItemID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if trade.ProposerPoints > 0 {
+			if err := payClubPoints(ctx, q, *trade.ClubID, trade.ID, trade.ProposerID, trade.ResponderID, trade.ProposerPoints); err != nil {
+				return err
+			}
+		}
+		if trade.ResponderPoints > 0 {
+			if err := payClubPoints(ctx, q, *trade.ClubID, trade.ID, trade.ResponderID, trade.ProposerID, trade.ResponderPoints); err != nil {
+				return err
+			}
+		}
+
+		// nobody can give away an item they no longer own
+		cancelled, err = q.GetPendingTradesSharingItems(ctx, tradeID)
+		if err != nil {
+			return err
+		}
+		for _, id := range cancelled {
+			if err := setTradeStatus(ctx, q, id, TradeCancelled); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyTrade(ctx, "trade_accepted", tradeID)
+	for _, id := range cancelled {
+		s.notifyTrade(ctx, "trade_cancelled", id)
+	}
+	return nil
+}
+
+func (s *MarketplaceService) RejectTrade(
+	ctx context.Context,
+	userID string,
+	tradeID string,
+) error {
+
+	trade, err := s.getTrade(ctx, userID, tradeID)
+	if err != nil {
+		return err
+	}
+	if trade.ResponderID != userID {
+		return ErrTradeNotResponder
+	}
+
+	if err := setTradeStatus(ctx, s.q, tradeID, TradeRejected); err != nil {
+		return err
+	}
+	s.notifyTrade(ctx, "trade_rejected", tradeID)
+	return nil
+}
+
+func (s *MarketplaceService) CancelTrade(
+	ctx context.Context,
+	userID string,
+	tradeID string,
+) error {
+
+	trade, err := s.getTrade(ctx, userID, tradeID)
+	if err != nil {
+		return err
+	}
+	if trade.ProposerID != userID {
+		return ErrTradeNotProposer
+	}
+
+	if err := setTradeStatus(ctx, s.q, tradeID, TradeCancelled); err != nil {
+		return err
+	}
+	s.notifyTrade(ctx, "trade_cancelled", tradeID)
+	return nil
+}
+
+func (s *MarketplaceService) GetTrade(
+	ctx context.Context,
+	userID string,
+	tradeID string,
+) (TradeDetails, error) {
+
+	trade, err := s.getTrade(ctx, userID, tradeID)
+	if err != nil {
+		return TradeDetails{}, err
+	}
+	return s.tradeDetails(ctx, trade)
+}
+
+func (s *MarketplaceService) GetIncomingTrades(
+	ctx context.Context,
+	userID string,
+	status string,
+) ([]TradeDetails, error) {
+
+	if err := checkTradeStatus(status); err != nil {
+		return nil, err
+	}
+	rows, err := s.q.GetIncomingTrades(ctx, repository.GetIncomingTradesParams{
+		UserID: userID,
+		Status: status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tradeList(ctx, rows)
+}
+
+func (s *MarketplaceService) GetOutgoingTrades(
+	ctx context.Context,
+	userID string,
+	status string,
+) ([]TradeDetails, error) {
+
+	if err := checkTradeStatus(status); err != nil {
+		return nil, err
+	}
+	rows, err := s.q.GetOutgoingTrades(ctx, repository.GetOutgoingTradesParams{
+		UserID: userID,
+		Status: status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// both listings return the same columns
+	incoming := make([]repository.GetIncomingTradesRow, 0, len(rows))
+	for _, row := range rows {
+		incoming = append(incoming, repository.GetIncomingTradesRow(row))
+	}
+	return s.tradeList(ctx, incoming)
+}
+
+// Trades are only visible to their two parties.
+func (s *MarketplaceService) getTrade(ctx context.Context, userID string, tradeID string) (repository.Trade, error) {
+	trade, err := s.q.GetTradeByID(ctx, tradeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.Trade{}, ErrTradeNotFound
+	}
+	if err != nil {
+		return repository.Trade{}, err
+	}
+	if trade.ProposerID != userID && trade.ResponderID != userID {
+		return repository.Trade{}, ErrTradeNotFound
+	}
+	return trade, nil
+}
+
+func (s *MarketplaceService) tradeDetails(ctx context.Context, trade repository.Trade) (TradeDetails, error) {
+	proposer, err := s.q.GetUserDisplay(ctx, trade.ProposerID)
+	if err != nil {
+		return TradeDetails{}, err
+	}
+	responder, err := s.q.GetUserDisplay(ctx, trade.ResponderID)
+	if err != nil {
+		return TradeDetails{}, err
+	}
+	return s.tradeItems(ctx, TradeDetails{
+		Trade:             trade,
+		ProposerUsername:  proposer.Username,
+		ResponderUsername: responder.Username,
+	})
+}
+
+func (s *MarketplaceService) tradeList(ctx context.Context, rows []repository.GetIncomingTradesRow) ([]TradeDetails, error) {
+	out := make([]TradeDetails, 0, len(rows))
+	for _, row := range rows {
+		details, err := s.tradeItems(ctx, TradeDetails{
+			Trade: repository.Trade{
+				ID:               row.ID,
+				ProposerID:       row.ProposerID,
+				ResponderID:      row.ResponderID,
+				Status:           row.Status,
+				CounteredTradeID: row.CounteredTradeID,
+				ClubID:           row.ClubID,
+				ProposerPoints:   row.ProposerPoints,
+				ResponderPoints:  row.ResponderPoints,
+				CreatedAt:        row.CreatedAt,
+				UpdatedAt:        row.UpdatedAt,
+			},
+			ProposerUsername:  row.ProposerUsername,
+			ResponderUsername: row.ResponderUsername,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, details)
+	}
+	return out, nil
+}
+
+// tradeItems splits the trade's items into what each side gives and adds the trade's reviews
+func (s *MarketplaceService) tradeItems(ctx context.Context, details TradeDetails) (TradeDetails, error) {
+	items, err := s.q.GetTradeItems(ctx, details.ID)
+	if err != nil {
+		return TradeDetails{}, err
+	}
+	details.Reviews, err = s.q.GetTradeReviews(ctx, details.ID)
+	if err != nil {
+		return TradeDetails{}, err
+	}
+	if details.Reviews == nil {
+		details.Reviews = []repository.TradeReview{}
+	}
+	details.ProposerItems = []repository.GetTradeItemsRow{}
+	details.ResponderItems = []repository.GetTradeItemsRow{}
+	for _, item := range items {
+		if item.OwnerID == details.ProposerID {
+			details.ProposerItems = append(details.ProposerItems, item)
+		} else {
+			details.ResponderItems = append(details.ResponderItems, item)
+		}
+	}
+	return details, nil
+}
+
+/* ============================
+   Review Logic
+   ============================ */
+
+func (s *MarketplaceService) ReviewTrade(
+	ctx context.Context,
+	userID string,
+	tradeID string,
+	req TradeReviewRequest,
+) error {
+
+	if req.Rating < config.MinReviewRating || req.Rating > config.MaxReviewRating {
+		return ErrInvalidRating
+	}
+	var comment *string
+	if req.Comment != nil {
+		trimmed := strings.TrimSpace(*req.Comment)
+		comment = emptyToNil(&trimmed)
+	}
+	if comment != nil && utf8.RuneCountInString(*comment) > config.MaxReviewCommentLength {
+		return ErrReviewCommentLength
+	}
+
+	trade, err := s.getTrade(ctx, userID, tradeID)
+	if err != nil {
+		return err
+	}
+	if trade.Status != TradeAccepted {
+		return ErrTradeNotCompleted
+	}
+	revieweeID := trade.ResponderID
+	if userID == trade.ResponderID {
+		revieweeID = trade.ProposerID
+	}
+
+	if comment != nil {
+		if _, err := filterContent(ctx, s.f, Content{
+			Kind:     ContentKindReview,
+			AuthorID: userID,
+			Text:     *comment,
+		}); err != nil {
+			return err
+		}
+	}
+
+	rows, err := s.q.CreateTradeReview(ctx, repository.CreateTradeReviewParams{
+		TradeID:    tradeID,
+		ReviewerID: userID,
+		RevieweeID: revieweeID,
+		Rating:     req.Rating,
+		Comment:    comment,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAlreadyReviewed
+	}
+	s.notifyTrade(ctx, "trade_reviewed", tradeID)
+	return nil
+}
+
+func (s *MarketplaceService) GetUserReviews(
+	ctx context.Context,
+	userID string,
+	limit int64,
+	offset int64,
+) ([]repository.GetUserReviewsRow, error) {
+
+	if limit <= 0 {
+		limit = config.DefaultReviewPageSize
+	}
+	reviews, err := s.q.GetUserReviews(ctx, repository.GetUserReviewsParams{
+		UserID: userID,
+		Limit:  min(limit, config.MaxReviewPageSize),
+		Offset: max(offset, 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reviews == nil {
+		reviews = []repository.GetUserReviewsRow{}
+	}
+	return reviews, nil
+}
+
+func userReputation(ctx context.Context, q repository.Querier, userID string) (Reputation, error) {
+	row, err := q.GetUserReputation(ctx, userID)
+	if err != nil {
+		return Reputation{}, err
+	}
+	reputation := Reputation{ReviewCount: row.ReviewCount}
+	if row.ReviewCount > 0 {
+		reputation.Score = &row.Score
+	}
+	return reputation, nil
+}
+
+// cachedReputation looks up each owner's reputation once when listing many items
+func cachedReputation(ctx context.Context, q repository.Querier, cache map[string]Reputation, userID string) (Reputation, error) {
+	if reputation, ok := cache[userID]; ok {
+		return reputation, nil
+	}
+	reputation, err := userReputation(ctx, q, userID)
+	if err != nil {
+		return Reputation{}, err
+	}
+	cache[userID] = reputation
+	return reputation, nil
+}
+
+// notifyTrade tells both parties about the trade's current state, the change is already saved so failures are only logged
+func (s *MarketplaceService) notifyTrade(ctx context.Context, event string, tradeID string) {
+	trade, err := s.q.GetTradeByID(ctx, tradeID)
+	if err != nil {
+		log.Printf("Failed to load trade %s for %s: %v", tradeID, event, err)
+		return
+	}
+	details, err := s.tradeDetails(ctx, trade)
+	if err != nil {
+		log.Printf("Failed to load trade %s for %s: %v", tradeID, event, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(WebSocketMessage{
+		Event:   event,
+		Payload: details,
+	})
+	if err != nil {
+		log.Printf("Failed to encode %s: %v", event, err)
+		return
+	}
+	s.w.BroadcastMessage(ctx, []string{trade.ProposerID, trade.ResponderID}, string(jsonBytes))
+}
+
+// createTrade checks what both sides give and saves the trade with its items, returning a warning if their values are far apart
+func createTrade(ctx context.Context, q repository.Querier, tradeID string, userID string, req TradeRequest, counteredTradeID *string) (string, error) {
+	if len(req.OfferedItemIDs) > config.MaxTradeItems || len(req.RequestedItemIDs) > config.MaxTradeItems {
+		return "", ErrTooManyTradeItems
+	}
+	allItems := append(slices.Clone(req.OfferedItemIDs), req.RequestedItemIDs...)
+	slices.Sort(allItems)
+	if len(slices.Compact(allItems)) != len(req.OfferedItemIDs)+len(req.RequestedItemIDs) {
+		return "", ErrDuplicateTradeItem
+	}
+	if req.OfferedPoints < 0 || req.RequestedPoints < 0 || (req.OfferedPoints > 0 && req.RequestedPoints > 0) {
+		return "", ErrInvalidTradePoints
+	}
+	hasPoints := req.OfferedPoints > 0 || req.RequestedPoints > 0
+	if hasPoints != (req.ClubID != nil) {
+		return "", ErrTradeClubRequired
+	}
+	if (len(req.OfferedItemIDs) == 0 && req.OfferedPoints == 0) || (len(req.RequestedItemIDs) == 0 && req.RequestedPoints == 0) {
+		return "", ErrTradeEmptySide
+	}
+
+	responderID := req.ResponderID
+	var offered, requested []repository.Item
+	for _, itemID := range req.OfferedItemIDs {
+		item, err := getTradeItem(ctx, q, userID, itemID)
+		if err != nil {
+			return "", err
+		}
+		if item.OwnerID != userID {
+			return "", ErrTradeOwnItem
+		}
+		if !isTradable(item) {
+			return "", ErrTradeItemUnavailable
+		}
+		offered = append(offered, item)
+	}
+	for _, itemID := range req.RequestedItemIDs {
+		item, err := getTradeItem(ctx, q, userID, itemID)
+		if err != nil {
+			return "", err
+		}
+		if item.OwnerID == userID {
+			return "", ErrTradeOwnItem
+		}
+		if responderID == "" {
+			responderID = item.OwnerID
+		}
+		if item.OwnerID != responderID {
+			return "", ErrTradeItemOwners
+		}
+		if !isTradable(item) || reservedForOther(item, userID) {
+			return "", ErrTradeItemUnavailable
+		}
+		requested = append(requested, item)
+	}
+	if responderID == "" {
+		return "", ErrTradeNoResponder
+	}
+	if responderID == userID {
+		return "", ErrTradeOwnItem
+	}
+
+	if hasPoints {
+		for _, memberID := range []string{userID, responderID} {
+			isMember, err := q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
+				UserID: memberID,
+				ClubID: *req.ClubID,
+			})
+			if err != nil {
+				return "", err
+			}
+			if isMember == 0 {
+				return "", ErrTradeNotClubMember
+			}
+		}
+		// the responder's points are only checked when they accept
+		points, err := q.GetClubPoints(ctx, repository.GetClubPointsParams{
+			UserID: userID,
+			ClubID: *req.ClubID,
+		})
+		if err != nil {
+			return "", err
+		}
+		if points < req.OfferedPoints {
+			return "", ErrNotEnoughPoints
+		}
+	}
+
+	exists, err := q.HasPendingTrade(ctx, repository.HasPendingTradeParams{
+		ProposerID:  userID,
+		ResponderID: responderID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if exists != 0 {
+		return "", ErrTradeAlreadyProposed
+	}
+
+	if err := q.TradeCreate(ctx, repository.TradeCreateParams{
+		ID:               tradeID,
+		ProposerID:       userID,
+		ResponderID:      responderID,
+		CounteredTradeID: counteredTradeID,
+		ClubID:           req.ClubID,
+		ProposerPoints:   req.OfferedPoints,
+		ResponderPoints:  req.RequestedPoints,
+	}); err != nil {
+		return "", err
+	}
+	for _, side := range []struct {
+		ownerID string
+		itemIDs []string
+	}{
+		{userID, req.OfferedItemIDs},
+		{responderID, req.RequestedItemIDs},
+	} {
+		for _, itemID := range side.itemIDs {
+			if err := q.AddTradeItem(ctx, repository.AddTradeItemParams{
+				TradeID: tradeID,
+				ItemID:  itemID,
+				OwnerID: side.ownerID,
+			}); err != nil {
+				return "", err
+			}
+		}
+	}
+	if hasPoints {
+		// points have no price, there is nothing to compare
+		return "", nil
+	}
+	return tradeValueWarning(offered, requested), nil
+}
+
+// tradeValueWarning compares the estimated values of both sides when every item has an estimate in the same currency
+func tradeValueWarning(offered []repository.Item, requested []repository.Item) string {
+	var currency string
+	sum := func(items []repository.Item) (float64, bool) {
+		total := 0.0
+		for _, item := range items {
+			if item.PriceEstimate == nil || item.Currency == nil {
+				return 0, false
+			}
+			if currency == "" {
+				currency = *item.Currency
+			}
+			if *item.Currency != currency {
+				return 0, false
+			}
+			total += *item.PriceEstimate
+		}
+		return total, true
+	}
+	offeredValue, ok := sum(offered)
+	if !ok {
+		return ""
+	}
+	requestedValue, ok := sum(requested)
+	if !ok {
+		return ""
+	}
+
+	if max(offeredValue, requestedValue) <= config.TradeValueWarningRatio*min(offeredValue, requestedValue) {
+		return ""
+	}
+	return fmt.Sprintf("the estimated values are far apart: %.2f %s offered for %.2f %s", offeredValue, currency, requestedValue, currency)
+}
+
+// getTradeItem hides club items from users outside of the club
+func getTradeItem(ctx context.Context, q repository.Querier, userID string, itemID string) (repository.Item, error) {
+	item, err := q.GetItem(ctx, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.Item{}, ErrTradeItemNotFound
+	}
+	if err != nil {
+		return repository.Item{}, err
+	}
+	if item.ClubID == nil || item.OwnerID == userID {
+		return item, nil
+	}
+	isMember, err := q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
+		UserID: userID,
+		ClubID: *item.ClubID,
+	})
+	if err != nil {
+		return repository.Item{}, err
+	}
+	if isMember == 0 {
+		return repository.Item{}, ErrTradeItemNotFound
+	}
+	return item, nil
+}
+
+// items waiting for the club's moderators cannot be traded yet
+func isTradable(item repository.Item) bool {
+	return item.IsAvailable && item.ModerationStatus == ModerationApproved && item.ListingType == ListingTrade
+}
+
+// payClubPoints moves points between the two parties of a trade, both have to be members of the club
+func payClubPoints(ctx context.Context, q repository.Querier, clubID string, tradeID string, fromUserID string, toUserID string, points float64) error {
+	err := changeClubPoints(ctx, q, pointChange{
+		ClubID:      clubID,
+		UserID:      fromUserID,
+		Amount:      -points,
+		Reason:      PointsTrade,
+		ReferenceID: &tradeID,
+	})
+	if err == nil {
+		err = changeClubPoints(ctx, q, pointChange{
+			ClubID:      clubID,
+			UserID:      toUserID,
+			Amount:      points,
+			Reason:      PointsTrade,
+			ReferenceID: &tradeID,
+		})
+	}
+	if errors.Is(err, ErrNotClubMember) {
+		return ErrTradeNotClubMember
+	}
+	return err
+}
+
+func setTradeStatus(ctx context.Context, q repository.Querier, tradeID string, status string) error {
+	rows, err := q.UpdateTradeStatus(ctx, repository.UpdateTradeStatusParams{
+		Status: status,
+		ID:     tradeID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTradeNotPending
+	}
+	return nil
+}
+
+func checkTradeStatus(status string) error {
+	if status == "" || slices.Contains([]string{TradePending, TradeAccepted, TradeRejected, TradeCancelled, TradeCountered}, status) {
+		return nil
+	}
+	return ErrInvalidTradeStatus
 }
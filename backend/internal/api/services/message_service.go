@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+type MessageServicer interface {
+	// SendDirectMessage saves the message and sends it to both users' connections subscribed to the conversation
+	SendDirectMessage(ctx context.Context, senderID string, recipientID string, content string) (string, error)
+	// the messages the two users exchanged, oldest first
+	GetDirectMessages(ctx context.Context, userID string, otherID string) ([]repository.GetDirectMessagesRow, error)
+}
+
+type MessageService struct {
+	q repository.Querier
+	w WebSocketServicer
+	f ContentFilter
+}
+
+var _ MessageServicer = (*MessageService)(nil)
+
+func NewMessageService(q repository.Querier, w WebSocketServicer, f ContentFilter) *MessageService {
+	return &MessageService{q: q, w: w, f: f}
+}
+
+var (
+	ErrMessageToSelf        = errors.New("cannot send a message to yourself")
+	ErrMessageLength        = fmt.Errorf("content must be between 1 and %d characters", config.MaxDirectMessageLength)
+	ErrMessagingNotAllowed  = errors.New("you can only message your friends, the members of your clubs and users who wrote to you")
+	ErrConversationNotFound = errors.New("conversation not found")
+)
+
+func (s *MessageService) SendDirectMessage(ctx context.Context, senderID string, recipientID string, content string) (string, error) {
+	if senderID == recipientID {
+		return "", ErrMessageToSelf
+	}
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > config.MaxDirectMessageLength {
+		return "", ErrMessageLength
+	}
+	if _, err := s.q.GetUserDisplay(ctx, recipientID); errors.Is(err, sql.ErrNoRows) {
+		return "", ErrUserNotFound
+	} else if err != nil {
+		return "", err
+	}
+	allowed, err := mayMessage(ctx, s.q, senderID, recipientID)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		return "", ErrMessagingNotAllowed
+	}
+	if _, err := filterContent(ctx, s.f, Content{
+		Kind:     ContentKindMessage,
+		AuthorID: senderID,
+		Text:     content,
+	}); err != nil {
+		return "", err
+	}
+
+	id := util.GenerateUUID()
+	if err := s.q.CreateDirectMessage(ctx, repository.CreateDirectMessageParams{
+		ID:          id,
+		SenderID:    senderID,
+		RecipientID: recipientID,
+		Content:     content,
+	}); err != nil {
+		return "", err
+	}
+	if err := s.broadcastDirectMessage(ctx, id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// broadcastDirectMessage sends the message to both users, each subscribes to the conversation under the other's id
+func (s *MessageService) broadcastDirectMessage(ctx context.Context, messageID string) error {
+	message, err := s.q.GetDirectMessage(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	for _, side := range []struct{ userID, otherID string }{
+		{message.SenderID, message.RecipientID},
+		{message.RecipientID, message.SenderID},
+	} {
+		topic := DirectMessagesTopic(side.otherID)
+		jsonBytes, err := json.Marshal(WebSocketMessage{
+			Event:   "new_direct_message",
+			Topic:   topic,
+			Payload: message,
+		})
+		if err != nil {
+			return err
+		}
+		s.w.PublishMessage(ctx, []string{side.userID}, topic, string(jsonBytes))
+	}
+	return nil
+}
+
+func (s *MessageService) GetDirectMessages(ctx context.Context, userID string, otherID string) ([]repository.GetDirectMessagesRow, error) {
+	allowed, err := inConversation(ctx, s.q, userID, otherID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrConversationNotFound
+	}
+	return s.q.GetDirectMessages(ctx, repository.GetDirectMessagesParams{
+		UserID:  userID,
+		OtherID: otherID,
+	})
+}
+
+// mayMessage is whether the user may start a conversation with the other user or already has one
+func mayMessage(ctx context.Context, q repository.Querier, userID string, otherID string) (bool, error) {
+	friends, err := q.AreFriends(ctx, repository.AreFriendsParams{UserID: userID, OtherID: otherID})
+	if err != nil {
+		return false, err
+	}
+	if friends != 0 {
+		return true, nil
+	}
+	shared, err := q.ShareClub(ctx, repository.ShareClubParams{UserID: userID, OtherID: otherID})
+	if err != nil {
+		return false, err
+	}
+	if shared != 0 {
+		return true, nil
+	}
+	exchanged, err := q.HasDirectMessages(ctx, repository.HasDirectMessagesParams{UserID: userID, OtherID: otherID})
+	if err != nil {
+		return false, err
+	}
+	return exchanged != 0, nil
+}
+
+// inConversation is whether the user may read the conversation with the other user,
+// which is whenever they may write to each other
+func inConversation(ctx context.Context, q repository.Querier, userID string, otherID string) (bool, error) {
+	if userID == otherID {
+		return false, nil
+	}
+	return mayMessage(ctx, q, userID, otherID)
+}
@@ -2,31 +2,44 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
 	"time"
 
+	"github.com/rhellwege/task-social/config"
 	"github.com/rhellwege/task-social/internal/db/repository"
 	"github.com/rhellwege/task-social/internal/util"
 )
 
+var (
+	ErrMetricNotFound    = errors.New("metric not found")
+	ErrMetricEntryExists = errors.New("already turned in the current instance of the metric")
+)
+
 type MetricServicer interface {
 	CreateMetric(ctx context.Context, params repository.CreateMetricParams) (string, error)
 	GetMetric(ctx context.Context, metricID string) (repository.Metric, error)
 	UpdateMetric(ctx context.Context, params repository.UpdateMetricParams) error
 	DeleteMetric(ctx context.Context, metricID string) error
+	// members earn points for turning in the metric, and a streak bonus if they turned in the previous instance too
 	CreateMetricEntry(ctx context.Context, userID string, metricID string, params repository.CreateMetricEntryParams) (string, error)
 	GetLatestMetricEntries(ctx context.Context, metricID string) ([]repository.MetricEntry, error)
 	GetHistoricalMetricEntries(ctx context.Context, metricID string) ([]repository.MetricEntry, error)
 }
 
 type MetricService struct {
-	q repository.Querier
-	c ClubServicer
+	q  repository.Querier
+	c  ClubServicer
+	tx Transactor
+	w  WebSocketServicer
 }
 
 var _ MetricServicer = (*MetricService)(nil)
 
-func NewMetricService(q repository.Querier, c ClubServicer) *MetricService {
-	return &MetricService{q: q, c: c}
+func NewMetricService(q repository.Querier, c ClubServicer, tx Transactor, w WebSocketServicer) *MetricService {
+	return &MetricService{q: q, c: c, tx: tx, w: w}
 }
 
 func (s *MetricService) CreateMetric(ctx context.Context, params repository.CreateMetricParams) (string, error) {
@@ -54,7 +67,28 @@ func (s *MetricService) DeleteMetric(ctx context.Context, metricID string) error
 }
 
 func (s *MetricService) CreateMetricEntry(ctx context.Context, userID string, metricID string, params repository.CreateMetricEntryParams) (string, error) {
+	metric, err := s.q.GetMetric(ctx, metricID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrMetricNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	isMember, err := s.q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
+		UserID: userID,
+		ClubID: metric.ClubID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if isMember == 0 {
+		return "", ErrNotClubMember
+	}
+
 	instance, err := s.q.GetLatestMetricInstance(ctx, metricID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrMetricNotFound
+	}
 	if err != nil {
 		return "", err
 	}
@@ -62,14 +96,116 @@ func (s *MetricService) CreateMetricEntry(ctx context.Context, userID string, me
 	params.UserID = userID
 	params.MetricInstanceID = instance.ID
 
-	err = s.q.CreateMetricEntry(ctx, params)
+	err = s.tx.WithTx(ctx, func(q repository.Querier) error {
+		entered, err := q.HasMetricEntry(ctx, repository.HasMetricEntryParams{
+			UserID:           userID,
+			MetricInstanceID: instance.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if entered != 0 {
+			return ErrMetricEntryExists
+		}
+		if err := q.CreateMetricEntry(ctx, params); err != nil {
+			return err
+		}
+		return awardMetricEntry(ctx, q, metric.ClubID, userID, instance)
+	})
 	if err != nil {
 		return "", err
 	}
 
+	s.notifyMetricEntry(ctx, metric, MetricEntryEvent{
+		MetricID:         metricID,
+		MetricInstanceID: instance.ID,
+		UserID:           userID,
+		Value:            params.Value,
+	})
 	return instance.ID, nil
 }
 
+// MetricEntryEvent is the payload of new_metric_entry
+type MetricEntryEvent struct {
+	MetricID         string  `json:"metric_id"`
+	MetricInstanceID string  `json:"metric_instance_id"`
+	UserID           string  `json:"user_id"`
+	Value            float64 `json:"value"`
+}
+
+// notifyMetricEntry tells the members of the metric's club subscribed to it, the entry is already saved so failures are only logged
+func (s *MetricService) notifyMetricEntry(ctx context.Context, metric repository.Metric, entry MetricEntryEvent) {
+	members, err := s.q.GetClubUserIds(ctx, metric.ClubID)
+	if err != nil {
+		log.Printf("Failed to load the members of club %s for new_metric_entry: %v", metric.ClubID, err)
+		return
+	}
+	jsonBytes, err := json.Marshal(WebSocketMessage{
+		Event:   "new_metric_entry",
+		Topic:   MetricTopic(metric.ID),
+		Payload: entry,
+	})
+	if err != nil {
+		log.Printf("Failed to encode new_metric_entry: %v", err)
+		return
+	}
+	s.w.PublishMessage(ctx, members, MetricTopic(metric.ID), string(jsonBytes))
+}
+
+// awardMetricEntry credits the points for an entry and continues the member's streak in the club
+// if they turned in the previous instance of the metric, otherwise the streak starts over
+func awardMetricEntry(ctx context.Context, q repository.Querier, clubID string, userID string, instance repository.MetricInstance) error {
+	err := changeClubPoints(ctx, q, pointChange{
+		ClubID:      clubID,
+		UserID:      userID,
+		Amount:      config.MetricEntryPoints,
+		Reason:      PointsMetricEntry,
+		ReferenceID: &instance.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	continued, err := q.HasEntryInPreviousMetricInstance(ctx, repository.HasEntryInPreviousMetricInstanceParams{
+		UserID:           userID,
+		MetricID:         instance.MetricID,
+		MetricInstanceID: instance.ID,
+	})
+	if err != nil {
+		return err
+	}
+	membership, err := q.GetClubMembership(ctx, repository.GetClubMembershipParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+	if err != nil {
+		return err
+	}
+	streak := int64(1)
+	if continued != 0 {
+		streak = membership.UserStreak + 1
+	}
+	err = q.UpdateClubMembership(ctx, repository.UpdateClubMembershipParams{
+		UserStreak: &streak,
+		UserID:     userID,
+		ClubID:     clubID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if continued == 0 {
+		return nil
+	}
+	return changeClubPoints(ctx, q, pointChange{
+		ClubID:      clubID,
+		UserID:      userID,
+		Amount:      config.StreakBonusPoints,
+		Reason:      PointsStreakBonus,
+		ReferenceID: &instance.ID,
+	})
+}
+
 func (s *MetricService) GetLatestMetricEntries(ctx context.Context, metricID string) ([]repository.MetricEntry, error) {
 	instance, err := s.q.GetLatestMetricInstance(ctx, metricID)
 	if err != nil {
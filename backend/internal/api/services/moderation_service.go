@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+)
+
+// ModerationServicer lets club moderators manage their club's blocklist and review what the content filter held
+type ModerationServicer interface {
+	GetBlockedTerms(ctx context.Context, userID string, clubID string) ([]repository.ClubBlockedTerm, error)
+	// adds the term or changes its action
+	SetBlockedTerm(ctx context.Context, userID string, clubID string, req BlockedTermRequest) error
+	RemoveBlockedTerm(ctx context.Context, userID string, clubID string, term string) error
+	GetHeldContent(ctx context.Context, userID string, clubID string) (HeldContent, error)
+	// approved posts are sent to the club's members like new ones
+	ApprovePost(ctx context.Context, userID string, clubID string, postID string) error
+	RejectPost(ctx context.Context, userID string, clubID string, postID string) error
+	ApproveItem(ctx context.Context, userID string, clubID string, itemID string) error
+	RejectItem(ctx context.Context, userID string, clubID string, itemID string) error
+}
+
+type ModerationService struct {
+	q repository.Querier
+	i ImageServicer
+	w WebSocketServicer
+}
+
+var _ ModerationServicer = (*ModerationService)(nil)
+
+func NewModerationService(q repository.Querier, i ImageServicer, w WebSocketServicer) *ModerationService {
+	return &ModerationService{q: q, i: i, w: w}
+}
+
+var (
+	ErrNotClubModerator         = errors.New("only the club's moderators can do this")
+	ErrInvalidBlockedTerm       = fmt.Errorf("term must contain letters or digits and be at most %d characters", config.MaxBlockedTermLength)
+	ErrInvalidBlockedTermAction = fmt.Errorf("action must be %s or %s", FilterReject, FilterHold)
+	ErrTooManyBlockedTerms      = fmt.Errorf("a club can block at most %d terms", config.MaxClubBlockedTerms)
+	ErrBlockedTermNotFound      = errors.New("term is not blocked")
+	ErrHeldContentNotFound      = errors.New("no held content with this id in the club")
+)
+
+type BlockedTermRequest struct {
+	// a word or phrase, matched case-insensitively against whole words
+	Term string `json:"term"`
+	// reject or hold
+	Action string `json:"action"`
+}
+
+type HeldContent struct {
+	Posts []repository.GetHeldClubPostsRow `json:"posts"`
+	Items []repository.GetHeldClubItemsRow `json:"items"`
+}
+
+func (s *ModerationService) GetBlockedTerms(ctx context.Context, userID string, clubID string) ([]repository.ClubBlockedTerm, error) {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return nil, err
+	}
+	return s.q.GetClubBlockedTerms(ctx, clubID)
+}
+
+func (s *ModerationService) SetBlockedTerm(ctx context.Context, userID string, clubID string, req BlockedTermRequest) error {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return err
+	}
+	term := NormalizeTerm(req.Term)
+	if term == "" || utf8.RuneCountInString(term) > config.MaxBlockedTermLength {
+		return ErrInvalidBlockedTerm
+	}
+	if req.Action != FilterReject && req.Action != FilterHold {
+		return ErrInvalidBlockedTermAction
+	}
+
+	terms, err := s.q.GetClubBlockedTerms(ctx, clubID)
+	if err != nil {
+		return err
+	}
+	exists := slices.ContainsFunc(terms, func(t repository.ClubBlockedTerm) bool { return t.Term == term })
+	if !exists && len(terms) >= config.MaxClubBlockedTerms {
+		return ErrTooManyBlockedTerms
+	}
+
+	return s.q.UpsertClubBlockedTerm(ctx, repository.UpsertClubBlockedTermParams{
+		ClubID: clubID,
+		Term:   term,
+		Action: req.Action,
+	})
+}
+
+func (s *ModerationService) RemoveBlockedTerm(ctx context.Context, userID string, clubID string, term string) error {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return err
+	}
+	rows, err := s.q.DeleteClubBlockedTerm(ctx, repository.DeleteClubBlockedTermParams{
+		ClubID: clubID,
+		Term:   NormalizeTerm(term),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrBlockedTermNotFound
+	}
+	return nil
+}
+
+func (s *ModerationService) GetHeldContent(ctx context.Context, userID string, clubID string) (HeldContent, error) {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return HeldContent{}, err
+	}
+	posts, err := s.q.GetHeldClubPosts(ctx, clubID)
+	if err != nil {
+		return HeldContent{}, err
+	}
+	items, err := s.q.GetHeldClubItems(ctx, &clubID)
+	if err != nil {
+		return HeldContent{}, err
+	}
+	return HeldContent{Posts: posts, Items: items}, nil
+}
+
+func (s *ModerationService) ApprovePost(ctx context.Context, userID string, clubID string, postID string) error {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return err
+	}
+	rows, err := s.q.ApproveClubPost(ctx, repository.ApproveClubPostParams{
+		ID:     postID,
+		ClubID: clubID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrHeldContentNotFound
+	}
+	return broadcastNewPost(ctx, s.q, s.w, postID)
+}
+
+func (s *ModerationService) RejectPost(ctx context.Context, userID string, clubID string, postID string) error {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return err
+	}
+	post, err := s.q.GetClubPost(ctx, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrHeldContentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if post.ClubID != clubID || post.ModerationStatus != ModerationPending {
+		return ErrHeldContentNotFound
+	}
+	return s.q.DeleteClubPost(ctx, postID)
+}
+
+func (s *ModerationService) ApproveItem(ctx context.Context, userID string, clubID string, itemID string) error {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return err
+	}
+	rows, err := s.q.ApproveItem(ctx, repository.ApproveItemParams{
+		ID:     itemID,
+		ClubID: &clubID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrHeldContentNotFound
+	}
+	notifySavedSearches(ctx, s.q, s.w, itemID)
+	return nil
+}
+
+func (s *ModerationService) RejectItem(ctx context.Context, userID string, clubID string, itemID string) error {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return err
+	}
+	item, err := s.q.GetItem(ctx, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrHeldContentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if item.ClubID == nil || *item.ClubID != clubID || item.ModerationStatus != ModerationPending {
+		return ErrHeldContentNotFound
+	}
+	return deleteItem(ctx, s.q, s.i, itemID)
+}
+
+func (s *ModerationService) checkModerator(ctx context.Context, userID string, clubID string) error {
+	isModerator, err := s.q.IsUserModeratorOfClub(ctx, repository.IsUserModeratorOfClubParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+	if err != nil {
+		return err
+	}
+	if isModerator == 0 {
+		return ErrNotClubModerator
+	}
+	return nil
+}
@@ -0,0 +1,557 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+type OIDCServicer interface {
+	// names of the configured identity providers
+	GetProviders(ctx context.Context) []string
+	// starts an authorization code flow with PKCE and returns the URL to send the user to
+	// and the verifier the app must keep for the callback
+	AuthorizationURL(ctx context.Context, provider string) (OIDCAuthorization, error)
+	// finishes the flow with the code and state the provider redirected back with and the verifier from AuthorizationURL.
+	// the account is created on first login and linked to the external identity
+	Login(ctx context.Context, provider string, code string, state string, codeVerifier string, client ClientInfo) (LoginResult, error)
+	GetIdentities(ctx context.Context, userID string) ([]repository.GetUserIdentitiesRow, error)
+}
+
+type OIDCService struct {
+	q      repository.Querier
+	u      UserServicer
+	f      ContentFilter
+	client *http.Client
+
+	mu          sync.Mutex
+	metadata    map[string]*oidcMetadata // by issuer
+	refreshedAt map[string]time.Time     // last time the metadata of an issuer was fetched again for an unknown key
+}
+
+var _ OIDCServicer = (*OIDCService)(nil)
+
+func NewOIDCService(q repository.Querier, u UserServicer, f ContentFilter) *OIDCService {
+	return &OIDCService{
+		q:           q,
+		u:           u,
+		f:           f,
+		client:      &http.Client{Timeout: config.OIDCHTTPTimeout},
+		metadata:    make(map[string]*oidcMetadata),
+		refreshedAt: make(map[string]time.Time),
+	}
+}
+
+var (
+	ErrUnknownOIDCProvider = errors.New("unknown identity provider")
+	ErrInvalidOIDCState    = errors.New("invalid or expired login attempt, please start again")
+	ErrOIDCEmailTaken      = errors.New("an account with this email already exists, log in with your password instead")
+)
+
+// OIDCAuthorization starts a login. Only the app that started it knows the verifier, the server keeps its hash,
+// so a code and state taken from the redirect cannot be redeemed by anyone else
+type OIDCAuthorization struct {
+	URL          string
+	CodeVerifier string
+}
+
+// discovery document, only the fields we use
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	keys map[string]any // signing keys by kid
+}
+
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type oidcClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"` // some providers send "true" as a string
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+}
+
+func (c oidcClaims) emailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+func (s *OIDCService) GetProviders(ctx context.Context) []string {
+	providers := make([]string, 0, len(config.OIDCProviders))
+	for name := range config.OIDCProviders {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+func (s *OIDCService) AuthorizationURL(ctx context.Context, provider string) (OIDCAuthorization, error) {
+	p, ok := config.OIDCProviders[provider]
+	if !ok {
+		return OIDCAuthorization{}, ErrUnknownOIDCProvider
+	}
+	meta, err := s.getMetadata(ctx, p.Issuer, false)
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+
+	state, err := util.GenerateSecureToken(32)
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+	nonce, err := util.GenerateSecureToken(32)
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+	verifier, err := util.GenerateSecureToken(32)
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+
+	err = s.q.CreateOIDCLoginState(ctx, repository.CreateOIDCLoginStateParams{
+		State:            state,
+		Provider:         provider,
+		CodeVerifierHash: util.HashToken(verifier),
+		Nonce:            nonce,
+		ExpiresAt:        time.Now().Add(config.OIDCLoginStateDuration),
+	})
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+
+	authURL, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	params := authURL.Query()
+	params.Set("response_type", "code")
+	params.Set("client_id", p.ClientID)
+	params.Set("redirect_uri", p.RedirectURL)
+	params.Set("scope", "openid email profile")
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+	authURL.RawQuery = params.Encode()
+	return OIDCAuthorization{URL: authURL.String(), CodeVerifier: verifier}, nil
+}
+
+func (s *OIDCService) Login(ctx context.Context, provider string, code string, state string, codeVerifier string, client ClientInfo) (LoginResult, error) {
+	p, ok := config.OIDCProviders[provider]
+	if !ok {
+		return LoginResult{}, ErrUnknownOIDCProvider
+	}
+
+	loginState, err := s.q.ConsumeOIDCLoginState(ctx, repository.ConsumeOIDCLoginStateParams{
+		State:            state,
+		Provider:         provider,
+		CodeVerifierHash: util.HashToken(codeVerifier),
+	})
+	if err != nil || loginState.ExpiresAt.Before(time.Now()) {
+		return LoginResult{}, ErrInvalidOIDCState
+	}
+
+	meta, err := s.getMetadata(ctx, p.Issuer, false)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	idToken, err := s.exchangeCode(ctx, p, meta, code, codeVerifier)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	claims, err := s.verifyIDToken(ctx, p, idToken)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	if claims.Nonce != loginState.Nonce {
+		return LoginResult{}, errors.New("id token nonce does not match the login attempt")
+	}
+
+	userID, err := s.findOrCreateUser(ctx, provider, claims)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	return s.u.LoginUserByID(ctx, userID, client)
+}
+
+func (s *OIDCService) GetIdentities(ctx context.Context, userID string) ([]repository.GetUserIdentitiesRow, error) {
+	return s.q.GetUserIdentities(ctx, userID)
+}
+
+// findOrCreateUser returns the user linked to the identity. On first login an existing account is linked
+// if both the provider and the account verified the email address, otherwise a new account is created.
+func (s *OIDCService) findOrCreateUser(ctx context.Context, provider string, claims *oidcClaims) (string, error) {
+	identity, err := s.q.GetUserIdentity(ctx, repository.GetUserIdentityParams{
+		Provider: provider,
+		Subject:  claims.Subject,
+	})
+	if err == nil {
+		return identity.UserID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	if claims.Email == "" {
+		return "", errors.New("identity provider did not share an email address")
+	}
+
+	userID, err := s.q.GetUserIDByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		// linking is only safe when both sides confirmed the owner of the address, otherwise whoever
+		// registered it first with a password would share the account with the provider's user
+		if !claims.emailVerified() {
+			return "", ErrOIDCEmailTaken
+		}
+		verified, err := s.u.IsEmailVerified(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		if !verified {
+			return "", ErrOIDCEmailTaken
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		userID, err = s.createUser(ctx, claims)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", err
+	}
+
+	var email *string
+	if claims.Email != "" {
+		email = &claims.Email
+	}
+	err = s.q.CreateUserIdentity(ctx, repository.CreateUserIdentityParams{
+		Provider: provider,
+		Subject:  claims.Subject,
+		UserID:   userID,
+		Email:    email,
+	})
+	if err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+func (s *OIDCService) createUser(ctx context.Context, claims *oidcClaims) (string, error) {
+	username, err := s.availableUsername(ctx, claims)
+	if err != nil {
+		return "", err
+	}
+
+	userID := util.GenerateUUID()
+	err = s.q.CreateUser(ctx, repository.CreateUserParams{
+		ID:       userID,
+		Email:    claims.Email,
+		Username: username,
+		// no password can match an empty hash, one can be set later with a password reset
+		Password: "",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if claims.emailVerified() {
+		if err := s.q.SetUserEmailVerified(ctx, userID); err != nil {
+			return "", err
+		}
+	}
+	return userID, nil
+}
+
+// availableUsername derives a username from the profile claims, adding digits if it is taken.
+// claims the content filter rejects are skipped like empty ones, registering would not allow them either
+func (s *OIDCService) availableUsername(ctx context.Context, claims *oidcClaims) (string, error) {
+	local, _, _ := strings.Cut(claims.Email, "@")
+	base := "user"
+	for _, claim := range []string{claims.PreferredUsername, local, claims.Name} {
+		name := sanitizeUsername(claim)
+		if name == "" {
+			continue
+		}
+		_, err := filterContent(ctx, s.f, Content{Kind: ContentKindUsername, Text: name})
+		if errors.Is(err, ErrContentRejected) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		base = name
+		break
+	}
+
+	candidate := base
+	for range 10 {
+		exists, err := s.q.UsernameExists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if exists == 0 {
+			return candidate, nil
+		}
+		suffix := fmt.Sprintf("%d", rand.IntN(10000))
+		candidate = base[:min(len(base), config.OIDCMaxUsernameLength-len(suffix))] + suffix
+	}
+	return "", errors.New("could not find an available username")
+}
+
+func sanitizeUsername(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('_')
+		}
+		if b.Len() >= config.OIDCMaxUsernameLength {
+			break
+		}
+	}
+	return b.String()
+}
+
+func (s *OIDCService) exchangeCode(ctx context.Context, p config.OIDCProvider, meta *oidcMetadata, code string, verifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("client_id", p.ClientID)
+	form.Set("code_verifier", verifier)
+	if p.ClientSecret != "" {
+		form.Set("client_secret", p.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body oidcTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid token response from identity provider: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("identity provider rejected the login: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("identity provider did not return an id token")
+	}
+	return body.IDToken, nil
+}
+
+func (s *OIDCService) verifyIDToken(ctx context.Context, p config.OIDCProvider, idToken string) (*oidcClaims, error) {
+	claims := &oidcClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.signingKey(ctx, p.Issuer, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(p.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid id token: missing subject")
+	}
+	return claims, nil
+}
+
+// signingKey looks up a key by id, refreshing the key set once in case the provider rotated its keys.
+// refreshes are limited per issuer, so tokens with made up key ids cannot make every login fetch the keys
+func (s *OIDCService) signingKey(ctx context.Context, issuer string, kid string) (any, error) {
+	for _, refresh := range []bool{false, true} {
+		meta, err := s.getMetadata(ctx, issuer, refresh)
+		if err != nil {
+			return nil, err
+		}
+		if key, ok := meta.keys[kid]; ok {
+			return key, nil
+		}
+		// a single key without an id may be used for tokens without a kid header
+		if kid == "" && len(meta.keys) == 1 {
+			for _, key := range meta.keys {
+				return key, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// getMetadata returns the cached discovery document and keys of an issuer, fetching them if needed
+func (s *OIDCService) getMetadata(ctx context.Context, issuer string, refresh bool) (*oidcMetadata, error) {
+	s.mu.Lock()
+	meta, ok := s.metadata[issuer]
+	if ok && refresh && time.Since(s.refreshedAt[issuer]) < config.OIDCKeyRefreshInterval {
+		refresh = false
+	}
+	if ok && refresh {
+		s.refreshedAt[issuer] = time.Now()
+	}
+	s.mu.Unlock()
+	if ok && !refresh {
+		return meta, nil
+	}
+
+	meta = &oidcMetadata{}
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if err := s.getJSON(ctx, discoveryURL, meta); err != nil {
+		return nil, fmt.Errorf("could not load identity provider configuration: %w", err)
+	}
+	if meta.Issuer != issuer {
+		return nil, fmt.Errorf("identity provider issuer %q does not match configured issuer %q", meta.Issuer, issuer)
+	}
+
+	var jwks JWKS
+	if err := s.getJSON(ctx, meta.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("could not load identity provider keys: %w", err)
+	}
+	meta.keys = make(map[string]any, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// skip key types we do not support instead of failing the whole set
+			continue
+		}
+		meta.keys[k.Kid] = key
+	}
+
+	s.mu.Lock()
+	s.metadata[issuer] = meta
+	s.mu.Unlock()
+	return meta, nil
+}
+
+func (s *OIDCService) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// JSONWebKey is a public key in a JWKS document (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+func (k JSONWebKey) publicKey() (any, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func CleanUpExpiredOIDCLoginStates(ctx context.Context, q repository.Querier) error {
+	return q.DeleteExpiredOIDCLoginStates(ctx, time.Now())
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+type PersonalTokenServicer interface {
+	// returns the token in plain text, it cannot be retrieved again
+	CreateToken(ctx context.Context, userID string, name string, scopes []string, duration time.Duration) (PersonalAccessToken, string, error)
+	GetTokens(ctx context.Context, userID string) ([]PersonalAccessToken, error)
+	RevokeToken(ctx context.Context, userID string, tokenID string) error
+	// returns the owner and scopes of a usable token
+	ValidateToken(ctx context.Context, token string) (string, []string, error)
+}
+
+type PersonalTokenService struct {
+	q repository.Querier
+}
+
+var _ PersonalTokenServicer = (*PersonalTokenService)(nil)
+
+func NewPersonalTokenService(q repository.Querier) *PersonalTokenService {
+	return &PersonalTokenService{q: q}
+}
+
+// TokenScopes are the scopes a personal access token can be granted.
+// a write scope includes reading the same resource
+var TokenScopes = []string{
+	"profile:read",
+	"clubs:read",
+	"clubs:write",
+	"metrics:read",
+	"metrics:write",
+	"marketplace:read",
+	"marketplace:write",
+}
+
+type PersonalAccessToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+var ErrInvalidPersonalToken = errors.New("invalid, expired or revoked access token")
+
+// IsPersonalToken tells personal access tokens apart from JWTs
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, config.PersonalTokenPrefix)
+}
+
+// HasScope reports whether the granted scopes allow the wanted one
+func HasScope(granted []string, want string) bool {
+	if slices.Contains(granted, want) {
+		return true
+	}
+	resource, access, _ := strings.Cut(want, ":")
+	return access == "read" && slices.Contains(granted, resource+":write")
+}
+
+func (s *PersonalTokenService) CreateToken(ctx context.Context, userID string, name string, scopes []string, duration time.Duration) (PersonalAccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return PersonalAccessToken{}, "", errors.New("token name is required")
+	}
+	if len(scopes) == 0 {
+		return PersonalAccessToken{}, "", errors.New("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(TokenScopes, scope) {
+			return PersonalAccessToken{}, "", fmt.Errorf("unknown scope %q, valid scopes are %s", scope, strings.Join(TokenScopes, ", "))
+		}
+	}
+	if duration == 0 {
+		duration = config.DefaultPersonalTokenDuration
+	}
+	if duration < 24*time.Hour || duration > config.MaxPersonalTokenDuration {
+		return PersonalAccessToken{}, "", fmt.Errorf("token lifetime must be between 1 and %d days", int(config.MaxPersonalTokenDuration.Hours()/24))
+	}
+
+	secret, err := util.GenerateSecureToken(config.PersonalTokenBytes)
+	if err != nil {
+		return PersonalAccessToken{}, "", err
+	}
+	token := config.PersonalTokenPrefix + secret
+
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+	pat := PersonalAccessToken{
+		ID:        util.GenerateUUID(),
+		Name:      name,
+		Scopes:    scopes,
+		ExpiresAt: time.Now().Add(duration),
+		CreatedAt: time.Now(),
+	}
+	err = s.q.CreatePersonalAccessToken(ctx, repository.CreatePersonalAccessTokenParams{
+		ID:        pat.ID,
+		UserID:    userID,
+		Name:      pat.Name,
+		TokenHash: util.HashToken(token),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: pat.ExpiresAt,
+	})
+	if err != nil {
+		return PersonalAccessToken{}, "", err
+	}
+	return pat, token, nil
+}
+
+func (s *PersonalTokenService) GetTokens(ctx context.Context, userID string) ([]PersonalAccessToken, error) {
+	rows, err := s.q.GetUserPersonalAccessTokens(ctx, repository.GetUserPersonalAccessTokensParams{
+		UserID: userID,
+		Now:    time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]PersonalAccessToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, PersonalAccessToken{
+			ID:         row.ID,
+			Name:       row.Name,
+			Scopes:     strings.Fields(row.Scopes),
+			ExpiresAt:  row.ExpiresAt,
+			LastUsedAt: row.LastUsedAt,
+			CreatedAt:  row.CreatedAt,
+		})
+	}
+	return tokens, nil
+}
+
+func (s *PersonalTokenService) RevokeToken(ctx context.Context, userID string, tokenID string) error {
+	rows, err := s.q.RevokePersonalAccessToken(ctx, repository.RevokePersonalAccessTokenParams{
+		ID:     tokenID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("access token not found")
+	}
+	return nil
+}
+
+func (s *PersonalTokenService) ValidateToken(ctx context.Context, token string) (string, []string, error) {
+	row, err := s.q.GetPersonalAccessTokenByHash(ctx, util.HashToken(token))
+	if err != nil {
+		return "", nil, ErrInvalidPersonalToken
+	}
+	if row.RevokedAt != nil || row.ExpiresAt.Before(time.Now()) {
+		return "", nil, ErrInvalidPersonalToken
+	}
+
+	// same as sessions, last used only needs to be roughly accurate
+	if row.LastUsedAt == nil || time.Since(*row.LastUsedAt) > config.SessionLastSeenInterval {
+		if err := s.q.TouchPersonalAccessToken(ctx, row.ID); err != nil {
+			return "", nil, err
+		}
+	}
+	return row.UserID, strings.Fields(row.Scopes), nil
+}
+
+func CleanUpExpiredPersonalAccessTokens(ctx context.Context, q repository.Querier) error {
+	return q.DeleteExpiredPersonalAccessTokens(ctx, time.Now())
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+// why a member's points changed, recorded with every entry of the points ledger
+const (
+	PointsMetricEntry = "metric_entry"
+	PointsStreakBonus = "streak_bonus"
+	PointsTrade       = "trade"
+	PointsRedemption  = "redemption"
+	PointsRefund      = "refund"
+	PointsAdjustment  = "adjustment"
+	PointsLeftClub    = "left_club"
+	// the points a member had before the ledger existed, written once by a database migration
+	PointsOpeningBalance = "opening_balance"
+)
+
+var (
+	ErrNotClubMember          = errors.New("not a member of the club")
+	ErrInvalidPointAdjustment = errors.New("the adjustment must add or take away points")
+	ErrPointNoteLength        = fmt.Errorf("note must be between 1 and %d characters", config.MaxPointNoteLength)
+	ErrSelfPointAdjustment    = errors.New("moderators cannot adjust their own points")
+)
+
+type PointsServicer interface {
+	// members can see their own history, moderators everyone's
+	GetPointHistory(ctx context.Context, userID string, clubID string, memberID string, limit int64, offset int64) (PointHistory, error)
+	// only for moderators and not for their own points, a negative amount takes points away
+	AdjustPoints(ctx context.Context, userID string, clubID string, memberID string, req AdjustPointsRequest) error
+}
+
+type PointsService struct {
+	q  repository.Querier
+	tx Transactor
+}
+
+var _ PointsServicer = (*PointsService)(nil)
+
+func NewPointsService(q repository.Querier, tx Transactor) *PointsService {
+	return &PointsService{q: q, tx: tx}
+}
+
+type PointHistory struct {
+	Points  float64                         `json:"points"`
+	Streak  int64                           `json:"streak"`
+	Entries []repository.GetPointHistoryRow `json:"entries"`
+}
+
+type AdjustPointsRequest struct {
+	Amount float64 `json:"amount"`
+	// why, shown to the member in their history
+	Note string `json:"note"`
+}
+
+func (s *PointsService) GetPointHistory(
+	ctx context.Context,
+	userID string,
+	clubID string,
+	memberID string,
+	limit int64,
+	offset int64,
+) (PointHistory, error) {
+
+	if userID != memberID {
+		isModerator, err := s.q.IsUserModeratorOfClub(ctx, repository.IsUserModeratorOfClubParams{
+			UserID: userID,
+			ClubID: clubID,
+		})
+		if err != nil {
+			return PointHistory{}, err
+		}
+		if isModerator == 0 {
+			return PointHistory{}, ErrNotClubModerator
+		}
+	}
+
+	membership, err := s.q.GetClubMembership(ctx, repository.GetClubMembershipParams{
+		UserID: memberID,
+		ClubID: clubID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return PointHistory{}, ErrNotClubMember
+	}
+	if err != nil {
+		return PointHistory{}, err
+	}
+
+	if limit <= 0 {
+		limit = config.DefaultPointHistoryPageSize
+	}
+	entries, err := s.q.GetPointHistory(ctx, repository.GetPointHistoryParams{
+		ClubID: clubID,
+		UserID: memberID,
+		Limit:  min(limit, config.MaxPointHistoryPageSize),
+		Offset: max(offset, 0),
+	})
+	if err != nil {
+		return PointHistory{}, err
+	}
+	if entries == nil {
+		entries = []repository.GetPointHistoryRow{}
+	}
+	return PointHistory{
+		Points:  membership.UserPoints,
+		Streak:  membership.UserStreak,
+		Entries: entries,
+	}, nil
+}
+
+func (s *PointsService) AdjustPoints(
+	ctx context.Context,
+	userID string,
+	clubID string,
+	memberID string,
+	req AdjustPointsRequest,
+) error {
+
+	if req.Amount == 0 {
+		return ErrInvalidPointAdjustment
+	}
+	if len(req.Note) == 0 || len(req.Note) > config.MaxPointNoteLength {
+		return ErrPointNoteLength
+	}
+	// the points could be spent in trades and the reward shop
+	if memberID == userID {
+		return ErrSelfPointAdjustment
+	}
+	isModerator, err := s.q.IsUserModeratorOfClub(ctx, repository.IsUserModeratorOfClubParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+	if err != nil {
+		return err
+	}
+	if isModerator == 0 {
+		return ErrNotClubModerator
+	}
+
+	return s.tx.WithTx(ctx, func(q repository.Querier) error {
+		return changeClubPoints(ctx, q, pointChange{
+			ClubID:  clubID,
+			UserID:  memberID,
+			Amount:  req.Amount,
+			Reason:  PointsAdjustment,
+			ActorID: &userID,
+			Note:    &req.Note,
+		})
+	})
+}
+
+// RebuildPointBalances sets every member's points to the sum of their entries in the points ledger,
+// returning how many balances had drifted
+func RebuildPointBalances(ctx context.Context, q repository.Querier) (int64, error) {
+	return q.RebuildPointBalances(ctx)
+}
+
+// pointChange is one entry of the points ledger
+type pointChange struct {
+	ClubID string
+	UserID string
+	// negative when points are spent
+	Amount float64
+	Reason string
+	// the metric instance, trade or redemption the points are for
+	ReferenceID *string
+	// the moderator who made an adjustment
+	ActorID *string
+	Note    *string
+}
+
+// changeClubPoints adds the change's amount to the member's points, a negative amount spends them, and records
+// it with the new balance in the points ledger. spending more than the member has fails with ErrNotEnoughPoints.
+// use it inside a transaction so the balance and the ledger cannot disagree
+func changeClubPoints(ctx context.Context, q repository.Querier, change pointChange) error {
+	var rows int64
+	var err error
+	if change.Amount < 0 {
+		rows, err = q.SpendClubPoints(ctx, repository.SpendClubPointsParams{
+			Points: -change.Amount,
+			UserID: change.UserID,
+			ClubID: change.ClubID,
+		})
+	} else {
+		rows, err = q.AddClubPoints(ctx, repository.AddClubPointsParams{
+			Points: change.Amount,
+			UserID: change.UserID,
+			ClubID: change.ClubID,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		isMember, err := q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
+			UserID: change.UserID,
+			ClubID: change.ClubID,
+		})
+		if err != nil {
+			return err
+		}
+		if isMember == 0 {
+			return ErrNotClubMember
+		}
+		return ErrNotEnoughPoints
+	}
+
+	balance, err := q.GetClubPoints(ctx, repository.GetClubPointsParams{
+		UserID: change.UserID,
+		ClubID: change.ClubID,
+	})
+	if err != nil {
+		return err
+	}
+	return q.CreatePointLedgerEntry(ctx, repository.CreatePointLedgerEntryParams{
+		ID:          util.GenerateUUID(),
+		ClubID:      change.ClubID,
+		UserID:      change.UserID,
+		Amount:      change.Amount,
+		Balance:     balance,
+		Reason:      change.Reason,
+		ReferenceID: change.ReferenceID,
+		ActorID:     change.ActorID,
+		Note:        change.Note,
+	})
+}
+
+// removeClubMember ends the user's membership, their points are forfeited with a closing ledger entry
+// so that the ledger still adds up to the balance if they join again. use it inside a transaction
+func removeClubMember(ctx context.Context, q repository.Querier, clubID string, userID string) error {
+	balance, err := q.GetClubPoints(ctx, repository.GetClubPointsParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if balance != 0 {
+		err := changeClubPoints(ctx, q, pointChange{
+			ClubID: clubID,
+			UserID: userID,
+			Amount: -balance,
+			Reason: PointsLeftClub,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return q.DeleteClubMembership(ctx, repository.DeleteClubMembershipParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+)
+
+type ProfileServicer interface {
+	// the profile as the viewer may see it, sections the owner hides are left out
+	GetProfile(ctx context.Context, viewerID string, userID string) (UserProfile, error)
+	// nil fields are left unchanged, links replace the existing ones when set
+	UpdateProfile(ctx context.Context, userID string, update ProfileUpdate) error
+}
+
+type ProfileService struct {
+	q repository.Querier
+}
+
+var _ ProfileServicer = (*ProfileService)(nil)
+
+func NewProfileService(q repository.Querier) *ProfileService {
+	return &ProfileService{q: q}
+}
+
+const (
+	ProfileVisibilityPublic  = "public"
+	ProfileVisibilityFriends = "friends"
+	ProfileVisibilityPrivate = "private"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+type ProfileLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+type ProfilePrivacy struct {
+	ProfileVisibility string `json:"profile_visibility"`
+	ShowClubs         bool   `json:"show_clubs"`
+	ShowStats         bool   `json:"show_stats"`
+	ShowItems         bool   `json:"show_items"`
+}
+
+type UserProfile struct {
+	ID             string    `json:"id"`
+	Username       string    `json:"username"`
+	ProfilePicture *string   `json:"profile_picture"`
+	CreatedAt      time.Time `json:"created_at"`
+	// from the reviews of the user's trades
+	Reputation Reputation `json:"reputation"`
+	// false when the owner hides the profile from the viewer, only the fields above are set then
+	Visible     bool                               `json:"visible"`
+	DisplayName *string                            `json:"display_name,omitempty"`
+	Bio         *string                            `json:"bio,omitempty"`
+	Location    *string                            `json:"location,omitempty"`
+	Pronouns    *string                            `json:"pronouns,omitempty"`
+	Links       []ProfileLink                      `json:"links,omitempty"`
+	Clubs       []repository.GetUserPublicClubsRow `json:"clubs,omitempty"`
+	Stats       *repository.GetUserStatsRow        `json:"stats,omitempty"`
+	Items       []repository.Item                  `json:"items,omitempty"`
+	// only shown to the owner
+	Privacy *ProfilePrivacy `json:"privacy,omitempty"`
+}
+
+type ProfileUpdate struct {
+	DisplayName *string `json:"display_name,omitempty"`
+	Bio         *string `json:"bio,omitempty"`
+	Location    *string `json:"location,omitempty"`
+	Pronouns    *string `json:"pronouns,omitempty"`
+	// replaces all links, an empty list removes them
+	Links []ProfileLink `json:"links"`
+	// public, friends or private
+	ProfileVisibility *string `json:"profile_visibility,omitempty"`
+	ShowClubs         *bool   `json:"show_clubs,omitempty"`
+	ShowStats         *bool   `json:"show_stats,omitempty"`
+	ShowItems         *bool   `json:"show_items,omitempty"`
+}
+
+func (s *ProfileService) GetProfile(ctx context.Context, viewerID string, userID string) (UserProfile, error) {
+	user, err := s.q.GetUserProfile(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return UserProfile{}, ErrUserNotFound
+	}
+	if err != nil {
+		return UserProfile{}, err
+	}
+
+	profile := UserProfile{
+		ID:             user.ID,
+		Username:       user.Username,
+		ProfilePicture: user.ProfilePicture,
+		CreatedAt:      user.CreatedAt,
+	}
+	profile.Reputation, err = userReputation(ctx, s.q, userID)
+	if err != nil {
+		return UserProfile{}, err
+	}
+	isOwner := viewerID == userID
+	visible, err := s.isVisibleTo(ctx, user, viewerID)
+	if err != nil || !visible {
+		return profile, err
+	}
+
+	profile.Visible = true
+	profile.DisplayName = user.DisplayName
+	profile.Bio = user.Bio
+	profile.Location = user.Location
+	profile.Pronouns = user.Pronouns
+	links, err := s.q.GetUserLinks(ctx, userID)
+	if err != nil {
+		return UserProfile{}, err
+	}
+	for _, link := range links {
+		profile.Links = append(profile.Links, ProfileLink{Label: link.Label, URL: link.Url})
+	}
+
+	if isOwner || user.ShowClubs {
+		profile.Clubs, err = s.q.GetUserPublicClubs(ctx, userID)
+		if err != nil {
+			return UserProfile{}, err
+		}
+	}
+	if isOwner || user.ShowStats {
+		stats, err := s.q.GetUserStats(ctx, userID)
+		if err != nil {
+			return UserProfile{}, err
+		}
+		profile.Stats = &stats
+	}
+	if isOwner || user.ShowItems {
+		now := time.Now()
+		profile.Items, err = s.q.GetAvailableItemsByOwner(ctx, repository.GetAvailableItemsByOwnerParams{
+			OwnerID:  userID,
+			ViewerID: viewerID,
+			Now:      &now,
+		})
+		if err != nil {
+			return UserProfile{}, err
+		}
+	}
+	if isOwner {
+		profile.Privacy = &ProfilePrivacy{
+			ProfileVisibility: user.ProfileVisibility,
+			ShowClubs:         user.ShowClubs,
+			ShowStats:         user.ShowStats,
+			ShowItems:         user.ShowItems,
+		}
+	}
+	return profile, nil
+}
+
+func (s *ProfileService) isVisibleTo(ctx context.Context, user repository.GetUserProfileRow, viewerID string) (bool, error) {
+	if viewerID == user.ID {
+		return true, nil
+	}
+	switch user.ProfileVisibility {
+	case ProfileVisibilityPublic:
+		return true, nil
+	case ProfileVisibilityFriends:
+		friends, err := s.q.AreFriends(ctx, repository.AreFriendsParams{
+			UserID:  user.ID,
+			OtherID: viewerID,
+		})
+		return friends != 0, err
+	}
+	return false, nil
+}
+
+func (s *ProfileService) UpdateProfile(ctx context.Context, userID string, update ProfileUpdate) error {
+	if err := validateProfileUpdate(update); err != nil {
+		return err
+	}
+
+	err := s.q.UpdateUserProfile(ctx, repository.UpdateUserProfileParams{
+		ID:                userID,
+		DisplayName:       update.DisplayName,
+		Bio:               update.Bio,
+		Location:          update.Location,
+		Pronouns:          update.Pronouns,
+		ProfileVisibility: update.ProfileVisibility,
+		ShowClubs:         update.ShowClubs,
+		ShowStats:         update.ShowStats,
+		ShowItems:         update.ShowItems,
+	})
+	if err != nil {
+		return err
+	}
+
+	if update.Links == nil {
+		return nil
+	}
+	if err := s.q.DeleteUserLinks(ctx, userID); err != nil {
+		return err
+	}
+	for i, link := range update.Links {
+		err := s.q.CreateUserLink(ctx, repository.CreateUserLinkParams{
+			UserID:   userID,
+			Position: int64(i),
+			Label:    strings.TrimSpace(link.Label),
+			Url:      link.URL,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateProfileUpdate(update ProfileUpdate) error {
+	fields := []struct {
+		name  string
+		value *string
+		max   int
+	}{
+		{"display name", update.DisplayName, config.MaxDisplayNameLength},
+		{"bio", update.Bio, config.MaxBioLength},
+		{"location", update.Location, config.MaxLocationLength},
+		{"pronouns", update.Pronouns, config.MaxPronounsLength},
+	}
+	for _, field := range fields {
+		if field.value != nil && utf8.RuneCountInString(*field.value) > field.max {
+			return fmt.Errorf("%s must be at most %d characters", field.name, field.max)
+		}
+	}
+
+	if v := update.ProfileVisibility; v != nil &&
+		*v != ProfileVisibilityPublic && *v != ProfileVisibilityFriends && *v != ProfileVisibilityPrivate {
+		return fmt.Errorf("profile visibility must be %s, %s or %s", ProfileVisibilityPublic, ProfileVisibilityFriends, ProfileVisibilityPrivate)
+	}
+
+	if len(update.Links) > config.MaxProfileLinks {
+		return fmt.Errorf("at most %d links are allowed", config.MaxProfileLinks)
+	}
+	for _, link := range update.Links {
+		label := strings.TrimSpace(link.Label)
+		if label == "" || utf8.RuneCountInString(label) > config.MaxLinkLabelLength {
+			return fmt.Errorf("link labels must be between 1 and %d characters", config.MaxLinkLabelLength)
+		}
+		// only web links, a javascript: url would run in the browser of whoever clicks it
+		u, err := url.Parse(link.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(link.URL) > config.MaxLinkURLLength {
+			return fmt.Errorf("invalid link %q, links must be http or https URLs", link.URL)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,520 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+type ReportServicer interface {
+	// merged into the unresolved report about the same target if there is one, returns the report id
+	CreateReport(ctx context.Context, reporterID string, req CreateReportRequest) (string, error)
+	// reports the user submitted and what became of them
+	GetUserReports(ctx context.Context, userID string) ([]repository.GetReportsByReporterRow, error)
+	// reports routed to the club's moderators
+	GetClubReports(ctx context.Context, userID string, clubID string, filter ReportFilter) ([]repository.GetReportQueueRow, error)
+	// reports routed to site admins, or every report with filter.AllClubs. only for admins
+	GetAdminReports(ctx context.Context, filter ReportFilter) ([]repository.GetReportQueueRow, error)
+	// only for the moderators or admins handling the report
+	GetReport(ctx context.Context, userID string, reportID string) (ReportDetails, error)
+	TriageReport(ctx context.Context, userID string, reportID string) error
+	// actioned resolutions take the moderation action themselves, admin actions end up in the audit log
+	ResolveReport(ctx context.Context, userID string, reportID string, resolution ReportResolution, client ClientInfo) error
+}
+
+type ReportService struct {
+	q  repository.Querier
+	a  AdminServicer
+	i  ImageServicer
+	tx Transactor
+}
+
+var _ ReportServicer = (*ReportService)(nil)
+
+func NewReportService(q repository.Querier, a AdminServicer, i ImageServicer, tx Transactor) *ReportService {
+	return &ReportService{q: q, a: a, i: i, tx: tx}
+}
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusTriaged   = "triaged"
+	ReportStatusActioned  = "actioned"
+	ReportStatusDismissed = "dismissed"
+)
+
+var ReportCategories = []string{"spam", "harassment", "scam", "inappropriate", "other"}
+
+// moderation actions a report can be resolved with
+const (
+	ReportActionDeletePost   = "delete_post"
+	ReportActionDeleteItem   = "delete_item"
+	ReportActionRemoveMember = "remove_member" // removes the author or owner from the club
+	ReportActionDisableUser  = "disable_user"
+	ReportActionDeleteClub   = "delete_club"
+)
+
+// the actions that fit each kind of target
+var reportActions = map[string][]string{
+	AdminTargetPost: {ReportActionDeletePost, ReportActionRemoveMember},
+	AdminTargetItem: {ReportActionDeleteItem, ReportActionRemoveMember},
+	AdminTargetUser: {ReportActionDisableUser},
+	AdminTargetClub: {ReportActionDeleteClub},
+}
+
+var (
+	ErrInvalidReportTarget     = fmt.Errorf("target type must be %s, %s, %s or %s", AdminTargetPost, AdminTargetItem, AdminTargetUser, AdminTargetClub)
+	ErrInvalidReportCategory   = fmt.Errorf("category must be one of %s", strings.Join(ReportCategories, ", "))
+	ErrReportTargetNotFound    = errors.New("reported content not found")
+	ErrReportSelf              = errors.New("you cannot report yourself")
+	ErrAlreadyReported         = errors.New("you have already reported this")
+	ErrReportNotFound          = errors.New("report not found")
+	ErrReportForbidden         = errors.New("only the club's moderators or site admins can handle this report")
+	ErrReportResolved          = errors.New("report has already been resolved")
+	ErrReportNotOpen           = errors.New("only open reports can be triaged")
+	ErrInvalidReportResolution = errors.New("invalid resolution")
+	ErrReportActionNotAllowed  = errors.New("only club moderators can remove members")
+)
+
+type CreateReportRequest struct {
+	// post, item, user or club
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	// spam, harassment, scam, inappropriate or other
+	Category string `json:"category"`
+	Comment  string `json:"comment"`
+}
+
+type ReportFilter struct {
+	// open, triaged, actioned or dismissed, empty for all
+	Status     string
+	TargetType string
+	// admins only, include reports routed to club moderators
+	AllClubs bool
+	Limit    int64
+	Offset   int64
+}
+
+type ReportDetails struct {
+	Report      repository.Report                    `json:"report"`
+	Submissions []repository.GetReportSubmissionsRow `json:"submissions"`
+	// set when the report was actioned
+	Action *repository.ModerationAction `json:"action,omitempty"`
+}
+
+type ReportResolution struct {
+	// actioned or dismissed
+	Status string `json:"status"`
+	// required when actioned, e.g. delete_post
+	Action string `json:"action,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+func (s *ReportService) CreateReport(ctx context.Context, reporterID string, req CreateReportRequest) (string, error) {
+	if !slices.Contains(ReportCategories, req.Category) {
+		return "", ErrInvalidReportCategory
+	}
+	if utf8.RuneCountInString(req.Comment) > config.MaxReportCommentLength {
+		return "", fmt.Errorf("comment must be at most %d characters", config.MaxReportCommentLength)
+	}
+
+	clubID, err := s.reportTargetClub(ctx, reporterID, req.TargetType, req.TargetID)
+	if err != nil {
+		return "", err
+	}
+
+	report, err := s.q.GetUnresolvedReportForTarget(ctx, repository.GetUnresolvedReportForTargetParams{
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		report.ID = util.GenerateUUID()
+		err = s.q.CreateReport(ctx, repository.CreateReportParams{
+			ID:         report.ID,
+			TargetType: req.TargetType,
+			TargetID:   req.TargetID,
+			ClubID:     clubID,
+		})
+		if err != nil {
+			// someone else reported it at the same time, join their report
+			report, err = s.q.GetUnresolvedReportForTarget(ctx, repository.GetUnresolvedReportForTargetParams{
+				TargetType: req.TargetType,
+				TargetID:   req.TargetID,
+			})
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	exists, err := s.q.HasReportSubmission(ctx, repository.HasReportSubmissionParams{
+		ReportID:   report.ID,
+		ReporterID: reporterID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if exists != 0 {
+		return "", ErrAlreadyReported
+	}
+
+	err = s.q.CreateReportSubmission(ctx, repository.CreateReportSubmissionParams{
+		ReportID:   report.ID,
+		ReporterID: reporterID,
+		Category:   req.Category,
+		Comment:    strings.TrimSpace(req.Comment),
+	})
+	if err != nil {
+		return "", err
+	}
+	return report.ID, nil
+}
+
+// reportTargetClub checks the target exists and the reporter can see it,
+// returns the club whose moderators handle the report or nil for site admins
+func (s *ReportService) reportTargetClub(ctx context.Context, reporterID string, targetType string, targetID string) (*string, error) {
+	var clubID *string
+	switch targetType {
+	case AdminTargetPost:
+		post, err := s.q.GetClubPost(ctx, targetID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReportTargetNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		clubID = &post.ClubID
+	case AdminTargetItem:
+		item, err := s.q.GetItem(ctx, targetID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReportTargetNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		clubID = item.ClubID
+	case AdminTargetUser:
+		if targetID == reporterID {
+			return nil, ErrReportSelf
+		}
+		_, err := s.q.GetUserRole(ctx, targetID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReportTargetNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	case AdminTargetClub:
+		_, err := s.q.GetClub(ctx, targetID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReportTargetNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		// moderators should not judge their own club
+		return nil, nil
+	default:
+		return nil, ErrInvalidReportTarget
+	}
+
+	if clubID == nil {
+		return nil, nil
+	}
+	// content of a club is only visible to its members, do not confirm it exists to anyone else
+	isMember, err := s.q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
+		UserID: reporterID,
+		ClubID: *clubID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if isMember == 0 {
+		return nil, ErrReportTargetNotFound
+	}
+	return clubID, nil
+}
+
+func (s *ReportService) GetUserReports(ctx context.Context, userID string) ([]repository.GetReportsByReporterRow, error) {
+	return s.q.GetReportsByReporter(ctx, userID)
+}
+
+func (s *ReportService) GetClubReports(ctx context.Context, userID string, clubID string, filter ReportFilter) ([]repository.GetReportQueueRow, error) {
+	isModerator, err := s.q.IsUserModeratorOfClub(ctx, repository.IsUserModeratorOfClubParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if isModerator == 0 {
+		return nil, ErrReportForbidden
+	}
+
+	return s.q.GetReportQueue(ctx, repository.GetReportQueueParams{
+		Status:     filter.Status,
+		TargetType: filter.TargetType,
+		ClubID:     clubID,
+		Limit:      adminPageSize(filter.Limit),
+		Offset:     max(filter.Offset, 0),
+	})
+}
+
+func (s *ReportService) GetAdminReports(ctx context.Context, filter ReportFilter) ([]repository.GetReportQueueRow, error) {
+	return s.q.GetReportQueue(ctx, repository.GetReportQueueParams{
+		Status:     filter.Status,
+		TargetType: filter.TargetType,
+		AllClubs:   filter.AllClubs,
+		Limit:      adminPageSize(filter.Limit),
+		Offset:     max(filter.Offset, 0),
+	})
+}
+
+func (s *ReportService) GetReport(ctx context.Context, userID string, reportID string) (ReportDetails, error) {
+	report, _, err := s.getHandledReport(ctx, userID, reportID)
+	if err != nil {
+		return ReportDetails{}, err
+	}
+
+	details := ReportDetails{Report: report}
+	details.Submissions, err = s.q.GetReportSubmissions(ctx, reportID)
+	if err != nil {
+		return ReportDetails{}, err
+	}
+	if report.ResolutionActionID != nil {
+		action, err := s.q.GetModerationAction(ctx, *report.ResolutionActionID)
+		if err != nil {
+			return ReportDetails{}, err
+		}
+		details.Action = &action
+	}
+	return details, nil
+}
+
+func (s *ReportService) TriageReport(ctx context.Context, userID string, reportID string) error {
+	if _, _, err := s.getHandledReport(ctx, userID, reportID); err != nil {
+		return err
+	}
+
+	rows, err := s.q.TriageReport(ctx, repository.TriageReportParams{
+		ID:        reportID,
+		HandledBy: &userID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrReportNotOpen
+	}
+	return nil
+}
+
+func (s *ReportService) ResolveReport(ctx context.Context, userID string, reportID string, resolution ReportResolution, client ClientInfo) error {
+	report, isAdmin, err := s.getHandledReport(ctx, userID, reportID)
+	if err != nil {
+		return err
+	}
+	if report.Status != ReportStatusOpen && report.Status != ReportStatusTriaged {
+		return ErrReportResolved
+	}
+	if utf8.RuneCountInString(resolution.Note) > config.MaxReportNoteLength {
+		return fmt.Errorf("note must be at most %d characters", config.MaxReportNoteLength)
+	}
+
+	var action, actionID *string
+	switch resolution.Status {
+	case ReportStatusDismissed:
+		if resolution.Action != "" {
+			return fmt.Errorf("%w: dismissed reports take no action", ErrInvalidReportResolution)
+		}
+	case ReportStatusActioned:
+		if !slices.Contains(reportActions[report.TargetType], resolution.Action) {
+			return fmt.Errorf("%w: %s reports can be actioned with %s", ErrInvalidReportResolution,
+				report.TargetType, strings.Join(reportActions[report.TargetType], " or "))
+		}
+		id := util.GenerateUUID()
+		action, actionID = &resolution.Action, &id
+	default:
+		return fmt.Errorf("%w: status must be %s or %s", ErrInvalidReportResolution, ReportStatusActioned, ReportStatusDismissed)
+	}
+
+	var note *string
+	if n := strings.TrimSpace(resolution.Note); n != "" {
+		note = &n
+	}
+	var images []repository.ItemImage
+	var banner *string
+	err = s.tx.WithTx(ctx, func(q repository.Querier) error {
+		// claimed first, a second moderator resolving the same report waits and then finds it resolved
+		rows, err := q.ResolveReport(ctx, repository.ResolveReportParams{
+			ID:                 reportID,
+			Status:             resolution.Status,
+			HandledBy:          &userID,
+			ResolutionAction:   action,
+			ResolutionNote:     note,
+			ResolutionActionID: actionID,
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrReportResolved
+		}
+		if actionID == nil {
+			return nil
+		}
+
+		images, banner, err = takeAction(ctx, q, report, resolution, isAdmin, AdminRequest{
+			AdminID:  userID,
+			Reason:   resolution.Note,
+			Client:   client,
+			ReportID: &report.ID,
+		})
+		if err != nil {
+			return err
+		}
+		return q.CreateModerationAction(ctx, repository.CreateModerationActionParams{
+			ID:          *actionID,
+			ModeratorID: &userID,
+			Action:      resolution.Action,
+			TargetType:  report.TargetType,
+			TargetID:    report.TargetID,
+			ClubID:      report.ClubID,
+			AsAdmin:     isAdmin,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	deleteItemImageFiles(s.i, images)
+	deleteUploadedImage(s.i, banner)
+	return nil
+}
+
+// takeAction runs the moderation action on q, admin actions are audited. it returns the item photos and
+// club banner of deleted content, their files are removed once the transaction committed
+func takeAction(ctx context.Context, q repository.Querier, report repository.Report, resolution ReportResolution, isAdmin bool, req AdminRequest) ([]repository.ItemImage, *string, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		req.Reason = "report " + report.ID
+	}
+
+	switch resolution.Action {
+	case ReportActionDeletePost:
+		if isAdmin {
+			return nil, nil, adminDeleteClubPost(ctx, q, req, report.TargetID)
+		}
+		if _, err := q.GetClubPost(ctx, report.TargetID); errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrPostNotFound
+		}
+		return nil, nil, q.DeleteClubPost(ctx, report.TargetID)
+	case ReportActionDeleteItem:
+		if isAdmin {
+			images, err := adminDeleteItem(ctx, q, req, report.TargetID)
+			return images, nil, err
+		}
+		if _, err := q.GetItem(ctx, report.TargetID); errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrItemNotFound
+		}
+		images, err := deleteItemRecord(ctx, q, report.TargetID)
+		return images, nil, err
+	case ReportActionRemoveMember:
+		return nil, nil, removeReportedMember(ctx, q, report, req.AdminID)
+	case ReportActionDisableUser:
+		return nil, nil, disableUser(ctx, q, req, report.TargetID)
+	case ReportActionDeleteClub:
+		club, images, err := adminDeleteClub(ctx, q, req, report.TargetID)
+		return images, club.BannerImage, err
+	}
+	return nil, nil, ErrInvalidReportResolution
+}
+
+// removeReportedMember removes the author of a reported post or the owner of a reported item from the club, forfeiting their points
+func removeReportedMember(ctx context.Context, q repository.Querier, report repository.Report, moderatorID string) error {
+	if report.ClubID == nil {
+		return ErrReportActionNotAllowed
+	}
+	isModerator, err := q.IsUserModeratorOfClub(ctx, repository.IsUserModeratorOfClubParams{
+		UserID: moderatorID,
+		ClubID: *report.ClubID,
+	})
+	if err != nil {
+		return err
+	}
+	if isModerator == 0 {
+		return ErrReportActionNotAllowed
+	}
+
+	var memberID string
+	switch report.TargetType {
+	case AdminTargetPost:
+		post, err := q.GetClubPost(ctx, report.TargetID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && post.UserID == nil) {
+			return ErrPostNotFound
+		}
+		if err != nil {
+			return err
+		}
+		memberID = *post.UserID
+	case AdminTargetItem:
+		item, err := q.GetItem(ctx, report.TargetID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+		memberID = item.OwnerID
+	}
+
+	isOwner, err := q.IsUserOwnerOfClub(ctx, repository.IsUserOwnerOfClubParams{
+		UserID: memberID,
+		ClubID: *report.ClubID,
+	})
+	if err != nil {
+		return err
+	}
+	if isOwner != 0 {
+		return fmt.Errorf("%w: the club owner cannot be removed", ErrReportActionNotAllowed)
+	}
+	return removeClubMember(ctx, q, *report.ClubID, memberID)
+}
+
+// getHandledReport returns the report if the user may handle it, and whether they do so as a site admin
+func (s *ReportService) getHandledReport(ctx context.Context, userID string, reportID string) (repository.Report, bool, error) {
+	report, err := s.q.GetReport(ctx, reportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.Report{}, false, ErrReportNotFound
+	}
+	if err != nil {
+		return repository.Report{}, false, err
+	}
+
+	isAdmin, err := s.a.IsAdmin(ctx, userID)
+	if err != nil {
+		return repository.Report{}, false, err
+	}
+	if isAdmin {
+		return report, true, nil
+	}
+	if report.ClubID != nil {
+		isModerator, err := s.q.IsUserModeratorOfClub(ctx, repository.IsUserModeratorOfClubParams{
+			UserID: userID,
+			ClubID: *report.ClubID,
+		})
+		if err != nil {
+			return repository.Report{}, false, err
+		}
+		if isModerator != 0 {
+			return report, false, nil
+		}
+	}
+	return repository.Report{}, false, ErrReportForbidden
+}
@@ -0,0 +1,505 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+// RewardServicer is a club's point shop, members spend their points on the club's rewards and moderators hand them out.
+// a reward can be one of the moderator's items, it cannot be traded while it is offered and goes to the member
+// when the redemption is fulfilled
+type RewardServicer interface {
+	// inactive rewards are only listed for the club's moderators
+	GetClubRewards(ctx context.Context, userID string, clubID string) ([]repository.ClubReward, error)
+	CreateClubReward(ctx context.Context, userID string, clubID string, req CreateRewardRequest) (string, error)
+	UpdateClubReward(ctx context.Context, userID string, clubID string, rewardID string, req UpdateRewardRequest) error
+	// takes the cost from the member's points right away, the redemption then waits for a moderator
+	RedeemReward(ctx context.Context, userID string, clubID string, rewardID string, note *string) (string, error)
+	// moderators see everyone's redemptions, members only their own. status is optional
+	GetClubRedemptions(ctx context.Context, userID string, clubID string, status *string) ([]repository.GetClubRedemptionsRow, error)
+	// hands over the reward's item, the member is told over the WebSocket
+	FulfillRedemption(ctx context.Context, userID string, clubID string, redemptionID string) error
+	// refunds the points if the member is still in the club and puts the reward back in stock
+	RejectRedemption(ctx context.Context, userID string, clubID string, redemptionID string) error
+}
+
+type RewardService struct {
+	q  repository.Querier
+	tx Transactor
+	w  WebSocketServicer
+}
+
+var _ RewardServicer = (*RewardService)(nil)
+
+func NewRewardService(q repository.Querier, tx Transactor, w WebSocketServicer) *RewardService {
+	return &RewardService{q: q, tx: tx, w: w}
+}
+
+const (
+	RedemptionPending   = "pending"
+	RedemptionFulfilled = "fulfilled"
+	RedemptionRejected  = "rejected"
+)
+
+var (
+	ErrInvalidReward = fmt.Errorf("name must be 1 to %d characters, description at most %d, cost positive and stock zero or more",
+		config.MaxRewardNameLength, config.MaxRewardDescriptionLength)
+	ErrRedemptionNoteLength    = fmt.Errorf("note must be at most %d characters", config.MaxRedemptionNoteLength)
+	ErrInvalidRedemptionStatus = fmt.Errorf("status must be %s, %s or %s", RedemptionPending, RedemptionFulfilled, RedemptionRejected)
+	ErrRewardNotFound          = errors.New("reward not found")
+	ErrRewardUnavailable       = errors.New("reward is inactive or out of stock")
+	ErrRedemptionNotFound      = errors.New("redemption not found")
+	ErrRedemptionHandled       = errors.New("redemption was already fulfilled or rejected")
+	ErrRewardItemUnavailable   = errors.New("a reward's item must be one of your tradable items in the club")
+	ErrRewardItemStock         = errors.New("a reward with an item always has one in stock")
+)
+
+type CreateRewardRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// in club points
+	Cost float64 `json:"cost"`
+	// how many can be redeemed, no limit if left out
+	Stock *int64 `json:"stock,omitempty"`
+	// one of the moderator's items in the club to hand out, the reward then has one in stock
+	ItemID *string `json:"item_id,omitempty"`
+}
+
+// fields left out stay the same
+type UpdateRewardRequest struct {
+	Name        *string  `json:"name,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Cost        *float64 `json:"cost,omitempty"`
+	Stock       *int64   `json:"stock,omitempty"`
+	// removes the stock limit, stock is ignored
+	UnlimitedStock bool `json:"unlimited_stock,omitempty"`
+	// inactive rewards cannot be redeemed and are hidden from members. deactivating a reward with an item that was
+	// not redeemed lists the item for trading again, activating it needs the item to be one of the moderator's again
+	IsActive *bool `json:"is_active,omitempty"`
+}
+
+func (s *RewardService) GetClubRewards(ctx context.Context, userID string, clubID string) ([]repository.ClubReward, error) {
+	isModerator, err := s.checkMember(ctx, userID, clubID)
+	if err != nil {
+		return nil, err
+	}
+	return s.q.GetClubRewards(ctx, repository.GetClubRewardsParams{
+		ClubID:          clubID,
+		IncludeInactive: isModerator,
+	})
+}
+
+func (s *RewardService) CreateClubReward(ctx context.Context, userID string, clubID string, req CreateRewardRequest) (string, error) {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(req.Name)
+	description := strings.TrimSpace(req.Description)
+	if !validReward(&name, &description, &req.Cost, req.Stock) {
+		return "", ErrInvalidReward
+	}
+	if req.ItemID != nil {
+		if req.Stock != nil && *req.Stock != 1 {
+			return "", ErrRewardItemStock
+		}
+		one := int64(1)
+		req.Stock = &one
+	}
+
+	rewardID := util.GenerateUUID()
+	err := s.tx.WithTx(ctx, func(q repository.Querier) error {
+		if req.ItemID != nil {
+			if err := setRewardItemListing(ctx, q, userID, clubID, *req.ItemID, ListingReward); err != nil {
+				return err
+			}
+		}
+		return q.CreateClubReward(ctx, repository.CreateClubRewardParams{
+			ID:          rewardID,
+			ClubID:      clubID,
+			Name:        name,
+			Description: description,
+			Cost:        req.Cost,
+			Stock:       req.Stock,
+			ItemID:      req.ItemID,
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return rewardID, nil
+}
+
+func (s *RewardService) UpdateClubReward(
+	ctx context.Context,
+	userID string,
+	clubID string,
+	rewardID string,
+	req UpdateRewardRequest,
+) error {
+
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return err
+	}
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		*req.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.UnlimitedStock {
+		req.Stock = nil
+	}
+	if !validReward(req.Name, req.Description, req.Cost, req.Stock) {
+		return ErrInvalidReward
+	}
+
+	return s.tx.WithTx(ctx, func(q repository.Querier) error {
+		reward, err := getReward(ctx, q, clubID, rewardID)
+		if err != nil {
+			return err
+		}
+		if reward.ItemID != nil {
+			if req.Stock != nil || req.UnlimitedStock {
+				return ErrRewardItemStock
+			}
+			// an item that was redeemed waits for the moderators or belongs to the member already
+			if req.IsActive != nil && *req.IsActive != reward.IsActive && reward.Stock != nil && *reward.Stock > 0 {
+				listing := ListingTrade
+				if *req.IsActive {
+					listing = ListingReward
+				}
+				if err := setRewardItemListing(ctx, q, userID, clubID, *reward.ItemID, listing); err != nil {
+					return err
+				}
+			}
+		}
+		return q.UpdateClubReward(ctx, repository.UpdateClubRewardParams{
+			Name:           req.Name,
+			Description:    req.Description,
+			Cost:           req.Cost,
+			UnlimitedStock: req.UnlimitedStock,
+			Stock:          req.Stock,
+			IsActive:       req.IsActive,
+			ID:             rewardID,
+		})
+	})
+}
+
+func (s *RewardService) RedeemReward(
+	ctx context.Context,
+	userID string,
+	clubID string,
+	rewardID string,
+	note *string,
+) (string, error) {
+
+	if _, err := s.checkMember(ctx, userID, clubID); err != nil {
+		return "", err
+	}
+	if note != nil {
+		if *note = strings.TrimSpace(*note); *note == "" {
+			note = nil
+		} else if utf8.RuneCountInString(*note) > config.MaxRedemptionNoteLength {
+			return "", ErrRedemptionNoteLength
+		}
+	}
+
+	redemptionID := util.GenerateUUID()
+	err := s.tx.WithTx(ctx, func(q repository.Querier) error {
+		reward, err := getReward(ctx, q, clubID, rewardID)
+		if err != nil {
+			return err
+		}
+		rows, err := q.TakeRewardStock(ctx, rewardID)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrRewardUnavailable
+		}
+		if reward.ItemID != nil {
+			if _, err := rewardItem(ctx, q, reward); err != nil {
+				return err
+			}
+		}
+		err = q.CreateRewardRedemption(ctx, repository.CreateRewardRedemptionParams{
+			ID:       redemptionID,
+			RewardID: rewardID,
+			ClubID:   clubID,
+			UserID:   userID,
+			Cost:     reward.Cost,
+			Note:     note,
+		})
+		if err != nil {
+			return err
+		}
+		return changeClubPoints(ctx, q, pointChange{
+			ClubID:      clubID,
+			UserID:      userID,
+			Amount:      -reward.Cost,
+			Reason:      PointsRedemption,
+			ReferenceID: &redemptionID,
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return redemptionID, nil
+}
+
+func (s *RewardService) GetClubRedemptions(
+	ctx context.Context,
+	userID string,
+	clubID string,
+	status *string,
+) ([]repository.GetClubRedemptionsRow, error) {
+
+	isModerator, err := s.checkMember(ctx, userID, clubID)
+	if err != nil {
+		return nil, err
+	}
+	if status != nil && *status != RedemptionPending && *status != RedemptionFulfilled && *status != RedemptionRejected {
+		return nil, ErrInvalidRedemptionStatus
+	}
+
+	params := repository.GetClubRedemptionsParams{
+		ClubID: clubID,
+		Status: status,
+	}
+	if !isModerator {
+		params.UserID = &userID
+	}
+	return s.q.GetClubRedemptions(ctx, params)
+}
+
+func (s *RewardService) FulfillRedemption(ctx context.Context, userID string, clubID string, redemptionID string) error {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return err
+	}
+	err := s.tx.WithTx(ctx, func(q repository.Querier) error {
+		redemption, err := getRedemption(ctx, q, clubID, redemptionID)
+		if err != nil {
+			return err
+		}
+		if err := handleRedemption(ctx, q, userID, redemptionID, RedemptionFulfilled); err != nil {
+			return err
+		}
+		reward, err := q.GetClubReward(ctx, redemption.RewardID)
+		if err != nil || reward.ItemID == nil {
+			return err
+		}
+		item, err := rewardItem(ctx, q, reward)
+		if err != nil {
+			return err
+		}
+		err = q.TransferItemOwnership(ctx, repository.TransferItemOwnershipParams{
+			OwnerID: redemption.UserID,
+			ID:      item.ID,
+		})
+		if err != nil {
+			return err
+		}
+		return q.SetItemListingType(ctx, repository.SetItemListingTypeParams{
+			ListingType: ListingTrade,
+			ID:          item.ID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.notifyRedemption(ctx, redemptionID)
+	return nil
+}
+
+func (s *RewardService) RejectRedemption(ctx context.Context, userID string, clubID string, redemptionID string) error {
+	if err := s.checkModerator(ctx, userID, clubID); err != nil {
+		return err
+	}
+	err := s.tx.WithTx(ctx, func(q repository.Querier) error {
+		redemption, err := getRedemption(ctx, q, clubID, redemptionID)
+		if err != nil {
+			return err
+		}
+		if err := handleRedemption(ctx, q, userID, redemptionID, RedemptionRejected); err != nil {
+			return err
+		}
+		if err := q.ReturnRewardStock(ctx, redemption.RewardID); err != nil {
+			return err
+		}
+		// the item of a reward that was deactivated while it waited is listed for trading again
+		reward, err := q.GetClubReward(ctx, redemption.RewardID)
+		if err != nil {
+			return err
+		}
+		if reward.ItemID != nil && !reward.IsActive {
+			if err := setRewardItemListing(ctx, q, userID, clubID, *reward.ItemID, ListingTrade); err != nil {
+				return err
+			}
+		}
+		err = changeClubPoints(ctx, q, pointChange{
+			ClubID:      clubID,
+			UserID:      redemption.UserID,
+			Amount:      redemption.Cost,
+			Reason:      PointsRefund,
+			ReferenceID: &redemptionID,
+		})
+		// the points went with the membership when the member left
+		if errors.Is(err, ErrNotClubMember) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.notifyRedemption(ctx, redemptionID)
+	return nil
+}
+
+// validReward checks the fields that are set
+func validReward(name *string, description *string, cost *float64, stock *int64) bool {
+	if name != nil && (*name == "" || utf8.RuneCountInString(*name) > config.MaxRewardNameLength) {
+		return false
+	}
+	if description != nil && utf8.RuneCountInString(*description) > config.MaxRewardDescriptionLength {
+		return false
+	}
+	if cost != nil && (!(*cost > 0) || math.IsInf(*cost, 0)) {
+		return false
+	}
+	return stock == nil || *stock >= 0
+}
+
+// setRewardItemListing sets an item of the moderator aside for a reward or lists it for trading again
+func setRewardItemListing(ctx context.Context, q repository.Querier, userID string, clubID string, itemID string, listing string) error {
+	item, err := q.GetItem(ctx, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		if listing == ListingReward {
+			return ErrItemNotFound
+		}
+		// a deleted item has nothing to list again
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if listing == ListingReward {
+		if item.OwnerID != userID || item.ClubID == nil || *item.ClubID != clubID ||
+			!isTradable(item) || activeReservation(item.ReservedUntil, time.Now()) != nil {
+			return ErrRewardItemUnavailable
+		}
+	} else if item.ListingType != ListingReward {
+		return nil
+	}
+	return q.SetItemListingType(ctx, repository.SetItemListingTypeParams{
+		ListingType: listing,
+		ID:          itemID,
+	})
+}
+
+// rewardItem is the item a reward hands out, the reward cannot be redeemed or fulfilled once the item is gone
+func rewardItem(ctx context.Context, q repository.Querier, reward repository.ClubReward) (repository.Item, error) {
+	item, err := q.GetItem(ctx, *reward.ItemID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && item.ListingType != ListingReward) {
+		return repository.Item{}, ErrRewardUnavailable
+	}
+	return item, err
+}
+
+// Rewards of other clubs are not found.
+func getReward(ctx context.Context, q repository.Querier, clubID string, rewardID string) (repository.ClubReward, error) {
+	reward, err := q.GetClubReward(ctx, rewardID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && reward.ClubID != clubID) {
+		return repository.ClubReward{}, ErrRewardNotFound
+	}
+	return reward, err
+}
+
+func getRedemption(ctx context.Context, q repository.Querier, clubID string, redemptionID string) (repository.RewardRedemption, error) {
+	redemption, err := q.GetRewardRedemption(ctx, redemptionID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && redemption.ClubID != clubID) {
+		return repository.RewardRedemption{}, ErrRedemptionNotFound
+	}
+	return redemption, err
+}
+
+func handleRedemption(ctx context.Context, q repository.Querier, userID string, redemptionID string, status string) error {
+	rows, err := q.HandleRewardRedemption(ctx, repository.HandleRewardRedemptionParams{
+		Status:    status,
+		HandledBy: &userID,
+		ID:        redemptionID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRedemptionHandled
+	}
+	return nil
+}
+
+// notifyRedemption tells the member their redemption was handled, failures are only logged
+func (s *RewardService) notifyRedemption(ctx context.Context, redemptionID string) {
+	redemption, err := s.q.GetRewardRedemption(ctx, redemptionID)
+	if err != nil {
+		log.Printf("Failed to load redemption %s: %v", redemptionID, err)
+		return
+	}
+	jsonBytes, err := json.Marshal(WebSocketMessage{
+		Event:   "redemption_updated",
+		Payload: redemption,
+	})
+	if err != nil {
+		log.Printf("Failed to encode redemption_updated: %v", err)
+		return
+	}
+	s.w.BroadcastMessage(ctx, []string{redemption.UserID}, string(jsonBytes))
+}
+
+// checkMember also reports whether the user is one of the club's moderators
+func (s *RewardService) checkMember(ctx context.Context, userID string, clubID string) (bool, error) {
+	isMember, err := s.q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+	if err != nil {
+		return false, err
+	}
+	if isMember == 0 {
+		return false, ErrNotClubMember
+	}
+	isModerator, err := s.q.IsUserModeratorOfClub(ctx, repository.IsUserModeratorOfClubParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return isModerator == 1, nil
+}
+
+func (s *RewardService) checkModerator(ctx context.Context, userID string, clubID string) error {
+	isModerator, err := s.q.IsUserModeratorOfClub(ctx, repository.IsUserModeratorOfClubParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+	if err != nil {
+		return err
+	}
+	if isModerator == 0 {
+		return ErrNotClubModerator
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+type SessionServicer interface {
+	// creates a new device session and returns its first token pair
+	CreateSession(ctx context.Context, userID string, client ClientInfo) (AuthTokens, error)
+	// exchanges a refresh token for a new token pair, the old refresh token stops working
+	RefreshSession(ctx context.Context, refreshToken string, client ClientInfo) (AuthTokens, error)
+	// read-only session for an admin acting as the user, it cannot be refreshed. returns its access token
+	CreateImpersonationSession(ctx context.Context, userID string, impersonatorID string, client ClientInfo) (string, error)
+	// returns the session if it is still usable
+	ValidateSession(ctx context.Context, userID string, sessionID string) (repository.UserSession, error)
+	GetSessions(ctx context.Context, userID string, currentSessionID string) ([]UserSession, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID string) error
+}
+
+type SessionService struct {
+	q repository.Querier
+	a AuthServicer
+}
+
+var _ SessionServicer = (*SessionService)(nil)
+
+func NewSessionService(q repository.Querier, a AuthServicer) *SessionService {
+	return &SessionService{q: q, a: a}
+}
+
+// ClientInfo describes the device a request came from
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+type UserSession struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	IsCurrent  bool      `json:"is_current"`
+	// set while an admin is looking at the account for support
+	ImpersonatorID *string `json:"impersonator_id,omitempty"`
+}
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+// SessionIDFromToken reads the "sid" claim set by GenerateToken
+func SessionIDFromToken(token *jwt.Token) (string, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", errors.New("invalid token claims")
+	}
+	sid, ok := claims["sid"].(string)
+	if !ok || sid == "" {
+		return "", errors.New("token is not bound to a session")
+	}
+	return sid, nil
+}
+
+func (s *SessionService) CreateSession(ctx context.Context, userID string, client ClientInfo) (AuthTokens, error) {
+	sessionID := util.GenerateUUID()
+	refreshToken, hash, err := newRefreshToken(sessionID)
+	if err != nil {
+		return AuthTokens{}, err
+	}
+
+	err = s.q.CreateUserSession(ctx, repository.CreateUserSessionParams{
+		ID:               sessionID,
+		UserID:           userID,
+		RefreshTokenHash: hash,
+		UserAgent:        client.UserAgent,
+		IpAddress:        client.IP,
+		ExpiresAt:        time.Now().Add(config.RefreshTokenDuration),
+	})
+	if err != nil {
+		return AuthTokens{}, err
+	}
+
+	accessToken, err := s.a.GenerateToken(ctx, userID, sessionID)
+	if err != nil {
+		return AuthTokens{}, err
+	}
+	return AuthTokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+func (s *SessionService) CreateImpersonationSession(ctx context.Context, userID string, impersonatorID string, client ClientInfo) (string, error) {
+	sessionID := util.GenerateUUID()
+	// the refresh token is never handed out, the session ends with its access token
+	_, hash, err := newRefreshToken(sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.q.CreateUserSession(ctx, repository.CreateUserSessionParams{
+		ID:               sessionID,
+		UserID:           userID,
+		RefreshTokenHash: hash,
+		UserAgent:        client.UserAgent,
+		IpAddress:        client.IP,
+		ExpiresAt:        time.Now().Add(config.ImpersonationDuration),
+		ImpersonatorID:   &impersonatorID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return s.a.GenerateToken(ctx, userID, sessionID)
+}
+
+func (s *SessionService) RefreshSession(ctx context.Context, refreshToken string, client ClientInfo) (AuthTokens, error) {
+	sessionID, _, ok := strings.Cut(refreshToken, ".")
+	if !ok {
+		return AuthTokens{}, ErrInvalidRefreshToken
+	}
+
+	session, err := s.q.GetUserSession(ctx, sessionID)
+	if err != nil {
+		return AuthTokens{}, ErrInvalidRefreshToken
+	}
+	if session.RevokedAt != nil || session.ExpiresAt.Before(time.Now()) {
+		return AuthTokens{}, ErrInvalidRefreshToken
+	}
+
+	newToken, newHash, err := newRefreshToken(sessionID)
+	if err != nil {
+		return AuthTokens{}, err
+	}
+
+	rows, err := s.q.RotateUserSessionRefreshToken(ctx, repository.RotateUserSessionRefreshTokenParams{
+		ID:                  sessionID,
+		RefreshTokenHash:    util.HashToken(refreshToken),
+		NewRefreshTokenHash: newHash,
+		UserAgent:           client.UserAgent,
+		IpAddress:           client.IP,
+		ExpiresAt:           time.Now().Add(config.RefreshTokenDuration),
+	})
+	if err != nil {
+		return AuthTokens{}, err
+	}
+	if rows == 0 {
+		// a rotated out token was replayed, assume it leaked and kill the session
+		s.q.RevokeUserSession(ctx, repository.RevokeUserSessionParams{
+			ID:     sessionID,
+			UserID: session.UserID,
+		})
+		return AuthTokens{}, ErrInvalidRefreshToken
+	}
+
+	accessToken, err := s.a.GenerateToken(ctx, session.UserID, sessionID)
+	if err != nil {
+		return AuthTokens{}, err
+	}
+	return AuthTokens{AccessToken: accessToken, RefreshToken: newToken}, nil
+}
+
+func (s *SessionService) ValidateSession(ctx context.Context, userID string, sessionID string) (repository.UserSession, error) {
+	session, err := s.q.GetUserSession(ctx, sessionID)
+	if err != nil {
+		return repository.UserSession{}, errors.New("session not found")
+	}
+	if session.UserID != userID {
+		return repository.UserSession{}, errors.New("session does not belong to user")
+	}
+	if session.RevokedAt != nil {
+		return repository.UserSession{}, errors.New("session has been revoked")
+	}
+	if session.ExpiresAt.Before(time.Now()) {
+		return repository.UserSession{}, errors.New("session has expired")
+	}
+
+	// avoid a write on every request, last seen only needs to be roughly accurate
+	if time.Since(session.LastSeenAt) > config.SessionLastSeenInterval {
+		if err := s.q.TouchUserSession(ctx, sessionID); err != nil {
+			return repository.UserSession{}, err
+		}
+	}
+	return session, nil
+}
+
+func (s *SessionService) GetSessions(ctx context.Context, userID string, currentSessionID string) ([]UserSession, error) {
+	rows, err := s.q.GetActiveUserSessions(ctx, repository.GetActiveUserSessionsParams{
+		UserID: userID,
+		Now:    time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]UserSession, 0, len(rows))
+	for _, row := range rows {
+		sessions = append(sessions, UserSession{
+			ID:             row.ID,
+			UserAgent:      row.UserAgent,
+			IPAddress:      row.IpAddress,
+			LastSeenAt:     row.LastSeenAt,
+			ExpiresAt:      row.ExpiresAt,
+			CreatedAt:      row.CreatedAt,
+			IsCurrent:      row.ID == currentSessionID,
+			ImpersonatorID: row.ImpersonatorID,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession returns ErrSessionNotFound unless the user had the session open
+func (s *SessionService) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	rows, err := s.q.RevokeUserSession(ctx, repository.RevokeUserSessionParams{
+		ID:     sessionID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *SessionService) RevokeAllSessions(ctx context.Context, userID string) error {
+	return s.q.RevokeAllUserSessions(ctx, userID)
+}
+
+// refresh tokens are "<session id>.<secret>" so the session can be looked up without scanning hashes
+func newRefreshToken(sessionID string) (string, string, error) {
+	secret, err := util.GenerateSecureToken(config.RefreshTokenBytes)
+	if err != nil {
+		return "", "", err
+	}
+	token := sessionID + "." + secret
+	return token, util.HashToken(token), nil
+}
+
+func CleanUpExpiredSessions(ctx context.Context, q repository.Querier) error {
+	return q.DeleteExpiredUserSessions(ctx, time.Now())
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+type SigningKeyServicer interface {
+	// key new tokens are signed with
+	SigningKey(ctx context.Context) (SigningKey, error)
+	// key a token with the given "kid" header was signed with
+	VerificationKey(ctx context.Context, kid string) (SigningKey, error)
+	// public keys other services can verify our tokens with, empty for a shared secret
+	JWKS(ctx context.Context) (JWKS, error)
+}
+
+type SigningKey struct {
+	ID      string // "kid" header, empty for the shared secret
+	Method  jwt.SigningMethod
+	Private any // signs tokens
+	Public  any // verifies tokens
+}
+
+var ErrUnknownSigningKey = errors.New("invalid token: unknown signing key")
+
+// NewSigningKeyService returns the key source for config.JWTSigningMethod
+func NewSigningKeyService(q repository.Querier) SigningKeyServicer {
+	if config.JWTSigningMethod.Alg() == jwt.SigningMethodHS256.Alg() {
+		return NewHMACKeyService()
+	}
+	return NewAsymmetricKeyService(q, config.JWTSigningMethod)
+}
+
+// HMACKeyService signs with the shared config.JWTSecret. It has a single key and nothing to publish
+type HMACKeyService struct{}
+
+var _ SigningKeyServicer = (*HMACKeyService)(nil)
+
+func NewHMACKeyService() *HMACKeyService {
+	return &HMACKeyService{}
+}
+
+func (s *HMACKeyService) SigningKey(ctx context.Context) (SigningKey, error) {
+	if len(config.JWTSecret) == 0 {
+		return SigningKey{}, errors.New("JWT secret is empty. Set with JWT_SECRET_KEY env variable.")
+	}
+	return SigningKey{
+		Method:  jwt.SigningMethodHS256,
+		Private: config.JWTSecret,
+		Public:  config.JWTSecret,
+	}, nil
+}
+
+func (s *HMACKeyService) VerificationKey(ctx context.Context, kid string) (SigningKey, error) {
+	return s.SigningKey(ctx)
+}
+
+func (s *HMACKeyService) JWKS(ctx context.Context) (JWKS, error) {
+	// a shared secret must never be published
+	return JWKS{Keys: []JSONWebKey{}}, nil
+}
+
+// AsymmetricKeyService signs with RS256 or EdDSA keys stored in the database, see RotateSigningKeys.
+// keys are cached for config.SigningKeyCacheDuration so every instance picks up rotations
+type AsymmetricKeyService struct {
+	q      repository.Querier
+	method jwt.SigningMethod
+	now    func() time.Time
+
+	mu       sync.Mutex
+	keys     []storedSigningKey // newest first
+	loadedAt time.Time
+}
+
+type storedSigningKey struct {
+	SigningKey
+	activeAt  time.Time
+	expiresAt time.Time
+}
+
+var _ SigningKeyServicer = (*AsymmetricKeyService)(nil)
+
+func NewAsymmetricKeyService(q repository.Querier, method jwt.SigningMethod) *AsymmetricKeyService {
+	return &AsymmetricKeyService{
+		q:      q,
+		method: method,
+		now:    time.Now,
+	}
+}
+
+func (s *AsymmetricKeyService) SigningKey(ctx context.Context) (SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(ctx, false); err != nil {
+		return SigningKey{}, err
+	}
+	if key, ok := s.activeKey(); ok {
+		return key, nil
+	}
+
+	// first start, nobody has created a key yet
+	if err := RotateSigningKeys(ctx, s.q, s.method, s.now()); err != nil {
+		return SigningKey{}, err
+	}
+	if err := s.load(ctx, true); err != nil {
+		return SigningKey{}, err
+	}
+	if key, ok := s.activeKey(); ok {
+		return key, nil
+	}
+	return SigningKey{}, errors.New("no active signing key")
+}
+
+func (s *AsymmetricKeyService) VerificationKey(ctx context.Context, kid string) (SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(ctx, false); err != nil {
+		return SigningKey{}, err
+	}
+	if key, ok := s.findKey(kid); ok {
+		return key, nil
+	}
+	// another instance may have rotated since the cache was filled
+	if err := s.load(ctx, true); err != nil {
+		return SigningKey{}, err
+	}
+	if key, ok := s.findKey(kid); ok {
+		return key, nil
+	}
+	return SigningKey{}, ErrUnknownSigningKey
+}
+
+func (s *AsymmetricKeyService) JWKS(ctx context.Context) (JWKS, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(ctx, false); err != nil {
+		return JWKS{}, err
+	}
+	// upcoming keys are included so verifiers already have them when they start signing
+	jwks := JWKS{Keys: make([]JSONWebKey, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwk, err := publicJWK(key.SigningKey)
+		if err != nil {
+			return JWKS{}, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks, nil
+}
+
+// load refreshes the cached keys when they are stale or force is set. s.mu must be held
+func (s *AsymmetricKeyService) load(ctx context.Context, force bool) error {
+	now := s.now()
+	if !force && !s.loadedAt.IsZero() && now.Sub(s.loadedAt) < config.SigningKeyCacheDuration {
+		return nil
+	}
+	rows, err := s.q.GetSigningKeys(ctx, now)
+	if err != nil {
+		return err
+	}
+	keys := make([]storedSigningKey, 0, len(rows))
+	for _, row := range rows {
+		key, err := parseSigningKey(row.Kid, row.Algorithm, row.PrivateKey)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, storedSigningKey{
+			SigningKey: key,
+			activeAt:   row.ActiveAt,
+			expiresAt:  row.ExpiresAt,
+		})
+	}
+	s.keys = keys
+	s.loadedAt = now
+	return nil
+}
+
+// activeKey is the newest key of the configured algorithm that has been published long enough
+func (s *AsymmetricKeyService) activeKey() (SigningKey, bool) {
+	now := s.now()
+	for _, key := range s.keys {
+		if key.Method.Alg() == s.method.Alg() && !key.activeAt.After(now) && key.expiresAt.After(now) {
+			return key.SigningKey, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+func (s *AsymmetricKeyService) findKey(kid string) (SigningKey, bool) {
+	now := s.now()
+	for _, key := range s.keys {
+		if key.ID == kid && key.expiresAt.After(now) {
+			return key.SigningKey, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// RotateSigningKeys creates the next key once the current one is close to the end of its rotation period
+// and deletes keys no valid token can have been signed with. The next key is published
+// config.SigningKeyPrePublish before it signs anything so verifiers caching the JWKS know it in time
+func RotateSigningKeys(ctx context.Context, q repository.Querier, method jwt.SigningMethod, now time.Time) error {
+	if err := q.DeleteExpiredSigningKeys(ctx, now); err != nil {
+		return err
+	}
+
+	activeAt := now
+	latest, err := q.GetLatestSigningKey(ctx, method.Alg())
+	switch {
+	case err == nil && latest.ActiveAt.After(now.Add(config.SigningKeyPrePublish-config.SigningKeyRotationPeriod)):
+		return nil // current key is still fresh, or its successor is already published
+	case err == nil:
+		activeAt = now.Add(config.SigningKeyPrePublish)
+	case errors.Is(err, sql.ErrNoRows):
+		// no key to keep signing with in the meantime, so the first one is active immediately
+	default:
+		return err
+	}
+
+	privateKey, err := generatePrivateKey(method)
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	return q.CreateSigningKey(ctx, repository.CreateSigningKeyParams{
+		Kid:        util.GenerateUUID(),
+		Algorithm:  method.Alg(),
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ActiveAt:   activeAt,
+		// signs for one rotation period, then verifies until the last tokens it signed have expired
+		ExpiresAt: activeAt.Add(config.SigningKeyRotationPeriod + config.SigningKeyGracePeriod),
+	})
+}
+
+func generatePrivateKey(method jwt.SigningMethod) (crypto.Signer, error) {
+	switch method.Alg() {
+	case jwt.SigningMethodRS256.Alg():
+		return rsa.GenerateKey(rand.Reader, config.RSAKeyBits)
+	case jwt.SigningMethodEdDSA.Alg():
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, fmt.Errorf("unsupported signing algorithm %q", method.Alg())
+}
+
+func parseSigningKey(kid string, algorithm string, privatePEM string) (SigningKey, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return SigningKey{}, fmt.Errorf("signing key %s is not PEM encoded", kid)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	method := jwt.GetSigningMethod(algorithm)
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if method != jwt.SigningMethodRS256 {
+			break
+		}
+		return SigningKey{ID: kid, Method: method, Private: key, Public: &key.PublicKey}, nil
+	case ed25519.PrivateKey:
+		if method != jwt.SigningMethodEdDSA {
+			break
+		}
+		return SigningKey{ID: kid, Method: method, Private: key, Public: key.Public()}, nil
+	}
+	return SigningKey{}, fmt.Errorf("signing key %s does not match algorithm %s", kid, algorithm)
+}
+
+func publicJWK(key SigningKey) (JSONWebKey, error) {
+	jwk := JSONWebKey{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JSONWebKey{}, fmt.Errorf("signing key %s has no public key to publish", key.ID)
+	}
+	return jwk, nil
+}
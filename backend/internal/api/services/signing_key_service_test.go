@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/db"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKeyService(t *testing.T, method jwt.SigningMethod) (*AsymmetricKeyService, repository.Querier) {
+	conn, closer, err := db.New(context.Background(), ":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(closer)
+	q := repository.New(conn)
+	return NewAsymmetricKeyService(q, method), q
+}
+
+func TestAsymmetricSigning(t *testing.T) {
+	ctx := context.Background()
+
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodRS256, jwt.SigningMethodEdDSA} {
+		t.Run(method.Alg(), func(t *testing.T) {
+			keys, _ := newTestKeyService(t, method)
+			authService := NewAuthService(keys)
+
+			tokenString, err := authService.GenerateToken(ctx, "user-id", "session-id")
+			assert.NoError(t, err)
+
+			token, err := authService.VerifyToken(ctx, tokenString)
+			assert.NoError(t, err)
+			assert.Equal(t, method.Alg(), token.Method.Alg())
+			kid, _ := token.Header["kid"].(string)
+			assert.NotEmpty(t, kid)
+
+			// the published key verifies the token on its own
+			jwks, err := keys.JWKS(ctx)
+			assert.NoError(t, err)
+			if !assert.Len(t, jwks.Keys, 1) {
+				return
+			}
+			assert.Equal(t, kid, jwks.Keys[0].Kid)
+			assert.Equal(t, method.Alg(), jwks.Keys[0].Alg)
+			publicKey, err := jwks.Keys[0].publicKey()
+			assert.NoError(t, err)
+			_, err = jwt.Parse(tokenString, func(*jwt.Token) (any, error) { return publicKey, nil })
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("Tokens signed with another algorithm are rejected", func(t *testing.T) {
+		keys, _ := newTestKeyService(t, jwt.SigningMethodEdDSA)
+		authService := NewAuthService(keys)
+		_, err := keys.SigningKey(ctx)
+		assert.NoError(t, err)
+
+		config.JWTSecret = []byte("test-secret")
+		hmacToken, err := NewAuthService(NewHMACKeyService()).GenerateToken(ctx, "user-id", "session-id")
+		assert.NoError(t, err)
+		_, err = authService.VerifyToken(ctx, hmacToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("HMAC publishes no keys", func(t *testing.T) {
+		jwks, err := NewHMACKeyService().JWKS(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, jwks.Keys)
+	})
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	keys, q := newTestKeyService(t, jwt.SigningMethodEdDSA)
+	authService := NewAuthService(keys)
+	start := time.Now()
+	now := start
+	keys.now = func() time.Time { return now }
+
+	oldToken, err := authService.GenerateToken(ctx, "user-id", "session-id")
+	assert.NoError(t, err)
+	first, err := keys.SigningKey(ctx)
+	assert.NoError(t, err)
+
+	// nothing to do while the key is fresh
+	assert.NoError(t, RotateSigningKeys(ctx, q, jwt.SigningMethodEdDSA, now))
+	rows, err := q.GetSigningKeys(ctx, now)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	t.Run("Next key is published before it signs", func(t *testing.T) {
+		now = start.Add(config.SigningKeyRotationPeriod - config.SigningKeyPrePublish)
+		assert.NoError(t, RotateSigningKeys(ctx, q, jwt.SigningMethodEdDSA, now))
+		now = now.Add(config.SigningKeyCacheDuration)
+
+		jwks, err := keys.JWKS(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, jwks.Keys, 2)
+
+		current, err := keys.SigningKey(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, current.ID)
+	})
+
+	t.Run("Next key signs after the pre-publish period", func(t *testing.T) {
+		now = start.Add(config.SigningKeyRotationPeriod + config.SigningKeyCacheDuration)
+
+		current, err := keys.SigningKey(ctx)
+		assert.NoError(t, err)
+		assert.NotEqual(t, first.ID, current.ID)
+
+		// tokens signed with the old key still verify during the grace period
+		_, err = authService.VerifyToken(ctx, oldToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Expired keys are removed", func(t *testing.T) {
+		now = start.Add(config.SigningKeyRotationPeriod + config.SigningKeyGracePeriod + time.Minute)
+		assert.NoError(t, RotateSigningKeys(ctx, q, jwt.SigningMethodEdDSA, now))
+		now = now.Add(config.SigningKeyCacheDuration)
+
+		jwks, err := keys.JWKS(ctx)
+		assert.NoError(t, err)
+		if !assert.Len(t, jwks.Keys, 1) {
+			return
+		}
+		assert.NotEqual(t, first.ID, jwks.Keys[0].Kid)
+
+		_, err = authService.VerifyToken(ctx, oldToken)
+		assert.ErrorIs(t, err, ErrUnknownSigningKey)
+	})
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/rhellwege/task-social/internal/db/repository"
+)
+
+// what a client sends over the WebSocket, as the event of a WebSocketMessage with a TopicRequest as the payload
+const (
+	WebSocketSubscribe   = "subscribe"
+	WebSocketUnsubscribe = "unsubscribe"
+)
+
+// how the server answers them
+const (
+	WebSocketSubscribed   = "subscribed"
+	WebSocketUnsubscribed = "unsubscribed"
+	WebSocketError        = "error"
+)
+
+var (
+	ErrInvalidWebSocketMessage = errors.New("messages must be a subscribe or unsubscribe event with a topic")
+	ErrUnknownTopic            = errors.New("unknown topic, use club:<id>:posts, club:<id>:members, club:<id>:auctions, metric:<id> or dm:<user id>")
+	ErrTopicNotAllowed         = errors.New("not allowed to subscribe to the topic")
+)
+
+// ClubPostsTopic carries new_post events
+func ClubPostsTopic(clubID string) string {
+	return "club:" + clubID + ":posts"
+}
+
+// ClubMembersTopic carries user_joined_club events
+func ClubMembersTopic(clubID string) string {
+	return "club:" + clubID + ":members"
+}
+
+// ClubAuctionsTopic carries the events of the club's auctions
+func ClubAuctionsTopic(clubID string) string {
+	return "club:" + clubID + ":auctions"
+}
+
+// MetricTopic carries new_metric_entry events
+func MetricTopic(metricID string) string {
+	return "metric:" + metricID
+}
+
+// DirectMessagesTopic carries the new_direct_message events of the conversation with the other user
+func DirectMessagesTopic(otherUserID string) string {
+	return "dm:" + otherUserID
+}
+
+type TopicRequest struct {
+	Topic string `json:"topic"`
+}
+
+type WebSocketErrorPayload struct {
+	// the topic of the request that failed, if it had one
+	Topic string `json:"topic,omitempty"`
+	Error string `json:"error"`
+}
+
+type SubscriptionServicer interface {
+	// HandleMessage acts on a message the client sent over one of its connections and answers on that connection
+	HandleMessage(ctx context.Context, userID string, connectionID string, data []byte)
+	// Subscribe checks that the user may see the topic before the connection gets its events
+	Subscribe(ctx context.Context, userID string, connectionID string, topic string) error
+	Unsubscribe(ctx context.Context, userID string, connectionID string, topic string)
+}
+
+type SubscriptionService struct {
+	q repository.Querier
+	w WebSocketServicer
+}
+
+var _ SubscriptionServicer = (*SubscriptionService)(nil)
+
+func NewSubscriptionService(q repository.Querier, w WebSocketServicer) *SubscriptionService {
+	return &SubscriptionService{q: q, w: w}
+}
+
+func (s *SubscriptionService) HandleMessage(ctx context.Context, userID string, connectionID string, data []byte) {
+	var message struct {
+		Event   string       `json:"event"`
+		Payload TopicRequest `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &message); err != nil || message.Payload.Topic == "" {
+		s.reply(ctx, userID, connectionID, WebSocketError, WebSocketErrorPayload{Error: ErrInvalidWebSocketMessage.Error()})
+		return
+	}
+	topic := message.Payload.Topic
+
+	switch message.Event {
+	case WebSocketSubscribe:
+		if err := s.Subscribe(ctx, userID, connectionID, topic); err != nil {
+			s.reply(ctx, userID, connectionID, WebSocketError, WebSocketErrorPayload{Topic: topic, Error: err.Error()})
+			return
+		}
+		s.reply(ctx, userID, connectionID, WebSocketSubscribed, TopicRequest{Topic: topic})
+	case WebSocketUnsubscribe:
+		s.Unsubscribe(ctx, userID, connectionID, topic)
+		s.reply(ctx, userID, connectionID, WebSocketUnsubscribed, TopicRequest{Topic: topic})
+	default:
+		s.reply(ctx, userID, connectionID, WebSocketError, WebSocketErrorPayload{Topic: topic, Error: ErrInvalidWebSocketMessage.Error()})
+	}
+}
+
+func (s *SubscriptionService) Subscribe(ctx context.Context, userID string, connectionID string, topic string) error {
+	allowed, err := s.mayReceive(ctx, userID, topic)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrTopicNotAllowed
+	}
+	return s.w.Subscribe(ctx, userID, connectionID, topic)
+}
+
+// mayReceive is whether the user may see the topic's events, conversations are open to their two users
+// and everything else to the members of a club
+func (s *SubscriptionService) mayReceive(ctx context.Context, userID string, topic string) (bool, error) {
+	if otherID, ok := strings.CutPrefix(topic, "dm:"); ok && otherID != "" && !strings.Contains(otherID, ":") {
+		return inConversation(ctx, s.q, userID, otherID)
+	}
+	clubID, err := s.topicClub(ctx, topic)
+	if err != nil {
+		return false, err
+	}
+	isMember, err := s.q.IsUserMemberOfClub(ctx, repository.IsUserMemberOfClubParams{
+		UserID: userID,
+		ClubID: clubID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return isMember != 0, nil
+}
+
+func (s *SubscriptionService) Unsubscribe(ctx context.Context, userID string, connectionID string, topic string) {
+	s.w.Unsubscribe(ctx, userID, connectionID, topic)
+}
+
+// topicClub returns the club whose members may subscribe to the topic
+func (s *SubscriptionService) topicClub(ctx context.Context, topic string) (string, error) {
+	parts := strings.Split(topic, ":")
+	switch {
+	case len(parts) == 3 && parts[0] == "club" && parts[1] != "" &&
+		(parts[2] == "posts" || parts[2] == "members" || parts[2] == "auctions"):
+		return parts[1], nil
+	case len(parts) == 2 && parts[0] == "metric" && parts[1] != "":
+		metric, err := s.q.GetMetric(ctx, parts[1])
+		if errors.Is(err, sql.ErrNoRows) {
+			// the same answer as for a metric of a club the user is not in
+			return "", ErrTopicNotAllowed
+		}
+		if err != nil {
+			return "", err
+		}
+		return metric.ClubID, nil
+	}
+	return "", ErrUnknownTopic
+}
+
+// reply answers on the connection the message came from, failures are only logged
+func (s *SubscriptionService) reply(ctx context.Context, userID string, connectionID string, event string, payload interface{}) {
+	jsonBytes, err := json.Marshal(WebSocketMessage{
+		Event:   event,
+		Payload: payload,
+	})
+	if err != nil {
+		log.Printf("Failed to encode %s: %v", event, err)
+		return
+	}
+	if err := s.w.SendToConnection(ctx, userID, connectionID, string(jsonBytes)); err != nil {
+		log.Printf("Failed to answer connection %s of user %s: %v", connectionID, userID, err)
+	}
+}
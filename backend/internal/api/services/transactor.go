@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/rhellwege/task-social/internal/db/repository"
+)
+
+// Transactor runs changes that must succeed or fail together
+type Transactor interface {
+	// fn gets a querier bound to one transaction, which is committed if fn returns nil and rolled back otherwise.
+	// Only use the given querier inside fn, other queries are not part of the transaction.
+	WithTx(ctx context.Context, fn func(q repository.Querier) error) error
+}
+
+type SQLTransactor struct {
+	db *sql.DB
+}
+
+var _ Transactor = (*SQLTransactor)(nil)
+
+func NewTransactor(db *sql.DB) *SQLTransactor {
+	return &SQLTransactor{db: db}
+}
+
+func (t *SQLTransactor) WithTx(ctx context.Context, fn func(q repository.Querier) error) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(repository.New(tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
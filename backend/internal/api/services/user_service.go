@@ -2,17 +2,34 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/rhellwege/task-social/config"
 	"github.com/rhellwege/task-social/internal/db/repository"
 	"github.com/rhellwege/task-social/internal/util"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 type UserServicer interface {
-	RegisterUser(ctx context.Context, username string, email string, password string) (string, error)
-	LoginUser(ctx context.Context, username *string, email *string, password string) (string, error)
+	RegisterUser(ctx context.Context, username string, email string, password string, client ClientInfo) (AuthTokens, error)
+	// when two factor auth is enabled the result only carries an MFA token, see CompleteMFALogin
+	LoginUser(ctx context.Context, username *string, email *string, password string, client ClientInfo) (LoginResult, error)
+	// logs in a user whose identity was already proven another way, e.g. by an identity provider.
+	// two factor auth still applies
+	LoginUserByID(ctx context.Context, userID string, client ClientInfo) (LoginResult, error)
+	// second step of a two factor login, accepts a TOTP code or an unused recovery code
+	CompleteMFALogin(ctx context.Context, mfaToken string, code string, client ClientInfo) (AuthTokens, error)
 	GetUserDisplay(ctx context.Context, userID string) (repository.GetUserDisplayRow, error)
 	GetUserClubs(ctx context.Context, userID string) ([]repository.GetUserClubsRow, error)
 	CreateFriend(ctx context.Context, userID string, friendID string) error
@@ -22,30 +39,108 @@ type UserServicer interface {
 	GetUserMetrics(ctx context.Context, userID string) ([]repository.Metric, error)
 	GetUserMetricEntries(ctx context.Context, userID string) ([]repository.MetricEntry, error)
 	GetItemsByOwner(ctx context.Context, ownerID string) ([]repository.Item, error)
+	IsEmailVerified(ctx context.Context, userID string) (bool, error)
+	SendVerificationEmail(ctx context.Context, userID string) error
+	VerifyEmail(ctx context.Context, token string) error
+	// mails the link in the background, so neither the response nor its timing shows whether the account exists
+	RequestPasswordReset(ctx context.Context, email string)
+	ResetPassword(ctx context.Context, token string, newPassword string) error
+	// stores a new TOTP secret, two factor auth stays off until EnableTOTP confirms a code from it
+	EnrollTOTP(ctx context.Context, userID string) (TOTPEnrollment, error)
+	// returns the recovery codes, they are only shown this once
+	EnableTOTP(ctx context.Context, userID string, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID string, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error)
 }
 
 type UserService struct {
-	q repository.Querier
-	a AuthServicer
-	i ImageServicer
+	q  repository.Querier
+	a  AuthServicer
+	i  ImageServicer
+	s  SessionServicer
+	m  Mailer
+	l  LoginAttemptServicer
+	f  ContentFilter
+	tx Transactor
+
+	// compared against when the account does not exist so the response takes as long as a wrong password
+	dummyPasswordHash func() string
 }
 
 // compile time interface implementation check
 var _ UserServicer = (*UserService)(nil)
 
-func NewUserService(q repository.Querier, a AuthServicer, i ImageServicer) *UserService {
-	return &UserService{q: q, a: a, i: i}
+func NewUserService(q repository.Querier, a AuthServicer, i ImageServicer, s SessionServicer, m Mailer, l LoginAttemptServicer, f ContentFilter, tx Transactor) *UserService {
+	return &UserService{
+		q: q, a: a, i: i, s: s, m: m, l: l, f: f, tx: tx,
+		dummyPasswordHash: sync.OnceValue(func() string {
+			hash, _ := a.HashPassword(context.Background(), util.GenerateUUID())
+			return hash
+		}),
+	}
+}
+
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+	TokenPurposeDeleteAccount = "delete_account"
+)
+
+var ErrInvalidEmailToken = errors.New("invalid or expired token")
+
+var ErrInvalidMFACode = errors.New("invalid two factor code")
+
+var ErrAccountDisabled = errors.New("this account has been disabled, contact support")
+
+// a taken username and a taken email get the same answer, registering is throttled per IP on top
+var ErrRegistrationUnavailable = errors.New("this username or email cannot be used, choose another or log in")
+
+// LoginResult holds either a full token pair or, if the user has two factor auth enabled, an MFA token
+type LoginResult struct {
+	AuthTokens
+	MFAToken string
+}
+
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+func (s *UserService) RegisterUser(ctx context.Context, username string, password string, email string, client ClientInfo) (AuthTokens, error) {
+	if err := s.l.CheckRegister(ctx, client); err != nil {
+		return AuthTokens{}, err
+	}
+	tokens, err := s.registerUser(ctx, username, password, email, client)
+
+	// every attempt counts towards the limit, failed ones included
+	attempt := LoginAttempt{
+		Action:     LoginActionRegister,
+		Account:    LoginAccountKey("", username),
+		Identifier: username,
+		Succeeded:  err == nil,
+	}
+	if err != nil {
+		attempt.FailureReason = err.Error()
+	}
+	s.recordAttempt(ctx, attempt, client)
+
+	return tokens, err
 }
 
-func (s *UserService) RegisterUser(ctx context.Context, username string, password string, email string) (string, error) {
+func (s *UserService) registerUser(ctx context.Context, username string, password string, email string, client ClientInfo) (AuthTokens, error) {
+	// usernames are shown right away, so anything the filter would hold is rejected
+	if _, err := filterContent(ctx, s.f, Content{Kind: ContentKindUsername, Text: username}); err != nil {
+		return AuthTokens{}, err
+	}
+
 	// password check
 	err := s.a.ValidatePasswordStrength(ctx, password)
 	if err != nil {
-		return "", err
+		return AuthTokens{}, err
 	}
 	hashedPassword, err := s.a.HashPassword(ctx, password)
 	if err != nil {
-		return "", err
+		return AuthTokens{}, err
 	}
 
 	userID := util.GenerateUUID()
@@ -57,53 +152,183 @@ func (s *UserService) RegisterUser(ctx context.Context, username string, passwor
 		Password: hashedPassword,
 	}
 	err = s.q.CreateUser(ctx, params)
+	if isUniqueViolation(err) {
+		return AuthTokens{}, ErrRegistrationUnavailable
+	}
 	if err != nil {
-		return "", err
+		return AuthTokens{}, err
 	}
 
-	tokenString, err := s.a.GenerateToken(ctx, userID)
-	if err != nil {
-		return "", err
+	// the user can request another mail later, so do not fail the registration over it
+	if err := s.SendVerificationEmail(ctx, userID); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", userID, err)
 	}
 
-	return tokenString, nil
+	return s.s.CreateSession(ctx, userID, client)
 }
 
 // either username or email is required
-func (s *UserService) LoginUser(ctx context.Context, username *string, email *string, password string) (string, error) {
+func (s *UserService) LoginUser(ctx context.Context, username *string, email *string, password string, client ClientInfo) (LoginResult, error) {
 	var hashedPassword string
 	var userID string
+	var identifier string
 	var err error
 
 	if username == nil && email == nil {
-		return "", errors.New("username or email is required")
+		return LoginResult{}, errors.New("username or email is required")
 	}
 
 	if username != nil {
+		identifier = *username
 		login, e := s.q.GetUserLoginByUsername(ctx, *username)
 		hashedPassword = login.Password
 		userID = login.ID
 		err = e
 	} else if email != nil {
+		identifier = *email
 		login, e := s.q.GetUserLoginByEmail(ctx, *email)
 		hashedPassword = login.Password
 		userID = login.ID
 		err = e
 	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return LoginResult{}, err
+	}
+
+	attempt := LoginAttempt{
+		Action:     LoginActionLogin,
+		Account:    LoginAccountKey(userID, identifier),
+		Identifier: identifier,
+	}
+	if userID != "" {
+		attempt.UserID = &userID
+	}
+	attemptID, err := s.l.StartLogin(ctx, attempt, client)
 	if err != nil {
-		return "", errors.New("Could not find user with provided credentials")
+		return LoginResult{}, err
+	}
+
+	if userID == "" {
+		s.a.VerifyPassword(ctx, password, s.dummyPasswordHash())
+		attempt.FailureReason = "unknown account"
+		s.finishAttempt(ctx, attemptID, attempt)
+		return LoginResult{}, ErrInvalidCredentials
 	}
 
 	if err := s.a.VerifyPassword(ctx, password, hashedPassword); err != nil {
-		return "", err
+		attempt.FailureReason = "wrong password"
+		s.finishAttempt(ctx, attemptID, attempt)
+		return LoginResult{}, ErrInvalidCredentials
 	}
 
-	tokenString, err := s.a.GenerateToken(ctx, userID)
+	result, err := s.LoginUserByID(ctx, userID, client)
+	if errors.Is(err, ErrAccountDisabled) {
+		attempt.FailureReason = "account disabled"
+		s.finishAttempt(ctx, attemptID, attempt)
+		return LoginResult{}, err
+	}
 	if err != nil {
-		return "", err
+		s.cancelAttempt(ctx, attemptID)
+		return LoginResult{}, err
+	}
+	// with two factor auth the login only counts as successful once the code is checked,
+	// otherwise knowing the password would reset the limit on guessing codes
+	if result.MFAToken != "" {
+		s.cancelAttempt(ctx, attemptID)
+		return result, nil
+	}
+	attempt.Succeeded = true
+	s.finishAttempt(ctx, attemptID, attempt)
+	return result, nil
+}
+
+func (s *UserService) LoginUserByID(ctx context.Context, userID string, client ClientInfo) (LoginResult, error) {
+	if err := s.checkNotDisabled(ctx, userID); err != nil {
+		return LoginResult{}, err
+	}
+
+	totp, err := s.q.GetUserTOTP(ctx, userID)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	if totp.TotpEnabledAt != nil {
+		mfaToken, err := s.a.GenerateMFAToken(ctx, userID)
+		if err != nil {
+			return LoginResult{}, err
+		}
+		return LoginResult{MFAToken: mfaToken}, nil
+	}
+
+	tokens, err := s.s.CreateSession(ctx, userID, client)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	return LoginResult{AuthTokens: tokens}, nil
+}
+
+func (s *UserService) CompleteMFALogin(ctx context.Context, mfaToken string, code string, client ClientInfo) (AuthTokens, error) {
+	userID, err := s.a.VerifyMFAToken(ctx, mfaToken)
+	if err != nil {
+		return AuthTokens{}, err
+	}
+
+	attempt := LoginAttempt{
+		Action:     LoginActionMFA,
+		Account:    LoginAccountKey(userID, ""),
+		Identifier: userID,
+		UserID:     &userID,
 	}
+	attemptID, err := s.l.StartLogin(ctx, attempt, client)
+	if err != nil {
+		return AuthTokens{}, err
+	}
+	if err := s.verifySecondFactor(ctx, userID, code, true); err != nil {
+		attempt.FailureReason = err.Error()
+		s.finishAttempt(ctx, attemptID, attempt)
+		return AuthTokens{}, err
+	}
+
+	// an admin may have disabled the account after the password step
+	if err := s.checkNotDisabled(ctx, userID); err != nil {
+		attempt.FailureReason = "account disabled"
+		s.finishAttempt(ctx, attemptID, attempt)
+		return AuthTokens{}, err
+	}
+
+	attempt.Succeeded = true
+	s.finishAttempt(ctx, attemptID, attempt)
+	return s.s.CreateSession(ctx, userID, client)
+}
+
+func (s *UserService) checkNotDisabled(ctx context.Context, userID string) error {
+	disabledAt, err := s.q.GetUserDisabledAt(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if disabledAt != nil {
+		return ErrAccountDisabled
+	}
+	return nil
+}
+
+// recordAttempt only logs failures, a broken audit trail should not lock everyone out
+func (s *UserService) recordAttempt(ctx context.Context, attempt LoginAttempt, client ClientInfo) {
+	if err := s.l.RecordAttempt(ctx, attempt, client); err != nil {
+		log.Printf("Failed to record %s attempt for %s: %v", attempt.Action, attempt.Identifier, err)
+	}
+}
 
-	return tokenString, nil
+// finishAttempt and cancelAttempt only log failures like recordAttempt, the attempt then stays a failure
+func (s *UserService) finishAttempt(ctx context.Context, attemptID string, attempt LoginAttempt) {
+	if err := s.l.FinishLogin(ctx, attemptID, attempt); err != nil {
+		log.Printf("Failed to record %s attempt for %s: %v", attempt.Action, attempt.Identifier, err)
+	}
+}
+
+func (s *UserService) cancelAttempt(ctx context.Context, attemptID string) {
+	if err := s.l.CancelLogin(ctx, attemptID); err != nil {
+		log.Printf("Failed to cancel login attempt %s: %v", attemptID, err)
+	}
 }
 
 func (s *UserService) GetUserDisplay(ctx context.Context, userID string) (repository.GetUserDisplayRow, error) {
@@ -127,6 +352,12 @@ func (s *UserService) GetFriends(ctx context.Context, uuid string) ([]repository
 }
 
 func (s *UserService) UpdateUser(ctx context.Context, params repository.UpdateUserParams) error {
+	if params.Username != nil {
+		if _, err := filterContent(ctx, s.f, Content{Kind: ContentKindUsername, AuthorID: params.ID, Text: *params.Username}); err != nil {
+			return err
+		}
+	}
+
 	// hash new password if provided
 	if params.Password != nil {
 		err := s.a.ValidatePasswordStrength(ctx, *params.Password)
@@ -140,7 +371,20 @@ func (s *UserService) UpdateUser(ctx context.Context, params repository.UpdateUs
 		params.Password = &hashed
 	}
 
-	return s.q.UpdateUser(ctx, params)
+	if err := s.q.UpdateUser(ctx, params); err != nil {
+		return err
+	}
+
+	// a new address has to be verified again
+	if params.Email != nil {
+		if err := s.q.ClearUserEmailVerified(ctx, params.ID); err != nil {
+			return err
+		}
+		if err := s.SendVerificationEmail(ctx, params.ID); err != nil {
+			log.Printf("Failed to send verification email to user %s: %v", params.ID, err)
+		}
+	}
+	return nil
 }
 
 func (s *UserService) GetUserClubs(ctx context.Context, userID string) ([]repository.GetUserClubsRow, error) {
@@ -189,3 +433,331 @@ func (s *UserService) GetUserMetricEntries(ctx context.Context, userID string) (
 func (s *UserService) GetItemsByOwner(ctx context.Context, ownerID string) ([]repository.Item, error) {
 	return s.q.GetItemsByOwner(ctx, ownerID)
 }
+
+func (s *UserService) IsEmailVerified(ctx context.Context, userID string) (bool, error) {
+	user, err := s.q.GetUserEmail(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerifiedAt != nil, nil
+}
+
+func (s *UserService) SendVerificationEmail(ctx context.Context, userID string) error {
+	user, err := s.q.GetUserEmail(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return errors.New("email is already verified")
+	}
+
+	token, err := issueUserToken(ctx, s.q, userID, TokenPurposeVerifyEmail, config.VerifyEmailTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	return s.m.Send(ctx, Email{
+		To:      user.Email,
+		Subject: "Verify your Task Social email address",
+		Body: fmt.Sprintf("Welcome to Task Social!\n\nConfirm your email address by opening this link:\n%s\n\nThe link expires in %s.\n",
+			emailLink("verify-email", token), config.VerifyEmailTokenDuration),
+	})
+}
+
+func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := consumeUserToken(ctx, s.q, token, TokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+	return s.q.SetUserEmailVerified(ctx, userID)
+}
+
+func (s *UserService) RequestPasswordReset(ctx context.Context, email string) {
+	// the request's context and buffers are reused once the response is sent
+	email = strings.Clone(email)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.MailSendTimeout)
+		defer cancel()
+		if err := s.sendPasswordReset(ctx, email); err != nil {
+			log.Printf("Failed to send a password reset email: %v", err)
+		}
+	}()
+}
+
+func (s *UserService) sendPasswordReset(ctx context.Context, email string) error {
+	userID, err := s.q.GetUserIDByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := issueUserToken(ctx, s.q, userID, TokenPurposeResetPassword, config.ResetPasswordTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	return s.m.Send(ctx, Email{
+		To:      email,
+		Subject: "Reset your Task Social password",
+		Body: fmt.Sprintf("Someone asked to reset the password for this account.\n\nChoose a new password by opening this link:\n%s\n\nThe link expires in %s. If this was not you, you can ignore this email.\n",
+			emailLink("reset-password", token), config.ResetPasswordTokenDuration),
+	})
+}
+
+func (s *UserService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	if err := s.a.ValidatePasswordStrength(ctx, newPassword); err != nil {
+		return err
+	}
+
+	userID, err := consumeUserToken(ctx, s.q, token, TokenPurposeResetPassword)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := s.a.HashPassword(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.q.UpdateUser(ctx, repository.UpdateUserParams{ID: userID, Password: &hashed}); err != nil {
+		return err
+	}
+
+	// the reset link proves the user controls the mailbox
+	if err := s.q.SetUserEmailVerified(ctx, userID); err != nil {
+		return err
+	}
+
+	// whoever knew the old password must not stay logged in, or keep a token they created
+	if err := s.q.RevokeAllPersonalAccessTokens(ctx, userID); err != nil {
+		return err
+	}
+	return s.s.RevokeAllSessions(ctx, userID)
+}
+
+// issueUserToken invalidates older tokens with the same purpose and returns a new one
+func issueUserToken(ctx context.Context, q repository.Querier, userID string, purpose string, ttl time.Duration) (string, error) {
+	err := q.InvalidateUserTokens(ctx, repository.InvalidateUserTokensParams{
+		UserID:  userID,
+		Purpose: purpose,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	token, err := util.GenerateSecureToken(config.EmailTokenBytes)
+	if err != nil {
+		return "", err
+	}
+
+	err = q.CreateUserToken(ctx, repository.CreateUserTokenParams{
+		TokenHash: util.HashToken(token),
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func consumeUserToken(ctx context.Context, q repository.Querier, token string, purpose string) (string, error) {
+	row, err := q.ConsumeUserToken(ctx, repository.ConsumeUserTokenParams{
+		TokenHash: util.HashToken(token),
+		Purpose:   purpose,
+	})
+	if err != nil {
+		return "", ErrInvalidEmailToken
+	}
+	if row.ExpiresAt.Before(time.Now()) {
+		return "", ErrInvalidEmailToken
+	}
+	return row.UserID, nil
+}
+
+func emailLink(path string, token string) string {
+	return fmt.Sprintf("%s/%s?token=%s", config.AppURL, path, url.QueryEscape(token))
+}
+
+func CleanUpExpiredUserTokens(ctx context.Context, q repository.Querier) error {
+	return q.DeleteExpiredUserTokens(ctx, time.Now())
+}
+
+func (s *UserService) EnrollTOTP(ctx context.Context, userID string) (TOTPEnrollment, error) {
+	totp, err := s.q.GetUserTOTP(ctx, userID)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	if totp.TotpEnabledAt != nil {
+		return TOTPEnrollment{}, errors.New("two factor auth is already enabled")
+	}
+
+	secret, err := s.a.GenerateTOTPSecret(ctx)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	err = s.q.SetUserTOTPSecret(ctx, repository.SetUserTOTPSecretParams{
+		ID:         userID,
+		TotpSecret: &secret,
+	})
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+
+	return TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: s.a.TOTPProvisioningURI(ctx, secret, totp.Username),
+	}, nil
+}
+
+func (s *UserService) EnableTOTP(ctx context.Context, userID string, code string) ([]string, error) {
+	totp, err := s.q.GetUserTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if totp.TotpEnabledAt != nil {
+		return nil, errors.New("two factor auth is already enabled")
+	}
+	if totp.TotpSecret == nil {
+		return nil, errors.New("two factor auth enrollment has not been started")
+	}
+
+	step, err := s.a.VerifyTOTP(ctx, *totp.TotpSecret, code)
+	if err != nil {
+		return nil, ErrInvalidMFACode
+	}
+	// two factor auth is only turned on together with the recovery codes the user is shown
+	var codes []string
+	err = s.tx.WithTx(ctx, func(q repository.Querier) error {
+		if _, err := q.UpdateUserTOTPLastStep(ctx, repository.UpdateUserTOTPLastStepParams{ID: userID, Step: &step}); err != nil {
+			return err
+		}
+		if err := q.EnableUserTOTP(ctx, userID); err != nil {
+			return err
+		}
+		codes, err = createRecoveryCodes(ctx, q, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *UserService) DisableTOTP(ctx context.Context, userID string, code string) error {
+	if err := s.verifySecondFactor(ctx, userID, code, true); err != nil {
+		return err
+	}
+	return s.tx.WithTx(ctx, func(q repository.Querier) error {
+		if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+			return err
+		}
+		return q.DisableUserTOTP(ctx, userID)
+	})
+}
+
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error) {
+	// a recovery code cannot be used to mint new recovery codes
+	if err := s.verifySecondFactor(ctx, userID, code, false); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := s.tx.WithTx(ctx, func(q repository.Querier) error {
+		var err error
+		codes, err = createRecoveryCodes(ctx, q, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verifySecondFactor checks a TOTP code, or a recovery code when allowRecovery is set.
+// Both are single use: a TOTP step can only be accepted once and recovery codes are marked as used.
+func (s *UserService) verifySecondFactor(ctx context.Context, userID string, code string, allowRecovery bool) error {
+	totp, err := s.q.GetUserTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if totp.TotpEnabledAt == nil || totp.TotpSecret == nil {
+		return errors.New("two factor auth is not enabled")
+	}
+
+	if step, err := s.a.VerifyTOTP(ctx, *totp.TotpSecret, code); err == nil {
+		rows, err := s.q.UpdateUserTOTPLastStep(ctx, repository.UpdateUserTOTPLastStepParams{ID: userID, Step: &step})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	if !allowRecovery {
+		return ErrInvalidMFACode
+	}
+	rows, err := s.q.UseUserRecoveryCode(ctx, repository.UseUserRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: util.HashToken(normalizeRecoveryCode(code)),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// createRecoveryCodes replaces all recovery codes of the user and returns the new ones in plain text.
+// Run it in a transaction so the user never ends up with only some of the codes they were shown.
+func createRecoveryCodes(ctx context.Context, q repository.Querier, userID string) ([]string, error) {
+	if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, config.RecoveryCodeCount)
+	for range config.RecoveryCodeCount {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		err = q.CreateUserRecoveryCode(ctx, repository.CreateUserRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: util.HashToken(normalizeRecoveryCode(code)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// recovery codes look like "abcde-fghij" so they are easy to write down
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := recoveryCodeEncoding.EncodeToString(b)[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode ignores case, spaces and dashes the user may or may not type
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
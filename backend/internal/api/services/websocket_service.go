@@ -3,80 +3,180 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/util"
 )
 
 // WebSocketMessage defines the structure for messages sent over the WebSocket.
 type WebSocketMessage struct {
-	Event   string      `json:"event"`
+	Event string `json:"event"`
+	// set on events that are only sent to connections subscribed to the topic
+	Topic   string      `json:"topic,omitempty"`
 	Payload interface{} `json:"payload"`
 }
 
+var (
+	ErrRecipientNotFound    = errors.New("recipient not found")
+	ErrWebSocketQueueFull   = errors.New("the connection's send queue is full")
+	ErrTooManySubscriptions = fmt.Errorf("a connection can subscribe to at most %d topics", config.MaxWebSocketSubscriptions)
+)
+
 type WebSocketServicer interface {
-	AddConnection(ctx context.Context, userID string, conn *websocket.Conn, jwt *jwt.Token)
-	RemoveConnection(ctx context.Context, userID string)
-	GetConnection(ctx context.Context, userID string) (*websocket.Conn, bool)
+	// a user can be connected from several devices at once, each connection gets its own ID
+	AddConnection(ctx context.Context, userID string, conn *websocket.Conn, jwt *jwt.Token) string
+	// waits until nothing is written to the connection anymore
+	RemoveConnection(ctx context.Context, userID string, connectionID string)
+	GetConnectionIDs(ctx context.Context, userID string) []string
 	CleanUpExpiredConnections(ctx context.Context)
+	// queues the message for every connection of the recipients without waiting for it to be written.
+	// a connection that cannot take it does not stop the others, the errors are joined
 	BroadcastMessage(ctx context.Context, recipients []string, message string) error
+	// like BroadcastMessage, but only for the recipients' connections subscribed to the topic
+	PublishMessage(ctx context.Context, recipients []string, topic string, message string) error
+	SendToConnection(ctx context.Context, userID string, connectionID string, message string) error
+	// the caller checks that the user may see the topic
+	Subscribe(ctx context.Context, userID string, connectionID string, topic string) error
+	Unsubscribe(ctx context.Context, userID string, connectionID string, topic string)
 }
 
 var _ WebSocketServicer = (*WebSocketService)(nil)
 
+// SlowClientPolicy decides what happens to a message for a connection whose send queue is full
+type SlowClientPolicy int
+
+const (
+	// the message is lost for that connection, the client catches up over the API
+	DropMessages SlowClientPolicy = iota
+	// the connection is closed, the client reconnects and catches up over the API
+	DisconnectSlowClients
+)
+
+// messageWriter is the part of *websocket.Conn the service writes with
+type messageWriter interface {
+	WriteMessage(messageType int, data []byte) error
+	SetWriteDeadline(t time.Time) error
+	Close() error
+}
+
 type WebSocketConnection struct {
-	Conn *websocket.Conn
-	JWT  *jwt.Token
+	ID  string
+	JWT *jwt.Token
+
+	conn messageWriter
+	// guarded by the service's mutex
+	topics map[string]struct{}
+	// messages waiting to be written by the connection's own goroutine, so a slow client only holds up itself
+	send      chan []byte
+	closed    chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// close stops the connection's writes, the handler then removes it when its read fails
+func (c *WebSocketConnection) close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.conn.Close()
+	})
+}
+
+func (c *WebSocketConnection) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
 }
 
 type WebSocketService struct {
-	connections map[string]WebSocketConnection
-	mu          sync.Mutex
+	// the connections of each user by their ID
+	connections map[string]map[string]*WebSocketConnection
+	// only held to look up and queue, never while writing
+	mu        sync.Mutex
+	policy    SlowClientPolicy
+	queueSize int
 }
 
-func NewWebSocketService() *WebSocketService {
+func NewWebSocketService(policy SlowClientPolicy) *WebSocketService {
 	return &WebSocketService{
-		connections: make(map[string]WebSocketConnection),
+		connections: make(map[string]map[string]*WebSocketConnection),
+		policy:      policy,
+		queueSize:   config.WebSocketSendQueueSize,
 	}
 }
 
-func (s *WebSocketService) AddConnection(ctx context.Context, userID string, conn *websocket.Conn, jwt *jwt.Token) {
+func (s *WebSocketService) AddConnection(ctx context.Context, userID string, conn *websocket.Conn, jwt *jwt.Token) string {
+	return s.addConnection(userID, conn, jwt)
+}
+
+func (s *WebSocketService) addConnection(userID string, conn messageWriter, jwt *jwt.Token) string {
+	c := &WebSocketConnection{
+		ID:      util.GenerateUUID(),
+		JWT:     jwt,
+		conn:    conn,
+		topics:  make(map[string]struct{}),
+		send:    make(chan []byte, s.queueSize),
+		closed:  make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go s.writeLoop(userID, c)
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.connections[userID] = WebSocketConnection{Conn: conn, JWT: jwt}
+	if s.connections[userID] == nil {
+		s.connections[userID] = make(map[string]*WebSocketConnection)
+	}
+	s.connections[userID][c.ID] = c
+	return c.ID
 }
 
-func (s *WebSocketService) RemoveConnection(ctx context.Context, userID string) {
+// RemoveConnection only removes the given connection, the user's other devices stay connected
+func (s *WebSocketService) RemoveConnection(ctx context.Context, userID string, connectionID string) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	delete(s.connections, userID)
+	c, ok := s.connections[userID][connectionID]
+	delete(s.connections[userID], connectionID)
+	if len(s.connections[userID]) == 0 {
+		delete(s.connections, userID)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		c.close()
+		<-c.stopped
+	}
 }
 
-func (s *WebSocketService) GetConnection(ctx context.Context, userID string) (*websocket.Conn, bool) {
+func (s *WebSocketService) GetConnectionIDs(ctx context.Context, userID string) []string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	conn, ok := s.connections[userID]
-	if !ok {
-		return nil, false
+	ids := make([]string, 0, len(s.connections[userID]))
+	for id := range s.connections[userID] {
+		ids = append(ids, id)
 	}
-	return conn.Conn, true
+	return ids
 }
 
 func (s *WebSocketService) CleanUpExpiredConnections(ctx context.Context) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	for userID, connData := range s.connections {
-		expTime, err := connData.JWT.Claims.GetExpirationTime()
-		if err != nil || expTime == nil {
-			log.Errorf("Error getting expiration time for user %s: %v", userID, err)
-			continue
-		}
-		if expTime.Before(time.Now()) {
-			delete(s.connections, userID)
-			connData.Conn.Close()
+	for userID, userConnections := range s.connections {
+		for _, c := range userConnections {
+			expTime, err := c.JWT.Claims.GetExpirationTime()
+			if err != nil || expTime == nil {
+				log.Errorf("Error getting expiration time for user %s: %v", userID, err)
+				continue
+			}
+			if expTime.Before(time.Now()) {
+				c.close()
+			}
 		}
 	}
 }
@@ -84,47 +184,122 @@ func (s *WebSocketService) CleanUpExpiredConnections(ctx context.Context) {
 func (s *WebSocketService) BroadcastMessage(ctx context.Context, recipients []string, message string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	var errs []error
 	for _, recipient := range recipients {
-		connData, ok := s.connections[recipient]
-		if !ok {
-			continue
-		}
-		if err := connData.Conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
-			connData.Conn.Close()
-			log.Errorf("Error sending message to %s: %v", recipient, err)
-			delete(s.connections, recipient)
-			return err
+		for _, c := range s.connections[recipient] {
+			errs = append(errs, s.enqueue(recipient, c, message))
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 func (s *WebSocketService) BroadcastGlobalMessage(ctx context.Context, message string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	for userID, connData := range s.connections {
-		if err := connData.Conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
-			connData.Conn.Close()
-			log.Errorf("Error sending message to %s: %v", userID, err)
-			delete(s.connections, userID)
-			return err
+	var errs []error
+	for userID, userConnections := range s.connections {
+		for _, c := range userConnections {
+			errs = append(errs, s.enqueue(userID, c, message))
 		}
 	}
+	return errors.Join(errs...)
+}
+
+func (s *WebSocketService) PublishMessage(ctx context.Context, recipients []string, topic string, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for _, recipient := range recipients {
+		for _, c := range s.connections[recipient] {
+			if _, ok := c.topics[topic]; ok {
+				errs = append(errs, s.enqueue(recipient, c, message))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *WebSocketService) Subscribe(ctx context.Context, userID string, connectionID string, topic string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.connections[userID][connectionID]
+	if !ok {
+		return ErrRecipientNotFound
+	}
+	if _, ok := c.topics[topic]; !ok && len(c.topics) >= config.MaxWebSocketSubscriptions {
+		return ErrTooManySubscriptions
+	}
+	c.topics[topic] = struct{}{}
 	return nil
 }
 
+func (s *WebSocketService) Unsubscribe(ctx context.Context, userID string, connectionID string, topic string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.connections[userID][connectionID]; ok {
+		delete(c.topics, topic)
+	}
+}
+
+// SendMessage sends the message to every device the recipient is connected from
 func (s *WebSocketService) SendMessage(ctx context.Context, recipient string, message string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	connData, ok := s.connections[recipient]
+	userConnections, ok := s.connections[recipient]
 	if !ok {
-		return errors.New("recipient not found")
+		return ErrRecipientNotFound
 	}
-	if err := connData.Conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
-		connData.Conn.Close()
-		log.Errorf("Error sending message to %s: %v", recipient, err)
-		delete(s.connections, recipient)
-		return err
+	var errs []error
+	for _, c := range userConnections {
+		errs = append(errs, s.enqueue(recipient, c, message))
+	}
+	return errors.Join(errs...)
+}
+
+// SendToConnection sends the message to one of the user's devices, e.g. to answer something it sent
+func (s *WebSocketService) SendToConnection(ctx context.Context, userID string, connectionID string, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.connections[userID][connectionID]
+	if !ok {
+		return ErrRecipientNotFound
+	}
+	return s.enqueue(userID, c, message)
+}
+
+// enqueue never blocks, a full queue is handled by the policy. closed connections are skipped. s.mu must be held
+func (s *WebSocketService) enqueue(userID string, c *WebSocketConnection, message string) error {
+	if c.isClosed() {
+		return nil
+	}
+	select {
+	case c.send <- []byte(message):
+		return nil
+	default:
+	}
+	if s.policy == DisconnectSlowClients {
+		log.Warnf("Disconnecting connection %s of user %s, it is not keeping up", c.ID, userID)
+		c.close()
+	} else {
+		log.Warnf("Dropping a message for connection %s of user %s, it is not keeping up", c.ID, userID)
+	}
+	return fmt.Errorf("%w: connection %s of user %s", ErrWebSocketQueueFull, c.ID, userID)
+}
+
+// writeLoop writes the connection's queued messages until it is closed or a write fails
+func (s *WebSocketService) writeLoop(userID string, c *WebSocketConnection) {
+	defer close(c.stopped)
+	for {
+		select {
+		case <-c.closed:
+			return
+		case message := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(config.WebSocketWriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				log.Errorf("Error sending message to %s on connection %s: %v", userID, c.ID, err)
+				c.close()
+				return
+			}
+		}
 	}
-	return nil
 }
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConn records what is written to it. a blocked connection waits in WriteMessage until it is unblocked or closed
+type fakeConn struct {
+	mu        sync.Mutex
+	messages  []string
+	fail      bool
+	unblocked chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newFakeConn(blocked bool, fail bool) *fakeConn {
+	f := &fakeConn{fail: fail, unblocked: make(chan struct{}), closed: make(chan struct{})}
+	if !blocked {
+		close(f.unblocked)
+	}
+	return f
+}
+
+func (f *fakeConn) WriteMessage(messageType int, data []byte) error {
+	select {
+	case <-f.unblocked:
+	case <-f.closed:
+		return errors.New("use of closed connection")
+	}
+	if f.fail {
+		return errors.New("broken pipe")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, string(data))
+	return nil
+}
+
+func (f *fakeConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func (f *fakeConn) Close() error {
+	f.closeOnce.Do(func() { close(f.closed) })
+	return nil
+}
+
+func (f *fakeConn) received() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.messages...)
+}
+
+func (f *fakeConn) isClosed() bool {
+	select {
+	case <-f.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestWebSocketFanOut(t *testing.T) {
+	ctx := context.Background()
+	newService := func(policy SlowClientPolicy) *WebSocketService {
+		s := NewWebSocketService(policy)
+		s.queueSize = 2
+		return s
+	}
+	delivered := func(t *testing.T, conn *fakeConn, count int) bool {
+		return assert.Eventually(t, func() bool { return len(conn.received()) == count }, time.Second, 5*time.Millisecond)
+	}
+
+	t.Run("Delivery continues past a failing connection", func(t *testing.T) {
+		s := newService(DisconnectSlowClients)
+		broken := newFakeConn(false, true)
+		phone := newFakeConn(false, false)
+		other := newFakeConn(false, false)
+		brokenID := s.addConnection("alice", broken, nil)
+		s.addConnection("alice", phone, nil)
+		s.addConnection("bob", other, nil)
+
+		assert.NoError(t, s.BroadcastMessage(ctx, []string{"alice", "bob"}, "first"))
+		delivered(t, phone, 1)
+		delivered(t, other, 1)
+		assert.Eventually(t, broken.isClosed, time.Second, 5*time.Millisecond)
+
+		// the closed connection is skipped until its handler removes it
+		assert.NoError(t, s.BroadcastMessage(ctx, []string{"alice", "bob"}, "second"))
+		delivered(t, phone, 2)
+		assert.Equal(t, []string{"first", "second"}, other.received())
+		s.RemoveConnection(ctx, "alice", brokenID)
+		assert.Len(t, s.GetConnectionIDs(ctx, "alice"), 1)
+	})
+
+	t.Run("A slow client does not hold up the others", func(t *testing.T) {
+		for _, policy := range []SlowClientPolicy{DropMessages, DisconnectSlowClients} {
+			s := newService(policy)
+			slow := newFakeConn(true, false)
+			fast := newFakeConn(false, false)
+			slowID := s.addConnection("alice", slow, nil)
+			s.addConnection("bob", fast, nil)
+
+			var errs []error
+			for i := range 5 {
+				errs = append(errs, s.BroadcastMessage(ctx, []string{"alice", "bob"}, "update"))
+				delivered(t, fast, i+1)
+			}
+			// the queue holds two, at most one more is waiting to be written
+			assert.ErrorIs(t, errors.Join(errs...), ErrWebSocketQueueFull)
+			assert.Equal(t, policy == DisconnectSlowClients, slow.isClosed())
+
+			close(slow.unblocked)
+			if policy == DropMessages {
+				assert.Eventually(t, func() bool { return len(slow.received()) >= 2 }, time.Second, 5*time.Millisecond)
+				assert.Less(t, len(slow.received()), 5)
+				assert.NoError(t, s.SendToConnection(ctx, "alice", slowID, "caught up"))
+				assert.Eventually(t, func() bool {
+					received := slow.received()
+					return received[len(received)-1] == "caught up"
+				}, time.Second, 5*time.Millisecond)
+			}
+			s.RemoveConnection(ctx, "alice", slowID)
+		}
+	})
+
+	t.Run("Removing a connection waits for its writes", func(t *testing.T) {
+		s := newService(DropMessages)
+		conn := newFakeConn(true, false)
+		connID := s.addConnection("alice", conn, nil)
+		assert.NoError(t, s.SendMessage(ctx, "alice", "pending"))
+
+		s.RemoveConnection(ctx, "alice", connID)
+		assert.True(t, conn.isClosed())
+		assert.Empty(t, s.GetConnectionIDs(ctx, "alice"))
+		assert.ErrorIs(t, s.SendMessage(ctx, "alice", "gone"), ErrRecipientNotFound)
+		assert.ErrorIs(t, s.SendToConnection(ctx, "alice", connID, "gone"), ErrRecipientNotFound)
+	})
+
+	t.Run("Published messages reach only subscribed connections", func(t *testing.T) {
+		s := newService(DropMessages)
+		phone := newFakeConn(false, false)
+		laptop := newFakeConn(false, false)
+		other := newFakeConn(false, false)
+		phoneID := s.addConnection("alice", phone, nil)
+		laptopID := s.addConnection("alice", laptop, nil)
+		otherID := s.addConnection("bob", other, nil)
+		assert.NoError(t, s.Subscribe(ctx, "alice", phoneID, "club:1:posts"))
+		assert.NoError(t, s.Subscribe(ctx, "alice", laptopID, "club:1:members"))
+		assert.NoError(t, s.Subscribe(ctx, "bob", otherID, "club:1:posts"))
+		assert.ErrorIs(t, s.Subscribe(ctx, "alice", "missing", "club:1:posts"), ErrRecipientNotFound)
+
+		// bob is subscribed but not a recipient
+		assert.NoError(t, s.PublishMessage(ctx, []string{"alice"}, "club:1:posts", "post"))
+		assert.NoError(t, s.PublishMessage(ctx, []string{"alice", "bob"}, "club:1:members", "joined"))
+		delivered(t, phone, 1)
+		delivered(t, laptop, 1)
+		assert.Equal(t, []string{"post"}, phone.received())
+		assert.Equal(t, []string{"joined"}, laptop.received())
+		assert.Empty(t, other.received())
+
+		s.Unsubscribe(ctx, "alice", phoneID, "club:1:posts")
+		assert.NoError(t, s.PublishMessage(ctx, []string{"alice"}, "club:1:posts", "unheard"))
+		assert.NoError(t, s.SendToConnection(ctx, "alice", phoneID, "direct"))
+		delivered(t, phone, 2)
+		assert.Equal(t, []string{"post", "direct"}, phone.received())
+	})
+
+	t.Run("Subscriptions per connection are limited", func(t *testing.T) {
+		s := newService(DropMessages)
+		connID := s.addConnection("alice", newFakeConn(false, false), nil)
+		for i := range config.MaxWebSocketSubscriptions {
+			assert.NoError(t, s.Subscribe(ctx, "alice", connID, fmt.Sprintf("metric:%d", i)))
+		}
+		assert.ErrorIs(t, s.Subscribe(ctx, "alice", connID, "metric:one-more"), ErrTooManySubscriptions)
+		// subscribing again to a topic it has is not a new subscription
+		assert.NoError(t, s.Subscribe(ctx, "alice", connID, "metric:0"))
+	})
+}
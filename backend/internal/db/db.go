@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
@@ -22,7 +23,7 @@ func New(ctx context.Context, uri string) (*sql.DB, func(), error) {
 		return nil, nil, errors.New("DATABASE_URL must be set")
 	}
 
-	db, err := sql.Open("sqlite", uri)
+	db, err := sql.Open("sqlite", dsn(uri))
 	if err != nil {
 		return nil, nil, fmt.Errorf("Error opening SQLite database: %v", err)
 	}
@@ -31,31 +32,48 @@ func New(ctx context.Context, uri string) (*sql.DB, func(), error) {
 		return nil, nil, fmt.Errorf("Error connecting to database: %v", err)
 	}
 
+	// set up on one connection, an in-memory database only exists on the connection that created it
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error connecting to database: %v", err)
+	}
+	defer conn.Close()
+
 	log.Println("Loading Database Extensions...")
 	extensionBytes, err := schemas.ReadFile("sql/schemas/extensions.sql")
 	if err != nil {
 		return nil, nil, fmt.Errorf("Error reading extension file: %v", err)
 	}
-	if _, err := db.ExecContext(ctx, string(extensionBytes)); err != nil {
+	if _, err := conn.ExecContext(ctx, string(extensionBytes)); err != nil {
 		return nil, nil, fmt.Errorf("Error loading database extensions: %v", err)
 	}
 
+	hasSchema, err := tableExists(ctx, conn, "user")
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error reading db schema: %v", err)
+	}
+
 	log.Println("Loading Database Schema...")
 	schemaBytes, err := schemas.ReadFile("sql/schemas/schema.sql")
 	if err != nil {
 		return nil, nil, fmt.Errorf("Error reading schema file: %v", err)
 	}
-	if _, err := db.ExecContext(ctx, string(schemaBytes)); err != nil {
+	if _, err := conn.ExecContext(ctx, string(schemaBytes)); err != nil {
 		return nil, nil, fmt.Errorf("Error initializing db schema: %v", err)
 	}
 
+	log.Println("Migrating Database...")
+	if err := migrate(ctx, conn, !hasSchema); err != nil {
+		return nil, nil, fmt.Errorf("Error migrating database: %v", err)
+	}
+
 	log.Println("Loading Database Triggers...")
 	triggersBytes, err := schemas.ReadFile("sql/schemas/triggers.sql")
 	if err != nil {
 		return nil, nil, fmt.Errorf("Error reading triggers file: %v", err)
 	}
 
-	if _, err := db.ExecContext(ctx, string(triggersBytes)); err != nil {
+	if _, err := conn.ExecContext(ctx, string(triggersBytes)); err != nil {
 		return nil, nil, fmt.Errorf("Error initializing db triggers: %v", err)
 	}
 
@@ -63,3 +81,18 @@ func New(ctx context.Context, uri string) (*sql.DB, func(), error) {
 		db.Close()
 	}, nil
 }
+
+// busyTimeout is how long a connection waits for another one's write lock before failing with SQLITE_BUSY
+const busyTimeout = 5000 // milliseconds
+
+// dsn adds the settings every connection of the pool needs. a PRAGMA that is executed only applies to the
+// connection that ran it, the driver runs the ones in the DSN on each new connection.
+// transactions take the write lock when they begin, a deferred one that reads first cannot wait for
+// the lock when it later writes and fails right away
+func dsn(uri string) string {
+	separator := "?"
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	}
+	return uri + separator + fmt.Sprintf("_pragma=foreign_keys(1)&_pragma=busy_timeout(%d)&_txlock=immediate", busyTimeout)
+}
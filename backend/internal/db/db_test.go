@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// data as the first release stored it
+const baselineData = `
+INSERT INTO user (id, email, username, password, created_at) VALUES
+    ('alice', 'alice@example.com', 'alice', 'hash', '2024-01-01 10:00:00'),
+    ('bob', 'bob@example.com', 'bob', 'hash', '2024-01-02 10:00:00'),
+    ('carol', 'carol@example.com', 'carol', 'hash', '2024-01-03 10:00:00');
+INSERT INTO club (id, name, owner_user_id, is_public) VALUES ('club', 'Book Club', 'alice', TRUE);
+INSERT INTO club_membership (user_id, club_id, user_points, user_streak) VALUES
+    ('alice', 'club', 40.0, 3),
+    ('bob', 'club', 15.0, 1),
+    ('carol', 'club', 0.0, 0);
+INSERT INTO club_post (id, user_id, club_id, content) VALUES ('post', 'carol', 'club', 'Hello');
+INSERT INTO items (id, name, description, owner_id, club_id) VALUES
+    ('lamp', 'Reading lamp', 'Warm light for late chapters', 'alice', 'club'),
+    ('novel', 'Signed novel', 'First edition', 'bob', 'club');
+INSERT INTO trades (id, proposer_id, proposer_item_id, responder_id, responder_item_id) VALUES
+    ('trade', 'alice', 'lamp', 'bob', 'novel');
+`
+
+func openDatabase(t *testing.T, path string) *sql.DB {
+	conn, closer, err := New(context.Background(), path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(closer)
+	return conn
+}
+
+func userVersion(t *testing.T, conn *sql.DB) int {
+	var version int
+	assert.NoError(t, conn.QueryRow("PRAGMA user_version").Scan(&version))
+	return version
+}
+
+func TestConnectionSettings(t *testing.T) {
+	ctx := context.Background()
+	db := openDatabase(t, filepath.Join(t.TempDir(), "pool.sqlite"))
+
+	// hold one connection so the pool has to open another
+	first, err := db.Conn(ctx)
+	assert.NoError(t, err)
+	defer first.Close()
+	second, err := db.Conn(ctx)
+	assert.NoError(t, err)
+	defer second.Close()
+
+	for _, conn := range []*sql.Conn{first, second} {
+		var foreignKeys int
+		assert.NoError(t, conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys))
+		assert.Equal(t, 1, foreignKeys)
+		var timeout int
+		assert.NoError(t, conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&timeout))
+		assert.Equal(t, busyTimeout, timeout)
+	}
+
+	// both transactions read before they write, deferred ones would fail with SQLITE_BUSY instead of waiting
+	if _, err := db.ExecContext(ctx, "CREATE TABLE counter (n INTEGER NOT NULL)"); !assert.NoError(t, err) {
+		return
+	}
+	_, err = db.ExecContext(ctx, "INSERT INTO counter (n) VALUES (0)")
+	assert.NoError(t, err)
+	errs := make(chan error, 2)
+	for _, conn := range []*sql.Conn{first, second} {
+		go func(conn *sql.Conn) {
+			errs <- increment(ctx, conn)
+		}(conn)
+	}
+	assert.NoError(t, <-errs)
+	assert.NoError(t, <-errs)
+	var n int
+	assert.NoError(t, db.QueryRowContext(ctx, "SELECT n FROM counter").Scan(&n))
+	assert.Equal(t, 2, n)
+}
+
+func increment(ctx context.Context, conn *sql.Conn) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var n int
+	if err := tx.QueryRowContext(ctx, "SELECT n FROM counter").Scan(&n); err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := tx.ExecContext(ctx, "UPDATE counter SET n = ?", n+1); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("A new database needs no migrations", func(t *testing.T) {
+		conn := openDatabase(t, filepath.Join(t.TempDir(), "new.sqlite"))
+		assert.Equal(t, len(migrations), userVersion(t, conn))
+	})
+
+	t.Run("A database from the first release is upgraded", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "baseline.sqlite")
+		baseline, err := os.ReadFile("testdata/baseline_schema.sql")
+		assert.NoError(t, err)
+		old, err := sql.Open("sqlite", path)
+		assert.NoError(t, err)
+		_, err = old.Exec(string(baseline) + baselineData)
+		assert.NoError(t, err)
+		assert.NoError(t, old.Close())
+
+		conn := openDatabase(t, path)
+		assert.Equal(t, len(migrations), userVersion(t, conn))
+		q := repository.New(conn)
+
+		email, err := q.GetUserEmail(ctx, "bob")
+		assert.NoError(t, err)
+		assert.NotNil(t, email.EmailVerifiedAt, "existing accounts count as verified")
+		role, err := q.GetUserRole(ctx, "alice")
+		assert.NoError(t, err)
+		assert.Equal(t, "user", role)
+
+		item, err := q.GetItem(ctx, "lamp")
+		assert.NoError(t, err)
+		assert.Equal(t, "Reading lamp", item.Name)
+		assert.Equal(t, "approved", item.ModerationStatus)
+		assert.Equal(t, "trade", item.ListingType)
+		club, err := q.GetClub(ctx, "club")
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, club.MinListingReputation)
+
+		trade, err := q.GetTradeByID(ctx, "trade")
+		assert.NoError(t, err)
+		assert.Equal(t, "pending", trade.Status)
+		assert.Equal(t, 0.0, trade.ProposerPoints)
+		tradeItems, err := q.GetTradeItems(ctx, "trade")
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []repository.GetTradeItemsRow{
+			{ItemID: "lamp", OwnerID: "alice", Name: "Reading lamp"},
+			{ItemID: "novel", OwnerID: "bob", Name: "Signed novel"},
+		}, tradeItems)
+
+		// points earned before the ledger are an opening balance, rebuilding from the ledger keeps them
+		ledger, err := q.GetPointLedger(ctx, repository.GetPointLedgerParams{ClubID: "club", UserID: "bob"})
+		assert.NoError(t, err)
+		if assert.Len(t, ledger, 1) {
+			assert.Equal(t, "opening_balance", ledger[0].Reason)
+			assert.Equal(t, 15.0, ledger[0].Amount)
+			assert.Equal(t, 15.0, ledger[0].Balance)
+		}
+		ledger, err = q.GetPointLedger(ctx, repository.GetPointLedgerParams{ClubID: "club", UserID: "alice"})
+		assert.NoError(t, err)
+		if assert.Len(t, ledger, 1) {
+			assert.Equal(t, 40.0, ledger[0].Amount)
+		}
+		ledger, err = q.GetPointLedger(ctx, repository.GetPointLedgerParams{ClubID: "club", UserID: "carol"})
+		assert.NoError(t, err)
+		assert.Empty(t, ledger)
+		drifted, err := q.RebuildPointBalances(ctx)
+		assert.NoError(t, err)
+		assert.Zero(t, drifted)
+
+		search := func(match string) []repository.SearchItemsRow {
+			items, err := q.SearchItems(ctx, repository.SearchItemsParams{UserID: "carol", Match: match, Limit: 10})
+			assert.NoError(t, err)
+			return items
+		}
+		// VACUUM may renumber the rowid of items, the index does not depend on it
+		_, err = conn.ExecContext(ctx, "VACUUM")
+		assert.NoError(t, err)
+		if found := search("chapters"); assert.Len(t, found, 1) {
+			assert.Equal(t, "lamp", found[0].ID)
+		}
+		assert.Len(t, search("edition"), 1)
+		// deleting items takes them out of the index
+		assert.NoError(t, q.DeleteUser(ctx, "bob"))
+		assert.Empty(t, search("edition"))
+
+		// posts outlive their author since the first release's club_post was rebuilt
+		assert.NoError(t, q.DeleteUser(ctx, "carol"))
+		post, err := q.GetClubPost(ctx, "post")
+		assert.NoError(t, err)
+		assert.Nil(t, post.UserID)
+		assert.Equal(t, "Hello", post.Content)
+
+		// a second start has nothing left to do
+		assert.NoError(t, conn.Close())
+		conn = openDatabase(t, path)
+		assert.Equal(t, len(migrations), userVersion(t, conn))
+	})
+}
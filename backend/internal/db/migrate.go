@@ -0,0 +1,286 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/rhellwege/task-social/internal/util"
+)
+
+type migration struct {
+	name string
+	up   func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrations bring a database created by an older schema.sql up to date, PRAGMA user_version counts the ones
+// that ran. schema.sql always describes the latest schema, so a new database is marked as migrated without
+// running them. Only append to the list, a migration that was released must not change.
+var migrations = []migration{
+	{"add the user, item, club and post columns", addReleaseColumns},
+	{"keep club posts of deleted accounts", rebuildClubPost},
+	{"move the items of trades into trade_item", rebuildTrades},
+	{"index the items listed before marketplace search", indexItems},
+	{"record the points earned before the points ledger", addOpeningBalances},
+}
+
+// migrate runs on the connection that loaded schema.sql, newDatabase is true when schema.sql created it
+func migrate(ctx context.Context, conn *sql.Conn, newDatabase bool) error {
+	var version int
+	if err := conn.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if newDatabase {
+		return setUserVersion(ctx, conn, len(migrations))
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this build (%d)", version, len(migrations))
+	}
+	if version == len(migrations) {
+		return nil
+	}
+
+	// rebuilding a table drops the old one, which must not cascade. the pragma has no effect inside a transaction
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON")
+
+	for i := version; i < len(migrations); i++ {
+		log.Printf("Running database migration %d: %s", i+1, migrations[i].name)
+		if err := runMigration(ctx, conn, i+1, migrations[i]); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", i+1, migrations[i].name, err)
+		}
+	}
+	return nil
+}
+
+func runMigration(ctx context.Context, conn *sql.Conn, version int, m migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(ctx, tx); err != nil {
+		return err
+	}
+	if err := logForeignKeyViolations(ctx, tx); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// logForeignKeyViolations reports rows that point at missing rows. older databases did not always enforce
+// foreign keys, so such rows are kept instead of failing every start
+func logForeignKeyViolations(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "SELECT \"table\", parent, COUNT(*) FROM pragma_foreign_key_check GROUP BY \"table\", parent")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, parent string
+		var count int
+		if err := rows.Scan(&table, &parent, &count); err != nil {
+			return err
+		}
+		log.Printf("WARNING: %d rows of %s point at missing rows of %s", count, table, parent)
+	}
+	return rows.Err()
+}
+
+func setUserVersion(ctx context.Context, conn *sql.Conn, version int) error {
+	_, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version))
+	return err
+}
+
+func tableExists(ctx context.Context, conn *sql.Conn, table string) (bool, error) {
+	var count int
+	err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count)
+	return count > 0, err
+}
+
+// addColumn returns false when the table already has the column, a development build may have created it
+func addColumn(ctx context.Context, tx *sql.Tx, table string, column string, definition string) (bool, error) {
+	var count int
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil || count > 0 {
+		return false, err
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %q ADD COLUMN %q %s", table, column, definition))
+	return err == nil, err
+}
+
+// addReleaseColumns adds the columns that were added to tables of the first release, see schema.sql for what they hold
+func addReleaseColumns(ctx context.Context, tx *sql.Tx) error {
+	columns := []struct{ table, column, definition string }{
+		{"user", "email_verified_at", "DATETIME"},
+		{"user", "totp_secret", "TEXT"},
+		{"user", "totp_enabled_at", "DATETIME"},
+		{"user", "totp_last_step", "INTEGER"},
+		{"user", "deletion_scheduled_at", "DATETIME"},
+		{"user", "display_name", "TEXT"},
+		{"user", "bio", "TEXT"},
+		{"user", "location", "TEXT"},
+		{"user", "pronouns", "TEXT"},
+		{"user", "profile_visibility", "TEXT NOT NULL DEFAULT 'public'"},
+		{"user", "show_clubs", "BOOLEAN NOT NULL DEFAULT TRUE"},
+		{"user", "show_stats", "BOOLEAN NOT NULL DEFAULT TRUE"},
+		{"user", "show_items", "BOOLEAN NOT NULL DEFAULT TRUE"},
+		{"user", "role", "TEXT NOT NULL DEFAULT 'user'"},
+		{"user", "disabled_at", "DATETIME"},
+		{"user", "disabled_reason", "TEXT"},
+		{"items", "price_estimate", "REAL"},
+		{"items", "currency", "TEXT"},
+		{"items", "condition", "TEXT"},
+		{"items", "category", "TEXT"},
+		{"items", "pickup_location", "TEXT"},
+		{"items", "can_ship", "BOOLEAN NOT NULL DEFAULT FALSE"},
+		{"items", "moderation_status", "TEXT NOT NULL DEFAULT 'approved'"},
+		{"items", "moderation_reason", "TEXT"},
+		{"items", "reserved_for", "TEXT REFERENCES user(id) ON DELETE SET NULL"},
+		{"items", "reserved_until", "DATETIME"},
+		{"items", "listing_type", "TEXT NOT NULL DEFAULT 'trade'"},
+		{"club", "min_listing_reputation", "REAL NOT NULL DEFAULT 0.0"},
+		{"club_post", "moderation_status", "TEXT NOT NULL DEFAULT 'approved'"},
+		{"club_post", "moderation_reason", "TEXT"},
+	}
+	addedEmailVerifiedAt := false
+	for _, c := range columns {
+		added, err := addColumn(ctx, tx, c.table, c.column, c.definition)
+		if err != nil {
+			return err
+		}
+		if c.column == "email_verified_at" {
+			addedEmailVerifiedAt = added
+		}
+	}
+
+	// accounts from before email verification could not verify, they keep working when it is required
+	if addedEmailVerifiedAt {
+		if _, err := tx.ExecContext(ctx, "UPDATE user SET email_verified_at = created_at"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildClubPost makes club_post.user_id nullable and sets it to null instead of deleting the post with its
+// author. SQLite cannot change a column or a foreign key in place, so the table is copied into a new one
+func rebuildClubPost(ctx context.Context, tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE club_post_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT,
+    club_id TEXT NOT NULL,
+    content TEXT NOT NULL,
+    moderation_status TEXT NOT NULL DEFAULT 'approved',
+    moderation_reason TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE SET NULL,
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE
+)`,
+		`INSERT INTO club_post_new (id, user_id, club_id, content, moderation_status, moderation_reason, created_at, updated_at)
+SELECT id, user_id, club_id, content, moderation_status, moderation_reason, created_at, updated_at FROM club_post`,
+		// takes its indexes and triggers along, triggers.sql adds the triggers back after the migrations
+		`DROP TABLE club_post`,
+		`ALTER TABLE club_post_new RENAME TO club_post`,
+		`CREATE INDEX IF NOT EXISTS idx_club_post_user ON club_post(user_id, created_at)`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildTrades drops the first release's proposer_item_id and responder_item_id from trades, each trade
+// moves its two items into trade_item
+func rebuildTrades(ctx context.Context, tx *sql.Tx) error {
+	statements := []string{
+		`INSERT OR IGNORE INTO trade_item (trade_id, item_id, owner_id)
+SELECT id, proposer_item_id, proposer_id FROM trades
+UNION ALL
+SELECT id, responder_item_id, responder_id FROM trades`,
+		`CREATE TABLE trades_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    proposer_id TEXT NOT NULL,
+    responder_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    countered_trade_id TEXT,
+    club_id TEXT,
+    proposer_points REAL NOT NULL DEFAULT 0.0,
+    responder_points REAL NOT NULL DEFAULT 0.0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (proposer_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (responder_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (countered_trade_id) REFERENCES trades(id) ON DELETE SET NULL,
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE
+)`,
+		`INSERT INTO trades_new (id, proposer_id, responder_id, status, created_at, updated_at)
+SELECT id, proposer_id, responder_id, status, created_at, updated_at FROM trades`,
+		`DROP TABLE trades`,
+		`ALTER TABLE trades_new RENAME TO trades`,
+		`CREATE INDEX IF NOT EXISTS idx_trades_proposer ON trades(proposer_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_trades_responder ON trades(responder_id, status)`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexItems adds the items of the first release to item_search, schema.sql creates it empty and the
+// triggers that keep it up to date only see later changes
+func indexItems(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO item_search (item_id, name, description) SELECT id, name, description FROM items")
+	return err
+}
+
+// addOpeningBalances writes one ledger entry for the points each member earned before the points ledger
+// existed, rebuilding the balances from the ledger would take them away otherwise
+func addOpeningBalances(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "SELECT club_id, user_id, user_points FROM club_membership WHERE user_points != 0")
+	if err != nil {
+		return err
+	}
+	type openingBalance struct {
+		clubID, userID string
+		amount         float64
+	}
+	var balances []openingBalance
+	for rows.Next() {
+		var b openingBalance
+		if err := rows.Scan(&b.clubID, &b.userID, &b.amount); err != nil {
+			rows.Close()
+			return err
+		}
+		balances = append(balances, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, b := range balances {
+		_, err := tx.ExecContext(ctx, `
+INSERT INTO point_ledger (id, club_id, user_id, amount, balance, reason)
+VALUES (?1, ?2, ?3, ?4, ?4, 'opening_balance')`,
+			util.GenerateUUID(), b.clubID, b.userID, b.amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
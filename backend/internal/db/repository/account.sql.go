@@ -0,0 +1,358 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: account.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const cancelUserDeletion = `-- name: CancelUserDeletion :exec
+UPDATE user SET deletion_scheduled_at = NULL WHERE id = ?
+`
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, cancelUserDeletion, id)
+	return err
+}
+
+const getClubSuccessor = `-- name: GetClubSuccessor :one
+SELECT user_id
+FROM club_membership
+WHERE club_id = ?1 AND user_id != ?2
+ORDER BY is_moderator DESC, created_at ASC
+LIMIT 1
+`
+
+type GetClubSuccessorParams struct {
+	ClubID string `json:"club_id"`
+	UserID string `json:"user_id"`
+}
+
+// moderators first, then the longest standing member
+func (q *Queries) GetClubSuccessor(ctx context.Context, arg GetClubSuccessorParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getClubSuccessor, arg.ClubID, arg.UserID)
+	var user_id string
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const getOwnedClubs = `-- name: GetOwnedClubs :many
+SELECT id, name, banner_image
+FROM club
+WHERE owner_user_id = ?
+`
+
+type GetOwnedClubsRow struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	BannerImage *string `json:"banner_image"`
+}
+
+func (q *Queries) GetOwnedClubs(ctx context.Context, ownerUserID string) ([]GetOwnedClubsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOwnedClubs, ownerUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOwnedClubsRow
+	for rows.Next() {
+		var i GetOwnedClubsRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.BannerImage); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserDeletionScheduledAt = `-- name: GetUserDeletionScheduledAt :one
+SELECT deletion_scheduled_at FROM user WHERE id = ?
+`
+
+func (q *Queries) GetUserDeletionScheduledAt(ctx context.Context, id string) (*time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getUserDeletionScheduledAt, id)
+	var deletion_scheduled_at *time.Time
+	err := row.Scan(&deletion_scheduled_at)
+	return deletion_scheduled_at, err
+}
+
+const getUserExportMessages = `-- name: GetUserExportMessages :many
+SELECT pm.id, s.username AS sender, r.username AS recipient, pm.content, pm.created_at
+FROM user_private_message pm
+JOIN user s ON s.id = pm.sender_id
+JOIN user r ON r.id = pm.recipient_id
+WHERE pm.sender_id = ?1 OR pm.recipient_id = ?1
+ORDER BY pm.created_at ASC
+`
+
+type GetUserExportMessagesRow struct {
+	ID        string    `json:"id"`
+	Sender    string    `json:"sender"`
+	Recipient string    `json:"recipient"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetUserExportMessages(ctx context.Context, userID string) ([]GetUserExportMessagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserExportMessages, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserExportMessagesRow
+	for rows.Next() {
+		var i GetUserExportMessagesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sender,
+			&i.Recipient,
+			&i.Content,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserExportMetricEntries = `-- name: GetUserExportMetricEntries :many
+SELECT m.id AS metric_id, m.title AS metric_title, m.unit, c.name AS club_name, mi.due_at, me.value, me.created_at, me.updated_at
+FROM metric_entry me
+JOIN metric_instance mi ON mi.id = me.metric_instance_id
+JOIN metric m ON m.id = mi.metric_id
+JOIN club c ON c.id = m.club_id
+WHERE me.user_id = ?
+ORDER BY me.created_at ASC
+`
+
+type GetUserExportMetricEntriesRow struct {
+	MetricID    string    `json:"metric_id"`
+	MetricTitle string    `json:"metric_title"`
+	Unit        string    `json:"unit"`
+	ClubName    string    `json:"club_name"`
+	DueAt       time.Time `json:"due_at"`
+	Value       float64   `json:"value"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (q *Queries) GetUserExportMetricEntries(ctx context.Context, userID string) ([]GetUserExportMetricEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserExportMetricEntries, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserExportMetricEntriesRow
+	for rows.Next() {
+		var i GetUserExportMetricEntriesRow
+		if err := rows.Scan(
+			&i.MetricID,
+			&i.MetricTitle,
+			&i.Unit,
+			&i.ClubName,
+			&i.DueAt,
+			&i.Value,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserExportPosts = `-- name: GetUserExportPosts :many
+SELECT cp.id, cp.club_id, c.name AS club_name, cp.content, cp.created_at, cp.updated_at
+FROM club_post cp
+JOIN club c ON c.id = cp.club_id
+WHERE cp.user_id = ?
+ORDER BY cp.created_at ASC
+`
+
+type GetUserExportPostsRow struct {
+	ID        string    `json:"id"`
+	ClubID    string    `json:"club_id"`
+	ClubName  string    `json:"club_name"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) GetUserExportPosts(ctx context.Context, userID *string) ([]GetUserExportPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserExportPosts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserExportPostsRow
+	for rows.Next() {
+		var i GetUserExportPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClubID,
+			&i.ClubName,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserExportProfile = `-- name: GetUserExportProfile :one
+SELECT id, email, username, display_name, bio, location, pronouns, profile_picture, profile_visibility, show_clubs, show_stats, show_items,
+    email_verified_at, totp_enabled_at, deletion_scheduled_at, created_at, updated_at
+FROM user
+WHERE id = ?
+`
+
+type GetUserExportProfileRow struct {
+	ID                  string     `json:"id"`
+	Email               string     `json:"email"`
+	Username            string     `json:"username"`
+	DisplayName         *string    `json:"display_name"`
+	Bio                 *string    `json:"bio"`
+	Location            *string    `json:"location"`
+	Pronouns            *string    `json:"pronouns"`
+	ProfilePicture      *string    `json:"profile_picture"`
+	ProfileVisibility   string     `json:"profile_visibility"`
+	ShowClubs           bool       `json:"show_clubs"`
+	ShowStats           bool       `json:"show_stats"`
+	ShowItems           bool       `json:"show_items"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	TotpEnabledAt       *time.Time `json:"totp_enabled_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func (q *Queries) GetUserExportProfile(ctx context.Context, id string) (GetUserExportProfileRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserExportProfile, id)
+	var i GetUserExportProfileRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Pronouns,
+		&i.ProfilePicture,
+		&i.ProfileVisibility,
+		&i.ShowClubs,
+		&i.ShowStats,
+		&i.ShowItems,
+		&i.EmailVerifiedAt,
+		&i.TotpEnabledAt,
+		&i.DeletionScheduledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserPasswordHash = `-- name: GetUserPasswordHash :one
+SELECT password FROM user WHERE id = ?
+`
+
+func (q *Queries) GetUserPasswordHash(ctx context.Context, id string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getUserPasswordHash, id)
+	var password string
+	err := row.Scan(&password)
+	return password, err
+}
+
+const getUsersDueForDeletion = `-- name: GetUsersDueForDeletion :many
+SELECT id, profile_picture
+FROM user
+WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?1
+`
+
+type GetUsersDueForDeletionRow struct {
+	ID             string  `json:"id"`
+	ProfilePicture *string `json:"profile_picture"`
+}
+
+func (q *Queries) GetUsersDueForDeletion(ctx context.Context, now *time.Time) ([]GetUsersDueForDeletionRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersDueForDeletion, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUsersDueForDeletionRow
+	for rows.Next() {
+		var i GetUsersDueForDeletionRow
+		if err := rows.Scan(&i.ID, &i.ProfilePicture); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :exec
+UPDATE user SET deletion_scheduled_at = ?1 WHERE id = ?2
+`
+
+type ScheduleUserDeletionParams struct {
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	ID                  string     `json:"id"`
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) error {
+	_, err := q.db.ExecContext(ctx, scheduleUserDeletion, arg.DeletionScheduledAt, arg.ID)
+	return err
+}
+
+const transferClubOwnership = `-- name: TransferClubOwnership :exec
+UPDATE club SET owner_user_id = ?1 WHERE id = ?2
+`
+
+type TransferClubOwnershipParams struct {
+	OwnerUserID string `json:"owner_user_id"`
+	ID          string `json:"id"`
+}
+
+func (q *Queries) TransferClubOwnership(ctx context.Context, arg TransferClubOwnershipParams) error {
+	_, err := q.db.ExecContext(ctx, transferClubOwnership, arg.OwnerUserID, arg.ID)
+	return err
+}
@@ -0,0 +1,336 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: admin.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createAdminAuditLogEntry = `-- name: CreateAdminAuditLogEntry :exec
+INSERT INTO admin_audit_log (id, admin_id, action, target_type, target_id, reason, ip_address, report_id, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)
+`
+
+type CreateAdminAuditLogEntryParams struct {
+	ID         string    `json:"id"`
+	AdminID    string    `json:"admin_id"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	Reason     string    `json:"reason"`
+	IpAddress  string    `json:"ip_address"`
+	ReportID   *string   `json:"report_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (q *Queries) CreateAdminAuditLogEntry(ctx context.Context, arg CreateAdminAuditLogEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createAdminAuditLogEntry,
+		arg.ID,
+		arg.AdminID,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Reason,
+		arg.IpAddress,
+		arg.ReportID,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const disableUser = `-- name: DisableUser :execrows
+UPDATE user
+SET disabled_at = CURRENT_TIMESTAMP, disabled_reason = ?1
+WHERE id = ?2 AND disabled_at IS NULL
+`
+
+type DisableUserParams struct {
+	DisabledReason *string `json:"disabled_reason"`
+	ID             string  `json:"id"`
+}
+
+func (q *Queries) DisableUser(ctx context.Context, arg DisableUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, disableUser, arg.DisabledReason, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const enableUser = `-- name: EnableUser :execrows
+UPDATE user
+SET disabled_at = NULL, disabled_reason = NULL
+WHERE id = ?1 AND disabled_at IS NOT NULL
+`
+
+func (q *Queries) EnableUser(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, enableUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getAdminAuditLog = `-- name: GetAdminAuditLog :many
+SELECT a.id, a.admin_id, a."action", a.target_type, a.target_id, a.reason, a.ip_address, a.report_id, a.created_at, a.updated_at, COALESCE(u.username, '[deleted]') AS admin_username
+FROM admin_audit_log a
+LEFT JOIN user u ON u.id = a.admin_id
+WHERE
+    (CAST(?1 AS TEXT) = '' OR a.admin_id = ?1)
+    AND (CAST(?2 AS TEXT) = '' OR a.target_id = ?2)
+ORDER BY a.created_at DESC
+LIMIT ?4 OFFSET ?3
+`
+
+type GetAdminAuditLogParams struct {
+	AdminID  string `json:"admin_id"`
+	TargetID string `json:"target_id"`
+	Offset   int64  `json:"offset"`
+	Limit    int64  `json:"limit"`
+}
+
+type GetAdminAuditLogRow struct {
+	ID            string    `json:"id"`
+	AdminID       string    `json:"admin_id"`
+	Action        string    `json:"action"`
+	TargetType    string    `json:"target_type"`
+	TargetID      string    `json:"target_id"`
+	Reason        string    `json:"reason"`
+	IpAddress     string    `json:"ip_address"`
+	ReportID      *string   `json:"report_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	AdminUsername string    `json:"admin_username"`
+}
+
+// empty filters match every entry
+func (q *Queries) GetAdminAuditLog(ctx context.Context, arg GetAdminAuditLogParams) ([]GetAdminAuditLogRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAdminAuditLog,
+		arg.AdminID,
+		arg.TargetID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAdminAuditLogRow
+	for rows.Next() {
+		var i GetAdminAuditLogRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AdminID,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Reason,
+			&i.IpAddress,
+			&i.ReportID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AdminUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserDisabledAt = `-- name: GetUserDisabledAt :one
+SELECT disabled_at FROM user WHERE id = ?1
+`
+
+func (q *Queries) GetUserDisabledAt(ctx context.Context, id string) (*time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getUserDisabledAt, id)
+	var disabled_at *time.Time
+	err := row.Scan(&disabled_at)
+	return disabled_at, err
+}
+
+const getUserRole = `-- name: GetUserRole :one
+SELECT role FROM user WHERE id = ?1
+`
+
+func (q *Queries) GetUserRole(ctx context.Context, id string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getUserRole, id)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const grantAdminRoleByEmail = `-- name: GrantAdminRoleByEmail :execrows
+UPDATE user SET role = 'admin' WHERE email = ?1 AND email_verified_at IS NOT NULL AND role != 'admin'
+`
+
+// only a verified address proves the account belongs to the listed person and not to whoever registered it first
+func (q *Queries) GrantAdminRoleByEmail(ctx context.Context, email string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, grantAdminRoleByEmail, email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchClubs = `-- name: SearchClubs :many
+SELECT
+    c.id, c.name, c.description, c.is_public, c.owner_user_id,
+    u.username AS owner_username,
+    (SELECT COUNT(*) FROM club_membership m WHERE m.club_id = c.id) AS member_count,
+    c.created_at
+FROM club c
+JOIN user u ON u.id = c.owner_user_id
+WHERE
+    CAST(?1 AS TEXT) = ''
+    OR c.id = ?1
+    OR instr(lower(c.name), lower(?1)) > 0
+    OR instr(lower(u.username), lower(?1)) > 0
+ORDER BY c.created_at DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type SearchClubsParams struct {
+	Query  string `json:"query"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+type SearchClubsRow struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Description   *string   `json:"description"`
+	IsPublic      bool      `json:"is_public"`
+	OwnerUserID   string    `json:"owner_user_id"`
+	OwnerUsername string    `json:"owner_username"`
+	MemberCount   int64     `json:"member_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// matches part of the club name, the owner's username or the exact id. private clubs are included
+func (q *Queries) SearchClubs(ctx context.Context, arg SearchClubsParams) ([]SearchClubsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchClubs, arg.Query, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchClubsRow
+	for rows.Next() {
+		var i SearchClubsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.IsPublic,
+			&i.OwnerUserID,
+			&i.OwnerUsername,
+			&i.MemberCount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT
+    id, email, username, display_name, role, email_verified_at,
+    disabled_at, disabled_reason, deletion_scheduled_at, created_at
+FROM user
+WHERE
+    CAST(?1 AS TEXT) = ''
+    OR id = ?1
+    OR instr(lower(username), lower(?1)) > 0
+    OR instr(lower(email), lower(?1)) > 0
+ORDER BY created_at DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type SearchUsersParams struct {
+	Query  string `json:"query"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+type SearchUsersRow struct {
+	ID                  string     `json:"id"`
+	Email               string     `json:"email"`
+	Username            string     `json:"username"`
+	DisplayName         *string    `json:"display_name"`
+	Role                string     `json:"role"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	DisabledReason      *string    `json:"disabled_reason"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// matches part of the username or email, or the exact id. an empty query lists everyone
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]SearchUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers, arg.Query, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchUsersRow
+	for rows.Next() {
+		var i SearchUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.DisplayName,
+			&i.Role,
+			&i.EmailVerifiedAt,
+			&i.DisabledAt,
+			&i.DisabledReason,
+			&i.DeletionScheduledAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserRole = `-- name: SetUserRole :execrows
+UPDATE user SET role = ?1 WHERE id = ?2
+`
+
+type SetUserRoleParams struct {
+	Role string `json:"role"`
+	ID   string `json:"id"`
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setUserRole, arg.Role, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,274 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: auction.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const closeAuction = `-- name: CloseAuction :execrows
+UPDATE auction
+SET status = ?1, winner_id = ?2, winning_bid = ?3
+WHERE id = ?4 AND status = 'open'
+`
+
+type CloseAuctionParams struct {
+	Status     string   `json:"status"`
+	WinnerID   *string  `json:"winner_id"`
+	WinningBid *float64 `json:"winning_bid"`
+	ID         string   `json:"id"`
+}
+
+// does nothing if the auction was already closed
+func (q *Queries) CloseAuction(ctx context.Context, arg CloseAuctionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, closeAuction,
+		arg.Status,
+		arg.WinnerID,
+		arg.WinningBid,
+		arg.ID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createAuction = `-- name: CreateAuction :exec
+INSERT INTO auction (id, item_id, club_id, seller_id, start_price, reserve_price, bid_increment, currency, ends_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateAuctionParams struct {
+	ID           string    `json:"id"`
+	ItemID       string    `json:"item_id"`
+	ClubID       string    `json:"club_id"`
+	SellerID     string    `json:"seller_id"`
+	StartPrice   float64   `json:"start_price"`
+	ReservePrice *float64  `json:"reserve_price"`
+	BidIncrement float64   `json:"bid_increment"`
+	Currency     string    `json:"currency"`
+	EndsAt       time.Time `json:"ends_at"`
+}
+
+func (q *Queries) CreateAuction(ctx context.Context, arg CreateAuctionParams) error {
+	_, err := q.db.ExecContext(ctx, createAuction,
+		arg.ID,
+		arg.ItemID,
+		arg.ClubID,
+		arg.SellerID,
+		arg.StartPrice,
+		arg.ReservePrice,
+		arg.BidIncrement,
+		arg.Currency,
+		arg.EndsAt,
+	)
+	return err
+}
+
+const createAuctionBid = `-- name: CreateAuctionBid :exec
+INSERT INTO auction_bid (id, auction_id, bidder_id, amount)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateAuctionBidParams struct {
+	ID        string  `json:"id"`
+	AuctionID string  `json:"auction_id"`
+	BidderID  string  `json:"bidder_id"`
+	Amount    float64 `json:"amount"`
+}
+
+func (q *Queries) CreateAuctionBid(ctx context.Context, arg CreateAuctionBidParams) error {
+	_, err := q.db.ExecContext(ctx, createAuctionBid,
+		arg.ID,
+		arg.AuctionID,
+		arg.BidderID,
+		arg.Amount,
+	)
+	return err
+}
+
+const extendAuction = `-- name: ExtendAuction :exec
+UPDATE auction
+SET ends_at = ?1
+WHERE id = ?2
+`
+
+type ExtendAuctionParams struct {
+	EndsAt time.Time `json:"ends_at"`
+	ID     string    `json:"id"`
+}
+
+func (q *Queries) ExtendAuction(ctx context.Context, arg ExtendAuctionParams) error {
+	_, err := q.db.ExecContext(ctx, extendAuction, arg.EndsAt, arg.ID)
+	return err
+}
+
+const getAuction = `-- name: GetAuction :one
+SELECT id, item_id, club_id, seller_id, start_price, reserve_price, bid_increment, currency, ends_at, status, winner_id, winning_bid, created_at, updated_at
+FROM auction
+WHERE id = ?
+`
+
+func (q *Queries) GetAuction(ctx context.Context, id string) (Auction, error) {
+	row := q.db.QueryRowContext(ctx, getAuction, id)
+	var i Auction
+	err := row.Scan(
+		&i.ID,
+		&i.ItemID,
+		&i.ClubID,
+		&i.SellerID,
+		&i.StartPrice,
+		&i.ReservePrice,
+		&i.BidIncrement,
+		&i.Currency,
+		&i.EndsAt,
+		&i.Status,
+		&i.WinnerID,
+		&i.WinningBid,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getAuctionBids = `-- name: GetAuctionBids :many
+SELECT b.id, b.bidder_id, u.username AS bidder_username, b.amount, b.created_at
+FROM auction_bid b
+JOIN user u ON u.id = b.bidder_id
+WHERE b.auction_id = ?
+ORDER BY b.amount DESC, b.rowid
+`
+
+type GetAuctionBidsRow struct {
+	ID             string    `json:"id"`
+	BidderID       string    `json:"bidder_id"`
+	BidderUsername string    `json:"bidder_username"`
+	Amount         float64   `json:"amount"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// the highest bid first, of equal bids the earlier one
+func (q *Queries) GetAuctionBids(ctx context.Context, auctionID string) ([]GetAuctionBidsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAuctionBids, auctionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAuctionBidsRow
+	for rows.Next() {
+		var i GetAuctionBidsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BidderID,
+			&i.BidderUsername,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClubAuctions = `-- name: GetClubAuctions :many
+SELECT id, item_id, club_id, seller_id, start_price, reserve_price, bid_increment, currency, ends_at, status, winner_id, winning_bid, created_at, updated_at
+FROM auction
+WHERE club_id = ? AND status = 'open'
+ORDER BY ends_at, rowid
+`
+
+// the club's open auctions, the ones ending first first
+func (q *Queries) GetClubAuctions(ctx context.Context, clubID string) ([]Auction, error) {
+	rows, err := q.db.QueryContext(ctx, getClubAuctions, clubID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Auction
+	for rows.Next() {
+		var i Auction
+		if err := rows.Scan(
+			&i.ID,
+			&i.ItemID,
+			&i.ClubID,
+			&i.SellerID,
+			&i.StartPrice,
+			&i.ReservePrice,
+			&i.BidIncrement,
+			&i.Currency,
+			&i.EndsAt,
+			&i.Status,
+			&i.WinnerID,
+			&i.WinningBid,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEndedAuctions = `-- name: GetEndedAuctions :many
+SELECT id
+FROM auction
+WHERE status = 'open' AND ends_at <= ?1
+ORDER BY ends_at
+`
+
+func (q *Queries) GetEndedAuctions(ctx context.Context, now time.Time) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getEndedAuctions, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setItemListingType = `-- name: SetItemListingType :exec
+UPDATE items
+SET listing_type = ?1
+WHERE id = ?2
+`
+
+type SetItemListingTypeParams struct {
+	ListingType string `json:"listing_type"`
+	ID          string `json:"id"`
+}
+
+func (q *Queries) SetItemListingType(ctx context.Context, arg SetItemListingTypeParams) error {
+	_, err := q.db.ExecContext(ctx, setItemListingType, arg.ListingType, arg.ID)
+	return err
+}
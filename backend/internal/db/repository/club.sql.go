@@ -53,15 +53,17 @@ func (q *Queries) CreateClubMembership(ctx context.Context, arg CreateClubMember
 }
 
 const createClubPost = `-- name: CreateClubPost :exec
-INSERT INTO club_post (id, club_id, user_id, content)
-VALUES (?1, ?2, ?3, ?4)
+INSERT INTO club_post (id, club_id, user_id, content, moderation_status, moderation_reason)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
 `
 
 type CreateClubPostParams struct {
-	ID      string `json:"id"`
-	ClubID  string `json:"club_id"`
-	UserID  string `json:"user_id"`
-	Content string `json:"content"`
+	ID               string  `json:"id"`
+	ClubID           string  `json:"club_id"`
+	UserID           *string `json:"user_id"`
+	Content          string  `json:"content"`
+	ModerationStatus string  `json:"moderation_status"`
+	ModerationReason *string `json:"moderation_reason"`
 }
 
 func (q *Queries) CreateClubPost(ctx context.Context, arg CreateClubPostParams) error {
@@ -70,6 +72,8 @@ func (q *Queries) CreateClubPost(ctx context.Context, arg CreateClubPostParams)
 		arg.ClubID,
 		arg.UserID,
 		arg.Content,
+		arg.ModerationStatus,
+		arg.ModerationReason,
 	)
 	return err
 }
@@ -140,7 +144,7 @@ func (q *Queries) DeleteClubPostAttachment(ctx context.Context, id string) error
 }
 
 const getAllClubs = `-- name: GetAllClubs :many
-SELECT id, name, description, owner_user_id, banner_image, is_public, created_at, updated_at FROM club
+SELECT id, name, description, owner_user_id, banner_image, is_public, min_listing_reputation, created_at, updated_at FROM club
 `
 
 func (q *Queries) GetAllClubs(ctx context.Context) ([]Club, error) {
@@ -159,6 +163,7 @@ func (q *Queries) GetAllClubs(ctx context.Context) ([]Club, error) {
 			&i.OwnerUserID,
 			&i.BannerImage,
 			&i.IsPublic,
+			&i.MinListingReputation,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -176,7 +181,7 @@ func (q *Queries) GetAllClubs(ctx context.Context) ([]Club, error) {
 }
 
 const getClub = `-- name: GetClub :one
-SELECT id, name, description, owner_user_id, banner_image, is_public, created_at, updated_at FROM club WHERE id = ?1
+SELECT id, name, description, owner_user_id, banner_image, is_public, min_listing_reputation, created_at, updated_at FROM club WHERE id = ?1
 `
 
 func (q *Queries) GetClub(ctx context.Context, id string) (Club, error) {
@@ -189,6 +194,7 @@ func (q *Queries) GetClub(ctx context.Context, id string) (Club, error) {
 		&i.OwnerUserID,
 		&i.BannerImage,
 		&i.IsPublic,
+		&i.MinListingReputation,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -247,6 +253,30 @@ func (q *Queries) GetClubLeaderboard(ctx context.Context, clubID string) ([]GetC
 	return items, nil
 }
 
+const getClubMembership = `-- name: GetClubMembership :one
+SELECT user_id, club_id, user_points, user_streak, is_moderator, created_at, updated_at FROM club_membership WHERE user_id = ? AND club_id = ?
+`
+
+type GetClubMembershipParams struct {
+	UserID string `json:"user_id"`
+	ClubID string `json:"club_id"`
+}
+
+func (q *Queries) GetClubMembership(ctx context.Context, arg GetClubMembershipParams) (ClubMembership, error) {
+	row := q.db.QueryRowContext(ctx, getClubMembership, arg.UserID, arg.ClubID)
+	var i ClubMembership
+	err := row.Scan(
+		&i.UserID,
+		&i.ClubID,
+		&i.UserPoints,
+		&i.UserStreak,
+		&i.IsModerator,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getClubMetrics = `-- name: GetClubMetrics :many
 SELECT id, club_id, title, description, interval, start_at, unit, unit_is_integer, requires_verification, created_at, updated_at FROM metric
 WHERE club_id = ?
@@ -288,22 +318,24 @@ func (q *Queries) GetClubMetrics(ctx context.Context, clubID string) ([]Metric,
 }
 
 const getClubPost = `-- name: GetClubPost :one
-SELECT cp.id, cp.user_id, cp.club_id, cp.content, cp.created_at, cp.updated_at, u.username as author_username, c.name as club_name
+SELECT cp.id, cp.user_id, cp.club_id, cp.content, cp.moderation_status, cp.moderation_reason, cp.created_at, cp.updated_at, COALESCE(u.username, '[deleted]') as author_username, c.name as club_name
 FROM club_post cp
-JOIN "user" u on u.id = cp.user_id
+LEFT JOIN "user" u on u.id = cp.user_id
 JOIN club c on c.id = cp.club_id
 WHERE cp.id = ?1
 `
 
 type GetClubPostRow struct {
-	ID             string    `json:"id"`
-	UserID         string    `json:"user_id"`
-	ClubID         string    `json:"club_id"`
-	Content        string    `json:"content"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	AuthorUsername string    `json:"author_username"`
-	ClubName       string    `json:"club_name"`
+	ID               string    `json:"id"`
+	UserID           *string   `json:"user_id"`
+	ClubID           string    `json:"club_id"`
+	Content          string    `json:"content"`
+	ModerationStatus string    `json:"moderation_status"`
+	ModerationReason *string   `json:"moderation_reason"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	AuthorUsername   string    `json:"author_username"`
+	ClubName         string    `json:"club_name"`
 }
 
 func (q *Queries) GetClubPost(ctx context.Context, id string) (GetClubPostRow, error) {
@@ -314,6 +346,8 @@ func (q *Queries) GetClubPost(ctx context.Context, id string) (GetClubPostRow, e
 		&i.UserID,
 		&i.ClubID,
 		&i.Content,
+		&i.ModerationStatus,
+		&i.ModerationReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.AuthorUsername,
@@ -323,27 +357,34 @@ func (q *Queries) GetClubPost(ctx context.Context, id string) (GetClubPostRow, e
 }
 
 const getClubPosts = `-- name: GetClubPosts :many
-SELECT cp.id, cp.user_id, cp.club_id, cp.content, cp.created_at, cp.updated_at, u.username as author_username, c.name as club_name
+SELECT cp.id, cp.user_id, cp.club_id, cp.content, cp.moderation_status, cp.moderation_reason, cp.created_at, cp.updated_at, COALESCE(u.username, '[deleted]') as author_username, c.name as club_name
 FROM club_post cp
-JOIN "user" u on u.id = cp.user_id
+LEFT JOIN "user" u on u.id = cp.user_id
 JOIN club c on c.id = cp.club_id
-WHERE cp.club_id = ?1
+WHERE cp.club_id = ?1 AND (cp.moderation_status = 'approved' OR cp.user_id = ?2)
 ORDER BY cp.created_at ASC
 `
 
-type GetClubPostsRow struct {
-	ID             string    `json:"id"`
-	UserID         string    `json:"user_id"`
-	ClubID         string    `json:"club_id"`
-	Content        string    `json:"content"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	AuthorUsername string    `json:"author_username"`
-	ClubName       string    `json:"club_name"`
+type GetClubPostsParams struct {
+	ClubID string  `json:"club_id"`
+	UserID *string `json:"user_id"`
 }
 
-func (q *Queries) GetClubPosts(ctx context.Context, clubID string) ([]GetClubPostsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getClubPosts, clubID)
+type GetClubPostsRow struct {
+	ID               string    `json:"id"`
+	UserID           *string   `json:"user_id"`
+	ClubID           string    `json:"club_id"`
+	Content          string    `json:"content"`
+	ModerationStatus string    `json:"moderation_status"`
+	ModerationReason *string   `json:"moderation_reason"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	AuthorUsername   string    `json:"author_username"`
+	ClubName         string    `json:"club_name"`
+}
+
+func (q *Queries) GetClubPosts(ctx context.Context, arg GetClubPostsParams) ([]GetClubPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getClubPosts, arg.ClubID, arg.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -356,6 +397,8 @@ func (q *Queries) GetClubPosts(ctx context.Context, clubID string) ([]GetClubPos
 			&i.UserID,
 			&i.ClubID,
 			&i.Content,
+			&i.ModerationStatus,
+			&i.ModerationReason,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.AuthorUsername,
@@ -402,7 +445,7 @@ func (q *Queries) GetClubUserIds(ctx context.Context, clubID string) ([]string,
 }
 
 const getPublicClubs = `-- name: GetPublicClubs :many
-SELECT id, name, description, owner_user_id, banner_image, is_public, created_at, updated_at FROM club
+SELECT id, name, description, owner_user_id, banner_image, is_public, min_listing_reputation, created_at, updated_at FROM club
 WHERE is_public = true
 `
 
@@ -423,6 +466,7 @@ func (q *Queries) GetPublicClubs(ctx context.Context) ([]Club, error) {
 			&i.OwnerUserID,
 			&i.BannerImage,
 			&i.IsPublic,
+			&i.MinListingReputation,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -457,7 +501,11 @@ func (q *Queries) IsUserMemberOfClub(ctx context.Context, arg IsUserMemberOfClub
 }
 
 const isUserModeratorOfClub = `-- name: IsUserModeratorOfClub :one
-SELECT EXISTS(SELECT 1 FROM club_membership WHERE user_id = ? AND club_id = ? AND (is_moderator = true OR is_owner = true))
+SELECT EXISTS(
+    SELECT 1 FROM club_membership WHERE user_id = ?1 AND club_id = ?2 AND is_moderator = true
+    UNION ALL
+    SELECT 1 FROM club WHERE id = ?2 AND owner_user_id = ?1
+)
 `
 
 type IsUserModeratorOfClubParams struct {
@@ -497,17 +545,19 @@ SET
     name = COALESCE(?1, name),
     description = COALESCE(?2, description),
     banner_image = COALESCE(?3, banner_image),
-    is_public = COALESCE(?4, is_public)
+    is_public = COALESCE(?4, is_public),
+    min_listing_reputation = COALESCE(?5, min_listing_reputation)
 WHERE
-    id = ?5
+    id = ?6
 `
 
 type UpdateClubParams struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
-	BannerImage *string `json:"banner_image"`
-	IsPublic    *bool   `json:"is_public"`
-	ID          string  `json:"id"`
+	Name                 *string  `json:"name"`
+	Description          *string  `json:"description"`
+	BannerImage          *string  `json:"banner_image"`
+	IsPublic             *bool    `json:"is_public"`
+	MinListingReputation *float64 `json:"min_listing_reputation"`
+	ID                   string   `json:"id"`
 }
 
 func (q *Queries) UpdateClub(ctx context.Context, arg UpdateClubParams) error {
@@ -516,6 +566,7 @@ func (q *Queries) UpdateClub(ctx context.Context, arg UpdateClubParams) error {
 		arg.Description,
 		arg.BannerImage,
 		arg.IsPublic,
+		arg.MinListingReputation,
 		arg.ID,
 	)
 	return err
@@ -524,24 +575,22 @@ func (q *Queries) UpdateClub(ctx context.Context, arg UpdateClubParams) error {
 const updateClubMembership = `-- name: UpdateClubMembership :exec
 UPDATE club_membership
 SET
-    user_points = COALESCE(?1, user_points),
-    user_streak = COALESCE(?2, user_streak),
-    is_moderator = COALESCE(?3, is_moderator)
+    user_streak = COALESCE(?1, user_streak),
+    is_moderator = COALESCE(?2, is_moderator)
 WHERE
-    user_id = ?4 AND club_id = ?5
+    user_id = ?3 AND club_id = ?4
 `
 
 type UpdateClubMembershipParams struct {
-	UserPoints  *float64 `json:"user_points"`
-	UserStreak  *int64   `json:"user_streak"`
-	IsModerator *bool    `json:"is_moderator"`
-	UserID      string   `json:"user_id"`
-	ClubID      string   `json:"club_id"`
+	UserStreak  *int64 `json:"user_streak"`
+	IsModerator *bool  `json:"is_moderator"`
+	UserID      string `json:"user_id"`
+	ClubID      string `json:"club_id"`
 }
 
+// points only change through the points ledger
 func (q *Queries) UpdateClubMembership(ctx context.Context, arg UpdateClubMembershipParams) error {
 	_, err := q.db.ExecContext(ctx, updateClubMembership,
-		arg.UserPoints,
 		arg.UserStreak,
 		arg.IsModerator,
 		arg.UserID,
@@ -0,0 +1,240 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: login_attempt.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const countRecentIPRegistrations = `-- name: CountRecentIPRegistrations :one
+SELECT COUNT(*)
+FROM login_attempt
+WHERE ip_address = ?1 AND action = 'register' AND attempted_at > ?2
+`
+
+type CountRecentIPRegistrationsParams struct {
+	IpAddress string    `json:"ip_address"`
+	Since     time.Time `json:"since"`
+}
+
+func (q *Queries) CountRecentIPRegistrations(ctx context.Context, arg CountRecentIPRegistrationsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecentIPRegistrations, arg.IpAddress, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createLoginAttempt = `-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempt (id, action, account, identifier, user_id, ip_address, user_agent, succeeded, failure_reason, attempted_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+`
+
+type CreateLoginAttemptParams struct {
+	ID            string    `json:"id"`
+	Action        string    `json:"action"`
+	Account       string    `json:"account"`
+	Identifier    string    `json:"identifier"`
+	UserID        *string   `json:"user_id"`
+	IpAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	Succeeded     bool      `json:"succeeded"`
+	FailureReason *string   `json:"failure_reason"`
+	AttemptedAt   time.Time `json:"attempted_at"`
+}
+
+func (q *Queries) CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, createLoginAttempt,
+		arg.ID,
+		arg.Action,
+		arg.Account,
+		arg.Identifier,
+		arg.UserID,
+		arg.IpAddress,
+		arg.UserAgent,
+		arg.Succeeded,
+		arg.FailureReason,
+		arg.AttemptedAt,
+	)
+	return err
+}
+
+const deleteLoginAttempt = `-- name: DeleteLoginAttempt :exec
+DELETE FROM login_attempt
+WHERE id = ?1
+`
+
+func (q *Queries) DeleteLoginAttempt(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteLoginAttempt, id)
+	return err
+}
+
+const deleteOldLoginAttempts = `-- name: DeleteOldLoginAttempts :exec
+DELETE FROM login_attempt
+WHERE attempted_at < ?1
+`
+
+func (q *Queries) DeleteOldLoginAttempts(ctx context.Context, before time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteOldLoginAttempts, before)
+	return err
+}
+
+const finishLoginAttempt = `-- name: FinishLoginAttempt :exec
+UPDATE login_attempt
+SET succeeded = ?1, failure_reason = ?2
+WHERE id = ?3
+`
+
+type FinishLoginAttemptParams struct {
+	Succeeded     bool    `json:"succeeded"`
+	FailureReason *string `json:"failure_reason"`
+	ID            string  `json:"id"`
+}
+
+func (q *Queries) FinishLoginAttempt(ctx context.Context, arg FinishLoginAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, finishLoginAttempt, arg.Succeeded, arg.FailureReason, arg.ID)
+	return err
+}
+
+const getLastAccountLoginSuccess = `-- name: GetLastAccountLoginSuccess :one
+SELECT attempted_at
+FROM login_attempt
+WHERE account = ?1 AND action != 'register' AND succeeded = 1
+ORDER BY attempted_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastAccountLoginSuccess(ctx context.Context, account string) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getLastAccountLoginSuccess, account)
+	var attempted_at time.Time
+	err := row.Scan(&attempted_at)
+	return attempted_at, err
+}
+
+const getRecentAccountLoginFailures = `-- name: GetRecentAccountLoginFailures :many
+SELECT attempted_at
+FROM login_attempt
+WHERE account = ?1 AND action != 'register' AND succeeded = 0 AND attempted_at > ?2
+ORDER BY attempted_at DESC
+LIMIT ?3
+`
+
+type GetRecentAccountLoginFailuresParams struct {
+	Account string    `json:"account"`
+	Since   time.Time `json:"since"`
+	MaxRows int64     `json:"max_rows"`
+}
+
+// newest first, registrations do not count against an account
+func (q *Queries) GetRecentAccountLoginFailures(ctx context.Context, arg GetRecentAccountLoginFailuresParams) ([]time.Time, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentAccountLoginFailures, arg.Account, arg.Since, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var attempted_at time.Time
+		if err := rows.Scan(&attempted_at); err != nil {
+			return nil, err
+		}
+		items = append(items, attempted_at)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentIPLoginFailures = `-- name: GetRecentIPLoginFailures :many
+SELECT attempted_at
+FROM login_attempt
+WHERE ip_address = ?1 AND action != 'register' AND succeeded = 0 AND attempted_at > ?2
+ORDER BY attempted_at DESC
+LIMIT ?3
+`
+
+type GetRecentIPLoginFailuresParams struct {
+	IpAddress string    `json:"ip_address"`
+	Since     time.Time `json:"since"`
+	MaxRows   int64     `json:"max_rows"`
+}
+
+func (q *Queries) GetRecentIPLoginFailures(ctx context.Context, arg GetRecentIPLoginFailuresParams) ([]time.Time, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentIPLoginFailures, arg.IpAddress, arg.Since, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var attempted_at time.Time
+		if err := rows.Scan(&attempted_at); err != nil {
+			return nil, err
+		}
+		items = append(items, attempted_at)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserLoginFailures = `-- name: GetUserLoginFailures :many
+SELECT action, ip_address, user_agent, failure_reason, attempted_at
+FROM login_attempt
+WHERE user_id = ?1 AND succeeded = 0
+ORDER BY attempted_at DESC
+LIMIT ?2
+`
+
+type GetUserLoginFailuresParams struct {
+	UserID  *string `json:"user_id"`
+	MaxRows int64   `json:"max_rows"`
+}
+
+type GetUserLoginFailuresRow struct {
+	Action        string    `json:"action"`
+	IpAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	FailureReason *string   `json:"failure_reason"`
+	AttemptedAt   time.Time `json:"attempted_at"`
+}
+
+func (q *Queries) GetUserLoginFailures(ctx context.Context, arg GetUserLoginFailuresParams) ([]GetUserLoginFailuresRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserLoginFailures, arg.UserID, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserLoginFailuresRow
+	for rows.Next() {
+		var i GetUserLoginFailuresRow
+		if err := rows.Scan(
+			&i.Action,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.FailureReason,
+			&i.AttemptedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
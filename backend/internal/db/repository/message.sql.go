@@ -0,0 +1,148 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: message.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createDirectMessage = `-- name: CreateDirectMessage :exec
+INSERT INTO user_private_message (id, sender_id, recipient_id, content)
+VALUES (?1, ?2, ?3, ?4)
+`
+
+type CreateDirectMessageParams struct {
+	ID          string `json:"id"`
+	SenderID    string `json:"sender_id"`
+	RecipientID string `json:"recipient_id"`
+	Content     string `json:"content"`
+}
+
+func (q *Queries) CreateDirectMessage(ctx context.Context, arg CreateDirectMessageParams) error {
+	_, err := q.db.ExecContext(ctx, createDirectMessage,
+		arg.ID,
+		arg.SenderID,
+		arg.RecipientID,
+		arg.Content,
+	)
+	return err
+}
+
+const getDirectMessage = `-- name: GetDirectMessage :one
+SELECT id, sender_id, recipient_id, content, created_at
+FROM user_private_message
+WHERE id = ?
+`
+
+type GetDirectMessageRow struct {
+	ID          string    `json:"id"`
+	SenderID    string    `json:"sender_id"`
+	RecipientID string    `json:"recipient_id"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetDirectMessage(ctx context.Context, id string) (GetDirectMessageRow, error) {
+	row := q.db.QueryRowContext(ctx, getDirectMessage, id)
+	var i GetDirectMessageRow
+	err := row.Scan(
+		&i.ID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.Content,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDirectMessages = `-- name: GetDirectMessages :many
+SELECT id, sender_id, recipient_id, content, created_at
+FROM user_private_message
+WHERE (sender_id = ?1 AND recipient_id = ?2) OR (sender_id = ?2 AND recipient_id = ?1)
+ORDER BY created_at ASC, rowid ASC
+`
+
+type GetDirectMessagesParams struct {
+	UserID  string `json:"user_id"`
+	OtherID string `json:"other_id"`
+}
+
+type GetDirectMessagesRow struct {
+	ID          string    `json:"id"`
+	SenderID    string    `json:"sender_id"`
+	RecipientID string    `json:"recipient_id"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetDirectMessages(ctx context.Context, arg GetDirectMessagesParams) ([]GetDirectMessagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDirectMessages, arg.UserID, arg.OtherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDirectMessagesRow
+	for rows.Next() {
+		var i GetDirectMessagesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.Content,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasDirectMessages = `-- name: HasDirectMessages :one
+SELECT EXISTS(
+    SELECT 1 FROM user_private_message
+    WHERE (sender_id = ?1 AND recipient_id = ?2) OR (sender_id = ?2 AND recipient_id = ?1)
+)
+`
+
+type HasDirectMessagesParams struct {
+	UserID  string `json:"user_id"`
+	OtherID string `json:"other_id"`
+}
+
+func (q *Queries) HasDirectMessages(ctx context.Context, arg HasDirectMessagesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, hasDirectMessages, arg.UserID, arg.OtherID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const shareClub = `-- name: ShareClub :one
+SELECT EXISTS(
+    SELECT 1 FROM club_membership a
+    JOIN club_membership b ON b.club_id = a.club_id
+    WHERE a.user_id = ?1 AND b.user_id = ?2
+)
+`
+
+type ShareClubParams struct {
+	UserID  string `json:"user_id"`
+	OtherID string `json:"other_id"`
+}
+
+func (q *Queries) ShareClub(ctx context.Context, arg ShareClubParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, shareClub, arg.UserID, arg.OtherID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
@@ -223,7 +223,7 @@ func (q *Queries) GetHistoricalMetricEntries(ctx context.Context, metricID strin
 }
 
 const getLatestMetricInstance = `-- name: GetLatestMetricInstance :one
-SELECT id, metric_id, due_at, created_at, updated_at FROM metric_instance WHERE metric_id = ? ORDER BY created_at DESC LIMIT 1
+SELECT id, metric_id, due_at, created_at, updated_at FROM metric_instance WHERE metric_id = ? ORDER BY created_at DESC, rowid DESC LIMIT 1
 `
 
 func (q *Queries) GetLatestMetricInstance(ctx context.Context, metricID string) (MetricInstance, error) {
@@ -295,6 +295,48 @@ func (q *Queries) GetMetricEntries(ctx context.Context, metricInstanceID string)
 	return items, nil
 }
 
+const hasEntryInPreviousMetricInstance = `-- name: HasEntryInPreviousMetricInstance :one
+SELECT EXISTS(
+    SELECT 1 FROM metric_entry
+    WHERE user_id = ?1 AND metric_instance_id = (
+        SELECT id FROM metric_instance
+        WHERE metric_id = ?2 AND id != ?3
+        ORDER BY created_at DESC, rowid DESC
+        LIMIT 1
+    )
+)
+`
+
+type HasEntryInPreviousMetricInstanceParams struct {
+	UserID           string `json:"user_id"`
+	MetricID         string `json:"metric_id"`
+	MetricInstanceID string `json:"metric_instance_id"`
+}
+
+// whether the user turned in the instance of the metric before the given one
+func (q *Queries) HasEntryInPreviousMetricInstance(ctx context.Context, arg HasEntryInPreviousMetricInstanceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, hasEntryInPreviousMetricInstance, arg.UserID, arg.MetricID, arg.MetricInstanceID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const hasMetricEntry = `-- name: HasMetricEntry :one
+SELECT EXISTS(SELECT 1 FROM metric_entry WHERE user_id = ?1 AND metric_instance_id = ?2)
+`
+
+type HasMetricEntryParams struct {
+	UserID           string `json:"user_id"`
+	MetricInstanceID string `json:"metric_instance_id"`
+}
+
+func (q *Queries) HasMetricEntry(ctx context.Context, arg HasMetricEntryParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, hasMetricEntry, arg.UserID, arg.MetricInstanceID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const updateMetric = `-- name: UpdateMetric :exec
 UPDATE metric
 SET
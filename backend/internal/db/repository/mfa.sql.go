@@ -0,0 +1,151 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: mfa.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const countUnusedUserRecoveryCodes = `-- name: CountUnusedUserRecoveryCodes :one
+SELECT COUNT(*) FROM user_recovery_code
+WHERE user_id = ?1 AND used_at IS NULL
+`
+
+func (q *Queries) CountUnusedUserRecoveryCodes(ctx context.Context, userID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnusedUserRecoveryCodes, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createUserRecoveryCode = `-- name: CreateUserRecoveryCode :exec
+INSERT INTO user_recovery_code (user_id, code_hash)
+VALUES (?1, ?2)
+`
+
+type CreateUserRecoveryCodeParams struct {
+	UserID   string `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) CreateUserRecoveryCode(ctx context.Context, arg CreateUserRecoveryCodeParams) error {
+	_, err := q.db.ExecContext(ctx, createUserRecoveryCode, arg.UserID, arg.CodeHash)
+	return err
+}
+
+const deleteUserRecoveryCodes = `-- name: DeleteUserRecoveryCodes :exec
+DELETE FROM user_recovery_code
+WHERE user_id = ?1
+`
+
+func (q *Queries) DeleteUserRecoveryCodes(ctx context.Context, userID string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserRecoveryCodes, userID)
+	return err
+}
+
+const disableUserTOTP = `-- name: DisableUserTOTP :exec
+UPDATE user
+SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL
+WHERE id = ?1
+`
+
+func (q *Queries) DisableUserTOTP(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, disableUserTOTP, id)
+	return err
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :exec
+UPDATE user
+SET totp_enabled_at = CURRENT_TIMESTAMP
+WHERE id = ?1 AND totp_secret IS NOT NULL
+`
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, enableUserTOTP, id)
+	return err
+}
+
+const getUserTOTP = `-- name: GetUserTOTP :one
+SELECT username, totp_secret, totp_enabled_at, totp_last_step
+FROM user
+WHERE id = ?
+`
+
+type GetUserTOTPRow struct {
+	Username      string     `json:"username"`
+	TotpSecret    *string    `json:"totp_secret"`
+	TotpEnabledAt *time.Time `json:"totp_enabled_at"`
+	TotpLastStep  *int64     `json:"totp_last_step"`
+}
+
+func (q *Queries) GetUserTOTP(ctx context.Context, id string) (GetUserTOTPRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserTOTP, id)
+	var i GetUserTOTPRow
+	err := row.Scan(
+		&i.Username,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const setUserTOTPSecret = `-- name: SetUserTOTPSecret :exec
+UPDATE user
+SET totp_secret = ?1, totp_enabled_at = NULL, totp_last_step = NULL
+WHERE id = ?2
+`
+
+type SetUserTOTPSecretParams struct {
+	TotpSecret *string `json:"totp_secret"`
+	ID         string  `json:"id"`
+}
+
+// starts enrollment, two factor auth is not active until EnableUserTOTP
+func (q *Queries) SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) error {
+	_, err := q.db.ExecContext(ctx, setUserTOTPSecret, arg.TotpSecret, arg.ID)
+	return err
+}
+
+const updateUserTOTPLastStep = `-- name: UpdateUserTOTPLastStep :execrows
+UPDATE user
+SET totp_last_step = ?1
+WHERE id = ?2 AND (totp_last_step IS NULL OR totp_last_step < ?1)
+`
+
+type UpdateUserTOTPLastStepParams struct {
+	Step *int64 `json:"step"`
+	ID   string `json:"id"`
+}
+
+// only moves forward, so a code that was already accepted cannot be used again
+func (q *Queries) UpdateUserTOTPLastStep(ctx context.Context, arg UpdateUserTOTPLastStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserTOTPLastStep, arg.Step, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const useUserRecoveryCode = `-- name: UseUserRecoveryCode :execrows
+UPDATE user_recovery_code
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = ?1 AND code_hash = ?2 AND used_at IS NULL
+`
+
+type UseUserRecoveryCodeParams struct {
+	UserID   string `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) UseUserRecoveryCode(ctx context.Context, arg UseUserRecoveryCodeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useUserRecoveryCode, arg.UserID, arg.CodeHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
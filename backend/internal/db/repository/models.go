@@ -8,15 +8,62 @@ import (
 	"time"
 )
 
+type AdminAuditLog struct {
+	ID         string    `json:"id"`
+	AdminID    string    `json:"admin_id"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	Reason     string    `json:"reason"`
+	IpAddress  string    `json:"ip_address"`
+	ReportID   *string   `json:"report_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type Auction struct {
+	ID           string    `json:"id"`
+	ItemID       string    `json:"item_id"`
+	ClubID       string    `json:"club_id"`
+	SellerID     string    `json:"seller_id"`
+	StartPrice   float64   `json:"start_price"`
+	ReservePrice *float64  `json:"reserve_price"`
+	BidIncrement float64   `json:"bid_increment"`
+	Currency     string    `json:"currency"`
+	EndsAt       time.Time `json:"ends_at"`
+	Status       string    `json:"status"`
+	WinnerID     *string   `json:"winner_id"`
+	WinningBid   *float64  `json:"winning_bid"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type AuctionBid struct {
+	ID        string    `json:"id"`
+	AuctionID string    `json:"auction_id"`
+	BidderID  string    `json:"bidder_id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Club struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description"`
-	OwnerUserID string    `json:"owner_user_id"`
-	BannerImage *string   `json:"banner_image"`
-	IsPublic    bool      `json:"is_public"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                   string    `json:"id"`
+	Name                 string    `json:"name"`
+	Description          *string   `json:"description"`
+	OwnerUserID          string    `json:"owner_user_id"`
+	BannerImage          *string   `json:"banner_image"`
+	IsPublic             bool      `json:"is_public"`
+	MinListingReputation float64   `json:"min_listing_reputation"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+type ClubBlockedTerm struct {
+	ClubID    string    `json:"club_id"`
+	Term      string    `json:"term"`
+	Action    string    `json:"action"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type ClubMembership struct {
@@ -30,12 +77,14 @@ type ClubMembership struct {
 }
 
 type ClubPost struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	ClubID    string    `json:"club_id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               string    `json:"id"`
+	UserID           *string   `json:"user_id"`
+	ClubID           string    `json:"club_id"`
+	Content          string    `json:"content"`
+	ModerationStatus string    `json:"moderation_status"`
+	ModerationReason *string   `json:"moderation_reason"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 type ClubPostAttachment struct {
@@ -46,20 +95,83 @@ type ClubPostAttachment struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type ClubReward struct {
+	ID          string    `json:"id"`
+	ClubID      string    `json:"club_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Cost        float64   `json:"cost"`
+	Stock       *int64    `json:"stock"`
+	IsActive    bool      `json:"is_active"`
+	ItemID      *string   `json:"item_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
 type ClubTag struct {
 	ClubID string `json:"club_id"`
 	Tag    string `json:"tag"`
 }
 
 type Item struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description"`
-	IsAvailable bool      `json:"is_available"`
-	OwnerID     string    `json:"owner_id"`
-	ClubID      *string   `json:"club_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	Description      *string    `json:"description"`
+	PriceEstimate    *float64   `json:"price_estimate"`
+	Currency         *string    `json:"currency"`
+	Condition        *string    `json:"condition"`
+	Category         *string    `json:"category"`
+	PickupLocation   *string    `json:"pickup_location"`
+	CanShip          bool       `json:"can_ship"`
+	IsAvailable      bool       `json:"is_available"`
+	OwnerID          string     `json:"owner_id"`
+	ClubID           *string    `json:"club_id"`
+	ModerationStatus string     `json:"moderation_status"`
+	ModerationReason *string    `json:"moderation_reason"`
+	ReservedFor      *string    `json:"reserved_for"`
+	ReservedUntil    *time.Time `json:"reserved_until"`
+	ListingType      string     `json:"listing_type"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type ItemImage struct {
+	ID           string    `json:"id"`
+	ItemID       string    `json:"item_id"`
+	Url          string    `json:"url"`
+	ThumbnailUrl string    `json:"thumbnail_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ItemSearch struct {
+	ItemID      string `json:"item_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type JwtSigningKey struct {
+	Kid        string    `json:"kid"`
+	Algorithm  string    `json:"algorithm"`
+	PrivateKey string    `json:"private_key"`
+	ActiveAt   time.Time `json:"active_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type LoginAttempt struct {
+	ID            string    `json:"id"`
+	Action        string    `json:"action"`
+	Account       string    `json:"account"`
+	Identifier    string    `json:"identifier"`
+	UserID        *string   `json:"user_id"`
+	IpAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	Succeeded     bool      `json:"succeeded"`
+	FailureReason *string   `json:"failure_reason"`
+	AttemptedAt   time.Time `json:"attempted_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type Metric struct {
@@ -111,25 +223,161 @@ type MetricInstance struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type ModerationAction struct {
+	ID          string    `json:"id"`
+	ModeratorID *string   `json:"moderator_id"`
+	Action      string    `json:"action"`
+	TargetType  string    `json:"target_type"`
+	TargetID    string    `json:"target_id"`
+	ClubID      *string   `json:"club_id"`
+	AsAdmin     bool      `json:"as_admin"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type OidcLoginState struct {
+	State            string    `json:"state"`
+	Provider         string    `json:"provider"`
+	CodeVerifierHash string    `json:"code_verifier_hash"`
+	Nonce            string    `json:"nonce"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type PersonalAccessToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"token_hash"`
+	Scopes     string     `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type PointLedger struct {
+	ID          string    `json:"id"`
+	ClubID      string    `json:"club_id"`
+	UserID      string    `json:"user_id"`
+	Amount      float64   `json:"amount"`
+	Balance     float64   `json:"balance"`
+	Reason      string    `json:"reason"`
+	ReferenceID *string   `json:"reference_id"`
+	ActorID     *string   `json:"actor_id"`
+	Note        *string   `json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Report struct {
+	ID                 string     `json:"id"`
+	TargetType         string     `json:"target_type"`
+	TargetID           string     `json:"target_id"`
+	ClubID             *string    `json:"club_id"`
+	Status             string     `json:"status"`
+	HandledBy          *string    `json:"handled_by"`
+	ResolutionAction   *string    `json:"resolution_action"`
+	ResolutionNote     *string    `json:"resolution_note"`
+	ResolutionActionID *string    `json:"resolution_action_id"`
+	ResolvedAt         *time.Time `json:"resolved_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+type ReportSubmission struct {
+	ReportID   string    `json:"report_id"`
+	ReporterID string    `json:"reporter_id"`
+	Category   string    `json:"category"`
+	Comment    string    `json:"comment"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type RewardRedemption struct {
+	ID        string     `json:"id"`
+	RewardID  string     `json:"reward_id"`
+	ClubID    string     `json:"club_id"`
+	UserID    string     `json:"user_id"`
+	Cost      float64    `json:"cost"`
+	Note      *string    `json:"note"`
+	Status    string     `json:"status"`
+	HandledBy *string    `json:"handled_by"`
+	HandledAt *time.Time `json:"handled_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type SavedSearch struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Name       string    `json:"name"`
+	Query      string    `json:"query"`
+	MatchQuery string    `json:"match_query"`
+	Category   *string   `json:"category"`
+	Condition  *string   `json:"condition"`
+	OwnerID    *string   `json:"owner_id"`
+	Currency   *string   `json:"currency"`
+	MinPrice   *float64  `json:"min_price"`
+	MaxPrice   *float64  `json:"max_price"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
 type Trade struct {
-	ID              string    `json:"id"`
-	ProposerID      string    `json:"proposer_id"`
-	ProposerItemID  string    `json:"proposer_item_id"`
-	ResponderID     string    `json:"responder_id"`
-	ResponderItemID string    `json:"responder_item_id"`
-	Status          string    `json:"status"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID               string    `json:"id"`
+	ProposerID       string    `json:"proposer_id"`
+	ResponderID      string    `json:"responder_id"`
+	Status           string    `json:"status"`
+	CounteredTradeID *string   `json:"countered_trade_id"`
+	ClubID           *string   `json:"club_id"`
+	ProposerPoints   float64   `json:"proposer_points"`
+	ResponderPoints  float64   `json:"responder_points"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type TradeItem struct {
+	TradeID string `json:"trade_id"`
+	ItemID  string `json:"item_id"`
+	OwnerID string `json:"owner_id"`
+}
+
+type TradeReview struct {
+	TradeID    string    `json:"trade_id"`
+	ReviewerID string    `json:"reviewer_id"`
+	RevieweeID string    `json:"reviewee_id"`
+	Rating     int64     `json:"rating"`
+	Comment    *string   `json:"comment"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
 
 type User struct {
-	ID             string    `json:"id"`
-	Email          string    `json:"email"`
-	Username       string    `json:"username"`
-	Password       string    `json:"password"`
-	ProfilePicture *string   `json:"profile_picture"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID                  string     `json:"id"`
+	Email               string     `json:"email"`
+	Username            string     `json:"username"`
+	Password            string     `json:"password"`
+	ProfilePicture      *string    `json:"profile_picture"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	TotpSecret          *string    `json:"totp_secret"`
+	TotpEnabledAt       *time.Time `json:"totp_enabled_at"`
+	TotpLastStep        *int64     `json:"totp_last_step"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	DisplayName         *string    `json:"display_name"`
+	Bio                 *string    `json:"bio"`
+	Location            *string    `json:"location"`
+	Pronouns            *string    `json:"pronouns"`
+	ProfileVisibility   string     `json:"profile_visibility"`
+	ShowClubs           bool       `json:"show_clubs"`
+	ShowStats           bool       `json:"show_stats"`
+	ShowItems           bool       `json:"show_items"`
+	Role                string     `json:"role"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	DisabledReason      *string    `json:"disabled_reason"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 type UserFriendship struct {
@@ -139,6 +387,24 @@ type UserFriendship struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type UserIdentity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	UserID    string    `json:"user_id"`
+	Email     *string   `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UserLink struct {
+	UserID    string    `json:"user_id"`
+	Position  int64     `json:"position"`
+	Label     string    `json:"label"`
+	Url       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type UserPrivateMessage struct {
 	ID          string    `json:"id"`
 	SenderID    string    `json:"sender_id"`
@@ -155,3 +421,41 @@ type UserPrivateMessageAttachment struct {
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
 }
+
+type UserRecoveryCode struct {
+	UserID    string     `json:"user_id"`
+	CodeHash  string     `json:"code_hash"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type UserSession struct {
+	ID               string     `json:"id"`
+	UserID           string     `json:"user_id"`
+	RefreshTokenHash string     `json:"refresh_token_hash"`
+	UserAgent        string     `json:"user_agent"`
+	IpAddress        string     `json:"ip_address"`
+	LastSeenAt       time.Time  `json:"last_seen_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	ImpersonatorID   *string    `json:"impersonator_id"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type UserToken struct {
+	TokenHash string     `json:"token_hash"`
+	UserID    string     `json:"user_id"`
+	Purpose   string     `json:"purpose"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type Wishlist struct {
+	UserID    string    `json:"user_id"`
+	ItemID    string    `json:"item_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
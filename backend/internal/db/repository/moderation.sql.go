@@ -0,0 +1,354 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: moderation.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const approveClubPost = `-- name: ApproveClubPost :execrows
+UPDATE club_post
+SET moderation_status = 'approved', moderation_reason = NULL
+WHERE id = ?1 AND club_id = ?2 AND moderation_status = 'pending'
+`
+
+type ApproveClubPostParams struct {
+	ID     string `json:"id"`
+	ClubID string `json:"club_id"`
+}
+
+func (q *Queries) ApproveClubPost(ctx context.Context, arg ApproveClubPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, approveClubPost, arg.ID, arg.ClubID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const approveItem = `-- name: ApproveItem :execrows
+UPDATE items
+SET moderation_status = 'approved', moderation_reason = NULL
+WHERE id = ?1 AND club_id = ?2 AND moderation_status = 'pending'
+`
+
+type ApproveItemParams struct {
+	ID     string  `json:"id"`
+	ClubID *string `json:"club_id"`
+}
+
+func (q *Queries) ApproveItem(ctx context.Context, arg ApproveItemParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, approveItem, arg.ID, arg.ClubID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countRecentClubPostsByUser = `-- name: CountRecentClubPostsByUser :one
+SELECT COUNT(*)
+FROM club_post
+WHERE user_id = ?1 AND id != ?2 AND created_at > CAST(?3 AS TEXT)
+`
+
+type CountRecentClubPostsByUserParams struct {
+	UserID    *string `json:"user_id"`
+	ExcludeID string  `json:"exclude_id"`
+	Since     string  `json:"since"`
+}
+
+// since is formatted like CURRENT_TIMESTAMP, the post with exclude_id is left out
+func (q *Queries) CountRecentClubPostsByUser(ctx context.Context, arg CountRecentClubPostsByUserParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecentClubPostsByUser, arg.UserID, arg.ExcludeID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRecentClubPostsWithLink = `-- name: CountRecentClubPostsWithLink :one
+SELECT COUNT(*)
+FROM club_post
+WHERE user_id = ?1 AND id != ?2 AND created_at > CAST(?3 AS TEXT) AND instr(lower(content), ?4) > 0
+`
+
+type CountRecentClubPostsWithLinkParams struct {
+	UserID    *string `json:"user_id"`
+	ExcludeID string  `json:"exclude_id"`
+	Since     string  `json:"since"`
+	Link      string  `json:"link"`
+}
+
+func (q *Queries) CountRecentClubPostsWithLink(ctx context.Context, arg CountRecentClubPostsWithLinkParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecentClubPostsWithLink,
+		arg.UserID,
+		arg.ExcludeID,
+		arg.Since,
+		arg.Link,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRecentItemsByOwner = `-- name: CountRecentItemsByOwner :one
+SELECT COUNT(*)
+FROM items
+WHERE owner_id = ?1 AND id != ?2 AND created_at > CAST(?3 AS TEXT)
+`
+
+type CountRecentItemsByOwnerParams struct {
+	OwnerID   string `json:"owner_id"`
+	ExcludeID string `json:"exclude_id"`
+	Since     string `json:"since"`
+}
+
+func (q *Queries) CountRecentItemsByOwner(ctx context.Context, arg CountRecentItemsByOwnerParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecentItemsByOwner, arg.OwnerID, arg.ExcludeID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRecentItemsWithLink = `-- name: CountRecentItemsWithLink :one
+SELECT COUNT(*)
+FROM items
+WHERE owner_id = ?1 AND id != ?2 AND created_at > CAST(?3 AS TEXT)
+    AND instr(lower(name || ' ' || COALESCE(description, '')), ?4) > 0
+`
+
+type CountRecentItemsWithLinkParams struct {
+	OwnerID   string `json:"owner_id"`
+	ExcludeID string `json:"exclude_id"`
+	Since     string `json:"since"`
+	Link      string `json:"link"`
+}
+
+func (q *Queries) CountRecentItemsWithLink(ctx context.Context, arg CountRecentItemsWithLinkParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecentItemsWithLink,
+		arg.OwnerID,
+		arg.ExcludeID,
+		arg.Since,
+		arg.Link,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteClubBlockedTerm = `-- name: DeleteClubBlockedTerm :execrows
+DELETE FROM club_blocked_term
+WHERE club_id = ?1 AND term = ?2
+`
+
+type DeleteClubBlockedTermParams struct {
+	ClubID string `json:"club_id"`
+	Term   string `json:"term"`
+}
+
+func (q *Queries) DeleteClubBlockedTerm(ctx context.Context, arg DeleteClubBlockedTermParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteClubBlockedTerm, arg.ClubID, arg.Term)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getClubBlockedTerms = `-- name: GetClubBlockedTerms :many
+SELECT club_id, term, "action", created_at, updated_at
+FROM club_blocked_term
+WHERE club_id = ?1
+ORDER BY term
+`
+
+func (q *Queries) GetClubBlockedTerms(ctx context.Context, clubID string) ([]ClubBlockedTerm, error) {
+	rows, err := q.db.QueryContext(ctx, getClubBlockedTerms, clubID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClubBlockedTerm
+	for rows.Next() {
+		var i ClubBlockedTerm
+		if err := rows.Scan(
+			&i.ClubID,
+			&i.Term,
+			&i.Action,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHeldClubItems = `-- name: GetHeldClubItems :many
+SELECT i.id, i.name, i.description, i.price_estimate, i.currency, i.condition, i.category, i.pickup_location, i.can_ship, i.is_available, i.owner_id, i.club_id, i.moderation_status, i.moderation_reason, i.reserved_for, i.reserved_until, i.listing_type, i.created_at, i.updated_at, u.username AS owner_username
+FROM items i
+JOIN user u ON u.id = i.owner_id
+WHERE i.club_id = ?1 AND i.moderation_status = 'pending'
+ORDER BY i.created_at ASC
+`
+
+type GetHeldClubItemsRow struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	Description      *string    `json:"description"`
+	PriceEstimate    *float64   `json:"price_estimate"`
+	Currency         *string    `json:"currency"`
+	Condition        *string    `json:"condition"`
+	Category         *string    `json:"category"`
+	PickupLocation   *string    `json:"pickup_location"`
+	CanShip          bool       `json:"can_ship"`
+	IsAvailable      bool       `json:"is_available"`
+	OwnerID          string     `json:"owner_id"`
+	ClubID           *string    `json:"club_id"`
+	ModerationStatus string     `json:"moderation_status"`
+	ModerationReason *string    `json:"moderation_reason"`
+	ReservedFor      *string    `json:"reserved_for"`
+	ReservedUntil    *time.Time `json:"reserved_until"`
+	ListingType      string     `json:"listing_type"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	OwnerUsername    string     `json:"owner_username"`
+}
+
+func (q *Queries) GetHeldClubItems(ctx context.Context, clubID *string) ([]GetHeldClubItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getHeldClubItems, clubID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetHeldClubItemsRow
+	for rows.Next() {
+		var i GetHeldClubItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.PriceEstimate,
+			&i.Currency,
+			&i.Condition,
+			&i.Category,
+			&i.PickupLocation,
+			&i.CanShip,
+			&i.IsAvailable,
+			&i.OwnerID,
+			&i.ClubID,
+			&i.ModerationStatus,
+			&i.ModerationReason,
+			&i.ReservedFor,
+			&i.ReservedUntil,
+			&i.ListingType,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OwnerUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHeldClubPosts = `-- name: GetHeldClubPosts :many
+SELECT cp.id, cp.user_id, cp.club_id, cp.content, cp.moderation_status, cp.moderation_reason, cp.created_at, cp.updated_at, COALESCE(u.username, '[deleted]') AS author_username
+FROM club_post cp
+LEFT JOIN user u ON u.id = cp.user_id
+WHERE cp.club_id = ?1 AND cp.moderation_status = 'pending'
+ORDER BY cp.created_at ASC
+`
+
+type GetHeldClubPostsRow struct {
+	ID               string    `json:"id"`
+	UserID           *string   `json:"user_id"`
+	ClubID           string    `json:"club_id"`
+	Content          string    `json:"content"`
+	ModerationStatus string    `json:"moderation_status"`
+	ModerationReason *string   `json:"moderation_reason"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	AuthorUsername   string    `json:"author_username"`
+}
+
+func (q *Queries) GetHeldClubPosts(ctx context.Context, clubID string) ([]GetHeldClubPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getHeldClubPosts, clubID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetHeldClubPostsRow
+	for rows.Next() {
+		var i GetHeldClubPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ClubID,
+			&i.Content,
+			&i.ModerationStatus,
+			&i.ModerationReason,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AuthorUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const holdItem = `-- name: HoldItem :exec
+UPDATE items
+SET moderation_status = 'pending', moderation_reason = ?1
+WHERE id = ?2
+`
+
+type HoldItemParams struct {
+	ModerationReason *string `json:"moderation_reason"`
+	ID               string  `json:"id"`
+}
+
+func (q *Queries) HoldItem(ctx context.Context, arg HoldItemParams) error {
+	_, err := q.db.ExecContext(ctx, holdItem, arg.ModerationReason, arg.ID)
+	return err
+}
+
+const upsertClubBlockedTerm = `-- name: UpsertClubBlockedTerm :exec
+INSERT INTO club_blocked_term (club_id, term, action)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (club_id, term) DO UPDATE SET action = excluded.action
+`
+
+type UpsertClubBlockedTermParams struct {
+	ClubID string `json:"club_id"`
+	Term   string `json:"term"`
+	Action string `json:"action"`
+}
+
+func (q *Queries) UpsertClubBlockedTerm(ctx context.Context, arg UpsertClubBlockedTermParams) error {
+	_, err := q.db.ExecContext(ctx, upsertClubBlockedTerm, arg.ClubID, arg.Term, arg.Action)
+	return err
+}
@@ -0,0 +1,170 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: oidc.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const consumeOIDCLoginState = `-- name: ConsumeOIDCLoginState :one
+DELETE FROM oidc_login_state
+WHERE state = ?1 AND provider = ?2 AND code_verifier_hash = ?3
+RETURNING nonce, expires_at
+`
+
+type ConsumeOIDCLoginStateParams struct {
+	State            string `json:"state"`
+	Provider         string `json:"provider"`
+	CodeVerifierHash string `json:"code_verifier_hash"`
+}
+
+type ConsumeOIDCLoginStateRow struct {
+	Nonce     string    `json:"nonce"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// deletes the state in the same statement that reads it so a callback can only be redeemed once.
+// a callback without the verifier the login was started with leaves the state for the app that started it
+func (q *Queries) ConsumeOIDCLoginState(ctx context.Context, arg ConsumeOIDCLoginStateParams) (ConsumeOIDCLoginStateRow, error) {
+	row := q.db.QueryRowContext(ctx, consumeOIDCLoginState, arg.State, arg.Provider, arg.CodeVerifierHash)
+	var i ConsumeOIDCLoginStateRow
+	err := row.Scan(&i.Nonce, &i.ExpiresAt)
+	return i, err
+}
+
+const createOIDCLoginState = `-- name: CreateOIDCLoginState :exec
+INSERT INTO oidc_login_state (state, provider, code_verifier_hash, nonce, expires_at)
+VALUES (?1, ?2, ?3, ?4, ?5)
+`
+
+type CreateOIDCLoginStateParams struct {
+	State            string    `json:"state"`
+	Provider         string    `json:"provider"`
+	CodeVerifierHash string    `json:"code_verifier_hash"`
+	Nonce            string    `json:"nonce"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateOIDCLoginState(ctx context.Context, arg CreateOIDCLoginStateParams) error {
+	_, err := q.db.ExecContext(ctx, createOIDCLoginState,
+		arg.State,
+		arg.Provider,
+		arg.CodeVerifierHash,
+		arg.Nonce,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createUserIdentity = `-- name: CreateUserIdentity :exec
+INSERT INTO user_identity (provider, subject, user_id, email)
+VALUES (?1, ?2, ?3, ?4)
+`
+
+type CreateUserIdentityParams struct {
+	Provider string  `json:"provider"`
+	Subject  string  `json:"subject"`
+	UserID   string  `json:"user_id"`
+	Email    *string `json:"email"`
+}
+
+func (q *Queries) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) error {
+	_, err := q.db.ExecContext(ctx, createUserIdentity,
+		arg.Provider,
+		arg.Subject,
+		arg.UserID,
+		arg.Email,
+	)
+	return err
+}
+
+const deleteExpiredOIDCLoginStates = `-- name: DeleteExpiredOIDCLoginStates :exec
+DELETE FROM oidc_login_state
+WHERE expires_at < ?1
+`
+
+func (q *Queries) DeleteExpiredOIDCLoginStates(ctx context.Context, now time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredOIDCLoginStates, now)
+	return err
+}
+
+const getUserIdentities = `-- name: GetUserIdentities :many
+SELECT provider, email, created_at
+FROM user_identity
+WHERE user_id = ?1
+ORDER BY created_at
+`
+
+type GetUserIdentitiesRow struct {
+	Provider  string    `json:"provider"`
+	Email     *string   `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetUserIdentities(ctx context.Context, userID string) ([]GetUserIdentitiesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserIdentities, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserIdentitiesRow
+	for rows.Next() {
+		var i GetUserIdentitiesRow
+		if err := rows.Scan(&i.Provider, &i.Email, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserIdentity = `-- name: GetUserIdentity :one
+SELECT provider, subject, user_id, email
+FROM user_identity
+WHERE provider = ?1 AND subject = ?2
+`
+
+type GetUserIdentityParams struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+}
+
+type GetUserIdentityRow struct {
+	Provider string  `json:"provider"`
+	Subject  string  `json:"subject"`
+	UserID   string  `json:"user_id"`
+	Email    *string `json:"email"`
+}
+
+func (q *Queries) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (GetUserIdentityRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserIdentity, arg.Provider, arg.Subject)
+	var i GetUserIdentityRow
+	err := row.Scan(
+		&i.Provider,
+		&i.Subject,
+		&i.UserID,
+		&i.Email,
+	)
+	return i, err
+}
+
+const usernameExists = `-- name: UsernameExists :one
+SELECT EXISTS (SELECT 1 FROM user WHERE username = ?1)
+`
+
+func (q *Queries) UsernameExists(ctx context.Context, username string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, usernameExists, username)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
@@ -0,0 +1,168 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: personal_access_token.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createPersonalAccessToken = `-- name: CreatePersonalAccessToken :exec
+INSERT INTO personal_access_token (id, user_id, name, token_hash, scopes, expires_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+`
+
+type CreatePersonalAccessTokenParams struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	TokenHash string    `json:"token_hash"`
+	Scopes    string    `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createPersonalAccessToken,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.TokenHash,
+		arg.Scopes,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const deleteExpiredPersonalAccessTokens = `-- name: DeleteExpiredPersonalAccessTokens :exec
+DELETE FROM personal_access_token
+WHERE expires_at < ?1
+`
+
+func (q *Queries) DeleteExpiredPersonalAccessTokens(ctx context.Context, now time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredPersonalAccessTokens, now)
+	return err
+}
+
+const getPersonalAccessTokenByHash = `-- name: GetPersonalAccessTokenByHash :one
+SELECT id, user_id, scopes, expires_at, last_used_at, revoked_at
+FROM personal_access_token
+WHERE token_hash = ?1
+`
+
+type GetPersonalAccessTokenByHashRow struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Scopes     string     `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+func (q *Queries) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (GetPersonalAccessTokenByHashRow, error) {
+	row := q.db.QueryRowContext(ctx, getPersonalAccessTokenByHash, tokenHash)
+	var i GetPersonalAccessTokenByHashRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Scopes,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getUserPersonalAccessTokens = `-- name: GetUserPersonalAccessTokens :many
+SELECT id, name, scopes, expires_at, last_used_at, created_at
+FROM personal_access_token
+WHERE user_id = ?1 AND revoked_at IS NULL AND expires_at > ?2
+ORDER BY created_at DESC
+`
+
+type GetUserPersonalAccessTokensParams struct {
+	UserID string    `json:"user_id"`
+	Now    time.Time `json:"now"`
+}
+
+type GetUserPersonalAccessTokensRow struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     string     `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (q *Queries) GetUserPersonalAccessTokens(ctx context.Context, arg GetUserPersonalAccessTokensParams) ([]GetUserPersonalAccessTokensRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserPersonalAccessTokens, arg.UserID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserPersonalAccessTokensRow
+	for rows.Next() {
+		var i GetUserPersonalAccessTokensRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Scopes,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAllPersonalAccessTokens = `-- name: RevokeAllPersonalAccessTokens :exec
+UPDATE personal_access_token
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = ?1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAllPersonalAccessTokens(ctx context.Context, userID string) error {
+	_, err := q.db.ExecContext(ctx, revokeAllPersonalAccessTokens, userID)
+	return err
+}
+
+const revokePersonalAccessToken = `-- name: RevokePersonalAccessToken :execrows
+UPDATE personal_access_token
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ?1 AND user_id = ?2 AND revoked_at IS NULL
+`
+
+type RevokePersonalAccessTokenParams struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) RevokePersonalAccessToken(ctx context.Context, arg RevokePersonalAccessTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokePersonalAccessToken, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const touchPersonalAccessToken = `-- name: TouchPersonalAccessToken :exec
+UPDATE personal_access_token
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = ?1
+`
+
+func (q *Queries) TouchPersonalAccessToken(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, touchPersonalAccessToken, id)
+	return err
+}
@@ -0,0 +1,186 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: points.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createPointLedgerEntry = `-- name: CreatePointLedgerEntry :exec
+INSERT INTO point_ledger (id, club_id, user_id, amount, balance, reason, reference_id, actor_id, note)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreatePointLedgerEntryParams struct {
+	ID          string  `json:"id"`
+	ClubID      string  `json:"club_id"`
+	UserID      string  `json:"user_id"`
+	Amount      float64 `json:"amount"`
+	Balance     float64 `json:"balance"`
+	Reason      string  `json:"reason"`
+	ReferenceID *string `json:"reference_id"`
+	ActorID     *string `json:"actor_id"`
+	Note        *string `json:"note"`
+}
+
+func (q *Queries) CreatePointLedgerEntry(ctx context.Context, arg CreatePointLedgerEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createPointLedgerEntry,
+		arg.ID,
+		arg.ClubID,
+		arg.UserID,
+		arg.Amount,
+		arg.Balance,
+		arg.Reason,
+		arg.ReferenceID,
+		arg.ActorID,
+		arg.Note,
+	)
+	return err
+}
+
+const getPointHistory = `-- name: GetPointHistory :many
+SELECT
+    point_ledger.id, point_ledger.club_id, point_ledger.user_id, point_ledger.amount, point_ledger.balance, point_ledger.reason, point_ledger.reference_id, point_ledger.actor_id, point_ledger.note, point_ledger.created_at, point_ledger.updated_at,
+    user.username AS actor_username
+FROM point_ledger
+LEFT JOIN user ON user.id = point_ledger.actor_id
+WHERE point_ledger.club_id = ?1 AND point_ledger.user_id = ?2
+ORDER BY point_ledger.created_at DESC, point_ledger.rowid DESC
+LIMIT ?4 OFFSET ?3
+`
+
+type GetPointHistoryParams struct {
+	ClubID string `json:"club_id"`
+	UserID string `json:"user_id"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+type GetPointHistoryRow struct {
+	ID            string    `json:"id"`
+	ClubID        string    `json:"club_id"`
+	UserID        string    `json:"user_id"`
+	Amount        float64   `json:"amount"`
+	Balance       float64   `json:"balance"`
+	Reason        string    `json:"reason"`
+	ReferenceID   *string   `json:"reference_id"`
+	ActorID       *string   `json:"actor_id"`
+	Note          *string   `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	ActorUsername *string   `json:"actor_username"`
+}
+
+// the newest first
+func (q *Queries) GetPointHistory(ctx context.Context, arg GetPointHistoryParams) ([]GetPointHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPointHistory,
+		arg.ClubID,
+		arg.UserID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPointHistoryRow
+	for rows.Next() {
+		var i GetPointHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClubID,
+			&i.UserID,
+			&i.Amount,
+			&i.Balance,
+			&i.Reason,
+			&i.ReferenceID,
+			&i.ActorID,
+			&i.Note,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ActorUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPointLedger = `-- name: GetPointLedger :many
+SELECT id, club_id, user_id, amount, balance, reason, reference_id, actor_id, note, created_at, updated_at FROM point_ledger
+WHERE club_id = ? AND user_id = ?
+ORDER BY created_at, rowid
+`
+
+type GetPointLedgerParams struct {
+	ClubID string `json:"club_id"`
+	UserID string `json:"user_id"`
+}
+
+// the oldest first
+func (q *Queries) GetPointLedger(ctx context.Context, arg GetPointLedgerParams) ([]PointLedger, error) {
+	rows, err := q.db.QueryContext(ctx, getPointLedger, arg.ClubID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PointLedger
+	for rows.Next() {
+		var i PointLedger
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClubID,
+			&i.UserID,
+			&i.Amount,
+			&i.Balance,
+			&i.Reason,
+			&i.ReferenceID,
+			&i.ActorID,
+			&i.Note,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rebuildPointBalances = `-- name: RebuildPointBalances :execrows
+UPDATE club_membership
+SET user_points = (
+    SELECT COALESCE(SUM(amount), 0.0) FROM point_ledger
+    WHERE point_ledger.club_id = club_membership.club_id AND point_ledger.user_id = club_membership.user_id
+)
+WHERE user_points != (
+    SELECT COALESCE(SUM(amount), 0.0) FROM point_ledger
+    WHERE point_ledger.club_id = club_membership.club_id AND point_ledger.user_id = club_membership.user_id
+)
+`
+
+// sets every member's points to the sum of their ledger entries, returns how many were wrong
+func (q *Queries) RebuildPointBalances(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rebuildPointBalances)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,314 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: profile.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const areFriends = `-- name: AreFriends :one
+SELECT EXISTS(
+    SELECT 1 FROM user_friendship
+    WHERE (user_id = ?1 AND friend_id = ?2) OR (user_id = ?2 AND friend_id = ?1)
+)
+`
+
+type AreFriendsParams struct {
+	UserID  string `json:"user_id"`
+	OtherID string `json:"other_id"`
+}
+
+func (q *Queries) AreFriends(ctx context.Context, arg AreFriendsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, areFriends, arg.UserID, arg.OtherID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createUserLink = `-- name: CreateUserLink :exec
+INSERT INTO user_link (user_id, position, label, url)
+VALUES (?1, ?2, ?3, ?4)
+`
+
+type CreateUserLinkParams struct {
+	UserID   string `json:"user_id"`
+	Position int64  `json:"position"`
+	Label    string `json:"label"`
+	Url      string `json:"url"`
+}
+
+func (q *Queries) CreateUserLink(ctx context.Context, arg CreateUserLinkParams) error {
+	_, err := q.db.ExecContext(ctx, createUserLink,
+		arg.UserID,
+		arg.Position,
+		arg.Label,
+		arg.Url,
+	)
+	return err
+}
+
+const deleteUserLinks = `-- name: DeleteUserLinks :exec
+DELETE FROM user_link WHERE user_id = ?
+`
+
+func (q *Queries) DeleteUserLinks(ctx context.Context, userID string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserLinks, userID)
+	return err
+}
+
+const getAvailableItemsByOwner = `-- name: GetAvailableItemsByOwner :many
+SELECT id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id, club_id, moderation_status, moderation_reason, reserved_for, reserved_until, listing_type, created_at, updated_at
+FROM items
+WHERE owner_id = ?1 AND is_available = TRUE AND moderation_status = 'approved' AND listing_type = 'trade'
+    AND (owner_id = ?2 OR (
+        (reserved_until IS NULL OR reserved_until <= ?3 OR reserved_for = CAST(?2 AS TEXT))
+        AND (club_id IS NULL
+            OR club_id IN (SELECT id FROM club WHERE is_public = TRUE)
+            OR club_id IN (SELECT club_id FROM club_membership WHERE user_id = ?2))
+    ))
+ORDER BY created_at DESC
+`
+
+type GetAvailableItemsByOwnerParams struct {
+	OwnerID  string     `json:"owner_id"`
+	ViewerID string     `json:"viewer_id"`
+	Now      *time.Time `json:"now"`
+}
+
+// the owner's trade listings as the marketplace shows them to the viewer: items outside of clubs, in public clubs
+// and in the viewer's clubs, reserved items only for the user they are reserved for. the owner sees all of them
+func (q *Queries) GetAvailableItemsByOwner(ctx context.Context, arg GetAvailableItemsByOwnerParams) ([]Item, error) {
+	rows, err := q.db.QueryContext(ctx, getAvailableItemsByOwner, arg.OwnerID, arg.ViewerID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Item
+	for rows.Next() {
+		var i Item
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.PriceEstimate,
+			&i.Currency,
+			&i.Condition,
+			&i.Category,
+			&i.PickupLocation,
+			&i.CanShip,
+			&i.IsAvailable,
+			&i.OwnerID,
+			&i.ClubID,
+			&i.ModerationStatus,
+			&i.ModerationReason,
+			&i.ReservedFor,
+			&i.ReservedUntil,
+			&i.ListingType,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserLinks = `-- name: GetUserLinks :many
+SELECT label, url
+FROM user_link
+WHERE user_id = ?
+ORDER BY position ASC
+`
+
+type GetUserLinksRow struct {
+	Label string `json:"label"`
+	Url   string `json:"url"`
+}
+
+func (q *Queries) GetUserLinks(ctx context.Context, userID string) ([]GetUserLinksRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserLinks, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserLinksRow
+	for rows.Next() {
+		var i GetUserLinksRow
+		if err := rows.Scan(&i.Label, &i.Url); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserProfile = `-- name: GetUserProfile :one
+SELECT id, username, display_name, profile_picture, bio, location, pronouns,
+    profile_visibility, show_clubs, show_stats, show_items, created_at
+FROM user
+WHERE id = ?
+`
+
+type GetUserProfileRow struct {
+	ID                string    `json:"id"`
+	Username          string    `json:"username"`
+	DisplayName       *string   `json:"display_name"`
+	ProfilePicture    *string   `json:"profile_picture"`
+	Bio               *string   `json:"bio"`
+	Location          *string   `json:"location"`
+	Pronouns          *string   `json:"pronouns"`
+	ProfileVisibility string    `json:"profile_visibility"`
+	ShowClubs         bool      `json:"show_clubs"`
+	ShowStats         bool      `json:"show_stats"`
+	ShowItems         bool      `json:"show_items"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetUserProfile(ctx context.Context, id string) (GetUserProfileRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserProfile, id)
+	var i GetUserProfileRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.DisplayName,
+		&i.ProfilePicture,
+		&i.Bio,
+		&i.Location,
+		&i.Pronouns,
+		&i.ProfileVisibility,
+		&i.ShowClubs,
+		&i.ShowStats,
+		&i.ShowItems,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserPublicClubs = `-- name: GetUserPublicClubs :many
+SELECT c.id AS club_id, c.name, c.banner_image, cm.user_points, cm.user_streak, cm.created_at AS joined_at
+FROM club_membership cm
+JOIN club c ON c.id = cm.club_id
+WHERE cm.user_id = ? AND c.is_public = TRUE
+ORDER BY cm.user_points DESC
+`
+
+type GetUserPublicClubsRow struct {
+	ClubID      string    `json:"club_id"`
+	Name        string    `json:"name"`
+	BannerImage *string   `json:"banner_image"`
+	UserPoints  float64   `json:"user_points"`
+	UserStreak  int64     `json:"user_streak"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+func (q *Queries) GetUserPublicClubs(ctx context.Context, userID string) ([]GetUserPublicClubsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserPublicClubs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserPublicClubsRow
+	for rows.Next() {
+		var i GetUserPublicClubsRow
+		if err := rows.Scan(
+			&i.ClubID,
+			&i.Name,
+			&i.BannerImage,
+			&i.UserPoints,
+			&i.UserStreak,
+			&i.JoinedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserStats = `-- name: GetUserStats :one
+SELECT
+    COUNT(*) AS club_count,
+    CAST(COALESCE(SUM(user_points), 0) AS REAL) AS total_points,
+    CAST(COALESCE(MAX(user_streak), 0) AS INTEGER) AS longest_streak
+FROM club_membership
+WHERE user_id = ?
+`
+
+type GetUserStatsRow struct {
+	ClubCount     int64   `json:"club_count"`
+	TotalPoints   float64 `json:"total_points"`
+	LongestStreak int64   `json:"longest_streak"`
+}
+
+// across every membership, private clubs count without being named
+func (q *Queries) GetUserStats(ctx context.Context, userID string) (GetUserStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserStats, userID)
+	var i GetUserStatsRow
+	err := row.Scan(&i.ClubCount, &i.TotalPoints, &i.LongestStreak)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :exec
+UPDATE user
+SET
+    display_name = COALESCE(?1, display_name),
+    bio = COALESCE(?2, bio),
+    location = COALESCE(?3, location),
+    pronouns = COALESCE(?4, pronouns),
+    profile_visibility = COALESCE(?5, profile_visibility),
+    show_clubs = COALESCE(?6, show_clubs),
+    show_stats = COALESCE(?7, show_stats),
+    show_items = COALESCE(?8, show_items)
+WHERE
+    id = ?9
+`
+
+type UpdateUserProfileParams struct {
+	DisplayName       *string `json:"display_name"`
+	Bio               *string `json:"bio"`
+	Location          *string `json:"location"`
+	Pronouns          *string `json:"pronouns"`
+	ProfileVisibility *string `json:"profile_visibility"`
+	ShowClubs         *bool   `json:"show_clubs"`
+	ShowStats         *bool   `json:"show_stats"`
+	ShowItems         *bool   `json:"show_items"`
+	ID                string  `json:"id"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserProfile,
+		arg.DisplayName,
+		arg.Bio,
+		arg.Location,
+		arg.Pronouns,
+		arg.ProfileVisibility,
+		arg.ShowClubs,
+		arg.ShowStats,
+		arg.ShowItems,
+		arg.ID,
+	)
+	return err
+}
@@ -6,63 +6,239 @@ package repository
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	AddClubPoints(ctx context.Context, arg AddClubPointsParams) (int64, error)
+	AddItemImage(ctx context.Context, arg AddItemImageParams) error
+	AddToWishlist(ctx context.Context, arg AddToWishlistParams) error
+	AddTradeItem(ctx context.Context, arg AddTradeItemParams) error
+	ApproveClubPost(ctx context.Context, arg ApproveClubPostParams) (int64, error)
+	ApproveItem(ctx context.Context, arg ApproveItemParams) (int64, error)
+	AreFriends(ctx context.Context, arg AreFriendsParams) (int64, error)
+	CancelUserDeletion(ctx context.Context, id string) error
+	ClearUserEmailVerified(ctx context.Context, id string) error
+	// does nothing if the auction was already closed
+	CloseAuction(ctx context.Context, arg CloseAuctionParams) (int64, error)
+	// deletes the state in the same statement that reads it so a callback can only be redeemed once.
+	// a callback without the verifier the login was started with leaves the state for the app that started it
+	ConsumeOIDCLoginState(ctx context.Context, arg ConsumeOIDCLoginStateParams) (ConsumeOIDCLoginStateRow, error)
+	// marks the token used in the same statement that reads it so it can only be redeemed once
+	ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (ConsumeUserTokenRow, error)
+	CountItemImages(ctx context.Context, itemID string) (int64, error)
+	// since is formatted like CURRENT_TIMESTAMP, the post with exclude_id is left out
+	CountRecentClubPostsByUser(ctx context.Context, arg CountRecentClubPostsByUserParams) (int64, error)
+	CountRecentClubPostsWithLink(ctx context.Context, arg CountRecentClubPostsWithLinkParams) (int64, error)
+	CountRecentIPRegistrations(ctx context.Context, arg CountRecentIPRegistrationsParams) (int64, error)
+	CountRecentItemsByOwner(ctx context.Context, arg CountRecentItemsByOwnerParams) (int64, error)
+	CountRecentItemsWithLink(ctx context.Context, arg CountRecentItemsWithLinkParams) (int64, error)
+	CountSavedSearches(ctx context.Context, userID string) (int64, error)
+	CountUnusedUserRecoveryCodes(ctx context.Context, userID string) (int64, error)
+	CountWishlist(ctx context.Context, userID string) (int64, error)
+	CreateAdminAuditLogEntry(ctx context.Context, arg CreateAdminAuditLogEntryParams) error
+	CreateAuction(ctx context.Context, arg CreateAuctionParams) error
+	CreateAuctionBid(ctx context.Context, arg CreateAuctionBidParams) error
 	CreateClub(ctx context.Context, arg CreateClubParams) error
 	CreateClubMembership(ctx context.Context, arg CreateClubMembershipParams) error
 	CreateClubPost(ctx context.Context, arg CreateClubPostParams) error
 	CreateClubPostAttachment(ctx context.Context, arg CreateClubPostAttachmentParams) error
+	CreateClubReward(ctx context.Context, arg CreateClubRewardParams) error
+	CreateDirectMessage(ctx context.Context, arg CreateDirectMessageParams) error
 	CreateFriend(ctx context.Context, arg CreateFriendParams) error
 	CreateItem(ctx context.Context, arg CreateItemParams) error
 	CreateItemForClub(ctx context.Context, arg CreateItemForClubParams) error
+	CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) error
 	CreateMetric(ctx context.Context, arg CreateMetricParams) error
 	CreateMetricEntry(ctx context.Context, arg CreateMetricEntryParams) error
 	CreateMetricEntryAttachment(ctx context.Context, arg CreateMetricEntryAttachmentParams) error
 	CreateMetricEntryVerification(ctx context.Context, arg CreateMetricEntryVerificationParams) error
 	CreateMetricInstance(ctx context.Context, arg CreateMetricInstanceParams) error
+	CreateModerationAction(ctx context.Context, arg CreateModerationActionParams) error
+	CreateOIDCLoginState(ctx context.Context, arg CreateOIDCLoginStateParams) error
+	CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) error
+	CreatePointLedgerEntry(ctx context.Context, arg CreatePointLedgerEntryParams) error
+	CreateReport(ctx context.Context, arg CreateReportParams) error
+	CreateReportSubmission(ctx context.Context, arg CreateReportSubmissionParams) error
+	CreateRewardRedemption(ctx context.Context, arg CreateRewardRedemptionParams) error
+	CreateSavedSearch(ctx context.Context, arg CreateSavedSearchParams) error
+	CreateSigningKey(ctx context.Context, arg CreateSigningKeyParams) error
+	// a second review of the same trade by the same party is ignored
+	CreateTradeReview(ctx context.Context, arg CreateTradeReviewParams) (int64, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) error
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) error
+	CreateUserLink(ctx context.Context, arg CreateUserLinkParams) error
+	CreateUserRecoveryCode(ctx context.Context, arg CreateUserRecoveryCodeParams) error
+	CreateUserSession(ctx context.Context, arg CreateUserSessionParams) error
+	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) error
 	DeleteClub(ctx context.Context, id string) error
+	DeleteClubBlockedTerm(ctx context.Context, arg DeleteClubBlockedTermParams) (int64, error)
 	DeleteClubMembership(ctx context.Context, arg DeleteClubMembershipParams) error
 	DeleteClubPost(ctx context.Context, id string) error
 	DeleteClubPostAttachment(ctx context.Context, id string) error
+	DeleteExpiredOIDCLoginStates(ctx context.Context, now time.Time) error
+	DeleteExpiredPersonalAccessTokens(ctx context.Context, now time.Time) error
+	DeleteExpiredSigningKeys(ctx context.Context, now time.Time) error
+	// revoked sessions are kept until they would have expired so the list stays auditable
+	DeleteExpiredUserSessions(ctx context.Context, now time.Time) error
+	DeleteExpiredUserTokens(ctx context.Context, now time.Time) error
 	// assumes user_id < friend_id
 	DeleteFriend(ctx context.Context, arg DeleteFriendParams) error
 	DeleteItem(ctx context.Context, id string) error
+	DeleteItemImage(ctx context.Context, id string) error
+	DeleteLoginAttempt(ctx context.Context, id string) error
 	DeleteMetric(ctx context.Context, id string) error
 	DeleteMetricEntry(ctx context.Context, arg DeleteMetricEntryParams) error
 	DeleteMetricEntryAttachment(ctx context.Context, id string) error
 	DeleteMetricEntryVerification(ctx context.Context, arg DeleteMetricEntryVerificationParams) error
 	DeleteMetricInstance(ctx context.Context, id string) error
+	DeleteOldLoginAttempts(ctx context.Context, before time.Time) error
+	DeleteSavedSearch(ctx context.Context, arg DeleteSavedSearchParams) (int64, error)
 	DeleteUser(ctx context.Context, id string) error
+	DeleteUserLinks(ctx context.Context, userID string) error
+	DeleteUserRecoveryCodes(ctx context.Context, userID string) error
+	DisableUser(ctx context.Context, arg DisableUserParams) (int64, error)
+	DisableUserTOTP(ctx context.Context, id string) error
+	EnableUser(ctx context.Context, id string) (int64, error)
+	EnableUserTOTP(ctx context.Context, id string) error
+	ExtendAuction(ctx context.Context, arg ExtendAuctionParams) error
+	FinishLoginAttempt(ctx context.Context, arg FinishLoginAttemptParams) error
+	GetActiveUserSessions(ctx context.Context, arg GetActiveUserSessionsParams) ([]GetActiveUserSessionsRow, error)
+	// empty filters match every entry
+	GetAdminAuditLog(ctx context.Context, arg GetAdminAuditLogParams) ([]GetAdminAuditLogRow, error)
 	GetAllClubs(ctx context.Context) ([]Club, error)
+	GetAuction(ctx context.Context, id string) (Auction, error)
+	// the highest bid first, of equal bids the earlier one
+	GetAuctionBids(ctx context.Context, auctionID string) ([]GetAuctionBidsRow, error)
+	// the owner's trade listings as the marketplace shows them to the viewer: items outside of clubs, in public clubs
+	// and in the viewer's clubs, reserved items only for the user they are reserved for. the owner sees all of them
+	GetAvailableItemsByOwner(ctx context.Context, arg GetAvailableItemsByOwnerParams) ([]Item, error)
 	GetClub(ctx context.Context, id string) (Club, error)
+	// the club's open auctions, the ones ending first first
+	GetClubAuctions(ctx context.Context, clubID string) ([]Auction, error)
+	GetClubBlockedTerms(ctx context.Context, clubID string) ([]ClubBlockedTerm, error)
+	GetClubItemImages(ctx context.Context, clubID *string) ([]ItemImage, error)
 	GetClubLeaderboard(ctx context.Context, clubID string) ([]GetClubLeaderboardRow, error)
+	GetClubMembership(ctx context.Context, arg GetClubMembershipParams) (ClubMembership, error)
 	GetClubMetrics(ctx context.Context, clubID string) ([]Metric, error)
+	GetClubPoints(ctx context.Context, arg GetClubPointsParams) (float64, error)
 	GetClubPost(ctx context.Context, id string) (GetClubPostRow, error)
-	GetClubPosts(ctx context.Context, clubID string) ([]GetClubPostsRow, error)
+	GetClubPosts(ctx context.Context, arg GetClubPostsParams) ([]GetClubPostsRow, error)
+	// the newest first, both filters are optional
+	GetClubRedemptions(ctx context.Context, arg GetClubRedemptionsParams) ([]GetClubRedemptionsRow, error)
+	GetClubReward(ctx context.Context, id string) (ClubReward, error)
+	// inactive rewards are only listed for moderators
+	GetClubRewards(ctx context.Context, arg GetClubRewardsParams) ([]ClubReward, error)
+	// moderators first, then the longest standing member
+	GetClubSuccessor(ctx context.Context, arg GetClubSuccessorParams) (string, error)
 	GetClubUserIds(ctx context.Context, clubID string) ([]string, error)
+	GetDirectMessage(ctx context.Context, id string) (GetDirectMessageRow, error)
+	GetDirectMessages(ctx context.Context, arg GetDirectMessagesParams) ([]GetDirectMessagesRow, error)
+	GetEndedAuctions(ctx context.Context, now time.Time) ([]string, error)
 	// assumes user_id < friend_id
 	// TODO: add user friendship created at
 	GetFriends(ctx context.Context, id string) ([]GetFriendsRow, error)
+	GetHeldClubItems(ctx context.Context, clubID *string) ([]GetHeldClubItemsRow, error)
+	GetHeldClubPosts(ctx context.Context, clubID string) ([]GetHeldClubPostsRow, error)
 	// get all entries for all instances of a given metric
 	GetHistoricalMetricEntries(ctx context.Context, metricID string) ([]MetricEntry, error)
+	GetImagesOfItems(ctx context.Context, itemIds []string) ([]ItemImage, error)
+	GetIncomingTrades(ctx context.Context, arg GetIncomingTradesParams) ([]GetIncomingTradesRow, error)
 	GetItem(ctx context.Context, id string) (Item, error)
 	GetItemClubId(ctx context.Context, id string) (*string, error)
-	GetItemsByClub(ctx context.Context, clubID *string) ([]Item, error)
+	GetItemImage(ctx context.Context, arg GetItemImageParams) (ItemImage, error)
+	GetItemImages(ctx context.Context, itemID string) ([]ItemImage, error)
+	GetItemImagesByOwner(ctx context.Context, ownerID string) ([]ItemImage, error)
+	// the members of the item's club who wishlisted it, an owner who got it through a trade is left out
+	GetItemWishlisterIDs(ctx context.Context, itemID string) ([]string, error)
+	GetItemWishlisters(ctx context.Context, itemID string) ([]GetItemWishlistersRow, error)
+	// items without a price estimate are left out by the price filters and listed last when sorting by price.
+	// reserved items are only listed for the user they are reserved for
+	GetItemsByClub(ctx context.Context, arg GetItemsByClubParams) ([]GetItemsByClubRow, error)
 	GetItemsByOwner(ctx context.Context, ownerID string) ([]Item, error)
+	GetLastAccountLoginSuccess(ctx context.Context, account string) (time.Time, error)
 	GetLatestMetricInstance(ctx context.Context, metricID string) (MetricInstance, error)
+	GetLatestSigningKey(ctx context.Context, algorithm string) (GetLatestSigningKeyRow, error)
 	GetMetric(ctx context.Context, id string) (Metric, error)
 	GetMetricEntries(ctx context.Context, metricInstanceID string) ([]MetricEntry, error)
+	GetModerationAction(ctx context.Context, id string) (ModerationAction, error)
+	GetOutgoingTrades(ctx context.Context, arg GetOutgoingTradesParams) ([]GetOutgoingTradesRow, error)
+	GetOwnedClubs(ctx context.Context, ownerUserID string) ([]GetOwnedClubsRow, error)
+	// the other pending trades sharing an item with the trade
+	GetPendingTradesSharingItems(ctx context.Context, tradeID string) ([]string, error)
+	GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (GetPersonalAccessTokenByHashRow, error)
+	// the newest first
+	GetPointHistory(ctx context.Context, arg GetPointHistoryParams) ([]GetPointHistoryRow, error)
+	// the oldest first
+	GetPointLedger(ctx context.Context, arg GetPointLedgerParams) ([]PointLedger, error)
 	// TODO: Implement pagination with LIMIT and OFFSET
 	GetPublicClubs(ctx context.Context) ([]Club, error)
+	// newest first, registrations do not count against an account
+	GetRecentAccountLoginFailures(ctx context.Context, arg GetRecentAccountLoginFailuresParams) ([]time.Time, error)
+	GetRecentIPLoginFailures(ctx context.Context, arg GetRecentIPLoginFailuresParams) ([]time.Time, error)
+	GetReport(ctx context.Context, id string) (Report, error)
+	// reports with how often they were submitted, the most reported first.
+	// empty filters match every report, an empty club id means reports routed to site admins unless all_clubs is set
+	GetReportQueue(ctx context.Context, arg GetReportQueueParams) ([]GetReportQueueRow, error)
+	GetReportSubmissions(ctx context.Context, reportID string) ([]GetReportSubmissionsRow, error)
+	GetReportsByReporter(ctx context.Context, reporterID string) ([]GetReportsByReporterRow, error)
+	GetRewardRedemption(ctx context.Context, id string) (RewardRedemption, error)
+	GetSavedSearches(ctx context.Context, userID string) ([]SavedSearch, error)
+	// saved searches of the item's club members other than its owner that the item matches
+	GetSavedSearchesMatchingItem(ctx context.Context, itemID string) ([]GetSavedSearchesMatchingItemRow, error)
+	// every key that may still have signed a valid token, newest first
+	GetSigningKeys(ctx context.Context, now time.Time) ([]GetSigningKeysRow, error)
 	GetTradeByID(ctx context.Context, id string) (Trade, error)
+	GetTradeItems(ctx context.Context, tradeID string) ([]GetTradeItemsRow, error)
+	GetTradeReviews(ctx context.Context, tradeID string) ([]TradeReview, error)
+	GetUnresolvedReportForTarget(ctx context.Context, arg GetUnresolvedReportForTargetParams) (Report, error)
 	GetUserClubs(ctx context.Context, userID string) ([]GetUserClubsRow, error)
+	GetUserDeletionScheduledAt(ctx context.Context, id string) (*time.Time, error)
+	GetUserDisabledAt(ctx context.Context, id string) (*time.Time, error)
 	GetUserDisplay(ctx context.Context, id string) (GetUserDisplayRow, error)
+	GetUserEmail(ctx context.Context, id string) (GetUserEmailRow, error)
+	GetUserExportMessages(ctx context.Context, userID string) ([]GetUserExportMessagesRow, error)
+	GetUserExportMetricEntries(ctx context.Context, userID string) ([]GetUserExportMetricEntriesRow, error)
+	GetUserExportPosts(ctx context.Context, userID *string) ([]GetUserExportPostsRow, error)
+	GetUserExportProfile(ctx context.Context, id string) (GetUserExportProfileRow, error)
+	GetUserIDByEmail(ctx context.Context, email string) (string, error)
+	GetUserIdentities(ctx context.Context, userID string) ([]GetUserIdentitiesRow, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (GetUserIdentityRow, error)
+	GetUserLinks(ctx context.Context, userID string) ([]GetUserLinksRow, error)
 	GetUserLoginByEmail(ctx context.Context, email string) (GetUserLoginByEmailRow, error)
 	GetUserLoginByUsername(ctx context.Context, username string) (GetUserLoginByUsernameRow, error)
+	GetUserLoginFailures(ctx context.Context, arg GetUserLoginFailuresParams) ([]GetUserLoginFailuresRow, error)
 	GetUserMetricEntries(ctx context.Context, userID string) ([]MetricEntry, error)
 	GetUserMetrics(ctx context.Context, userID string) ([]Metric, error)
+	GetUserPasswordHash(ctx context.Context, id string) (string, error)
+	GetUserPersonalAccessTokens(ctx context.Context, arg GetUserPersonalAccessTokensParams) ([]GetUserPersonalAccessTokensRow, error)
+	GetUserProfile(ctx context.Context, id string) (GetUserProfileRow, error)
+	GetUserPublicClubs(ctx context.Context, userID string) ([]GetUserPublicClubsRow, error)
+	GetUserReputation(ctx context.Context, revieweeID string) (GetUserReputationRow, error)
+	// reviews the user received, the newest first
+	GetUserReviews(ctx context.Context, arg GetUserReviewsParams) ([]GetUserReviewsRow, error)
+	GetUserRole(ctx context.Context, id string) (string, error)
+	GetUserSession(ctx context.Context, id string) (UserSession, error)
+	// across every membership, private clubs count without being named
+	GetUserStats(ctx context.Context, userID string) (GetUserStatsRow, error)
+	GetUserTOTP(ctx context.Context, id string) (GetUserTOTPRow, error)
+	GetUsersDueForDeletion(ctx context.Context, now *time.Time) ([]GetUsersDueForDeletionRow, error)
+	// wishlisted items of the clubs the user is still a member of, the latest first.
+	// unavailable and reserved items are listed too so the user sees why they cannot trade for them
+	GetWishlist(ctx context.Context, userID string) ([]GetWishlistRow, error)
+	// only a verified address proves the account belongs to the listed person and not to whoever registered it first
+	GrantAdminRoleByEmail(ctx context.Context, email string) (int64, error)
+	HandleRewardRedemption(ctx context.Context, arg HandleRewardRedemptionParams) (int64, error)
+	HasDirectMessages(ctx context.Context, arg HasDirectMessagesParams) (int64, error)
+	// whether the user turned in the instance of the metric before the given one
+	HasEntryInPreviousMetricInstance(ctx context.Context, arg HasEntryInPreviousMetricInstanceParams) (int64, error)
+	HasMetricEntry(ctx context.Context, arg HasMetricEntryParams) (int64, error)
+	HasPendingTrade(ctx context.Context, arg HasPendingTradeParams) (int64, error)
+	// returns boolean
+	HasReportSubmission(ctx context.Context, arg HasReportSubmissionParams) (int64, error)
+	HoldItem(ctx context.Context, arg HoldItemParams) error
+	// called before issuing a new token so only the latest mail works
+	InvalidateUserTokens(ctx context.Context, arg InvalidateUserTokensParams) error
 	// returns boolean
 	IsUserMemberOfClub(ctx context.Context, arg IsUserMemberOfClubParams) (int64, error)
 	// checks if user is moderator or owner of club
@@ -70,20 +246,67 @@ type Querier interface {
 	IsUserModeratorOfClub(ctx context.Context, arg IsUserModeratorOfClubParams) (int64, error)
 	// returns boolean
 	IsUserOwnerOfClub(ctx context.Context, arg IsUserOwnerOfClubParams) (int64, error)
+	// sets every member's points to the sum of their ledger entries, returns how many were wrong
+	RebuildPointBalances(ctx context.Context) (int64, error)
+	ReleaseItemReservation(ctx context.Context, id string) error
+	RemoveFromWishlist(ctx context.Context, arg RemoveFromWishlistParams) (int64, error)
+	ReserveItem(ctx context.Context, arg ReserveItemParams) error
+	ResolveReport(ctx context.Context, arg ResolveReportParams) (int64, error)
+	ReturnRewardStock(ctx context.Context, id string) error
+	RevokeAllPersonalAccessTokens(ctx context.Context, userID string) error
+	RevokeAllUserSessions(ctx context.Context, userID string) error
+	RevokePersonalAccessToken(ctx context.Context, arg RevokePersonalAccessTokenParams) (int64, error)
+	RevokeUserSession(ctx context.Context, arg RevokeUserSessionParams) (int64, error)
+	// only succeeds if the presented refresh token is still the current one,
+	// so two concurrent refreshes with the same token cannot both win
+	RotateUserSessionRefreshToken(ctx context.Context, arg RotateUserSessionRefreshTokenParams) (int64, error)
+	ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) error
+	// matches part of the club name, the owner's username or the exact id. private clubs are included
+	SearchClubs(ctx context.Context, arg SearchClubsParams) ([]SearchClubsRow, error)
+	// available items in every club the user is a member of. an empty match or filter does not narrow the search,
+	// items without a price estimate are left out by the price filters and listed last when sorting by price.
+	// reserved items are only found by the user they are reserved for
+	SearchItems(ctx context.Context, arg SearchItemsParams) ([]SearchItemsRow, error)
+	// matches part of the username or email, or the exact id. an empty query lists everyone
+	SearchUsers(ctx context.Context, arg SearchUsersParams) ([]SearchUsersRow, error)
+	SetItemListingType(ctx context.Context, arg SetItemListingTypeParams) error
+	SetUserEmailVerified(ctx context.Context, id string) error
+	SetUserRole(ctx context.Context, arg SetUserRoleParams) (int64, error)
+	// starts enrollment, two factor auth is not active until EnableUserTOTP
+	SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) error
+	ShareClub(ctx context.Context, arg ShareClubParams) (int64, error)
+	// fails without changing anything if the user has too few points
+	SpendClubPoints(ctx context.Context, arg SpendClubPointsParams) (int64, error)
+	// fails without changing anything if the reward is inactive or none are left
+	TakeRewardStock(ctx context.Context, id string) (int64, error)
+	TouchPersonalAccessToken(ctx context.Context, id string) error
+	TouchUserSession(ctx context.Context, id string) error
 	TradeCreate(ctx context.Context, arg TradeCreateParams) error
+	TransferClubOwnership(ctx context.Context, arg TransferClubOwnershipParams) error
+	// the previous owner's reservation ends with the trade
 	TransferItemOwnership(ctx context.Context, arg TransferItemOwnershipParams) error
+	TriageReport(ctx context.Context, arg TriageReportParams) (int64, error)
 	UpdateClub(ctx context.Context, arg UpdateClubParams) error
+	// points only change through the points ledger
 	UpdateClubMembership(ctx context.Context, arg UpdateClubMembershipParams) error
 	UpdateClubPost(ctx context.Context, arg UpdateClubPostParams) error
 	UpdateClubPostAttachment(ctx context.Context, arg UpdateClubPostAttachmentParams) error
+	UpdateClubReward(ctx context.Context, arg UpdateClubRewardParams) error
 	UpdateItem(ctx context.Context, arg UpdateItemParams) error
 	UpdateMetric(ctx context.Context, arg UpdateMetricParams) error
 	UpdateMetricEntry(ctx context.Context, arg UpdateMetricEntryParams) error
 	UpdateMetricEntryAttachment(ctx context.Context, arg UpdateMetricEntryAttachmentParams) error
 	UpdateMetricEntryVerification(ctx context.Context, arg UpdateMetricEntryVerificationParams) error
-	UpdateTradeStatus(ctx context.Context, arg UpdateTradeStatusParams) error
+	// only pending trades can change, so two parties answering at once cannot both win
+	UpdateTradeStatus(ctx context.Context, arg UpdateTradeStatusParams) (int64, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) error
 	UpdateUserPrivateMessage(ctx context.Context, arg UpdateUserPrivateMessageParams) error
+	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error
+	// only moves forward, so a code that was already accepted cannot be used again
+	UpdateUserTOTPLastStep(ctx context.Context, arg UpdateUserTOTPLastStepParams) (int64, error)
+	UpsertClubBlockedTerm(ctx context.Context, arg UpsertClubBlockedTermParams) error
+	UseUserRecoveryCode(ctx context.Context, arg UseUserRecoveryCodeParams) (int64, error)
+	UsernameExists(ctx context.Context, username string) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,413 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: report.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createModerationAction = `-- name: CreateModerationAction :exec
+INSERT INTO moderation_action (id, moderator_id, action, target_type, target_id, club_id, as_admin)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+`
+
+type CreateModerationActionParams struct {
+	ID          string  `json:"id"`
+	ModeratorID *string `json:"moderator_id"`
+	Action      string  `json:"action"`
+	TargetType  string  `json:"target_type"`
+	TargetID    string  `json:"target_id"`
+	ClubID      *string `json:"club_id"`
+	AsAdmin     bool    `json:"as_admin"`
+}
+
+func (q *Queries) CreateModerationAction(ctx context.Context, arg CreateModerationActionParams) error {
+	_, err := q.db.ExecContext(ctx, createModerationAction,
+		arg.ID,
+		arg.ModeratorID,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.ClubID,
+		arg.AsAdmin,
+	)
+	return err
+}
+
+const createReport = `-- name: CreateReport :exec
+INSERT INTO report (id, target_type, target_id, club_id)
+VALUES (?1, ?2, ?3, ?4)
+`
+
+type CreateReportParams struct {
+	ID         string  `json:"id"`
+	TargetType string  `json:"target_type"`
+	TargetID   string  `json:"target_id"`
+	ClubID     *string `json:"club_id"`
+}
+
+func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) error {
+	_, err := q.db.ExecContext(ctx, createReport,
+		arg.ID,
+		arg.TargetType,
+		arg.TargetID,
+		arg.ClubID,
+	)
+	return err
+}
+
+const createReportSubmission = `-- name: CreateReportSubmission :exec
+INSERT INTO report_submission (report_id, reporter_id, category, comment)
+VALUES (?1, ?2, ?3, ?4)
+`
+
+type CreateReportSubmissionParams struct {
+	ReportID   string `json:"report_id"`
+	ReporterID string `json:"reporter_id"`
+	Category   string `json:"category"`
+	Comment    string `json:"comment"`
+}
+
+func (q *Queries) CreateReportSubmission(ctx context.Context, arg CreateReportSubmissionParams) error {
+	_, err := q.db.ExecContext(ctx, createReportSubmission,
+		arg.ReportID,
+		arg.ReporterID,
+		arg.Category,
+		arg.Comment,
+	)
+	return err
+}
+
+const getModerationAction = `-- name: GetModerationAction :one
+SELECT id, moderator_id, "action", target_type, target_id, club_id, as_admin, created_at FROM moderation_action WHERE id = ?1
+`
+
+func (q *Queries) GetModerationAction(ctx context.Context, id string) (ModerationAction, error) {
+	row := q.db.QueryRowContext(ctx, getModerationAction, id)
+	var i ModerationAction
+	err := row.Scan(
+		&i.ID,
+		&i.ModeratorID,
+		&i.Action,
+		&i.TargetType,
+		&i.TargetID,
+		&i.ClubID,
+		&i.AsAdmin,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getReport = `-- name: GetReport :one
+SELECT id, target_type, target_id, club_id, status, handled_by, resolution_action, resolution_note, resolution_action_id, resolved_at, created_at, updated_at FROM report WHERE id = ?1
+`
+
+func (q *Queries) GetReport(ctx context.Context, id string) (Report, error) {
+	row := q.db.QueryRowContext(ctx, getReport, id)
+	var i Report
+	err := row.Scan(
+		&i.ID,
+		&i.TargetType,
+		&i.TargetID,
+		&i.ClubID,
+		&i.Status,
+		&i.HandledBy,
+		&i.ResolutionAction,
+		&i.ResolutionNote,
+		&i.ResolutionActionID,
+		&i.ResolvedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getReportQueue = `-- name: GetReportQueue :many
+SELECT r.id, r.target_type, r.target_id, r.club_id, r.status, r.handled_by, r.resolution_action, r.resolution_note, r.resolution_action_id, r.resolved_at, r.created_at, r.updated_at, (SELECT COUNT(*) FROM report_submission rs WHERE rs.report_id = r.id) AS submission_count
+FROM report r
+WHERE
+    (CAST(?1 AS TEXT) = '' OR r.status = ?1)
+    AND (CAST(?2 AS TEXT) = '' OR r.target_type = ?2)
+    AND (
+        CAST(?3 AS BOOLEAN)
+        OR (CAST(?4 AS TEXT) = '' AND r.club_id IS NULL)
+        OR r.club_id = ?4
+    )
+ORDER BY submission_count DESC, r.created_at
+LIMIT ?6 OFFSET ?5
+`
+
+type GetReportQueueParams struct {
+	Status     string `json:"status"`
+	TargetType string `json:"target_type"`
+	AllClubs   bool   `json:"all_clubs"`
+	ClubID     string `json:"club_id"`
+	Offset     int64  `json:"offset"`
+	Limit      int64  `json:"limit"`
+}
+
+type GetReportQueueRow struct {
+	ID                 string     `json:"id"`
+	TargetType         string     `json:"target_type"`
+	TargetID           string     `json:"target_id"`
+	ClubID             *string    `json:"club_id"`
+	Status             string     `json:"status"`
+	HandledBy          *string    `json:"handled_by"`
+	ResolutionAction   *string    `json:"resolution_action"`
+	ResolutionNote     *string    `json:"resolution_note"`
+	ResolutionActionID *string    `json:"resolution_action_id"`
+	ResolvedAt         *time.Time `json:"resolved_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	SubmissionCount    int64      `json:"submission_count"`
+}
+
+// reports with how often they were submitted, the most reported first.
+// empty filters match every report, an empty club id means reports routed to site admins unless all_clubs is set
+func (q *Queries) GetReportQueue(ctx context.Context, arg GetReportQueueParams) ([]GetReportQueueRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReportQueue,
+		arg.Status,
+		arg.TargetType,
+		arg.AllClubs,
+		arg.ClubID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReportQueueRow
+	for rows.Next() {
+		var i GetReportQueueRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TargetType,
+			&i.TargetID,
+			&i.ClubID,
+			&i.Status,
+			&i.HandledBy,
+			&i.ResolutionAction,
+			&i.ResolutionNote,
+			&i.ResolutionActionID,
+			&i.ResolvedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SubmissionCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReportSubmissions = `-- name: GetReportSubmissions :many
+SELECT rs.reporter_id, u.username AS reporter_username, rs.category, rs.comment, rs.created_at
+FROM report_submission rs
+JOIN user u ON u.id = rs.reporter_id
+WHERE rs.report_id = ?1
+ORDER BY rs.created_at
+`
+
+type GetReportSubmissionsRow struct {
+	ReporterID       string    `json:"reporter_id"`
+	ReporterUsername string    `json:"reporter_username"`
+	Category         string    `json:"category"`
+	Comment          string    `json:"comment"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetReportSubmissions(ctx context.Context, reportID string) ([]GetReportSubmissionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReportSubmissions, reportID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReportSubmissionsRow
+	for rows.Next() {
+		var i GetReportSubmissionsRow
+		if err := rows.Scan(
+			&i.ReporterID,
+			&i.ReporterUsername,
+			&i.Category,
+			&i.Comment,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReportsByReporter = `-- name: GetReportsByReporter :many
+SELECT r.id, r.target_type, r.target_id, r.status, r.resolution_action, r.resolved_at, rs.category, rs.comment, rs.created_at
+FROM report_submission rs
+JOIN report r ON r.id = rs.report_id
+WHERE rs.reporter_id = ?1
+ORDER BY rs.created_at DESC
+`
+
+type GetReportsByReporterRow struct {
+	ID               string     `json:"id"`
+	TargetType       string     `json:"target_type"`
+	TargetID         string     `json:"target_id"`
+	Status           string     `json:"status"`
+	ResolutionAction *string    `json:"resolution_action"`
+	ResolvedAt       *time.Time `json:"resolved_at"`
+	Category         string     `json:"category"`
+	Comment          string     `json:"comment"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func (q *Queries) GetReportsByReporter(ctx context.Context, reporterID string) ([]GetReportsByReporterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReportsByReporter, reporterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReportsByReporterRow
+	for rows.Next() {
+		var i GetReportsByReporterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Status,
+			&i.ResolutionAction,
+			&i.ResolvedAt,
+			&i.Category,
+			&i.Comment,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnresolvedReportForTarget = `-- name: GetUnresolvedReportForTarget :one
+SELECT id, target_type, target_id, club_id, status, handled_by, resolution_action, resolution_note, resolution_action_id, resolved_at, created_at, updated_at FROM report
+WHERE target_type = ?1 AND target_id = ?2 AND status IN ('open', 'triaged')
+`
+
+type GetUnresolvedReportForTargetParams struct {
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+}
+
+func (q *Queries) GetUnresolvedReportForTarget(ctx context.Context, arg GetUnresolvedReportForTargetParams) (Report, error) {
+	row := q.db.QueryRowContext(ctx, getUnresolvedReportForTarget, arg.TargetType, arg.TargetID)
+	var i Report
+	err := row.Scan(
+		&i.ID,
+		&i.TargetType,
+		&i.TargetID,
+		&i.ClubID,
+		&i.Status,
+		&i.HandledBy,
+		&i.ResolutionAction,
+		&i.ResolutionNote,
+		&i.ResolutionActionID,
+		&i.ResolvedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const hasReportSubmission = `-- name: HasReportSubmission :one
+SELECT EXISTS(SELECT 1 FROM report_submission WHERE report_id = ?1 AND reporter_id = ?2)
+`
+
+type HasReportSubmissionParams struct {
+	ReportID   string `json:"report_id"`
+	ReporterID string `json:"reporter_id"`
+}
+
+// returns boolean
+func (q *Queries) HasReportSubmission(ctx context.Context, arg HasReportSubmissionParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, hasReportSubmission, arg.ReportID, arg.ReporterID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const resolveReport = `-- name: ResolveReport :execrows
+UPDATE report
+SET
+    status = ?1,
+    handled_by = ?2,
+    resolution_action = ?3,
+    resolution_note = ?4,
+    resolution_action_id = ?5,
+    resolved_at = CURRENT_TIMESTAMP
+WHERE id = ?6 AND status IN ('open', 'triaged')
+`
+
+type ResolveReportParams struct {
+	Status             string  `json:"status"`
+	HandledBy          *string `json:"handled_by"`
+	ResolutionAction   *string `json:"resolution_action"`
+	ResolutionNote     *string `json:"resolution_note"`
+	ResolutionActionID *string `json:"resolution_action_id"`
+	ID                 string  `json:"id"`
+}
+
+func (q *Queries) ResolveReport(ctx context.Context, arg ResolveReportParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, resolveReport,
+		arg.Status,
+		arg.HandledBy,
+		arg.ResolutionAction,
+		arg.ResolutionNote,
+		arg.ResolutionActionID,
+		arg.ID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const triageReport = `-- name: TriageReport :execrows
+UPDATE report
+SET status = 'triaged', handled_by = ?1
+WHERE id = ?2 AND status = 'open'
+`
+
+type TriageReportParams struct {
+	HandledBy *string `json:"handled_by"`
+	ID        string  `json:"id"`
+}
+
+func (q *Queries) TriageReport(ctx context.Context, arg TriageReportParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, triageReport, arg.HandledBy, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
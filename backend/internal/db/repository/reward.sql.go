@@ -0,0 +1,307 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: reward.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createClubReward = `-- name: CreateClubReward :exec
+INSERT INTO club_reward (id, club_id, name, description, cost, stock, item_id)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateClubRewardParams struct {
+	ID          string  `json:"id"`
+	ClubID      string  `json:"club_id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Cost        float64 `json:"cost"`
+	Stock       *int64  `json:"stock"`
+	ItemID      *string `json:"item_id"`
+}
+
+func (q *Queries) CreateClubReward(ctx context.Context, arg CreateClubRewardParams) error {
+	_, err := q.db.ExecContext(ctx, createClubReward,
+		arg.ID,
+		arg.ClubID,
+		arg.Name,
+		arg.Description,
+		arg.Cost,
+		arg.Stock,
+		arg.ItemID,
+	)
+	return err
+}
+
+const createRewardRedemption = `-- name: CreateRewardRedemption :exec
+INSERT INTO reward_redemption (id, reward_id, club_id, user_id, cost, note)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateRewardRedemptionParams struct {
+	ID       string  `json:"id"`
+	RewardID string  `json:"reward_id"`
+	ClubID   string  `json:"club_id"`
+	UserID   string  `json:"user_id"`
+	Cost     float64 `json:"cost"`
+	Note     *string `json:"note"`
+}
+
+func (q *Queries) CreateRewardRedemption(ctx context.Context, arg CreateRewardRedemptionParams) error {
+	_, err := q.db.ExecContext(ctx, createRewardRedemption,
+		arg.ID,
+		arg.RewardID,
+		arg.ClubID,
+		arg.UserID,
+		arg.Cost,
+		arg.Note,
+	)
+	return err
+}
+
+const getClubRedemptions = `-- name: GetClubRedemptions :many
+SELECT
+    r.id, r.reward_id, cr.name AS reward_name, r.club_id, r.user_id, u.username, r.cost, r.note,
+    r.status, r.handled_by, r.handled_at, r.created_at
+FROM reward_redemption r
+JOIN club_reward cr ON cr.id = r.reward_id
+JOIN user u ON u.id = r.user_id
+WHERE r.club_id = ?1
+    AND (CAST(?2 AS TEXT) IS NULL OR r.user_id = ?2)
+    AND (CAST(?3 AS TEXT) IS NULL OR r.status = ?3)
+ORDER BY r.created_at DESC, r.rowid DESC
+`
+
+type GetClubRedemptionsParams struct {
+	ClubID string  `json:"club_id"`
+	UserID *string `json:"user_id"`
+	Status *string `json:"status"`
+}
+
+type GetClubRedemptionsRow struct {
+	ID         string     `json:"id"`
+	RewardID   string     `json:"reward_id"`
+	RewardName string     `json:"reward_name"`
+	ClubID     string     `json:"club_id"`
+	UserID     string     `json:"user_id"`
+	Username   string     `json:"username"`
+	Cost       float64    `json:"cost"`
+	Note       *string    `json:"note"`
+	Status     string     `json:"status"`
+	HandledBy  *string    `json:"handled_by"`
+	HandledAt  *time.Time `json:"handled_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// the newest first, both filters are optional
+func (q *Queries) GetClubRedemptions(ctx context.Context, arg GetClubRedemptionsParams) ([]GetClubRedemptionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getClubRedemptions, arg.ClubID, arg.UserID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetClubRedemptionsRow
+	for rows.Next() {
+		var i GetClubRedemptionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RewardID,
+			&i.RewardName,
+			&i.ClubID,
+			&i.UserID,
+			&i.Username,
+			&i.Cost,
+			&i.Note,
+			&i.Status,
+			&i.HandledBy,
+			&i.HandledAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClubReward = `-- name: GetClubReward :one
+SELECT id, club_id, name, description, cost, stock, is_active, item_id, created_at, updated_at FROM club_reward WHERE id = ?
+`
+
+func (q *Queries) GetClubReward(ctx context.Context, id string) (ClubReward, error) {
+	row := q.db.QueryRowContext(ctx, getClubReward, id)
+	var i ClubReward
+	err := row.Scan(
+		&i.ID,
+		&i.ClubID,
+		&i.Name,
+		&i.Description,
+		&i.Cost,
+		&i.Stock,
+		&i.IsActive,
+		&i.ItemID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getClubRewards = `-- name: GetClubRewards :many
+SELECT id, club_id, name, description, cost, stock, is_active, item_id, created_at, updated_at FROM club_reward
+WHERE club_id = ?1 AND (is_active OR CAST(?2 AS BOOLEAN))
+ORDER BY cost, name
+`
+
+type GetClubRewardsParams struct {
+	ClubID          string `json:"club_id"`
+	IncludeInactive bool   `json:"include_inactive"`
+}
+
+// inactive rewards are only listed for moderators
+func (q *Queries) GetClubRewards(ctx context.Context, arg GetClubRewardsParams) ([]ClubReward, error) {
+	rows, err := q.db.QueryContext(ctx, getClubRewards, arg.ClubID, arg.IncludeInactive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClubReward
+	for rows.Next() {
+		var i ClubReward
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClubID,
+			&i.Name,
+			&i.Description,
+			&i.Cost,
+			&i.Stock,
+			&i.IsActive,
+			&i.ItemID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRewardRedemption = `-- name: GetRewardRedemption :one
+SELECT id, reward_id, club_id, user_id, cost, note, status, handled_by, handled_at, created_at, updated_at FROM reward_redemption WHERE id = ?
+`
+
+func (q *Queries) GetRewardRedemption(ctx context.Context, id string) (RewardRedemption, error) {
+	row := q.db.QueryRowContext(ctx, getRewardRedemption, id)
+	var i RewardRedemption
+	err := row.Scan(
+		&i.ID,
+		&i.RewardID,
+		&i.ClubID,
+		&i.UserID,
+		&i.Cost,
+		&i.Note,
+		&i.Status,
+		&i.HandledBy,
+		&i.HandledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const handleRewardRedemption = `-- name: HandleRewardRedemption :execrows
+UPDATE reward_redemption
+SET status = ?1, handled_by = ?2, handled_at = CURRENT_TIMESTAMP
+WHERE id = ?3 AND status = 'pending'
+`
+
+type HandleRewardRedemptionParams struct {
+	Status    string  `json:"status"`
+	HandledBy *string `json:"handled_by"`
+	ID        string  `json:"id"`
+}
+
+func (q *Queries) HandleRewardRedemption(ctx context.Context, arg HandleRewardRedemptionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, handleRewardRedemption, arg.Status, arg.HandledBy, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const returnRewardStock = `-- name: ReturnRewardStock :exec
+UPDATE club_reward
+SET stock = stock + 1
+WHERE id = ? AND stock IS NOT NULL
+`
+
+func (q *Queries) ReturnRewardStock(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, returnRewardStock, id)
+	return err
+}
+
+const takeRewardStock = `-- name: TakeRewardStock :execrows
+UPDATE club_reward
+SET stock = stock - 1
+WHERE id = ? AND is_active AND (stock IS NULL OR stock > 0)
+`
+
+// fails without changing anything if the reward is inactive or none are left
+func (q *Queries) TakeRewardStock(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, takeRewardStock, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateClubReward = `-- name: UpdateClubReward :exec
+UPDATE club_reward
+SET
+    name = COALESCE(?1, name),
+    description = COALESCE(?2, description),
+    cost = COALESCE(?3, cost),
+    stock = CASE WHEN CAST(?4 AS BOOLEAN) THEN NULL ELSE COALESCE(?5, stock) END,
+    is_active = COALESCE(?6, is_active)
+WHERE id = ?7
+`
+
+type UpdateClubRewardParams struct {
+	Name           *string  `json:"name"`
+	Description    *string  `json:"description"`
+	Cost           *float64 `json:"cost"`
+	UnlimitedStock bool     `json:"unlimited_stock"`
+	Stock          *int64   `json:"stock"`
+	IsActive       *bool    `json:"is_active"`
+	ID             string   `json:"id"`
+}
+
+func (q *Queries) UpdateClubReward(ctx context.Context, arg UpdateClubRewardParams) error {
+	_, err := q.db.ExecContext(ctx, updateClubReward,
+		arg.Name,
+		arg.Description,
+		arg.Cost,
+		arg.UnlimitedStock,
+		arg.Stock,
+		arg.IsActive,
+		arg.ID,
+	)
+	return err
+}
@@ -0,0 +1,336 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: search.sql
+
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const countSavedSearches = `-- name: CountSavedSearches :one
+SELECT COUNT(*)
+FROM saved_search
+WHERE user_id = ?
+`
+
+func (q *Queries) CountSavedSearches(ctx context.Context, userID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSavedSearches, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createSavedSearch = `-- name: CreateSavedSearch :exec
+INSERT INTO saved_search (id, user_id, name, query, match_query, category, condition, owner_id, currency, min_price, max_price)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateSavedSearchParams struct {
+	ID         string   `json:"id"`
+	UserID     string   `json:"user_id"`
+	Name       string   `json:"name"`
+	Query      string   `json:"query"`
+	MatchQuery string   `json:"match_query"`
+	Category   *string  `json:"category"`
+	Condition  *string  `json:"condition"`
+	OwnerID    *string  `json:"owner_id"`
+	Currency   *string  `json:"currency"`
+	MinPrice   *float64 `json:"min_price"`
+	MaxPrice   *float64 `json:"max_price"`
+}
+
+func (q *Queries) CreateSavedSearch(ctx context.Context, arg CreateSavedSearchParams) error {
+	_, err := q.db.ExecContext(ctx, createSavedSearch,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Query,
+		arg.MatchQuery,
+		arg.Category,
+		arg.Condition,
+		arg.OwnerID,
+		arg.Currency,
+		arg.MinPrice,
+		arg.MaxPrice,
+	)
+	return err
+}
+
+const deleteSavedSearch = `-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_search
+WHERE id = ? AND user_id = ?
+`
+
+type DeleteSavedSearchParams struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) DeleteSavedSearch(ctx context.Context, arg DeleteSavedSearchParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSavedSearch, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getImagesOfItems = `-- name: GetImagesOfItems :many
+SELECT id, item_id, url, thumbnail_url, created_at
+FROM item_image
+WHERE item_id IN (/*SLICE:item_ids*/?)
+ORDER BY created_at, rowid
+`
+
+func (q *Queries) GetImagesOfItems(ctx context.Context, itemIds []string) ([]ItemImage, error) {
+	query := getImagesOfItems
+	var queryParams []interface{}
+	if len(itemIds) > 0 {
+		for _, v := range itemIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:item_ids*/?", strings.Repeat(",?", len(itemIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:item_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ItemImage
+	for rows.Next() {
+		var i ItemImage
+		if err := rows.Scan(
+			&i.ID,
+			&i.ItemID,
+			&i.Url,
+			&i.ThumbnailUrl,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSavedSearches = `-- name: GetSavedSearches :many
+SELECT id, user_id, name, "query", match_query, category, condition, owner_id, currency, min_price, max_price, created_at, updated_at
+FROM saved_search
+WHERE user_id = ?
+ORDER BY created_at DESC, rowid DESC
+`
+
+func (q *Queries) GetSavedSearches(ctx context.Context, userID string) ([]SavedSearch, error) {
+	rows, err := q.db.QueryContext(ctx, getSavedSearches, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SavedSearch
+	for rows.Next() {
+		var i SavedSearch
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Query,
+			&i.MatchQuery,
+			&i.Category,
+			&i.Condition,
+			&i.OwnerID,
+			&i.Currency,
+			&i.MinPrice,
+			&i.MaxPrice,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSavedSearchesMatchingItem = `-- name: GetSavedSearchesMatchingItem :many
+SELECT s.id, s.user_id, s.name
+FROM saved_search s
+JOIN items i ON i.id = ?1
+JOIN club_membership m ON m.club_id = i.club_id AND m.user_id = s.user_id
+WHERE s.user_id != i.owner_id
+    AND (s.match_query = '' OR i.id IN (SELECT item_id FROM item_search WHERE item_search MATCH s.match_query))
+    AND (s.category IS NULL OR s.category = i.category)
+    AND (s.condition IS NULL OR s.condition = i.condition)
+    AND (s.owner_id IS NULL OR s.owner_id = i.owner_id)
+    AND (s.currency IS NULL OR s.currency = i.currency)
+    AND (s.min_price IS NULL OR i.price_estimate >= s.min_price)
+    AND (s.max_price IS NULL OR i.price_estimate <= s.max_price)
+ORDER BY s.user_id, s.created_at
+`
+
+type GetSavedSearchesMatchingItemRow struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+// saved searches of the item's club members other than its owner that the item matches
+func (q *Queries) GetSavedSearchesMatchingItem(ctx context.Context, itemID string) ([]GetSavedSearchesMatchingItemRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSavedSearchesMatchingItem, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSavedSearchesMatchingItemRow
+	for rows.Next() {
+		var i GetSavedSearchesMatchingItemRow
+		if err := rows.Scan(&i.ID, &i.UserID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchItems = `-- name: SearchItems :many
+SELECT
+    i.id, i.name, i.description, i.price_estimate, i.currency, i.condition, i.category,
+    i.pickup_location, i.can_ship, i.is_available, i.owner_id, u.username AS owner_username,
+    i.club_id, c.name AS club_name, i.reserved_until, i.listing_type, i.created_at
+FROM (
+    SELECT id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id, club_id, moderation_status, moderation_reason, reserved_for, reserved_until, listing_type, created_at, updated_at,
+        CASE CAST(?1 AS TEXT)
+            WHEN 'price_asc' THEN price_estimate
+            WHEN 'price_desc' THEN -price_estimate
+        END AS sort_price
+    FROM items
+    WHERE is_available = TRUE AND moderation_status = 'approved'
+        AND (reserved_until IS NULL OR reserved_until <= ?2 OR reserved_for = CAST(?3 AS TEXT))
+        AND (CAST(?4 AS TEXT) = '' OR id IN (SELECT item_id FROM item_search WHERE item_search MATCH ?4))
+        AND (CAST(?5 AS TEXT) = '' OR category = ?5)
+        AND (CAST(?6 AS TEXT) = '' OR condition = ?6)
+        AND (CAST(?7 AS TEXT) = '' OR owner_id = ?7)
+        AND (CAST(?8 AS TEXT) = '' OR currency = ?8)
+        AND (CAST(?9 AS REAL) IS NULL OR price_estimate >= ?9)
+        AND (CAST(?10 AS REAL) IS NULL OR price_estimate <= ?10)
+) i
+JOIN club_membership m ON m.club_id = i.club_id AND m.user_id = ?3
+JOIN club c ON c.id = i.club_id
+JOIN user u ON u.id = i.owner_id
+ORDER BY sort_price IS NULL, sort_price, i.created_at DESC, i.id
+LIMIT ?12 OFFSET ?11
+`
+
+type SearchItemsParams struct {
+	Sort      string     `json:"sort"`
+	Now       *time.Time `json:"now"`
+	UserID    string     `json:"user_id"`
+	Match     string     `json:"match"`
+	Category  string     `json:"category"`
+	Condition string     `json:"condition"`
+	OwnerID   string     `json:"owner_id"`
+	Currency  string     `json:"currency"`
+	MinPrice  *float64   `json:"min_price"`
+	MaxPrice  *float64   `json:"max_price"`
+	Offset    int64      `json:"offset"`
+	Limit     int64      `json:"limit"`
+}
+
+type SearchItemsRow struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Description    *string    `json:"description"`
+	PriceEstimate  *float64   `json:"price_estimate"`
+	Currency       *string    `json:"currency"`
+	Condition      *string    `json:"condition"`
+	Category       *string    `json:"category"`
+	PickupLocation *string    `json:"pickup_location"`
+	CanShip        bool       `json:"can_ship"`
+	IsAvailable    bool       `json:"is_available"`
+	OwnerID        string     `json:"owner_id"`
+	OwnerUsername  string     `json:"owner_username"`
+	ClubID         *string    `json:"club_id"`
+	ClubName       string     `json:"club_name"`
+	ReservedUntil  *time.Time `json:"reserved_until"`
+	ListingType    string     `json:"listing_type"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// available items in every club the user is a member of. an empty match or filter does not narrow the search,
+// items without a price estimate are left out by the price filters and listed last when sorting by price.
+// reserved items are only found by the user they are reserved for
+func (q *Queries) SearchItems(ctx context.Context, arg SearchItemsParams) ([]SearchItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchItems,
+		arg.Sort,
+		arg.Now,
+		arg.UserID,
+		arg.Match,
+		arg.Category,
+		arg.Condition,
+		arg.OwnerID,
+		arg.Currency,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchItemsRow
+	for rows.Next() {
+		var i SearchItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.PriceEstimate,
+			&i.Currency,
+			&i.Condition,
+			&i.Category,
+			&i.PickupLocation,
+			&i.CanShip,
+			&i.IsAvailable,
+			&i.OwnerID,
+			&i.OwnerUsername,
+			&i.ClubID,
+			&i.ClubName,
+			&i.ReservedUntil,
+			&i.ListingType,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,205 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: session.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createUserSession = `-- name: CreateUserSession :exec
+INSERT INTO user_session (id, user_id, refresh_token_hash, user_agent, ip_address, expires_at, impersonator_id)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+`
+
+type CreateUserSessionParams struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"user_id"`
+	RefreshTokenHash string    `json:"refresh_token_hash"`
+	UserAgent        string    `json:"user_agent"`
+	IpAddress        string    `json:"ip_address"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	ImpersonatorID   *string   `json:"impersonator_id"`
+}
+
+func (q *Queries) CreateUserSession(ctx context.Context, arg CreateUserSessionParams) error {
+	_, err := q.db.ExecContext(ctx, createUserSession,
+		arg.ID,
+		arg.UserID,
+		arg.RefreshTokenHash,
+		arg.UserAgent,
+		arg.IpAddress,
+		arg.ExpiresAt,
+		arg.ImpersonatorID,
+	)
+	return err
+}
+
+const deleteExpiredUserSessions = `-- name: DeleteExpiredUserSessions :exec
+DELETE FROM user_session
+WHERE expires_at < ?1
+`
+
+// revoked sessions are kept until they would have expired so the list stays auditable
+func (q *Queries) DeleteExpiredUserSessions(ctx context.Context, now time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredUserSessions, now)
+	return err
+}
+
+const getActiveUserSessions = `-- name: GetActiveUserSessions :many
+SELECT id, user_agent, ip_address, last_seen_at, expires_at, impersonator_id, created_at
+FROM user_session
+WHERE user_id = ?1 AND revoked_at IS NULL AND expires_at > ?2
+ORDER BY last_seen_at DESC
+`
+
+type GetActiveUserSessionsParams struct {
+	UserID string    `json:"user_id"`
+	Now    time.Time `json:"now"`
+}
+
+type GetActiveUserSessionsRow struct {
+	ID             string    `json:"id"`
+	UserAgent      string    `json:"user_agent"`
+	IpAddress      string    `json:"ip_address"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	ImpersonatorID *string   `json:"impersonator_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetActiveUserSessions(ctx context.Context, arg GetActiveUserSessionsParams) ([]GetActiveUserSessionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveUserSessions, arg.UserID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActiveUserSessionsRow
+	for rows.Next() {
+		var i GetActiveUserSessionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserAgent,
+			&i.IpAddress,
+			&i.LastSeenAt,
+			&i.ExpiresAt,
+			&i.ImpersonatorID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserSession = `-- name: GetUserSession :one
+SELECT id, user_id, refresh_token_hash, user_agent, ip_address, last_seen_at, expires_at, revoked_at, impersonator_id, created_at, updated_at FROM user_session WHERE id = ?1
+`
+
+func (q *Queries) GetUserSession(ctx context.Context, id string) (UserSession, error) {
+	row := q.db.QueryRowContext(ctx, getUserSession, id)
+	var i UserSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RefreshTokenHash,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.LastSeenAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.ImpersonatorID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const revokeAllUserSessions = `-- name: RevokeAllUserSessions :exec
+UPDATE user_session
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = ?1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAllUserSessions(ctx context.Context, userID string) error {
+	_, err := q.db.ExecContext(ctx, revokeAllUserSessions, userID)
+	return err
+}
+
+const revokeUserSession = `-- name: RevokeUserSession :execrows
+UPDATE user_session
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ?1 AND user_id = ?2 AND revoked_at IS NULL
+`
+
+type RevokeUserSessionParams struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) RevokeUserSession(ctx context.Context, arg RevokeUserSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeUserSession, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rotateUserSessionRefreshToken = `-- name: RotateUserSessionRefreshToken :execrows
+UPDATE user_session
+SET
+    refresh_token_hash = ?1,
+    user_agent = ?2,
+    ip_address = ?3,
+    expires_at = ?4,
+    last_seen_at = CURRENT_TIMESTAMP
+WHERE
+    id = ?5 AND refresh_token_hash = ?6 AND revoked_at IS NULL
+`
+
+type RotateUserSessionRefreshTokenParams struct {
+	NewRefreshTokenHash string    `json:"new_refresh_token_hash"`
+	UserAgent           string    `json:"user_agent"`
+	IpAddress           string    `json:"ip_address"`
+	ExpiresAt           time.Time `json:"expires_at"`
+	ID                  string    `json:"id"`
+	RefreshTokenHash    string    `json:"refresh_token_hash"`
+}
+
+// only succeeds if the presented refresh token is still the current one,
+// so two concurrent refreshes with the same token cannot both win
+func (q *Queries) RotateUserSessionRefreshToken(ctx context.Context, arg RotateUserSessionRefreshTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rotateUserSessionRefreshToken,
+		arg.NewRefreshTokenHash,
+		arg.UserAgent,
+		arg.IpAddress,
+		arg.ExpiresAt,
+		arg.ID,
+		arg.RefreshTokenHash,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const touchUserSession = `-- name: TouchUserSession :exec
+UPDATE user_session
+SET last_seen_at = CURRENT_TIMESTAMP
+WHERE id = ?1
+`
+
+func (q *Queries) TouchUserSession(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, touchUserSession, id)
+	return err
+}
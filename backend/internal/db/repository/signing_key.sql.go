@@ -0,0 +1,119 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: signing_key.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createSigningKey = `-- name: CreateSigningKey :exec
+INSERT INTO jwt_signing_key (kid, algorithm, private_key, active_at, expires_at)
+VALUES (?1, ?2, ?3, ?4, ?5)
+`
+
+type CreateSigningKeyParams struct {
+	Kid        string    `json:"kid"`
+	Algorithm  string    `json:"algorithm"`
+	PrivateKey string    `json:"private_key"`
+	ActiveAt   time.Time `json:"active_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateSigningKey(ctx context.Context, arg CreateSigningKeyParams) error {
+	_, err := q.db.ExecContext(ctx, createSigningKey,
+		arg.Kid,
+		arg.Algorithm,
+		arg.PrivateKey,
+		arg.ActiveAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const deleteExpiredSigningKeys = `-- name: DeleteExpiredSigningKeys :exec
+DELETE FROM jwt_signing_key
+WHERE expires_at < ?1
+`
+
+func (q *Queries) DeleteExpiredSigningKeys(ctx context.Context, now time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredSigningKeys, now)
+	return err
+}
+
+const getLatestSigningKey = `-- name: GetLatestSigningKey :one
+SELECT kid, algorithm, private_key, active_at, expires_at
+FROM jwt_signing_key
+WHERE algorithm = ?1
+ORDER BY active_at DESC
+LIMIT 1
+`
+
+type GetLatestSigningKeyRow struct {
+	Kid        string    `json:"kid"`
+	Algorithm  string    `json:"algorithm"`
+	PrivateKey string    `json:"private_key"`
+	ActiveAt   time.Time `json:"active_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func (q *Queries) GetLatestSigningKey(ctx context.Context, algorithm string) (GetLatestSigningKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, getLatestSigningKey, algorithm)
+	var i GetLatestSigningKeyRow
+	err := row.Scan(
+		&i.Kid,
+		&i.Algorithm,
+		&i.PrivateKey,
+		&i.ActiveAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getSigningKeys = `-- name: GetSigningKeys :many
+SELECT kid, algorithm, private_key, active_at, expires_at
+FROM jwt_signing_key
+WHERE expires_at > ?1
+ORDER BY active_at DESC
+`
+
+type GetSigningKeysRow struct {
+	Kid        string    `json:"kid"`
+	Algorithm  string    `json:"algorithm"`
+	PrivateKey string    `json:"private_key"`
+	ActiveAt   time.Time `json:"active_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// every key that may still have signed a valid token, newest first
+func (q *Queries) GetSigningKeys(ctx context.Context, now time.Time) ([]GetSigningKeysRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSigningKeys, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSigningKeysRow
+	for rows.Next() {
+		var i GetSigningKeysRow
+		if err := rows.Scan(
+			&i.Kid,
+			&i.Algorithm,
+			&i.PrivateKey,
+			&i.ActiveAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,531 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: trade.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const addClubPoints = `-- name: AddClubPoints :execrows
+UPDATE club_membership
+SET user_points = user_points + ?1
+WHERE user_id = ?2 AND club_id = ?3
+`
+
+type AddClubPointsParams struct {
+	Points float64 `json:"points"`
+	UserID string  `json:"user_id"`
+	ClubID string  `json:"club_id"`
+}
+
+func (q *Queries) AddClubPoints(ctx context.Context, arg AddClubPointsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, addClubPoints, arg.Points, arg.UserID, arg.ClubID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const addTradeItem = `-- name: AddTradeItem :exec
+INSERT INTO trade_item (trade_id, item_id, owner_id)
+VALUES (?, ?, ?)
+`
+
+type AddTradeItemParams struct {
+	TradeID string `json:"trade_id"`
+	ItemID  string `json:"item_id"`
+	OwnerID string `json:"owner_id"`
+}
+
+func (q *Queries) AddTradeItem(ctx context.Context, arg AddTradeItemParams) error {
+	_, err := q.db.ExecContext(ctx, addTradeItem, arg.TradeID, arg.ItemID, arg.OwnerID)
+	return err
+}
+
+const createTradeReview = `-- name: CreateTradeReview :execrows
+INSERT INTO trade_review (trade_id, reviewer_id, reviewee_id, rating, comment)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (trade_id, reviewer_id) DO NOTHING
+`
+
+type CreateTradeReviewParams struct {
+	TradeID    string  `json:"trade_id"`
+	ReviewerID string  `json:"reviewer_id"`
+	RevieweeID string  `json:"reviewee_id"`
+	Rating     int64   `json:"rating"`
+	Comment    *string `json:"comment"`
+}
+
+// a second review of the same trade by the same party is ignored
+func (q *Queries) CreateTradeReview(ctx context.Context, arg CreateTradeReviewParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createTradeReview,
+		arg.TradeID,
+		arg.ReviewerID,
+		arg.RevieweeID,
+		arg.Rating,
+		arg.Comment,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getClubPoints = `-- name: GetClubPoints :one
+SELECT user_points FROM club_membership WHERE user_id = ? AND club_id = ?
+`
+
+type GetClubPointsParams struct {
+	UserID string `json:"user_id"`
+	ClubID string `json:"club_id"`
+}
+
+func (q *Queries) GetClubPoints(ctx context.Context, arg GetClubPointsParams) (float64, error) {
+	row := q.db.QueryRowContext(ctx, getClubPoints, arg.UserID, arg.ClubID)
+	var user_points float64
+	err := row.Scan(&user_points)
+	return user_points, err
+}
+
+const getIncomingTrades = `-- name: GetIncomingTrades :many
+SELECT t.id, t.proposer_id, t.responder_id, t.status, t.countered_trade_id, t.club_id, t.proposer_points, t.responder_points, t.created_at, t.updated_at, proposer.username AS proposer_username, responder.username AS responder_username
+FROM trades t
+JOIN user proposer ON proposer.id = t.proposer_id
+JOIN user responder ON responder.id = t.responder_id
+WHERE t.responder_id = ?1 AND (CAST(?2 AS TEXT) = '' OR t.status = ?2)
+ORDER BY t.created_at DESC, t.rowid DESC
+`
+
+type GetIncomingTradesParams struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status"`
+}
+
+type GetIncomingTradesRow struct {
+	ID                string    `json:"id"`
+	ProposerID        string    `json:"proposer_id"`
+	ResponderID       string    `json:"responder_id"`
+	Status            string    `json:"status"`
+	CounteredTradeID  *string   `json:"countered_trade_id"`
+	ClubID            *string   `json:"club_id"`
+	ProposerPoints    float64   `json:"proposer_points"`
+	ResponderPoints   float64   `json:"responder_points"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	ProposerUsername  string    `json:"proposer_username"`
+	ResponderUsername string    `json:"responder_username"`
+}
+
+func (q *Queries) GetIncomingTrades(ctx context.Context, arg GetIncomingTradesParams) ([]GetIncomingTradesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getIncomingTrades, arg.UserID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetIncomingTradesRow
+	for rows.Next() {
+		var i GetIncomingTradesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProposerID,
+			&i.ResponderID,
+			&i.Status,
+			&i.CounteredTradeID,
+			&i.ClubID,
+			&i.ProposerPoints,
+			&i.ResponderPoints,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ProposerUsername,
+			&i.ResponderUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOutgoingTrades = `-- name: GetOutgoingTrades :many
+SELECT t.id, t.proposer_id, t.responder_id, t.status, t.countered_trade_id, t.club_id, t.proposer_points, t.responder_points, t.created_at, t.updated_at, proposer.username AS proposer_username, responder.username AS responder_username
+FROM trades t
+JOIN user proposer ON proposer.id = t.proposer_id
+JOIN user responder ON responder.id = t.responder_id
+WHERE t.proposer_id = ?1 AND (CAST(?2 AS TEXT) = '' OR t.status = ?2)
+ORDER BY t.created_at DESC, t.rowid DESC
+`
+
+type GetOutgoingTradesParams struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status"`
+}
+
+type GetOutgoingTradesRow struct {
+	ID                string    `json:"id"`
+	ProposerID        string    `json:"proposer_id"`
+	ResponderID       string    `json:"responder_id"`
+	Status            string    `json:"status"`
+	CounteredTradeID  *string   `json:"countered_trade_id"`
+	ClubID            *string   `json:"club_id"`
+	ProposerPoints    float64   `json:"proposer_points"`
+	ResponderPoints   float64   `json:"responder_points"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	ProposerUsername  string    `json:"proposer_username"`
+	ResponderUsername string    `json:"responder_username"`
+}
+
+func (q *Queries) GetOutgoingTrades(ctx context.Context, arg GetOutgoingTradesParams) ([]GetOutgoingTradesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOutgoingTrades, arg.UserID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOutgoingTradesRow
+	for rows.Next() {
+		var i GetOutgoingTradesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProposerID,
+			&i.ResponderID,
+			&i.Status,
+			&i.CounteredTradeID,
+			&i.ClubID,
+			&i.ProposerPoints,
+			&i.ResponderPoints,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ProposerUsername,
+			&i.ResponderUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingTradesSharingItems = `-- name: GetPendingTradesSharingItems :many
+SELECT DISTINCT t.id
+FROM trades t
+JOIN trade_item ti ON ti.trade_id = t.id
+WHERE t.status = 'pending' AND t.id != ?1
+    AND ti.item_id IN (SELECT item_id FROM trade_item WHERE trade_id = ?1)
+`
+
+// the other pending trades sharing an item with the trade
+func (q *Queries) GetPendingTradesSharingItems(ctx context.Context, tradeID string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingTradesSharingItems, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTradeByID = `-- name: GetTradeByID :one
+SELECT id, proposer_id, responder_id, status, countered_trade_id, club_id, proposer_points, responder_points, created_at, updated_at
+FROM trades
+WHERE id = ?
+`
+
+func (q *Queries) GetTradeByID(ctx context.Context, id string) (Trade, error) {
+	row := q.db.QueryRowContext(ctx, getTradeByID, id)
+	var i Trade
+	err := row.Scan(
+		&i.ID,
+		&i.ProposerID,
+		&i.ResponderID,
+		&i.Status,
+		&i.CounteredTradeID,
+		&i.ClubID,
+		&i.ProposerPoints,
+		&i.ResponderPoints,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTradeItems = `-- name: GetTradeItems :many
+SELECT ti.item_id, ti.owner_id, i.name
+FROM trade_item ti
+JOIN items i ON i.id = ti.item_id
+WHERE ti.trade_id = ?
+ORDER BY i.name, ti.item_id
+`
+
+type GetTradeItemsRow struct {
+	ItemID  string `json:"item_id"`
+	OwnerID string `json:"owner_id"`
+	Name    string `json:"name"`
+}
+
+func (q *Queries) GetTradeItems(ctx context.Context, tradeID string) ([]GetTradeItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTradeItems, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTradeItemsRow
+	for rows.Next() {
+		var i GetTradeItemsRow
+		if err := rows.Scan(&i.ItemID, &i.OwnerID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTradeReviews = `-- name: GetTradeReviews :many
+SELECT trade_id, reviewer_id, reviewee_id, rating, comment, created_at, updated_at
+FROM trade_review
+WHERE trade_id = ?
+ORDER BY created_at, rowid
+`
+
+func (q *Queries) GetTradeReviews(ctx context.Context, tradeID string) ([]TradeReview, error) {
+	rows, err := q.db.QueryContext(ctx, getTradeReviews, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TradeReview
+	for rows.Next() {
+		var i TradeReview
+		if err := rows.Scan(
+			&i.TradeID,
+			&i.ReviewerID,
+			&i.RevieweeID,
+			&i.Rating,
+			&i.Comment,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserReputation = `-- name: GetUserReputation :one
+SELECT COUNT(*) AS review_count, CAST(COALESCE(AVG(rating), 0) AS REAL) AS score
+FROM trade_review
+WHERE reviewee_id = ?
+`
+
+type GetUserReputationRow struct {
+	ReviewCount int64   `json:"review_count"`
+	Score       float64 `json:"score"`
+}
+
+func (q *Queries) GetUserReputation(ctx context.Context, revieweeID string) (GetUserReputationRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserReputation, revieweeID)
+	var i GetUserReputationRow
+	err := row.Scan(&i.ReviewCount, &i.Score)
+	return i, err
+}
+
+const getUserReviews = `-- name: GetUserReviews :many
+SELECT r.trade_id, r.reviewer_id, u.username AS reviewer_username, r.rating, r.comment, r.created_at
+FROM trade_review r
+JOIN user u ON u.id = r.reviewer_id
+WHERE r.reviewee_id = ?1
+ORDER BY r.created_at DESC, r.rowid DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type GetUserReviewsParams struct {
+	UserID string `json:"user_id"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+type GetUserReviewsRow struct {
+	TradeID          string    `json:"trade_id"`
+	ReviewerID       string    `json:"reviewer_id"`
+	ReviewerUsername string    `json:"reviewer_username"`
+	Rating           int64     `json:"rating"`
+	Comment          *string   `json:"comment"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// reviews the user received, the newest first
+func (q *Queries) GetUserReviews(ctx context.Context, arg GetUserReviewsParams) ([]GetUserReviewsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserReviews, arg.UserID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserReviewsRow
+	for rows.Next() {
+		var i GetUserReviewsRow
+		if err := rows.Scan(
+			&i.TradeID,
+			&i.ReviewerID,
+			&i.ReviewerUsername,
+			&i.Rating,
+			&i.Comment,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasPendingTrade = `-- name: HasPendingTrade :one
+SELECT EXISTS(
+    SELECT 1 FROM trades
+    WHERE proposer_id = ?1 AND responder_id = ?2 AND status = 'pending'
+)
+`
+
+type HasPendingTradeParams struct {
+	ProposerID  string `json:"proposer_id"`
+	ResponderID string `json:"responder_id"`
+}
+
+func (q *Queries) HasPendingTrade(ctx context.Context, arg HasPendingTradeParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, hasPendingTrade, arg.ProposerID, arg.ResponderID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const spendClubPoints = `-- name: SpendClubPoints :execrows
+UPDATE club_membership
+SET user_points = user_points - ?1
+WHERE user_id = ?2 AND club_id = ?3 AND user_points >= ?1
+`
+
+type SpendClubPointsParams struct {
+	Points float64 `json:"points"`
+	UserID string  `json:"user_id"`
+	ClubID string  `json:"club_id"`
+}
+
+// fails without changing anything if the user has too few points
+func (q *Queries) SpendClubPoints(ctx context.Context, arg SpendClubPointsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, spendClubPoints, arg.Points, arg.UserID, arg.ClubID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const tradeCreate = `-- name: TradeCreate :exec
+INSERT INTO trades (id, proposer_id, responder_id, countered_trade_id, club_id, proposer_points, responder_points)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type TradeCreateParams struct {
+	ID               string  `json:"id"`
+	ProposerID       string  `json:"proposer_id"`
+	ResponderID      string  `json:"responder_id"`
+	CounteredTradeID *string `json:"countered_trade_id"`
+	ClubID           *string `json:"club_id"`
+	ProposerPoints   float64 `json:"proposer_points"`
+	ResponderPoints  float64 `json:"responder_points"`
+}
+
+func (q *Queries) TradeCreate(ctx context.Context, arg TradeCreateParams) error {
+	_, err := q.db.ExecContext(ctx, tradeCreate,
+		arg.ID,
+		arg.ProposerID,
+		arg.ResponderID,
+		arg.CounteredTradeID,
+		arg.ClubID,
+		arg.ProposerPoints,
+		arg.ResponderPoints,
+	)
+	return err
+}
+
+const transferItemOwnership = `-- name: TransferItemOwnership :exec
+UPDATE items
+SET owner_id = ?, reserved_for = NULL, reserved_until = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type TransferItemOwnershipParams struct {
+	OwnerID string `json:"owner_id"`
+	ID      string `json:"id"`
+}
+
+// the previous owner's reservation ends with the trade
+func (q *Queries) TransferItemOwnership(ctx context.Context, arg TransferItemOwnershipParams) error {
+	_, err := q.db.ExecContext(ctx, transferItemOwnership, arg.OwnerID, arg.ID)
+	return err
+}
+
+const updateTradeStatus = `-- name: UpdateTradeStatus :execrows
+UPDATE trades
+SET status = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = 'pending'
+`
+
+type UpdateTradeStatusParams struct {
+	Status string `json:"status"`
+	ID     string `json:"id"`
+}
+
+// only pending trades can change, so two parties answering at once cannot both win
+func (q *Queries) UpdateTradeStatus(ctx context.Context, arg UpdateTradeStatusParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateTradeStatus, arg.Status, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
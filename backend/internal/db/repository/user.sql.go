@@ -10,6 +10,50 @@ import (
 	"time"
 )
 
+const addItemImage = `-- name: AddItemImage :exec
+INSERT INTO item_image (id, item_id, url, thumbnail_url)
+VALUES (?, ?, ?, ?)
+`
+
+type AddItemImageParams struct {
+	ID           string `json:"id"`
+	ItemID       string `json:"item_id"`
+	Url          string `json:"url"`
+	ThumbnailUrl string `json:"thumbnail_url"`
+}
+
+func (q *Queries) AddItemImage(ctx context.Context, arg AddItemImageParams) error {
+	_, err := q.db.ExecContext(ctx, addItemImage,
+		arg.ID,
+		arg.ItemID,
+		arg.Url,
+		arg.ThumbnailUrl,
+	)
+	return err
+}
+
+const clearUserEmailVerified = `-- name: ClearUserEmailVerified :exec
+UPDATE user SET email_verified_at = NULL WHERE id = ?
+`
+
+func (q *Queries) ClearUserEmailVerified(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, clearUserEmailVerified, id)
+	return err
+}
+
+const countItemImages = `-- name: CountItemImages :one
+SELECT COUNT(*)
+FROM item_image
+WHERE item_id = ?
+`
+
+func (q *Queries) CountItemImages(ctx context.Context, itemID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countItemImages, itemID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFriend = `-- name: CreateFriend :exec
 INSERT INTO user_friendship (user_id, friend_id)
 VALUES (?, ?)
@@ -26,16 +70,22 @@ func (q *Queries) CreateFriend(ctx context.Context, arg CreateFriendParams) erro
 }
 
 const createItem = `-- name: CreateItem :exec
-INSERT INTO items (id, name, description, is_available, owner_id)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO items (id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateItemParams struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description *string `json:"description"`
-	IsAvailable bool    `json:"is_available"`
-	OwnerID     string  `json:"owner_id"`
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Description    *string  `json:"description"`
+	PriceEstimate  *float64 `json:"price_estimate"`
+	Currency       *string  `json:"currency"`
+	Condition      *string  `json:"condition"`
+	Category       *string  `json:"category"`
+	PickupLocation *string  `json:"pickup_location"`
+	CanShip        bool     `json:"can_ship"`
+	IsAvailable    bool     `json:"is_available"`
+	OwnerID        string   `json:"owner_id"`
 }
 
 func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) error {
@@ -43,6 +93,12 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) error {
 		arg.ID,
 		arg.Name,
 		arg.Description,
+		arg.PriceEstimate,
+		arg.Currency,
+		arg.Condition,
+		arg.Category,
+		arg.PickupLocation,
+		arg.CanShip,
 		arg.IsAvailable,
 		arg.OwnerID,
 	)
@@ -50,17 +106,25 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) error {
 }
 
 const createItemForClub = `-- name: CreateItemForClub :exec
-INSERT INTO items (id, name, description, is_available, owner_id, club_id)
-VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO items (id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id, club_id, moderation_status, moderation_reason)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateItemForClubParams struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description *string `json:"description"`
-	IsAvailable bool    `json:"is_available"`
-	OwnerID     string  `json:"owner_id"`
-	ClubID      *string `json:"club_id"`
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Description      *string  `json:"description"`
+	PriceEstimate    *float64 `json:"price_estimate"`
+	Currency         *string  `json:"currency"`
+	Condition        *string  `json:"condition"`
+	Category         *string  `json:"category"`
+	PickupLocation   *string  `json:"pickup_location"`
+	CanShip          bool     `json:"can_ship"`
+	IsAvailable      bool     `json:"is_available"`
+	OwnerID          string   `json:"owner_id"`
+	ClubID           *string  `json:"club_id"`
+	ModerationStatus string   `json:"moderation_status"`
+	ModerationReason *string  `json:"moderation_reason"`
 }
 
 func (q *Queries) CreateItemForClub(ctx context.Context, arg CreateItemForClubParams) error {
@@ -68,9 +132,17 @@ func (q *Queries) CreateItemForClub(ctx context.Context, arg CreateItemForClubPa
 		arg.ID,
 		arg.Name,
 		arg.Description,
+		arg.PriceEstimate,
+		arg.Currency,
+		arg.Condition,
+		arg.Category,
+		arg.PickupLocation,
+		arg.CanShip,
 		arg.IsAvailable,
 		arg.OwnerID,
 		arg.ClubID,
+		arg.ModerationStatus,
+		arg.ModerationReason,
 	)
 	return err
 }
@@ -122,6 +194,15 @@ func (q *Queries) DeleteItem(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteItemImage = `-- name: DeleteItemImage :exec
+DELETE FROM item_image WHERE id = ?
+`
+
+func (q *Queries) DeleteItemImage(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteItemImage, id)
+	return err
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE FROM user WHERE id = ?
 `
@@ -131,6 +212,43 @@ func (q *Queries) DeleteUser(ctx context.Context, id string) error {
 	return err
 }
 
+const getClubItemImages = `-- name: GetClubItemImages :many
+SELECT ii.id, ii.item_id, ii.url, ii.thumbnail_url, ii.created_at
+FROM item_image ii
+JOIN items i ON i.id = ii.item_id
+WHERE i.club_id = ?
+ORDER BY ii.created_at, ii.rowid
+`
+
+func (q *Queries) GetClubItemImages(ctx context.Context, clubID *string) ([]ItemImage, error) {
+	rows, err := q.db.QueryContext(ctx, getClubItemImages, clubID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ItemImage
+	for rows.Next() {
+		var i ItemImage
+		if err := rows.Scan(
+			&i.ID,
+			&i.ItemID,
+			&i.Url,
+			&i.ThumbnailUrl,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFriends = `-- name: GetFriends :many
 SELECT u.username, u.profile_picture, u.id  FROM user u WHERE u.id IN (
     SELECT friend_id FROM user_friendship f WHERE f.user_id = ?1
@@ -171,7 +289,7 @@ func (q *Queries) GetFriends(ctx context.Context, id string) ([]GetFriendsRow, e
 }
 
 const getItem = `-- name: GetItem :one
-SELECT id, name, description, is_available, owner_id, club_id, created_at, updated_at
+SELECT id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id, club_id, moderation_status, moderation_reason, reserved_for, reserved_until, listing_type, created_at, updated_at
 FROM items
 WHERE id = ?
 `
@@ -183,9 +301,20 @@ func (q *Queries) GetItem(ctx context.Context, id string) (Item, error) {
 		&i.ID,
 		&i.Name,
 		&i.Description,
+		&i.PriceEstimate,
+		&i.Currency,
+		&i.Condition,
+		&i.Category,
+		&i.PickupLocation,
+		&i.CanShip,
 		&i.IsAvailable,
 		&i.OwnerID,
 		&i.ClubID,
+		&i.ModerationStatus,
+		&i.ModerationReason,
+		&i.ReservedFor,
+		&i.ReservedUntil,
+		&i.ListingType,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -205,29 +334,183 @@ func (q *Queries) GetItemClubId(ctx context.Context, id string) (*string, error)
 	return club_id, err
 }
 
+const getItemImage = `-- name: GetItemImage :one
+SELECT id, item_id, url, thumbnail_url, created_at
+FROM item_image
+WHERE id = ? AND item_id = ?
+`
+
+type GetItemImageParams struct {
+	ID     string `json:"id"`
+	ItemID string `json:"item_id"`
+}
+
+func (q *Queries) GetItemImage(ctx context.Context, arg GetItemImageParams) (ItemImage, error) {
+	row := q.db.QueryRowContext(ctx, getItemImage, arg.ID, arg.ItemID)
+	var i ItemImage
+	err := row.Scan(
+		&i.ID,
+		&i.ItemID,
+		&i.Url,
+		&i.ThumbnailUrl,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getItemImages = `-- name: GetItemImages :many
+SELECT id, item_id, url, thumbnail_url, created_at
+FROM item_image
+WHERE item_id = ?
+ORDER BY created_at, rowid
+`
+
+func (q *Queries) GetItemImages(ctx context.Context, itemID string) ([]ItemImage, error) {
+	rows, err := q.db.QueryContext(ctx, getItemImages, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ItemImage
+	for rows.Next() {
+		var i ItemImage
+		if err := rows.Scan(
+			&i.ID,
+			&i.ItemID,
+			&i.Url,
+			&i.ThumbnailUrl,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getItemImagesByOwner = `-- name: GetItemImagesByOwner :many
+SELECT ii.id, ii.item_id, ii.url, ii.thumbnail_url, ii.created_at
+FROM item_image ii
+JOIN items i ON i.id = ii.item_id
+WHERE i.owner_id = ?
+`
+
+func (q *Queries) GetItemImagesByOwner(ctx context.Context, ownerID string) ([]ItemImage, error) {
+	rows, err := q.db.QueryContext(ctx, getItemImagesByOwner, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ItemImage
+	for rows.Next() {
+		var i ItemImage
+		if err := rows.Scan(
+			&i.ID,
+			&i.ItemID,
+			&i.Url,
+			&i.ThumbnailUrl,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getItemsByClub = `-- name: GetItemsByClub :many
-SELECT id, name, description, is_available, owner_id, club_id, created_at, updated_at
-FROM items
-WHERE club_id = ? AND is_available = TRUE
-ORDER BY created_at DESC
+SELECT id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id, club_id, reserved_until, listing_type, created_at, updated_at
+FROM (
+    SELECT id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id, club_id, moderation_status, moderation_reason, reserved_for, reserved_until, listing_type, created_at, updated_at,
+        CASE CAST(?1 AS TEXT)
+            WHEN 'price_asc' THEN price_estimate
+            WHEN 'price_desc' THEN -price_estimate
+        END AS sort_price
+    FROM items
+    WHERE club_id = ?2 AND is_available = TRUE AND moderation_status = 'approved'
+        AND (reserved_until IS NULL OR reserved_until <= ?3 OR reserved_for = CAST(?4 AS TEXT))
+        AND (CAST(?5 AS TEXT) = '' OR currency = ?5)
+        AND (CAST(?6 AS REAL) IS NULL OR price_estimate >= ?6)
+        AND (CAST(?7 AS REAL) IS NULL OR price_estimate <= ?7)
+)
+ORDER BY sort_price IS NULL, sort_price, created_at DESC
 `
 
-func (q *Queries) GetItemsByClub(ctx context.Context, clubID *string) ([]Item, error) {
-	rows, err := q.db.QueryContext(ctx, getItemsByClub, clubID)
+type GetItemsByClubParams struct {
+	Sort     string     `json:"sort"`
+	ClubID   *string    `json:"club_id"`
+	Now      *time.Time `json:"now"`
+	UserID   string     `json:"user_id"`
+	Currency string     `json:"currency"`
+	MinPrice *float64   `json:"min_price"`
+	MaxPrice *float64   `json:"max_price"`
+}
+
+type GetItemsByClubRow struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Description    *string    `json:"description"`
+	PriceEstimate  *float64   `json:"price_estimate"`
+	Currency       *string    `json:"currency"`
+	Condition      *string    `json:"condition"`
+	Category       *string    `json:"category"`
+	PickupLocation *string    `json:"pickup_location"`
+	CanShip        bool       `json:"can_ship"`
+	IsAvailable    bool       `json:"is_available"`
+	OwnerID        string     `json:"owner_id"`
+	ClubID         *string    `json:"club_id"`
+	ReservedUntil  *time.Time `json:"reserved_until"`
+	ListingType    string     `json:"listing_type"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// items without a price estimate are left out by the price filters and listed last when sorting by price.
+// reserved items are only listed for the user they are reserved for
+func (q *Queries) GetItemsByClub(ctx context.Context, arg GetItemsByClubParams) ([]GetItemsByClubRow, error) {
+	rows, err := q.db.QueryContext(ctx, getItemsByClub,
+		arg.Sort,
+		arg.ClubID,
+		arg.Now,
+		arg.UserID,
+		arg.Currency,
+		arg.MinPrice,
+		arg.MaxPrice,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Item
+	var items []GetItemsByClubRow
 	for rows.Next() {
-		var i Item
+		var i GetItemsByClubRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
 			&i.Description,
+			&i.PriceEstimate,
+			&i.Currency,
+			&i.Condition,
+			&i.Category,
+			&i.PickupLocation,
+			&i.CanShip,
 			&i.IsAvailable,
 			&i.OwnerID,
 			&i.ClubID,
+			&i.ReservedUntil,
+			&i.ListingType,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -245,7 +528,7 @@ func (q *Queries) GetItemsByClub(ctx context.Context, clubID *string) ([]Item, e
 }
 
 const getItemsByOwner = `-- name: GetItemsByOwner :many
-SELECT id, name, description, is_available, owner_id, club_id, created_at, updated_at
+SELECT id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id, club_id, moderation_status, moderation_reason, reserved_for, reserved_until, listing_type, created_at, updated_at
 FROM items
 WHERE owner_id = ?
 `
@@ -263,9 +546,20 @@ func (q *Queries) GetItemsByOwner(ctx context.Context, ownerID string) ([]Item,
 			&i.ID,
 			&i.Name,
 			&i.Description,
+			&i.PriceEstimate,
+			&i.Currency,
+			&i.Condition,
+			&i.Category,
+			&i.PickupLocation,
+			&i.CanShip,
 			&i.IsAvailable,
 			&i.OwnerID,
 			&i.ClubID,
+			&i.ModerationStatus,
+			&i.ModerationReason,
+			&i.ReservedFor,
+			&i.ReservedUntil,
+			&i.ListingType,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -282,28 +576,6 @@ func (q *Queries) GetItemsByOwner(ctx context.Context, ownerID string) ([]Item,
 	return items, nil
 }
 
-const getTradeByID = `-- name: GetTradeByID :one
-SELECT id, proposer_id, proposer_item_id, responder_id, responder_item_id, status, created_at, updated_at
-FROM trades
-WHERE id = ?
-`
-
-func (q *Queries) GetTradeByID(ctx context.Context, id string) (Trade, error) {
-	row := q.db.QueryRowContext(ctx, getTradeByID, id)
-	var i Trade
-	err := row.Scan(
-		&i.ID,
-		&i.ProposerID,
-		&i.ProposerItemID,
-		&i.ResponderID,
-		&i.ResponderItemID,
-		&i.Status,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
-}
-
 const getUserClubs = `-- name: GetUserClubs :many
 SELECT
     c.id AS club_id, c.name,
@@ -360,13 +632,14 @@ func (q *Queries) GetUserClubs(ctx context.Context, userID string) ([]GetUserClu
 }
 
 const getUserDisplay = `-- name: GetUserDisplay :one
-SELECT username, profile_picture, created_at
+SELECT username, display_name, profile_picture, created_at
 FROM user
 WHERE id = ?
 `
 
 type GetUserDisplayRow struct {
 	Username       string    `json:"username"`
+	DisplayName    *string   `json:"display_name"`
 	ProfilePicture *string   `json:"profile_picture"`
 	CreatedAt      time.Time `json:"created_at"`
 }
@@ -374,10 +647,42 @@ type GetUserDisplayRow struct {
 func (q *Queries) GetUserDisplay(ctx context.Context, id string) (GetUserDisplayRow, error) {
 	row := q.db.QueryRowContext(ctx, getUserDisplay, id)
 	var i GetUserDisplayRow
-	err := row.Scan(&i.Username, &i.ProfilePicture, &i.CreatedAt)
+	err := row.Scan(
+		&i.Username,
+		&i.DisplayName,
+		&i.ProfilePicture,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserEmail = `-- name: GetUserEmail :one
+SELECT email, email_verified_at FROM user WHERE id = ?
+`
+
+type GetUserEmailRow struct {
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+func (q *Queries) GetUserEmail(ctx context.Context, id string) (GetUserEmailRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserEmail, id)
+	var i GetUserEmailRow
+	err := row.Scan(&i.Email, &i.EmailVerifiedAt)
 	return i, err
 }
 
+const getUserIDByEmail = `-- name: GetUserIDByEmail :one
+SELECT id FROM user WHERE email = ?
+`
+
+func (q *Queries) GetUserIDByEmail(ctx context.Context, email string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getUserIDByEmail, email)
+	var id string
+	err := row.Scan(&id)
+	return id, err
+}
+
 const getUserLoginByEmail = `-- name: GetUserLoginByEmail :one
 SELECT id, email, username, password
 FROM user
@@ -505,49 +810,40 @@ func (q *Queries) GetUserMetrics(ctx context.Context, userID string) ([]Metric,
 	return items, nil
 }
 
-const tradeCreate = `-- name: TradeCreate :exec
-INSERT INTO trades (id, proposer_id, proposer_item_id, responder_id, responder_item_id, status, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+const releaseItemReservation = `-- name: ReleaseItemReservation :exec
+UPDATE items
+SET reserved_for = NULL, reserved_until = NULL
+WHERE id = ?
 `
 
-type TradeCreateParams struct {
-	ID              string    `json:"id"`
-	ProposerID      string    `json:"proposer_id"`
-	ProposerItemID  string    `json:"proposer_item_id"`
-	ResponderID     string    `json:"responder_id"`
-	ResponderItemID string    `json:"responder_item_id"`
-	Status          string    `json:"status"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-}
-
-func (q *Queries) TradeCreate(ctx context.Context, arg TradeCreateParams) error {
-	_, err := q.db.ExecContext(ctx, tradeCreate,
-		arg.ID,
-		arg.ProposerID,
-		arg.ProposerItemID,
-		arg.ResponderID,
-		arg.ResponderItemID,
-		arg.Status,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-	)
+func (q *Queries) ReleaseItemReservation(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, releaseItemReservation, id)
 	return err
 }
 
-const transferItemOwnership = `-- name: TransferItemOwnership :exec
+const reserveItem = `-- name: ReserveItem :exec
 UPDATE items
-SET owner_id = ?, updated_at = CURRENT_TIMESTAMP
-WHERE id = ?
+SET reserved_for = ?1, reserved_until = ?2
+WHERE id = ?3
 `
 
-type TransferItemOwnershipParams struct {
-	OwnerID string `json:"owner_id"`
-	ID      string `json:"id"`
+type ReserveItemParams struct {
+	ReservedFor   *string    `json:"reserved_for"`
+	ReservedUntil *time.Time `json:"reserved_until"`
+	ID            string     `json:"id"`
 }
 
-func (q *Queries) TransferItemOwnership(ctx context.Context, arg TransferItemOwnershipParams) error {
-	_, err := q.db.ExecContext(ctx, transferItemOwnership, arg.OwnerID, arg.ID)
+func (q *Queries) ReserveItem(ctx context.Context, arg ReserveItemParams) error {
+	_, err := q.db.ExecContext(ctx, reserveItem, arg.ReservedFor, arg.ReservedUntil, arg.ID)
+	return err
+}
+
+const setUserEmailVerified = `-- name: SetUserEmailVerified :exec
+UPDATE user SET email_verified_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+func (q *Queries) SetUserEmailVerified(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, setUserEmailVerified, id)
 	return err
 }
 
@@ -556,24 +852,43 @@ UPDATE items
 SET
     name = COALESCE(?1, name),
     description = COALESCE(?2, description),
-    is_available = COALESCE(?3, is_available),
-    owner_id = COALESCE(?4, owner_id)
+    price_estimate = COALESCE(?3, price_estimate),
+    currency = COALESCE(?4, currency),
+    -- an empty string clears the condition, category or pickup location
+    condition = NULLIF(COALESCE(CAST(?5 AS TEXT), condition), ''),
+    category = NULLIF(COALESCE(CAST(?6 AS TEXT), category), ''),
+    pickup_location = NULLIF(COALESCE(CAST(?7 AS TEXT), pickup_location), ''),
+    can_ship = COALESCE(?8, can_ship),
+    is_available = COALESCE(?9, is_available),
+    owner_id = COALESCE(?10, owner_id)
 WHERE
-    id = ?5
+    id = ?11
 `
 
 type UpdateItemParams struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
-	IsAvailable *bool   `json:"is_available"`
-	OwnerID     *string `json:"owner_id"`
-	ID          string  `json:"id"`
+	Name           *string  `json:"name"`
+	Description    *string  `json:"description"`
+	PriceEstimate  *float64 `json:"price_estimate"`
+	Currency       *string  `json:"currency"`
+	Condition      *string  `json:"condition"`
+	Category       *string  `json:"category"`
+	PickupLocation *string  `json:"pickup_location"`
+	CanShip        *bool    `json:"can_ship"`
+	IsAvailable    *bool    `json:"is_available"`
+	OwnerID        *string  `json:"owner_id"`
+	ID             string   `json:"id"`
 }
 
 func (q *Queries) UpdateItem(ctx context.Context, arg UpdateItemParams) error {
 	_, err := q.db.ExecContext(ctx, updateItem,
 		arg.Name,
 		arg.Description,
+		arg.PriceEstimate,
+		arg.Currency,
+		arg.Condition,
+		arg.Category,
+		arg.PickupLocation,
+		arg.CanShip,
 		arg.IsAvailable,
 		arg.OwnerID,
 		arg.ID,
@@ -581,22 +896,6 @@ func (q *Queries) UpdateItem(ctx context.Context, arg UpdateItemParams) error {
 	return err
 }
 
-const updateTradeStatus = `-- name: UpdateTradeStatus :exec
-UPDATE trades
-SET status = ?, updated_at = CURRENT_TIMESTAMP
-WHERE id = ?
-`
-
-type UpdateTradeStatusParams struct {
-	Status string `json:"status"`
-	ID     string `json:"id"`
-}
-
-func (q *Queries) UpdateTradeStatus(ctx context.Context, arg UpdateTradeStatusParams) error {
-	_, err := q.db.ExecContext(ctx, updateTradeStatus, arg.Status, arg.ID)
-	return err
-}
-
 const updateUser = `-- name: UpdateUser :exec
 UPDATE user
 SET
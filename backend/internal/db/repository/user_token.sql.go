@@ -0,0 +1,85 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: user_token.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const consumeUserToken = `-- name: ConsumeUserToken :one
+UPDATE user_token
+SET used_at = CURRENT_TIMESTAMP
+WHERE token_hash = ?1 AND purpose = ?2 AND used_at IS NULL
+RETURNING user_id, expires_at
+`
+
+type ConsumeUserTokenParams struct {
+	TokenHash string `json:"token_hash"`
+	Purpose   string `json:"purpose"`
+}
+
+type ConsumeUserTokenRow struct {
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// marks the token used in the same statement that reads it so it can only be redeemed once
+func (q *Queries) ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (ConsumeUserTokenRow, error) {
+	row := q.db.QueryRowContext(ctx, consumeUserToken, arg.TokenHash, arg.Purpose)
+	var i ConsumeUserTokenRow
+	err := row.Scan(&i.UserID, &i.ExpiresAt)
+	return i, err
+}
+
+const createUserToken = `-- name: CreateUserToken :exec
+INSERT INTO user_token (token_hash, user_id, purpose, expires_at)
+VALUES (?1, ?2, ?3, ?4)
+`
+
+type CreateUserTokenParams struct {
+	TokenHash string    `json:"token_hash"`
+	UserID    string    `json:"user_id"`
+	Purpose   string    `json:"purpose"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createUserToken,
+		arg.TokenHash,
+		arg.UserID,
+		arg.Purpose,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const deleteExpiredUserTokens = `-- name: DeleteExpiredUserTokens :exec
+DELETE FROM user_token
+WHERE expires_at < ?1
+`
+
+func (q *Queries) DeleteExpiredUserTokens(ctx context.Context, now time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredUserTokens, now)
+	return err
+}
+
+const invalidateUserTokens = `-- name: InvalidateUserTokens :exec
+UPDATE user_token
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = ?1 AND purpose = ?2 AND used_at IS NULL
+`
+
+type InvalidateUserTokensParams struct {
+	UserID  string `json:"user_id"`
+	Purpose string `json:"purpose"`
+}
+
+// called before issuing a new token so only the latest mail works
+func (q *Queries) InvalidateUserTokens(ctx context.Context, arg InvalidateUserTokensParams) error {
+	_, err := q.db.ExecContext(ctx, invalidateUserTokens, arg.UserID, arg.Purpose)
+	return err
+}
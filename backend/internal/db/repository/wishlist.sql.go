@@ -0,0 +1,204 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: wishlist.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const addToWishlist = `-- name: AddToWishlist :exec
+INSERT INTO wishlist (user_id, item_id)
+VALUES (?, ?)
+ON CONFLICT (user_id, item_id) DO NOTHING
+`
+
+type AddToWishlistParams struct {
+	UserID string `json:"user_id"`
+	ItemID string `json:"item_id"`
+}
+
+func (q *Queries) AddToWishlist(ctx context.Context, arg AddToWishlistParams) error {
+	_, err := q.db.ExecContext(ctx, addToWishlist, arg.UserID, arg.ItemID)
+	return err
+}
+
+const countWishlist = `-- name: CountWishlist :one
+SELECT COUNT(*)
+FROM wishlist
+WHERE user_id = ?
+`
+
+func (q *Queries) CountWishlist(ctx context.Context, userID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countWishlist, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getItemWishlisterIDs = `-- name: GetItemWishlisterIDs :many
+SELECT w.user_id
+FROM wishlist w
+JOIN items i ON i.id = w.item_id
+JOIN club_membership m ON m.club_id = i.club_id AND m.user_id = w.user_id
+WHERE w.item_id = ? AND w.user_id != i.owner_id
+ORDER BY w.user_id
+`
+
+// the members of the item's club who wishlisted it, an owner who got it through a trade is left out
+func (q *Queries) GetItemWishlisterIDs(ctx context.Context, itemID string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getItemWishlisterIDs, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var user_id string
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getItemWishlisters = `-- name: GetItemWishlisters :many
+SELECT u.id, u.username, w.created_at AS wishlisted_at
+FROM wishlist w
+JOIN user u ON u.id = w.user_id
+WHERE w.item_id = ?
+ORDER BY w.created_at, w.rowid
+`
+
+type GetItemWishlistersRow struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	WishlistedAt time.Time `json:"wishlisted_at"`
+}
+
+func (q *Queries) GetItemWishlisters(ctx context.Context, itemID string) ([]GetItemWishlistersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getItemWishlisters, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetItemWishlistersRow
+	for rows.Next() {
+		var i GetItemWishlistersRow
+		if err := rows.Scan(&i.ID, &i.Username, &i.WishlistedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWishlist = `-- name: GetWishlist :many
+SELECT
+    i.id, i.name, i.description, i.price_estimate, i.currency, i.condition, i.category,
+    i.pickup_location, i.can_ship, i.is_available, i.owner_id, u.username AS owner_username,
+    i.club_id, i.reserved_for, i.reserved_until, i.listing_type, w.created_at AS wishlisted_at
+FROM wishlist w
+JOIN items i ON i.id = w.item_id
+JOIN club_membership m ON m.club_id = i.club_id AND m.user_id = w.user_id
+JOIN user u ON u.id = i.owner_id
+WHERE w.user_id = ?1 AND i.moderation_status = 'approved'
+ORDER BY w.created_at DESC, w.rowid DESC
+`
+
+type GetWishlistRow struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Description    *string    `json:"description"`
+	PriceEstimate  *float64   `json:"price_estimate"`
+	Currency       *string    `json:"currency"`
+	Condition      *string    `json:"condition"`
+	Category       *string    `json:"category"`
+	PickupLocation *string    `json:"pickup_location"`
+	CanShip        bool       `json:"can_ship"`
+	IsAvailable    bool       `json:"is_available"`
+	OwnerID        string     `json:"owner_id"`
+	OwnerUsername  string     `json:"owner_username"`
+	ClubID         *string    `json:"club_id"`
+	ReservedFor    *string    `json:"reserved_for"`
+	ReservedUntil  *time.Time `json:"reserved_until"`
+	ListingType    string     `json:"listing_type"`
+	WishlistedAt   time.Time  `json:"wishlisted_at"`
+}
+
+// wishlisted items of the clubs the user is still a member of, the latest first.
+// unavailable and reserved items are listed too so the user sees why they cannot trade for them
+func (q *Queries) GetWishlist(ctx context.Context, userID string) ([]GetWishlistRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWishlist, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWishlistRow
+	for rows.Next() {
+		var i GetWishlistRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.PriceEstimate,
+			&i.Currency,
+			&i.Condition,
+			&i.Category,
+			&i.PickupLocation,
+			&i.CanShip,
+			&i.IsAvailable,
+			&i.OwnerID,
+			&i.OwnerUsername,
+			&i.ClubID,
+			&i.ReservedFor,
+			&i.ReservedUntil,
+			&i.ListingType,
+			&i.WishlistedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeFromWishlist = `-- name: RemoveFromWishlist :execrows
+DELETE FROM wishlist
+WHERE user_id = ? AND item_id = ?
+`
+
+type RemoveFromWishlistParams struct {
+	UserID string `json:"user_id"`
+	ItemID string `json:"item_id"`
+}
+
+func (q *Queries) RemoveFromWishlist(ctx context.Context, arg RemoveFromWishlistParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeFromWishlist, arg.UserID, arg.ItemID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
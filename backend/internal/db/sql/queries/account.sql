@@ -0,0 +1,62 @@
+-- name: ScheduleUserDeletion :exec
+UPDATE user SET deletion_scheduled_at = @deletion_scheduled_at WHERE id = @id;
+
+-- name: CancelUserDeletion :exec
+UPDATE user SET deletion_scheduled_at = NULL WHERE id = ?;
+
+-- name: GetUserDeletionScheduledAt :one
+SELECT deletion_scheduled_at FROM user WHERE id = ?;
+
+-- name: GetUsersDueForDeletion :many
+SELECT id, profile_picture
+FROM user
+WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= @now;
+
+-- name: GetOwnedClubs :many
+SELECT id, name, banner_image
+FROM club
+WHERE owner_user_id = ?;
+
+-- name: GetClubSuccessor :one
+-- moderators first, then the longest standing member
+SELECT user_id
+FROM club_membership
+WHERE club_id = @club_id AND user_id != @user_id
+ORDER BY is_moderator DESC, created_at ASC
+LIMIT 1;
+
+-- name: TransferClubOwnership :exec
+UPDATE club SET owner_user_id = @owner_user_id WHERE id = @id;
+
+-- name: GetUserExportProfile :one
+SELECT id, email, username, display_name, bio, location, pronouns, profile_picture, profile_visibility, show_clubs, show_stats, show_items,
+    email_verified_at, totp_enabled_at, deletion_scheduled_at, created_at, updated_at
+FROM user
+WHERE id = ?;
+
+-- name: GetUserExportPosts :many
+SELECT cp.id, cp.club_id, c.name AS club_name, cp.content, cp.created_at, cp.updated_at
+FROM club_post cp
+JOIN club c ON c.id = cp.club_id
+WHERE cp.user_id = ?
+ORDER BY cp.created_at ASC;
+
+-- name: GetUserExportMetricEntries :many
+SELECT m.id AS metric_id, m.title AS metric_title, m.unit, c.name AS club_name, mi.due_at, me.value, me.created_at, me.updated_at
+FROM metric_entry me
+JOIN metric_instance mi ON mi.id = me.metric_instance_id
+JOIN metric m ON m.id = mi.metric_id
+JOIN club c ON c.id = m.club_id
+WHERE me.user_id = ?
+ORDER BY me.created_at ASC;
+
+-- name: GetUserExportMessages :many
+SELECT pm.id, s.username AS sender, r.username AS recipient, pm.content, pm.created_at
+FROM user_private_message pm
+JOIN user s ON s.id = pm.sender_id
+JOIN user r ON r.id = pm.recipient_id
+WHERE pm.sender_id = @user_id OR pm.recipient_id = @user_id
+ORDER BY pm.created_at ASC;
+
+-- name: GetUserPasswordHash :one
+SELECT password FROM user WHERE id = ?;
@@ -0,0 +1,68 @@
+-- name: GetUserRole :one
+SELECT role FROM user WHERE id = @id;
+
+-- name: SetUserRole :execrows
+UPDATE user SET role = @role WHERE id = @id;
+
+-- name: GrantAdminRoleByEmail :execrows
+-- only a verified address proves the account belongs to the listed person and not to whoever registered it first
+UPDATE user SET role = 'admin' WHERE email = @email AND email_verified_at IS NOT NULL AND role != 'admin';
+
+-- name: GetUserDisabledAt :one
+SELECT disabled_at FROM user WHERE id = @id;
+
+-- name: DisableUser :execrows
+UPDATE user
+SET disabled_at = CURRENT_TIMESTAMP, disabled_reason = @disabled_reason
+WHERE id = @id AND disabled_at IS NULL;
+
+-- name: EnableUser :execrows
+UPDATE user
+SET disabled_at = NULL, disabled_reason = NULL
+WHERE id = @id AND disabled_at IS NOT NULL;
+
+-- name: SearchUsers :many
+-- matches part of the username or email, or the exact id. an empty query lists everyone
+SELECT
+    id, email, username, display_name, role, email_verified_at,
+    disabled_at, disabled_reason, deletion_scheduled_at, created_at
+FROM user
+WHERE
+    CAST(@query AS TEXT) = ''
+    OR id = @query
+    OR instr(lower(username), lower(@query)) > 0
+    OR instr(lower(email), lower(@query)) > 0
+ORDER BY created_at DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: SearchClubs :many
+-- matches part of the club name, the owner's username or the exact id. private clubs are included
+SELECT
+    c.id, c.name, c.description, c.is_public, c.owner_user_id,
+    u.username AS owner_username,
+    (SELECT COUNT(*) FROM club_membership m WHERE m.club_id = c.id) AS member_count,
+    c.created_at
+FROM club c
+JOIN user u ON u.id = c.owner_user_id
+WHERE
+    CAST(@query AS TEXT) = ''
+    OR c.id = @query
+    OR instr(lower(c.name), lower(@query)) > 0
+    OR instr(lower(u.username), lower(@query)) > 0
+ORDER BY c.created_at DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: CreateAdminAuditLogEntry :exec
+INSERT INTO admin_audit_log (id, admin_id, action, target_type, target_id, reason, ip_address, report_id, created_at)
+VALUES (@id, @admin_id, @action, @target_type, @target_id, @reason, @ip_address, @report_id, @created_at);
+
+-- name: GetAdminAuditLog :many
+-- empty filters match every entry
+SELECT a.*, COALESCE(u.username, '[deleted]') AS admin_username
+FROM admin_audit_log a
+LEFT JOIN user u ON u.id = a.admin_id
+WHERE
+    (CAST(@admin_id AS TEXT) = '' OR a.admin_id = @admin_id)
+    AND (CAST(@target_id AS TEXT) = '' OR a.target_id = @target_id)
+ORDER BY a.created_at DESC
+LIMIT @limit OFFSET @offset;
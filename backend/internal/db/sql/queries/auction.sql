@@ -0,0 +1,49 @@
+-- name: CreateAuction :exec
+INSERT INTO auction (id, item_id, club_id, seller_id, start_price, reserve_price, bid_increment, currency, ends_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: SetItemListingType :exec
+UPDATE items
+SET listing_type = @listing_type
+WHERE id = @id;
+
+-- name: GetAuction :one
+SELECT *
+FROM auction
+WHERE id = ?;
+
+-- name: GetClubAuctions :many
+-- the club's open auctions, the ones ending first first
+SELECT *
+FROM auction
+WHERE club_id = ? AND status = 'open'
+ORDER BY ends_at, rowid;
+
+-- name: GetAuctionBids :many
+-- the highest bid first, of equal bids the earlier one
+SELECT b.id, b.bidder_id, u.username AS bidder_username, b.amount, b.created_at
+FROM auction_bid b
+JOIN user u ON u.id = b.bidder_id
+WHERE b.auction_id = ?
+ORDER BY b.amount DESC, b.rowid;
+
+-- name: CreateAuctionBid :exec
+INSERT INTO auction_bid (id, auction_id, bidder_id, amount)
+VALUES (?, ?, ?, ?);
+
+-- name: ExtendAuction :exec
+UPDATE auction
+SET ends_at = @ends_at
+WHERE id = @id;
+
+-- name: GetEndedAuctions :many
+SELECT id
+FROM auction
+WHERE status = 'open' AND ends_at <= @now
+ORDER BY ends_at;
+
+-- name: CloseAuction :execrows
+-- does nothing if the auction was already closed
+UPDATE auction
+SET status = @status, winner_id = @winner_id, winning_bid = @winning_bid
+WHERE id = @id AND status = 'open';
@@ -14,7 +14,8 @@ SET
     name = COALESCE(sqlc.narg(name), name),
     description = COALESCE(sqlc.narg(description), description),
     banner_image = COALESCE(sqlc.narg(banner_image), banner_image),
-    is_public = COALESCE(sqlc.narg(is_public), is_public)
+    is_public = COALESCE(sqlc.narg(is_public), is_public),
+    min_listing_reputation = COALESCE(sqlc.narg(min_listing_reputation), min_listing_reputation)
 WHERE
     id = @id;
 
@@ -42,10 +43,13 @@ WHERE
 INSERT INTO club_membership (user_id, club_id, is_moderator)
 VALUES (@user_id, @club_id, @is_moderator);
 
+-- name: GetClubMembership :one
+SELECT * FROM club_membership WHERE user_id = ? AND club_id = ?;
+
 -- name: UpdateClubMembership :exec
+-- points only change through the points ledger
 UPDATE club_membership
 SET
-    user_points = COALESCE(sqlc.narg(user_points), user_points),
     user_streak = COALESCE(sqlc.narg(user_streak), user_streak),
     is_moderator = COALESCE(sqlc.narg(is_moderator), is_moderator)
 WHERE
@@ -57,8 +61,8 @@ WHERE
     user_id = @user_id AND club_id = @club_id;
 
 -- name: CreateClubPost :exec
-INSERT INTO club_post (id, club_id, user_id, content)
-VALUES (@id, @club_id, @user_id, @content);
+INSERT INTO club_post (id, club_id, user_id, content, moderation_status, moderation_reason)
+VALUES (@id, @club_id, @user_id, @content, @moderation_status, @moderation_reason);
 
 -- name: UpdateClubPost :exec
 UPDATE club_post
@@ -73,17 +77,17 @@ WHERE
     id = @id;
 
 -- name: GetClubPosts :many
-SELECT cp.*, u.username as author_username, c.name as club_name
+SELECT cp.*, COALESCE(u.username, '[deleted]') as author_username, c.name as club_name
 FROM club_post cp
-JOIN "user" u on u.id = cp.user_id
+LEFT JOIN "user" u on u.id = cp.user_id
 JOIN club c on c.id = cp.club_id
-WHERE cp.club_id = @club_id
+WHERE cp.club_id = @club_id AND (cp.moderation_status = 'approved' OR cp.user_id = @user_id)
 ORDER BY cp.created_at ASC;
 
 -- name: GetClubPost :one
-SELECT cp.*, u.username as author_username, c.name as club_name
+SELECT cp.*, COALESCE(u.username, '[deleted]') as author_username, c.name as club_name
 FROM club_post cp
-JOIN "user" u on u.id = cp.user_id
+LEFT JOIN "user" u on u.id = cp.user_id
 JOIN club c on c.id = cp.club_id
 WHERE cp.id = @id;
 
@@ -119,7 +123,11 @@ SELECT EXISTS(SELECT 1 FROM club_membership WHERE user_id = ? AND club_id = ?);
 -- name: IsUserModeratorOfClub :one
 -- checks if user is moderator or owner of club
 -- returns boolean
-SELECT EXISTS(SELECT 1 FROM club_membership WHERE user_id = ? AND club_id = ? AND (is_moderator = true OR is_owner = true));
+SELECT EXISTS(
+    SELECT 1 FROM club_membership WHERE user_id = @user_id AND club_id = @club_id AND is_moderator = true
+    UNION ALL
+    SELECT 1 FROM club WHERE id = @club_id AND owner_user_id = @user_id
+);
 
 -- name: IsUserOwnerOfClub :one
 -- returns boolean
@@ -0,0 +1,50 @@
+-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempt (id, action, account, identifier, user_id, ip_address, user_agent, succeeded, failure_reason, attempted_at)
+VALUES (@id, @action, @account, @identifier, @user_id, @ip_address, @user_agent, @succeeded, @failure_reason, @attempted_at);
+
+-- name: FinishLoginAttempt :exec
+UPDATE login_attempt
+SET succeeded = @succeeded, failure_reason = @failure_reason
+WHERE id = @id;
+
+-- name: DeleteLoginAttempt :exec
+DELETE FROM login_attempt
+WHERE id = @id;
+
+-- name: GetRecentAccountLoginFailures :many
+-- newest first, registrations do not count against an account
+SELECT attempted_at
+FROM login_attempt
+WHERE account = @account AND action != 'register' AND succeeded = 0 AND attempted_at > @since
+ORDER BY attempted_at DESC
+LIMIT @max_rows;
+
+-- name: GetLastAccountLoginSuccess :one
+SELECT attempted_at
+FROM login_attempt
+WHERE account = @account AND action != 'register' AND succeeded = 1
+ORDER BY attempted_at DESC
+LIMIT 1;
+
+-- name: GetRecentIPLoginFailures :many
+SELECT attempted_at
+FROM login_attempt
+WHERE ip_address = @ip_address AND action != 'register' AND succeeded = 0 AND attempted_at > @since
+ORDER BY attempted_at DESC
+LIMIT @max_rows;
+
+-- name: CountRecentIPRegistrations :one
+SELECT COUNT(*)
+FROM login_attempt
+WHERE ip_address = @ip_address AND action = 'register' AND attempted_at > @since;
+
+-- name: GetUserLoginFailures :many
+SELECT action, ip_address, user_agent, failure_reason, attempted_at
+FROM login_attempt
+WHERE user_id = @user_id AND succeeded = 0
+ORDER BY attempted_at DESC
+LIMIT @max_rows;
+
+-- name: DeleteOldLoginAttempts :exec
+DELETE FROM login_attempt
+WHERE attempted_at < @before;
@@ -0,0 +1,27 @@
+-- name: CreateDirectMessage :exec
+INSERT INTO user_private_message (id, sender_id, recipient_id, content)
+VALUES (@id, @sender_id, @recipient_id, @content);
+
+-- name: GetDirectMessage :one
+SELECT id, sender_id, recipient_id, content, created_at
+FROM user_private_message
+WHERE id = ?;
+
+-- name: GetDirectMessages :many
+SELECT id, sender_id, recipient_id, content, created_at
+FROM user_private_message
+WHERE (sender_id = @user_id AND recipient_id = @other_id) OR (sender_id = @other_id AND recipient_id = @user_id)
+ORDER BY created_at ASC, rowid ASC;
+
+-- name: HasDirectMessages :one
+SELECT EXISTS(
+    SELECT 1 FROM user_private_message
+    WHERE (sender_id = @user_id AND recipient_id = @other_id) OR (sender_id = @other_id AND recipient_id = @user_id)
+);
+
+-- name: ShareClub :one
+SELECT EXISTS(
+    SELECT 1 FROM club_membership a
+    JOIN club_membership b ON b.club_id = a.club_id
+    WHERE a.user_id = @user_id AND b.user_id = @other_id
+);
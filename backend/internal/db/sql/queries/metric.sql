@@ -36,6 +36,9 @@ WHERE
 INSERT INTO metric_entry (user_id, metric_instance_id, value)
 VALUES (@user_id, @metric_instance_id, @value);
 
+-- name: HasMetricEntry :one
+SELECT EXISTS(SELECT 1 FROM metric_entry WHERE user_id = @user_id AND metric_instance_id = @metric_instance_id);
+
 -- name: UpdateMetricEntry :exec
 UPDATE metric_entry
 SET
@@ -83,7 +86,19 @@ WHERE
     id = @id;
 
 -- name: GetLatestMetricInstance :one
-SELECT * FROM metric_instance WHERE metric_id = ? ORDER BY created_at DESC LIMIT 1;
+SELECT * FROM metric_instance WHERE metric_id = ? ORDER BY created_at DESC, rowid DESC LIMIT 1;
+
+-- name: HasEntryInPreviousMetricInstance :one
+-- whether the user turned in the instance of the metric before the given one
+SELECT EXISTS(
+    SELECT 1 FROM metric_entry
+    WHERE user_id = @user_id AND metric_instance_id = (
+        SELECT id FROM metric_instance
+        WHERE metric_id = @metric_id AND id != @metric_instance_id
+        ORDER BY created_at DESC, rowid DESC
+        LIMIT 1
+    )
+);
 
 -- name: GetMetricEntries :many
 SELECT * FROM metric_entry WHERE metric_instance_id = @metric_instance_id;
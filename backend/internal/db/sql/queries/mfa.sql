@@ -0,0 +1,43 @@
+-- name: GetUserTOTP :one
+SELECT username, totp_secret, totp_enabled_at, totp_last_step
+FROM user
+WHERE id = ?;
+
+-- name: SetUserTOTPSecret :exec
+-- starts enrollment, two factor auth is not active until EnableUserTOTP
+UPDATE user
+SET totp_secret = @totp_secret, totp_enabled_at = NULL, totp_last_step = NULL
+WHERE id = @id;
+
+-- name: EnableUserTOTP :exec
+UPDATE user
+SET totp_enabled_at = CURRENT_TIMESTAMP
+WHERE id = @id AND totp_secret IS NOT NULL;
+
+-- name: DisableUserTOTP :exec
+UPDATE user
+SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL
+WHERE id = @id;
+
+-- name: UpdateUserTOTPLastStep :execrows
+-- only moves forward, so a code that was already accepted cannot be used again
+UPDATE user
+SET totp_last_step = @step
+WHERE id = @id AND (totp_last_step IS NULL OR totp_last_step < @step);
+
+-- name: CreateUserRecoveryCode :exec
+INSERT INTO user_recovery_code (user_id, code_hash)
+VALUES (@user_id, @code_hash);
+
+-- name: UseUserRecoveryCode :execrows
+UPDATE user_recovery_code
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = @user_id AND code_hash = @code_hash AND used_at IS NULL;
+
+-- name: DeleteUserRecoveryCodes :exec
+DELETE FROM user_recovery_code
+WHERE user_id = @user_id;
+
+-- name: CountUnusedUserRecoveryCodes :one
+SELECT COUNT(*) FROM user_recovery_code
+WHERE user_id = @user_id AND used_at IS NULL;
@@ -0,0 +1,65 @@
+-- name: GetClubBlockedTerms :many
+SELECT *
+FROM club_blocked_term
+WHERE club_id = @club_id
+ORDER BY term;
+
+-- name: UpsertClubBlockedTerm :exec
+INSERT INTO club_blocked_term (club_id, term, action)
+VALUES (@club_id, @term, @action)
+ON CONFLICT (club_id, term) DO UPDATE SET action = excluded.action;
+
+-- name: DeleteClubBlockedTerm :execrows
+DELETE FROM club_blocked_term
+WHERE club_id = @club_id AND term = @term;
+
+-- name: CountRecentClubPostsByUser :one
+-- since is formatted like CURRENT_TIMESTAMP, the post with exclude_id is left out
+SELECT COUNT(*)
+FROM club_post
+WHERE user_id = @user_id AND id != @exclude_id AND created_at > CAST(@since AS TEXT);
+
+-- name: CountRecentClubPostsWithLink :one
+SELECT COUNT(*)
+FROM club_post
+WHERE user_id = @user_id AND id != @exclude_id AND created_at > CAST(@since AS TEXT) AND instr(lower(content), @link) > 0;
+
+-- name: CountRecentItemsByOwner :one
+SELECT COUNT(*)
+FROM items
+WHERE owner_id = @owner_id AND id != @exclude_id AND created_at > CAST(@since AS TEXT);
+
+-- name: CountRecentItemsWithLink :one
+SELECT COUNT(*)
+FROM items
+WHERE owner_id = @owner_id AND id != @exclude_id AND created_at > CAST(@since AS TEXT)
+    AND instr(lower(name || ' ' || COALESCE(description, '')), @link) > 0;
+
+-- name: GetHeldClubPosts :many
+SELECT cp.*, COALESCE(u.username, '[deleted]') AS author_username
+FROM club_post cp
+LEFT JOIN user u ON u.id = cp.user_id
+WHERE cp.club_id = @club_id AND cp.moderation_status = 'pending'
+ORDER BY cp.created_at ASC;
+
+-- name: GetHeldClubItems :many
+SELECT i.*, u.username AS owner_username
+FROM items i
+JOIN user u ON u.id = i.owner_id
+WHERE i.club_id = @club_id AND i.moderation_status = 'pending'
+ORDER BY i.created_at ASC;
+
+-- name: ApproveClubPost :execrows
+UPDATE club_post
+SET moderation_status = 'approved', moderation_reason = NULL
+WHERE id = @id AND club_id = @club_id AND moderation_status = 'pending';
+
+-- name: ApproveItem :execrows
+UPDATE items
+SET moderation_status = 'approved', moderation_reason = NULL
+WHERE id = @id AND club_id = @club_id AND moderation_status = 'pending';
+
+-- name: HoldItem :exec
+UPDATE items
+SET moderation_status = 'pending', moderation_reason = @moderation_reason
+WHERE id = @id;
@@ -0,0 +1,32 @@
+-- name: CreateOIDCLoginState :exec
+INSERT INTO oidc_login_state (state, provider, code_verifier_hash, nonce, expires_at)
+VALUES (@state, @provider, @code_verifier_hash, @nonce, @expires_at);
+
+-- name: ConsumeOIDCLoginState :one
+-- deletes the state in the same statement that reads it so a callback can only be redeemed once.
+-- a callback without the verifier the login was started with leaves the state for the app that started it
+DELETE FROM oidc_login_state
+WHERE state = @state AND provider = @provider AND code_verifier_hash = @code_verifier_hash
+RETURNING nonce, expires_at;
+
+-- name: DeleteExpiredOIDCLoginStates :exec
+DELETE FROM oidc_login_state
+WHERE expires_at < @now;
+
+-- name: GetUserIdentity :one
+SELECT provider, subject, user_id, email
+FROM user_identity
+WHERE provider = @provider AND subject = @subject;
+
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identity (provider, subject, user_id, email)
+VALUES (@provider, @subject, @user_id, @email);
+
+-- name: GetUserIdentities :many
+SELECT provider, email, created_at
+FROM user_identity
+WHERE user_id = @user_id
+ORDER BY created_at;
+
+-- name: UsernameExists :one
+SELECT EXISTS (SELECT 1 FROM user WHERE username = @username);
@@ -0,0 +1,33 @@
+-- name: CreatePersonalAccessToken :exec
+INSERT INTO personal_access_token (id, user_id, name, token_hash, scopes, expires_at)
+VALUES (@id, @user_id, @name, @token_hash, @scopes, @expires_at);
+
+-- name: GetPersonalAccessTokenByHash :one
+SELECT id, user_id, scopes, expires_at, last_used_at, revoked_at
+FROM personal_access_token
+WHERE token_hash = @token_hash;
+
+-- name: GetUserPersonalAccessTokens :many
+SELECT id, name, scopes, expires_at, last_used_at, created_at
+FROM personal_access_token
+WHERE user_id = @user_id AND revoked_at IS NULL AND expires_at > @now
+ORDER BY created_at DESC;
+
+-- name: TouchPersonalAccessToken :exec
+UPDATE personal_access_token
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: RevokePersonalAccessToken :execrows
+UPDATE personal_access_token
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = @id AND user_id = @user_id AND revoked_at IS NULL;
+
+-- name: RevokeAllPersonalAccessTokens :exec
+UPDATE personal_access_token
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = @user_id AND revoked_at IS NULL;
+
+-- name: DeleteExpiredPersonalAccessTokens :exec
+DELETE FROM personal_access_token
+WHERE expires_at < @now;
@@ -0,0 +1,32 @@
+-- name: CreatePointLedgerEntry :exec
+INSERT INTO point_ledger (id, club_id, user_id, amount, balance, reason, reference_id, actor_id, note)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetPointLedger :many
+-- the oldest first
+SELECT * FROM point_ledger
+WHERE club_id = ? AND user_id = ?
+ORDER BY created_at, rowid;
+
+-- name: GetPointHistory :many
+-- the newest first
+SELECT
+    point_ledger.*,
+    user.username AS actor_username
+FROM point_ledger
+LEFT JOIN user ON user.id = point_ledger.actor_id
+WHERE point_ledger.club_id = @club_id AND point_ledger.user_id = @user_id
+ORDER BY point_ledger.created_at DESC, point_ledger.rowid DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: RebuildPointBalances :execrows
+-- sets every member's points to the sum of their ledger entries, returns how many were wrong
+UPDATE club_membership
+SET user_points = (
+    SELECT COALESCE(SUM(amount), 0.0) FROM point_ledger
+    WHERE point_ledger.club_id = club_membership.club_id AND point_ledger.user_id = club_membership.user_id
+)
+WHERE user_points != (
+    SELECT COALESCE(SUM(amount), 0.0) FROM point_ledger
+    WHERE point_ledger.club_id = club_membership.club_id AND point_ledger.user_id = club_membership.user_id
+);
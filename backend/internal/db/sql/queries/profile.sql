@@ -0,0 +1,68 @@
+-- name: GetUserProfile :one
+SELECT id, username, display_name, profile_picture, bio, location, pronouns,
+    profile_visibility, show_clubs, show_stats, show_items, created_at
+FROM user
+WHERE id = ?;
+
+-- name: UpdateUserProfile :exec
+UPDATE user
+SET
+    display_name = COALESCE(sqlc.narg(display_name), display_name),
+    bio = COALESCE(sqlc.narg(bio), bio),
+    location = COALESCE(sqlc.narg(location), location),
+    pronouns = COALESCE(sqlc.narg(pronouns), pronouns),
+    profile_visibility = COALESCE(sqlc.narg(profile_visibility), profile_visibility),
+    show_clubs = COALESCE(sqlc.narg(show_clubs), show_clubs),
+    show_stats = COALESCE(sqlc.narg(show_stats), show_stats),
+    show_items = COALESCE(sqlc.narg(show_items), show_items)
+WHERE
+    id = @id;
+
+-- name: GetUserLinks :many
+SELECT label, url
+FROM user_link
+WHERE user_id = ?
+ORDER BY position ASC;
+
+-- name: DeleteUserLinks :exec
+DELETE FROM user_link WHERE user_id = ?;
+
+-- name: CreateUserLink :exec
+INSERT INTO user_link (user_id, position, label, url)
+VALUES (@user_id, @position, @label, @url);
+
+-- name: GetUserPublicClubs :many
+SELECT c.id AS club_id, c.name, c.banner_image, cm.user_points, cm.user_streak, cm.created_at AS joined_at
+FROM club_membership cm
+JOIN club c ON c.id = cm.club_id
+WHERE cm.user_id = ? AND c.is_public = TRUE
+ORDER BY cm.user_points DESC;
+
+-- name: GetUserStats :one
+-- across every membership, private clubs count without being named
+SELECT
+    COUNT(*) AS club_count,
+    CAST(COALESCE(SUM(user_points), 0) AS REAL) AS total_points,
+    CAST(COALESCE(MAX(user_streak), 0) AS INTEGER) AS longest_streak
+FROM club_membership
+WHERE user_id = ?;
+
+-- name: GetAvailableItemsByOwner :many
+-- the owner's trade listings as the marketplace shows them to the viewer: items outside of clubs, in public clubs
+-- and in the viewer's clubs, reserved items only for the user they are reserved for. the owner sees all of them
+SELECT *
+FROM items
+WHERE owner_id = @owner_id AND is_available = TRUE AND moderation_status = 'approved' AND listing_type = 'trade'
+    AND (owner_id = @viewer_id OR (
+        (reserved_until IS NULL OR reserved_until <= @now OR reserved_for = CAST(@viewer_id AS TEXT))
+        AND (club_id IS NULL
+            OR club_id IN (SELECT id FROM club WHERE is_public = TRUE)
+            OR club_id IN (SELECT club_id FROM club_membership WHERE user_id = @viewer_id))
+    ))
+ORDER BY created_at DESC;
+
+-- name: AreFriends :one
+SELECT EXISTS(
+    SELECT 1 FROM user_friendship
+    WHERE (user_id = @user_id AND friend_id = @other_id) OR (user_id = @other_id AND friend_id = @user_id)
+);
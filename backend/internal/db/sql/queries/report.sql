@@ -0,0 +1,71 @@
+-- name: GetUnresolvedReportForTarget :one
+SELECT * FROM report
+WHERE target_type = @target_type AND target_id = @target_id AND status IN ('open', 'triaged');
+
+-- name: CreateReport :exec
+INSERT INTO report (id, target_type, target_id, club_id)
+VALUES (@id, @target_type, @target_id, @club_id);
+
+-- name: HasReportSubmission :one
+-- returns boolean
+SELECT EXISTS(SELECT 1 FROM report_submission WHERE report_id = @report_id AND reporter_id = @reporter_id);
+
+-- name: CreateReportSubmission :exec
+INSERT INTO report_submission (report_id, reporter_id, category, comment)
+VALUES (@report_id, @reporter_id, @category, @comment);
+
+-- name: GetReport :one
+SELECT * FROM report WHERE id = @id;
+
+-- name: GetReportSubmissions :many
+SELECT rs.reporter_id, u.username AS reporter_username, rs.category, rs.comment, rs.created_at
+FROM report_submission rs
+JOIN user u ON u.id = rs.reporter_id
+WHERE rs.report_id = @report_id
+ORDER BY rs.created_at;
+
+-- name: GetReportQueue :many
+-- reports with how often they were submitted, the most reported first.
+-- empty filters match every report, an empty club id means reports routed to site admins unless all_clubs is set
+SELECT r.*, (SELECT COUNT(*) FROM report_submission rs WHERE rs.report_id = r.id) AS submission_count
+FROM report r
+WHERE
+    (CAST(@status AS TEXT) = '' OR r.status = @status)
+    AND (CAST(@target_type AS TEXT) = '' OR r.target_type = @target_type)
+    AND (
+        CAST(@all_clubs AS BOOLEAN)
+        OR (CAST(@club_id AS TEXT) = '' AND r.club_id IS NULL)
+        OR r.club_id = @club_id
+    )
+ORDER BY submission_count DESC, r.created_at
+LIMIT @limit OFFSET @offset;
+
+-- name: GetReportsByReporter :many
+SELECT r.id, r.target_type, r.target_id, r.status, r.resolution_action, r.resolved_at, rs.category, rs.comment, rs.created_at
+FROM report_submission rs
+JOIN report r ON r.id = rs.report_id
+WHERE rs.reporter_id = @reporter_id
+ORDER BY rs.created_at DESC;
+
+-- name: TriageReport :execrows
+UPDATE report
+SET status = 'triaged', handled_by = @handled_by
+WHERE id = @id AND status = 'open';
+
+-- name: ResolveReport :execrows
+UPDATE report
+SET
+    status = @status,
+    handled_by = @handled_by,
+    resolution_action = @resolution_action,
+    resolution_note = @resolution_note,
+    resolution_action_id = @resolution_action_id,
+    resolved_at = CURRENT_TIMESTAMP
+WHERE id = @id AND status IN ('open', 'triaged');
+
+-- name: CreateModerationAction :exec
+INSERT INTO moderation_action (id, moderator_id, action, target_type, target_id, club_id, as_admin)
+VALUES (@id, @moderator_id, @action, @target_type, @target_id, @club_id, @as_admin);
+
+-- name: GetModerationAction :one
+SELECT * FROM moderation_action WHERE id = @id;
@@ -0,0 +1,58 @@
+-- name: CreateClubReward :exec
+INSERT INTO club_reward (id, club_id, name, description, cost, stock, item_id)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetClubReward :one
+SELECT * FROM club_reward WHERE id = ?;
+
+-- name: GetClubRewards :many
+-- inactive rewards are only listed for moderators
+SELECT * FROM club_reward
+WHERE club_id = @club_id AND (is_active OR CAST(@include_inactive AS BOOLEAN))
+ORDER BY cost, name;
+
+-- name: UpdateClubReward :exec
+UPDATE club_reward
+SET
+    name = COALESCE(sqlc.narg(name), name),
+    description = COALESCE(sqlc.narg(description), description),
+    cost = COALESCE(sqlc.narg(cost), cost),
+    stock = CASE WHEN CAST(@unlimited_stock AS BOOLEAN) THEN NULL ELSE COALESCE(sqlc.narg(stock), stock) END,
+    is_active = COALESCE(sqlc.narg(is_active), is_active)
+WHERE id = @id;
+
+-- name: TakeRewardStock :execrows
+-- fails without changing anything if the reward is inactive or none are left
+UPDATE club_reward
+SET stock = stock - 1
+WHERE id = ? AND is_active AND (stock IS NULL OR stock > 0);
+
+-- name: ReturnRewardStock :exec
+UPDATE club_reward
+SET stock = stock + 1
+WHERE id = ? AND stock IS NOT NULL;
+
+-- name: CreateRewardRedemption :exec
+INSERT INTO reward_redemption (id, reward_id, club_id, user_id, cost, note)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetRewardRedemption :one
+SELECT * FROM reward_redemption WHERE id = ?;
+
+-- name: GetClubRedemptions :many
+-- the newest first, both filters are optional
+SELECT
+    r.id, r.reward_id, cr.name AS reward_name, r.club_id, r.user_id, u.username, r.cost, r.note,
+    r.status, r.handled_by, r.handled_at, r.created_at
+FROM reward_redemption r
+JOIN club_reward cr ON cr.id = r.reward_id
+JOIN user u ON u.id = r.user_id
+WHERE r.club_id = @club_id
+    AND (CAST(sqlc.narg(user_id) AS TEXT) IS NULL OR r.user_id = sqlc.narg(user_id))
+    AND (CAST(sqlc.narg(status) AS TEXT) IS NULL OR r.status = sqlc.narg(status))
+ORDER BY r.created_at DESC, r.rowid DESC;
+
+-- name: HandleRewardRedemption :execrows
+UPDATE reward_redemption
+SET status = @status, handled_by = @handled_by, handled_at = CURRENT_TIMESTAMP
+WHERE id = @id AND status = 'pending';
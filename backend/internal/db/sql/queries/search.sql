@@ -0,0 +1,71 @@
+-- name: SearchItems :many
+-- available items in every club the user is a member of. an empty match or filter does not narrow the search,
+-- items without a price estimate are left out by the price filters and listed last when sorting by price.
+-- reserved items are only found by the user they are reserved for
+SELECT
+    i.id, i.name, i.description, i.price_estimate, i.currency, i.condition, i.category,
+    i.pickup_location, i.can_ship, i.is_available, i.owner_id, u.username AS owner_username,
+    i.club_id, c.name AS club_name, i.reserved_until, i.listing_type, i.created_at
+FROM (
+    SELECT *,
+        CASE CAST(@sort AS TEXT)
+            WHEN 'price_asc' THEN price_estimate
+            WHEN 'price_desc' THEN -price_estimate
+        END AS sort_price
+    FROM items
+    WHERE is_available = TRUE AND moderation_status = 'approved'
+        AND (reserved_until IS NULL OR reserved_until <= @now OR reserved_for = CAST(@user_id AS TEXT))
+        AND (CAST(@match AS TEXT) = '' OR id IN (SELECT item_id FROM item_search WHERE item_search MATCH @match))
+        AND (CAST(@category AS TEXT) = '' OR category = @category)
+        AND (CAST(@condition AS TEXT) = '' OR condition = @condition)
+        AND (CAST(@owner_id AS TEXT) = '' OR owner_id = @owner_id)
+        AND (CAST(@currency AS TEXT) = '' OR currency = @currency)
+        AND (CAST(sqlc.narg(min_price) AS REAL) IS NULL OR price_estimate >= sqlc.narg(min_price))
+        AND (CAST(sqlc.narg(max_price) AS REAL) IS NULL OR price_estimate <= sqlc.narg(max_price))
+) i
+JOIN club_membership m ON m.club_id = i.club_id AND m.user_id = @user_id
+JOIN club c ON c.id = i.club_id
+JOIN user u ON u.id = i.owner_id
+ORDER BY sort_price IS NULL, sort_price, i.created_at DESC, i.id
+LIMIT @limit OFFSET @offset;
+
+-- name: GetImagesOfItems :many
+SELECT *
+FROM item_image
+WHERE item_id IN (sqlc.slice(item_ids))
+ORDER BY created_at, rowid;
+
+-- name: CreateSavedSearch :exec
+INSERT INTO saved_search (id, user_id, name, query, match_query, category, condition, owner_id, currency, min_price, max_price)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetSavedSearches :many
+SELECT *
+FROM saved_search
+WHERE user_id = ?
+ORDER BY created_at DESC, rowid DESC;
+
+-- name: CountSavedSearches :one
+SELECT COUNT(*)
+FROM saved_search
+WHERE user_id = ?;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_search
+WHERE id = ? AND user_id = ?;
+
+-- name: GetSavedSearchesMatchingItem :many
+-- saved searches of the item's club members other than its owner that the item matches
+SELECT s.id, s.user_id, s.name
+FROM saved_search s
+JOIN items i ON i.id = @item_id
+JOIN club_membership m ON m.club_id = i.club_id AND m.user_id = s.user_id
+WHERE s.user_id != i.owner_id
+    AND (s.match_query = '' OR i.id IN (SELECT item_id FROM item_search WHERE item_search MATCH s.match_query))
+    AND (s.category IS NULL OR s.category = i.category)
+    AND (s.condition IS NULL OR s.condition = i.condition)
+    AND (s.owner_id IS NULL OR s.owner_id = i.owner_id)
+    AND (s.currency IS NULL OR s.currency = i.currency)
+    AND (s.min_price IS NULL OR i.price_estimate >= s.min_price)
+    AND (s.max_price IS NULL OR i.price_estimate <= s.max_price)
+ORDER BY s.user_id, s.created_at;
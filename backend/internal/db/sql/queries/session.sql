@@ -0,0 +1,45 @@
+-- name: CreateUserSession :exec
+INSERT INTO user_session (id, user_id, refresh_token_hash, user_agent, ip_address, expires_at, impersonator_id)
+VALUES (@id, @user_id, @refresh_token_hash, @user_agent, @ip_address, @expires_at, @impersonator_id);
+
+-- name: GetUserSession :one
+SELECT * FROM user_session WHERE id = @id;
+
+-- name: GetActiveUserSessions :many
+SELECT id, user_agent, ip_address, last_seen_at, expires_at, impersonator_id, created_at
+FROM user_session
+WHERE user_id = @user_id AND revoked_at IS NULL AND expires_at > @now
+ORDER BY last_seen_at DESC;
+
+-- name: RotateUserSessionRefreshToken :execrows
+-- only succeeds if the presented refresh token is still the current one,
+-- so two concurrent refreshes with the same token cannot both win
+UPDATE user_session
+SET
+    refresh_token_hash = @new_refresh_token_hash,
+    user_agent = @user_agent,
+    ip_address = @ip_address,
+    expires_at = @expires_at,
+    last_seen_at = CURRENT_TIMESTAMP
+WHERE
+    id = @id AND refresh_token_hash = @refresh_token_hash AND revoked_at IS NULL;
+
+-- name: TouchUserSession :exec
+UPDATE user_session
+SET last_seen_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: RevokeUserSession :execrows
+UPDATE user_session
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = @id AND user_id = @user_id AND revoked_at IS NULL;
+
+-- name: RevokeAllUserSessions :exec
+UPDATE user_session
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = @user_id AND revoked_at IS NULL;
+
+-- name: DeleteExpiredUserSessions :exec
+-- revoked sessions are kept until they would have expired so the list stays auditable
+DELETE FROM user_session
+WHERE expires_at < @now;
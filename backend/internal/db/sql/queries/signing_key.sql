@@ -0,0 +1,21 @@
+-- name: CreateSigningKey :exec
+INSERT INTO jwt_signing_key (kid, algorithm, private_key, active_at, expires_at)
+VALUES (@kid, @algorithm, @private_key, @active_at, @expires_at);
+
+-- name: GetSigningKeys :many
+-- every key that may still have signed a valid token, newest first
+SELECT kid, algorithm, private_key, active_at, expires_at
+FROM jwt_signing_key
+WHERE expires_at > @now
+ORDER BY active_at DESC;
+
+-- name: GetLatestSigningKey :one
+SELECT kid, algorithm, private_key, active_at, expires_at
+FROM jwt_signing_key
+WHERE algorithm = @algorithm
+ORDER BY active_at DESC
+LIMIT 1;
+
+-- name: DeleteExpiredSigningKeys :exec
+DELETE FROM jwt_signing_key
+WHERE expires_at < @now;
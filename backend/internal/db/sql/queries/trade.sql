@@ -0,0 +1,101 @@
+-- name: TradeCreate :exec
+INSERT INTO trades (id, proposer_id, responder_id, countered_trade_id, club_id, proposer_points, responder_points)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: AddTradeItem :exec
+INSERT INTO trade_item (trade_id, item_id, owner_id)
+VALUES (?, ?, ?);
+
+-- name: GetTradeByID :one
+SELECT *
+FROM trades
+WHERE id = ?;
+
+-- name: GetTradeItems :many
+SELECT ti.item_id, ti.owner_id, i.name
+FROM trade_item ti
+JOIN items i ON i.id = ti.item_id
+WHERE ti.trade_id = ?
+ORDER BY i.name, ti.item_id;
+
+-- only pending trades can change, so two parties answering at once cannot both win
+-- name: UpdateTradeStatus :execrows
+UPDATE trades
+SET status = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = 'pending';
+
+-- name: TransferItemOwnership :exec
+-- the previous owner's reservation ends with the trade
+UPDATE items
+SET owner_id = ?, reserved_for = NULL, reserved_until = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- fails without changing anything if the user has too few points
+-- name: SpendClubPoints :execrows
+UPDATE club_membership
+SET user_points = user_points - @points
+WHERE user_id = @user_id AND club_id = @club_id AND user_points >= @points;
+
+-- name: AddClubPoints :execrows
+UPDATE club_membership
+SET user_points = user_points + @points
+WHERE user_id = @user_id AND club_id = @club_id;
+
+-- name: GetClubPoints :one
+SELECT user_points FROM club_membership WHERE user_id = ? AND club_id = ?;
+
+-- name: HasPendingTrade :one
+SELECT EXISTS(
+    SELECT 1 FROM trades
+    WHERE proposer_id = @proposer_id AND responder_id = @responder_id AND status = 'pending'
+);
+
+-- the other pending trades sharing an item with the trade
+-- name: GetPendingTradesSharingItems :many
+SELECT DISTINCT t.id
+FROM trades t
+JOIN trade_item ti ON ti.trade_id = t.id
+WHERE t.status = 'pending' AND t.id != @trade_id
+    AND ti.item_id IN (SELECT item_id FROM trade_item WHERE trade_id = @trade_id);
+
+-- name: GetIncomingTrades :many
+SELECT t.*, proposer.username AS proposer_username, responder.username AS responder_username
+FROM trades t
+JOIN user proposer ON proposer.id = t.proposer_id
+JOIN user responder ON responder.id = t.responder_id
+WHERE t.responder_id = @user_id AND (CAST(@status AS TEXT) = '' OR t.status = @status)
+ORDER BY t.created_at DESC, t.rowid DESC;
+
+-- name: GetOutgoingTrades :many
+SELECT t.*, proposer.username AS proposer_username, responder.username AS responder_username
+FROM trades t
+JOIN user proposer ON proposer.id = t.proposer_id
+JOIN user responder ON responder.id = t.responder_id
+WHERE t.proposer_id = @user_id AND (CAST(@status AS TEXT) = '' OR t.status = @status)
+ORDER BY t.created_at DESC, t.rowid DESC;
+
+-- name: CreateTradeReview :execrows
+-- a second review of the same trade by the same party is ignored
+INSERT INTO trade_review (trade_id, reviewer_id, reviewee_id, rating, comment)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (trade_id, reviewer_id) DO NOTHING;
+
+-- name: GetTradeReviews :many
+SELECT *
+FROM trade_review
+WHERE trade_id = ?
+ORDER BY created_at, rowid;
+
+-- name: GetUserReviews :many
+-- reviews the user received, the newest first
+SELECT r.trade_id, r.reviewer_id, u.username AS reviewer_username, r.rating, r.comment, r.created_at
+FROM trade_review r
+JOIN user u ON u.id = r.reviewer_id
+WHERE r.reviewee_id = @user_id
+ORDER BY r.created_at DESC, r.rowid DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: GetUserReputation :one
+SELECT COUNT(*) AS review_count, CAST(COALESCE(AVG(rating), 0) AS REAL) AS score
+FROM trade_review
+WHERE reviewee_id = ?;
@@ -12,8 +12,20 @@ SELECT id, email, username, password
 FROM user
 WHERE username = ?;
 
+-- name: GetUserIDByEmail :one
+SELECT id FROM user WHERE email = ?;
+
+-- name: GetUserEmail :one
+SELECT email, email_verified_at FROM user WHERE id = ?;
+
+-- name: SetUserEmailVerified :exec
+UPDATE user SET email_verified_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: ClearUserEmailVerified :exec
+UPDATE user SET email_verified_at = NULL WHERE id = ?;
+
 -- name: GetUserDisplay :one
-SELECT username, profile_picture, created_at
+SELECT username, display_name, profile_picture, created_at
 FROM user
 WHERE id = ?;
 
@@ -21,8 +33,8 @@ WHERE id = ?;
 DELETE FROM user WHERE id = ?;
 
 -- name: CreateItem :exec
-INSERT INTO items (id, name, description, is_available, owner_id)
-VALUES (?, ?, ?, ?, ?);
+INSERT INTO items (id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetItem :one
 SELECT *
@@ -40,14 +52,27 @@ FROM items
 WHERE id = ?;
 
 -- name: CreateItemForClub :exec
-INSERT INTO items (id, name, description, is_available, owner_id, club_id)
-VALUES (?, ?, ?, ?, ?, ?);
+INSERT INTO items (id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id, club_id, moderation_status, moderation_reason)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetItemsByClub :many
-SELECT id, name, description, is_available, owner_id, club_id, created_at, updated_at
-FROM items
-WHERE club_id = ? AND is_available = TRUE
-ORDER BY created_at DESC;
+-- items without a price estimate are left out by the price filters and listed last when sorting by price.
+-- reserved items are only listed for the user they are reserved for
+SELECT id, name, description, price_estimate, currency, condition, category, pickup_location, can_ship, is_available, owner_id, club_id, reserved_until, listing_type, created_at, updated_at
+FROM (
+    SELECT *,
+        CASE CAST(@sort AS TEXT)
+            WHEN 'price_asc' THEN price_estimate
+            WHEN 'price_desc' THEN -price_estimate
+        END AS sort_price
+    FROM items
+    WHERE club_id = @club_id AND is_available = TRUE AND moderation_status = 'approved'
+        AND (reserved_until IS NULL OR reserved_until <= @now OR reserved_for = CAST(@user_id AS TEXT))
+        AND (CAST(@currency AS TEXT) = '' OR currency = @currency)
+        AND (CAST(sqlc.narg(min_price) AS REAL) IS NULL OR price_estimate >= sqlc.narg(min_price))
+        AND (CAST(sqlc.narg(max_price) AS REAL) IS NULL OR price_estimate <= sqlc.narg(max_price))
+)
+ORDER BY sort_price IS NULL, sort_price, created_at DESC;
 
 
 
@@ -56,6 +81,13 @@ UPDATE items
 SET
     name = COALESCE(sqlc.narg(name), name),
     description = COALESCE(sqlc.narg(description), description),
+    price_estimate = COALESCE(sqlc.narg(price_estimate), price_estimate),
+    currency = COALESCE(sqlc.narg(currency), currency),
+    -- an empty string clears the condition, category or pickup location
+    condition = NULLIF(COALESCE(CAST(sqlc.narg(condition) AS TEXT), condition), ''),
+    category = NULLIF(COALESCE(CAST(sqlc.narg(category) AS TEXT), category), ''),
+    pickup_location = NULLIF(COALESCE(CAST(sqlc.narg(pickup_location) AS TEXT), pickup_location), ''),
+    can_ship = COALESCE(sqlc.narg(can_ship), can_ship),
     is_available = COALESCE(sqlc.narg(is_available), is_available),
     owner_id = COALESCE(sqlc.narg(owner_id), owner_id)
 WHERE
@@ -64,24 +96,51 @@ WHERE
 -- name: DeleteItem :exec
 DELETE FROM items WHERE id = ?;
 
--- name: TradeCreate :exec
-INSERT INTO trades (id, proposer_id, proposer_item_id, responder_id, responder_item_id, status, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+-- name: ReserveItem :exec
+UPDATE items
+SET reserved_for = @reserved_for, reserved_until = @reserved_until
+WHERE id = @id;
 
--- name: GetTradeByID :one
-SELECT *
-FROM trades
+-- name: ReleaseItemReservation :exec
+UPDATE items
+SET reserved_for = NULL, reserved_until = NULL
 WHERE id = ?;
 
--- name: UpdateTradeStatus :exec
-UPDATE trades
-SET status = ?, updated_at = CURRENT_TIMESTAMP
-WHERE id = ?;
+-- name: AddItemImage :exec
+INSERT INTO item_image (id, item_id, url, thumbnail_url)
+VALUES (?, ?, ?, ?);
 
--- name: TransferItemOwnership :exec
-UPDATE items
-SET owner_id = ?, updated_at = CURRENT_TIMESTAMP
-WHERE id = ?;
+-- name: GetItemImage :one
+SELECT *
+FROM item_image
+WHERE id = ? AND item_id = ?;
+
+-- name: GetItemImages :many
+SELECT *
+FROM item_image
+WHERE item_id = ?
+ORDER BY created_at, rowid;
+
+-- name: GetClubItemImages :many
+SELECT ii.*
+FROM item_image ii
+JOIN items i ON i.id = ii.item_id
+WHERE i.club_id = ?
+ORDER BY ii.created_at, ii.rowid;
+
+-- name: GetItemImagesByOwner :many
+SELECT ii.*
+FROM item_image ii
+JOIN items i ON i.id = ii.item_id
+WHERE i.owner_id = ?;
+
+-- name: CountItemImages :one
+SELECT COUNT(*)
+FROM item_image
+WHERE item_id = ?;
+
+-- name: DeleteItemImage :exec
+DELETE FROM item_image WHERE id = ?;
 
 -- name: CreateFriend :exec
 INSERT INTO user_friendship (user_id, friend_id)
@@ -0,0 +1,20 @@
+-- name: CreateUserToken :exec
+INSERT INTO user_token (token_hash, user_id, purpose, expires_at)
+VALUES (@token_hash, @user_id, @purpose, @expires_at);
+
+-- name: ConsumeUserToken :one
+-- marks the token used in the same statement that reads it so it can only be redeemed once
+UPDATE user_token
+SET used_at = CURRENT_TIMESTAMP
+WHERE token_hash = @token_hash AND purpose = @purpose AND used_at IS NULL
+RETURNING user_id, expires_at;
+
+-- name: InvalidateUserTokens :exec
+-- called before issuing a new token so only the latest mail works
+UPDATE user_token
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = @user_id AND purpose = @purpose AND used_at IS NULL;
+
+-- name: DeleteExpiredUserTokens :exec
+DELETE FROM user_token
+WHERE expires_at < @now;
@@ -0,0 +1,43 @@
+-- name: AddToWishlist :exec
+INSERT INTO wishlist (user_id, item_id)
+VALUES (?, ?)
+ON CONFLICT (user_id, item_id) DO NOTHING;
+
+-- name: RemoveFromWishlist :execrows
+DELETE FROM wishlist
+WHERE user_id = ? AND item_id = ?;
+
+-- name: CountWishlist :one
+SELECT COUNT(*)
+FROM wishlist
+WHERE user_id = ?;
+
+-- name: GetWishlist :many
+-- wishlisted items of the clubs the user is still a member of, the latest first.
+-- unavailable and reserved items are listed too so the user sees why they cannot trade for them
+SELECT
+    i.id, i.name, i.description, i.price_estimate, i.currency, i.condition, i.category,
+    i.pickup_location, i.can_ship, i.is_available, i.owner_id, u.username AS owner_username,
+    i.club_id, i.reserved_for, i.reserved_until, i.listing_type, w.created_at AS wishlisted_at
+FROM wishlist w
+JOIN items i ON i.id = w.item_id
+JOIN club_membership m ON m.club_id = i.club_id AND m.user_id = w.user_id
+JOIN user u ON u.id = i.owner_id
+WHERE w.user_id = @user_id AND i.moderation_status = 'approved'
+ORDER BY w.created_at DESC, w.rowid DESC;
+
+-- name: GetItemWishlisters :many
+SELECT u.id, u.username, w.created_at AS wishlisted_at
+FROM wishlist w
+JOIN user u ON u.id = w.user_id
+WHERE w.item_id = ?
+ORDER BY w.created_at, w.rowid;
+
+-- name: GetItemWishlisterIDs :many
+-- the members of the item's club who wishlisted it, an owner who got it through a trade is left out
+SELECT w.user_id
+FROM wishlist w
+JOIN items i ON i.id = w.item_id
+JOIN club_membership m ON m.club_id = i.club_id AND m.user_id = w.user_id
+WHERE w.item_id = ? AND w.user_id != i.owner_id
+ORDER BY w.user_id;
@@ -6,6 +6,208 @@ CREATE TABLE IF NOT EXISTS user (
     username TEXT NOT NULL UNIQUE,
     password TEXT NOT NULL,
     profile_picture TEXT,
+    email_verified_at DATETIME,
+    totp_secret TEXT, -- base32, set on enrollment
+    totp_enabled_at DATETIME, -- null until the first code is confirmed
+    totp_last_step INTEGER, -- last accepted time step, prevents replaying a code
+    deletion_scheduled_at DATETIME, -- set while a requested deletion waits out the cooling-off period
+    display_name TEXT,
+    bio TEXT,
+    location TEXT,
+    pronouns TEXT,
+    profile_visibility TEXT NOT NULL DEFAULT 'public', -- 'public', 'friends' or 'private'
+    show_clubs BOOLEAN NOT NULL DEFAULT TRUE, -- public clubs on the profile
+    show_stats BOOLEAN NOT NULL DEFAULT TRUE, -- points and streaks on the profile
+    show_items BOOLEAN NOT NULL DEFAULT TRUE, -- available marketplace items on the profile
+    role TEXT NOT NULL DEFAULT 'user', -- 'user' or 'admin', admins can moderate the whole site
+    disabled_at DATETIME, -- set by an admin, disabled accounts cannot log in
+    disabled_reason TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- links shown on the user's profile, in order
+CREATE TABLE IF NOT EXISTS user_link (
+    user_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    label TEXT NOT NULL,
+    url TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, position),
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_recovery_code (
+    user_id TEXT NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+-- single use tokens that are mailed to the user
+CREATE TABLE IF NOT EXISTS user_token (
+    token_hash TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    purpose TEXT NOT NULL, -- 'verify_email', 'reset_password' or 'delete_account'
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_session (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    refresh_token_hash TEXT NOT NULL, -- sha256 of the current refresh token, rotated on every refresh
+    user_agent TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    last_seen_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+    impersonator_id TEXT, -- admin acting as the user for support, such sessions are read-only
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+-- asymmetric keys JWTs are signed with, the public halves are published at /.well-known/jwks.json
+CREATE TABLE IF NOT EXISTS jwt_signing_key (
+    kid TEXT NOT NULL PRIMARY KEY,
+    algorithm TEXT NOT NULL, -- 'RS256' or 'EdDSA'
+    private_key TEXT NOT NULL, -- PKCS #8 PEM
+    active_at DATETIME NOT NULL, -- signs new tokens from here on, published before so verifiers can cache it
+    expires_at DATETIME NOT NULL, -- after the last token signed with it has expired
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- long lived tokens for scripts, limited to the scopes they were created with
+CREATE TABLE IF NOT EXISTS personal_access_token (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE, -- sha256 of the token, the token itself is only shown once
+    scopes TEXT NOT NULL, -- space separated, e.g. 'metrics:write clubs:read'
+    expires_at DATETIME NOT NULL,
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+-- audit trail of logins, second factor checks and registrations, also used to rate limit them
+CREATE TABLE IF NOT EXISTS login_attempt (
+    id TEXT NOT NULL PRIMARY KEY,
+    action TEXT NOT NULL, -- 'login', 'mfa' or 'register'
+    account TEXT NOT NULL, -- user id if the account exists, otherwise the lowercased identifier
+    identifier TEXT NOT NULL, -- username or email as typed
+    user_id TEXT,
+    ip_address TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    failure_reason TEXT,
+    attempted_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempt_account ON login_attempt(account, attempted_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempt_ip ON login_attempt(ip_address, attempted_at);
+
+-- every action taken through the admin API, rows can never be changed or deleted (see triggers.sql).
+-- admin and target ids are not foreign keys so entries outlive the accounts and content they mention
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id TEXT NOT NULL PRIMARY KEY,
+    admin_id TEXT NOT NULL,
+    action TEXT NOT NULL, -- e.g. 'disable_user', 'delete_club', 'impersonate_user'
+    target_type TEXT NOT NULL, -- 'user', 'club' or 'post'
+    target_id TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    report_id TEXT, -- set when the action resolved a report
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created_at ON admin_audit_log(created_at);
+
+-- a case about reported content, reports about the same target are merged while it is unresolved.
+-- targets in a club (posts and club items) go to the club's moderators, everything else to site admins
+CREATE TABLE IF NOT EXISTS report (
+    id TEXT NOT NULL PRIMARY KEY,
+    target_type TEXT NOT NULL, -- 'post', 'item', 'user' or 'club'
+    target_id TEXT NOT NULL, -- not a foreign key, the report stays when the content is deleted
+    club_id TEXT, -- club whose moderators handle the report, null for site admins
+    status TEXT NOT NULL DEFAULT 'open', -- 'open', 'triaged', 'actioned' or 'dismissed'
+    handled_by TEXT, -- moderator or admin who triaged or resolved it
+    resolution_action TEXT, -- moderation action taken, e.g. 'delete_post', null when dismissed
+    resolution_note TEXT,
+    resolution_action_id TEXT, -- the moderation_action the report was actioned with
+    resolved_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE SET NULL,
+    FOREIGN KEY (handled_by) REFERENCES user(id) ON DELETE SET NULL,
+    -- the report is claimed before the action is recorded, both in one transaction
+    FOREIGN KEY (resolution_action_id) REFERENCES moderation_action(id) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_unresolved_target ON report(target_type, target_id) WHERE status IN ('open', 'triaged');
+CREATE INDEX IF NOT EXISTS idx_report_club ON report(club_id, status);
+
+-- moderation actions taken to resolve reports, by club moderators or site admins. admin actions are in admin_audit_log too
+CREATE TABLE IF NOT EXISTS moderation_action (
+    id TEXT NOT NULL PRIMARY KEY,
+    moderator_id TEXT, -- null once the moderator deleted their account
+    action TEXT NOT NULL, -- e.g. 'delete_post'
+    target_type TEXT NOT NULL, -- 'post', 'item', 'user' or 'club'
+    target_id TEXT NOT NULL, -- not a foreign key, the content is usually gone
+    club_id TEXT,
+    as_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (moderator_id) REFERENCES user(id) ON DELETE SET NULL,
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE SET NULL
+);
+
+-- every user who reported the target of a report, with what they said
+CREATE TABLE IF NOT EXISTS report_submission (
+    report_id TEXT NOT NULL,
+    reporter_id TEXT NOT NULL,
+    category TEXT NOT NULL, -- 'spam', 'harassment', 'scam', 'inappropriate' or 'other'
+    comment TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (report_id, reporter_id),
+    FOREIGN KEY (report_id) REFERENCES report(id) ON DELETE CASCADE,
+    FOREIGN KEY (reporter_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+-- accounts at external OpenID Connect providers linked to a user
+CREATE TABLE IF NOT EXISTS user_identity (
+    provider TEXT NOT NULL, -- name of the provider in the server config
+    subject TEXT NOT NULL, -- "sub" claim, stable id of the account at the provider
+    user_id TEXT NOT NULL,
+    email TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, subject),
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+-- pending authorization code flows, consumed by the callback
+CREATE TABLE IF NOT EXISTS oidc_login_state (
+    state TEXT NOT NULL PRIMARY KEY,
+    provider TEXT NOT NULL,
+    code_verifier_hash TEXT NOT NULL, -- the app keeps the PKCE verifier and sends it with the callback
+    nonce TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
@@ -13,32 +215,165 @@ CREATE TABLE IF NOT EXISTS user (
 CREATE TABLE IF NOT EXISTS trades (
     id TEXT NOT NULL PRIMARY KEY,
     proposer_id TEXT NOT NULL,
-    proposer_item_id TEXT NOT NULL,
     responder_id TEXT NOT NULL,
-    responder_item_id TEXT NOT NULL,
-    status TEXT NOT NULL DEFAULT 'pending',
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, accepted, rejected, cancelled or countered
+    countered_trade_id TEXT, -- the trade this one answers with a counter-offer
+    club_id TEXT, -- the club whose points are paid, both parties must be members
+    proposer_points REAL NOT NULL DEFAULT 0.0, -- paid by the proposer on top of or instead of items
+    responder_points REAL NOT NULL DEFAULT 0.0,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 
     FOREIGN KEY (proposer_id) REFERENCES user(id) ON DELETE CASCADE,
     FOREIGN KEY (responder_id) REFERENCES user(id) ON DELETE CASCADE,
-    FOREIGN KEY (proposer_item_id) REFERENCES items(id) ON DELETE CASCADE,
-    FOREIGN KEY (responder_item_id) REFERENCES items(id) ON DELETE CASCADE
+    FOREIGN KEY (countered_trade_id) REFERENCES trades(id) ON DELETE SET NULL,
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_trades_proposer ON trades(proposer_id, status);
+CREATE INDEX IF NOT EXISTS idx_trades_responder ON trades(responder_id, status);
+
+-- the items each side of a trade gives, the owner tells the sides apart
+CREATE TABLE IF NOT EXISTS trade_item (
+    trade_id TEXT NOT NULL,
+    item_id TEXT NOT NULL,
+    owner_id TEXT NOT NULL, -- owner when the trade was proposed
+    PRIMARY KEY (trade_id, item_id),
+    FOREIGN KEY (trade_id) REFERENCES trades(id) ON DELETE CASCADE,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    FOREIGN KEY (owner_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_trade_item_item ON trade_item(item_id);
+
+-- each party of an accepted trade can rate the other once, the ratings make up their reputation
+CREATE TABLE IF NOT EXISTS trade_review (
+    trade_id TEXT NOT NULL,
+    reviewer_id TEXT NOT NULL,
+    reviewee_id TEXT NOT NULL,
+    rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (trade_id, reviewer_id),
+    FOREIGN KEY (trade_id) REFERENCES trades(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewer_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewee_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_trade_review_reviewee ON trade_review(reviewee_id);
+
+-- items users saved to hear when they become available again or their price changes
+CREATE TABLE IF NOT EXISTS wishlist (
+    user_id TEXT NOT NULL,
+    item_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, item_id),
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlist_item ON wishlist(item_id);
+
+-- an item sold to the highest bidder once ends_at passes, bids are in the auction's currency and paid outside the app
+CREATE TABLE IF NOT EXISTS auction (
+    id TEXT NOT NULL PRIMARY KEY,
+    item_id TEXT NOT NULL,
+    club_id TEXT NOT NULL,
+    seller_id TEXT NOT NULL,
+    start_price REAL NOT NULL,
+    reserve_price REAL, -- the lowest bid that wins, only the seller sees it
+    bid_increment REAL NOT NULL,
+    currency TEXT NOT NULL,
+    ends_at DATETIME NOT NULL, -- moved back by bids just before the end
+    status TEXT NOT NULL DEFAULT 'open', -- open, sold, unsold or cancelled
+    winner_id TEXT,
+    winning_bid REAL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE,
+    FOREIGN KEY (seller_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (winner_id) REFERENCES user(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_auction_club ON auction(club_id, status);
+CREATE INDEX IF NOT EXISTS idx_auction_status_ends ON auction(status, ends_at);
+
+CREATE TABLE IF NOT EXISTS auction_bid (
+    id TEXT NOT NULL PRIMARY KEY,
+    auction_id TEXT NOT NULL,
+    bidder_id TEXT NOT NULL,
+    amount REAL NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (auction_id) REFERENCES auction(id) ON DELETE CASCADE,
+    FOREIGN KEY (bidder_id) REFERENCES user(id) ON DELETE CASCADE
 );
 
+CREATE INDEX IF NOT EXISTS idx_auction_bid_auction ON auction_bid(auction_id, amount);
+
 CREATE TABLE IF NOT EXISTS items (
     id TEXT NOT NULL PRIMARY KEY,
     name TEXT NOT NULL,
     description TEXT,
+    price_estimate REAL, -- what the owner thinks the item is worth
+    currency TEXT, -- ISO 4217 code of the price estimate
+    condition TEXT, -- new, like_new, good, fair or poor
+    category TEXT,
+    pickup_location TEXT, -- where the item can be picked up
+    can_ship BOOLEAN NOT NULL DEFAULT FALSE, -- the owner is willing to ship the item
     is_available BOOLEAN NOT NULL DEFAULT TRUE,
     owner_id TEXT NOT NULL,
     club_id TEXT, 
+    moderation_status TEXT NOT NULL DEFAULT 'approved', -- approved or pending, pending items wait for the club's moderators
+    moderation_reason TEXT, -- why the content filter held it
+    reserved_for TEXT, -- the user the owner holds the item for, it is hidden from everyone else until reserved_until
+    reserved_until DATETIME,
+    listing_type TEXT NOT NULL DEFAULT 'trade', -- trade, auction or reward, items on auction or offered as a club reward cannot be traded or reserved
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (owner_id) REFERENCES user(id) ON DELETE CASCADE,
-    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE,
+    FOREIGN KEY (reserved_for) REFERENCES user(id) ON DELETE SET NULL
+);
+
+-- photos of an item, the files have to be deleted along with the rows
+CREATE TABLE IF NOT EXISTS item_image (
+    id TEXT NOT NULL PRIMARY KEY,
+    item_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    thumbnail_url TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE
 );
 
+CREATE INDEX IF NOT EXISTS idx_item_image_item ON item_image(item_id);
+
+-- full-text index over the name and description of items, kept in sync by triggers. it keeps its own copy of
+-- the text and the item id, the implicit rowid of items is not stable enough to point at them
+CREATE VIRTUAL TABLE IF NOT EXISTS item_search USING fts5(item_id UNINDEXED, name, description);
+
+-- a marketplace search the user is told about over the WebSocket whenever a new item matches it
+CREATE TABLE IF NOT EXISTS saved_search (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    query TEXT NOT NULL DEFAULT '', -- as the user typed it
+    match_query TEXT NOT NULL DEFAULT '', -- the query as an FTS5 expression, empty matches everything
+    category TEXT,
+    condition TEXT,
+    owner_id TEXT,
+    currency TEXT,
+    min_price REAL,
+    max_price REAL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (owner_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_user ON saved_search(user_id);
+
 
 CREATE TABLE IF NOT EXISTS user_friendship (
     user_id TEXT NOT NULL,
@@ -78,6 +413,7 @@ CREATE TABLE IF NOT EXISTS club (
     owner_user_id TEXT NOT NULL,
     banner_image TEXT,
     is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    min_listing_reputation REAL NOT NULL DEFAULT 0.0, -- reputation score members need to list items, 0 for none
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (owner_user_id) REFERENCES user(id) ON DELETE CASCADE
@@ -103,14 +439,86 @@ CREATE TABLE IF NOT EXISTS club_membership (
     FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE
 );
 
-CREATE TABLE IF NOT EXISTS club_post (
+-- every change to a member's points with the balance after it, rows are never changed (see triggers.sql).
+-- club_membership.user_points is the sum of the member's entries, kept up to date as they are added
+CREATE TABLE IF NOT EXISTS point_ledger (
     id TEXT NOT NULL PRIMARY KEY,
+    club_id TEXT NOT NULL,
     user_id TEXT NOT NULL,
+    amount REAL NOT NULL, -- negative when points were spent
+    balance REAL NOT NULL,
+    reason TEXT NOT NULL, -- metric_entry, streak_bonus, trade, redemption, refund, adjustment, left_club or opening_balance
+    reference_id TEXT, -- the metric instance, trade or redemption, not a foreign key so the entry outlives it
+    actor_id TEXT, -- the moderator who made an adjustment, not a foreign key for the same reason
+    note TEXT, -- why the moderator made the adjustment
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_point_ledger_member ON point_ledger(club_id, user_id, created_at);
+
+-- what a club's members can spend their points on, moderators hand the rewards out
+CREATE TABLE IF NOT EXISTS club_reward (
+    id TEXT NOT NULL PRIMARY KEY,
     club_id TEXT NOT NULL,
-    content TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    cost REAL NOT NULL,
+    stock INTEGER, -- how many are left, null for no limit
+    is_active BOOLEAN NOT NULL DEFAULT TRUE, -- inactive rewards cannot be redeemed and are only listed for moderators
+    item_id TEXT, -- the item handed to the member, not a foreign key so the redemptions outlive a deleted item
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_club_reward_club ON club_reward(club_id);
+
+CREATE TABLE IF NOT EXISTS reward_redemption (
+    id TEXT NOT NULL PRIMARY KEY,
+    reward_id TEXT NOT NULL,
+    club_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    cost REAL NOT NULL, -- what the member paid, the reward's cost may change later
+    note TEXT, -- from the member, e.g. which week to skip
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, fulfilled or rejected, rejected redemptions are refunded
+    handled_by TEXT, -- the moderator who fulfilled or rejected it
+    handled_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (reward_id) REFERENCES club_reward(id) ON DELETE CASCADE,
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE,
     FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (handled_by) REFERENCES user(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_reward_redemption_club ON reward_redemption(club_id, status, created_at);
+
+CREATE TABLE IF NOT EXISTS club_post (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT, -- null once the author deleted their account, the post stays for the club
+    club_id TEXT NOT NULL,
+    content TEXT NOT NULL,
+    moderation_status TEXT NOT NULL DEFAULT 'approved', -- approved or pending, pending posts are only shown to the author and moderators
+    moderation_reason TEXT, -- why the content filter held it
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE SET NULL,
+    FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_club_post_user ON club_post(user_id, created_at);
+
+-- words and phrases a club does not allow in its posts and items, on top of the site-wide lists
+CREATE TABLE IF NOT EXISTS club_blocked_term (
+    club_id TEXT NOT NULL,
+    term TEXT NOT NULL, -- lowercase
+    action TEXT NOT NULL, -- reject or hold
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (club_id, term),
     FOREIGN KEY (club_id) REFERENCES club(id) ON DELETE CASCADE
 );
 
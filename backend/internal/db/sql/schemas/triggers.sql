@@ -7,6 +7,96 @@ BEGIN
     UPDATE user SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
 END;
 
+CREATE TRIGGER IF NOT EXISTS update_user_link_updated_at
+AFTER UPDATE ON user_link
+FOR EACH ROW
+BEGIN
+    UPDATE user_link SET updated_at = CURRENT_TIMESTAMP WHERE user_id = OLD.user_id AND position = OLD.position;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_user_recovery_code_updated_at
+AFTER UPDATE ON user_recovery_code
+FOR EACH ROW
+BEGIN
+    UPDATE user_recovery_code SET updated_at = CURRENT_TIMESTAMP WHERE user_id = OLD.user_id AND code_hash = OLD.code_hash;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_user_token_updated_at
+AFTER UPDATE ON user_token
+FOR EACH ROW
+BEGIN
+    UPDATE user_token SET updated_at = CURRENT_TIMESTAMP WHERE token_hash = OLD.token_hash;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_user_session_updated_at
+AFTER UPDATE ON user_session
+FOR EACH ROW
+BEGIN
+    UPDATE user_session SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_jwt_signing_key_updated_at
+AFTER UPDATE ON jwt_signing_key
+FOR EACH ROW
+BEGIN
+    UPDATE jwt_signing_key SET updated_at = CURRENT_TIMESTAMP WHERE kid = OLD.kid;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_personal_access_token_updated_at
+AFTER UPDATE ON personal_access_token
+FOR EACH ROW
+BEGIN
+    UPDATE personal_access_token SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_login_attempt_updated_at
+AFTER UPDATE ON login_attempt
+FOR EACH ROW
+BEGIN
+    UPDATE login_attempt SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+-- the audit log is append-only, not even a compromised admin account can cover its tracks through the api
+CREATE TRIGGER IF NOT EXISTS prevent_admin_audit_log_update
+BEFORE UPDATE ON admin_audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'admin audit log entries cannot be changed');
+END;
+
+CREATE TRIGGER IF NOT EXISTS prevent_admin_audit_log_delete
+BEFORE DELETE ON admin_audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'admin audit log entries cannot be deleted');
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_report_updated_at
+AFTER UPDATE ON report
+FOR EACH ROW
+BEGIN
+    UPDATE report SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_report_submission_updated_at
+AFTER UPDATE ON report_submission
+FOR EACH ROW
+BEGIN
+    UPDATE report_submission SET updated_at = CURRENT_TIMESTAMP WHERE report_id = OLD.report_id AND reporter_id = OLD.reporter_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_user_identity_updated_at
+AFTER UPDATE ON user_identity
+FOR EACH ROW
+BEGIN
+    UPDATE user_identity SET updated_at = CURRENT_TIMESTAMP WHERE provider = OLD.provider AND subject = OLD.subject;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_oidc_login_state_updated_at
+AFTER UPDATE ON oidc_login_state
+FOR EACH ROW
+BEGIN
+    UPDATE oidc_login_state SET updated_at = CURRENT_TIMESTAMP WHERE state = OLD.state;
+END;
+
 CREATE TRIGGER IF NOT EXISTS update_user_friendship_updated_at
 AFTER UPDATE ON user_friendship
 FOR EACH ROW
@@ -49,6 +139,13 @@ BEGIN
     UPDATE club_post SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
 END;
 
+CREATE TRIGGER IF NOT EXISTS update_club_blocked_term_updated_at
+AFTER UPDATE ON club_blocked_term
+FOR EACH ROW
+BEGIN
+    UPDATE club_blocked_term SET updated_at = CURRENT_TIMESTAMP WHERE club_id = OLD.club_id AND term = OLD.term;
+END;
+
 CREATE TRIGGER IF NOT EXISTS update_club_post_attachment_updated_at
 AFTER UPDATE ON club_post_attachment
 FOR EACH ROW
@@ -90,3 +187,73 @@ FOR EACH ROW
 BEGIN
     UPDATE metric_entry_attachment SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
 END;
+
+-- a trade can no longer be accepted once one of its items is gone
+CREATE TRIGGER IF NOT EXISTS cancel_trades_on_item_delete
+BEFORE DELETE ON items
+FOR EACH ROW
+BEGIN
+    UPDATE trades SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP
+    WHERE status = 'pending' AND id IN (SELECT trade_id FROM trade_item WHERE item_id = OLD.id);
+END;
+
+-- keep the full-text index of items in sync
+CREATE TRIGGER IF NOT EXISTS item_search_insert
+AFTER INSERT ON items
+BEGIN
+    INSERT INTO item_search (item_id, name, description) VALUES (NEW.id, NEW.name, NEW.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS item_search_delete
+AFTER DELETE ON items
+BEGIN
+    DELETE FROM item_search WHERE item_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS item_search_update
+AFTER UPDATE OF name, description ON items
+BEGIN
+    UPDATE item_search SET name = NEW.name, description = NEW.description WHERE item_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_saved_search_updated_at
+AFTER UPDATE ON saved_search
+FOR EACH ROW
+BEGIN
+    UPDATE saved_search SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_trade_review_updated_at
+AFTER UPDATE ON trade_review
+FOR EACH ROW
+BEGIN
+    UPDATE trade_review SET updated_at = CURRENT_TIMESTAMP WHERE trade_id = OLD.trade_id AND reviewer_id = OLD.reviewer_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_auction_updated_at
+AFTER UPDATE ON auction
+FOR EACH ROW
+BEGIN
+    UPDATE auction SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+-- ledger entries are only removed with their club or the member's account
+CREATE TRIGGER IF NOT EXISTS prevent_point_ledger_update
+BEFORE UPDATE ON point_ledger
+BEGIN
+    SELECT RAISE(ABORT, 'point ledger entries cannot be changed');
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_club_reward_updated_at
+AFTER UPDATE ON club_reward
+FOR EACH ROW
+BEGIN
+    UPDATE club_reward SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_reward_redemption_updated_at
+AFTER UPDATE ON reward_redemption
+FOR EACH ROW
+BEGIN
+    UPDATE reward_redemption SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
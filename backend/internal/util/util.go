@@ -1,7 +1,30 @@
 package util
 
-import "github.com/google/uuid"
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+)
 
 func GenerateUUID() string {
 	return uuid.New().String()
 }
+
+// GenerateSecureToken returns a url safe random string built from n bytes of entropy
+func GenerateSecureToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken hashes high entropy tokens before they are stored.
+// Passwords must go through bcrypt instead.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
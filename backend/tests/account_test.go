@@ -0,0 +1,297 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestPost(t *testing.T, app *fiber.App, token string, clubID string, content string) string {
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/post", clubID), token, handlers.ClubPostRequest{TextContent: content})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	return decodeBody[handlers.CreatedResponse](t, resp).ID
+}
+
+func readZipFile(t *testing.T, archive *zip.Reader, name string) []byte {
+	f, err := archive.Open(name)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestAccountExport(t *testing.T) {
+	app := SetupTestApp()
+	token, err := CreateTestUser(app, "exportuser", "export@example.com", "Password123!@")
+	assert.NoError(t, err)
+	club, err := CreateTestClub(app, token, "Export Club", StringToPtr(""), true)
+	assert.NoError(t, err)
+	createTestPost(t, app, token, club.ID, "exported post")
+
+	resp := protectedJSON(t, app, "GET", "/api/user/export", token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, name := range []string{"profile", "links", "clubs", "posts", "metric_entries", "items", "messages"} {
+		readZipFile(t, archive, name+".json")
+		readZipFile(t, archive, name+".csv")
+	}
+
+	var profile []repository.GetUserExportProfileRow
+	assert.NoError(t, json.Unmarshal(readZipFile(t, archive, "profile.json"), &profile))
+	if assert.Len(t, profile, 1) {
+		assert.Equal(t, "exportuser", profile[0].Username)
+		assert.Equal(t, "export@example.com", profile[0].Email)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(readZipFile(t, archive, "posts.csv"))).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Contains(t, records[0], "content")
+		assert.Contains(t, records[1], "exported post")
+		assert.Contains(t, records[1], "Export Club")
+	}
+}
+
+func TestAccountDeletion(t *testing.T) {
+	mailer := &TestMailer{}
+	app, conn, querier, _ := SetupTestAppWithConn(mailer)
+	tx := services.NewTransactor(conn)
+	images := services.NewImageService(t.TempDir())
+	ctx := context.Background()
+	password := "Password123!@"
+
+	ownerToken, err := CreateTestUser(app, "leaving", "leaving@example.com", password)
+	assert.NoError(t, err)
+	memberToken, err := CreateTestUser(app, "staying", "staying@example.com", password)
+	assert.NoError(t, err)
+
+	sharedClub, err := CreateTestClub(app, ownerToken, "Shared Club", StringToPtr(""), true)
+	assert.NoError(t, err)
+	soloClub, err := CreateTestClub(app, ownerToken, "Solo Club", StringToPtr(""), true)
+	assert.NoError(t, err)
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", sharedClub.ID), memberToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	postID := createTestPost(t, app, ownerToken, sharedClub.ID, "hello from a leaving user")
+
+	t.Run("Wrong password is rejected", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/user/deletion", ownerToken, handlers.AccountDeletionRequest{Password: "wrong"})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "GET", "/api/user/deletion", ownerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Deletion can be cancelled during the cooling-off period", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/user/deletion", ownerToken, handlers.AccountDeletionRequest{Password: password})
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		scheduled := decodeBody[handlers.AccountDeletionResponse](t, resp)
+		assert.WithinDuration(t, time.Now().Add(config.AccountDeletionCoolingOff), scheduled.DeletionScheduledAt, time.Minute)
+
+		_, ok := mailer.LastTo("leaving@example.com")
+		assert.True(t, ok)
+
+		resp = protectedJSON(t, app, "POST", "/api/user/deletion", ownerToken, handlers.AccountDeletionRequest{Password: password})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "DELETE", "/api/user/deletion", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "DELETE", "/api/user/deletion", ownerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		// nothing happens once the period would have been over
+		assert.NoError(t, services.DeleteScheduledAccounts(ctx, querier, tx, images, time.Now().Add(config.AccountDeletionCoolingOff+time.Hour)))
+		getUserDisplay(t, app, ownerToken)
+	})
+
+	t.Run("Account is deleted after the cooling-off period", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/user/deletion", ownerToken, handlers.AccountDeletionRequest{Password: password})
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		// too early
+		assert.NoError(t, services.DeleteScheduledAccounts(ctx, querier, tx, images, time.Now()))
+		resp = protectedJSON(t, app, "GET", "/api/user/deletion", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		assert.NoError(t, services.DeleteScheduledAccounts(ctx, querier, tx, images, time.Now().Add(config.AccountDeletionCoolingOff+time.Minute)))
+
+		_, err := LoginUser(app, StringToPtr("leaving"), nil, password)
+		assert.Error(t, err)
+		resp = protectedJSON(t, app, "GET", "/api/user", ownerToken, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Owned clubs are handed over or deleted", func(t *testing.T) {
+		resp := protectedJSON(t, app, "GET", "/api/club/"+sharedClub.ID, memberToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		club := decodeBody[repository.Club](t, resp)
+		member, err := querier.GetUserIDByEmail(ctx, "staying@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, member, club.OwnerUserID)
+
+		_, err = querier.GetClub(ctx, soloClub.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("Posts stay without an author", func(t *testing.T) {
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/post/%s", sharedClub.ID, postID), memberToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		post := decodeBody[repository.GetClubPostRow](t, resp)
+		assert.Nil(t, post.UserID)
+		assert.Equal(t, "[deleted]", post.AuthorUsername)
+		assert.Equal(t, "hello from a leaving user", post.Content)
+	})
+}
+
+// failingDeletionTransactor fails deleting one user inside the transaction, after their clubs were handed over
+type failingDeletionTransactor struct {
+	services.Transactor
+	userID string
+}
+
+type failingDeletionQuerier struct {
+	repository.Querier
+	userID string
+}
+
+func (t failingDeletionTransactor) WithTx(ctx context.Context, fn func(q repository.Querier) error) error {
+	return t.Transactor.WithTx(ctx, func(q repository.Querier) error {
+		return fn(failingDeletionQuerier{Querier: q, userID: t.userID})
+	})
+}
+
+func (q failingDeletionQuerier) DeleteUser(ctx context.Context, id string) error {
+	if id == q.userID {
+		return errors.New("disk I/O error")
+	}
+	return q.Querier.DeleteUser(ctx, id)
+}
+
+func TestAccountDeletionFailure(t *testing.T) {
+	app, conn, querier, _ := SetupTestAppWithConn(&TestMailer{})
+	tx := services.NewTransactor(conn)
+	images := services.NewImageService(t.TempDir())
+	ctx := context.Background()
+	password := "Password123!@"
+
+	failingToken, err := CreateTestUser(app, "failing", "failing@example.com", password)
+	assert.NoError(t, err)
+	leavingToken, err := CreateTestUser(app, "leaving", "leaving@example.com", password)
+	assert.NoError(t, err)
+	memberToken, err := CreateTestUser(app, "member", "member@example.com", password)
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, failingToken, "Handed Over Club", StringToPtr(""), true)
+	assert.NoError(t, err)
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), memberToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	for _, token := range []string{failingToken, leavingToken} {
+		resp := protectedJSON(t, app, "POST", "/api/user/deletion", token, handlers.AccountDeletionRequest{Password: password})
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	}
+	failingID, err := querier.GetUserIDByEmail(ctx, "failing@example.com")
+	assert.NoError(t, err)
+	later := time.Now().Add(config.AccountDeletionCoolingOff + time.Minute)
+
+	t.Run("A failed account does not stop the others", func(t *testing.T) {
+		failing := failingDeletionTransactor{Transactor: tx, userID: failingID}
+		assert.NoError(t, services.DeleteScheduledAccounts(ctx, querier, failing, images, later))
+
+		_, err := querier.GetUserIDByEmail(ctx, "leaving@example.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("A failed account is left unchanged", func(t *testing.T) {
+		getUserDisplay(t, app, failingToken)
+		dbClub, err := querier.GetClub(ctx, club.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, failingID, dbClub.OwnerUserID)
+	})
+
+	t.Run("The next run deletes it", func(t *testing.T) {
+		assert.NoError(t, services.DeleteScheduledAccounts(ctx, querier, tx, images, later))
+
+		_, err := querier.GetUserIDByEmail(ctx, "failing@example.com")
+		assert.Error(t, err)
+		dbClub, err := querier.GetClub(ctx, club.ID)
+		assert.NoError(t, err)
+		assert.NotEqual(t, failingID, dbClub.OwnerUserID)
+	})
+}
+
+func TestAccountDeletionWithoutPassword(t *testing.T) {
+	mailer := &TestMailer{}
+	app, conn, _, _ := SetupTestAppWithConn(mailer)
+
+	token, err := CreateTestUser(app, "federated", "federated@example.com", "Password123!@")
+	assert.NoError(t, err)
+	otherToken, err := CreateTestUser(app, "other", "other@example.com", "Password123!@")
+	assert.NoError(t, err)
+	// accounts created through an identity provider are stored without a password
+	_, err = conn.Exec("UPDATE user SET password = '' WHERE username = 'federated'")
+	assert.NoError(t, err)
+
+	t.Run("An empty password is not accepted", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/user/deletion", token, handlers.AccountDeletionRequest{Password: ""})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Accounts with a password cannot confirm by email", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/user/deletion/email", otherToken, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("The token only works for its account", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/user/deletion/email", token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		confirmation := tokenFromEmail(t, mailer, "federated@example.com")
+
+		resp = protectedJSON(t, app, "POST", "/api/user/deletion/confirm", otherToken, handlers.ConfirmAccountDeletionRequest{Token: confirmation})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp = protectedJSON(t, app, "GET", "/api/user/deletion", otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("The emailed token schedules the deletion", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/user/deletion/email", token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		confirmation := tokenFromEmail(t, mailer, "federated@example.com")
+
+		resp = protectedJSON(t, app, "POST", "/api/user/deletion/confirm", token, handlers.ConfirmAccountDeletionRequest{Token: confirmation})
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		scheduled := decodeBody[handlers.AccountDeletionResponse](t, resp)
+		assert.WithinDuration(t, time.Now().Add(config.AccountDeletionCoolingOff), scheduled.DeletionScheduledAt, time.Minute)
+
+		resp = protectedJSON(t, app, "POST", "/api/user/deletion/confirm", token, handlers.ConfirmAccountDeletionRequest{Token: confirmation})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "the token is used up")
+		resp = protectedJSON(t, app, "POST", "/api/user/deletion/email", token, nil)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+}
@@ -0,0 +1,298 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// grantAdminRoles verifies the emails and promotes their accounts, as done at startup for ADMIN_EMAILS
+func grantAdminRoles(t *testing.T, querier repository.Querier, emails ...string) {
+	ctx := context.Background()
+	for _, email := range emails {
+		userID, err := querier.GetUserIDByEmail(ctx, email)
+		assert.NoError(t, err)
+		assert.NoError(t, querier.SetUserEmailVerified(ctx, userID))
+	}
+	assert.NoError(t, services.GrantAdminRoles(ctx, querier, emails))
+}
+
+func TestAdminEmailsRequireVerification(t *testing.T) {
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	ctx := context.Background()
+
+	// whoever registers a listed address first must not become admin without proving they own it
+	token, err := CreateTestUser(app, "squatter", "listedadmin@example.com", "Password123!@")
+	assert.NoError(t, err)
+	assert.NoError(t, services.GrantAdminRoles(ctx, querier, []string{"listedadmin@example.com"}))
+	assert.Equal(t, http.StatusForbidden, protectedJSON(t, app, "GET", "/api/admin/users", token, nil).StatusCode)
+
+	grantAdminRoles(t, querier, "listedadmin@example.com")
+	assert.Equal(t, http.StatusOK, protectedJSON(t, app, "GET", "/api/admin/users", token, nil).StatusCode)
+}
+
+func TestAdmin(t *testing.T) {
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	ctx := context.Background()
+	password := "Password123!@"
+	reason := handlers.AdminActionRequest{Reason: "support ticket 42"}
+
+	adminToken, err := CreateTestUser(app, "siteadmin", "admin@example.com", password)
+	assert.NoError(t, err)
+	otherAdminToken, err := CreateTestUser(app, "otheradmin", "otheradmin@example.com", password)
+	assert.NoError(t, err)
+	targetToken, err := CreateTestUser(app, "troublemaker", "trouble@example.com", password)
+	assert.NoError(t, err)
+	adminID, err := querier.GetUserIDByEmail(ctx, "admin@example.com")
+	assert.NoError(t, err)
+	otherAdminID, err := querier.GetUserIDByEmail(ctx, "otheradmin@example.com")
+	assert.NoError(t, err)
+	targetID, err := querier.GetUserIDByEmail(ctx, "trouble@example.com")
+	assert.NoError(t, err)
+
+	privateClub, err := CreateTestClub(app, targetToken, "Hidden Trouble", StringToPtr(""), false)
+	assert.NoError(t, err)
+	postID := createTestPost(t, app, targetToken, privateClub.ID, "abusive post")
+
+	t.Run("Regular users cannot use admin routes", func(t *testing.T) {
+		resp := protectedJSON(t, app, "GET", "/api/admin/users", targetToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		pat := createPersonalToken(t, app, targetToken, "script", "clubs:read")
+		resp = protectedJSON(t, app, "GET", "/api/admin/users", pat.Token, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	grantAdminRoles(t, querier, "admin@example.com", "otheradmin@example.com")
+
+	t.Run("Search users and clubs", func(t *testing.T) {
+		resp := protectedJSON(t, app, "GET", "/api/admin/users?q=TROUBLE", adminToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		users := decodeBody[[]repository.SearchUsersRow](t, resp)
+		if assert.Len(t, users, 1) {
+			assert.Equal(t, targetID, users[0].ID)
+			assert.Equal(t, services.RoleUser, users[0].Role)
+		}
+
+		resp = protectedJSON(t, app, "GET", "/api/admin/users?limit=2", adminToken, nil)
+		assert.Len(t, decodeBody[[]repository.SearchUsersRow](t, resp), 2)
+
+		// private clubs are found too
+		resp = protectedJSON(t, app, "GET", "/api/admin/clubs?q=hidden", adminToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		clubs := decodeBody[[]repository.SearchClubsRow](t, resp)
+		if assert.Len(t, clubs, 1) {
+			assert.Equal(t, "troublemaker", clubs[0].OwnerUsername)
+			assert.Equal(t, int64(1), clubs[0].MemberCount)
+		}
+	})
+
+	t.Run("Actions require a reason", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/admin/users/"+targetID+"/disable", adminToken, handlers.AdminActionRequest{Reason: " "})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Admins cannot act on themselves or other admins", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/admin/users/"+adminID+"/disable", adminToken, reason)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "POST", "/api/admin/users/"+otherAdminID+"/impersonate", adminToken, reason)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "PUT", "/api/admin/users/"+adminID+"/role", adminToken, handlers.SetUserRoleRequest{Role: services.RoleUser, Reason: reason.Reason})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Roles can be changed", func(t *testing.T) {
+		resp := protectedJSON(t, app, "PUT", "/api/admin/users/"+otherAdminID+"/role", adminToken, handlers.SetUserRoleRequest{Role: "superuser", Reason: reason.Reason})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "PUT", "/api/admin/users/"+otherAdminID+"/role", adminToken, handlers.SetUserRoleRequest{Role: services.RoleUser, Reason: reason.Reason})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "GET", "/api/admin/users", otherAdminToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Impersonation is read-only and visible to the user", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/admin/users/"+targetID+"/impersonate", adminToken, reason)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		impersonation := decodeBody[services.Impersonation](t, resp)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), impersonation.ExpiresAt, time.Minute)
+
+		assert.Equal(t, "troublemaker", getUserDisplay(t, app, impersonation.AccessToken).Username)
+
+		resp = protectedJSON(t, app, "POST", "/api/club", impersonation.AccessToken, services.CreateClubRequest{Name: "Made by support"})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		for _, path := range []string{"/api/user/export", "/api/user/sessions", "/api/user/tokens", "/api/user/identities", "/api/user/login-failures"} {
+			resp = protectedJSON(t, app, "GET", path, impersonation.AccessToken, nil)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode, path)
+		}
+		req, err := http.NewRequest("GET", "/ws/?token="+impersonation.AccessToken, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		resp, err = app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "GET", "/api/user/sessions", targetToken, nil)
+		sessions := decodeBody[[]services.UserSession](t, resp)
+		impersonated := 0
+		for _, session := range sessions {
+			if session.ImpersonatorID != nil {
+				impersonated++
+				assert.Equal(t, adminID, *session.ImpersonatorID)
+			}
+		}
+		assert.Equal(t, 1, impersonated)
+	})
+
+	t.Run("Disabled accounts are logged out and cannot log in", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/admin/users/"+targetID+"/disable", adminToken, reason)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "POST", "/api/admin/users/"+targetID+"/disable", adminToken, reason)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		assert.Equal(t, http.StatusUnauthorized, getUserStatus(t, app, targetToken))
+		assert.Equal(t, http.StatusForbidden, attemptLogin(t, app, "troublemaker", password).StatusCode)
+		// the password is still checked first so the status does not leak which accounts are disabled
+		assert.Equal(t, http.StatusUnauthorized, attemptLogin(t, app, "troublemaker", "WrongPassword1!@").StatusCode)
+
+		resp = protectedJSON(t, app, "POST", "/api/admin/users/"+targetID+"/enable", adminToken, reason)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, attemptLogin(t, app, "troublemaker", password).StatusCode)
+	})
+
+	t.Run("Posts and clubs can be force deleted", func(t *testing.T) {
+		resp := protectedJSON(t, app, "DELETE", "/api/admin/posts/"+postID, adminToken, reason)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "DELETE", "/api/admin/posts/"+postID, adminToken, reason)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "DELETE", "/api/admin/clubs/"+privateClub.ID, adminToken, reason)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_, err := querier.GetClub(ctx, privateClub.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("Every action is in the audit log", func(t *testing.T) {
+		resp := protectedJSON(t, app, "GET", "/api/admin/audit-log", adminToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		entries := decodeBody[[]repository.GetAdminAuditLogRow](t, resp)
+		actions := []string{}
+		for _, entry := range entries {
+			actions = append(actions, entry.Action)
+			assert.Equal(t, adminID, entry.AdminID)
+			assert.Equal(t, "siteadmin", entry.AdminUsername)
+			assert.Equal(t, reason.Reason, entry.Reason)
+		}
+		// newest first, rejected attempts are not logged
+		assert.Equal(t, []string{
+			services.AdminActionDeleteClub,
+			services.AdminActionDeletePost,
+			services.AdminActionEnableUser,
+			services.AdminActionDisableUser,
+			services.AdminActionImpersonateUser,
+			services.AdminActionSetRole,
+		}, actions)
+
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/admin/audit-log?target_id=%s", targetID), adminToken, nil)
+		assert.Len(t, decodeBody[[]repository.GetAdminAuditLogRow](t, resp), 3)
+	})
+}
+
+func TestAdminAuditLogIsAppendOnly(t *testing.T) {
+	ctx := context.Background()
+	conn, closer, err := db.New(ctx, ":memory:")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer closer()
+
+	err = repository.New(conn).CreateAdminAuditLogEntry(ctx, repository.CreateAdminAuditLogEntryParams{
+		ID:         "entry",
+		AdminID:    "admin",
+		Action:     services.AdminActionDisableUser,
+		TargetType: services.AdminTargetUser,
+		TargetID:   "user",
+		Reason:     "spam",
+		IpAddress:  "127.0.0.1",
+		CreatedAt:  time.Now(),
+	})
+	assert.NoError(t, err)
+
+	_, err = conn.ExecContext(ctx, "UPDATE admin_audit_log SET reason = 'nothing happened'")
+	assert.Error(t, err)
+	_, err = conn.ExecContext(ctx, "DELETE FROM admin_audit_log")
+	assert.Error(t, err)
+}
+
+// failingAuditTransactor fails writing the audit log entry, after the action's own changes
+type failingAuditTransactor struct {
+	services.Transactor
+}
+
+type failingAuditQuerier struct {
+	repository.Querier
+}
+
+func (t failingAuditTransactor) WithTx(ctx context.Context, fn func(q repository.Querier) error) error {
+	return t.Transactor.WithTx(ctx, func(q repository.Querier) error {
+		return fn(failingAuditQuerier{Querier: q})
+	})
+}
+
+func (failingAuditQuerier) CreateAdminAuditLogEntry(context.Context, repository.CreateAdminAuditLogEntryParams) error {
+	return errors.New("disk I/O error")
+}
+
+func TestAdminActionsRollBackWithoutAudit(t *testing.T) {
+	app, conn, querier, _ := SetupTestAppWithConn(&TestMailer{})
+	ctx := context.Background()
+	adminService := services.NewAdminService(querier, nil, services.NewImageService(t.TempDir()), failingAuditTransactor{services.NewTransactor(conn)})
+
+	targetToken, err := CreateTestUser(app, "audited", "audited@example.com", "Password123!@")
+	assert.NoError(t, err)
+	targetID, err := querier.GetUserIDByEmail(ctx, "audited@example.com")
+	assert.NoError(t, err)
+	club, err := CreateTestClub(app, targetToken, "Audited Club", StringToPtr(""), true)
+	assert.NoError(t, err)
+	postID := createTestPost(t, app, targetToken, club.ID, "audited post")
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", club.ID), targetToken, services.CreateItemRequest{Name: "Audited item"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	itemID := decodeBody[handlers.CreatedResponse](t, resp).ID
+	req := services.AdminRequest{AdminID: "some-admin", Reason: "support ticket 7"}
+
+	t.Run("Disabling", func(t *testing.T) {
+		assert.Error(t, adminService.DisableUser(ctx, req, targetID))
+		getUserDisplay(t, app, targetToken)
+	})
+
+	t.Run("Changing the role", func(t *testing.T) {
+		assert.Error(t, adminService.SetUserRole(ctx, req, targetID, services.RoleAdmin))
+		role, err := querier.GetUserRole(ctx, targetID)
+		assert.NoError(t, err)
+		assert.Equal(t, services.RoleUser, role)
+	})
+
+	t.Run("Deleting content", func(t *testing.T) {
+		assert.Error(t, adminService.DeleteClubPost(ctx, req, postID))
+		assert.Error(t, adminService.DeleteItem(ctx, req, itemID))
+		assert.Error(t, adminService.DeleteClub(ctx, req, club.ID))
+
+		_, err := querier.GetClubPost(ctx, postID)
+		assert.NoError(t, err)
+		_, err = querier.GetItem(ctx, itemID)
+		assert.NoError(t, err)
+		_, err = querier.GetClub(ctx, club.ID)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,348 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuctions(t *testing.T) {
+	ctx := context.Background()
+	app, conn, querier, wsService := SetupTestAppWithConn(&TestMailer{})
+	password := "Password123!@"
+
+	aliceToken, err := CreateTestUser(app, "alice", "alice@example.com", password)
+	assert.NoError(t, err)
+	bobToken, err := CreateTestUser(app, "bob", "bob@example.com", password)
+	assert.NoError(t, err)
+	carolToken, err := CreateTestUser(app, "carol", "carol@example.com", password)
+	assert.NoError(t, err)
+	outsiderToken, err := CreateTestUser(app, "outsider", "outsider@example.com", password)
+	assert.NoError(t, err)
+	bobID, err := querier.GetUserIDByEmail(ctx, "bob@example.com")
+	assert.NoError(t, err)
+	carolID, err := querier.GetUserIDByEmail(ctx, "carol@example.com")
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, aliceToken, "Auction Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	for _, token := range []string{bobToken, carolToken} {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	lamp := createTestClubItem(t, app, aliceToken, club.ID, "Lamp")
+	chair := createTestClubItem(t, app, aliceToken, club.ID, "Chair")
+	vase := createTestClubItem(t, app, aliceToken, club.ID, "Vase")
+	guitar := createTestClubItem(t, app, bobToken, club.ID, "Guitar")
+
+	createAuction := func(token string, itemID string, req services.AuctionRequest) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/item/%s/auction", itemID), token, req)
+	}
+	bid := func(token string, auctionID string, amount float64) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/auctions/%s/bids", auctionID), token, handlers.PlaceBidRequest{Amount: amount})
+	}
+	getAuction := func(token string, auctionID string) services.AuctionDetails {
+		resp := protectedJSON(t, app, "GET", "/api/marketplace/auctions/"+auctionID, token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeBody[services.AuctionDetails](t, resp)
+	}
+	inTwoHours := time.Now().Add(2 * time.Hour)
+
+	t.Run("Invalid auctions", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			token    string
+			itemID   string
+			req      services.AuctionRequest
+			expected int
+		}{
+			{name: "Not the owner", token: bobToken, itemID: lamp, req: services.AuctionRequest{StartPrice: 10, EndsAt: inTwoHours}, expected: http.StatusForbidden},
+			{name: "Unknown item", token: aliceToken, itemID: "missing", req: services.AuctionRequest{StartPrice: 10, EndsAt: inTwoHours}, expected: http.StatusNotFound},
+			{name: "Negative start price", token: aliceToken, itemID: lamp, req: services.AuctionRequest{StartPrice: -1, EndsAt: inTwoHours}, expected: http.StatusBadRequest},
+			{
+				name:     "Reserve below the start price",
+				token:    aliceToken,
+				itemID:   lamp,
+				req:      services.AuctionRequest{StartPrice: 10, ReservePrice: FloatToPtr(5), EndsAt: inTwoHours},
+				expected: http.StatusBadRequest,
+			},
+			{name: "Increment too small", token: aliceToken, itemID: lamp, req: services.AuctionRequest{StartPrice: 10, BidIncrement: 0.001, EndsAt: inTwoHours}, expected: http.StatusBadRequest},
+			{name: "Invalid currency", token: aliceToken, itemID: lamp, req: services.AuctionRequest{StartPrice: 10, Currency: StringToPtr("EU"), EndsAt: inTwoHours}, expected: http.StatusBadRequest},
+			{name: "Ends too soon", token: aliceToken, itemID: lamp, req: services.AuctionRequest{StartPrice: 10, EndsAt: time.Now().Add(30 * time.Minute)}, expected: http.StatusBadRequest},
+			{name: "Ends too late", token: aliceToken, itemID: lamp, req: services.AuctionRequest{StartPrice: 10, EndsAt: time.Now().Add(31 * 24 * time.Hour)}, expected: http.StatusBadRequest},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.expected, createAuction(tc.token, tc.itemID, tc.req).StatusCode)
+			})
+		}
+	})
+
+	var lampAuction string
+	t.Run("Bidding", func(t *testing.T) {
+		resp := createAuction(aliceToken, lamp, services.AuctionRequest{
+			StartPrice:   10,
+			ReservePrice: FloatToPtr(25),
+			BidIncrement: 5,
+			EndsAt:       inTwoHours,
+		})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		lampAuction = decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		// items on auction cannot be auctioned twice, traded or reserved
+		resp = createAuction(aliceToken, lamp, services.AuctionRequest{StartPrice: 10, EndsAt: inTwoHours})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		resp = protectedJSON(t, app, "POST", "/api/marketplace/trades", bobToken, services.TradeRequest{
+			OfferedItemIDs:   []string{guitar},
+			RequestedItemIDs: []string{lamp},
+		})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		resp = protectedJSON(t, app, "PUT", fmt.Sprintf("/api/marketplace/item/%s/reservation", lamp), aliceToken, handlers.ReserveItemRequest{UserID: bobID, ExpiresInHours: 1})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/items", club.ID), bobToken, nil)
+		for _, item := range decodeBody[[]services.ClubMarketplaceItem](t, resp) {
+			if item.ID == lamp {
+				assert.Equal(t, services.ListingAuction, item.ListingType)
+			} else {
+				assert.Equal(t, services.ListingTrade, item.ListingType, item.Name)
+			}
+		}
+
+		assert.Equal(t, http.StatusNotFound, protectedJSON(t, app, "GET", "/api/marketplace/auctions/"+lampAuction, outsiderToken, nil).StatusCode)
+		assert.Equal(t, http.StatusNotFound, bid(outsiderToken, lampAuction, 10).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, bid(aliceToken, lampAuction, 10).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, bid(bobToken, lampAuction, 9).StatusCode)
+
+		resp = bid(bobToken, lampAuction, 10)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		auction := decodeBody[services.AuctionDetails](t, resp)
+		assert.Equal(t, FloatToPtr(10), auction.HighestBid)
+		assert.Equal(t, 15.0, auction.MinimumBid)
+		assert.Equal(t, 1, auction.BidCount)
+		assert.Nil(t, auction.ReservePrice)
+		assert.True(t, auction.HasReserve)
+		assert.False(t, auction.ReserveMet)
+		assert.Equal(t, "USD", auction.Currency)
+		assert.Equal(t, "alice", auction.SellerUsername)
+
+		assert.Equal(t, http.StatusConflict, bid(bobToken, lampAuction, 20).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, bid(carolToken, lampAuction, 14).StatusCode)
+		assert.Equal(t, http.StatusOK, bid(carolToken, lampAuction, 15).StatusCode)
+
+		auction = getAuction(aliceToken, lampAuction)
+		assert.Equal(t, FloatToPtr(25), auction.ReservePrice)
+		if assert.Len(t, auction.Bids, 2) {
+			assert.Equal(t, carolID, auction.Bids[0].BidderID)
+			assert.Equal(t, "carol", auction.Bids[0].BidderUsername)
+			assert.Equal(t, 15.0, auction.Bids[0].Amount)
+			assert.Equal(t, bobID, auction.Bids[1].BidderID)
+		}
+
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/auctions", club.ID), carolToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		auctions := decodeBody[[]services.AuctionDetails](t, resp)
+		if assert.Len(t, auctions, 1) {
+			assert.Equal(t, lampAuction, auctions[0].ID)
+			assert.Equal(t, 2, auctions[0].BidCount)
+			assert.Empty(t, auctions[0].Bids)
+		}
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/auctions", club.ID), outsiderToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/auctions/%s/cancel", lampAuction), bobToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/auctions/%s/cancel", lampAuction), aliceToken, nil)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("Members hear about bids over the WebSocket", func(t *testing.T) {
+		testServerAddr := ":1115"
+		go func() {
+			err := app.Listen(testServerAddr)
+			assert.NoError(t, err)
+		}()
+		defer app.Shutdown()
+
+		headers := http.Header{}
+		headers.Set("Authorization", carolToken)
+		var conn *websocket.Conn
+		// the server may still be starting
+		for range 50 {
+			conn, _, err = websocket.DefaultDialer.Dial(fmt.Sprintf("ws://localhost%s/ws", testServerAddr), headers)
+			if err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		subscribe(t, conn, services.ClubAuctionsTopic(club.ID))
+
+		assert.Equal(t, http.StatusOK, bid(bobToken, lampAuction, 20).StatusCode)
+
+		var message struct {
+			Event   string                  `json:"event"`
+			Payload services.AuctionDetails `json:"payload"`
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for message.Event != "auction_bid" {
+			_, data, err := conn.ReadMessage()
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, json.Unmarshal(data, &message))
+		}
+		assert.Equal(t, lampAuction, message.Payload.ID)
+		assert.Equal(t, FloatToPtr(20), message.Payload.HighestBid)
+		assert.Equal(t, 25.0, message.Payload.MinimumBid)
+		assert.Nil(t, message.Payload.ReservePrice)
+	})
+
+	t.Run("Bids just before the end extend the auction", func(t *testing.T) {
+		err := querier.ExtendAuction(ctx, repository.ExtendAuctionParams{
+			EndsAt: time.Now().Add(time.Minute),
+			ID:     lampAuction,
+		})
+		assert.NoError(t, err)
+
+		resp := bid(carolToken, lampAuction, 25)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		auction := decodeBody[services.AuctionDetails](t, resp)
+		assert.True(t, auction.EndsAt.After(time.Now().Add(4*time.Minute)))
+		assert.True(t, auction.ReserveMet)
+	})
+
+	t.Run("Settlement", func(t *testing.T) {
+		resp := createAuction(aliceToken, chair, services.AuctionRequest{StartPrice: 5, ReservePrice: FloatToPtr(50), Currency: StringToPtr("eur"), EndsAt: inTwoHours})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		chairAuction := decodeBody[handlers.CreatedResponse](t, resp).ID
+		resp = createAuction(aliceToken, vase, services.AuctionRequest{StartPrice: 5, EndsAt: inTwoHours})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		vaseAuction := decodeBody[handlers.CreatedResponse](t, resp).ID
+		assert.Equal(t, http.StatusOK, bid(bobToken, chairAuction, 30).StatusCode)
+		assert.Equal(t, "EUR", getAuction(bobToken, chairAuction).Currency)
+
+		// nothing has ended yet
+		assert.NoError(t, services.SettleAuctions(ctx, querier, services.NewTransactor(conn), wsService, time.Now()))
+		assert.Equal(t, services.AuctionOpen, getAuction(bobToken, lampAuction).Status)
+
+		later := time.Now().Add(3 * time.Hour)
+		assert.NoError(t, services.SettleAuctions(ctx, querier, services.NewTransactor(conn), wsService, later))
+
+		auction := getAuction(bobToken, lampAuction)
+		assert.Equal(t, services.AuctionSold, auction.Status)
+		assert.Equal(t, &carolID, auction.WinnerID)
+		assert.Equal(t, FloatToPtr(25), auction.WinningBid)
+		item, err := querier.GetItem(ctx, lamp)
+		assert.NoError(t, err)
+		assert.Equal(t, carolID, item.OwnerID)
+		assert.Equal(t, services.ListingTrade, item.ListingType)
+
+		// the highest bid did not reach the reserve price
+		auction = getAuction(bobToken, chairAuction)
+		assert.Equal(t, services.AuctionUnsold, auction.Status)
+		assert.Nil(t, auction.WinnerID)
+		item, err = querier.GetItem(ctx, chair)
+		assert.NoError(t, err)
+		assert.NotEqual(t, bobID, item.OwnerID)
+		assert.Equal(t, services.ListingTrade, item.ListingType)
+
+		assert.Equal(t, services.AuctionUnsold, getAuction(bobToken, vaseAuction).Status)
+		assert.Equal(t, http.StatusConflict, bid(bobToken, vaseAuction, 5).StatusCode)
+
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/auctions", club.ID), carolToken, nil)
+		assert.Empty(t, decodeBody[[]services.AuctionDetails](t, resp))
+
+		// settling again changes nothing
+		assert.NoError(t, services.SettleAuctions(ctx, querier, services.NewTransactor(conn), wsService, later))
+	})
+
+	t.Run("Cancel an auction nobody bid on", func(t *testing.T) {
+		resp := createAuction(bobToken, guitar, services.AuctionRequest{StartPrice: 50, EndsAt: inTwoHours})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		guitarAuction := decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/auctions/%s/cancel", guitarAuction), bobToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/auctions/%s/cancel", guitarAuction), bobToken, nil)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		assert.Equal(t, services.AuctionCancelled, getAuction(carolToken, guitarAuction).Status)
+		item, err := querier.GetItem(ctx, guitar)
+		assert.NoError(t, err)
+		assert.Equal(t, services.ListingTrade, item.ListingType)
+	})
+
+	t.Run("A failed auction does not stop the others", func(t *testing.T) {
+		clock := createTestClubItem(t, app, aliceToken, club.ID, "Clock")
+		rug := createTestClubItem(t, app, aliceToken, club.ID, "Rug")
+		var auctionIDs []string
+		for _, itemID := range []string{clock, rug} {
+			resp := createAuction(aliceToken, itemID, services.AuctionRequest{StartPrice: 5, EndsAt: inTwoHours})
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			auctionID := decodeBody[handlers.CreatedResponse](t, resp).ID
+			assert.Equal(t, http.StatusOK, bid(bobToken, auctionID, 5).StatusCode)
+			auctionIDs = append(auctionIDs, auctionID)
+		}
+		clockAuction, rugAuction := auctionIDs[0], auctionIDs[1]
+
+		later := time.Now().Add(3 * time.Hour)
+		failing := failingSettlementTransactor{Transactor: services.NewTransactor(conn), auctionID: clockAuction}
+		assert.NoError(t, services.SettleAuctions(ctx, querier, failing, wsService, later))
+		assert.Equal(t, services.AuctionOpen, getAuction(bobToken, clockAuction).Status)
+		assert.Equal(t, services.AuctionSold, getAuction(bobToken, rugAuction).Status)
+		item, err := querier.GetItem(ctx, clock)
+		assert.NoError(t, err)
+		assert.NotEqual(t, bobID, item.OwnerID)
+
+		// the next run settles it
+		assert.NoError(t, services.SettleAuctions(ctx, querier, services.NewTransactor(conn), wsService, later))
+		assert.Equal(t, services.AuctionSold, getAuction(bobToken, clockAuction).Status)
+		item, err = querier.GetItem(ctx, clock)
+		assert.NoError(t, err)
+		assert.Equal(t, bobID, item.OwnerID)
+	})
+}
+
+// failingSettlementTransactor fails handing over the item of one auction, after the auction was closed
+type failingSettlementTransactor struct {
+	services.Transactor
+	auctionID string
+}
+
+type failingSettlementQuerier struct {
+	repository.Querier
+	itemID string
+}
+
+func (t failingSettlementTransactor) WithTx(ctx context.Context, fn func(q repository.Querier) error) error {
+	return t.Transactor.WithTx(ctx, func(q repository.Querier) error {
+		auction, err := q.GetAuction(ctx, t.auctionID)
+		if err != nil {
+			return err
+		}
+		return fn(failingSettlementQuerier{Querier: q, itemID: auction.ItemID})
+	})
+}
+
+func (q failingSettlementQuerier) TransferItemOwnership(ctx context.Context, arg repository.TransferItemOwnershipParams) error {
+	if arg.ID == q.itemID {
+		return errors.New("disk I/O error")
+	}
+	return q.Querier.TransferItemOwnership(ctx, arg)
+}
@@ -3,10 +3,12 @@ package tests
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rhellwege/task-social/config"
@@ -31,25 +33,99 @@ func StringToPtr(s string) *string {
 	return &s
 }
 
+func BoolToPtr(b bool) *bool {
+	return &b
+}
+
+func FloatToPtr(f float64) *float64 {
+	return &f
+}
+
+func IntToPtr(i int64) *int64 {
+	return &i
+}
+
+// TestMailer keeps every sent email in memory so tests can read tokens out of them
+type TestMailer struct {
+	mu   sync.Mutex
+	sent []services.Email
+}
+
+func (m *TestMailer) Send(ctx context.Context, email services.Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, email)
+	return nil
+}
+
+// LastTo returns the most recent email sent to the given address
+func (m *TestMailer) LastTo(to string) (services.Email, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if m.sent[i].To == to {
+			return m.sent[i], true
+		}
+	}
+	return services.Email{}, false
+}
+
 // SetupTestApp creates a new app instance with an in-memory database
 func SetupTestApp() *fiber.App {
+	return SetupTestAppWithMailer(&TestMailer{})
+}
+
+func SetupTestAppWithMailer(mailer services.Mailer) *fiber.App {
+	app, _ := SetupTestAppWithQuerier(mailer)
+	return app
+}
+
+// SetupTestAppWithQuerier also returns the database for tests that run scheduled jobs
+func SetupTestAppWithQuerier(mailer services.Mailer) (*fiber.App, repository.Querier) {
+	app, _, querier, _ := SetupTestAppWithConn(mailer)
+	return app, querier
+}
+
+// SetupTestAppWithConn also returns the connection and the WebSocket service for scheduled jobs that use them
+func SetupTestAppWithConn(mailer services.Mailer) (*fiber.App, *sql.DB, repository.Querier, services.WebSocketServicer) {
+	return setupTestAppWithDatabase(mailer, ":memory:")
+}
+
+// setupTestAppWithDatabase is for tests that make requests in parallel, each connection to an in-memory
+// database gets its own empty database, so they need a file
+func setupTestAppWithDatabase(mailer services.Mailer, uri string) (*fiber.App, *sql.DB, repository.Querier, services.WebSocketServicer) {
 	// Set JWT secret for tests
 	config.JWTSecret = []byte("test-secret-key-for-testing")
+	// most tests do not care about email verification
+	config.RequireVerifiedEmail = false
+	// tests log in and register many times from the same address
+	config.LoginRateLimiting = false
 
 	ctx := context.Background()
-	conn, _, err := db.New(ctx, ":memory:")
+	conn, _, err := db.New(ctx, uri)
 	if err != nil {
 		panic(err)
 	}
 
-	app := fiber.New()
+	app := fiber.New(routes.AppConfig())
 	querier := repository.New(conn)
-	routes.SetupServicesAndRoutes(app, querier)
-	return app
+	wsService := services.NewWebSocketService(services.DisconnectSlowClients)
+	routes.SetupServicesAndRoutes(app, conn, querier, mailer, wsService)
+	return app, conn, querier, wsService
 }
 
 // CreateTestUser creates a test user and returns the token
 func CreateTestUser(app *fiber.App, username, email, password string) (string, error) {
+	response, err := CreateTestUserSession(app, username, email, password)
+	if err != nil {
+		return "", err
+	}
+	return response.Token, nil
+}
+
+// CreateTestUserSession creates a test user and returns both the access and refresh token
+func CreateTestUserSession(app *fiber.App, username, email, password string) (handlers.SuccessfulLoginResponse, error) {
+	var response handlers.SuccessfulLoginResponse
 	reqBody := handlers.RegisterUserRequest{
 		Username: username,
 		Email:    email,
@@ -58,26 +134,35 @@ func CreateTestUser(app *fiber.App, username, email, password string) (string, e
 	jsonBody, _ := json.Marshal(reqBody)
 	req, err := http.NewRequest("POST", "/api/register", bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return "", err
+		return response, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := app.Test(req)
 	if err != nil {
-		return "", err
+		return response, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return response, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
-	var response handlers.SuccessfulLoginResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", err
+		return response, err
 	}
-	return response.Token, nil
+	return response, nil
 }
 
 // LoginUser logs in a user and returns the token
 func LoginUser(app *fiber.App, username *string, email *string, password string) (string, error) {
+	loginBody, err := LoginUserSession(app, username, email, password)
+	if err != nil {
+		return "", err
+	}
+	return loginBody.Token, nil
+}
+
+// LoginUserSession logs in a user and returns both the access and refresh token
+func LoginUserSession(app *fiber.App, username *string, email *string, password string) (handlers.SuccessfulLoginResponse, error) {
+	var loginBody handlers.SuccessfulLoginResponse
 	reqBody := handlers.LoginUserRequest{
 		Email:    email,
 		Username: username,
@@ -88,23 +173,22 @@ func LoginUser(app *fiber.App, username *string, email *string, password string)
 	req, err := http.NewRequest("POST", "/api/login", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		fmt.Println("Error creating login request:", err)
-		return "", err
+		return loginBody, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := app.Test(req)
 	if err != nil {
-		return "", err
+		return loginBody, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("login failed with status %d", resp.StatusCode)
+		return loginBody, fmt.Errorf("login failed with status %d", resp.StatusCode)
 	}
 
-	var loginBody handlers.SuccessfulLoginResponse
 	respBody, _ := io.ReadAll(resp.Body)
 	json.Unmarshal(respBody, &loginBody)
-	return loginBody.Token, nil
+	return loginBody, nil
 }
 
 func CreateTestClub(app *fiber.App, token, name string, description *string, isPublic bool) (*handlers.CreatedResponse, error) {
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+var emailTokenRegex = regexp.MustCompile(`token=([^\s]+)`)
+
+// extracts the token from the link in a mailed body
+func tokenFromEmail(t *testing.T, mailer *TestMailer, to string) string {
+	email, ok := mailer.LastTo(to)
+	assert.True(t, ok, "expected an email to %s", to)
+	match := emailTokenRegex.FindStringSubmatch(email.Body)
+	assert.Len(t, match, 2, "expected a token link in the email body")
+	if len(match) != 2 {
+		return ""
+	}
+	token, err := url.QueryUnescape(match[1])
+	assert.NoError(t, err)
+	return token
+}
+
+func postJSON(t *testing.T, app *fiber.App, path string, body any) *http.Response {
+	jsonBody, err := json.Marshal(body)
+	assert.NoError(t, err)
+	req, err := http.NewRequest("POST", path, bytes.NewBuffer(jsonBody))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	return resp
+}
+
+func TestEmailVerification(t *testing.T) {
+	mailer := &TestMailer{}
+	app := SetupTestAppWithMailer(mailer)
+	config.RequireVerifiedEmail = true
+	t.Cleanup(func() { config.RequireVerifiedEmail = false })
+
+	token, err := CreateTestUser(app, "verifyuser", "verify@example.com", "Password123!@")
+	assert.NoError(t, err)
+
+	t.Run("Unverified user cannot create a club", func(t *testing.T) {
+		_, err := CreateTestClub(app, token, "Unverified Club", nil, true)
+		assert.Error(t, err)
+	})
+
+	t.Run("Resend invalidates the previous link", func(t *testing.T) {
+		firstToken := tokenFromEmail(t, mailer, "verify@example.com")
+
+		req, err := NewProtectedRequest("POST", "/api/user/verify-email", token, nil, "application/json")
+		assert.NoError(t, err)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = postJSON(t, app, "/api/verify-email", handlers.VerifyEmailRequest{Token: firstToken})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Verify with mailed token", func(t *testing.T) {
+		verifyToken := tokenFromEmail(t, mailer, "verify@example.com")
+
+		resp := postJSON(t, app, "/api/verify-email", handlers.VerifyEmailRequest{Token: verifyToken})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// tokens are single use
+		resp = postJSON(t, app, "/api/verify-email", handlers.VerifyEmailRequest{Token: verifyToken})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Verified user can create a club", func(t *testing.T) {
+		_, err := CreateTestClub(app, token, "Verified Club", nil, true)
+		assert.NoError(t, err)
+	})
+}
+
+func TestPasswordReset(t *testing.T) {
+	mailer := &TestMailer{}
+	app := SetupTestAppWithMailer(mailer)
+
+	username := "resetuser"
+	oldPassword := "Password123!@"
+	newPassword := "NewPassword456#$"
+	session, err := CreateTestUserSession(app, username, "reset@example.com", oldPassword)
+	assert.NoError(t, err)
+
+	t.Run("Unknown email looks the same as a known one", func(t *testing.T) {
+		resp := postJSON(t, app, "/api/password-reset", handlers.RequestPasswordResetRequest{Email: "nobody@example.com"})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_, ok := mailer.LastTo("nobody@example.com")
+		assert.False(t, ok)
+	})
+
+	t.Run("Weak new password is rejected", func(t *testing.T) {
+		resp := postJSON(t, app, "/api/password-reset", handlers.RequestPasswordResetRequest{Email: "reset@example.com"})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		// the link is sent in the background
+		assert.Eventually(t, func() bool {
+			email, _ := mailer.LastTo("reset@example.com")
+			return email.Subject == "Reset your Task Social password"
+		}, time.Second, 5*time.Millisecond)
+		resetToken := tokenFromEmail(t, mailer, "reset@example.com")
+
+		resp = postJSON(t, app, "/api/password-reset/confirm", handlers.ResetPasswordRequest{Token: resetToken, Password: "weak"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Reset with mailed token", func(t *testing.T) {
+		resetToken := tokenFromEmail(t, mailer, "reset@example.com")
+
+		resp := postJSON(t, app, "/api/password-reset/confirm", handlers.ResetPasswordRequest{Token: resetToken, Password: newPassword})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err := LoginUser(app, &username, nil, oldPassword)
+		assert.Error(t, err)
+		_, err = LoginUser(app, &username, nil, newPassword)
+		assert.NoError(t, err)
+
+		// existing sessions are logged out
+		assert.Equal(t, http.StatusUnauthorized, getUserStatus(t, app, session.Token))
+
+		// tokens are single use
+		resp = postJSON(t, app, "/api/password-reset/confirm", handlers.ResetPasswordRequest{Token: resetToken, Password: "Another789%^"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+// failingMailer fails like a relay that is down
+type failingMailer struct{}
+
+func (m failingMailer) Send(ctx context.Context, email services.Email) error {
+	return errors.New("connection refused")
+}
+
+func TestPasswordResetMailFailure(t *testing.T) {
+	app := SetupTestAppWithMailer(failingMailer{})
+	_, err := CreateTestUser(app, "failuser", "fail@example.com", "Password123!@")
+	assert.NoError(t, err)
+
+	// a known address must not stand out from an unknown one
+	for _, email := range []string{"fail@example.com", "nobody@example.com"} {
+		resp := postJSON(t, app, "/api/password-reset", handlers.RequestPasswordResetRequest{Email: email})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestSMTPMailerTimeout(t *testing.T) {
+	// a relay that accepts connections and never answers
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	assert.NoError(t, err)
+	mailer := services.NewSMTPMailer(host, port, "", "", config.DefaultMailFrom)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = mailer.Send(ctx, services.Email{To: "someone@example.com", Subject: "Hello", Body: "Hello"})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func getJWKS(t *testing.T, app *fiber.App) services.JWKS {
+	req, err := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	assert.NoError(t, err)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	return decodeBody[services.JWKS](t, resp)
+}
+
+func TestJWKS(t *testing.T) {
+	t.Run("Shared secret is not published", func(t *testing.T) {
+		app := SetupTestApp()
+		assert.Empty(t, getJWKS(t, app).Keys)
+	})
+
+	t.Run("Access tokens verify with the published key", func(t *testing.T) {
+		config.JWTSigningMethod = jwt.SigningMethodEdDSA
+		t.Cleanup(func() { config.JWTSigningMethod = jwt.SigningMethodHS256 })
+		app := SetupTestApp()
+
+		token, err := CreateTestUser(app, "jwksuser", "jwks@example.com", "Password123!@")
+		assert.NoError(t, err)
+		// the api accepts its own tokens
+		getUserDisplay(t, app, token)
+
+		jwks := getJWKS(t, app)
+		if !assert.Len(t, jwks.Keys, 1) {
+			return
+		}
+		jwk := jwks.Keys[0]
+		assert.Equal(t, "OKP", jwk.Kty)
+		assert.Equal(t, "EdDSA", jwk.Alg)
+		assert.Equal(t, "sig", jwk.Use)
+
+		publicKey, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		assert.NoError(t, err)
+		parsed, err := jwt.Parse(token, func(token *jwt.Token) (any, error) {
+			return ed25519.PublicKey(publicKey), nil
+		}, jwt.WithValidMethods([]string{"EdDSA"}))
+		assert.NoError(t, err)
+		assert.Equal(t, jwk.Kid, parsed.Header["kid"])
+	})
+
+	t.Run("Tokens signed with the shared secret are rejected", func(t *testing.T) {
+		config.JWTSigningMethod = jwt.SigningMethodEdDSA
+		t.Cleanup(func() { config.JWTSigningMethod = jwt.SigningMethodHS256 })
+		app := SetupTestApp()
+
+		forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "someone",
+			"sid": "session",
+		}).SignedString(config.JWTSecret)
+		assert.NoError(t, err)
+		resp := protectedJSON(t, app, "GET", "/api/user", forged, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRateLimitedTestApp(t *testing.T) *fiber.App {
+	app := SetupTestApp()
+	config.LoginRateLimiting = true
+	t.Cleanup(func() { config.LoginRateLimiting = false })
+	return app
+}
+
+func attemptLogin(t *testing.T, app *fiber.App, username string, password string) *http.Response {
+	return postJSON(t, app, "/api/login", handlers.LoginUserRequest{Username: &username, Password: password})
+}
+
+func TestLoginErrorsAreUniform(t *testing.T) {
+	app := SetupTestApp()
+	_, err := CreateTestUser(app, "uniformuser", "uniform@example.com", "Password123!@")
+	assert.NoError(t, err)
+
+	wrongPassword := attemptLogin(t, app, "uniformuser", "WrongPassword1!@")
+	unknownUser := attemptLogin(t, app, "nosuchuser", "WrongPassword1!@")
+
+	assert.Equal(t, http.StatusUnauthorized, wrongPassword.StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, unknownUser.StatusCode)
+	assert.Equal(t,
+		decodeBody[handlers.ErrorResponse](t, wrongPassword).Error,
+		decodeBody[handlers.ErrorResponse](t, unknownUser).Error,
+	)
+}
+
+func TestLoginBackoff(t *testing.T) {
+	app := setupRateLimitedTestApp(t)
+	password := "Password123!@"
+	token, err := CreateTestUser(app, "backoffuser", "backoff@example.com", password)
+	assert.NoError(t, err)
+
+	t.Run("Account is throttled after repeated failures", func(t *testing.T) {
+		for range config.AccountFreeLoginFailures {
+			assert.Equal(t, http.StatusUnauthorized, attemptLogin(t, app, "backoffuser", "WrongPassword1!@").StatusCode)
+		}
+
+		resp := attemptLogin(t, app, "backoffuser", password)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "even the right password has to wait")
+		retryAfter, err := strconv.Atoi(resp.Header.Get(fiber.HeaderRetryAfter))
+		assert.NoError(t, err)
+		assert.Positive(t, retryAfter)
+	})
+
+	t.Run("Unknown accounts are throttled the same way", func(t *testing.T) {
+		for range config.AccountFreeLoginFailures {
+			assert.Equal(t, http.StatusUnauthorized, attemptLogin(t, app, "ghostuser", "WrongPassword1!@").StatusCode)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, attemptLogin(t, app, "ghostuser", "WrongPassword1!@").StatusCode)
+	})
+
+	t.Run("Failures are listed for the account owner", func(t *testing.T) {
+		resp := protectedJSON(t, app, "GET", "/api/user/login-failures", token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		failures := decodeBody[[]repository.GetUserLoginFailuresRow](t, resp)
+		// throttled attempts are rejected before the password is checked and are not recorded
+		assert.Len(t, failures, config.AccountFreeLoginFailures)
+		for _, f := range failures {
+			assert.Equal(t, "login", f.Action)
+			assert.Equal(t, "wrong password", *f.FailureReason)
+		}
+	})
+}
+
+func TestConcurrentLoginsAreThrottled(t *testing.T) {
+	app, _, _, _ := setupTestAppWithDatabase(&TestMailer{}, filepath.Join(t.TempDir(), "test.db"))
+	config.LoginRateLimiting = true
+	t.Cleanup(func() { config.LoginRateLimiting = false })
+	token, err := CreateTestUser(app, "paralleluser", "parallel@example.com", "Password123!@")
+	assert.NoError(t, err)
+
+	username := "paralleluser"
+	body, err := json.Marshal(handlers.LoginUserRequest{Username: &username, Password: "WrongPassword1!@"})
+	assert.NoError(t, err)
+
+	// every attempt sees the ones started before it, even while their passwords are still being checked
+	const attempts = 20
+	statuses := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequest("POST", "/api/login", bytes.NewBuffer(body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			// the attempts wait for each other's password checks, which can take longer than the default timeout
+			resp, err := app.Test(req, -1)
+			if assert.NoError(t, err) {
+				statuses <- resp.StatusCode
+			}
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	rejected := 0
+	for status := range statuses {
+		switch status {
+		case http.StatusUnauthorized:
+			rejected++
+		default:
+			assert.Equal(t, http.StatusTooManyRequests, status)
+		}
+	}
+	assert.Equal(t, config.AccountFreeLoginFailures, rejected)
+
+	resp := protectedJSON(t, app, "GET", "/api/user/login-failures", token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, decodeBody[[]repository.GetUserLoginFailuresRow](t, resp), config.AccountFreeLoginFailures)
+}
+
+func TestLoginIPBackoff(t *testing.T) {
+	app := setupRateLimitedTestApp(t)
+
+	// spread the failures over many accounts so only the per IP limit is reached
+	accounts := config.IPFreeLoginFailures/(config.AccountFreeLoginFailures-1) + 1
+	failures := 0
+	for i := range accounts {
+		for range config.AccountFreeLoginFailures - 1 {
+			if failures == config.IPFreeLoginFailures {
+				break
+			}
+			resp := attemptLogin(t, app, fmt.Sprintf("sprayuser%d", i), "WrongPassword1!@")
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			failures++
+		}
+	}
+
+	resp := attemptLogin(t, app, "freshuser", "WrongPassword1!@")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func TestRegisterRateLimit(t *testing.T) {
+	app := setupRateLimitedTestApp(t)
+
+	for i := range config.MaxRegistrationsPerIP {
+		_, err := CreateTestUser(app, fmt.Sprintf("signup%d", i), fmt.Sprintf("signup%d@example.com", i), "Password123!@")
+		assert.NoError(t, err)
+	}
+
+	resp := postJSON(t, app, "/api/register", handlers.RegisterUserRequest{
+		Username: "onetoomany",
+		Email:    "onetoomany@example.com",
+		Password: "Password123!@",
+	})
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func TestRegisterRateLimitBehindProxy(t *testing.T) {
+	register := func(app *fiber.App, name string, clientIP string) int {
+		body, err := json.Marshal(handlers.RegisterUserRequest{
+			Username: name,
+			Email:    name + "@example.com",
+			Password: "Password123!@",
+		})
+		assert.NoError(t, err)
+		req, err := http.NewRequest("POST", "/api/register", bytes.NewBuffer(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Real-IP", clientIP)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp.StatusCode
+	}
+	fillLimit := func(app *fiber.App, prefix string, clientIP string) {
+		for i := range config.MaxRegistrationsPerIP {
+			assert.Equal(t, http.StatusCreated, register(app, fmt.Sprintf("%s%d", prefix, i), clientIP))
+		}
+	}
+
+	t.Run("Clients behind a trusted proxy are limited separately", func(t *testing.T) {
+		// requests made with app.Test come from 0.0.0.0
+		config.TrustedProxies = []string{"0.0.0.0"}
+		t.Cleanup(func() { config.TrustedProxies = nil })
+		app := setupRateLimitedTestApp(t)
+
+		fillLimit(app, "first", "203.0.113.1")
+		assert.Equal(t, http.StatusTooManyRequests, register(app, "firstextra", "203.0.113.1"))
+		assert.Equal(t, http.StatusCreated, register(app, "second", "203.0.113.2"))
+	})
+
+	t.Run("The header is ignored from other addresses", func(t *testing.T) {
+		app := setupRateLimitedTestApp(t)
+
+		fillLimit(app, "spoofed", "203.0.113.1")
+		assert.Equal(t, http.StatusTooManyRequests, register(app, "spoofedextra", "203.0.113.2"))
+	})
+}
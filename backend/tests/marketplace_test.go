@@ -0,0 +1,374 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chai2010/webp"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemPriceEstimates(t *testing.T) {
+	app := SetupTestApp()
+	password := "Password123!@"
+
+	sellerToken, err := CreateTestUser(app, "seller", "seller@example.com", password)
+	assert.NoError(t, err)
+	buyerToken, err := CreateTestUser(app, "buyer", "buyer@example.com", password)
+	assert.NoError(t, err)
+	club, err := CreateTestClub(app, sellerToken, "Price Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), buyerToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	createItem := func(token string, req services.CreateItemRequest) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", club.ID), token, req)
+	}
+	listItems := func(query string) []services.ClubMarketplaceItem {
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/items%s", club.ID, query), buyerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeBody[[]services.ClubMarketplaceItem](t, resp)
+	}
+	names := func(items []services.ClubMarketplaceItem) string {
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			out = append(out, item.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	ids := map[string]string{}
+	t.Run("Create items with price estimates", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			price    *float64
+			currency *string
+		}{
+			{name: "Table", price: FloatToPtr(40.0)},
+			{name: "Sofa", price: FloatToPtr(250.0), currency: StringToPtr("usd")},
+			{name: "Rug", price: FloatToPtr(30.0), currency: StringToPtr("EUR")},
+			{name: "Poster"},
+		}
+		for _, tc := range testCases {
+			resp := createItem(sellerToken, services.CreateItemRequest{Name: tc.name, PriceEstimate: tc.price, Currency: tc.currency})
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			ids[tc.name] = decodeBody[handlers.CreatedResponse](t, resp).ID
+		}
+
+		items := listItems("")
+		if assert.Len(t, items, 4) {
+			for _, item := range items {
+				switch item.Name {
+				case "Table":
+					// no currency means the default one
+					if assert.NotNil(t, item.Currency) && assert.NotNil(t, item.PriceEstimate) {
+						assert.Equal(t, "USD", *item.Currency)
+						assert.Equal(t, 40.0, *item.PriceEstimate)
+					}
+				case "Sofa":
+					if assert.NotNil(t, item.Currency) {
+						assert.Equal(t, "USD", *item.Currency)
+					}
+				case "Poster":
+					assert.Nil(t, item.PriceEstimate)
+					assert.Nil(t, item.Currency)
+				}
+			}
+		}
+	})
+
+	t.Run("Invalid price estimates", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			price    *float64
+			currency *string
+		}{
+			{name: "Negative", price: FloatToPtr(-1.0)},
+			{name: "Currency without a price", currency: StringToPtr("USD")},
+			{name: "Long currency", price: FloatToPtr(5.0), currency: StringToPtr("DOLLAR")},
+			{name: "Currency with digits", price: FloatToPtr(5.0), currency: StringToPtr("U5D")},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				resp := createItem(sellerToken, services.CreateItemRequest{Name: "Broken", PriceEstimate: tc.price, Currency: tc.currency})
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			})
+		}
+
+		resp := protectedJSON(t, app, "PUT", "/api/marketplace/item/"+ids["Poster"], sellerToken, repository.UpdateItemParams{Currency: StringToPtr("EUR")})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Update price estimates", func(t *testing.T) {
+		resp := protectedJSON(t, app, "PUT", "/api/marketplace/item/"+ids["Poster"], sellerToken, repository.UpdateItemParams{PriceEstimate: FloatToPtr(10.0)})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "PUT", "/api/marketplace/item/"+ids["Rug"], sellerToken, repository.UpdateItemParams{Currency: StringToPtr("gbp")})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		for _, item := range listItems("") {
+			switch item.Name {
+			case "Poster":
+				if assert.NotNil(t, item.Currency) {
+					assert.Equal(t, "USD", *item.Currency)
+				}
+			case "Rug":
+				if assert.NotNil(t, item.Currency) && assert.NotNil(t, item.PriceEstimate) {
+					assert.Equal(t, "GBP", *item.Currency)
+					assert.Equal(t, 30.0, *item.PriceEstimate)
+				}
+			}
+		}
+	})
+
+	t.Run("Filter and sort by price", func(t *testing.T) {
+		unpriced := createItem(sellerToken, services.CreateItemRequest{Name: "Vase"})
+		assert.Equal(t, http.StatusCreated, unpriced.StatusCode)
+
+		testCases := []struct {
+			name     string
+			query    string
+			expected string
+		}{
+			{name: "Cheapest first", query: "?sort=price_asc", expected: "Poster,Rug,Table,Sofa,Vase"},
+			{name: "Most expensive first", query: "?sort=price_desc", expected: "Sofa,Table,Rug,Poster,Vase"},
+			{name: "Price range", query: "?min_price=20&max_price=100&sort=price_asc", expected: "Rug,Table"},
+			{name: "Currency", query: "?currency=usd&sort=price_desc", expected: "Sofa,Table,Poster"},
+			{name: "Currency and minimum", query: "?currency=USD&min_price=40&sort=price_asc", expected: "Table,Sofa"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.expected, names(listItems(tc.query)))
+			})
+		}
+
+		for _, query := range []string{"?sort=cheapest", "?min_price=abc", "?max_price=-5"} {
+			resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/items%s", club.ID, query), buyerToken, nil)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		}
+	})
+
+	t.Run("Trades warn about mismatched values", func(t *testing.T) {
+		resp := createItem(buyerToken, services.CreateItemRequest{Name: "Stool", PriceEstimate: FloatToPtr(15.0)})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		stool := decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		propose := func(requested string) handlers.TradeCreatedResponse {
+			resp := protectedJSON(t, app, "POST", "/api/marketplace/trades", buyerToken, services.TradeRequest{
+				OfferedItemIDs:   []string{stool},
+				RequestedItemIDs: []string{requested},
+			})
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			created := decodeBody[handlers.TradeCreatedResponse](t, resp)
+			resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/trades/%s/cancel", created.ID), buyerToken, nil)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			return created
+		}
+
+		// 15 USD for 250 USD
+		assert.True(t, strings.Contains(propose(ids["Sofa"]).Warning, "250.00 USD"))
+		// 15 USD for 10 USD
+		assert.Empty(t, propose(ids["Poster"]).Warning)
+		// GBP and USD cannot be compared
+		assert.Empty(t, propose(ids["Rug"]).Warning)
+	})
+}
+
+func TestItemImages(t *testing.T) {
+	app := SetupTestApp()
+	password := "Password123!@"
+
+	sellerToken, err := CreateTestUser(app, "seller", "seller@example.com", password)
+	assert.NoError(t, err)
+	buyerToken, err := CreateTestUser(app, "buyer", "buyer@example.com", password)
+	assert.NoError(t, err)
+	club, err := CreateTestClub(app, sellerToken, "Photo Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), buyerToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	photo, err := os.ReadFile("test_assets/testbanner.jpg")
+	assert.NoError(t, err)
+	photoConfig, _, err := image.DecodeConfig(bytes.NewReader(photo))
+	assert.NoError(t, err)
+
+	listItem := func() services.ClubMarketplaceItem {
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/items", club.ID), buyerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		items := decodeBody[[]services.ClubMarketplaceItem](t, resp)
+		if !assert.Len(t, items, 1) {
+			return services.ClubMarketplaceItem{}
+		}
+		return items[0]
+	}
+
+	var itemID string
+	t.Run("Create an item with details", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			req      services.CreateItemRequest
+			expected int
+		}{
+			{name: "Unknown condition", req: services.CreateItemRequest{Name: "Desk", Condition: StringToPtr("broken")}, expected: http.StatusBadRequest},
+			{name: "Unknown category", req: services.CreateItemRequest{Name: "Desk", Category: StringToPtr("spaceships")}, expected: http.StatusBadRequest},
+			{name: "Long pickup location", req: services.CreateItemRequest{Name: "Desk", PickupLocation: StringToPtr(strings.Repeat("a", config.MaxPickupLocationLength+1))}, expected: http.StatusBadRequest},
+			{name: "Valid", req: services.CreateItemRequest{
+				Name:           "Desk",
+				Condition:      StringToPtr("Like_New"),
+				Category:       StringToPtr("furniture"),
+				PickupLocation: StringToPtr("  12 Main Street "),
+				CanShip:        true,
+			}, expected: http.StatusCreated},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", club.ID), sellerToken, tc.req)
+				assert.Equal(t, tc.expected, resp.StatusCode)
+				if tc.expected == http.StatusCreated {
+					itemID = decodeBody[handlers.CreatedResponse](t, resp).ID
+				}
+			})
+		}
+
+		item := listItem()
+		if assert.NotNil(t, item.Condition) && assert.NotNil(t, item.Category) && assert.NotNil(t, item.PickupLocation) {
+			assert.Equal(t, services.ItemConditionLikeNew, *item.Condition)
+			assert.Equal(t, "furniture", *item.Category)
+			assert.Equal(t, "12 Main Street", *item.PickupLocation)
+		}
+		assert.True(t, item.CanShip)
+		assert.NotNil(t, item.Images)
+		assert.Empty(t, item.Images)
+	})
+
+	t.Run("Update item details", func(t *testing.T) {
+		resp := protectedJSON(t, app, "PUT", "/api/marketplace/item/"+itemID, sellerToken, repository.UpdateItemParams{Category: StringToPtr("rocks")})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp = protectedJSON(t, app, "PUT", "/api/marketplace/item/"+itemID, buyerToken, repository.UpdateItemParams{Condition: StringToPtr("poor")})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "PUT", "/api/marketplace/item/unknown", sellerToken, repository.UpdateItemParams{Condition: StringToPtr("poor")})
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "PUT", "/api/marketplace/item/"+itemID, sellerToken, repository.UpdateItemParams{
+			Condition:      StringToPtr("GOOD"),
+			PickupLocation: StringToPtr(""),
+			CanShip:        BoolToPtr(false),
+		})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		item := listItem()
+		if assert.NotNil(t, item.Condition) && assert.NotNil(t, item.Category) {
+			assert.Equal(t, services.ItemConditionGood, *item.Condition)
+			assert.Equal(t, "furniture", *item.Category)
+		}
+		assert.Nil(t, item.PickupLocation)
+		assert.False(t, item.CanShip)
+	})
+
+	var images []repository.ItemImage
+	t.Run("Upload photos", func(t *testing.T) {
+		resp := uploadItemImage(t, app, buyerToken, itemID, photo)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = uploadItemImage(t, app, sellerToken, itemID, []byte("not an image"))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp = uploadItemImage(t, app, sellerToken, "unknown", photo)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		for range config.MaxItemImages {
+			resp := uploadItemImage(t, app, sellerToken, itemID, photo)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			images = append(images, decodeBody[repository.ItemImage](t, resp))
+		}
+		resp = uploadItemImage(t, app, sellerToken, itemID, photo)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		// both sizes keep the aspect ratio of the upload
+		for url, expectedWidth := range map[string]int{images[0].Url: config.ItemImageWidth, images[0].ThumbnailUrl: config.ItemThumbnailWidth} {
+			data, err := os.ReadFile(assetPath(url))
+			if assert.NoError(t, err) {
+				width, height, _, err := webp.GetInfo(data)
+				assert.NoError(t, err)
+				assert.Equal(t, expectedWidth, width)
+				assert.Equal(t, expectedWidth*photoConfig.Height/photoConfig.Width, height)
+			}
+		}
+
+		item := listItem()
+		if assert.Len(t, item.Images, config.MaxItemImages) {
+			assert.Equal(t, images[0].ID, item.Images[0].ID)
+			assert.Equal(t, images[0].ThumbnailUrl, item.Images[0].ThumbnailUrl)
+		}
+	})
+
+	t.Run("Delete a photo", func(t *testing.T) {
+		path := fmt.Sprintf("/api/marketplace/item/%s/images/%s", itemID, images[0].ID)
+		resp := protectedJSON(t, app, "DELETE", path, buyerToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "DELETE", path, sellerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "DELETE", path, sellerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		assert.NoFileExists(t, assetPath(images[0].Url))
+		assert.NoFileExists(t, assetPath(images[0].ThumbnailUrl))
+		assert.Len(t, listItem().Images, config.MaxItemImages-1)
+	})
+
+	t.Run("Deleting the item deletes its photos", func(t *testing.T) {
+		resp := protectedJSON(t, app, "DELETE", "/api/marketplace/item/"+itemID, buyerToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "DELETE", "/api/marketplace/item/"+itemID, sellerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		for _, image := range images[1:] {
+			assert.NoFileExists(t, assetPath(image.Url))
+			assert.NoFileExists(t, assetPath(image.ThumbnailUrl))
+		}
+	})
+
+	t.Run("Deleting the club deletes the photos of its items", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", club.ID), buyerToken, services.CreateItemRequest{Name: "Lamp"})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp = uploadItemImage(t, app, buyerToken, decodeBody[handlers.CreatedResponse](t, resp).ID, photo)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		image := decodeBody[repository.ItemImage](t, resp)
+
+		resp = protectedJSON(t, app, "DELETE", "/api/club/"+club.ID, sellerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NoFileExists(t, assetPath(image.Url))
+		assert.NoFileExists(t, assetPath(image.ThumbnailUrl))
+	})
+}
+
+func uploadItemImage(t *testing.T, app *fiber.App, token, itemID string, data []byte) *http.Response {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("image", "photo")
+	assert.NoError(t, err)
+	_, err = part.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req, err := NewProtectedRequest("POST", fmt.Sprintf("/api/marketplace/item/%s/images", itemID), token, body, writer.FormDataContentType())
+	assert.NoError(t, err)
+	resp, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	return resp
+}
+
+// assetPath is where an uploaded image is stored relative to the tests
+func assetPath(url string) string {
+	return filepath.Join("./", strings.TrimPrefix(url, "/"))
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectMessages(t *testing.T) {
+	app, _, querier, _ := SetupTestAppWithConn(&TestMailer{})
+	ctx := context.Background()
+	testServerAddr := ":1118"
+	go func() {
+		err := app.Listen(testServerAddr)
+		assert.NoError(t, err)
+	}()
+	defer app.Shutdown()
+
+	aliceToken, err := CreateTestUser(app, "alice", "alice@example.com", "Password123!@")
+	assert.NoError(t, err)
+	bobToken, err := CreateTestUser(app, "bob", "bob@example.com", "Password123!@")
+	assert.NoError(t, err)
+	outsiderToken, err := CreateTestUser(app, "outsider", "outsider@example.com", "Password123!@")
+	assert.NoError(t, err)
+	aliceID, err := querier.GetUserIDByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+	bobID, err := querier.GetUserIDByEmail(ctx, "bob@example.com")
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, aliceToken, "Pen Pals", StringToPtr(""), true)
+	assert.NoError(t, err)
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), bobToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	send := func(token string, recipientID string, content string) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/user/%s/messages", recipientID), token, handlers.DirectMessageRequest{Content: content})
+	}
+
+	aliceConn, err := dialWebSocket(testServerAddr, aliceToken)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer aliceConn.Close()
+	bobConn, err := dialWebSocket(testServerAddr, bobToken)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer bobConn.Close()
+	outsiderConn, err := dialWebSocket(testServerAddr, outsiderToken)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer outsiderConn.Close()
+
+	t.Run("Users without a conversation are refused", func(t *testing.T) {
+		event, payload := sendTopicRequest(t, outsiderConn, services.WebSocketSubscribe, services.DirectMessagesTopic(aliceID))
+		assert.Equal(t, services.WebSocketError, event)
+		assert.Equal(t, services.ErrTopicNotAllowed.Error(), payload.Error)
+		event, _ = sendTopicRequest(t, aliceConn, services.WebSocketSubscribe, services.DirectMessagesTopic(aliceID))
+		assert.Equal(t, services.WebSocketError, event, "a user has no conversation with themselves")
+
+		resp := send(outsiderToken, aliceID, "Hi there")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/user/%s/messages", aliceID), outsiderToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp = send(aliceToken, "missing", "Hi there")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Both users of the conversation get the message", func(t *testing.T) {
+		subscribe(t, aliceConn, services.DirectMessagesTopic(bobID))
+		subscribe(t, bobConn, services.DirectMessagesTopic(aliceID))
+		// a member of the club who is not part of the conversation
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), outsiderToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		subscribe(t, outsiderConn, services.DirectMessagesTopic(aliceID))
+
+		resp = send(aliceToken, bobID, "See you at the meeting")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		messageID := decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		for _, side := range []struct {
+			conn  *websocket.Conn
+			topic string
+		}{{aliceConn, services.DirectMessagesTopic(bobID)}, {bobConn, services.DirectMessagesTopic(aliceID)}} {
+			side.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			var message struct {
+				services.WebSocketMessage
+				Payload repository.GetDirectMessageRow `json:"payload"`
+			}
+			assert.NoError(t, side.conn.ReadJSON(&message))
+			assert.Equal(t, "new_direct_message", message.Event)
+			assert.Equal(t, side.topic, message.Topic)
+			assert.Equal(t, messageID, message.Payload.ID)
+			assert.Equal(t, aliceID, message.Payload.SenderID)
+			assert.Equal(t, bobID, message.Payload.RecipientID)
+			assert.Equal(t, "See you at the meeting", message.Payload.Content)
+		}
+
+		// the outsider's next message is the answer to this request, not alice's message to bob
+		event, _ := sendTopicRequest(t, outsiderConn, services.WebSocketUnsubscribe, services.DirectMessagesTopic(aliceID))
+		assert.Equal(t, services.WebSocketUnsubscribed, event)
+	})
+
+	t.Run("The conversation can be read by both users", func(t *testing.T) {
+		resp := send(bobToken, aliceID, "I'll bring the book")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		for _, conn := range []*websocket.Conn{aliceConn, bobConn} {
+			message, ok := readEvent[repository.GetDirectMessageRow](t, conn, "new_direct_message")
+			if assert.True(t, ok) {
+				assert.Equal(t, bobID, message.SenderID)
+			}
+		}
+		resp = send(bobToken, aliceID, "   ")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp = send(bobToken, bobID, "Note to self")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		for _, viewer := range []struct{ token, otherID string }{{aliceToken, bobID}, {bobToken, aliceID}} {
+			resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/user/%s/messages", viewer.otherID), viewer.token, nil)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			messages := decodeBody[[]repository.GetDirectMessagesRow](t, resp)
+			if assert.Len(t, messages, 2) {
+				assert.Equal(t, "See you at the meeting", messages[0].Content)
+				assert.Equal(t, "I'll bring the book", messages[1].Content)
+			}
+		}
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/user/%s/messages", bobID), outsiderToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, decodeBody[[]repository.GetDirectMessagesRow](t, resp))
+	})
+
+	t.Run("A conversation outlives the shared club", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/leave", club.ID), bobToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		// subscribing again checks the conversation rather than the club
+		subscribe(t, bobConn, services.DirectMessagesTopic(aliceID))
+		resp = send(aliceToken, bobID, "Still there?")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		message, ok := readEvent[json.RawMessage](t, bobConn, "new_direct_message")
+		if assert.True(t, ok) {
+			assert.Contains(t, string(message), "Still there?")
+		}
+
+		// the outsider never wrote to bob, so bob leaving the club ends theirs
+		resp = send(outsiderToken, bobID, "Hello?")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
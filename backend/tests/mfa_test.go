@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// generateTOTP computes the code an authenticator app would show at the given time
+func generateTOTP(t *testing.T, secret string, at time.Time) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	assert.NoError(t, err)
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func protectedJSON(t *testing.T, app *fiber.App, method string, path string, token string, body any) *http.Response {
+	var reader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		assert.NoError(t, err)
+		reader = bytes.NewBuffer(jsonBody)
+	}
+	req, err := NewProtectedRequest(method, path, token, reader, "application/json")
+	assert.NoError(t, err)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	return resp
+}
+
+func decodeBody[T any](t *testing.T, resp *http.Response) T {
+	var body T
+	respBody, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(respBody, &body))
+	return body
+}
+
+func TestTOTP(t *testing.T) {
+	app := SetupTestApp()
+
+	username := "mfauser"
+	password := "Password123!@"
+	token, err := CreateTestUser(app, username, "mfa@example.com", password)
+	assert.NoError(t, err)
+
+	var secret string
+	var recoveryCodes []string
+	// codes are derived from this time so the test does not flake on a step boundary
+	var enabledAt time.Time
+
+	t.Run("Enable requires a started enrollment", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/user/mfa/totp/enable", token, handlers.MFACodeRequest{Code: "123456"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Enroll returns a secret and provisioning uri", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/user/mfa/totp", token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		enrollment := decodeBody[services.TOTPEnrollment](t, resp)
+		assert.NotEmpty(t, enrollment.Secret)
+		assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/")
+		assert.Contains(t, enrollment.ProvisioningURI, username)
+		secret = enrollment.Secret
+
+		// login is unaffected until enrollment is confirmed
+		_, err := LoginUser(app, &username, nil, password)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Enable with a wrong code fails", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/user/mfa/totp/enable", token, handlers.MFACodeRequest{Code: "000000"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Enable returns recovery codes", func(t *testing.T) {
+		enabledAt = time.Now()
+		resp := protectedJSON(t, app, "POST", "/api/user/mfa/totp/enable", token, handlers.MFACodeRequest{Code: generateTOTP(t, secret, enabledAt)})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		body := decodeBody[handlers.RecoveryCodesResponse](t, resp)
+		assert.Len(t, body.RecoveryCodes, 10)
+		recoveryCodes = body.RecoveryCodes
+
+		resp = protectedJSON(t, app, "POST", "/api/user/mfa/totp", token, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "enrolling again while enabled must fail")
+	})
+
+	t.Run("Login requires a second factor", func(t *testing.T) {
+		resp := postJSON(t, app, "/api/login", handlers.LoginUserRequest{Username: &username, Password: password})
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		challenge := decodeBody[handlers.MFARequiredResponse](t, resp)
+		assert.Equal(t, "mfa_required", challenge.Message)
+		assert.NotEmpty(t, challenge.MFAToken)
+
+		// the challenge token is not an access token
+		assert.Equal(t, http.StatusUnauthorized, getUserStatus(t, app, challenge.MFAToken))
+
+		// the code used to enable was already accepted and cannot be replayed
+		resp = postJSON(t, app, "/api/login/mfa", handlers.MFALoginRequest{MFAToken: challenge.MFAToken, Code: generateTOTP(t, secret, enabledAt)})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = postJSON(t, app, "/api/login/mfa", handlers.MFALoginRequest{MFAToken: challenge.MFAToken, Code: generateTOTP(t, secret, enabledAt.Add(30*time.Second))})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		login := decodeBody[handlers.SuccessfulLoginResponse](t, resp)
+		assert.Equal(t, http.StatusOK, getUserStatus(t, app, login.Token))
+	})
+
+	t.Run("Recovery codes are single use", func(t *testing.T) {
+		resp := postJSON(t, app, "/api/login", handlers.LoginUserRequest{Username: &username, Password: password})
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		challenge := decodeBody[handlers.MFARequiredResponse](t, resp)
+
+		resp = postJSON(t, app, "/api/login/mfa", handlers.MFALoginRequest{MFAToken: challenge.MFAToken, Code: recoveryCodes[0]})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = postJSON(t, app, "/api/login/mfa", handlers.MFALoginRequest{MFAToken: challenge.MFAToken, Code: recoveryCodes[0]})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Disable with a recovery code", func(t *testing.T) {
+		resp := protectedJSON(t, app, "DELETE", "/api/user/mfa/totp", token, handlers.MFACodeRequest{Code: "wrong-code"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "DELETE", "/api/user/mfa/totp", token, handlers.MFACodeRequest{Code: recoveryCodes[1]})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err := LoginUser(app, &username, nil, password)
+		assert.NoError(t, err)
+	})
+}
+
+// failingRecoveryCodeTransactor fails storing the last recovery code, after the others were written
+type failingRecoveryCodeTransactor struct {
+	services.Transactor
+}
+
+type failingRecoveryCodeQuerier struct {
+	repository.Querier
+	created *int
+}
+
+func (t failingRecoveryCodeTransactor) WithTx(ctx context.Context, fn func(q repository.Querier) error) error {
+	created := 0
+	return t.Transactor.WithTx(ctx, func(q repository.Querier) error {
+		return fn(failingRecoveryCodeQuerier{Querier: q, created: &created})
+	})
+}
+
+func (q failingRecoveryCodeQuerier) CreateUserRecoveryCode(ctx context.Context, arg repository.CreateUserRecoveryCodeParams) error {
+	*q.created++
+	if *q.created == config.RecoveryCodeCount {
+		return errors.New("disk I/O error")
+	}
+	return q.Querier.CreateUserRecoveryCode(ctx, arg)
+}
+
+func TestTOTPEnableRollsBack(t *testing.T) {
+	app, conn, querier, _ := SetupTestAppWithConn(&TestMailer{})
+	ctx := context.Background()
+	authService := services.NewAuthService(services.NewSigningKeyService(querier))
+	userService := services.NewUserService(querier, authService, nil, nil, nil, nil, nil, failingRecoveryCodeTransactor{services.NewTransactor(conn)})
+
+	username := "halfenabled"
+	password := "Password123!@"
+	_, err := CreateTestUser(app, username, "halfenabled@example.com", password)
+	assert.NoError(t, err)
+	userID, err := querier.GetUserIDByEmail(ctx, "halfenabled@example.com")
+	assert.NoError(t, err)
+
+	enrollment, err := userService.EnrollTOTP(ctx, userID)
+	assert.NoError(t, err)
+	codes, err := userService.EnableTOTP(ctx, userID, generateTOTP(t, enrollment.Secret, time.Now()))
+	assert.Error(t, err)
+	assert.Empty(t, codes)
+
+	totp, err := querier.GetUserTOTP(ctx, userID)
+	assert.NoError(t, err)
+	assert.Nil(t, totp.TotpEnabledAt, "two factor auth must stay off without its recovery codes")
+	count, err := querier.CountUnusedUserRecoveryCodes(ctx, userID)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	_, err = LoginUser(app, &username, nil, password)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentFilter(t *testing.T) {
+	config.ContentBlockedWords = []string{"ScamCoin"}
+	config.ContentReviewWords = []string{"giveaway"}
+	t.Cleanup(func() {
+		config.ContentBlockedWords = nil
+		config.ContentReviewWords = nil
+	})
+	app := SetupTestApp()
+	password := "Password123!@"
+
+	ownerToken, err := CreateTestUser(app, "filterowner", "filterowner@example.com", password)
+	assert.NoError(t, err)
+	memberToken, err := CreateTestUser(app, "filtermember", "filtermember@example.com", password)
+	assert.NoError(t, err)
+	otherToken, err := CreateTestUser(app, "filterother", "filterother@example.com", password)
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, ownerToken, "Filtered Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	for _, token := range []string{memberToken, otherToken} {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	post := func(token string, content string) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/post", club.ID), token, handlers.ClubPostRequest{TextContent: content})
+	}
+	clubPosts := func(token string) []repository.GetClubPostsRow {
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/posts", club.ID), token, nil)
+		return decodeBody[[]repository.GetClubPostsRow](t, resp)
+	}
+	heldContent := func() services.HeldContent {
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/held", club.ID), ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeBody[services.HeldContent](t, resp)
+	}
+
+	t.Run("Blocked words reject usernames and posts", func(t *testing.T) {
+		resp := postJSON(t, app, "/api/register", handlers.RegisterUserRequest{
+			Username: "scamcoin_seller",
+			Email:    "seller@example.com",
+			Password: password,
+		})
+		// underscores separate words like any other punctuation
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = post(memberToken, "Buy SCAMCOIN now")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Empty(t, clubPosts(memberToken))
+	})
+
+	var heldPostID string
+	t.Run("Review words hold posts until a moderator approves them", func(t *testing.T) {
+		resp := post(memberToken, "Giveaway tonight!")
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		heldPostID = decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		// only the author sees it in the feed
+		if posts := clubPosts(memberToken); assert.Len(t, posts, 1) {
+			assert.Equal(t, services.ModerationPending, posts[0].ModerationStatus)
+		}
+		assert.Empty(t, clubPosts(otherToken))
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/post/%s", club.ID, heldPostID), otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/held", club.ID), memberToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		if held := heldContent(); assert.Len(t, held.Posts, 1) {
+			assert.Equal(t, heldPostID, held.Posts[0].ID)
+			assert.Equal(t, "filtermember", held.Posts[0].AuthorUsername)
+			assert.NotNil(t, held.Posts[0].ModerationReason)
+		}
+
+		resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/held/posts/%s/approve", club.ID, heldPostID), ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/held/posts/%s/approve", club.ID, heldPostID), ownerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Len(t, clubPosts(otherToken), 1)
+		assert.Empty(t, heldContent().Posts)
+	})
+
+	t.Run("Links are held when repeated or too many", func(t *testing.T) {
+		resp := post(memberToken, "My shop https://shop.example.com")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		testCases := []struct {
+			name    string
+			content string
+		}{
+			{name: "Repeated in the post", content: "https://a.example.com and again https://a.example.com"},
+			{name: "Posted recently", content: "Check out HTTPS://SHOP.EXAMPLE.COM."},
+			{name: "Too many", content: "https://1.example.com https://2.example.com https://3.example.com https://4.example.com"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, http.StatusAccepted, post(memberToken, tc.content).StatusCode)
+			})
+		}
+
+		// rejecting deletes them
+		held := heldContent()
+		assert.Len(t, held.Posts, len(testCases))
+		for _, p := range held.Posts {
+			resp := protectedJSON(t, app, "DELETE", fmt.Sprintf("/api/club/%s/held/posts/%s", club.ID, p.ID), ownerToken, nil)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+		assert.Empty(t, heldContent().Posts)
+		// approved posts cannot be rejected this way
+		resp = protectedJSON(t, app, "DELETE", fmt.Sprintf("/api/club/%s/held/posts/%s", club.ID, heldPostID), ownerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Clubs manage their own blocklist", func(t *testing.T) {
+		blocklist := fmt.Sprintf("/api/club/%s/blocklist", club.ID)
+		resp := protectedJSON(t, app, "PUT", blocklist, memberToken, services.BlockedTermRequest{Term: "bike", Action: services.FilterHold})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "PUT", blocklist, ownerToken, services.BlockedTermRequest{Term: "bike", Action: "ban"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp = protectedJSON(t, app, "PUT", blocklist, ownerToken, services.BlockedTermRequest{Term: "!!!", Action: services.FilterHold})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "PUT", blocklist, ownerToken, services.BlockedTermRequest{Term: "Free Pizza", Action: services.FilterReject})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "PUT", blocklist, ownerToken, services.BlockedTermRequest{Term: "bike", Action: services.FilterHold})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "GET", blocklist, ownerToken, nil)
+		terms := decodeBody[[]repository.ClubBlockedTerm](t, resp)
+		if assert.Len(t, terms, 2) {
+			assert.Equal(t, "free pizza", terms[1].Term)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, post(memberToken, "free-pizza for everyone").StatusCode)
+
+		// held items are not listed until approved
+		resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", club.ID), memberToken, services.CreateItemRequest{Name: "Old bike"})
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		itemID := decodeBody[handlers.CreatedResponse](t, resp).ID
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/items", club.ID), otherToken, nil)
+		assert.Empty(t, decodeBody[[]services.ClubMarketplaceItem](t, resp))
+		if held := heldContent(); assert.Len(t, held.Items, 1) {
+			assert.Equal(t, itemID, held.Items[0].ID)
+		}
+		resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/held/items/%s/approve", club.ID, itemID), ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/items", club.ID), otherToken, nil)
+		assert.Len(t, decodeBody[[]services.ClubMarketplaceItem](t, resp), 1)
+
+		// the blocklist only applies inside the club
+		_, err := CreateTestUser(app, "bike_rider", "rider@example.com", password)
+		assert.NoError(t, err)
+
+		resp = protectedJSON(t, app, "DELETE", blocklist+"?term="+url.QueryEscape("FREE PIZZA"), ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "DELETE", blocklist+"?term="+url.QueryEscape("free pizza"), ownerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, post(memberToken, "free pizza for everyone").StatusCode)
+	})
+
+	t.Run("Content outside of clubs cannot be held", func(t *testing.T) {
+		resp := postJSON(t, app, "/api/register", handlers.RegisterUserRequest{
+			Username: "giveaway_host",
+			Email:    "host@example.com",
+			Password: password,
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp = protectedJSON(t, app, "PUT", "/api/user", memberToken, handlers.UpdateUserRequest{Username: StringToPtr("giveaway")})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Posting too often is rejected", func(t *testing.T) {
+		for i := range config.MaxPostsPerRateWindow {
+			resp := post(otherToken, fmt.Sprintf("post number %d", i))
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+		resp := post(otherToken, "one more")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.True(t, strings.Contains(decodeBody[handlers.ErrorResponse](t, resp).Error, "too often"))
+	})
+}
@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MockOIDCUser is the account that is "logged in" at the mock provider
+type MockOIDCUser struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+}
+
+type mockAuthorization struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	user          MockOIDCUser
+}
+
+// MockOIDCProvider is a minimal OpenID Connect provider for tests.
+// It implements discovery, the authorization endpoint (without a login page), the token endpoint with PKCE and JWKS.
+type MockOIDCProvider struct {
+	Server   *httptest.Server
+	ClientID string
+
+	key          *rsa.PrivateKey
+	mu           sync.Mutex
+	user         MockOIDCUser
+	codes        map[string]mockAuthorization
+	tokenKeyID   string // kid header of issued id tokens
+	jwksRequests int
+}
+
+const mockOIDCKeyID = "mock-key"
+
+func NewMockOIDCProvider(t *testing.T, clientID string) *MockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &MockOIDCProvider{
+		ClientID:   clientID,
+		key:        key,
+		codes:      make(map[string]mockAuthorization),
+		tokenKeyID: mockOIDCKeyID,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/jwks", p.jwks)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Server.Close)
+	return p
+}
+
+func (p *MockOIDCProvider) Issuer() string {
+	return p.Server.URL
+}
+
+// SetUser changes who logs in on the next authorization
+func (p *MockOIDCProvider) SetUser(user MockOIDCUser) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = user
+}
+
+// SetTokenKeyID changes the key id id tokens claim to be signed with, the key set keeps its key id
+func (p *MockOIDCProvider) SetTokenKeyID(kid string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tokenKeyID = kid
+}
+
+// JWKSRequests counts how often the key set was fetched
+func (p *MockOIDCProvider) JWKSRequests() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jwksRequests
+}
+
+func (p *MockOIDCProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.Issuer(),
+		"authorization_endpoint":                p.Issuer() + "/authorize",
+		"token_endpoint":                        p.Issuer() + "/token",
+		"jwks_uri":                              p.Issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize logs in the current user immediately and redirects back with a code
+func (p *MockOIDCProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != p.ClientID ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := rand.Text()
+	p.mu.Lock()
+	p.codes[code] = mockAuthorization{
+		clientID:      q.Get("client_id"),
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+		nonce:         q.Get("nonce"),
+		user:          p.user,
+	}
+	p.mu.Unlock()
+
+	redirect, _ := url.Parse(q.Get("redirect_uri"))
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *MockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	auth, ok := p.codes[code]
+	delete(p.codes, code) // codes are single use
+	p.mu.Unlock()
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if r.PostForm.Get("client_id") != auth.clientID || r.PostForm.Get("redirect_uri") != auth.redirectURI {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                p.Issuer(),
+		"aud":                auth.clientID,
+		"sub":                auth.user.Subject,
+		"email":              auth.user.Email,
+		"email_verified":     auth.user.EmailVerified,
+		"preferred_username": auth.user.PreferredUsername,
+		"nonce":              auth.nonce,
+		"iat":                time.Now().Unix(),
+		"exp":                time.Now().Add(time.Hour).Unix(),
+	})
+	p.mu.Lock()
+	token.Header["kid"] = p.tokenKeyID
+	p.mu.Unlock()
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (p *MockOIDCProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.jwksRequests++
+	p.mu.Unlock()
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": mockOIDCKeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// startOIDCLogin asks the api for an authorization url and follows it at the mock provider,
+// returning the code and state the provider redirects back to the app with and the code verifier the app keeps
+func startOIDCLogin(t *testing.T, app *fiber.App, provider string) (string, string, string) {
+	req, err := http.NewRequest("GET", "/api/oidc/"+provider+"/authorize", nil)
+	assert.NoError(t, err)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	authorization := decodeBody[handlers.OIDCAuthorizationResponse](t, resp)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err = client.Get(authorization.AuthorizationURL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+
+	redirect, err := url.Parse(resp.Header.Get("Location"))
+	assert.NoError(t, err)
+	return redirect.Query().Get("code"), redirect.Query().Get("state"), authorization.CodeVerifier
+}
+
+func getUserDisplay(t *testing.T, app *fiber.App, token string) repository.GetUserDisplayRow {
+	resp := protectedJSON(t, app, "GET", "/api/user", token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	return decodeBody[repository.GetUserDisplayRow](t, resp)
+}
+
+func TestOIDCLogin(t *testing.T) {
+	config.ContentBlockedWords = []string{"scamcoin"}
+	t.Cleanup(func() { config.ContentBlockedWords = nil })
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	ctx := context.Background()
+	mock := NewMockOIDCProvider(t, "task-social")
+	config.OIDCProviders = map[string]config.OIDCProvider{
+		"mock": {
+			Issuer:      mock.Issuer(),
+			ClientID:    mock.ClientID,
+			RedirectURL: "http://localhost:8081/oidc/mock/callback",
+		},
+	}
+	t.Cleanup(func() { config.OIDCProviders = map[string]config.OIDCProvider{} })
+
+	t.Run("Configured providers are listed", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/oidc/providers", nil)
+		assert.NoError(t, err)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"mock"}, decodeBody[handlers.OIDCProvidersResponse](t, resp).Providers)
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/oidc/nope/authorize", nil)
+		assert.NoError(t, err)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	var firstToken string
+	t.Run("First login provisions an account", func(t *testing.T) {
+		mock.SetUser(MockOIDCUser{
+			Subject:           "mock-subject-1",
+			Email:             "oidc@example.com",
+			EmailVerified:     true,
+			PreferredUsername: "oidc user",
+		})
+		code, state, verifier := startOIDCLogin(t, app, "mock")
+
+		resp := postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: verifier})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		login := decodeBody[handlers.SuccessfulLoginResponse](t, resp)
+		assert.NotEmpty(t, login.RefreshToken)
+		firstToken = login.Token
+
+		assert.Equal(t, "oidc_user", getUserDisplay(t, app, login.Token).Username)
+
+		resp = protectedJSON(t, app, "GET", "/api/user/identities", login.Token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		identities := decodeBody[[]repository.GetUserIdentitiesRow](t, resp)
+		assert.Len(t, identities, 1)
+		assert.Equal(t, "mock", identities[0].Provider)
+
+		// the callback cannot be replayed
+		resp = postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: verifier})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Second login uses the linked account", func(t *testing.T) {
+		// profile changes at the provider do not create a new account
+		mock.SetUser(MockOIDCUser{
+			Subject:           "mock-subject-1",
+			Email:             "oidc@example.com",
+			EmailVerified:     true,
+			PreferredUsername: "renamed",
+		})
+		code, state, verifier := startOIDCLogin(t, app, "mock")
+
+		resp := postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: verifier})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		login := decodeBody[handlers.SuccessfulLoginResponse](t, resp)
+		assert.Equal(t, getUserDisplay(t, app, firstToken).Username, getUserDisplay(t, app, login.Token).Username)
+	})
+
+	t.Run("Code from another login attempt fails PKCE", func(t *testing.T) {
+		code, _, _ := startOIDCLogin(t, app, "mock")
+		_, otherState, otherVerifier := startOIDCLogin(t, app, "mock")
+
+		resp := postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: otherState, CodeVerifier: otherVerifier})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Callback without the verifier fails", func(t *testing.T) {
+		// someone who got the code and state from the redirect does not know the verifier
+		code, state, verifier := startOIDCLogin(t, app, "mock")
+
+		resp := postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp = postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: "guessed"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		// the attempts do not use up the login of the app that started it
+		resp = postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: verifier})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Verified email links an existing account", func(t *testing.T) {
+		_, err := CreateTestUser(app, "linkuser", "link@example.com", "Password123!@")
+		assert.NoError(t, err)
+		userID, err := querier.GetUserIDByEmail(ctx, "link@example.com")
+		assert.NoError(t, err)
+		assert.NoError(t, querier.SetUserEmailVerified(ctx, userID))
+
+		mock.SetUser(MockOIDCUser{
+			Subject:       "mock-subject-2",
+			Email:         "link@example.com",
+			EmailVerified: true,
+		})
+		code, state, verifier := startOIDCLogin(t, app, "mock")
+
+		resp := postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: verifier})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		login := decodeBody[handlers.SuccessfulLoginResponse](t, resp)
+		assert.Equal(t, "linkuser", getUserDisplay(t, app, login.Token).Username)
+	})
+
+	t.Run("Unverified email does not take over an existing account", func(t *testing.T) {
+		_, err := CreateTestUser(app, "takenuser", "taken@example.com", "Password123!@")
+		assert.NoError(t, err)
+
+		mock.SetUser(MockOIDCUser{
+			Subject:       "mock-subject-3",
+			Email:         "taken@example.com",
+			EmailVerified: false,
+		})
+		code, state, verifier := startOIDCLogin(t, app, "mock")
+
+		resp := postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: verifier})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("Unverified account is not linked", func(t *testing.T) {
+		// the address may have been registered by someone else before its owner logs in with the provider
+		_, err := CreateTestUser(app, "squatter", "squatted@example.com", "Password123!@")
+		assert.NoError(t, err)
+
+		mock.SetUser(MockOIDCUser{
+			Subject:       "mock-subject-7",
+			Email:         "squatted@example.com",
+			EmailVerified: true,
+		})
+		code, state, verifier := startOIDCLogin(t, app, "mock")
+
+		resp := postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: verifier})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("Taken username gets a suffix", func(t *testing.T) {
+		mock.SetUser(MockOIDCUser{
+			Subject:           "mock-subject-4",
+			Email:             "other@example.com",
+			EmailVerified:     true,
+			PreferredUsername: "linkuser",
+		})
+		code, state, verifier := startOIDCLogin(t, app, "mock")
+
+		resp := postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: verifier})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		login := decodeBody[handlers.SuccessfulLoginResponse](t, resp)
+		username := getUserDisplay(t, app, login.Token).Username
+		assert.NotEqual(t, "linkuser", username)
+		assert.Contains(t, username, "linkuser")
+	})
+
+	t.Run("Blocked username falls back to the email", func(t *testing.T) {
+		mock.SetUser(MockOIDCUser{
+			Subject:           "mock-subject-6",
+			Email:             "fallback@example.com",
+			EmailVerified:     true,
+			PreferredUsername: "scamcoin_deals",
+		})
+		code, state, verifier := startOIDCLogin(t, app, "mock")
+
+		resp := postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: verifier})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		login := decodeBody[handlers.SuccessfulLoginResponse](t, resp)
+		assert.Equal(t, "fallback", getUserDisplay(t, app, login.Token).Username)
+	})
+
+	t.Run("Unknown key ids refetch the keys at most once a minute", func(t *testing.T) {
+		mock.SetUser(MockOIDCUser{Subject: "mock-subject-5", Email: "unknownkey@example.com", EmailVerified: true})
+		mock.SetTokenKeyID("made-up-key")
+		defer mock.SetTokenKeyID(mockOIDCKeyID)
+
+		before := mock.JWKSRequests()
+		for range 3 {
+			code, state, verifier := startOIDCLogin(t, app, "mock")
+			resp := postJSON(t, app, "/api/oidc/mock/callback", handlers.OIDCCallbackRequest{Code: code, State: state, CodeVerifier: verifier})
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		}
+		// the first one may be a rotation at the provider
+		assert.Equal(t, 1, mock.JWKSRequests()-before)
+	})
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func createPersonalToken(t *testing.T, app *fiber.App, token string, name string, scopes ...string) handlers.CreatePersonalAccessTokenResponse {
+	resp := protectedJSON(t, app, "POST", "/api/user/tokens", token, handlers.CreatePersonalAccessTokenRequest{
+		Name:   name,
+		Scopes: scopes,
+	})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	return decodeBody[handlers.CreatePersonalAccessTokenResponse](t, resp)
+}
+
+func TestPersonalAccessTokens(t *testing.T) {
+	app := SetupTestApp()
+
+	token, err := CreateTestUser(app, "scriptuser", "script@example.com", "Password123!@")
+	assert.NoError(t, err)
+
+	t.Run("Invalid tokens are rejected at creation", func(t *testing.T) {
+		testCases := []struct {
+			name string
+			body handlers.CreatePersonalAccessTokenRequest
+		}{
+			{name: "Unknown scope", body: handlers.CreatePersonalAccessTokenRequest{Name: "bad", Scopes: []string{"admin"}}},
+			{name: "No scopes", body: handlers.CreatePersonalAccessTokenRequest{Name: "bad"}},
+			{name: "No name", body: handlers.CreatePersonalAccessTokenRequest{Scopes: []string{"clubs:read"}}},
+			{name: "Too long", body: handlers.CreatePersonalAccessTokenRequest{Name: "bad", Scopes: []string{"clubs:read"}, ExpiresInDays: 1000}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				resp := protectedJSON(t, app, "POST", "/api/user/tokens", token, tc.body)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			})
+		}
+	})
+
+	metricsToken := createPersonalToken(t, app, token, "step counter", "metrics:write")
+	clubsToken := createPersonalToken(t, app, token, "club reader", "clubs:read")
+
+	t.Run("Tokens are listed without the secret", func(t *testing.T) {
+		assert.Contains(t, metricsToken.Token, "tsp_")
+
+		resp := protectedJSON(t, app, "GET", "/api/user/tokens", token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		tokens := decodeBody[[]services.PersonalAccessToken](t, resp)
+		assert.Len(t, tokens, 2)
+		for _, pat := range tokens {
+			assert.NotEmpty(t, pat.Name)
+			assert.NotEmpty(t, pat.Scopes)
+		}
+	})
+
+	t.Run("Scopes limit the routes a token can call", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			token    string
+			method   string
+			path     string
+			expected int
+		}{
+			{name: "Read scope allows reading", token: clubsToken.Token, method: "GET", path: "/api/clubs", expected: http.StatusOK},
+			{name: "Read scope does not allow writing", token: clubsToken.Token, method: "POST", path: "/api/club", expected: http.StatusForbidden},
+			{name: "Write scope includes reading", token: metricsToken.Token, method: "GET", path: "/api/user/metrics", expected: http.StatusOK},
+			{name: "Other resources are off limits", token: metricsToken.Token, method: "GET", path: "/api/clubs", expected: http.StatusForbidden},
+			{name: "Metric entries need metrics:write", token: clubsToken.Token, method: "POST", path: "/api/metric/some-metric/entry", expected: http.StatusForbidden},
+			{name: "Sessions are never reachable", token: metricsToken.Token, method: "GET", path: "/api/user/sessions", expected: http.StatusForbidden},
+			{name: "Tokens cannot mint tokens", token: metricsToken.Token, method: "POST", path: "/api/user/tokens", expected: http.StatusForbidden},
+			{name: "Unknown token", token: "tsp_not-a-real-token", method: "GET", path: "/api/clubs", expected: http.StatusUnauthorized},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				resp := protectedJSON(t, app, tc.method, tc.path, tc.token, nil)
+				assert.Equal(t, tc.expected, resp.StatusCode)
+			})
+		}
+
+		// reaches the handler, which fails because the metric does not exist
+		resp := protectedJSON(t, app, "POST", "/api/metric/some-metric/entry", metricsToken.Token, map[string]any{"value": 1})
+		assert.NotEqual(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.NotEqual(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Club write scope does not cover club management", func(t *testing.T) {
+		club, err := CreateTestClub(app, token, "Script Club", nil, true)
+		assert.NoError(t, err)
+		clubPath := "/api/club/" + club.ID
+		writerToken := createPersonalToken(t, app, token, "club poster", "clubs:write")
+
+		testCases := []struct {
+			name   string
+			method string
+			path   string
+			body   any
+		}{
+			{name: "Deleting the club", method: "DELETE", path: clubPath},
+			{name: "Updating the club", method: "PUT", path: clubPath, body: map[string]any{"name": "Renamed"}},
+			{name: "Adjusting points", method: "POST", path: clubPath + "/members/some-user/points", body: map[string]any{"amount": 5}},
+			{name: "Editing the blocklist", method: "PUT", path: clubPath + "/blocklist", body: map[string]any{"term": "spam"}},
+			{name: "Reading held content", method: "GET", path: clubPath + "/held"},
+			{name: "Approving held posts", method: "POST", path: clubPath + "/held/posts/some-post/approve"},
+			{name: "Creating rewards", method: "POST", path: clubPath + "/rewards", body: map[string]any{"name": "Mug"}},
+			{name: "Fulfilling redemptions", method: "POST", path: clubPath + "/redemptions/some-redemption/fulfill"},
+			{name: "Rejecting redemptions", method: "POST", path: clubPath + "/redemptions/some-redemption/reject"},
+			{name: "Reading reports", method: "GET", path: clubPath + "/reports"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				resp := protectedJSON(t, app, tc.method, tc.path, writerToken.Token, tc.body)
+				assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			})
+		}
+
+		resp := protectedJSON(t, app, "GET", clubPath, token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "the club is not deleted")
+		resp = protectedJSON(t, app, "POST", clubPath+"/post", writerToken.Token, map[string]any{"content": "Posted by a script"})
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "members can still post")
+	})
+
+	t.Run("Revoked tokens stop working", func(t *testing.T) {
+		resp := protectedJSON(t, app, "DELETE", "/api/user/tokens/"+clubsToken.ID, token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "GET", "/api/clubs", clubsToken.Token, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "DELETE", "/api/user/tokens/"+clubsToken.ID, token, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
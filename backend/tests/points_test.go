@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointsLedger(t *testing.T) {
+	ctx := context.Background()
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	password := "Password123!@"
+
+	aliceToken, err := CreateTestUser(app, "alice", "alice@example.com", password)
+	assert.NoError(t, err)
+	bobToken, err := CreateTestUser(app, "bob", "bob@example.com", password)
+	assert.NoError(t, err)
+	carolToken, err := CreateTestUser(app, "carol", "carol@example.com", password)
+	assert.NoError(t, err)
+	outsiderToken, err := CreateTestUser(app, "outsider", "outsider@example.com", password)
+	assert.NoError(t, err)
+	aliceID, err := querier.GetUserIDByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+	bobID, err := querier.GetUserIDByEmail(ctx, "bob@example.com")
+	assert.NoError(t, err)
+	carolID, err := querier.GetUserIDByEmail(ctx, "carol@example.com")
+	assert.NoError(t, err)
+	outsiderID, err := querier.GetUserIDByEmail(ctx, "outsider@example.com")
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, aliceToken, "Running Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	for _, token := range []string{bobToken, carolToken} {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	resp := protectedJSON(t, app, "POST", "/api/metric", aliceToken, repository.CreateMetricParams{
+		ClubID:      club.ID,
+		Title:       "Weekly miles",
+		Description: "How far you ran this week",
+		Interval:    "168h",
+		StartAt:     time.Now(),
+		Unit:        "miles",
+	})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	metricID := decodeBody[handlers.CreatedResponse](t, resp).ID
+	// the scheduler starts a new instance of the metric when the last one is due
+	newInstance := func() string {
+		instanceID := fmt.Sprintf("instance-%d", time.Now().UnixNano())
+		assert.NoError(t, querier.CreateMetricInstance(ctx, repository.CreateMetricInstanceParams{
+			ID:       instanceID,
+			MetricID: metricID,
+			DueAt:    time.Now().Add(time.Hour),
+		}))
+		return instanceID
+	}
+
+	enter := func(token string, value float64) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/metric/%s/entry", metricID), token, repository.CreateMetricEntryParams{Value: value})
+	}
+	pointsURL := func(userID string) string {
+		return fmt.Sprintf("/api/club/%s/members/%s/points", club.ID, userID)
+	}
+	history := func(token string, userID string, query string) services.PointHistory {
+		resp := protectedJSON(t, app, "GET", pointsURL(userID)+query, token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeBody[services.PointHistory](t, resp)
+	}
+	adjust := func(token string, userID string, amount float64, note string) *http.Response {
+		return protectedJSON(t, app, "POST", pointsURL(userID), token, services.AdjustPointsRequest{Amount: amount, Note: note})
+	}
+	points := func(userID string) float64 {
+		p, err := querier.GetClubPoints(ctx, repository.GetClubPointsParams{UserID: userID, ClubID: club.ID})
+		assert.NoError(t, err)
+		return p
+	}
+
+	t.Run("Metric entries earn points", func(t *testing.T) {
+		firstInstance := newInstance()
+		assert.Equal(t, http.StatusCreated, enter(bobToken, 12).StatusCode)
+		assert.Equal(t, http.StatusConflict, enter(bobToken, 14).StatusCode)
+		assert.Equal(t, http.StatusForbidden, enter(outsiderToken, 3).StatusCode)
+		resp := protectedJSON(t, app, "POST", "/api/metric/missing/entry", bobToken, repository.CreateMetricEntryParams{Value: 1})
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		bob := history(bobToken, bobID, "")
+		assert.Equal(t, config.MetricEntryPoints, bob.Points)
+		assert.Equal(t, int64(1), bob.Streak)
+		if assert.Len(t, bob.Entries, 1) {
+			assert.Equal(t, services.PointsMetricEntry, bob.Entries[0].Reason)
+			assert.Equal(t, &firstInstance, bob.Entries[0].ReferenceID)
+		}
+
+		// bob keeps the streak going, carol starts one
+		newInstance()
+		assert.Equal(t, http.StatusCreated, enter(bobToken, 15).StatusCode)
+		assert.Equal(t, http.StatusCreated, enter(carolToken, 8).StatusCode)
+
+		bob = history(bobToken, bobID, "")
+		assert.Equal(t, 2*config.MetricEntryPoints+config.StreakBonusPoints, bob.Points)
+		assert.Equal(t, int64(2), bob.Streak)
+		if assert.Len(t, bob.Entries, 3) {
+			assert.Equal(t, services.PointsStreakBonus, bob.Entries[0].Reason)
+			assert.Equal(t, bob.Points, bob.Entries[0].Balance)
+			assert.Equal(t, services.PointsMetricEntry, bob.Entries[1].Reason)
+		}
+		carol := history(carolToken, carolID, "")
+		assert.Equal(t, config.MetricEntryPoints, carol.Points)
+		assert.Equal(t, int64(1), carol.Streak)
+
+		// skipping an instance breaks the streak
+		newInstance()
+		newInstance()
+		assert.Equal(t, http.StatusCreated, enter(bobToken, 9).StatusCode)
+		bob = history(bobToken, bobID, "")
+		assert.Equal(t, 3*config.MetricEntryPoints+config.StreakBonusPoints, bob.Points)
+		assert.Equal(t, int64(1), bob.Streak)
+	})
+
+	t.Run("Moderator adjustments", func(t *testing.T) {
+		before := points(carolID)
+		assert.Equal(t, http.StatusForbidden, adjust(bobToken, carolID, 5, "For helping out").StatusCode)
+		assert.Equal(t, http.StatusForbidden, adjust(aliceToken, aliceID, 5, "For running the club").StatusCode)
+		assert.Equal(t, http.StatusBadRequest, adjust(aliceToken, carolID, 0, "Nothing").StatusCode)
+		assert.Equal(t, http.StatusBadRequest, adjust(aliceToken, carolID, 5, "").StatusCode)
+		assert.Equal(t, http.StatusNotFound, adjust(aliceToken, outsiderID, 5, "Not a member").StatusCode)
+		assert.Equal(t, http.StatusConflict, adjust(aliceToken, carolID, -before-1, "Too much").StatusCode)
+		assert.Equal(t, before, points(carolID))
+
+		assert.Equal(t, http.StatusOK, adjust(aliceToken, carolID, 20, "Organised the group run").StatusCode)
+		assert.Equal(t, http.StatusOK, adjust(aliceToken, carolID, -5, "Entered the wrong distance").StatusCode)
+		assert.Equal(t, before+15, points(carolID))
+
+		carol := history(carolToken, carolID, "")
+		if assert.Len(t, carol.Entries, 3) {
+			assert.Equal(t, -5.0, carol.Entries[0].Amount)
+			assert.Equal(t, services.PointsAdjustment, carol.Entries[0].Reason)
+			assert.Equal(t, StringToPtr("Entered the wrong distance"), carol.Entries[0].Note)
+			assert.Equal(t, StringToPtr("alice"), carol.Entries[0].ActorUsername)
+			assert.Equal(t, 20.0, carol.Entries[1].Amount)
+		}
+	})
+
+	t.Run("Viewing the history", func(t *testing.T) {
+		resp := protectedJSON(t, app, "GET", pointsURL(bobID), carolToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "GET", pointsURL(bobID), outsiderToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "GET", pointsURL(outsiderID), aliceToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		all := history(aliceToken, bobID, "")
+		assert.Len(t, all.Entries, 4)
+		page := history(aliceToken, bobID, "?limit=2&offset=1")
+		if assert.Len(t, page.Entries, 2) {
+			assert.Equal(t, all.Entries[1].ID, page.Entries[0].ID)
+			assert.Equal(t, all.Entries[2].ID, page.Entries[1].ID)
+		}
+	})
+
+	t.Run("Rebuilding balances", func(t *testing.T) {
+		fixed, err := services.RebuildPointBalances(ctx, querier)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), fixed)
+
+		// a balance that drifted from the ledger
+		expected := points(bobID)
+		_, err = querier.AddClubPoints(ctx, repository.AddClubPointsParams{Points: 100, UserID: bobID, ClubID: club.ID})
+		assert.NoError(t, err)
+		fixed, err = services.RebuildPointBalances(ctx, querier)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), fixed)
+		assert.Equal(t, expected, points(bobID))
+	})
+
+	t.Run("Leaving forfeits the points", func(t *testing.T) {
+		balance := points(bobID)
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/leave", club.ID), bobToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		ledger, err := querier.GetPointLedger(ctx, repository.GetPointLedgerParams{ClubID: club.ID, UserID: bobID})
+		assert.NoError(t, err)
+		if assert.NotEmpty(t, ledger) {
+			last := ledger[len(ledger)-1]
+			assert.Equal(t, services.PointsLeftClub, last.Reason)
+			assert.Equal(t, -balance, last.Amount)
+			assert.Equal(t, 0.0, last.Balance)
+		}
+
+		// rejoining starts from nothing, which is what the ledger adds up to
+		resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), bobToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 0.0, history(bobToken, bobID, "").Points)
+		fixed, err := services.RebuildPointBalances(ctx, querier)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), fixed)
+	})
+}
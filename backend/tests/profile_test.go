@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func getProfile(t *testing.T, app *fiber.App, token string, userID string) services.UserProfile {
+	resp := protectedJSON(t, app, "GET", "/api/user/"+userID+"/profile", token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	return decodeBody[services.UserProfile](t, resp)
+}
+
+func updateProfile(t *testing.T, app *fiber.App, token string, update services.ProfileUpdate) *http.Response {
+	return protectedJSON(t, app, "PUT", "/api/user/profile", token, update)
+}
+
+func TestUserProfile(t *testing.T) {
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	ctx := context.Background()
+
+	ownerToken, err := CreateTestUser(app, "profileowner", "profile@example.com", "Password123!@")
+	assert.NoError(t, err)
+	viewerToken, err := CreateTestUser(app, "profileviewer", "viewer@example.com", "Password123!@")
+	assert.NoError(t, err)
+	ownerID, err := querier.GetUserIDByEmail(ctx, "profile@example.com")
+	assert.NoError(t, err)
+	viewerID, err := querier.GetUserIDByEmail(ctx, "viewer@example.com")
+	assert.NoError(t, err)
+
+	publicClub, err := CreateTestClub(app, ownerToken, "Public Club", StringToPtr(""), true)
+	assert.NoError(t, err)
+	_, err = CreateTestClub(app, ownerToken, "Secret Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", publicClub.ID), ownerToken, services.CreateItemRequest{Name: "Tent"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	t.Run("Invalid updates are rejected", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			update services.ProfileUpdate
+		}{
+			{name: "Bio too long", update: services.ProfileUpdate{Bio: StringToPtr(string(make([]byte, 501)))}},
+			{name: "Unknown visibility", update: services.ProfileUpdate{ProfileVisibility: StringToPtr("everyone")}},
+			{name: "Script link", update: services.ProfileUpdate{Links: []services.ProfileLink{{Label: "x", URL: "javascript:alert(1)"}}}},
+			{name: "Link without label", update: services.ProfileUpdate{Links: []services.ProfileLink{{URL: "https://example.com"}}}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, http.StatusBadRequest, updateProfile(t, app, ownerToken, tc.update).StatusCode)
+			})
+		}
+	})
+
+	t.Run("Public profile shows everything", func(t *testing.T) {
+		resp := updateProfile(t, app, ownerToken, services.ProfileUpdate{
+			DisplayName: StringToPtr("Profile Owner"),
+			Bio:         StringToPtr("Runs, reads and trades tents."),
+			Location:    StringToPtr("Portland"),
+			Pronouns:    StringToPtr("they/them"),
+			Links: []services.ProfileLink{
+				{Label: "Blog", URL: "https://example.com/blog"},
+				{Label: "Code", URL: "https://example.com/code"},
+			},
+		})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		profile := getProfile(t, app, viewerToken, ownerID)
+		assert.True(t, profile.Visible)
+		assert.Equal(t, "profileowner", profile.Username)
+		assert.Equal(t, "Profile Owner", *profile.DisplayName)
+		assert.Equal(t, "they/them", *profile.Pronouns)
+		assert.Equal(t, []services.ProfileLink{
+			{Label: "Blog", URL: "https://example.com/blog"},
+			{Label: "Code", URL: "https://example.com/code"},
+		}, profile.Links)
+		// private clubs are counted but not named
+		if assert.Len(t, profile.Clubs, 1) {
+			assert.Equal(t, "Public Club", profile.Clubs[0].Name)
+		}
+		if assert.NotNil(t, profile.Stats) {
+			assert.Equal(t, int64(2), profile.Stats.ClubCount)
+		}
+		assert.Len(t, profile.Items, 1)
+		// settings are only shown to the owner
+		assert.Nil(t, profile.Privacy)
+
+		assert.Equal(t, "Profile Owner", *getUserDisplay(t, app, ownerToken).DisplayName)
+	})
+
+	t.Run("Hidden sections are left out", func(t *testing.T) {
+		hide := false
+		resp := updateProfile(t, app, ownerToken, services.ProfileUpdate{ShowClubs: &hide, ShowItems: &hide})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		profile := getProfile(t, app, viewerToken, ownerID)
+		assert.Empty(t, profile.Clubs)
+		assert.Empty(t, profile.Items)
+		assert.NotNil(t, profile.Stats)
+		// earlier fields were not touched by the partial update
+		assert.Equal(t, "Portland", *profile.Location)
+
+		// the owner still sees them together with the settings
+		own := getProfile(t, app, ownerToken, ownerID)
+		assert.Len(t, own.Clubs, 1)
+		if assert.NotNil(t, own.Privacy) {
+			assert.False(t, own.Privacy.ShowClubs)
+			assert.Equal(t, services.ProfileVisibilityPublic, own.Privacy.ProfileVisibility)
+		}
+	})
+
+	t.Run("Friends only profile", func(t *testing.T) {
+		resp := updateProfile(t, app, ownerToken, services.ProfileUpdate{ProfileVisibility: StringToPtr(services.ProfileVisibilityFriends)})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		profile := getProfile(t, app, viewerToken, ownerID)
+		assert.False(t, profile.Visible)
+		assert.Equal(t, "profileowner", profile.Username)
+		assert.Nil(t, profile.Bio)
+		assert.Nil(t, profile.Stats)
+
+		params := repository.CreateFriendParams{UserID: ownerID, FriendID: viewerID}
+		if params.UserID > params.FriendID {
+			params.UserID, params.FriendID = params.FriendID, params.UserID
+		}
+		assert.NoError(t, querier.CreateFriend(ctx, params))
+		assert.True(t, getProfile(t, app, viewerToken, ownerID).Visible)
+	})
+
+	t.Run("Private profile", func(t *testing.T) {
+		resp := updateProfile(t, app, ownerToken, services.ProfileUpdate{
+			ProfileVisibility: StringToPtr(services.ProfileVisibilityPrivate),
+			Links:             []services.ProfileLink{},
+		})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		assert.False(t, getProfile(t, app, viewerToken, ownerID).Visible)
+		own := getProfile(t, app, ownerToken, ownerID)
+		assert.True(t, own.Visible)
+		assert.Empty(t, own.Links)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		resp := protectedJSON(t, app, "GET", "/api/user/00000000-0000-0000-0000-000000000000/profile", viewerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestUserProfileItems(t *testing.T) {
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	ctx := context.Background()
+
+	ownerToken, err := CreateTestUser(app, "itemowner", "itemowner@example.com", "Password123!@")
+	assert.NoError(t, err)
+	viewerToken, err := CreateTestUser(app, "itemviewer", "itemviewer@example.com", "Password123!@")
+	assert.NoError(t, err)
+	buyerToken, err := CreateTestUser(app, "itembuyer", "itembuyer@example.com", "Password123!@")
+	assert.NoError(t, err)
+	ownerID, err := querier.GetUserIDByEmail(ctx, "itemowner@example.com")
+	assert.NoError(t, err)
+	buyerID, err := querier.GetUserIDByEmail(ctx, "itembuyer@example.com")
+	assert.NoError(t, err)
+
+	publicClub, err := CreateTestClub(app, ownerToken, "Open Market", StringToPtr(""), true)
+	assert.NoError(t, err)
+	secretClub, err := CreateTestClub(app, ownerToken, "Hidden Market", StringToPtr(""), false)
+	assert.NoError(t, err)
+	createItem := func(clubID string, name string) string {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", clubID), ownerToken, services.CreateItemRequest{Name: name})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		return decodeBody[handlers.CreatedResponse](t, resp).ID
+	}
+	createItem(publicClub.ID, "Tent")
+	createItem(secretClub.ID, "Key")
+	lamp := createItem(publicClub.ID, "Lamp")
+	bike := createItem(publicClub.ID, "Bike")
+
+	until := time.Now().Add(time.Hour)
+	assert.NoError(t, querier.ReserveItem(ctx, repository.ReserveItemParams{ID: lamp, ReservedFor: &buyerID, ReservedUntil: &until}))
+	assert.NoError(t, querier.SetItemListingType(ctx, repository.SetItemListingTypeParams{ID: bike, ListingType: services.ListingAuction}))
+
+	itemNames := func(token string) []string {
+		names := []string{}
+		for _, item := range getProfile(t, app, token, ownerID).Items {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	t.Run("Other users see what they could trade for", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"Tent"}, itemNames(viewerToken))
+	})
+
+	t.Run("Reserved items are shown to the user they are reserved for", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"Tent", "Lamp"}, itemNames(buyerToken))
+	})
+
+	t.Run("The owner sees every trade listing", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"Tent", "Key", "Lamp"}, itemNames(ownerToken))
+	})
+}
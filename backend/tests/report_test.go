@@ -0,0 +1,264 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReports(t *testing.T) {
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	ctx := context.Background()
+	password := "Password123!@"
+
+	ownerToken, err := CreateTestUser(app, "reportowner", "reportowner@example.com", password)
+	assert.NoError(t, err)
+	authorToken, err := CreateTestUser(app, "spammer", "spammer@example.com", password)
+	assert.NoError(t, err)
+	reporterToken, err := CreateTestUser(app, "reporter", "reporter@example.com", password)
+	assert.NoError(t, err)
+	secondReporterToken, err := CreateTestUser(app, "reporter2", "reporter2@example.com", password)
+	assert.NoError(t, err)
+	outsiderToken, err := CreateTestUser(app, "outsider", "outsider@example.com", password)
+	assert.NoError(t, err)
+	adminToken, err := CreateTestUser(app, "reportadmin", "reportadmin@example.com", password)
+	assert.NoError(t, err)
+	grantAdminRoles(t, querier, "reportadmin@example.com")
+	authorID, err := querier.GetUserIDByEmail(ctx, "spammer@example.com")
+	assert.NoError(t, err)
+	ownerID, err := querier.GetUserIDByEmail(ctx, "reportowner@example.com")
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, ownerToken, "Reported Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	for _, token := range []string{authorToken, reporterToken, secondReporterToken} {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	postID := createTestPost(t, app, authorToken, club.ID, "buy cheap followers")
+	otherPostID := createTestPost(t, app, authorToken, club.ID, "more spam")
+
+	report := func(token string, targetType string, targetID string) *http.Response {
+		return protectedJSON(t, app, "POST", "/api/reports", token, services.CreateReportRequest{
+			TargetType: targetType,
+			TargetID:   targetID,
+			Category:   "spam",
+			Comment:    "advertising",
+		})
+	}
+
+	var postReportID string
+	t.Run("Reports about the same target are merged", func(t *testing.T) {
+		resp := report(reporterToken, services.AdminTargetPost, postID)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		postReportID = decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		resp = report(secondReporterToken, services.AdminTargetPost, postID)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, postReportID, decodeBody[handlers.CreatedResponse](t, resp).ID)
+
+		resp = report(reporterToken, services.AdminTargetPost, postID)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "GET", "/api/reports", reporterToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, decodeBody[[]repository.GetReportsByReporterRow](t, resp), 1)
+	})
+
+	t.Run("Invalid reports are rejected", func(t *testing.T) {
+		resp := report(reporterToken, "comment", postID)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "POST", "/api/reports", reporterToken, services.CreateReportRequest{
+			TargetType: services.AdminTargetPost,
+			TargetID:   otherPostID,
+			Category:   "boring",
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		// posts of private clubs are not visible to outsiders
+		resp = report(outsiderToken, services.AdminTargetPost, postID)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp = report(reporterToken, services.AdminTargetUser, "missing")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	var userReportID string
+	t.Run("Reports are routed to club moderators or admins", func(t *testing.T) {
+		resp := report(reporterToken, services.AdminTargetUser, authorID)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		userReportID = decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/reports", club.ID), ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		queue := decodeBody[[]repository.GetReportQueueRow](t, resp)
+		if assert.Len(t, queue, 1) {
+			assert.Equal(t, postReportID, queue[0].ID)
+			assert.Equal(t, int64(2), queue[0].SubmissionCount)
+		}
+
+		resp = protectedJSON(t, app, "GET", "/api/admin/reports", adminToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		queue = decodeBody[[]repository.GetReportQueueRow](t, resp)
+		if assert.Len(t, queue, 1) {
+			assert.Equal(t, userReportID, queue[0].ID)
+		}
+
+		resp = protectedJSON(t, app, "GET", "/api/admin/reports?all=true", adminToken, nil)
+		assert.Len(t, decodeBody[[]repository.GetReportQueueRow](t, resp), 2)
+	})
+
+	t.Run("Only moderators and admins can handle reports", func(t *testing.T) {
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/reports", club.ID), reporterToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "GET", "/api/reports/"+postReportID, reporterToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = protectedJSON(t, app, "GET", "/api/reports/"+userReportID, ownerToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Moderators triage and action reports", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/reports/"+postReportID+"/triage", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "POST", "/api/reports/"+postReportID+"/triage", ownerToken, nil)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "GET", "/api/reports/"+postReportID, ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		details := decodeBody[services.ReportDetails](t, resp)
+		assert.Equal(t, services.ReportStatusTriaged, details.Report.Status)
+		assert.Len(t, details.Submissions, 2)
+
+		// disabling users is not an action for posts
+		resp = protectedJSON(t, app, "POST", "/api/reports/"+postReportID+"/resolve", ownerToken, services.ReportResolution{
+			Status: services.ReportStatusActioned,
+			Action: services.ReportActionDisableUser,
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "POST", "/api/reports/"+postReportID+"/resolve", ownerToken, services.ReportResolution{
+			Status: services.ReportStatusActioned,
+			Action: services.ReportActionDeletePost,
+			Note:   "spam",
+		})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_, err := querier.GetClubPost(ctx, postID)
+		assert.Error(t, err)
+
+		resp = protectedJSON(t, app, "GET", "/api/reports/"+postReportID, ownerToken, nil)
+		details = decodeBody[services.ReportDetails](t, resp)
+		if assert.NotNil(t, details.Action) && assert.NotNil(t, details.Report.ResolutionActionID) {
+			assert.Equal(t, *details.Report.ResolutionActionID, details.Action.ID)
+			assert.Equal(t, services.ReportActionDeletePost, details.Action.Action)
+			assert.Equal(t, postID, details.Action.TargetID)
+			assert.Equal(t, ownerID, *details.Action.ModeratorID)
+			assert.False(t, details.Action.AsAdmin)
+		}
+
+		resp = protectedJSON(t, app, "POST", "/api/reports/"+postReportID+"/resolve", ownerToken, services.ReportResolution{
+			Status: services.ReportStatusDismissed,
+		})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "GET", "/api/reports", secondReporterToken, nil)
+		reports := decodeBody[[]repository.GetReportsByReporterRow](t, resp)
+		if assert.Len(t, reports, 1) {
+			assert.Equal(t, services.ReportStatusActioned, reports[0].Status)
+		}
+	})
+
+	t.Run("Admin actions are audited with the report", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", "/api/reports/"+userReportID+"/resolve", adminToken, services.ReportResolution{
+			Status: services.ReportStatusActioned,
+			Action: services.ReportActionDisableUser,
+			Note:   "repeated spam",
+		})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, http.StatusUnauthorized, getUserStatus(t, app, authorToken))
+
+		resp = protectedJSON(t, app, "GET", "/api/admin/audit-log?target_id="+authorID, adminToken, nil)
+		entries := decodeBody[[]repository.GetAdminAuditLogRow](t, resp)
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, services.AdminActionDisableUser, entries[0].Action)
+			assert.Equal(t, "repeated spam", entries[0].Reason)
+			if assert.NotNil(t, entries[0].ReportID) {
+				assert.Equal(t, userReportID, *entries[0].ReportID)
+			}
+		}
+	})
+
+	t.Run("A failed action leaves the report open", func(t *testing.T) {
+		ownerPostID := createTestPost(t, app, ownerToken, club.ID, "house rules")
+		resp := report(reporterToken, services.AdminTargetPost, ownerPostID)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		reportID := decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		// the owner cannot be removed from their own club
+		resp = protectedJSON(t, app, "POST", "/api/reports/"+reportID+"/resolve", ownerToken, services.ReportResolution{
+			Status: services.ReportStatusActioned,
+			Action: services.ReportActionRemoveMember,
+		})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "GET", "/api/reports/"+reportID, ownerToken, nil)
+		details := decodeBody[services.ReportDetails](t, resp)
+		assert.Equal(t, services.ReportStatusOpen, details.Report.Status)
+		assert.Nil(t, details.Report.ResolutionActionID)
+		assert.Nil(t, details.Action)
+	})
+
+	t.Run("Deleting a reported item deletes its photos", func(t *testing.T) {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", club.ID), secondReporterToken, services.CreateItemRequest{Name: "Counterfeit watch"})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		itemID := decodeBody[handlers.CreatedResponse](t, resp).ID
+		photo, err := os.ReadFile("test_assets/testbanner.jpg")
+		assert.NoError(t, err)
+		resp = uploadItemImage(t, app, secondReporterToken, itemID, photo)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		image := decodeBody[repository.ItemImage](t, resp)
+
+		resp = report(reporterToken, services.AdminTargetItem, itemID)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		reportID := decodeBody[handlers.CreatedResponse](t, resp).ID
+		resp = protectedJSON(t, app, "POST", "/api/reports/"+reportID+"/resolve", ownerToken, services.ReportResolution{
+			Status: services.ReportStatusActioned,
+			Action: services.ReportActionDeleteItem,
+		})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err = querier.GetItem(ctx, itemID)
+		assert.Error(t, err)
+		assert.NoFileExists(t, assetPath(image.Url))
+		assert.NoFileExists(t, assetPath(image.ThumbnailUrl))
+	})
+
+	t.Run("Reports can be dismissed and reopened by new reports", func(t *testing.T) {
+		resp := report(reporterToken, services.AdminTargetPost, otherPostID)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		firstID := decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		resp = protectedJSON(t, app, "POST", "/api/reports/"+firstID+"/resolve", ownerToken, services.ReportResolution{
+			Status: services.ReportStatusDismissed,
+			Action: services.ReportActionDeletePost,
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp = protectedJSON(t, app, "POST", "/api/reports/"+firstID+"/resolve", ownerToken, services.ReportResolution{
+			Status: services.ReportStatusDismissed,
+		})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_, err := querier.GetClubPost(ctx, otherPostID)
+		assert.NoError(t, err)
+
+		// the same reporter may report again once the earlier case is closed
+		resp = report(reporterToken, services.AdminTargetPost, otherPostID)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.NotEqual(t, firstID, decodeBody[handlers.CreatedResponse](t, resp).ID)
+	})
+}
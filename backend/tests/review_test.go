@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTradeReviews(t *testing.T) {
+	ctx := context.Background()
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	password := "Password123!@"
+
+	aliceToken, err := CreateTestUser(app, "alice", "alice@example.com", password)
+	assert.NoError(t, err)
+	bobToken, err := CreateTestUser(app, "bob", "bob@example.com", password)
+	assert.NoError(t, err)
+	carolToken, err := CreateTestUser(app, "carol", "carol@example.com", password)
+	assert.NoError(t, err)
+	aliceID, err := querier.GetUserIDByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+	bobID, err := querier.GetUserIDByEmail(ctx, "bob@example.com")
+	assert.NoError(t, err)
+	carolID, err := querier.GetUserIDByEmail(ctx, "carol@example.com")
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, aliceToken, "Swap Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	for _, token := range []string{bobToken, carolToken} {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	lamp := createTestClubItem(t, app, aliceToken, club.ID, "Lamp")
+	chair := createTestClubItem(t, app, aliceToken, club.ID, "Chair")
+	guitar := createTestClubItem(t, app, bobToken, club.ID, "Guitar")
+	bike := createTestClubItem(t, app, carolToken, club.ID, "Bike")
+
+	propose := func(token string, offered string, requested string) string {
+		resp := protectedJSON(t, app, "POST", "/api/marketplace/trades", token, services.TradeRequest{
+			OfferedItemIDs:   []string{offered},
+			RequestedItemIDs: []string{requested},
+		})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		return decodeBody[handlers.CreatedResponse](t, resp).ID
+	}
+	review := func(token string, tradeID string, req services.TradeReviewRequest) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/trades/%s/review", tradeID), token, req)
+	}
+
+	lampForGuitar := propose(aliceToken, lamp, guitar)
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/trades/%s/accept", lampForGuitar), bobToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	chairForBike := propose(aliceToken, chair, bike)
+
+	t.Run("Invalid reviews", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			token    string
+			tradeID  string
+			req      services.TradeReviewRequest
+			expected int
+		}{
+			{name: "Pending trade", token: aliceToken, tradeID: chairForBike, req: services.TradeReviewRequest{Rating: 4}, expected: http.StatusConflict},
+			{name: "Rating too low", token: aliceToken, tradeID: lampForGuitar, req: services.TradeReviewRequest{Rating: 0}, expected: http.StatusBadRequest},
+			{name: "Rating too high", token: aliceToken, tradeID: lampForGuitar, req: services.TradeReviewRequest{Rating: 6}, expected: http.StatusBadRequest},
+			{
+				name:     "Comment too long",
+				token:    aliceToken,
+				tradeID:  lampForGuitar,
+				req:      services.TradeReviewRequest{Rating: 4, Comment: StringToPtr(strings.Repeat("a", config.MaxReviewCommentLength+1))},
+				expected: http.StatusBadRequest,
+			},
+			{name: "Not a party of the trade", token: carolToken, tradeID: lampForGuitar, req: services.TradeReviewRequest{Rating: 4}, expected: http.StatusNotFound},
+			{name: "Unknown trade", token: aliceToken, tradeID: "missing", req: services.TradeReviewRequest{Rating: 4}, expected: http.StatusNotFound},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.expected, review(tc.token, tc.tradeID, tc.req).StatusCode)
+			})
+		}
+	})
+
+	t.Run("Each party reviews once", func(t *testing.T) {
+		resp := review(aliceToken, lampForGuitar, services.TradeReviewRequest{Rating: 2, Comment: StringToPtr("  Late for the pickup  ")})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp = review(aliceToken, lampForGuitar, services.TradeReviewRequest{Rating: 5})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		resp = review(bobToken, lampForGuitar, services.TradeReviewRequest{Rating: 5, Comment: StringToPtr("")})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp = protectedJSON(t, app, "GET", "/api/marketplace/trades/"+lampForGuitar, bobToken, nil)
+		trade := decodeBody[services.TradeDetails](t, resp)
+		if assert.Len(t, trade.Reviews, 2) {
+			assert.Equal(t, aliceID, trade.Reviews[0].ReviewerID)
+			assert.Equal(t, bobID, trade.Reviews[0].RevieweeID)
+			assert.Equal(t, int64(2), trade.Reviews[0].Rating)
+			assert.Equal(t, StringToPtr("Late for the pickup"), trade.Reviews[0].Comment)
+			assert.Equal(t, aliceID, trade.Reviews[1].RevieweeID)
+			assert.Nil(t, trade.Reviews[1].Comment)
+		}
+
+		resp = protectedJSON(t, app, "GET", "/api/marketplace/trades/"+chairForBike, carolToken, nil)
+		assert.Empty(t, decodeBody[services.TradeDetails](t, resp).Reviews)
+	})
+
+	t.Run("Reputation", func(t *testing.T) {
+		bob := getProfile(t, app, carolToken, bobID)
+		assert.Equal(t, int64(1), bob.Reputation.ReviewCount)
+		assert.Equal(t, FloatToPtr(2), bob.Reputation.Score)
+		carol := getProfile(t, app, bobToken, carolID)
+		assert.Equal(t, int64(0), carol.Reputation.ReviewCount)
+		assert.Nil(t, carol.Reputation.Score)
+
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/user/%s/reviews", bobID), carolToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		reviews := decodeBody[[]repository.GetUserReviewsRow](t, resp)
+		if assert.Len(t, reviews, 1) {
+			assert.Equal(t, "alice", reviews[0].ReviewerUsername)
+			assert.Equal(t, int64(2), reviews[0].Rating)
+		}
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/user/%s/reviews", carolID), bobToken, nil)
+		assert.Empty(t, decodeBody[[]repository.GetUserReviewsRow](t, resp))
+	})
+
+	t.Run("Item owners show their reputation", func(t *testing.T) {
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/items", club.ID), carolToken, nil)
+		items := decodeBody[[]services.ClubMarketplaceItem](t, resp)
+		assert.Len(t, items, 4)
+		for _, item := range items {
+			switch item.OwnerID {
+			case aliceID:
+				assert.Equal(t, FloatToPtr(5), item.OwnerReputation, item.Name)
+				assert.Equal(t, int64(1), item.OwnerReviewCount, item.Name)
+			case bobID:
+				assert.Equal(t, FloatToPtr(2), item.OwnerReputation, item.Name)
+			case carolID:
+				assert.Nil(t, item.OwnerReputation, item.Name)
+				assert.Equal(t, int64(0), item.OwnerReviewCount, item.Name)
+			}
+		}
+
+		resp = protectedJSON(t, app, "GET", "/api/marketplace/search?q=lamp", carolToken, nil)
+		results := decodeBody[[]services.MarketplaceSearchItem](t, resp)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, bobID, results[0].OwnerID)
+			assert.Equal(t, FloatToPtr(2), results[0].OwnerReputation)
+		}
+	})
+
+	t.Run("Club minimum listing reputation", func(t *testing.T) {
+		setMinimum := func(token string, minimum float64) int {
+			resp := protectedJSON(t, app, "PUT", "/api/club/"+club.ID, token, handlers.UpdateClubRequest{MinListingReputation: &minimum})
+			return resp.StatusCode
+		}
+		createItem := func(token string, name string) int {
+			resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", club.ID), token, services.CreateItemRequest{Name: name})
+			return resp.StatusCode
+		}
+
+		assert.Equal(t, http.StatusBadRequest, setMinimum(aliceToken, -1))
+		assert.Equal(t, http.StatusBadRequest, setMinimum(aliceToken, 6))
+		assert.Equal(t, http.StatusInternalServerError, setMinimum(bobToken, 3))
+		assert.Equal(t, http.StatusOK, setMinimum(aliceToken, 3))
+
+		assert.Equal(t, http.StatusForbidden, createItem(bobToken, "Amp"))
+		assert.Equal(t, http.StatusCreated, createItem(aliceToken, "Desk"))
+		// members who were never reviewed can still list
+		assert.Equal(t, http.StatusCreated, createItem(carolToken, "Helmet"))
+
+		assert.Equal(t, http.StatusOK, setMinimum(aliceToken, 0))
+		assert.Equal(t, http.StatusCreated, createItem(bobToken, "Amp"))
+	})
+}
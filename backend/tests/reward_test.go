@@ -0,0 +1,268 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointShop(t *testing.T) {
+	ctx := context.Background()
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	password := "Password123!@"
+
+	aliceToken, err := CreateTestUser(app, "alice", "alice@example.com", password)
+	assert.NoError(t, err)
+	bobToken, err := CreateTestUser(app, "bob", "bob@example.com", password)
+	assert.NoError(t, err)
+	carolToken, err := CreateTestUser(app, "carol", "carol@example.com", password)
+	assert.NoError(t, err)
+	outsiderToken, err := CreateTestUser(app, "outsider", "outsider@example.com", password)
+	assert.NoError(t, err)
+	bobID, err := querier.GetUserIDByEmail(ctx, "bob@example.com")
+	assert.NoError(t, err)
+	carolID, err := querier.GetUserIDByEmail(ctx, "carol@example.com")
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, aliceToken, "Reading Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	for _, token := range []string{bobToken, carolToken} {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	points := func(userID string) float64 {
+		p, err := querier.GetClubPoints(ctx, repository.GetClubPointsParams{UserID: userID, ClubID: club.ID})
+		assert.NoError(t, err)
+		return p
+	}
+	// points only change through the ledger, the owner adjusts them
+	setPoints := func(userID string, target float64) {
+		req := services.AdjustPointsRequest{Amount: target - points(userID), Note: "Starting points"}
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/members/%s/points", club.ID, userID), aliceToken, req)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	createReward := func(token string, req services.CreateRewardRequest) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/rewards", club.ID), token, req)
+	}
+	updateReward := func(token string, rewardID string, req services.UpdateRewardRequest) *http.Response {
+		return protectedJSON(t, app, "PUT", fmt.Sprintf("/api/club/%s/rewards/%s", club.ID, rewardID), token, req)
+	}
+	rewards := func(token string) []repository.ClubReward {
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/rewards", club.ID), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeBody[[]repository.ClubReward](t, resp)
+	}
+	redeem := func(token string, rewardID string, note *string) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/rewards/%s/redeem", club.ID, rewardID), token, handlers.RedeemRewardRequest{Note: note})
+	}
+	redemptions := func(token string, query string) []repository.GetClubRedemptionsRow {
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/redemptions%s", club.ID, query), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeBody[[]repository.GetClubRedemptionsRow](t, resp)
+	}
+	handle := func(token string, redemptionID string, action string) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/redemptions/%s/%s", club.ID, redemptionID, action), token, nil)
+	}
+
+	var skipWeek, customTitle, oldReward string
+	t.Run("Managing rewards", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			token    string
+			req      services.CreateRewardRequest
+			expected int
+		}{
+			{name: "Not a moderator", token: bobToken, req: services.CreateRewardRequest{Name: "Skip a week", Cost: 30}, expected: http.StatusForbidden},
+			{name: "No name", token: aliceToken, req: services.CreateRewardRequest{Name: "   ", Cost: 30}, expected: http.StatusBadRequest},
+			{name: "Name too long", token: aliceToken, req: services.CreateRewardRequest{Name: strings.Repeat("a", config.MaxRewardNameLength+1), Cost: 30}, expected: http.StatusBadRequest},
+			{name: "Free", token: aliceToken, req: services.CreateRewardRequest{Name: "Skip a week", Cost: 0}, expected: http.StatusBadRequest},
+			{name: "Negative stock", token: aliceToken, req: services.CreateRewardRequest{Name: "Skip a week", Cost: 30, Stock: IntToPtr(-1)}, expected: http.StatusBadRequest},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.expected, createReward(tc.token, tc.req).StatusCode)
+			})
+		}
+
+		resp := createReward(aliceToken, services.CreateRewardRequest{Name: " Skip a week ", Description: "One week off", Cost: 30, Stock: IntToPtr(1)})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		skipWeek = decodeBody[handlers.CreatedResponse](t, resp).ID
+		resp = createReward(aliceToken, services.CreateRewardRequest{Name: "Custom title", Cost: 50})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		customTitle = decodeBody[handlers.CreatedResponse](t, resp).ID
+		resp = createReward(aliceToken, services.CreateRewardRequest{Name: "Old reward", Cost: 5})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		oldReward = decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		assert.Equal(t, http.StatusForbidden, updateReward(bobToken, oldReward, services.UpdateRewardRequest{IsActive: BoolToPtr(false)}).StatusCode)
+		assert.Equal(t, http.StatusNotFound, updateReward(aliceToken, "missing", services.UpdateRewardRequest{IsActive: BoolToPtr(false)}).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, updateReward(aliceToken, oldReward, services.UpdateRewardRequest{Cost: FloatToPtr(-1)}).StatusCode)
+		assert.Equal(t, http.StatusOK, updateReward(aliceToken, oldReward, services.UpdateRewardRequest{IsActive: BoolToPtr(false)}).StatusCode)
+
+		// members only see the active rewards, the cheapest first
+		list := rewards(bobToken)
+		if assert.Len(t, list, 2) {
+			assert.Equal(t, skipWeek, list[0].ID)
+			assert.Equal(t, "Skip a week", list[0].Name)
+			assert.Equal(t, "One week off", list[0].Description)
+			assert.Equal(t, IntToPtr(1), list[0].Stock)
+			assert.Equal(t, customTitle, list[1].ID)
+			assert.Nil(t, list[1].Stock)
+		}
+		list = rewards(aliceToken)
+		if assert.Len(t, list, 3) {
+			assert.Equal(t, oldReward, list[0].ID)
+			assert.False(t, list[0].IsActive)
+		}
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/rewards", club.ID), outsiderToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	var bobSkip string
+	t.Run("Redeeming rewards", func(t *testing.T) {
+		setPoints(bobID, 100)
+		setPoints(carolID, 10)
+
+		assert.Equal(t, http.StatusForbidden, redeem(outsiderToken, skipWeek, nil).StatusCode)
+		assert.Equal(t, http.StatusNotFound, redeem(bobToken, "missing", nil).StatusCode)
+		assert.Equal(t, http.StatusConflict, redeem(carolToken, skipWeek, nil).StatusCode)
+		assert.Equal(t, http.StatusConflict, redeem(bobToken, oldReward, nil).StatusCode)
+		note := strings.Repeat("a", config.MaxRedemptionNoteLength+1)
+		assert.Equal(t, http.StatusBadRequest, redeem(bobToken, skipWeek, &note).StatusCode)
+		assert.Equal(t, 100.0, points(bobID))
+		assert.Equal(t, 10.0, points(carolID))
+
+		resp := redeem(bobToken, skipWeek, StringToPtr("  The week of the 12th "))
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		bobSkip = decodeBody[handlers.CreatedResponse](t, resp).ID
+		assert.Equal(t, 70.0, points(bobID))
+
+		// the only one was taken
+		setPoints(carolID, 100)
+		assert.Equal(t, http.StatusConflict, redeem(carolToken, skipWeek, nil).StatusCode)
+		assert.Equal(t, IntToPtr(0), rewards(carolToken)[0].Stock)
+		assert.Equal(t, 100.0, points(carolID))
+	})
+
+	t.Run("Listing redemptions", func(t *testing.T) {
+		list := redemptions(bobToken, "")
+		if assert.Len(t, list, 1) {
+			assert.Equal(t, bobSkip, list[0].ID)
+			assert.Equal(t, "Skip a week", list[0].RewardName)
+			assert.Equal(t, "bob", list[0].Username)
+			assert.Equal(t, 30.0, list[0].Cost)
+			assert.Equal(t, StringToPtr("The week of the 12th"), list[0].Note)
+			assert.Equal(t, services.RedemptionPending, list[0].Status)
+		}
+		assert.Empty(t, redemptions(carolToken, ""))
+		assert.Len(t, redemptions(aliceToken, "?status=pending"), 1)
+		assert.Empty(t, redemptions(aliceToken, "?status=fulfilled"))
+
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/redemptions?status=lost", club.ID), aliceToken, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/redemptions", club.ID), outsiderToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Fulfilling and rejecting", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, handle(bobToken, bobSkip, "fulfill").StatusCode)
+		assert.Equal(t, http.StatusNotFound, handle(aliceToken, "missing", "fulfill").StatusCode)
+		assert.Equal(t, http.StatusOK, handle(aliceToken, bobSkip, "fulfill").StatusCode)
+		assert.Equal(t, http.StatusConflict, handle(aliceToken, bobSkip, "fulfill").StatusCode)
+		assert.Equal(t, http.StatusConflict, handle(aliceToken, bobSkip, "reject").StatusCode)
+		assert.Equal(t, 70.0, points(bobID))
+
+		list := redemptions(bobToken, "?status=fulfilled")
+		if assert.Len(t, list, 1) {
+			assert.NotNil(t, list[0].HandledBy)
+			assert.NotNil(t, list[0].HandledAt)
+		}
+
+		resp := redeem(bobToken, customTitle, nil)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		bobTitle := decodeBody[handlers.CreatedResponse](t, resp).ID
+		assert.Equal(t, 20.0, points(bobID))
+		assert.Equal(t, http.StatusOK, handle(aliceToken, bobTitle, "reject").StatusCode)
+		assert.Equal(t, 70.0, points(bobID))
+
+		// rejecting puts the reward back in stock
+		assert.Equal(t, http.StatusOK, updateReward(aliceToken, skipWeek, services.UpdateRewardRequest{Stock: IntToPtr(1)}).StatusCode)
+		resp = redeem(carolToken, skipWeek, nil)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		carolSkip := decodeBody[handlers.CreatedResponse](t, resp).ID
+		assert.Equal(t, IntToPtr(0), rewards(carolToken)[0].Stock)
+		assert.Equal(t, http.StatusOK, handle(aliceToken, carolSkip, "reject").StatusCode)
+		assert.Equal(t, IntToPtr(1), rewards(carolToken)[0].Stock)
+		assert.Equal(t, 100.0, points(carolID))
+
+		assert.Equal(t, http.StatusOK, updateReward(aliceToken, skipWeek, services.UpdateRewardRequest{UnlimitedStock: true}).StatusCode)
+		assert.Nil(t, rewards(carolToken)[0].Stock)
+	})
+
+	t.Run("Every change is in the points ledger", func(t *testing.T) {
+		ledger, err := querier.GetPointLedger(ctx, repository.GetPointLedgerParams{ClubID: club.ID, UserID: bobID})
+		assert.NoError(t, err)
+		if assert.Len(t, ledger, 4) {
+			assert.Equal(t, 100.0, ledger[0].Amount)
+			assert.Equal(t, services.PointsAdjustment, ledger[0].Reason)
+			assert.Equal(t, -30.0, ledger[1].Amount)
+			assert.Equal(t, 70.0, ledger[1].Balance)
+			assert.Equal(t, services.PointsRedemption, ledger[1].Reason)
+			assert.Equal(t, &bobSkip, ledger[1].ReferenceID)
+			assert.Equal(t, -50.0, ledger[2].Amount)
+			assert.Equal(t, 20.0, ledger[2].Balance)
+			assert.Equal(t, 50.0, ledger[3].Amount)
+			assert.Equal(t, 70.0, ledger[3].Balance)
+			assert.Equal(t, services.PointsRefund, ledger[3].Reason)
+			assert.Equal(t, ledger[2].ReferenceID, ledger[3].ReferenceID)
+		}
+	})
+
+	t.Run("Item rewards", func(t *testing.T) {
+		bookmark := createTestClubItem(t, app, aliceToken, club.ID, "Bookmark")
+		pen := createTestClubItem(t, app, bobToken, club.ID, "Pen")
+		listing := func(itemID string) string {
+			item, err := querier.GetItem(ctx, itemID)
+			assert.NoError(t, err)
+			return item.ListingType
+		}
+
+		assert.Equal(t, http.StatusConflict, createReward(aliceToken, services.CreateRewardRequest{Name: "Pen", Cost: 10, ItemID: &pen}).StatusCode)
+		assert.Equal(t, http.StatusNotFound, createReward(aliceToken, services.CreateRewardRequest{Name: "Pen", Cost: 10, ItemID: StringToPtr("missing")}).StatusCode)
+		resp := createReward(aliceToken, services.CreateRewardRequest{Name: "Bookmark", Cost: 10, Stock: IntToPtr(3), ItemID: &bookmark})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp = createReward(aliceToken, services.CreateRewardRequest{Name: "Bookmark", Cost: 10, ItemID: &bookmark})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		bookmarkReward := decodeBody[handlers.CreatedResponse](t, resp).ID
+		assert.Equal(t, services.ListingReward, listing(bookmark))
+		assert.Equal(t, http.StatusConflict, createReward(aliceToken, services.CreateRewardRequest{Name: "Again", Cost: 10, ItemID: &bookmark}).StatusCode)
+
+		// deactivating lists the item for trading again
+		assert.Equal(t, http.StatusBadRequest, updateReward(aliceToken, bookmarkReward, services.UpdateRewardRequest{UnlimitedStock: true}).StatusCode)
+		assert.Equal(t, http.StatusOK, updateReward(aliceToken, bookmarkReward, services.UpdateRewardRequest{IsActive: BoolToPtr(false)}).StatusCode)
+		assert.Equal(t, services.ListingTrade, listing(bookmark))
+		assert.Equal(t, http.StatusOK, updateReward(aliceToken, bookmarkReward, services.UpdateRewardRequest{IsActive: BoolToPtr(true)}).StatusCode)
+		assert.Equal(t, services.ListingReward, listing(bookmark))
+
+		resp = redeem(bobToken, bookmarkReward, nil)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		bobBookmark := decodeBody[handlers.CreatedResponse](t, resp).ID
+		assert.Equal(t, http.StatusConflict, redeem(carolToken, bookmarkReward, nil).StatusCode)
+
+		assert.Equal(t, http.StatusOK, handle(aliceToken, bobBookmark, "fulfill").StatusCode)
+		item, err := querier.GetItem(ctx, bookmark)
+		assert.NoError(t, err)
+		assert.Equal(t, bobID, item.OwnerID)
+		assert.Equal(t, services.ListingTrade, item.ListingType)
+	})
+}
@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/rhellwege/task-social/config"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarketplaceSearch(t *testing.T) {
+	ctx := context.Background()
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	password := "Password123!@"
+
+	aliceToken, err := CreateTestUser(app, "alice", "alice@example.com", password)
+	assert.NoError(t, err)
+	bobToken, err := CreateTestUser(app, "bob", "bob@example.com", password)
+	assert.NoError(t, err)
+	carolToken, err := CreateTestUser(app, "carol", "carol@example.com", password)
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, aliceToken, "Cyclists", StringToPtr(""), false)
+	assert.NoError(t, err)
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), bobToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	otherClub, err := CreateTestClub(app, carolToken, "Other Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+
+	bobID, err := querier.GetUserIDByEmail(ctx, "bob@example.com")
+	assert.NoError(t, err)
+
+	createItem := func(token, clubID string, req services.CreateItemRequest) string {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", clubID), token, req)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		return decodeBody[handlers.CreatedResponse](t, resp).ID
+	}
+	search := func(query url.Values) []services.MarketplaceSearchItem {
+		resp := protectedJSON(t, app, "GET", "/api/marketplace/search?"+query.Encode(), bobToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeBody[[]services.MarketplaceSearchItem](t, resp)
+	}
+	names := func(items []services.MarketplaceSearchItem) []string {
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			out = append(out, item.Name)
+		}
+		return out
+	}
+
+	createItem(aliceToken, club.ID, services.CreateItemRequest{
+		Name: "Red Mountain Bike", Description: StringToPtr("Barely ridden"), PriceEstimate: FloatToPtr(300),
+		Category: StringToPtr("sports"), Condition: StringToPtr("good"),
+	})
+	createItem(aliceToken, club.ID, services.CreateItemRequest{
+		Name: "Helmet", Description: StringToPtr("Fits any bike rider"), PriceEstimate: FloatToPtr(40),
+		Category: StringToPtr("sports"), Condition: StringToPtr("new"),
+	})
+	cookbook := createItem(aliceToken, club.ID, services.CreateItemRequest{
+		Name: "Cookbook", Category: StringToPtr("books"), Condition: StringToPtr("fair"),
+	})
+	createItem(bobToken, club.ID, services.CreateItemRequest{
+		Name: "Blue Bike Lock", PriceEstimate: FloatToPtr(15), Currency: StringToPtr("EUR"),
+		Category: StringToPtr("tools"), Condition: StringToPtr("like_new"),
+	})
+	createItem(carolToken, otherClub.ID, services.CreateItemRequest{Name: "Road Bike", Category: StringToPtr("sports")})
+
+	t.Run("Search across clubs", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			query    url.Values
+			expected []string
+		}{
+			{name: "Everything", query: url.Values{}, expected: []string{"Red Mountain Bike", "Helmet", "Cookbook", "Blue Bike Lock"}},
+			{name: "Word in name or description", query: url.Values{"q": {"bike"}}, expected: []string{"Red Mountain Bike", "Helmet", "Blue Bike Lock"}},
+			{name: "Prefix", query: url.Values{"q": {"Bik"}}, expected: []string{"Red Mountain Bike", "Helmet", "Blue Bike Lock"}},
+			{name: "All words", query: url.Values{"q": {"mountain bike"}}, expected: []string{"Red Mountain Bike"}},
+			{name: "Query syntax is ignored", query: url.Values{"q": {`bike* ("lock`}}, expected: []string{"Blue Bike Lock"}},
+			{name: "Category", query: url.Values{"category": {"Sports"}}, expected: []string{"Red Mountain Bike", "Helmet"}},
+			{name: "Condition", query: url.Values{"q": {"bike"}, "condition": {"new"}}, expected: []string{"Helmet"}},
+			{name: "Owner", query: url.Values{"owner_id": {bobID}}, expected: []string{"Blue Bike Lock"}},
+			{name: "No match", query: url.Values{"q": {"road"}}, expected: []string{}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.ElementsMatch(t, tc.expected, names(search(tc.query)))
+			})
+		}
+
+		items := search(url.Values{"q": {"helmet"}})
+		if assert.Len(t, items, 1) {
+			assert.Equal(t, club.ID, items[0].ClubID)
+			assert.Equal(t, "Cyclists", items[0].ClubName)
+			assert.Equal(t, "alice", items[0].OwnerUsername)
+			assert.NotNil(t, items[0].Images)
+		}
+	})
+
+	t.Run("Sort and paginate by price", func(t *testing.T) {
+		assert.Equal(t, []string{"Blue Bike Lock", "Helmet", "Red Mountain Bike", "Cookbook"}, names(search(url.Values{"sort": {"price_asc"}})))
+		assert.Equal(t, []string{"Red Mountain Bike", "Helmet"}, names(search(url.Values{"sort": {"price_desc"}, "currency": {"usd"}, "min_price": {"30"}})))
+		assert.Equal(t, []string{"Helmet"}, names(search(url.Values{"sort": {"price_asc"}, "limit": {"1"}, "offset": {"1"}})))
+	})
+
+	t.Run("Invalid searches", func(t *testing.T) {
+		for _, query := range []url.Values{
+			{"category": {"rocks"}},
+			{"condition": {"broken"}},
+			{"sort": {"cheapest"}},
+			{"min_price": {"abc"}},
+			{"q": {strings.Repeat("a", config.MaxSearchQueryLength+1)}},
+		} {
+			resp := protectedJSON(t, app, "GET", "/api/marketplace/search?"+query.Encode(), bobToken, nil)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query.Encode())
+		}
+	})
+
+	t.Run("Edits and deletions update the index", func(t *testing.T) {
+		resp := protectedJSON(t, app, "PUT", "/api/marketplace/item/"+cookbook, aliceToken, repository.UpdateItemParams{Name: StringToPtr("Bike Repair Manual")})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, search(url.Values{"q": {"cookbook"}}))
+		assert.Equal(t, []string{"Bike Repair Manual"}, names(search(url.Values{"q": {"manual"}})))
+
+		resp = protectedJSON(t, app, "DELETE", "/api/marketplace/item/"+cookbook, aliceToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, search(url.Values{"q": {"manual"}}))
+	})
+
+	var bikes string
+	t.Run("Saved searches", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			req      services.SavedSearchRequest
+			expected int
+		}{
+			{name: "Missing name", req: services.SavedSearchRequest{Query: "bike"}, expected: http.StatusBadRequest},
+			{name: "Unknown category", req: services.SavedSearchRequest{Name: "Rocks", Category: "rocks"}, expected: http.StatusBadRequest},
+			{name: "Negative price", req: services.SavedSearchRequest{Name: "Cheap", MinPrice: FloatToPtr(-1)}, expected: http.StatusBadRequest},
+			{name: "Cheap bikes", req: services.SavedSearchRequest{Name: "Cheap bikes", Query: "bike", MaxPrice: FloatToPtr(100)}, expected: http.StatusCreated},
+			{name: "Books", req: services.SavedSearchRequest{Name: "Books", Category: "books"}, expected: http.StatusCreated},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				resp := protectedJSON(t, app, "POST", "/api/marketplace/saved-searches", bobToken, tc.req)
+				assert.Equal(t, tc.expected, resp.StatusCode)
+				if tc.name == "Cheap bikes" {
+					bikes = decodeBody[handlers.CreatedResponse](t, resp).ID
+				}
+			})
+		}
+
+		resp := protectedJSON(t, app, "GET", "/api/marketplace/saved-searches", bobToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		searches := decodeBody[[]repository.SavedSearch](t, resp)
+		if assert.Len(t, searches, 2) {
+			assert.Equal(t, "Books", searches[0].Name)
+			assert.Equal(t, "bike", searches[1].Query)
+		}
+
+		resp = protectedJSON(t, app, "DELETE", "/api/marketplace/saved-searches/"+bikes, aliceToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp = protectedJSON(t, app, "DELETE", "/api/marketplace/saved-searches/unknown", bobToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("New matches are sent over the WebSocket", func(t *testing.T) {
+		testServerAddr := ":1113"
+		go func() {
+			err := app.Listen(testServerAddr)
+			assert.NoError(t, err)
+		}()
+		defer app.Shutdown()
+
+		headers := http.Header{}
+		headers.Set("Authorization", bobToken)
+		var conn *websocket.Conn
+		// the server may still be starting
+		for range 50 {
+			conn, _, err = websocket.DefaultDialer.Dial(fmt.Sprintf("ws://localhost%s/ws", testServerAddr), headers)
+			if err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		nextMatch := func() services.SavedSearchMatch {
+			var message struct {
+				Event   string                    `json:"event"`
+				Payload services.SavedSearchMatch `json:"payload"`
+			}
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			for message.Event != "saved_search_match" {
+				_, data, err := conn.ReadMessage()
+				if !assert.NoError(t, err) {
+					return services.SavedSearchMatch{}
+				}
+				assert.NoError(t, json.Unmarshal(data, &message))
+			}
+			return message.Payload
+		}
+
+		createItem(aliceToken, club.ID, services.CreateItemRequest{Name: "Kids Bike", PriceEstimate: FloatToPtr(80)})
+		match := nextMatch()
+		assert.Equal(t, "Kids Bike", match.Item.Name)
+		assert.Equal(t, club.ID, match.ClubID)
+		if assert.Len(t, match.Searches, 1) {
+			assert.Equal(t, bikes, match.Searches[0].ID)
+			assert.Equal(t, "Cheap bikes", match.Searches[0].Name)
+		}
+
+		// none of these match: too expensive, bob's own item and a club bob is not in
+		createItem(aliceToken, club.ID, services.CreateItemRequest{Name: "Bike Trailer", PriceEstimate: FloatToPtr(500)})
+		createItem(bobToken, club.ID, services.CreateItemRequest{Name: "Bike Pump", PriceEstimate: FloatToPtr(10)})
+		createItem(carolToken, otherClub.ID, services.CreateItemRequest{Name: "Bike Bell", PriceEstimate: FloatToPtr(5)})
+
+		createItem(aliceToken, club.ID, services.CreateItemRequest{Name: "Bike Atlas", PriceEstimate: FloatToPtr(20), Category: StringToPtr("books")})
+		match = nextMatch()
+		assert.Equal(t, "Bike Atlas", match.Item.Name)
+		assert.Len(t, match.Searches, 2)
+
+		resp := protectedJSON(t, app, "DELETE", "/api/marketplace/saved-searches/"+bikes, bobToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		createItem(aliceToken, club.ID, services.CreateItemRequest{Name: "Bike Stand", PriceEstimate: FloatToPtr(30)})
+		createItem(aliceToken, club.ID, services.CreateItemRequest{Name: "Poems", Category: StringToPtr("books")})
+		match = nextMatch()
+		assert.Equal(t, "Poems", match.Item.Name)
+	})
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func refreshTestSession(app *fiber.App, refreshToken string) (*http.Response, handlers.SuccessfulLoginResponse, error) {
+	var body handlers.SuccessfulLoginResponse
+	jsonBody, _ := json.Marshal(handlers.RefreshTokenRequest{RefreshToken: refreshToken})
+	req, err := http.NewRequest("POST", "/api/refresh", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, body, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		return nil, body, err
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(respBody, &body)
+	return resp, body, nil
+}
+
+func getUserStatus(t *testing.T, app *fiber.App, token string) int {
+	req, err := NewProtectedRequest("GET", "/api/user", token, nil, "application/json")
+	assert.NoError(t, err)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestRefreshToken(t *testing.T) {
+	app := SetupTestApp()
+
+	session, err := CreateTestUserSession(app, "refreshuser", "refresh@example.com", "Password123!@")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, session.RefreshToken)
+
+	t.Run("Refresh rotates the token pair", func(t *testing.T) {
+		resp, refreshed, err := refreshTestSession(app, session.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotEmpty(t, refreshed.Token)
+		assert.NotEqual(t, session.RefreshToken, refreshed.RefreshToken)
+		assert.Equal(t, http.StatusOK, getUserStatus(t, app, refreshed.Token))
+
+		// the new refresh token keeps working
+		resp, _, err = refreshTestSession(app, refreshed.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Replayed refresh token revokes the session", func(t *testing.T) {
+		session, err := LoginUserSession(app, StringToPtr("refreshuser"), nil, "Password123!@")
+		assert.NoError(t, err)
+
+		resp, refreshed, err := refreshTestSession(app, session.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _, err = refreshTestSession(app, session.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// the legitimate holder of the rotated token is logged out too
+		assert.Equal(t, http.StatusUnauthorized, getUserStatus(t, app, refreshed.Token))
+		resp, _, err = refreshTestSession(app, refreshed.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Garbage refresh token", func(t *testing.T) {
+		resp, _, err := refreshTestSession(app, "not-a-refresh-token")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	app := SetupTestApp()
+
+	first, err := CreateTestUserSession(app, "logoutuser", "logout@example.com", "Password123!@")
+	assert.NoError(t, err)
+	second, err := LoginUserSession(app, StringToPtr("logoutuser"), nil, "Password123!@")
+	assert.NoError(t, err)
+
+	t.Run("Sessions are listed with the current one flagged", func(t *testing.T) {
+		req, err := NewProtectedRequest("GET", "/api/user/sessions", first.Token, nil, "application/json")
+		assert.NoError(t, err)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var sessions []services.UserSession
+		respBody, _ := io.ReadAll(resp.Body)
+		assert.NoError(t, json.Unmarshal(respBody, &sessions))
+		assert.Len(t, sessions, 2)
+
+		current := 0
+		for _, s := range sessions {
+			if s.IsCurrent {
+				current++
+			}
+		}
+		assert.Equal(t, 1, current)
+	})
+
+	t.Run("Logout only revokes the current session", func(t *testing.T) {
+		req, err := NewProtectedRequest("POST", "/api/logout", first.Token, nil, "application/json")
+		assert.NoError(t, err)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		assert.Equal(t, http.StatusUnauthorized, getUserStatus(t, app, first.Token))
+		assert.Equal(t, http.StatusOK, getUserStatus(t, app, second.Token))
+
+		resp, _, err = refreshTestSession(app, first.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Revoking a session that is not open", func(t *testing.T) {
+		other, err := CreateTestUserSession(app, "otherdevice", "otherdevice@example.com", "Password123!@")
+		assert.NoError(t, err)
+		resp := protectedJSON(t, app, "GET", "/api/user/sessions", other.Token, nil)
+		otherSessions := decodeBody[[]services.UserSession](t, resp)
+		if !assert.Len(t, otherSessions, 1) {
+			return
+		}
+
+		for _, sessionID := range []string{"missing", otherSessions[0].ID} {
+			resp = protectedJSON(t, app, "DELETE", "/api/user/sessions/"+sessionID, second.Token, nil)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		}
+		assert.Equal(t, http.StatusOK, getUserStatus(t, app, other.Token))
+	})
+
+	t.Run("Logout all revokes every session", func(t *testing.T) {
+		third, err := LoginUserSession(app, StringToPtr("logoutuser"), nil, "Password123!@")
+		assert.NoError(t, err)
+
+		req, err := NewProtectedRequest("POST", "/api/logout-all", second.Token, nil, "application/json")
+		assert.NoError(t, err)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		assert.Equal(t, http.StatusUnauthorized, getUserStatus(t, app, second.Token))
+		assert.Equal(t, http.StatusUnauthorized, getUserStatus(t, app, third.Token))
+	})
+}
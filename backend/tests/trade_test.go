@@ -0,0 +1,340 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestClubItem(t *testing.T, app *fiber.App, token string, clubID string, name string) string {
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", clubID), token, services.CreateItemRequest{Name: name})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	return decodeBody[handlers.CreatedResponse](t, resp).ID
+}
+
+func TestTrades(t *testing.T) {
+	ctx := context.Background()
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	password := "Password123!@"
+
+	aliceToken, err := CreateTestUser(app, "alice", "alice@example.com", password)
+	assert.NoError(t, err)
+	bobToken, err := CreateTestUser(app, "bob", "bob@example.com", password)
+	assert.NoError(t, err)
+	carolToken, err := CreateTestUser(app, "carol", "carol@example.com", password)
+	assert.NoError(t, err)
+	outsiderToken, err := CreateTestUser(app, "outsider", "outsider@example.com", password)
+	assert.NoError(t, err)
+	aliceID, err := querier.GetUserIDByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+	bobID, err := querier.GetUserIDByEmail(ctx, "bob@example.com")
+	assert.NoError(t, err)
+	carolID, err := querier.GetUserIDByEmail(ctx, "carol@example.com")
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, aliceToken, "Swap Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	for _, token := range []string{bobToken, carolToken} {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	otherClub, err := CreateTestClub(app, outsiderToken, "Other Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+
+	lamp := createTestClubItem(t, app, aliceToken, club.ID, "Lamp")
+	chair := createTestClubItem(t, app, aliceToken, club.ID, "Chair")
+	vase := createTestClubItem(t, app, aliceToken, club.ID, "Vase")
+	guitar := createTestClubItem(t, app, bobToken, club.ID, "Guitar")
+	drum := createTestClubItem(t, app, bobToken, club.ID, "Drum")
+	bike := createTestClubItem(t, app, carolToken, club.ID, "Bike")
+	kettle := createTestClubItem(t, app, outsiderToken, otherClub.ID, "Kettle")
+
+	proposeTrade := func(token string, req services.TradeRequest) *http.Response {
+		return protectedJSON(t, app, "POST", "/api/marketplace/trades", token, req)
+	}
+	propose := func(token string, offered string, requested string) *http.Response {
+		return proposeTrade(token, services.TradeRequest{
+			OfferedItemIDs:   []string{offered},
+			RequestedItemIDs: []string{requested},
+		})
+	}
+	tradeAction := func(token string, tradeID string, action string) *http.Response {
+		return protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/trades/%s/%s", tradeID, action), token, nil)
+	}
+	getTrade := func(token string, tradeID string) services.TradeDetails {
+		resp := protectedJSON(t, app, "GET", "/api/marketplace/trades/"+tradeID, token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeBody[services.TradeDetails](t, resp)
+	}
+	itemOwner := func(token string, itemID string) bool {
+		resp := protectedJSON(t, app, "GET", "/api/user/items", token, nil)
+		for _, item := range decodeBody[[]repository.Item](t, resp) {
+			if item.ID == itemID {
+				return true
+			}
+		}
+		return false
+	}
+	points := func(userID string) float64 {
+		p, err := querier.GetClubPoints(ctx, repository.GetClubPointsParams{UserID: userID, ClubID: club.ID})
+		assert.NoError(t, err)
+		return p
+	}
+	// points only change through the ledger, Carol moderates the club to adjust them since Alice cannot adjust her own
+	assert.NoError(t, querier.UpdateClubMembership(ctx, repository.UpdateClubMembershipParams{
+		UserID:      carolID,
+		ClubID:      club.ID,
+		IsModerator: BoolToPtr(true),
+	}))
+	setPoints := func(userID string, target float64) {
+		req := services.AdjustPointsRequest{Amount: target - points(userID), Note: "Starting points"}
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/members/%s/points", club.ID, userID), carolToken, req)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	t.Run("Invalid proposals", func(t *testing.T) {
+		testCases := []struct {
+			name      string
+			token     string
+			offered   string
+			requested string
+			expected  int
+		}{
+			{name: "Offering someone else's item", token: aliceToken, offered: guitar, requested: bike, expected: http.StatusBadRequest},
+			{name: "Requesting your own item", token: aliceToken, offered: lamp, requested: chair, expected: http.StatusBadRequest},
+			{name: "Unknown item", token: aliceToken, offered: lamp, requested: "missing", expected: http.StatusNotFound},
+			{name: "Item in a club you are not in", token: outsiderToken, offered: kettle, requested: guitar, expected: http.StatusNotFound},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.expected, propose(tc.token, tc.offered, tc.requested).StatusCode)
+			})
+		}
+	})
+
+	var lampForGuitar, bikeForGuitar string
+	t.Run("Propose and list trades", func(t *testing.T) {
+		resp := propose(aliceToken, lamp, guitar)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		lampForGuitar = decodeBody[handlers.CreatedResponse](t, resp).ID
+		assert.Equal(t, http.StatusConflict, propose(aliceToken, chair, guitar).StatusCode)
+
+		resp = propose(carolToken, bike, guitar)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		bikeForGuitar = decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		resp = protectedJSON(t, app, "GET", "/api/marketplace/trades/incoming?status=pending", bobToken, nil)
+		incoming := decodeBody[[]services.TradeDetails](t, resp)
+		if assert.Len(t, incoming, 2) {
+			assert.Equal(t, "alice", incoming[1].ProposerUsername)
+			if assert.Len(t, incoming[1].ProposerItems, 1) && assert.Len(t, incoming[1].ResponderItems, 1) {
+				assert.Equal(t, "Lamp", incoming[1].ProposerItems[0].Name)
+				assert.Equal(t, "Guitar", incoming[1].ResponderItems[0].Name)
+			}
+		}
+		resp = protectedJSON(t, app, "GET", "/api/marketplace/trades/outgoing", aliceToken, nil)
+		assert.Len(t, decodeBody[[]services.TradeDetails](t, resp), 1)
+		resp = protectedJSON(t, app, "GET", "/api/marketplace/trades/incoming", aliceToken, nil)
+		assert.Empty(t, decodeBody[[]services.TradeDetails](t, resp))
+		resp = protectedJSON(t, app, "GET", "/api/marketplace/trades/incoming?status=open", bobToken, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		// only the two parties can see a trade
+		resp = protectedJSON(t, app, "GET", "/api/marketplace/trades/"+lampForGuitar, carolToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	var counterID string
+	t.Run("Only the responder can answer a trade", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, tradeAction(aliceToken, lampForGuitar, "accept").StatusCode)
+		assert.Equal(t, http.StatusForbidden, tradeAction(aliceToken, lampForGuitar, "reject").StatusCode)
+		assert.Equal(t, http.StatusForbidden, tradeAction(bobToken, lampForGuitar, "cancel").StatusCode)
+		assert.Equal(t, http.StatusNotFound, tradeAction(carolToken, lampForGuitar, "accept").StatusCode)
+	})
+
+	t.Run("Counter-offer", func(t *testing.T) {
+		counter := func(token string, offered string, requested string) *http.Response {
+			return protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/trades/%s/counter", lampForGuitar), token, services.TradeRequest{
+				OfferedItemIDs:   []string{offered},
+				RequestedItemIDs: []string{requested},
+			})
+		}
+		assert.Equal(t, http.StatusForbidden, counter(aliceToken, lamp, guitar).StatusCode)
+		// a counter-offer goes back to the proposer
+		assert.Equal(t, http.StatusBadRequest, counter(bobToken, guitar, bike).StatusCode)
+
+		resp := counter(bobToken, guitar, chair)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		counterID = decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		assert.Equal(t, services.TradeCountered, getTrade(aliceToken, lampForGuitar).Status)
+		assert.Equal(t, http.StatusConflict, tradeAction(bobToken, lampForGuitar, "accept").StatusCode)
+
+		trade := getTrade(aliceToken, counterID)
+		assert.Equal(t, services.TradePending, trade.Status)
+		if assert.Len(t, trade.ResponderItems, 1) {
+			assert.Equal(t, chair, trade.ResponderItems[0].ItemID)
+		}
+		if assert.NotNil(t, trade.CounteredTradeID) {
+			assert.Equal(t, lampForGuitar, *trade.CounteredTradeID)
+		}
+	})
+
+	t.Run("Accepting swaps the items and cancels other trades for them", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, tradeAction(aliceToken, counterID, "accept").StatusCode)
+		assert.Equal(t, http.StatusConflict, tradeAction(aliceToken, counterID, "accept").StatusCode)
+
+		assert.True(t, itemOwner(aliceToken, guitar))
+		assert.True(t, itemOwner(bobToken, chair))
+		assert.False(t, itemOwner(bobToken, guitar))
+		assert.Equal(t, services.TradeAccepted, getTrade(bobToken, counterID).Status)
+
+		// bob no longer owns the guitar carol asked for
+		assert.Equal(t, services.TradeCancelled, getTrade(carolToken, bikeForGuitar).Status)
+		assert.Equal(t, http.StatusConflict, tradeAction(bobToken, bikeForGuitar, "accept").StatusCode)
+	})
+
+	t.Run("Reject and cancel", func(t *testing.T) {
+		resp := propose(carolToken, bike, lamp)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		tradeID := decodeBody[handlers.CreatedResponse](t, resp).ID
+		assert.Equal(t, http.StatusOK, tradeAction(aliceToken, tradeID, "reject").StatusCode)
+		assert.Equal(t, http.StatusConflict, tradeAction(carolToken, tradeID, "cancel").StatusCode)
+		assert.Equal(t, services.TradeRejected, getTrade(carolToken, tradeID).Status)
+
+		// the same trade can be proposed again once the old one is closed
+		resp = propose(carolToken, bike, lamp)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		tradeID = decodeBody[handlers.CreatedResponse](t, resp).ID
+		assert.Equal(t, http.StatusOK, tradeAction(carolToken, tradeID, "cancel").StatusCode)
+		assert.Equal(t, services.TradeCancelled, getTrade(aliceToken, tradeID).Status)
+	})
+
+	t.Run("Unavailable items cannot be traded", func(t *testing.T) {
+		resp := propose(carolToken, bike, lamp)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		tradeID := decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		resp = protectedJSON(t, app, "PUT", "/api/marketplace/item/"+bike, carolToken, repository.UpdateItemParams{IsAvailable: BoolToPtr(false)})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, http.StatusConflict, propose(aliceToken, lamp, bike).StatusCode)
+
+		// nothing changes when the acceptance fails
+		assert.Equal(t, http.StatusConflict, tradeAction(aliceToken, tradeID, "accept").StatusCode)
+		assert.Equal(t, services.TradePending, getTrade(aliceToken, tradeID).Status)
+		assert.True(t, itemOwner(aliceToken, lamp))
+		assert.True(t, itemOwner(carolToken, bike))
+		assert.Equal(t, http.StatusOK, tradeAction(carolToken, tradeID, "cancel").StatusCode)
+	})
+
+	// alice owns the lamp, vase and guitar, bob the chair and drum
+	t.Run("Invalid bundles and points", func(t *testing.T) {
+		tooMany := make([]string, 11)
+		for i := range tooMany {
+			tooMany[i] = lamp
+		}
+		testCases := []struct {
+			name string
+			req  services.TradeRequest
+		}{
+			{name: "Item twice", req: services.TradeRequest{OfferedItemIDs: []string{lamp, lamp}, RequestedItemIDs: []string{drum}}},
+			{name: "Too many items", req: services.TradeRequest{OfferedItemIDs: tooMany, RequestedItemIDs: []string{drum}}},
+			{name: "Nothing in return", req: services.TradeRequest{OfferedItemIDs: []string{lamp}, ResponderID: bobID}},
+			{name: "Items of two users", req: services.TradeRequest{OfferedItemIDs: []string{lamp}, RequestedItemIDs: []string{drum, bike}}},
+			{name: "Points on both sides", req: services.TradeRequest{OfferedPoints: 5, RequestedPoints: 5, RequestedItemIDs: []string{drum}, ClubID: &club.ID}},
+			{name: "Negative points", req: services.TradeRequest{OfferedPoints: -5, RequestedItemIDs: []string{drum}, ClubID: &club.ID}},
+			{name: "Points without a club", req: services.TradeRequest{OfferedPoints: 5, RequestedItemIDs: []string{drum}}},
+			{name: "Club without points", req: services.TradeRequest{OfferedItemIDs: []string{lamp}, RequestedItemIDs: []string{drum}, ClubID: &club.ID}},
+			{name: "Points in a club the users are not in", req: services.TradeRequest{OfferedPoints: 5, RequestedItemIDs: []string{drum}, ClubID: &otherClub.ID}},
+			{name: "Points without a responder", req: services.TradeRequest{OfferedItemIDs: []string{lamp}, RequestedPoints: 5, ClubID: &club.ID}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, http.StatusBadRequest, proposeTrade(aliceToken, tc.req).StatusCode)
+			})
+		}
+	})
+
+	t.Run("Bundles", func(t *testing.T) {
+		resp := proposeTrade(bobToken, services.TradeRequest{
+			OfferedItemIDs:   []string{chair, drum},
+			RequestedItemIDs: []string{lamp, vase, guitar},
+		})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		tradeID := decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		trade := getTrade(aliceToken, tradeID)
+		assert.Len(t, trade.ProposerItems, 2)
+		assert.Len(t, trade.ResponderItems, 3)
+
+		assert.Equal(t, http.StatusOK, tradeAction(aliceToken, tradeID, "accept").StatusCode)
+		for _, item := range []string{lamp, vase, guitar} {
+			assert.True(t, itemOwner(bobToken, item))
+		}
+		for _, item := range []string{chair, drum} {
+			assert.True(t, itemOwner(aliceToken, item))
+		}
+	})
+
+	t.Run("Items for points", func(t *testing.T) {
+		setPoints(aliceID, 50)
+		setPoints(bobID, 5)
+
+		buyLamp := services.TradeRequest{OfferedPoints: 60, RequestedItemIDs: []string{lamp}, ClubID: &club.ID}
+		assert.Equal(t, http.StatusConflict, proposeTrade(aliceToken, buyLamp).StatusCode)
+		buyLamp.OfferedPoints = 30
+		resp := proposeTrade(aliceToken, buyLamp)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		tradeID := decodeBody[handlers.CreatedResponse](t, resp).ID
+
+		assert.Equal(t, http.StatusOK, tradeAction(bobToken, tradeID, "accept").StatusCode)
+		assert.True(t, itemOwner(aliceToken, lamp))
+		assert.Equal(t, 20.0, points(aliceID))
+		assert.Equal(t, 35.0, points(bobID))
+		// both sides of the payment are in the points ledger, after the starting points
+		for userID, amount := range map[string]float64{aliceID: -30, bobID: 30} {
+			ledger, err := querier.GetPointLedger(ctx, repository.GetPointLedgerParams{ClubID: club.ID, UserID: userID})
+			assert.NoError(t, err)
+			if assert.Len(t, ledger, 2) {
+				assert.Equal(t, services.PointsAdjustment, ledger[0].Reason)
+				assert.Equal(t, amount, ledger[1].Amount)
+				assert.Equal(t, points(userID), ledger[1].Balance)
+				assert.Equal(t, services.PointsTrade, ledger[1].Reason)
+				assert.Equal(t, &tradeID, ledger[1].ReferenceID)
+			}
+		}
+
+		// the payer's points are checked again when the trade is accepted
+		resp = proposeTrade(bobToken, services.TradeRequest{
+			ResponderID:     aliceID,
+			OfferedItemIDs:  []string{vase},
+			RequestedPoints: 25,
+			ClubID:          &club.ID,
+		})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		tradeID = decodeBody[handlers.CreatedResponse](t, resp).ID
+		trade := getTrade(aliceToken, tradeID)
+		assert.Equal(t, 25.0, trade.ResponderPoints)
+		assert.Empty(t, trade.ResponderItems)
+
+		assert.Equal(t, http.StatusConflict, tradeAction(aliceToken, tradeID, "accept").StatusCode)
+		assert.Equal(t, services.TradePending, getTrade(aliceToken, tradeID).Status)
+		assert.True(t, itemOwner(bobToken, vase))
+		assert.Equal(t, 20.0, points(aliceID))
+		assert.Equal(t, 35.0, points(bobID))
+
+		// deleting an item cancels its trades
+		resp = protectedJSON(t, app, "DELETE", "/api/marketplace/item/"+vase, bobToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, services.TradeCancelled, getTrade(aliceToken, tradeID).Status)
+	})
+}
@@ -68,14 +68,26 @@ func TestRegisterUserDuplicates(t *testing.T) {
 	_, err := CreateTestUser(app, "testuser", "test@example.com", "Password123!@")
 	assert.NoError(t, err)
 
+	register := func(username string, email string) *http.Response {
+		return postJSON(t, app, "/api/register", handlers.RegisterUserRequest{Username: username, Email: email, Password: "Password123!@"})
+	}
+
+	var duplicateUsername, duplicateEmail handlers.ErrorResponse
 	t.Run("Duplicate username", func(t *testing.T) {
-		_, err := CreateTestUser(app, "testuser", "different@example.com", "Password123!@")
-		assert.Error(t, err)
+		resp := register("testuser", "different@example.com")
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		duplicateUsername = decodeBody[handlers.ErrorResponse](t, resp)
 	})
 
 	t.Run("Duplicate email", func(t *testing.T) {
-		_, err := CreateTestUser(app, "differentuser", "test@example.com", "Password123!@")
-		assert.Error(t, err)
+		resp := register("differentuser", "test@example.com")
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		duplicateEmail = decodeBody[handlers.ErrorResponse](t, resp)
+	})
+
+	t.Run("Duplicates look the same", func(t *testing.T) {
+		assert.Equal(t, duplicateUsername, duplicateEmail)
+		assert.NotContains(t, duplicateEmail.Error, "UNIQUE")
 	})
 }
 
@@ -2,17 +2,73 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/fasthttp/websocket"
 	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
 	"github.com/rhellwege/task-social/internal/db/repository"
 	"github.com/stretchr/testify/assert"
 )
 
+// dialWebSocket connects with the token, retrying while the test server is still starting
+func dialWebSocket(addr string, token string) (*websocket.Conn, error) {
+	headers := make(http.Header)
+	headers.Set("Authorization", token)
+	var conn *websocket.Conn
+	var err error
+	for range 50 {
+		conn, _, err = websocket.DefaultDialer.Dial(fmt.Sprintf("ws://localhost%s/ws", addr), headers)
+		if err == nil {
+			return conn, nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return nil, err
+}
+
+// readEvent reads messages until one with the event arrives and decodes its payload
+func readEvent[T any](t *testing.T, conn *websocket.Conn, event string) (T, bool) {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var message struct {
+			Event   string `json:"event"`
+			Payload T      `json:"payload"`
+		}
+		if err := conn.ReadJSON(&message); !assert.NoError(t, err) {
+			return message.Payload, false
+		}
+		if message.Event == event {
+			return message.Payload, true
+		}
+	}
+}
+
+// sendTopicRequest subscribes or unsubscribes the connection and returns the answer
+func sendTopicRequest(t *testing.T, conn *websocket.Conn, event string, topic string) (string, services.WebSocketErrorPayload) {
+	err := conn.WriteJSON(services.WebSocketMessage{Event: event, Payload: services.TopicRequest{Topic: topic}})
+	assert.NoError(t, err)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var answer struct {
+		Event   string                         `json:"event"`
+		Payload services.WebSocketErrorPayload `json:"payload"`
+	}
+	assert.NoError(t, conn.ReadJSON(&answer))
+	return answer.Event, answer.Payload
+}
+
+// subscribe fails the test unless the connection is subscribed to the topic
+func subscribe(t *testing.T, conn *websocket.Conn, topic string) {
+	event, payload := sendTopicRequest(t, conn, services.WebSocketSubscribe, topic)
+	assert.Equal(t, services.WebSocketSubscribed, event, payload.Error)
+	assert.Equal(t, topic, payload.Topic)
+}
+
 // 1. create a user
 // 2. use the use jwt token to create a websocket client
 // 3. test the client by sending a message
@@ -36,27 +92,34 @@ func TestWebsocketBasic(t *testing.T) {
 		err := app.Listen(testServerAddr)
 		assert.NoError(t, err)
 	}()
+	defer app.Shutdown()
 
 	t.Run("Websocket client connection with JWT", func(t *testing.T) {
-		conn, _, err := dialer.Dial(wsEndpoint, headers)
-		assert.NoError(t, err)
-		defer conn.Close()
+		// the server may still be starting
+		conn, err := dialWebSocket(testServerAddr, token)
+		if assert.NoError(t, err) {
+			conn.Close()
+		}
 	})
 
-	t.Run("Websocket client send echo", func(t *testing.T) {
+	t.Run("Websocket client send answered with an error", func(t *testing.T) {
 		conn, _, err := dialer.Dial(wsEndpoint, headers)
 		assert.NoError(t, err)
 		defer conn.Close()
-		// websocket send acts as an echo server, chat messages go through the API
+		// the client only sends subscriptions, chat messages go through the API
 		err = conn.WriteMessage(websocket.TextMessage, []byte("Hello, world!"))
 		assert.NoError(t, err)
 
-		_, message, err := conn.ReadMessage()
-		assert.NoError(t, err)
-		assert.Equal(t, "Hello, world!", string(message))
+		var answer struct {
+			Event   string                         `json:"event"`
+			Payload services.WebSocketErrorPayload `json:"payload"`
+		}
+		assert.NoError(t, conn.ReadJSON(&answer))
+		assert.Equal(t, services.WebSocketError, answer.Event)
+		assert.Equal(t, services.ErrInvalidWebSocketMessage.Error(), answer.Payload.Error)
 	})
 
-	t.Run("Multiple users echo", func(t *testing.T) {
+	t.Run("Multiple users get their own answers", func(t *testing.T) {
 		token2, err := CreateTestUser(app, "user2", "user2@email.com", "Password123!@")
 		assert.NoError(t, err)
 
@@ -71,19 +134,13 @@ func TestWebsocketBasic(t *testing.T) {
 		assert.NoError(t, err)
 		defer conn2.Close()
 
-		err = conn1.WriteMessage(websocket.TextMessage, []byte("Hello, user1!"))
-		assert.NoError(t, err)
-
-		_, message, err := conn1.ReadMessage()
-		assert.NoError(t, err)
-		assert.Equal(t, "Hello, user1!", string(message))
+		event, payload := sendTopicRequest(t, conn1, services.WebSocketUnsubscribe, "club:user1:posts")
+		assert.Equal(t, services.WebSocketUnsubscribed, event)
+		assert.Equal(t, "club:user1:posts", payload.Topic)
 
-		err = conn2.WriteMessage(websocket.TextMessage, []byte("Hello, user2!"))
-		assert.NoError(t, err)
-
-		_, message, err = conn2.ReadMessage()
-		assert.NoError(t, err)
-		assert.Equal(t, "Hello, user2!", string(message))
+		event, payload = sendTopicRequest(t, conn2, services.WebSocketUnsubscribe, "club:user2:posts")
+		assert.Equal(t, services.WebSocketUnsubscribed, event)
+		assert.Equal(t, "club:user2:posts", payload.Topic)
 	})
 }
 
@@ -129,6 +186,7 @@ func TestClubWebsockets(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	clubID := resp.ID
+	subscribe(t, clubOwnerConn, services.ClubPostsTopic(clubID))
 
 	for i := range 10 {
 		// 1. create test users
@@ -153,6 +211,8 @@ func TestClubWebsockets(t *testing.T) {
 		joinResp, err := app.Test(joinReq)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, joinResp.StatusCode)
+		// 4. and listen to its posts
+		subscribe(t, conn, services.ClubPostsTopic(clubID))
 	}
 
 	t.Run("Club message broadcasts to all joined users", func(t *testing.T) {
@@ -200,3 +260,194 @@ func TestClubWebsockets(t *testing.T) {
 		assert.Equal(t, message.TextContent, myMessage.Payload.Content)
 	})
 }
+
+// a user connected from two devices gets every message on both, and closing one leaves the other connected
+func TestWebsocketMultipleDevices(t *testing.T) {
+	app, _, querier, wsService := SetupTestAppWithConn(&TestMailer{})
+	ctx := context.Background()
+	testServerAddr := ":1116"
+	go func() {
+		err := app.Listen(testServerAddr)
+		assert.NoError(t, err)
+	}()
+	defer app.Shutdown()
+
+	token, err := CreateTestUser(app, "alice", "alice@example.com", "Password123!@")
+	assert.NoError(t, err)
+	userID, err := querier.GetUserIDByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+	club, err := CreateTestClub(app, token, "Devices Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	post := func(text string) {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/post", club.ID), token, handlers.ClubPostRequest{TextContent: text})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	phone, err := dialWebSocket(testServerAddr, token)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer phone.Close()
+	laptop, err := dialWebSocket(testServerAddr, token)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer laptop.Close()
+	for _, conn := range []*websocket.Conn{phone, laptop} {
+		subscribe(t, conn, services.ClubPostsTopic(club.ID))
+	}
+
+	t.Run("Every device gets the message", func(t *testing.T) {
+		assert.Len(t, wsService.GetConnectionIDs(ctx, userID), 2)
+		post("Hello from both")
+		for _, conn := range []*websocket.Conn{phone, laptop} {
+			message, ok := readEvent[repository.ClubPost](t, conn, "new_post")
+			if ok {
+				assert.Equal(t, "Hello from both", message.Content)
+			}
+		}
+	})
+
+	t.Run("Closing one device keeps the other", func(t *testing.T) {
+		phone.Close()
+		// the server notices the closed connection when its read fails
+		for range 50 {
+			if len(wsService.GetConnectionIDs(ctx, userID)) == 1 {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		assert.Len(t, wsService.GetConnectionIDs(ctx, userID), 1)
+
+		post("Still here")
+		message, ok := readEvent[repository.ClubPost](t, laptop, "new_post")
+		if ok {
+			assert.Equal(t, "Still here", message.Content)
+		}
+	})
+}
+
+// connections only get the events of the topics they subscribed to, and only members of the club may subscribe
+func TestWebsocketSubscriptions(t *testing.T) {
+	app, _, querier, _ := SetupTestAppWithConn(&TestMailer{})
+	ctx := context.Background()
+	testServerAddr := ":1117"
+	go func() {
+		err := app.Listen(testServerAddr)
+		assert.NoError(t, err)
+	}()
+	defer app.Shutdown()
+
+	aliceToken, err := CreateTestUser(app, "alice", "alice@example.com", "Password123!@")
+	assert.NoError(t, err)
+	outsiderToken, err := CreateTestUser(app, "outsider", "outsider@example.com", "Password123!@")
+	assert.NoError(t, err)
+	aliceID, err := querier.GetUserIDByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+	club, err := CreateTestClub(app, aliceToken, "Topics Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+
+	resp := protectedJSON(t, app, "POST", "/api/metric", aliceToken, repository.CreateMetricParams{
+		ClubID:      club.ID,
+		Title:       "Pages read",
+		Description: "Pages read this week",
+		Interval:    "168h",
+		StartAt:     time.Now(),
+		Unit:        "pages",
+	})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	metricID := decodeBody[handlers.CreatedResponse](t, resp).ID
+	newInstance := func() {
+		assert.NoError(t, querier.CreateMetricInstance(ctx, repository.CreateMetricInstanceParams{
+			ID:       fmt.Sprintf("instance-%d", time.Now().UnixNano()),
+			MetricID: metricID,
+			DueAt:    time.Now().Add(time.Hour),
+		}))
+	}
+	post := func(text string) {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/post", club.ID), aliceToken, handlers.ClubPostRequest{TextContent: text})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	enter := func(value float64) {
+		newInstance()
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/metric/%s/entry", metricID), aliceToken, repository.CreateMetricEntryParams{Value: value})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+	// nextMessage returns the next message of any event
+	nextMessage := func(conn *websocket.Conn) (services.WebSocketMessage, json.RawMessage) {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var message struct {
+			services.WebSocketMessage
+			Payload json.RawMessage `json:"payload"`
+		}
+		assert.NoError(t, conn.ReadJSON(&message))
+		return message.WebSocketMessage, message.Payload
+	}
+
+	phone, err := dialWebSocket(testServerAddr, aliceToken)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer phone.Close()
+	laptop, err := dialWebSocket(testServerAddr, aliceToken)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer laptop.Close()
+	outsider, err := dialWebSocket(testServerAddr, outsiderToken)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer outsider.Close()
+
+	t.Run("Subscriptions that are refused", func(t *testing.T) {
+		for topic, expected := range map[string]error{
+			services.ClubPostsTopic(club.ID): services.ErrTopicNotAllowed,
+			services.MetricTopic(metricID):   services.ErrTopicNotAllowed,
+			services.MetricTopic("missing"):  services.ErrTopicNotAllowed,
+			"club:" + club.ID + ":secrets":   services.ErrUnknownTopic,
+			// the outsider shares no club with alice
+			services.DirectMessagesTopic(aliceID): services.ErrTopicNotAllowed,
+		} {
+			event, payload := sendTopicRequest(t, outsider, services.WebSocketSubscribe, topic)
+			assert.Equal(t, services.WebSocketError, event, topic)
+			assert.Equal(t, topic, payload.Topic)
+			assert.Equal(t, expected.Error(), payload.Error, topic)
+		}
+	})
+
+	t.Run("Only subscribed connections get the events", func(t *testing.T) {
+		subscribe(t, phone, services.ClubPostsTopic(club.ID))
+		subscribe(t, phone, services.MetricTopic(metricID))
+
+		post("Posted to the phone")
+		message, payload := nextMessage(phone)
+		assert.Equal(t, "new_post", message.Event)
+		assert.Equal(t, services.ClubPostsTopic(club.ID), message.Topic)
+		assert.Contains(t, string(payload), "Posted to the phone")
+
+		enter(42)
+		message, payload = nextMessage(phone)
+		assert.Equal(t, "new_metric_entry", message.Event)
+		assert.Equal(t, services.MetricTopic(metricID), message.Topic)
+		var entry services.MetricEntryEvent
+		assert.NoError(t, json.Unmarshal(payload, &entry))
+		assert.Equal(t, metricID, entry.MetricID)
+		assert.Equal(t, aliceID, entry.UserID)
+		assert.Equal(t, 42.0, entry.Value)
+
+		// the post was queued before the answer, so the laptop would have got it first
+		subscribe(t, laptop, services.ClubMembersTopic(club.ID))
+	})
+
+	t.Run("Unsubscribing stops the events", func(t *testing.T) {
+		event, payload := sendTopicRequest(t, phone, services.WebSocketUnsubscribe, services.ClubPostsTopic(club.ID))
+		assert.Equal(t, services.WebSocketUnsubscribed, event)
+		assert.Equal(t, services.ClubPostsTopic(club.ID), payload.Topic)
+
+		post("Nobody is listening")
+		enter(7)
+		message, _ := nextMessage(phone)
+		assert.Equal(t, "new_metric_entry", message.Event)
+	})
+}
@@ -0,0 +1,276 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/rhellwege/task-social/internal/api/handlers"
+	"github.com/rhellwege/task-social/internal/api/services"
+	"github.com/rhellwege/task-social/internal/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservationsAndWishlists(t *testing.T) {
+	ctx := context.Background()
+	app, querier := SetupTestAppWithQuerier(&TestMailer{})
+	password := "Password123!@"
+
+	aliceToken, err := CreateTestUser(app, "alice", "alice@example.com", password)
+	assert.NoError(t, err)
+	bobToken, err := CreateTestUser(app, "bob", "bob@example.com", password)
+	assert.NoError(t, err)
+	carolToken, err := CreateTestUser(app, "carol", "carol@example.com", password)
+	assert.NoError(t, err)
+	outsiderToken, err := CreateTestUser(app, "outsider", "outsider@example.com", password)
+	assert.NoError(t, err)
+	aliceID, err := querier.GetUserIDByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+	bobID, err := querier.GetUserIDByEmail(ctx, "bob@example.com")
+	assert.NoError(t, err)
+	outsiderID, err := querier.GetUserIDByEmail(ctx, "outsider@example.com")
+	assert.NoError(t, err)
+
+	club, err := CreateTestClub(app, aliceToken, "Swap Club", StringToPtr(""), false)
+	assert.NoError(t, err)
+	for _, token := range []string{bobToken, carolToken} {
+		resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/join", club.ID), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	resp := protectedJSON(t, app, "POST", fmt.Sprintf("/api/club/%s/items", club.ID), aliceToken, services.CreateItemRequest{
+		Name:          "Lamp",
+		PriceEstimate: FloatToPtr(20),
+		Currency:      StringToPtr("USD"),
+	})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	lamp := decodeBody[handlers.CreatedResponse](t, resp).ID
+	chair := createTestClubItem(t, app, aliceToken, club.ID, "Chair")
+	guitar := createTestClubItem(t, app, bobToken, club.ID, "Guitar")
+	bike := createTestClubItem(t, app, carolToken, club.ID, "Bike")
+
+	reserve := func(token string, itemID string, req handlers.ReserveItemRequest) *http.Response {
+		return protectedJSON(t, app, "PUT", fmt.Sprintf("/api/marketplace/item/%s/reservation", itemID), token, req)
+	}
+	release := func(token string, itemID string) *http.Response {
+		return protectedJSON(t, app, "DELETE", fmt.Sprintf("/api/marketplace/item/%s/reservation", itemID), token, nil)
+	}
+	listed := func(token string) map[string]services.ClubMarketplaceItem {
+		resp := protectedJSON(t, app, "GET", fmt.Sprintf("/api/club/%s/items", club.ID), token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		items := map[string]services.ClubMarketplaceItem{}
+		for _, item := range decodeBody[[]services.ClubMarketplaceItem](t, resp) {
+			items[item.ID] = item
+		}
+		return items
+	}
+	found := func(token string, query string) bool {
+		resp := protectedJSON(t, app, "GET", "/api/marketplace/search?q="+query, token, nil)
+		return len(decodeBody[[]services.MarketplaceSearchItem](t, resp)) > 0
+	}
+	wishlist := func(token string) []services.WishlistItem {
+		resp := protectedJSON(t, app, "GET", "/api/marketplace/wishlist", token, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeBody[[]services.WishlistItem](t, resp)
+	}
+
+	t.Run("Invalid reservations", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			token    string
+			itemID   string
+			req      handlers.ReserveItemRequest
+			expected int
+		}{
+			{name: "Someone else's item", token: bobToken, itemID: lamp, req: handlers.ReserveItemRequest{UserID: bobID}, expected: http.StatusForbidden},
+			{name: "For yourself", token: aliceToken, itemID: lamp, req: handlers.ReserveItemRequest{UserID: aliceID}, expected: http.StatusBadRequest},
+			{name: "For a user outside the club", token: aliceToken, itemID: lamp, req: handlers.ReserveItemRequest{UserID: outsiderID}, expected: http.StatusBadRequest},
+			{name: "Too long", token: aliceToken, itemID: lamp, req: handlers.ReserveItemRequest{UserID: bobID, ExpiresInHours: 24*7 + 1}, expected: http.StatusBadRequest},
+			{name: "Negative duration", token: aliceToken, itemID: lamp, req: handlers.ReserveItemRequest{UserID: bobID, ExpiresInHours: -1}, expected: http.StatusBadRequest},
+			{name: "Unknown item", token: aliceToken, itemID: "missing", req: handlers.ReserveItemRequest{UserID: bobID}, expected: http.StatusNotFound},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.expected, reserve(tc.token, tc.itemID, tc.req).StatusCode)
+			})
+		}
+	})
+
+	t.Run("Reserved items are hidden from everyone else", func(t *testing.T) {
+		resp := reserve(aliceToken, lamp, handlers.ReserveItemRequest{UserID: bobID, ExpiresInHours: 2})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		reservation := decodeBody[handlers.ItemReservationResponse](t, resp)
+		assert.Equal(t, bobID, reservation.ReservedFor)
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), reservation.ReservedUntil, time.Minute)
+
+		assert.NotContains(t, listed(carolToken), lamp)
+		assert.NotContains(t, listed(aliceToken), lamp)
+		if item, ok := listed(bobToken)[lamp]; assert.True(t, ok) && assert.NotNil(t, item.ReservedUntil) {
+			assert.WithinDuration(t, reservation.ReservedUntil, *item.ReservedUntil, time.Second)
+		}
+		assert.Nil(t, listed(bobToken)[chair].ReservedUntil)
+		assert.False(t, found(carolToken, "lamp"))
+		assert.True(t, found(bobToken, "lamp"))
+
+		resp = protectedJSON(t, app, "POST", "/api/marketplace/trades", carolToken, services.TradeRequest{
+			OfferedItemIDs:   []string{bike},
+			RequestedItemIDs: []string{lamp},
+		})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		resp = protectedJSON(t, app, "POST", "/api/marketplace/trades", bobToken, services.TradeRequest{
+			OfferedItemIDs:   []string{guitar},
+			RequestedItemIDs: []string{lamp},
+		})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		guitarForLamp := decodeBody[handlers.CreatedResponse](t, resp).ID
+		resp = protectedJSON(t, app, "POST", fmt.Sprintf("/api/marketplace/trades/%s/cancel", guitarForLamp), bobToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Release and expiry", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, release(bobToken, lamp).StatusCode)
+		assert.Equal(t, http.StatusOK, release(aliceToken, lamp).StatusCode)
+		assert.Equal(t, http.StatusConflict, release(aliceToken, lamp).StatusCode)
+		assert.Contains(t, listed(carolToken), lamp)
+
+		// a reservation that ran out no longer hides the item
+		past := time.Now().Add(-time.Minute)
+		assert.NoError(t, querier.ReserveItem(ctx, repository.ReserveItemParams{
+			ReservedFor:   &bobID,
+			ReservedUntil: &past,
+			ID:            lamp,
+		}))
+		assert.Contains(t, listed(carolToken), lamp)
+		assert.True(t, found(carolToken, "lamp"))
+		assert.Nil(t, listed(bobToken)[lamp].ReservedUntil)
+		assert.Equal(t, http.StatusConflict, release(aliceToken, lamp).StatusCode)
+	})
+
+	t.Run("Wishlists", func(t *testing.T) {
+		add := func(token string, itemID string) int {
+			return protectedJSON(t, app, "PUT", "/api/marketplace/wishlist/"+itemID, token, nil).StatusCode
+		}
+		assert.Equal(t, http.StatusBadRequest, add(aliceToken, lamp))
+		assert.Equal(t, http.StatusNotFound, add(outsiderToken, lamp))
+		assert.Equal(t, http.StatusNotFound, add(carolToken, "missing"))
+		assert.Equal(t, http.StatusOK, add(carolToken, lamp))
+		assert.Equal(t, http.StatusOK, add(carolToken, lamp))
+		assert.Equal(t, http.StatusOK, add(carolToken, chair))
+		assert.Equal(t, http.StatusOK, add(bobToken, lamp))
+
+		items := wishlist(carolToken)
+		if assert.Len(t, items, 2) {
+			assert.Equal(t, chair, items[0].ID)
+			assert.Equal(t, lamp, items[1].ID)
+			assert.Equal(t, "alice", items[1].OwnerUsername)
+			assert.Equal(t, club.ID, items[1].ClubID)
+			assert.False(t, items[1].Reserved)
+		}
+
+		// reserved and unavailable items stay on the wishlist
+		resp := reserve(aliceToken, lamp, handlers.ReserveItemRequest{UserID: bobID})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = protectedJSON(t, app, "PUT", "/api/marketplace/item/"+chair, aliceToken, repository.UpdateItemParams{IsAvailable: BoolToPtr(false)})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		items = wishlist(carolToken)
+		if assert.Len(t, items, 2) {
+			assert.False(t, items[0].IsAvailable)
+			assert.True(t, items[1].Reserved)
+			assert.Nil(t, items[1].ReservedUntil)
+		}
+		if items := wishlist(bobToken); assert.Len(t, items, 1) {
+			assert.False(t, items[0].Reserved)
+			assert.NotNil(t, items[0].ReservedUntil)
+		}
+
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/marketplace/item/%s/wishlisters", lamp), aliceToken, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		users := decodeBody[[]repository.GetItemWishlistersRow](t, resp)
+		if assert.Len(t, users, 2) {
+			assert.Equal(t, "carol", users[0].Username)
+			assert.Equal(t, "bob", users[1].Username)
+		}
+		resp = protectedJSON(t, app, "GET", fmt.Sprintf("/api/marketplace/item/%s/wishlisters", lamp), carolToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		assert.Equal(t, http.StatusOK, protectedJSON(t, app, "DELETE", "/api/marketplace/wishlist/"+lamp, bobToken, nil).StatusCode)
+		assert.Equal(t, http.StatusNotFound, protectedJSON(t, app, "DELETE", "/api/marketplace/wishlist/"+lamp, bobToken, nil).StatusCode)
+		assert.Empty(t, wishlist(bobToken))
+	})
+
+	t.Run("Wishlisters hear about changes over the WebSocket", func(t *testing.T) {
+		testServerAddr := ":1114"
+		go func() {
+			err := app.Listen(testServerAddr)
+			assert.NoError(t, err)
+		}()
+		defer app.Shutdown()
+
+		headers := http.Header{}
+		headers.Set("Authorization", carolToken)
+		var conn *websocket.Conn
+		// the server may still be starting
+		for range 50 {
+			conn, _, err = websocket.DefaultDialer.Dial(fmt.Sprintf("ws://localhost%s/ws", testServerAddr), headers)
+			if err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		nextUpdate := func() services.WishlistUpdate {
+			var message struct {
+				Event   string                  `json:"event"`
+				Payload services.WishlistUpdate `json:"payload"`
+			}
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			for message.Event != "wishlist_item_updated" {
+				_, data, err := conn.ReadMessage()
+				if !assert.NoError(t, err) {
+					return services.WishlistUpdate{}
+				}
+				assert.NoError(t, json.Unmarshal(data, &message))
+			}
+			return message.Payload
+		}
+		updateItem := func(itemID string, params repository.UpdateItemParams) {
+			resp := protectedJSON(t, app, "PUT", "/api/marketplace/item/"+itemID, aliceToken, params)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+
+		updateItem(lamp, repository.UpdateItemParams{PriceEstimate: FloatToPtr(15)})
+		update := nextUpdate()
+		assert.Equal(t, lamp, update.Item.ID)
+		assert.Equal(t, club.ID, update.ClubID)
+		assert.True(t, update.PriceChanged)
+		assert.False(t, update.Available)
+		assert.Equal(t, FloatToPtr(20), update.PreviousPrice)
+		assert.Equal(t, StringToPtr("USD"), update.PreviousCurrency)
+		assert.Equal(t, FloatToPtr(15), update.Item.PriceEstimate)
+
+		// nothing to tell: the same price, a new name and the reserved lamp becoming available
+		updateItem(lamp, repository.UpdateItemParams{PriceEstimate: FloatToPtr(15), Name: StringToPtr("Desk Lamp")})
+		updateItem(lamp, repository.UpdateItemParams{IsAvailable: BoolToPtr(false)})
+		updateItem(lamp, repository.UpdateItemParams{IsAvailable: BoolToPtr(true)})
+
+		updateItem(chair, repository.UpdateItemParams{IsAvailable: BoolToPtr(true)})
+		update = nextUpdate()
+		assert.Equal(t, chair, update.Item.ID)
+		assert.True(t, update.Available)
+		assert.False(t, update.PriceChanged)
+
+		assert.Equal(t, http.StatusOK, release(aliceToken, lamp).StatusCode)
+		update = nextUpdate()
+		assert.Equal(t, "Desk Lamp", update.Item.Name)
+		assert.True(t, update.Available)
+	})
+}
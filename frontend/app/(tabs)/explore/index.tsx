@@ -11,6 +11,7 @@ import { Colors } from "@/constants/Colors";
 import { useState, useCallback } from "react";
 import { useFocusEffect } from "@react-navigation/native";
 import { useApi } from "@/hooks/useApi";
+import { useWebSocket } from "@/hooks/useWebSocket";
 import { RepositoryClub } from "@/services/api/Api";
 import { toastError, toastSuccess } from "@/services/toast";
 import { Stack } from "expo-router";
@@ -18,6 +19,7 @@ import { Stack } from "expo-router";
 export default function Tab() {
   const colorScheme = useColorScheme();
   const { api } = useApi();
+  const { subscribeToClub } = useWebSocket();
   const [clubs, setClubs] = useState<RepositoryClub[]>([]);
   const [isLoading, setIsLoading] = useState(true);
 
@@ -42,6 +44,7 @@ export default function Tab() {
   const handleJoin = async (clubId: string) => {
     try {
       await api.api.joinClub(clubId);
+      subscribeToClub(clubId);
       toastSuccess("Successfully joined club!");
       // Remove the joined club from the list
       setClubs((prev) => prev.filter((club) => club.id !== clubId));
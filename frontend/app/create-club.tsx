@@ -8,6 +8,7 @@ import {
   Switch,
 } from "react-native";
 import { useApi } from "@/hooks/useApi";
+import { useWebSocket } from "@/hooks/useWebSocket";
 import { Stack, useRouter } from "expo-router";
 import { ThemedView } from "@/components/ThemedView";
 import { ThemedText } from "@/components/ThemedText";
@@ -18,6 +19,7 @@ import { Colors } from "@/constants/Colors";
 
 export default function CreateClubScreen() {
   const { api } = useApi();
+  const { subscribeToClub } = useWebSocket();
   const router = useRouter();
   const [name, setName] = useState("");
   const [description, setDescription] = useState("");
@@ -42,6 +44,7 @@ export default function CreateClubScreen() {
       const response = await api.api.createClub(clubData);
 
       if (response.data.id) {
+        subscribeToClub(response.data.id);
         toastSuccess("Club created successfully!");
         // Go back to the previous screen (the clubs list), which will then refetch.
         router.back();
@@ -19,6 +19,7 @@ interface WebSocketContextType {
   send: (event: string, payload: any) => void;
   on: (event: string, callback: (data: any) => void) => void;
   off: (event: string, callback: (data: any) => void) => void;
+  subscribeToClub: (clubId: string) => void;
 }
 
 const WebSocketContext = createContext<WebSocketContextType | null>(null);
@@ -31,6 +32,12 @@ export const useWebSocket = () => {
   return context;
 };
 
+// The server only sends a connection the events of the topics it subscribed to
+const clubTopics = (clubId: string) => [
+  `club:${clubId}:posts`,
+  `club:${clubId}:members`,
+];
+
 const parseJwt = (token: string) => {
   try {
     const base64Url = token.split(".")[1];
@@ -56,10 +63,28 @@ export const WebSocketProvider = ({
 }: {
   children: React.ReactNode;
 }) => {
-  const { token } = useApi();
+  const { token, api } = useApi();
   const socketRef = useRef<WebSocket | null>(null);
   const [status, setStatus] = useState<WebSocketStatus>("disconnected");
   const eventListeners = useRef<Record<string, ((data: any) => void)[]>>({});
+  // Topics to subscribe to again whenever a new connection opens
+  const topicsRef = useRef<Set<string>>(new Set());
+
+  const subscribe = useCallback((topic: string) => {
+    topicsRef.current.add(topic);
+    if (socketRef.current && socketRef.current.readyState === WebSocket.OPEN) {
+      socketRef.current.send(
+        JSON.stringify({ event: "subscribe", payload: { topic } }),
+      );
+    }
+  }, []);
+
+  const subscribeToClub = useCallback(
+    (clubId: string) => {
+      clubTopics(clubId).forEach(subscribe);
+    },
+    [subscribe],
+  );
 
   // Use a ref to track segments to prevent re-connections on navigation
   const segments = useSegments();
@@ -83,6 +108,21 @@ export const WebSocketProvider = ({
     ws.onopen = () => {
       console.log("WebSocket connected");
       setStatus("connected");
+      topicsRef.current.forEach(subscribe);
+
+      // Subscribe to the posts and members of every club the user is in
+      api.api
+        .getUserClubs()
+        .then((response) => {
+          (response.data || []).forEach((club) => {
+            if (club.club_id) {
+              subscribeToClub(club.club_id);
+            }
+          });
+        })
+        .catch((error) => {
+          console.error("Failed to subscribe to club notifications:", error);
+        });
     };
 
     ws.onmessage = (event) => {
@@ -116,6 +156,10 @@ export const WebSocketProvider = ({
           toastSuccess(`${payload.username} has joined ${payload.club_name}!`);
         }
 
+        if (message.event === "error") {
+          console.warn("WebSocket error event:", message.payload);
+        }
+
         // Pass event to subscribed components
         if (message.event && eventListeners.current[message.event]) {
           eventListeners.current[message.event].forEach((callback) =>
@@ -137,7 +181,7 @@ export const WebSocketProvider = ({
       setStatus("disconnected");
       socketRef.current = null;
     };
-  }, [token]); // The dependency array no longer includes 'segments'
+  }, [token, api, subscribe, subscribeToClub]); // The dependency array no longer includes 'segments'
 
   const disconnect = useCallback(() => {
     if (socketRef.current) {
@@ -148,6 +192,7 @@ export const WebSocketProvider = ({
       socketRef.current = null;
       setStatus("disconnected");
     }
+    topicsRef.current.clear();
   }, []);
 
   useEffect(() => {
@@ -191,6 +236,7 @@ export const WebSocketProvider = ({
     on,
     off,
     send,
+    subscribeToClub,
   };
 
   return (